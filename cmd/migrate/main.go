@@ -0,0 +1,45 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/mat/arcapi/internal/config"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+// Applies pending database migrations, for deployments that set DB_MIGRATE_ON_STARTUP=false.
+// With "status" it only lists them.
+func main() {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	statusOnly := len(os.Args) > 1 && os.Args[1] == "status"
+	db, err := repository.Connect(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	if !statusOnly {
+		if err := db.Migrate(); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+	}
+
+	status, err := db.MigrationStatus()
+	if err != nil {
+		log.Fatalf("Failed to read migration status: %v", err)
+	}
+	for _, m := range status.Migrations {
+		state := "pending"
+		if m.Applied {
+			state = "applied"
+		}
+		log.Printf("%-8s %s", state, m.ID)
+	}
+	for _, id := range status.Unknown {
+		log.Printf("%-8s %s (applied by a newer build)", "unknown", id)
+	}
+}
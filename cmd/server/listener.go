@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/mat/arcapi/internal/config"
+)
+
+// systemdListenFDsStart is the first file descriptor systemd passes to activated services
+const systemdListenFDsStart = 3
+
+// listen opens the listener selected by the configuration: the first socket passed by
+// systemd socket activation, a Unix domain socket, or TCP on PORT
+func listen(cfg *config.Config) (net.Listener, error) {
+	switch {
+	case cfg.SystemdSocketActivation && cfg.UnixSocket != "":
+		return nil, fmt.Errorf("set either UNIX_SOCKET or SYSTEMD_SOCKET_ACTIVATION, not both")
+	case cfg.SystemdSocketActivation:
+		return systemdListener()
+	case cfg.UnixSocket != "":
+		return unixSocketListener(cfg.UnixSocket, cfg.UnixSocketMode)
+	}
+	return net.Listen("tcp", ":"+cfg.APIPort)
+}
+
+// systemdListener takes over the socket systemd opened for this process (sd_listen_fds).
+// Because systemd keeps the socket open, connections queue up while the service restarts.
+func systemdListener() (net.Listener, error) {
+	if os.Getenv("LISTEN_PID") != strconv.Itoa(os.Getpid()) {
+		return nil, fmt.Errorf("no socket passed by systemd (LISTEN_PID is not this process)")
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count < 1 {
+		return nil, fmt.Errorf("no socket passed by systemd (LISTEN_FDS=%q)", os.Getenv("LISTEN_FDS"))
+	}
+	// Child processes must not inherit the activation variables
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	file := os.NewFile(uintptr(systemdListenFDsStart), "systemd-socket")
+	defer file.Close()
+	ln, err := net.FileListener(file)
+	if err != nil {
+		return nil, fmt.Errorf("systemd socket: %w", err)
+	}
+	return ln, nil
+}
+
+// unixSocketListener listens on path, replacing a stale socket left by an unclean exit,
+// and applies the octal permission mode so the reverse proxy can connect
+func unixSocketListener(path, mode string) (net.Listener, error) {
+	perm, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UNIX_SOCKET_MODE %q: %w", mode, err)
+	}
+	if info, err := os.Stat(path); err == nil {
+		if info.Mode().Type() != fs.ModeSocket {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, fs.FileMode(perm)); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+	return ln, nil
+}
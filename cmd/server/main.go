@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"log"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,26 +12,41 @@ import (
 	"github.com/mat/arcapi/internal/config"
 	"github.com/mat/arcapi/internal/graph"
 	"github.com/mat/arcapi/internal/handlers"
+	"github.com/mat/arcapi/internal/logging"
 	"github.com/mat/arcapi/internal/middleware"
+	"github.com/mat/arcapi/internal/models"
 	"github.com/mat/arcapi/internal/repository"
 	"github.com/mat/arcapi/internal/services"
 )
 
+// logger is the server's component logger; it writes through the logger Setup installs
+var logger = logging.For("server")
+
 func main() {
 	
 	// Load configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		logging.Fatal(logger, "Failed to load config", "error", err)
+	}
+	if err := logging.Setup(cfg.LogLevel, cfg.LogFormat, os.Stderr); err != nil {
+		logging.Fatal(logger, "Invalid logging configuration", "error", err)
+	}
+	tlsMode, err := cfg.GetTLSMode()
+	if err != nil {
+		logging.Fatal(logger, "Invalid TLS configuration", "error", err)
+	}
+	if _, err := cfg.GetSyncSource(); err != nil {
+		logging.Fatal(logger, "Invalid sync source configuration", "error", err)
 	}
 
 	// Initialize database with retry logic (handles cold starts)
-	log.Println("Connecting to database...")
+	logger.Info("Connecting to database")
 	db, err := repository.NewDB(cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		logging.Fatal(logger, "Failed to connect to database", "error", err)
 	}
-	log.Println("Database connection established successfully")
+	logger.Info("Database connection established")
 	defer func() {
 		sqlDB, err := db.DB.DB()
 		if err == nil {
@@ -43,41 +57,93 @@ func main() {
 	// Initialize Redis cache (optional, continue if it fails)
 	cacheService, err := services.NewCacheService(cfg)
 	if err != nil {
-		log.Printf("Warning: Redis not available, continuing without cache: %v", err)
+		logger.Warn("Redis not available, continuing without cache", "error", err)
 		cacheService = nil
 	} else {
 		defer cacheService.Close()
 	}
 
+	// Detail lookups of synced data are read through Redis when it's available
+	var entityCache *repository.EntityCache
+	if cacheService != nil {
+		entityCache = repository.NewEntityCache(cacheService, time.Duration(cfg.EntityCacheTTLMinutes)*time.Minute)
+	}
+
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	apiKeyRepo := repository.NewAPIKeyRepository(db)
 	jwtTokenRepo := repository.NewJWTTokenRepository(db)
-	questRepo := repository.NewQuestRepository(db)
-	itemRepo := repository.NewItemRepository(db)
-	skillNodeRepo := repository.NewSkillNodeRepository(db)
-	hideoutModuleRepo := repository.NewHideoutModuleRepository(db)
+	questRepo := repository.NewQuestRepository(db, entityCache)
+	itemRepo := repository.NewItemRepository(db, entityCache)
+	skillNodeRepo := repository.NewSkillNodeRepository(db, entityCache)
+	hideoutModuleRepo := repository.NewHideoutModuleRepository(db, entityCache)
 	enemyTypeRepo := repository.NewEnemyTypeRepository(db)
 	alertRepo := repository.NewAlertRepository(db)
+	gameEventRepo := repository.NewGameEventRepository(db)
 	auditLogRepo := repository.NewAuditLogRepository(db)
 	questProgressRepo := repository.NewUserQuestProgressRepository(db)
 	hideoutModuleProgressRepo := repository.NewUserHideoutModuleProgressRepository(db)
 	skillNodeProgressRepo := repository.NewUserSkillNodeProgressRepository(db)
 	blueprintProgressRepo := repository.NewUserBlueprintProgressRepository(db)
-	botRepo := repository.NewBotRepository(db)
-	mapRepo := repository.NewMapRepository(db)
-	traderRepo := repository.NewTraderRepository(db)
-	projectRepo := repository.NewProjectRepository(db)
+	traderProgressRepo := repository.NewUserTraderProgressRepository(db)
+	inventoryRepo := repository.NewUserInventoryRepository(db)
+	playerLevelRepo := repository.NewUserPlayerLevelRepository(db)
+	noteRepo := repository.NewUserNoteRepository(db)
+	favoriteRepo := repository.NewFavoriteRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	savedSearchRepo := repository.NewSavedSearchRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	botRepo := repository.NewBotRepository(db, entityCache)
+	mapRepo := repository.NewMapRepository(db, entityCache)
+	traderRepo := repository.NewTraderRepository(db, entityCache)
+	projectRepo := repository.NewProjectRepository(db, entityCache)
+	metadataRepo := repository.NewMetadataRepository(db)
+	itemAliasRepo := repository.NewItemAliasRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	statsRepo := repository.NewStatsRepository(db)
+	userProgressRepo := repository.NewUserProgressRepository(db)
+	recipeRepo := repository.NewRecipeRepository(db)
+	itemStatRepo := repository.NewItemStatRepository(db)
+	translationRepo := repository.NewTranslationRepository(db)
+	imageCheckRepo := repository.NewImageCheckRepository(db)
+	syncRunRepo := repository.NewSyncRunRepository(db)
+	syncConflictRepo := repository.NewSyncConflictRepository(db, entityCache)
+	telemetryRepo := repository.NewTelemetryRepository(db)
+	securityEventRepo := repository.NewSecurityEventRepository(db)
+	experimentRepo := repository.NewExperimentRepository(db)
+	traderPriceHistoryRepo := repository.NewTraderPriceHistoryRepository(db)
+	mapMarkerRepo := repository.NewMapMarkerRepository(db)
+	traderStockRepo := repository.NewTraderStockRepository(db)
+	progressSnapshotRepo := repository.NewProgressSnapshotRepository(db)
+	if err := roleRepo.EnsureDefaults(); err != nil {
+		logger.Warn("Failed to create default roles", "error", err)
+	}
 
 	// Initialize services
 	authCodeRepo := repository.NewAuthorizationCodeRepository(db)
 	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
-	authService := services.NewAuthService(userRepo, apiKeyRepo, jwtTokenRepo, authCodeRepo, refreshTokenRepo, cacheService, cfg)
+	deviceCodeRepo := repository.NewDeviceCodeRepository(db)
+	hooks, err := services.LoadHooks(cfg.HooksConfig)
+	if err != nil {
+		logging.Fatal(logger, "Failed to load hooks", "error", err)
+	}
+	// Live update events for GraphQL subscriptions (only with Redis)
+	eventBus := services.NewEventBus(cacheService)
+	if eventBus != nil {
+		hooks.OnPostSync(eventBus.PublishSyncCompleted)
+	}
+	// Example records for docs and SDK fixtures follow the data shape after every sync
+	exampleService := services.NewExampleService(questRepo, itemRepo, skillNodeRepo, hideoutModuleRepo, enemyTypeRepo, botRepo, mapRepo, traderRepo, projectRepo, metadataRepo)
+	hooks.OnPostSync(exampleService.Regenerate)
+	rbacService := services.NewRBACService(roleRepo)
+	authService := services.NewAuthService(userRepo, apiKeyRepo, jwtTokenRepo, authCodeRepo, refreshTokenRepo, auditLogRepo, cacheService, hooks, rbacService, cfg)
+	deviceAuthService := services.NewDeviceAuthService(deviceCodeRepo, authService)
 	
 	// Supabase Authentication Service (Replaces Authentik OIDC)
 	supabaseAuthService, err := services.NewSupabaseAuthService(cfg)
 	if err != nil {
-		log.Fatalf("Failed to initialize Supabase auth service: %v", err)
+		logging.Fatal(logger, "Failed to initialize Supabase auth service", "error", err)
 	}
 	
 	userService := services.NewUserService(userRepo)
@@ -87,10 +153,12 @@ func main() {
 	if cacheService != nil {
 		dataCacheService = services.NewDataCacheService(cacheService, itemRepo, questRepo)
 		dataCacheService.Start()
-		log.Println("Data cache service started - will refresh items and quests every 15 minutes")
+		logger.Info("Data cache service started", "refresh_interval", "15m")
 	}
 
-	// Initialize sync service (with cache service if available)
+	// Initialize sync service (with cache service if available); it notifies users when
+	// entities they favorited or track change upstream
+	notificationService := services.NewNotificationService(notificationRepo, favoriteRepo, questProgressRepo, hideoutModuleProgressRepo, eventBus)
 	var syncService *services.SyncService
 	if dataCacheService != nil {
 		syncService = services.NewSyncServiceWithCache(
@@ -102,6 +170,14 @@ func main() {
 			mapRepo,
 			traderRepo,
 			projectRepo,
+			recipeRepo,
+			itemStatRepo,
+			translationRepo,
+			metadataRepo,
+			imageCheckRepo,
+			syncRunRepo,
+			notificationService,
+			hooks,
 			dataCacheService,
 			cfg,
 		)
@@ -115,22 +191,105 @@ func main() {
 			mapRepo,
 			traderRepo,
 			projectRepo,
+			recipeRepo,
+			itemStatRepo,
+			translationRepo,
+			metadataRepo,
+			imageCheckRepo,
+			syncRunRepo,
+			notificationService,
+			hooks,
 			cfg,
 		)
 	}
 
-	// Start sync service
-	if err := syncService.Start(); err != nil {
-		log.Fatalf("Failed to start sync service: %v", err)
+	// Start sync service; syncs are cancelled when the server shuts down
+	shutdownCtx, shutdown := context.WithCancel(context.Background())
+	defer shutdown()
+	if err := syncService.Start(shutdownCtx); err != nil {
+		logging.Fatal(logger, "Failed to start sync service", "error", err)
 	}
 	defer syncService.Stop()
 
+	// Wakes long-polling clients when notifications are published from any instance
+	notificationHub := services.NewNotificationHub(eventBus)
+	go notificationHub.Run(shutdownCtx)
+
+	// Start stats service (materialized leaderboard/stats refresh)
+	leaderboardService := services.NewLeaderboardService(statsRepo, userRepo, cacheService)
+	statsService := services.NewStatsService(statsRepo, leaderboardService, cfg)
+	if err := statsService.Start(); err != nil {
+		logging.Fatal(logger, "Failed to start stats service", "error", err)
+	}
+	defer statsService.Stop()
+
+	// Verify stored image URLs on their own schedule; results feed the data-quality report
+	imageCheckService := services.NewImageCheckService(itemRepo, enemyTypeRepo, imageCheckRepo)
+	if err := statsService.AddJob(services.JobImageCheck, cfg.ImageCheckCron, imageCheckService.Run); err != nil {
+		logging.Fatal(logger, "Failed to schedule image checks", "error", err)
+	}
+
+	// Client telemetry, with old daily counts pruned once a day
+	telemetryService := services.NewTelemetryService(telemetryRepo, cfg.TelemetryEnabled, cfg.TelemetryRetentionDays)
+	securityReportService := services.NewSecurityReportService(securityEventRepo, userRepo, notificationRepo, eventBus, cfg.SecurityReportCaptchaSecret, cfg.SecurityReportCaptchaVerifyURL)
+	if err := statsService.AddJob(services.JobTelemetryPrune, services.TelemetryPruneSchedule, telemetryService.Prune); err != nil {
+		logging.Fatal(logger, "Failed to schedule telemetry pruning", "error", err)
+	}
+
+	// Content deleted through the write API is soft-deleted, and purged once a day after the retention period
+	softDeleteService := services.NewSoftDeleteService(questRepo, itemRepo, skillNodeRepo, hideoutModuleRepo, enemyTypeRepo, dataCacheService, cfg.SoftDeleteRetentionDays)
+	if err := statsService.AddJob(services.JobSoftDeletePurge, services.SoftDeletePurgeSchedule, softDeleteService.Purge); err != nil {
+		logging.Fatal(logger, "Failed to schedule soft delete purging", "error", err)
+	}
+
+	// Audit log retention, archiving pruned rows to a directory and/or the backup bucket
+	var auditLogArchives []services.AuditLogArchive
+	if cfg.AuditLogArchiveDir != "" {
+		auditLogArchives = append(auditLogArchives, services.NewAuditLogDirArchive(cfg.AuditLogArchiveDir))
+	}
+	auditLogArchiveEndpoint, err := cfg.GetAuditLogArchiveEndpoint()
+	if err != nil {
+		logging.Fatal(logger, "Invalid audit log archive configuration", "error", err)
+	}
+	if auditLogArchiveEndpoint != "" {
+		uploader := services.NewS3Uploader(auditLogArchiveEndpoint, cfg.BackupS3Region, cfg.BackupS3Bucket, cfg.BackupS3AccessKeyID, cfg.BackupS3SecretAccessKey)
+		auditLogArchives = append(auditLogArchives, services.NewAuditLogS3Archive(uploader, cfg.BackupS3Bucket, cfg.AuditLogArchiveS3Prefix))
+	}
+	auditLogRetentionService := services.NewAuditLogRetentionService(auditLogRepo, cfg.AuditLogRetentionDays, auditLogArchives...)
+	if err := statsService.AddJob(services.JobAuditLogPrune, cfg.AuditLogPruneCron, auditLogRetentionService.Prune); err != nil {
+		logging.Fatal(logger, "Failed to schedule audit log pruning", "error", err)
+	}
+
+	// Daily user progress snapshots, kept in the database unless a directory is configured
+	var progressSnapshotStore services.ProgressSnapshotStore = progressSnapshotRepo
+	if cfg.ProgressSnapshotDir != "" {
+		progressSnapshotStore = services.NewProgressSnapshotDirStore(cfg.ProgressSnapshotDir)
+	}
+	progressSnapshotService := services.NewProgressSnapshotService(userProgressRepo, progressSnapshotStore, cfg.ProgressSnapshotRetentionDays)
+	if progressSnapshotService.Enabled() {
+		if err := statsService.AddJob(services.JobProgressSnapshot, cfg.ProgressSnapshotCron, progressSnapshotService.Run); err != nil {
+			logging.Fatal(logger, "Failed to schedule progress snapshots", "error", err)
+		}
+	}
+
+	// Graceful drain for rolling deploys: stops the schedulers and waits for running jobs
+	drainService := services.NewDrainService()
+	drainService.AddWorker(services.DrainWorker{Name: "sync", Stop: syncService.StopScheduling, Busy: syncService.IsRunning})
+	drainService.AddWorker(services.DrainWorker{Name: "stats", Stop: statsService.Stop, Busy: statsService.IsRunning})
+
+	// Weekly trader stock rotations, recorded from every refresh of the trader feed
+	traderRotationService, err := services.NewTraderRotationService(traderStockRepo, notificationService, cfg.WeeklyResetCron)
+	if err != nil {
+		logging.Fatal(logger, "Invalid WEEKLY_RESET_CRON", "error", err)
+	}
+
 	// Initialize traders service (only if cache is available)
 	var tradersService *services.TradersService
 	if cacheService != nil {
-		tradersService = services.NewTradersService(cacheService)
+		tradersService = services.NewTradersService(cacheService, traderPriceHistoryRepo, cfg)
+		tradersService.OnRefresh(traderRotationService.Record)
 		tradersService.Start()
-		log.Println("Traders service started - will refresh every 15 minutes")
+		logger.Info("Traders service started", "refresh_interval", "15m")
 	}
 
 	// Initialize handlers
@@ -139,49 +298,132 @@ func main() {
 	// Use cache-enabled handlers if cache is available
 	var questHandler *handlers.QuestHandler
 	if dataCacheService != nil {
-		questHandler = handlers.NewQuestHandlerWithCache(questRepo, dataCacheService)
+		questHandler = handlers.NewQuestHandlerWithCache(questRepo, tagRepo, dataCacheService)
 	} else {
-		questHandler = handlers.NewQuestHandler(questRepo)
+		questHandler = handlers.NewQuestHandler(questRepo, tagRepo)
 	}
 	missionHandler := questHandler // Backward compatibility
 
 	var itemHandler *handlers.ItemHandler
 	if dataCacheService != nil {
-		itemHandler = handlers.NewItemHandlerWithCache(itemRepo, questRepo, hideoutModuleRepo, dataCacheService)
+		itemHandler = handlers.NewItemHandlerWithCache(itemRepo, questRepo, hideoutModuleRepo, itemAliasRepo, questProgressRepo, hideoutModuleProgressRepo, inventoryRepo, tagRepo, dataCacheService)
 	} else {
-		itemHandler = handlers.NewItemHandlerWithRepos(itemRepo, questRepo, hideoutModuleRepo)
+		itemHandler = handlers.NewItemHandlerWithRepos(itemRepo, questRepo, hideoutModuleRepo, itemAliasRepo, questProgressRepo, hideoutModuleProgressRepo, inventoryRepo, tagRepo)
 	}
-	skillNodeHandler := handlers.NewSkillNodeHandler(skillNodeRepo)
+	skillNodeHandler := handlers.NewSkillNodeHandler(skillNodeRepo, skillNodeProgressRepo)
 	hideoutModuleHandler := handlers.NewHideoutModuleHandler(hideoutModuleRepo)
 	enemyTypeHandler := handlers.NewEnemyTypeHandler(enemyTypeRepo)
-	alertHandler := handlers.NewAlertHandler(alertRepo)
+	alertHandler := handlers.NewAlertHandler(alertRepo, eventBus)
+	gameEventHandler := handlers.NewGameEventHandler(gameEventRepo)
+	itemAliasHandler := handlers.NewItemAliasHandler(itemAliasRepo, itemRepo)
+	recipeHandler := handlers.NewRecipeHandler(recipeRepo, itemRepo, cfg)
+	acquisitionHandler := handlers.NewAcquisitionHandler(recipeRepo, itemRepo, tradersService, syncService, cacheService, cfg)
+	itemDetailViewHandler := handlers.NewItemDetailViewHandler(itemRepo, recipeRepo)
+	itemCompareHandler := handlers.NewItemCompareHandler(itemRepo, itemStatRepo)
+	deviceAuthHandler := handlers.NewDeviceAuthHandler(deviceAuthService, cfg)
 	botHandler := handlers.NewBotHandler(botRepo)
 	mapHandler := handlers.NewMapHandler(mapRepo)
+	mapMarkerHandler := handlers.NewMapMarkerHandler(mapMarkerRepo, mapRepo)
 	traderHandler := handlers.NewTraderHandler(traderRepo)
 	projectHandler := handlers.NewProjectHandler(projectRepo)
-	var tradersHandler *handlers.TradersHandler
-	if tradersService != nil {
-		tradersHandler = handlers.NewTradersHandler(tradersService)
-	}
+	tradersHandler := handlers.NewTradersHandler(traderRepo, tradersService, traderPriceHistoryRepo)
+	traderRotationHandler := handlers.NewTraderRotationHandler(traderRotationService)
+	progressSnapshotHandler := handlers.NewProgressSnapshotHandler(progressSnapshotService)
 	managementHandler := handlers.NewManagementHandler(
 		authService,
 		apiKeyRepo,
 		auditLogRepo,
 		userRepo,
 		hideoutModuleRepo,
+		rbacService,
 	)
 	syncHandler := handlers.NewSyncHandler(syncService)
+	syncConflictHandler := handlers.NewSyncConflictHandler(syncConflictRepo)
+	dashboardHandler := handlers.NewDashboardHandler(userRepo, apiKeyRepo, questRepo, itemRepo, auditLogRepo, syncService)
+	reportHandler := handlers.NewReportHandler(userRepo)
+	playerLevelThresholds, err := cfg.GetPlayerLevelThresholds()
+	if err != nil {
+		logging.Fatal(logger, "Invalid PLAYER_LEVEL_XP", "error", err)
+	}
+	branding, err := cfg.GetBranding()
+	if err != nil {
+		logging.Fatal(logger, "Invalid BRAND_* configuration", "error", err)
+	}
+	xpHandler := handlers.NewXPHandler(playerLevelRepo, questProgressRepo, questRepo, playerLevelThresholds)
+	inventoryHandler := handlers.NewInventoryHandler(inventoryRepo, itemRepo)
+	translationHandler := handlers.NewTranslationHandler(translationRepo)
+	searchHandler := handlers.NewSearchHandler(translationRepo)
+	noteHandler := handlers.NewNoteHandler(noteRepo, questRepo, itemRepo, hideoutModuleRepo)
+	notificationHandler := handlers.NewNotificationHandler(notificationRepo, notificationHub)
+	favoriteHandler := handlers.NewFavoriteHandler(favoriteRepo, questRepo, itemRepo, mapRepo, enemyTypeRepo)
+	tagHandler := handlers.NewTagHandler(tagRepo, itemRepo, questRepo)
+	savedSearchHandler := handlers.NewSavedSearchHandler(savedSearchRepo)
 	progressHandler := handlers.NewProgressHandler(
 		questProgressRepo,
 		hideoutModuleProgressRepo,
 		skillNodeProgressRepo,
 		blueprintProgressRepo,
+		traderProgressRepo,
+		userProgressRepo,
 		questRepo,
 		hideoutModuleRepo,
 		skillNodeRepo,
+		traderRepo,
 		itemRepo,
 		userRepo,
 	)
+	statsHandler := handlers.NewStatsHandler(statsRepo, statsService)
+	imageCheckHandler := handlers.NewImageCheckHandler(imageCheckRepo)
+	telemetryHandler := handlers.NewTelemetryHandler(telemetryService)
+	securityTxt, err := cfg.GetSecurityTxt()
+	if err != nil {
+		logging.Fatal(logger, "Invalid SECURITY_* configuration", "error", err)
+	}
+	securityReportHandler := handlers.NewSecurityReportHandler(securityReportService, securityEventRepo, securityTxt)
+	auditLogHandler := handlers.NewAuditLogHandler(auditLogRetentionService)
+	deepLinkWebURL, err := cfg.GetDeepLinkWebURL()
+	if err != nil {
+		logging.Fatal(logger, "Invalid deep link configuration", "error", err)
+	}
+	deepLinkHandler := handlers.NewDeepLinkHandler(
+		cfg.DeepLinkScheme,
+		deepLinkWebURL,
+		questRepo,
+		itemRepo,
+		skillNodeRepo,
+		hideoutModuleRepo,
+		enemyTypeRepo,
+		traderRepo,
+		botRepo,
+		mapRepo,
+		projectRepo,
+	)
+	experimentHandler := handlers.NewExperimentHandler(services.NewExperimentService(experimentRepo))
+	drainHandler := handlers.NewDrainHandler(drainService)
+	loggingHandler := handlers.NewLoggingHandler()
+	migrationHandler := handlers.NewMigrationHandler(db)
+	exampleHandler := handlers.NewExampleHandler(exampleService)
+	restoreHandler := handlers.NewRestoreHandler(softDeleteService)
+	leaderboardHandler := handlers.NewLeaderboardHandler(leaderboardService)
+	profileHandler := handlers.NewProfileHandler(userRepo)
+	shareHandler := handlers.NewShareHandler(
+		services.NewShareLinkService(cfg),
+		userRepo,
+		questProgressRepo,
+		hideoutModuleProgressRepo,
+		skillNodeProgressRepo,
+		blueprintProgressRepo,
+	)
+	teamHandler := handlers.NewTeamHandler(teamRepo, questProgressRepo, hideoutModuleProgressRepo)
+	mobileHandler := handlers.NewMobileHandler(
+		alertRepo,
+		questProgressRepo,
+		hideoutModuleProgressRepo,
+		skillNodeProgressRepo,
+		blueprintProgressRepo,
+		syncService,
+		cfg,
+	)
 	exportHandler := handlers.NewExportHandler(
 		questRepo,
 		itemRepo,
@@ -195,6 +437,20 @@ func main() {
 		projectRepo,
 	)
 
+	// Offsite backups of the full export
+	backupEndpoint, err := cfg.GetBackupEndpoint()
+	if err != nil {
+		logging.Fatal(logger, "Invalid backup configuration", "error", err)
+	}
+	if backupEndpoint != "" {
+		uploader := services.NewS3Uploader(backupEndpoint, cfg.BackupS3Region, cfg.BackupS3Bucket, cfg.BackupS3AccessKeyID, cfg.BackupS3SecretAccessKey)
+		backupService := services.NewBackupService(exportHandler.WriteArchive, uploader, cfg.BackupS3Prefix)
+		if err := statsService.AddJob(services.JobBackup, cfg.BackupCron, backupService.Run); err != nil {
+			logging.Fatal(logger, "Failed to schedule backups", "error", err)
+		}
+		logger.Info("Backups scheduled", "endpoint", backupEndpoint, "bucket", cfg.BackupS3Bucket, "schedule", cfg.BackupCron)
+	}
+
 	// Setup router
 	if cfg.LogLevel == "debug" {
 		gin.SetMode(gin.DebugMode)
@@ -205,18 +461,50 @@ func main() {
 	r := gin.New()
 	r.Use(gin.Recovery())
 
+	// Request IDs (first, so every response and audit log row carries one)
+	r.Use(middleware.RequestIDMiddleware())
+
 	// Request size limit (10MB max)
 	r.Use(middleware.RequestSizeLimitMiddleware(10 * 1024 * 1024))
 
 	// Security middleware
-	r.Use(middleware.SecurityMiddleware(cfg.GetAllowedOrigins()))
+	corsFrontends, err := cfg.GetCORSFrontends()
+	if err != nil {
+		logging.Fatal(logger, "Invalid CORS_FRONTENDS", "error", err)
+	}
+	r.Use(middleware.SecurityMiddleware(corsFrontends))
 
 	// Logger middleware
 	r.Use(middleware.LoggerMiddleware(auditLogRepo))
 
+	// Load shedding (after the logger so shed requests are still logged)
+	r.Use(middleware.LoadSheddingMiddleware(cfg, db.PoolStats))
+
+	// Refuse new requests once a drain has started
+	r.Use(middleware.DrainMiddleware(drainService))
+
+	// Mirror a sample of read traffic to a shadow deployment
+	shadowURL, err := cfg.GetShadowTrafficURL()
+	if err != nil {
+		logging.Fatal(logger, "Invalid shadow traffic configuration", "error", err)
+	}
+	if shadowURL != nil {
+		r.Use(middleware.ShadowTrafficMiddleware(shadowURL, cfg.ShadowTrafficPercent, cfg.ShadowTrafficForwardCredentials))
+		logger.Info("Mirroring read traffic", "percent", cfg.ShadowTrafficPercent, "shadow_url", shadowURL.String(), "forward_credentials", cfg.ShadowTrafficForwardCredentials)
+	}
+
+	rateLimitRules, err := cfg.GetRateLimitRules()
+	if err != nil {
+		logging.Fatal(logger, "Invalid RATE_LIMIT_ROUTES", "error", err)
+	}
+
 	// Public routes
 	api := r.Group("/api/v1")
-	api.Use(middleware.RateLimitMiddleware(cacheService, cfg.RateLimitRequests, cfg.RateLimitWindowSeconds))
+	// Route groups declare their own limits below; RATE_LIMIT_ROUTES overrides them
+	rateLimits := middleware.NewRateLimitRegistry(rateLimitRules)
+	api.Use(middleware.RateLimitMiddleware(cacheService, cfg.GetRateLimitTiers(), cfg.RateLimitWindowSeconds, rateLimits))
+	limitsHandler := handlers.NewLimitsHandler(cfg, rateLimits, rbacService)
+	api.Use(middleware.KeyCaseMiddleware())
 	{
 		// Serve swagger.json for documentation tools
 		api.GET("/swagger.json", func(c *gin.Context) {
@@ -250,11 +538,53 @@ func main() {
 		// Sync Snapshot (Public - game data only, no sensitive info)
 		api.GET("/sync/snapshot", syncHandler.GetSnapshot)
 
+		// Device authorization grant (Public - the device has no credentials yet). Codes are
+		// requested once per login; tokens are polled every few seconds until approved.
+		rateLimits.
+			Declare("/api/v1/auth/device/code", 10, time.Minute).
+			Declare("/api/v1/auth/device/token", 30, time.Minute)
+		api.POST("/auth/device/code", deviceAuthHandler.RequestCode)
+		api.POST("/auth/device/token", deviceAuthHandler.Token)
+
+		// Refresh token rotation (Public - the refresh token is the credential)
+		rateLimits.Declare("/api/v1/auth/refresh", 30, time.Minute)
+		api.POST("/auth/refresh", authHandler.Refresh)
+
+		// Self-service writes: any authenticated user changing their own account, like their
+		// progress below
+		self := api.Group("")
+		self.Use(middleware.ProgressAuthMiddleware(authService, cfg, supabaseAuthService))
+		{
+			self.DELETE("/me/sessions/:id", authHandler.RevokeMySession)
+			self.PUT("/me/privacy", leaderboardHandler.UpdateMyPrivacy)
+			self.POST("/me/favorites", favoriteHandler.Add)
+			self.DELETE("/me/favorites/:entity_type/:entity_id", favoriteHandler.Remove)
+			self.POST("/me/saved-searches", savedSearchHandler.Create)
+			self.PUT("/me/saved-searches/:id", savedSearchHandler.Update)
+			self.DELETE("/me/saved-searches/:id", savedSearchHandler.Delete)
+			self.POST("/me/snapshots/:id/restore", progressSnapshotHandler.Restore)
+			self.POST("/me/notifications/read-all", notificationHandler.MarkAllRead)
+			self.POST("/me/notifications/:id/read", notificationHandler.MarkRead)
+			self.POST("/auth/device/verify", deviceAuthHandler.Verify)
+		}
+
+		// Shared progress snapshots (Public - access is granted by the signed token)
+		api.GET("/shared/:token", shareHandler.GetSharedProgress)
+
 		// JWTAuthMiddleware handles Supabase JWT validation
 		readOnly := api.Group("")
 		readOnly.Use(middleware.JWTAuthMiddleware(authService, cfg, supabaseAuthService))
 		{
 			readOnly.GET("/me", authHandler.GetCurrentUser)
+			readOnly.GET("/me/sessions", authHandler.ListMySessions)
+			readOnly.GET("/users/:id/profile", profileHandler.Get)
+			readOnly.GET("/me/experiments", experimentHandler.MyExperiments)
+			readOnly.GET("/me/favorites", favoriteHandler.List)
+			readOnly.GET("/me/saved-searches", savedSearchHandler.List)
+			readOnly.GET("/me/snapshots", progressSnapshotHandler.List)
+			readOnly.GET("/me/notifications", notificationHandler.List)
+			readOnly.GET("/me/notifications/poll", notificationHandler.Poll)
+			readOnly.GET("/mobile/bootstrap", mobileHandler.Bootstrap)
 			// Quests - Read
 			readOnly.GET("/quests", questHandler.List)
 			readOnly.GET("/quests/:id", questHandler.Get)
@@ -265,16 +595,27 @@ func main() {
 			// Items - Read
 			readOnly.GET("/items", itemHandler.List)
 			readOnly.GET("/items/:id", itemHandler.Get)
+			readOnly.GET("/items/:id/materials", recipeHandler.GetMaterials)
+			readOnly.GET("/items/:id/craft-cost", recipeHandler.GetCraftCost)
+			readOnly.GET("/items/:id/acquisition", acquisitionHandler.GetAcquisition)
+			readOnly.GET("/items/:id/detail-view", itemDetailViewHandler.GetDetailView)
+			readOnly.GET("/integrations/appwrite/:collection", exportHandler.AppwriteDocuments)
 			readOnly.GET("/items/required", itemHandler.RequiredItems)
+			readOnly.GET("/items/compare", itemCompareHandler.Compare)
 			readOnly.GET("/items/blueprints", itemHandler.GetBlueprints)
+			readOnly.GET("/tags", tagHandler.List)
 
 			// Skill Nodes - Read
 			readOnly.GET("/skill-nodes", skillNodeHandler.List)
+			readOnly.GET("/skill-nodes/path", skillNodeHandler.Path)
 			readOnly.GET("/skill-nodes/:id", skillNodeHandler.Get)
 
 			// Hideout Modules - Read
 			readOnly.GET("/hideout-modules", hideoutModuleHandler.List)
 			readOnly.GET("/hideout-modules/:id", hideoutModuleHandler.Get)
+			readOnly.GET("/translations/:entity_type", translationHandler.List)
+			readOnly.GET("/search", searchHandler.Search)
+			readOnly.GET("/search/autocomplete", searchHandler.Autocomplete)
 
 			// Enemy Types - Read
 			readOnly.GET("/enemy-types", enemyTypeHandler.List)
@@ -284,19 +625,39 @@ func main() {
 			readOnly.GET("/alerts", alertHandler.List)
 			readOnly.GET("/alerts/active", alertHandler.GetActive)
 			readOnly.GET("/alerts/:id", alertHandler.Get)
-
-			// Traders - Read
-			if tradersHandler != nil {
-				readOnly.GET("/traders", tradersHandler.GetTraders)
-			}
+			readOnly.GET("/incidents", alertHandler.Incidents)
+
+			// Game event calendar - Read (?format=ics for calendar apps)
+			readOnly.GET("/events", gameEventHandler.List)
+			readOnly.GET("/events/:id", gameEventHandler.Get)
+
+			// Traders - Read (DB records merged with the live feed with ?include=inventory,
+			// the deprecated raw feed without it)
+			readOnly.GET("/traders", tradersHandler.List)
+			readOnly.GET("/traders/rotation", traderRotationHandler.Get)
+			readOnly.GET("/traders/:id", tradersHandler.Get)
+			readOnly.GET("/traders/:id/items/:item_id/history", tradersHandler.GetPriceHistory)
 			readOnly.GET("/bots", botHandler.List)
 			readOnly.GET("/bots/:id", botHandler.Get)
 			readOnly.GET("/maps", mapHandler.List)
 			readOnly.GET("/maps/:id", mapHandler.Get)
-			readOnly.GET("/repo-traders", traderHandler.List)
-			readOnly.GET("/repo-traders/:id", traderHandler.Get)
+			readOnly.GET("/maps/:id/markers", mapMarkerHandler.List)
+			// Deprecated split trader routes, superseded by /traders
+			deprecatedTraders := readOnly.Group("", middleware.DeprecatedMiddleware("/api/v1/traders?include=inventory"))
+			deprecatedTraders.GET("/traders/external", tradersHandler.GetTraders)
+			deprecatedTraders.GET("/repo-traders", traderHandler.List)
+			deprecatedTraders.GET("/repo-traders/:id", traderHandler.Get)
 			readOnly.GET("/projects", projectHandler.List)
 			readOnly.GET("/projects/:id", projectHandler.Get)
+
+			// Deep links
+			readOnly.GET("/resolve", deepLinkHandler.Resolve)
+			readOnly.GET("/links/:entity_type/:id", deepLinkHandler.Links)
+
+			readOnly.GET("/leaderboards/:type", leaderboardHandler.List)
+			readOnly.GET("/stats/quests", statsHandler.QuestStats)
+			readOnly.GET("/meta/examples/:entity", exampleHandler.Get)
+			readOnly.GET("/meta/limits", limitsHandler.Get)
 		}
 
 		// Progress routes
@@ -304,6 +665,8 @@ func main() {
 		progress.Use(middleware.ProgressAuthMiddleware(authService, cfg, supabaseAuthService))
 		{
 			progress.GET("/quests", progressHandler.GetMyQuestProgress)
+			progress.GET("/quests/available", progressHandler.GetAvailableQuests)
+			progress.GET("/items/needed", itemHandler.NeededItems)
 			progress.PUT("/quests/:quest_id", progressHandler.UpdateQuestProgress)
 			progress.GET("/hideout-modules", progressHandler.GetMyHideoutModuleProgress)
 			progress.PUT("/hideout-modules/:module_id", progressHandler.UpdateHideoutModuleProgress)
@@ -311,75 +674,198 @@ func main() {
 			progress.PUT("/skill-nodes/:skill_node_id", progressHandler.UpdateSkillNodeProgress)
 			progress.GET("/blueprints", progressHandler.GetMyBlueprintProgress)
 			progress.PUT("/blueprints/:item_id", progressHandler.UpdateBlueprintProgress)
+			progress.GET("/traders", progressHandler.GetMyTraderProgress)
+			progress.PUT("/traders/:trader_id", progressHandler.UpdateTraderProgress)
+			progress.GET("/inventory", inventoryHandler.List)
+			progress.PUT("/inventory/:item_id", inventoryHandler.Update)
+			progress.GET("/xp", xpHandler.GetXP)
+			progress.PUT("/xp", xpHandler.UpdateXP)
+			progress.GET("/notes", noteHandler.List)
+			progress.GET("/notes/:entity_type/:entity_id", noteHandler.Get)
+			progress.PUT("/notes/:entity_type/:entity_id", noteHandler.Upsert)
+			progress.DELETE("/notes/:entity_type/:entity_id", noteHandler.Delete)
+			progress.GET("/summary", progressHandler.GetMyProgressSummary)
+			progress.POST("/share", shareHandler.CreateShareLink)
+		}
+
+		// Team routes (shared progress views between squad members)
+		teams := api.Group("/teams")
+		teams.Use(middleware.JWTAuthMiddleware(authService, cfg, supabaseAuthService))
+		{
+			teams.GET("", teamHandler.List)
+			teams.POST("", teamHandler.Create)
+			teams.POST("/join", teamHandler.Join)
+			teams.GET("/:id/progress", teamHandler.GetProgress)
+			teams.DELETE("/:id/members/me", teamHandler.Leave)
+		}
+
+		// Client telemetry, limited per user on top of the global rate limit. The rule covers
+		// the whole group, so no tier limits apply
+		telemetry := api.Group("/telemetry")
+		telemetry.Use(middleware.JWTAuthMiddleware(authService, cfg, supabaseAuthService))
+		telemetry.Use(middleware.RateLimitMiddleware(cacheService, config.RateLimitTiers{}, cfg.TelemetryRateLimitWindowSeconds,
+			middleware.NewRateLimitRegistry(nil).Declare("/api/v1/telemetry", cfg.TelemetryRateLimit, time.Duration(cfg.TelemetryRateLimitWindowSeconds)*time.Second)))
+		{
+			telemetry.POST("/events", telemetryHandler.Ingest)
+		}
+
+		// Vulnerability reports, open to anyone and limited per IP on top of the global rate limit
+		security := api.Group("/security")
+		security.Use(middleware.RateLimitMiddleware(cacheService, config.RateLimitTiers{}, cfg.SecurityReportRateLimitWindowSeconds,
+			middleware.NewRateLimitRegistry(nil).Declare("/api/v1/security/reports", cfg.SecurityReportRateLimit, time.Duration(cfg.SecurityReportRateLimitWindowSeconds)*time.Second)))
+		{
+			security.POST("/reports", securityReportHandler.Submit)
 		}
 
 		// Write routes
 		writeProtected := api.Group("")
-		writeProtected.Use(middleware.WriteAuthMiddleware(authService, cfg, supabaseAuthService))
+		writeProtected.Use(middleware.WriteAuthMiddleware(authService, cfg, supabaseAuthService, rbacService))
 		{
-			writeProtected.POST("/quests", questHandler.Create)
-			writeProtected.PUT("/quests/:id", questHandler.Update)
-			writeProtected.DELETE("/quests/:id", questHandler.Delete)
-			writeProtected.POST("/missions", missionHandler.Create)
-			writeProtected.PUT("/missions/:id", missionHandler.Update)
-			writeProtected.DELETE("/missions/:id", missionHandler.Delete)
+			dataWrites := writeProtected.Group("")
+			dataWrites.Use(middleware.RequirePermission(rbacService, models.PermManageData))
+			dataWrites.Use(middleware.PreWriteHooks(hooks))
+			{
+				dataWrites.POST("/quests", questHandler.Create)
+				dataWrites.PUT("/quests/:id", questHandler.Update)
+				dataWrites.DELETE("/quests/:id", questHandler.Delete)
 
-			writeProtected.POST("/items", itemHandler.Create)
-			writeProtected.PUT("/items/:id", itemHandler.Update)
-			writeProtected.DELETE("/items/:id", itemHandler.Delete)
+				dataWrites.POST("/missions", missionHandler.Create)
+				dataWrites.PUT("/missions/:id", missionHandler.Update)
+				dataWrites.DELETE("/missions/:id", missionHandler.Delete)
 
-			writeProtected.POST("/skill-nodes", skillNodeHandler.Create)
-			writeProtected.PUT("/skill-nodes/:id", skillNodeHandler.Update)
-			writeProtected.DELETE("/skill-nodes/:id", skillNodeHandler.Delete)
+				dataWrites.POST("/items", itemHandler.Create)
+				dataWrites.PUT("/items/:id", itemHandler.Update)
+				dataWrites.DELETE("/items/:id", itemHandler.Delete)
 
-			writeProtected.POST("/hideout-modules", hideoutModuleHandler.Create)
-			writeProtected.PUT("/hideout-modules/:id", hideoutModuleHandler.Update)
-			writeProtected.DELETE("/hideout-modules/:id", hideoutModuleHandler.Delete)
+				dataWrites.POST("/skill-nodes", skillNodeHandler.Create)
+				dataWrites.PUT("/skill-nodes/:id", skillNodeHandler.Update)
+				dataWrites.DELETE("/skill-nodes/:id", skillNodeHandler.Delete)
 
-			writeProtected.POST("/enemy-types", enemyTypeHandler.Create)
-			writeProtected.PUT("/enemy-types/:id", enemyTypeHandler.Update)
-			writeProtected.DELETE("/enemy-types/:id", enemyTypeHandler.Delete)
+				dataWrites.POST("/hideout-modules", hideoutModuleHandler.Create)
+				dataWrites.PUT("/hideout-modules/:id", hideoutModuleHandler.Update)
+				dataWrites.DELETE("/hideout-modules/:id", hideoutModuleHandler.Delete)
 
-			writeProtected.POST("/alerts", alertHandler.Create)
-			writeProtected.PUT("/alerts/:id", alertHandler.Update)
-			writeProtected.DELETE("/alerts/:id", alertHandler.Delete)
+				dataWrites.POST("/enemy-types", enemyTypeHandler.Create)
+				dataWrites.PUT("/enemy-types/:id", enemyTypeHandler.Update)
+				dataWrites.DELETE("/enemy-types/:id", enemyTypeHandler.Delete)
+			}
+
+			alertWrites := writeProtected.Group("")
+			alertWrites.Use(middleware.RequirePermission(rbacService, models.PermManageAlerts))
+			{
+				alertWrites.POST("/alerts", alertHandler.Create)
+				alertWrites.PUT("/alerts/:id", alertHandler.Update)
+				alertWrites.POST("/alerts/:id/updates", alertHandler.AddIncidentUpdate)
+				alertWrites.DELETE("/alerts/:id", alertHandler.Delete)
+
+				alertWrites.POST("/events", gameEventHandler.Create)
+				alertWrites.PUT("/events/:id", gameEventHandler.Update)
+				alertWrites.DELETE("/events/:id", gameEventHandler.Delete)
+			}
 
 			admin := writeProtected.Group("/admin")
-			admin.Use(middleware.AdminMiddleware())
 			{
-				admin.POST("/api-keys", managementHandler.CreateAPIKey)
-				admin.GET("/api-keys", managementHandler.ListAPIKeys)
-				admin.DELETE("/api-keys/:id", managementHandler.RevokeAPIKey)
-				admin.GET("/logs", managementHandler.QueryLogs)
-				admin.POST("/sync/force", syncHandler.ForceSync)
-				admin.GET("/sync/status", syncHandler.SyncStatus)
-				admin.GET("/users", managementHandler.ListUsers)
-				admin.GET("/users/:id", managementHandler.GetUser)
-				admin.PUT("/users/:id/access", managementHandler.UpdateUserAccess)
-				admin.PUT("/users/:id/role", managementHandler.UpdateUserRole)
-				admin.DELETE("/users/:id", managementHandler.DeleteUser)
-				admin.POST("/hideout-modules/cleanup-duplicates", managementHandler.CleanupDuplicateHideoutModules)
-
-				admin.GET("/export/quests", exportHandler.ExportQuests)
-				admin.GET("/export/items", exportHandler.ExportItems)
-				admin.GET("/export/skill-nodes", exportHandler.ExportSkillNodes)
-				admin.GET("/export/hideout-modules", exportHandler.ExportHideoutModules)
-				admin.GET("/export/enemy-types", exportHandler.ExportEnemyTypes)
-				admin.GET("/export/alerts", exportHandler.ExportAlerts)
-				admin.GET("/export/bots", exportHandler.ExportBots)
-				admin.GET("/export/maps", exportHandler.ExportMaps)
-				admin.GET("/export/traders", exportHandler.ExportTraders)
-				admin.GET("/export/projects", exportHandler.ExportProjects)
-
-				admin.GET("/users/:id/progress", progressHandler.GetAllUserProgress)
-				admin.GET("/users/:id/progress/quests", progressHandler.GetUserQuestProgress)
-				admin.PUT("/users/:id/progress/quests/:quest_id", progressHandler.UpdateUserQuestProgress)
-				admin.GET("/users/:id/progress/hideout-modules", progressHandler.GetUserHideoutModuleProgress)
-				admin.PUT("/users/:id/progress/hideout-modules/:module_id", progressHandler.UpdateUserHideoutModuleProgress)
-				admin.GET("/users/:id/progress/skill-nodes", progressHandler.GetUserSkillNodeProgress)
-				admin.PUT("/users/:id/progress/skill-nodes/:skill_node_id", progressHandler.UpdateUserSkillNodeProgress)
-				admin.GET("/users/:id/progress/blueprints", progressHandler.GetUserBlueprintProgress)
-				admin.PUT("/users/:id/progress/blueprints/:item_id", progressHandler.UpdateUserBlueprintProgress)
+				adminUsers := admin.Group("")
+				adminUsers.Use(middleware.RequirePermission(rbacService, models.PermManageUsers))
+				{
+					adminUsers.POST("/api-keys", managementHandler.CreateAPIKey)
+					adminUsers.GET("/api-keys", managementHandler.ListAPIKeys)
+					adminUsers.DELETE("/api-keys/:id", managementHandler.RevokeAPIKey)
+					adminUsers.DELETE("/jwts/:jti", managementHandler.RevokeJWT)
+
+					adminUsers.GET("/users", managementHandler.ListUsers)
+					adminUsers.GET("/users/:id", managementHandler.GetUser)
+					adminUsers.PUT("/users/:id/access", managementHandler.UpdateUserAccess)
+					adminUsers.PUT("/users/:id/role", managementHandler.UpdateUserRole)
+					adminUsers.DELETE("/users/:id", managementHandler.DeleteUser)
+
+					adminUsers.GET("/users/:id/progress", progressHandler.GetAllUserProgress)
+					adminUsers.POST("/users/:id/progress/bulk", progressHandler.BulkSetUserProgress)
+					adminUsers.GET("/users/:id/progress/diff", progressSnapshotHandler.Diff)
+					adminUsers.GET("/users/:id/snapshots", progressSnapshotHandler.ListUserSnapshots)
+					adminUsers.POST("/progress/copy", progressHandler.CopyUserProgress)
+					adminUsers.GET("/users/:id/progress/quests", progressHandler.GetUserQuestProgress)
+					adminUsers.PUT("/users/:id/progress/quests/:quest_id", progressHandler.UpdateUserQuestProgress)
+					adminUsers.GET("/users/:id/progress/hideout-modules", progressHandler.GetUserHideoutModuleProgress)
+					adminUsers.PUT("/users/:id/progress/hideout-modules/:module_id", progressHandler.UpdateUserHideoutModuleProgress)
+					adminUsers.GET("/users/:id/progress/skill-nodes", progressHandler.GetUserSkillNodeProgress)
+					adminUsers.PUT("/users/:id/progress/skill-nodes/:skill_node_id", progressHandler.UpdateUserSkillNodeProgress)
+					adminUsers.GET("/users/:id/progress/blueprints", progressHandler.GetUserBlueprintProgress)
+					adminUsers.PUT("/users/:id/progress/blueprints/:item_id", progressHandler.UpdateUserBlueprintProgress)
+
+					adminUsers.GET("/reports/inactive-users", reportHandler.InactiveUsers)
+					adminUsers.PUT("/security/events/:id/status", securityReportHandler.UpdateEventStatus)
+
+					adminUsers.GET("/roles", managementHandler.ListRoles)
+					adminUsers.PUT("/roles/:name", middleware.RequirePermission(rbacService, models.PermManageRoles), managementHandler.SaveRole)
+				}
+
+				adminLogs := admin.Group("")
+				adminLogs.Use(middleware.RequirePermission(rbacService, models.PermReadLogs))
+				{
+					adminLogs.GET("/logs", managementHandler.QueryLogs)
+					adminLogs.GET("/logs/stats", auditLogHandler.Stats)
+					adminLogs.GET("/stats", dashboardHandler.Stats)
+					adminLogs.GET("/telemetry", telemetryHandler.Summary)
+					adminLogs.GET("/security/events", securityReportHandler.ListEvents)
+				}
+
+				adminData := admin.Group("")
+				adminData.Use(middleware.RequirePermission(rbacService, models.PermManageData))
+				{
+					adminData.POST("/sync/force", syncHandler.ForceSync)
+					adminData.GET("/sync/status", syncHandler.SyncStatus)
+					adminData.GET("/sync/history", syncHandler.History)
+					adminData.GET("/sync/history/:id", syncHandler.GetRun)
+					adminData.POST("/sync/:entity", syncHandler.SyncEntity)
+					adminData.GET("/sync/conflicts", syncConflictHandler.List)
+					adminData.POST("/sync/conflicts/:id/resolve", syncConflictHandler.Resolve)
+					adminData.POST("/hideout-modules/cleanup-duplicates", managementHandler.CleanupDuplicateHideoutModules)
+
+					adminData.GET("/item-aliases", itemAliasHandler.List)
+					adminData.POST("/item-aliases", itemAliasHandler.Create)
+					adminData.DELETE("/item-aliases/:id", itemAliasHandler.Delete)
+
+					adminData.PUT("/tags/:slug", tagHandler.Save)
+					adminData.DELETE("/tags/:slug", tagHandler.Delete)
+					adminData.PUT("/tags/:slug/:entity_type/:entity_id", tagHandler.Assign)
+					adminData.DELETE("/tags/:slug/:entity_type/:entity_id", tagHandler.Unassign)
+
+					// Map markers
+					adminData.POST("/maps/:id/markers", mapMarkerHandler.Create)
+					adminData.PUT("/map-markers/:id", mapMarkerHandler.Update)
+					adminData.DELETE("/map-markers/:id", mapMarkerHandler.Delete)
+					adminData.GET("/data-quality/unparsed-objectives", itemHandler.UnparsedObjectives)
+					adminData.GET("/data-quality/broken-images", imageCheckHandler.BrokenImages)
+
+					adminData.POST("/drain", drainHandler.Drain)
+					adminData.GET("/drain", drainHandler.Status)
+					adminData.GET("/logging/level", loggingHandler.GetLevel)
+					adminData.PUT("/logging/level", loggingHandler.SetLevel)
+					adminData.GET("/migrations/status", migrationHandler.Status)
+
+					adminData.GET("/experiments", experimentHandler.List)
+					adminData.PUT("/experiments/:key", experimentHandler.Save)
+					adminData.DELETE("/experiments/:key", experimentHandler.Delete)
+
+					adminData.GET("/jobs", statsHandler.ListJobs)
+					adminData.POST("/jobs/:name/run", statsHandler.RunJob)
+
+					adminData.GET("/export/quests", exportHandler.ExportQuests)
+					adminData.GET("/export/items", exportHandler.ExportItems)
+					adminData.GET("/export/skill-nodes", exportHandler.ExportSkillNodes)
+					adminData.GET("/export/hideout-modules", exportHandler.ExportHideoutModules)
+					adminData.GET("/export/enemy-types", exportHandler.ExportEnemyTypes)
+					adminData.GET("/export/alerts", exportHandler.ExportAlerts)
+					adminData.GET("/export/bots", exportHandler.ExportBots)
+					adminData.GET("/export/maps", exportHandler.ExportMaps)
+					adminData.GET("/export/traders", exportHandler.ExportTraders)
+					adminData.GET("/export/projects", exportHandler.ExportProjects)
+					adminData.GET("/export/all", exportHandler.ExportAll)
+					adminData.POST("/import/:entity", exportHandler.Import)
+
+					adminData.POST("/:entity/:id/restore", restoreHandler.Restore)
+				}
 			}
 
 			userProfile := writeProtected.Group("/users")
@@ -390,16 +876,30 @@ func main() {
 		}
 
 		// Health endpoints
-		healthHandler := handlers.NewHealthHandler(db, cacheService)
+		healthHandler := handlers.NewHealthHandler(db, cacheService, syncService, time.Duration(cfg.SyncMaxAgeMinutes)*time.Minute)
 		r.GET("/health", healthHandler.HealthCheck)
 		r.GET("/health/ready", healthHandler.ReadinessCheck)
 		r.GET("/health/live", healthHandler.LivenessCheck)
 
+		r.GET("/.well-known/security.txt", securityReportHandler.SecurityTxt)
+
 		// Config endpoint
-		configHandler := handlers.NewConfigHandler()
+		configHandler := handlers.NewConfigHandler(branding)
 		r.GET("/api/v1/config", configHandler.GetFrontendConfig)
 
+		// Server time and reset countdowns
+		timeHandler, err := handlers.NewTimeHandler(cfg.DailyResetCron, cfg.WeeklyResetCron)
+		if err != nil {
+			logging.Fatal(logger, "Invalid reset schedule", "error", err)
+		}
+		rateLimits.Declare("/api/v1/time", 300, time.Minute)
+		api.GET("/time", timeHandler.Get)
+
 		// GraphQL
+		persistedQueries, err := graph.NewPersistedQueryStore(cacheService, cfg)
+		if err != nil {
+			logging.Fatal(logger, "Failed to load persisted GraphQL queries", "error", err)
+		}
 		graphqlGroup := api.Group("")
 		graph.SetupGraphQLRoutes(
 			graphqlGroup,
@@ -416,6 +916,8 @@ func main() {
 			blueprintProgressRepo,
 			authService,
 			dataCacheService,
+			eventBus,
+			persistedQueries,
 			cfg,
 			supabaseAuthService,
 		)
@@ -436,22 +938,32 @@ func main() {
 		IdleTimeout:    60 * time.Second,
 	}
 
-	go func() {
-		log.Printf("Server starting on port %s", cfg.APIPort)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
-		}
-	}()
+	configureProtocols(srv, cfg)
+	drainService.OnDrain(func() { srv.SetKeepAlivesEnabled(false) })
+
+	ln, err := listen(cfg)
+	if err != nil {
+		logging.Fatal(logger, "Failed to listen", "error", err)
+	}
+	logger.Info("Server starting", "network", ln.Addr().Network(), "address", ln.Addr().String())
+	redirectSrv := startServer(srv, ln, cfg, tlsMode)
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Println("Shutting down server...")
+	logger.Info("Shutting down server")
+	shutdown()
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
+	if err := syncService.Wait(ctx); err != nil {
+		logger.Warn("Sync did not stop before the shutdown timeout", "error", err)
+	}
+	if redirectSrv != nil {
+		redirectSrv.Shutdown(ctx)
+	}
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("Server forced to shutdown:", err)
+		logging.Fatal(logger, "Server forced to shutdown", "error", err)
 	}
-	log.Println("Server exited")
+	logger.Info("Server exited")
 }
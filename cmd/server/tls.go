@@ -0,0 +1,95 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/mat/arcapi/internal/config"
+	"github.com/mat/arcapi/internal/logging"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// configureProtocols enables HTTP/1.1 plus, depending on HTTP2_ENABLED and
+// HTTP2_CLEARTEXT, HTTP/2 over TLS and h2c
+func configureProtocols(srv *http.Server, cfg *config.Config) {
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	if cfg.HTTP2Enabled {
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(cfg.HTTP2Cleartext)
+	}
+	srv.Protocols = &protocols
+}
+
+// startServer serves srv on ln in the background using the configured TLS mode. With TLS
+// and TLS_REDIRECT_PORT set, it also starts a plain HTTP server that redirects to HTTPS
+// (and answers ACME HTTP challenges), which is returned so it can be shut down with srv.
+func startServer(srv *http.Server, ln net.Listener, cfg *config.Config, tlsMode string) *http.Server {
+	redirectHandler := httpsRedirect(cfg.APIPort)
+
+	switch tlsMode {
+	case config.TLSModeFiles:
+		srv.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		go serve(func() error { return srv.ServeTLS(ln, cfg.TLSCertFile, cfg.TLSKeyFile) })
+	case config.TLSModeAutocert:
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.GetAutocertDomains()...),
+			Cache:      autocert.DirCache(cfg.TLSAutocertCacheDir),
+			Email:      cfg.TLSAutocertEmail,
+		}
+		srv.TLSConfig = manager.TLSConfig()
+		srv.TLSConfig.MinVersion = tls.VersionTLS12
+		if !cfg.HTTP2Enabled {
+			srv.TLSConfig.NextProtos = slices.DeleteFunc(srv.TLSConfig.NextProtos, func(p string) bool { return p == "h2" })
+		}
+		redirectHandler = manager.HTTPHandler(redirectHandler)
+		go serve(func() error { return srv.ServeTLS(ln, "", "") })
+	default:
+		go serve(func() error { return srv.Serve(ln) })
+		return nil
+	}
+	logger.Info("TLS enabled", "mode", tlsMode)
+
+	if cfg.TLSRedirectPort == "" {
+		return nil
+	}
+	redirect := &http.Server{
+		Addr:              ":" + cfg.TLSRedirectPort,
+		Handler:           redirectHandler,
+		ReadHeaderTimeout: 5 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
+	go func() {
+		logger.Info("Redirecting HTTP to HTTPS", "port", cfg.TLSRedirectPort)
+		if err := redirect.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logging.Fatal(logger, "Failed to start HTTP redirect server", "error", err)
+		}
+	}()
+	return redirect
+}
+
+func serve(listen func() error) {
+	if err := listen(); err != nil && err != http.ErrServerClosed {
+		logging.Fatal(logger, "Failed to start server", "error", err)
+	}
+}
+
+// httpsRedirect sends plain HTTP requests to the same host and path over HTTPS on port,
+// which is left out of the target when it is the default 443
+func httpsRedirect(port string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		http.Redirect(w, r, fmt.Sprintf("https://%s%s", host, r.URL.RequestURI()), http.StatusMovedPermanently)
+	})
+}
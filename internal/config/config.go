@@ -1,9 +1,13 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
@@ -18,33 +22,231 @@ type Config struct {
 	DBName     string `envconfig:"DB_NAME" default:"arcapi"`
 	DBSSLMode  string `envconfig:"DB_SSL_MODE" default:"disable"`
 
+	// Apply pending migrations at startup. When false, startup fails while any are pending
+	// and migrations are applied with `go run ./cmd/migrate`.
+	DBMigrateOnStartup bool `envconfig:"DB_MIGRATE_ON_STARTUP" default:"true"`
+
 	// Redis - supports both URL format (redis://password@host:port) or separate config
 	RedisURL      string `envconfig:"REDIS_URL" default:""`                // Single URL format: redis://password@host:port or redis://host:port
 	RedisAddr     string `envconfig:"REDIS_ADDR" default:"localhost:6379"` // Fallback if REDIS_URL not set
 	RedisPassword string `envconfig:"REDIS_PASSWORD" default:""`           // Fallback if REDIS_URL not set
 
-	// Sync
-	SyncCron string `envconfig:"SYNC_CRON" default:"*/15 * * * *"`
+	// Entity cache - how long detail lookups of synced data are cached in Redis (0 disables
+	// the cache). Entries are invalidated by sync and admin edits well before they expire.
+	EntityCacheTTLMinutes int `envconfig:"ENTITY_CACHE_TTL_MINUTES" default:"60"`
+
+	// Sync - GET /health reports the sync as degraded once its last success is older than
+	// SyncMaxAgeMinutes; runs are kept in the sync history for SyncRunRetentionDays (0
+	// keeps them forever)
+	SyncCron             string `envconfig:"SYNC_CRON" default:"*/15 * * * *"`
+	SyncMaxAgeMinutes    int    `envconfig:"SYNC_MAX_AGE_MINUTES" default:"60"`
+	SyncRunRetentionDays int    `envconfig:"SYNC_RUN_RETENTION_DAYS" default:"30"`
+
+	// Sync source - the GitHub repository and branch game data is synced from, and the
+	// directory of the repository its JSON files and images are in (empty for the root)
+	SyncRepoOwner  string `envconfig:"SYNC_REPO_OWNER" default:"MatD1"`
+	SyncRepoName   string `envconfig:"SYNC_REPO_NAME" default:"arcraiders-data-fork"`
+	SyncRepoBranch string `envconfig:"SYNC_REPO_BRANCH" default:"main"`
+	SyncRepoPath   string `envconfig:"SYNC_REPO_PATH" default:""`
+
+	// Where sync fetches the repository from: "github", "dir" for a checkout at SyncSourceDir,
+	// "url" for a zip archive at SyncSourceURL or "s3" for a zip archive at SyncS3Key in an
+	// S3-compatible bucket (AWS S3 in SyncS3Region unless SyncS3Endpoint is set)
+	SyncSource            string `envconfig:"SYNC_SOURCE" default:"github"`
+	SyncSourceDir         string `envconfig:"SYNC_SOURCE_DIR" default:""`
+	SyncSourceURL         string `envconfig:"SYNC_SOURCE_URL" default:""`
+	SyncS3Endpoint        string `envconfig:"SYNC_S3_ENDPOINT" default:""`
+	SyncS3Region          string `envconfig:"SYNC_S3_REGION" default:"us-east-1"`
+	SyncS3Bucket          string `envconfig:"SYNC_S3_BUCKET" default:""`
+	SyncS3Key             string `envconfig:"SYNC_S3_KEY" default:""`
+	SyncS3AccessKeyID     string `envconfig:"SYNC_S3_ACCESS_KEY_ID" default:""`
+	SyncS3SecretAccessKey string `envconfig:"SYNC_S3_SECRET_ACCESS_KEY" default:""`
+
+	// Stats - refresh schedule for the materialized leaderboard/stats tables
+	StatsCron string `envconfig:"STATS_CRON" default:"*/10 * * * *"`
+
+	// GraphQL persisted queries (APQ). Client-registered queries expire after the TTL;
+	// GRAPHQL_PERSISTED_ONLY rejects anything not in the GRAPHQL_PERSISTED_QUERIES_FILE
+	// manifest ({"<sha256>": "<query>"}) for production allow-listing
+	GraphQLAPQTTLHours          int    `envconfig:"GRAPHQL_APQ_TTL_HOURS" default:"168"`
+	GraphQLPersistedOnly        bool   `envconfig:"GRAPHQL_PERSISTED_ONLY" default:"false"`
+	GraphQLPersistedQueriesFile string `envconfig:"GRAPHQL_PERSISTED_QUERIES_FILE" default:""`
+
+	// Image checks - schedule for verifying item/enemy image URLs and repairing broken links
+	ImageCheckCron string `envconfig:"IMAGE_CHECK_CRON" default:"0 4 * * *"`
 
 	// Server
-	APIPort  string `envconfig:"PORT" default:"8080"` // Railway uses PORT env var
-	LogLevel string `envconfig:"LOG_LEVEL" default:"info"`
+	APIPort   string `envconfig:"PORT" default:"8080"` // Railway uses PORT env var
+	LogLevel  string `envconfig:"LOG_LEVEL" default:"info"`
+	LogFormat string `envconfig:"LOG_FORMAT" default:"json"` // json for log collectors, text for terminals
+
+	// Listener - TCP on PORT by default. UNIX_SOCKET serves on a Unix domain socket
+	// instead; SYSTEMD_SOCKET_ACTIVATION uses the socket passed by systemd (LISTEN_FDS)
+	UnixSocket              string `envconfig:"UNIX_SOCKET" default:""`
+	UnixSocketMode          string `envconfig:"UNIX_SOCKET_MODE" default:"0660"` // Octal permissions
+	SystemdSocketActivation bool   `envconfig:"SYSTEMD_SOCKET_ACTIVATION" default:"false"`
+
+	// TLS - optional built-in HTTPS for hosts without a reverse proxy. Use either a
+	// certificate/key pair or ACME (Let's Encrypt) certificates for the listed domains.
+	TLSCertFile         string `envconfig:"TLS_CERT_FILE" default:""`
+	TLSKeyFile          string `envconfig:"TLS_KEY_FILE" default:""`
+	TLSAutocertDomains  string `envconfig:"TLS_AUTOCERT_DOMAINS" default:""` // Comma-separated
+	TLSAutocertEmail    string `envconfig:"TLS_AUTOCERT_EMAIL" default:""`
+	TLSAutocertCacheDir string `envconfig:"TLS_AUTOCERT_CACHE_DIR" default:"certs"`
+	// Plain HTTP port that redirects to HTTPS and answers ACME HTTP challenges, e.g. "80"
+	TLSRedirectPort string `envconfig:"TLS_REDIRECT_PORT" default:""`
+
+	// HTTP/2 - negotiated via ALPN over TLS; HTTP2_CLEARTEXT also accepts h2c (HTTP/2
+	// without TLS) for proxies that forward it
+	HTTP2Enabled   bool `envconfig:"HTTP2_ENABLED" default:"true"`
+	HTTP2Cleartext bool `envconfig:"HTTP2_CLEARTEXT" default:"false"`
 
 	// Security
 	AllowedOrigins string `envconfig:"ALLOWED_ORIGINS" default:""`
+	// Named frontends with their own CORS policy, as a JSON array of
+	// {"name", "origins", "headers", "credentials"}; see GetCORSFrontends
+	CORSFrontends string `envconfig:"CORS_FRONTENDS" default:""`
 
 	// Rate Limiting
-	RateLimitRequests      int `envconfig:"RATE_LIMIT_REQUESTS" default:"21"`
+	RateLimitRequests      int `envconfig:"RATE_LIMIT_REQUESTS" default:"21"` // Anonymous requests, per IP
 	RateLimitWindowSeconds int `envconfig:"RATE_LIMIT_WINDOW_SECONDS" default:"60"`
 	RateLimitBurst         int `envconfig:"RATE_LIMIT_BURST" default:"8"`
+	// Per-tier limits for authenticated requests over the same window: per user with a
+	// Bearer token, per API key, and per admin user with either. 0 disables limiting.
+	RateLimitUserRequests   int `envconfig:"RATE_LIMIT_USER_REQUESTS" default:"60"`
+	RateLimitAPIKeyRequests int `envconfig:"RATE_LIMIT_API_KEY_REQUESTS" default:"120"`
+	RateLimitAdminRequests  int `envconfig:"RATE_LIMIT_ADMIN_REQUESTS" default:"300"`
+	// Largest page size API keys with the bulk:read scope may request (others are capped at 100)
+	BulkReadMaxPageSize int `envconfig:"BULK_READ_MAX_PAGE_SIZE" default:"1000"`
+	// Per-route buckets: comma-separated "path_prefix=limit[/window_seconds]"; a limit of 0 exempts
+	// the prefix. Overrides the limits route groups declare for the same prefix.
+	RateLimitRoutes string `envconfig:"RATE_LIMIT_ROUTES" default:""`
+
+	// Load shedding - reject low-priority requests with 503 when in-flight requests or the
+	// DB pool approach saturation. Thresholds are fractions of capacity; 0 max disables it.
+	LoadShedMaxInFlight       int     `envconfig:"LOAD_SHED_MAX_IN_FLIGHT" default:"200"`
+	LoadShedLowThreshold      float64 `envconfig:"LOAD_SHED_LOW_THRESHOLD" default:"0.7"`
+	LoadShedNormalThreshold   float64 `envconfig:"LOAD_SHED_NORMAL_THRESHOLD" default:"0.9"`
+	LoadShedRetryAfterSeconds int     `envconfig:"LOAD_SHED_RETRY_AFTER_SECONDS" default:"5"`
 
 	// Supabase Auth
 	SupabaseURL            string `envconfig:"SUPABASE_URL" default:""`            // Main project URL (fallback: NEXT_PUBLIC_SUPABASE_URL)
 	SupabaseJWKSURL        string `envconfig:"SUPABASE_JWKS_URL" default:""`        // Use if different from standard auth/v1/jwks
 	SupabasePublishableKey string `envconfig:"SUPABASE_PUBLISHABLE_KEY" default:""` // Modern label (replacing "Anon Key")
 
+	// Refresh tokens
+	RefreshTokenTTLHours int `envconfig:"REFRESH_TOKEN_TTL_HOURS" default:"720"`
+
+	// Access tokens issued alongside refresh tokens - HMAC secret for signing them and their lifetime
+	AccessTokenSecret     string `envconfig:"ACCESS_TOKEN_SECRET" default:""`
+	AccessTokenTTLMinutes int    `envconfig:"ACCESS_TOKEN_TTL_MINUTES" default:"15"`
+
+	// Device authorization grant - page where users enter the code shown by CLI/console clients
+	DeviceVerificationURL string `envconfig:"DEVICE_VERIFICATION_URL" default:""`
+
+	// Craft cost rollup - deepest recipe nesting GET /items/:id/craft-cost will expand
+	CraftCostMaxDepth int `envconfig:"CRAFT_COST_MAX_DEPTH" default:"10"`
+
+	// Trader price history - days of price snapshots to keep
+	TraderPriceHistoryDays int `envconfig:"TRADER_PRICE_HISTORY_DAYS" default:"90"`
+
+	// Deep links - app links look like <scheme>://item/arc_alloy; share links use the web
+	// URL instead (e.g. https://arcdb.app/item/arc_alloy) and are omitted when it is unset
+	DeepLinkScheme string `envconfig:"DEEP_LINK_SCHEME" default:"arcdb"`
+	DeepLinkWebURL string `envconfig:"DEEP_LINK_WEB_URL" default:""`
+
+	// Offsite backups - on BackupCron, upload GET /admin/export/all's zip to an S3-compatible
+	// bucket (AWS S3, or GCS through storage.googleapis.com with HMAC keys); disabled when
+	// BackupCron is empty. The endpoint defaults to AWS S3 in BackupS3Region.
+	BackupCron              string `envconfig:"BACKUP_CRON" default:""`
+	BackupS3Endpoint        string `envconfig:"BACKUP_S3_ENDPOINT" default:""`
+	BackupS3Region          string `envconfig:"BACKUP_S3_REGION" default:"us-east-1"`
+	BackupS3Bucket          string `envconfig:"BACKUP_S3_BUCKET" default:""`
+	BackupS3Prefix          string `envconfig:"BACKUP_S3_PREFIX" default:"backups/"`
+	BackupS3AccessKeyID     string `envconfig:"BACKUP_S3_ACCESS_KEY_ID" default:""`
+	BackupS3SecretAccessKey string `envconfig:"BACKUP_S3_SECRET_ACCESS_KEY" default:""`
+
+	// Client telemetry - POST /telemetry/events accepts usage analytics from opted-in users,
+	// limited to TelemetryRateLimit batches per user per window; daily counts are kept for
+	// TelemetryRetentionDays (0 keeps them forever)
+	TelemetryEnabled                bool `envconfig:"TELEMETRY_ENABLED" default:"true"`
+	TelemetryRateLimit              int  `envconfig:"TELEMETRY_RATE_LIMIT" default:"20"`
+	TelemetryRateLimitWindowSeconds int  `envconfig:"TELEMETRY_RATE_LIMIT_WINDOW_SECONDS" default:"3600"`
+	TelemetryRetentionDays          int  `envconfig:"TELEMETRY_RETENTION_DAYS" default:"180"`
+
+	// Audit log retention - on AuditLogPruneCron, delete audit logs older than
+	// AuditLogRetentionDays (0 keeps them forever). Pruned rows are archived first as gzipped
+	// JSON lines to AuditLogArchiveDir and, with AuditLogArchiveS3, to the backup bucket under
+	// AuditLogArchiveS3Prefix.
+	AuditLogRetentionDays   int    `envconfig:"AUDIT_LOG_RETENTION_DAYS" default:"180"`
+	AuditLogPruneCron       string `envconfig:"AUDIT_LOG_PRUNE_CRON" default:"30 3 * * *"`
+	AuditLogArchiveDir      string `envconfig:"AUDIT_LOG_ARCHIVE_DIR" default:""`
+	AuditLogArchiveS3       bool   `envconfig:"AUDIT_LOG_ARCHIVE_S3" default:"false"`
+	AuditLogArchiveS3Prefix string `envconfig:"AUDIT_LOG_ARCHIVE_S3_PREFIX" default:"audit-logs/"`
+
+	// Soft deletes - quests, items, skill nodes, hideout modules and enemy types deleted through
+	// the write API can be restored with POST /admin/:entity/:id/restore until they are purged
+	// SoftDeleteRetentionDays after deletion (0 keeps them forever)
+	SoftDeleteRetentionDays int `envconfig:"SOFT_DELETE_RETENTION_DAYS" default:"30"`
+
+	// Progress snapshots - on ProgressSnapshotCron, copy every user's progress so it can be
+	// restored from GET /me/snapshots, keeping ProgressSnapshotRetentionDays of them (0 turns
+	// snapshots off). They are stored in the database, or under ProgressSnapshotDir when set.
+	ProgressSnapshotCron          string `envconfig:"PROGRESS_SNAPSHOT_CRON" default:"15 4 * * *"`
+	ProgressSnapshotRetentionDays int    `envconfig:"PROGRESS_SNAPSHOT_RETENTION_DAYS" default:"7"`
+	ProgressSnapshotDir           string `envconfig:"PROGRESS_SNAPSHOT_DIR" default:""`
+
+	// Security contact - /.well-known/security.txt lists SecurityContact (comma-separated
+	// mailto: or https: URIs; bare email addresses get mailto:) and is not served when it is
+	// empty. It expires SecurityTxtExpires (RFC 3339), or a year after it is fetched.
+	SecurityContact    string `envconfig:"SECURITY_CONTACT" default:""`
+	SecurityPolicyURL  string `envconfig:"SECURITY_POLICY_URL" default:""`
+	SecurityTxtExpires string `envconfig:"SECURITY_TXT_EXPIRES" default:""`
+
+	// Vulnerability reports - POST /security/reports is limited per IP to
+	// SecurityReportRateLimit reports per window, and requires a captcha token verified
+	// against SecurityReportCaptchaVerifyURL when SecurityReportCaptchaSecret is set
+	SecurityReportRateLimit              int    `envconfig:"SECURITY_REPORT_RATE_LIMIT" default:"5"`
+	SecurityReportRateLimitWindowSeconds int    `envconfig:"SECURITY_REPORT_RATE_LIMIT_WINDOW_SECONDS" default:"3600"`
+	SecurityReportCaptchaSecret          string `envconfig:"SECURITY_REPORT_CAPTCHA_SECRET" default:""`
+	SecurityReportCaptchaVerifyURL       string `envconfig:"SECURITY_REPORT_CAPTCHA_VERIFY_URL" default:"https://challenges.cloudflare.com/turnstile/v0/siteverify"`
+
+	// Repeatable task resets reported by GET /time; the weekly one also starts a new trader
+	// stock rotation. Standard cron expressions in UTC, or another zone with a CRON_TZ= prefix
+	DailyResetCron  string `envconfig:"DAILY_RESET_CRON" default:"0 0 * * *"`
+	WeeklyResetCron string `envconfig:"WEEKLY_RESET_CRON" default:"0 0 * * 1"`
+
+	// Player levels - comma-separated total XP needed to reach level 2, 3, ... (e.g. "1000,2500,4500");
+	// level projections in GET /progress/xp are omitted when unset
+	PlayerLevelXP string `envconfig:"PLAYER_LEVEL_XP" default:""`
+
+	// Shared progress links - HMAC secret for signing tokens and maximum link lifetime
+	ShareLinkSecret   string `envconfig:"SHARE_LINK_SECRET" default:""`
+	ShareLinkTTLHours int    `envconfig:"SHARE_LINK_TTL_HOURS" default:"168"`
+
+	// Extension hooks - path to a YAML file listing Go plugins and HTTP callouts for the
+	// post-sync, pre-write and claim mapping extension points
+	HooksConfig string `envconfig:"HOOKS_CONFIG" default:""`
+
 	// GitHub
 	GitHubToken string `envconfig:"GITHUB_TOKEN" default:""`
+
+	// Branding served from GET /config so dashboards and white-label apps can render a
+	// community's name, logo and colors; support links are comma-separated "label=url"
+	BrandAppName      string `envconfig:"BRAND_APP_NAME" default:"ARC Raiders API"`
+	BrandLogoURL      string `envconfig:"BRAND_LOGO_URL" default:""`
+	BrandAccentColor  string `envconfig:"BRAND_ACCENT_COLOR" default:""` // Hex color, e.g. "#f5a623"
+	BrandSupportLinks string `envconfig:"BRAND_SUPPORT_LINKS" default:""`
+
+	// Shadow traffic - mirror a percentage of GET requests to a secondary deployment (e.g.
+	// the next API version under test) and log responses whose status differs. Credentials
+	// (Authorization, X-API-Key, cookies) are only forwarded when opted in.
+	ShadowTrafficURL                string  `envconfig:"SHADOW_TRAFFIC_URL" default:""`
+	ShadowTrafficPercent            float64 `envconfig:"SHADOW_TRAFFIC_PERCENT" default:"0"`
+	ShadowTrafficForwardCredentials bool    `envconfig:"SHADOW_TRAFFIC_FORWARD_CREDENTIALS" default:"false"`
+
+	// Feature flags exposed to clients (comma-separated, e.g. "progress_sync,new_map_ui")
+	FeatureFlags string `envconfig:"FEATURE_FLAGS" default:""`
 }
 
 func LoadConfig() (*Config, error) {
@@ -91,6 +293,59 @@ func (c *Config) IsDiscordOAuthEnabled() bool {
 	return false
 }
 
+// Sync sources returned by GetSyncSource
+const (
+	SyncSourceGitHub = "github"
+	SyncSourceDir    = "dir"
+	SyncSourceURL    = "url"
+	SyncSourceS3     = "s3"
+)
+
+// GetSyncSource returns where sync fetches data from, rejecting a source without the
+// settings it needs
+func (c *Config) GetSyncSource() (string, error) {
+	switch source := strings.ToLower(c.SyncSource); source {
+	case "", SyncSourceGitHub:
+		return SyncSourceGitHub, nil
+	case SyncSourceDir:
+		if c.SyncSourceDir == "" {
+			return "", fmt.Errorf("SYNC_SOURCE=dir requires SYNC_SOURCE_DIR")
+		}
+		return source, nil
+	case SyncSourceURL:
+		if !isHTTPURL(c.SyncSourceURL) {
+			return "", fmt.Errorf("SYNC_SOURCE=url requires SYNC_SOURCE_URL to be an http(s) URL")
+		}
+		return source, nil
+	case SyncSourceS3:
+		if _, err := c.GetSyncS3Endpoint(); err != nil {
+			return "", err
+		}
+		return source, nil
+	}
+	return "", fmt.Errorf("invalid SYNC_SOURCE %q, expected github, dir, url or s3", c.SyncSource)
+}
+
+// GetSyncS3Endpoint validates the bucket settings of SYNC_SOURCE=s3 and returns its endpoint
+func (c *Config) GetSyncS3Endpoint() (string, error) {
+	if c.SyncS3Bucket == "" || c.SyncS3Key == "" || c.SyncS3AccessKeyID == "" || c.SyncS3SecretAccessKey == "" {
+		return "", fmt.Errorf("SYNC_SOURCE=s3 requires SYNC_S3_BUCKET, SYNC_S3_KEY, SYNC_S3_ACCESS_KEY_ID and SYNC_S3_SECRET_ACCESS_KEY")
+	}
+	if c.SyncS3Endpoint == "" {
+		return "https://s3." + c.SyncS3Region + ".amazonaws.com", nil
+	}
+	if !isHTTPURL(c.SyncS3Endpoint) {
+		return "", fmt.Errorf("invalid SYNC_S3_ENDPOINT %q", c.SyncS3Endpoint)
+	}
+	return c.SyncS3Endpoint, nil
+}
+
+// GetSyncRepoPath returns the data directory of the sync source repository without leading
+// or trailing slashes
+func (c *Config) GetSyncRepoPath() string {
+	return strings.Trim(c.SyncRepoPath, "/")
+}
+
 func (c *Config) GetAllowedOrigins() []string {
 	if c.AllowedOrigins == "" {
 		return []string{}
@@ -105,3 +360,358 @@ func (c *Config) GetAllowedOrigins() []string {
 	}
 	return result
 }
+
+// DefaultCORSHeaders are the request headers frontends may send unless they list their own
+var DefaultCORSHeaders = []string{"Content-Type", "Authorization", "X-API-Key", "X-Requested-With", "X-Request-ID"}
+
+// CORSFrontend is a deployed frontend and the CORS policy its origins get. Origins are
+// exact, or "https://*.example.com" for any subdomain.
+type CORSFrontend struct {
+	Name        string   `json:"name"`
+	Origins     []string `json:"origins"`
+	Headers     []string `json:"headers"`
+	Credentials bool     `json:"credentials"`
+}
+
+// GetCORSFrontends parses CORSFrontends. ALLOWED_ORIGINS is kept as a last frontend named
+// "default" with credentials allowed, as it was before frontends could be named.
+func (c *Config) GetCORSFrontends() ([]CORSFrontend, error) {
+	var frontends []CORSFrontend
+	if strings.TrimSpace(c.CORSFrontends) != "" {
+		if err := json.Unmarshal([]byte(c.CORSFrontends), &frontends); err != nil {
+			return nil, fmt.Errorf("expected a JSON array of frontends: %w", err)
+		}
+	}
+
+	names := make(map[string]bool, len(frontends))
+	for i := range frontends {
+		f := &frontends[i]
+		f.Name = strings.TrimSpace(f.Name)
+		if f.Name == "" || names[f.Name] {
+			return nil, fmt.Errorf("frontend %d needs a unique name", i+1)
+		}
+		names[f.Name] = true
+		if len(f.Origins) == 0 {
+			return nil, fmt.Errorf("frontend %q has no origins", f.Name)
+		}
+		for j, origin := range f.Origins {
+			origin = strings.TrimSpace(origin)
+			if origin == "" || origin == "*" {
+				return nil, fmt.Errorf("frontend %q has an invalid origin %q", f.Name, origin)
+			}
+			f.Origins[j] = origin
+		}
+		if len(f.Headers) == 0 {
+			f.Headers = DefaultCORSHeaders
+		}
+	}
+
+	if origins := c.GetAllowedOrigins(); len(origins) > 0 {
+		if names["default"] {
+			return nil, fmt.Errorf("a frontend named \"default\" can't be combined with ALLOWED_ORIGINS")
+		}
+		frontends = append(frontends, CORSFrontend{
+			Name:        "default",
+			Origins:     origins,
+			Headers:     DefaultCORSHeaders,
+			Credentials: true,
+		})
+	}
+	return frontends, nil
+}
+
+// TLS modes returned by GetTLSMode
+const (
+	TLSModeOff      = ""
+	TLSModeFiles    = "files"
+	TLSModeAutocert = "autocert"
+)
+
+// GetTLSMode reports how the server terminates TLS, rejecting incomplete or conflicting settings
+func (c *Config) GetTLSMode() (string, error) {
+	hasFiles := c.TLSCertFile != "" || c.TLSKeyFile != ""
+	hasAutocert := len(c.GetAutocertDomains()) > 0
+	switch {
+	case hasFiles && hasAutocert:
+		return "", fmt.Errorf("set either TLS_CERT_FILE/TLS_KEY_FILE or TLS_AUTOCERT_DOMAINS, not both")
+	case hasFiles:
+		if c.TLSCertFile == "" || c.TLSKeyFile == "" {
+			return "", fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+		}
+		return TLSModeFiles, nil
+	case hasAutocert:
+		return TLSModeAutocert, nil
+	}
+	if c.TLSRedirectPort != "" {
+		return "", fmt.Errorf("TLS_REDIRECT_PORT requires TLS to be enabled")
+	}
+	return TLSModeOff, nil
+}
+
+// GetAutocertDomains returns the hostnames ACME certificates may be requested for
+func (c *Config) GetAutocertDomains() []string {
+	var domains []string
+	for _, domain := range strings.Split(c.TLSAutocertDomains, ",") {
+		trimmed := strings.TrimSpace(domain)
+		if trimmed != "" {
+			domains = append(domains, trimmed)
+		}
+	}
+	return domains
+}
+
+// GetDeepLinkWebURL returns the base of web share links without a trailing slash, or ""
+// when share links are disabled
+func (c *Config) GetDeepLinkWebURL() (string, error) {
+	if c.DeepLinkWebURL == "" {
+		return "", nil
+	}
+	if !isHTTPURL(c.DeepLinkWebURL) {
+		return "", fmt.Errorf("invalid DEEP_LINK_WEB_URL %q", c.DeepLinkWebURL)
+	}
+	return strings.TrimSuffix(c.DeepLinkWebURL, "/"), nil
+}
+
+// GetBackupEndpoint returns the object storage endpoint backups are uploaded to, or ""
+// when backups are disabled
+func (c *Config) GetBackupEndpoint() (string, error) {
+	if c.BackupCron == "" {
+		return "", nil
+	}
+	return c.backupS3Endpoint("BACKUP_CRON")
+}
+
+// GetAuditLogArchiveEndpoint returns the object storage endpoint pruned audit logs are
+// archived to, or "" when they are not archived to object storage
+func (c *Config) GetAuditLogArchiveEndpoint() (string, error) {
+	if !c.AuditLogArchiveS3 {
+		return "", nil
+	}
+	return c.backupS3Endpoint("AUDIT_LOG_ARCHIVE_S3")
+}
+
+// backupS3Endpoint validates the backup bucket settings the option named setting requires
+func (c *Config) backupS3Endpoint(setting string) (string, error) {
+	if c.BackupS3Bucket == "" || c.BackupS3AccessKeyID == "" || c.BackupS3SecretAccessKey == "" {
+		return "", fmt.Errorf("%s requires BACKUP_S3_BUCKET, BACKUP_S3_ACCESS_KEY_ID and BACKUP_S3_SECRET_ACCESS_KEY", setting)
+	}
+	if c.BackupS3Endpoint == "" {
+		return "https://s3." + c.BackupS3Region + ".amazonaws.com", nil
+	}
+	if !isHTTPURL(c.BackupS3Endpoint) {
+		return "", fmt.Errorf("invalid BACKUP_S3_ENDPOINT %q", c.BackupS3Endpoint)
+	}
+	return c.BackupS3Endpoint, nil
+}
+
+// GetShadowTrafficURL returns the mirror target, or nil when shadow traffic is disabled
+func (c *Config) GetShadowTrafficURL() (*url.URL, error) {
+	if c.ShadowTrafficURL == "" || c.ShadowTrafficPercent == 0 {
+		return nil, nil
+	}
+	if c.ShadowTrafficPercent < 0 || c.ShadowTrafficPercent > 100 {
+		return nil, fmt.Errorf("SHADOW_TRAFFIC_PERCENT must be between 0 and 100")
+	}
+	if !isHTTPURL(c.ShadowTrafficURL) {
+		return nil, fmt.Errorf("invalid SHADOW_TRAFFIC_URL %q", c.ShadowTrafficURL)
+	}
+	return url.Parse(strings.TrimSuffix(c.ShadowTrafficURL, "/"))
+}
+
+// GetFeatureFlags returns the enabled feature flags as a set
+func (c *Config) GetFeatureFlags() map[string]bool {
+	flags := make(map[string]bool)
+	for _, flag := range strings.Split(c.FeatureFlags, ",") {
+		trimmed := strings.TrimSpace(flag)
+		if trimmed != "" {
+			flags[trimmed] = true
+		}
+	}
+	return flags
+}
+
+// GetPlayerLevelThresholds parses PlayerLevelXP into the total XP needed for each level
+// after the first. Thresholds must be positive and strictly increasing.
+func (c *Config) GetPlayerLevelThresholds() ([]int, error) {
+	var thresholds []int
+	for _, entry := range strings.Split(c.PlayerLevelXP, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		xp, err := strconv.Atoi(entry)
+		if err != nil || xp <= 0 {
+			return nil, fmt.Errorf("invalid XP threshold %q", entry)
+		}
+		if n := len(thresholds); n > 0 && xp <= thresholds[n-1] {
+			return nil, fmt.Errorf("XP thresholds must be increasing, got %d after %d", xp, thresholds[n-1])
+		}
+		thresholds = append(thresholds, xp)
+	}
+	return thresholds, nil
+}
+
+// BrandSupportLink is a labelled link shown in the client's help menu
+type BrandSupportLink struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// Branding is the public white-label configuration
+type Branding struct {
+	AppName      string             `json:"app_name"`
+	LogoURL      string             `json:"logo_url,omitempty"`
+	AccentColor  string             `json:"accent_color,omitempty"`
+	SupportLinks []BrandSupportLink `json:"support_links"`
+}
+
+// GetBranding validates and parses the Brand* settings
+func (c *Config) GetBranding() (Branding, error) {
+	branding := Branding{
+		AppName:      strings.TrimSpace(c.BrandAppName),
+		LogoURL:      strings.TrimSpace(c.BrandLogoURL),
+		AccentColor:  strings.TrimSpace(c.BrandAccentColor),
+		SupportLinks: []BrandSupportLink{},
+	}
+
+	if branding.LogoURL != "" && !isHTTPURL(branding.LogoURL) {
+		return Branding{}, fmt.Errorf("invalid logo URL %q", branding.LogoURL)
+	}
+	if branding.AccentColor != "" && !isHexColor(branding.AccentColor) {
+		return Branding{}, fmt.Errorf("invalid accent color %q: expected #rgb or #rrggbb", branding.AccentColor)
+	}
+
+	for _, entry := range strings.Split(c.BrandSupportLinks, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		label, url, ok := strings.Cut(entry, "=")
+		label, url = strings.TrimSpace(label), strings.TrimSpace(url)
+		if !ok || label == "" || !isHTTPURL(url) {
+			return Branding{}, fmt.Errorf("invalid support link %q: expected label=url", entry)
+		}
+		branding.SupportLinks = append(branding.SupportLinks, BrandSupportLink{Label: label, URL: url})
+	}
+	return branding, nil
+}
+
+func isHTTPURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
+}
+
+func isHexColor(s string) bool {
+	if !strings.HasPrefix(s, "#") || (len(s) != 4 && len(s) != 7) {
+		return false
+	}
+	_, err := strconv.ParseUint(s[1:], 16, 32)
+	return err == nil
+}
+
+// SecurityTxt is the content of /.well-known/security.txt (RFC 9116)
+type SecurityTxt struct {
+	Contacts []string
+	Policy   string
+	Expires  *time.Time // Nil means a year after each fetch
+}
+
+// GetSecurityTxt validates the Security* settings. It returns nil when no contact is
+// configured, in which case security.txt is not served.
+func (c *Config) GetSecurityTxt() (*SecurityTxt, error) {
+	txt := &SecurityTxt{Policy: strings.TrimSpace(c.SecurityPolicyURL)}
+	for _, contact := range strings.Split(c.SecurityContact, ",") {
+		contact = strings.TrimSpace(contact)
+		if contact == "" {
+			continue
+		}
+		if !strings.Contains(contact, ":") && strings.Contains(contact, "@") {
+			contact = "mailto:" + contact
+		}
+		if !strings.HasPrefix(contact, "mailto:") && !strings.HasPrefix(contact, "https://") && !strings.HasPrefix(contact, "tel:") {
+			return nil, fmt.Errorf("invalid contact %q: expected a mailto:, https: or tel: URI", contact)
+		}
+		txt.Contacts = append(txt.Contacts, contact)
+	}
+	if len(txt.Contacts) == 0 {
+		return nil, nil
+	}
+	if txt.Policy != "" && !strings.HasPrefix(txt.Policy, "https://") {
+		return nil, fmt.Errorf("invalid policy URL %q: expected https", txt.Policy)
+	}
+	if expires := strings.TrimSpace(c.SecurityTxtExpires); expires != "" {
+		t, err := time.Parse(time.RFC3339, expires)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SECURITY_TXT_EXPIRES %q: expected RFC 3339", expires)
+		}
+		txt.Expires = &t
+	}
+	return txt, nil
+}
+
+// RateLimitTiers are the request limits per window of each kind of client
+type RateLimitTiers struct {
+	Anonymous int
+	User      int
+	APIKey    int
+	Admin     int
+}
+
+// GetRateLimitTiers returns the global rate limit tiers
+func (c *Config) GetRateLimitTiers() RateLimitTiers {
+	return RateLimitTiers{
+		Anonymous: c.RateLimitRequests,
+		User:      c.RateLimitUserRequests,
+		APIKey:    c.RateLimitAPIKeyRequests,
+		Admin:     c.RateLimitAdminRequests,
+	}
+}
+
+// RateLimitRule gives requests under PathPrefix their own rate limit bucket
+type RateLimitRule struct {
+	PathPrefix string
+	Limit      int // 0 means requests under PathPrefix are not rate limited
+	Window     time.Duration
+}
+
+// GetRateLimitRules parses RateLimitRoutes. Rules without an explicit window use
+// RateLimitWindowSeconds. Only /api/v1 is rate limited, so prefixes outside it are rejected
+// rather than silently ignored.
+func (c *Config) GetRateLimitRules() ([]RateLimitRule, error) {
+	var rules []RateLimitRule
+	for _, entry := range strings.Split(c.RateLimitRoutes, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		prefix, spec, ok := strings.Cut(entry, "=")
+		prefix = strings.TrimSpace(prefix)
+		if !ok || !strings.HasPrefix(prefix, "/") {
+			return nil, fmt.Errorf("invalid rate limit rule %q: expected path_prefix=limit[/window_seconds]", entry)
+		}
+		if prefix != "/api/v1" && !strings.HasPrefix(prefix, "/api/v1/") {
+			return nil, fmt.Errorf("invalid rate limit rule %q: only routes under /api/v1 are rate limited", entry)
+		}
+
+		limitStr, windowStr, hasWindow := strings.Cut(strings.TrimSpace(spec), "/")
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid limit in rate limit rule %q", entry)
+		}
+
+		windowSeconds := c.RateLimitWindowSeconds
+		if hasWindow {
+			windowSeconds, err = strconv.Atoi(windowStr)
+			if err != nil || windowSeconds <= 0 {
+				return nil, fmt.Errorf("invalid window in rate limit rule %q", entry)
+			}
+		}
+
+		rules = append(rules, RateLimitRule{
+			PathPrefix: prefix,
+			Limit:      limit,
+			Window:     time.Duration(windowSeconds) * time.Second,
+		})
+	}
+	return rules, nil
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetRateLimitRules(t *testing.T) {
+	cfg := &Config{RateLimitWindowSeconds: 60, RateLimitRoutes: " /api/v1/auth/device/token=60/30, ,/api/v1/time=0"}
+	rules, err := cfg.GetRateLimitRules()
+	if err != nil {
+		t.Fatalf("GetRateLimitRules: %v", err)
+	}
+	want := []RateLimitRule{
+		{PathPrefix: "/api/v1/auth/device/token", Limit: 60, Window: 30 * time.Second},
+		{PathPrefix: "/api/v1/time", Limit: 0, Window: time.Minute},
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("GetRateLimitRules() = %+v, want %+v", rules, want)
+	}
+
+	if rules, err := (&Config{}).GetRateLimitRules(); err != nil || len(rules) != 0 {
+		t.Errorf("expected no rules by default, got %+v, %v", rules, err)
+	}
+
+	for _, routes := range []string{
+		"/api/v1/time",      // no limit
+		"api/v1/time=10",    // not a path
+		"/api/v1/time=-1",   // negative limit
+		"/api/v1/time=10/0", // empty window
+		"/api/v1/time=10/x", // window not a number
+		"/health=0",         // outside the rate limited routes
+		"/api/v1config=10",  // not under /api/v1
+	} {
+		if _, err := (&Config{RateLimitWindowSeconds: 60, RateLimitRoutes: routes}).GetRateLimitRules(); err == nil {
+			t.Errorf("expected an error for %q", routes)
+		}
+	}
+}
+
+func TestGetBranding(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "test")
+	t.Setenv("BRAND_APP_NAME", " Raider Hub ")
+	t.Setenv("BRAND_LOGO_URL", "https://example.com/logo.png")
+	t.Setenv("BRAND_ACCENT_COLOR", "#f5a623")
+	t.Setenv("BRAND_SUPPORT_LINKS", "Discord=https://discord.gg/raiders, Docs = https://example.com/docs")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	branding, err := cfg.GetBranding()
+	if err != nil {
+		t.Fatalf("GetBranding: %v", err)
+	}
+	want := Branding{
+		AppName:     "Raider Hub",
+		LogoURL:     "https://example.com/logo.png",
+		AccentColor: "#f5a623",
+		SupportLinks: []BrandSupportLink{
+			{Label: "Discord", URL: "https://discord.gg/raiders"},
+			{Label: "Docs", URL: "https://example.com/docs"},
+		},
+	}
+	if !reflect.DeepEqual(branding, want) {
+		t.Errorf("GetBranding() = %+v, want %+v", branding, want)
+	}
+
+	if branding, err := (&Config{BrandAppName: "ARC Raiders API"}).GetBranding(); err != nil || branding.SupportLinks == nil {
+		t.Errorf("expected defaults to be valid with an empty link list, got %+v, %v", branding, err)
+	}
+
+	for _, cfg := range []Config{
+		{BrandLogoURL: "logo.png"},
+		{BrandAccentColor: "orange"},
+		{BrandSupportLinks: "Discord"},
+		{BrandSupportLinks: "=https://example.com"},
+		{BrandSupportLinks: "Docs=ftp://example.com"},
+	} {
+		if _, err := cfg.GetBranding(); err == nil {
+			t.Errorf("expected an error for %+v", cfg)
+		}
+	}
+}
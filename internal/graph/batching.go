@@ -0,0 +1,144 @@
+package graph
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// BatchPOST serves a JSON array of operations sent in one POST, so the dashboard can load
+// its first screen over a single request. Operations run in order and the response is an
+// array of their results in the same order; one failing doesn't stop the others.
+//
+// Every operation is held to MaxQueryComplexity on its own by the complexity extension,
+// and their complexities are summed against MaxComplexity so a batch can't cost more than
+// a few plain requests. Each result reports its complexity in extensions.complexity.
+// Register it before transport.POST, which would otherwise claim the request. Bodies over
+// MaxBodyBytes are rejected, whichever of the two transports reads them.
+type BatchPOST struct {
+	MaxOperations int
+	MaxComplexity int
+	MaxBodyBytes  int64
+}
+
+var _ graphql.Transport = BatchPOST{}
+
+// Supports accepts JSON POSTs whose body is an array
+func (t BatchPOST) Supports(r *http.Request) bool {
+	if r.Method != http.MethodPost || r.Header.Get("Upgrade") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" || r.Body == nil {
+		return false
+	}
+
+	// Peek at the first byte that isn't whitespace and leave the rest of the body for
+	// whichever transport ends up reading it
+	body := bufio.NewReader(http.MaxBytesReader(nil, r.Body, t.MaxBodyBytes))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{body, r.Body}
+	for {
+		b, err := body.ReadByte()
+		if err != nil {
+			return false
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			body.UnreadByte()
+			return b == '['
+		}
+	}
+}
+
+func (t BatchPOST) Do(w http.ResponseWriter, r *http.Request, exec graphql.GraphExecutor) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	var batch []*graphql.RawParams
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			writeBatchJSON(w, exec.DispatchError(ctx, gqlerror.List{gqlerror.Errorf("request body is over %d bytes", t.MaxBodyBytes)}))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		writeBatchJSON(w, exec.DispatchError(ctx, gqlerror.List{gqlerror.Errorf("json request body could not be decoded: %v", err)}))
+		return
+	}
+	if len(batch) == 0 || len(batch) > t.MaxOperations {
+		w.WriteHeader(http.StatusBadRequest)
+		writeBatchJSON(w, exec.DispatchError(ctx, gqlerror.List{gqlerror.Errorf("a batch must have 1 to %d operations", t.MaxOperations)}))
+		return
+	}
+
+	responses := make([]*graphql.Response, len(batch))
+	spent := 0
+	for i, params := range batch {
+		responses[i] = t.execute(ctx, r, exec, params, &spent)
+	}
+	writeBatchJSON(w, responses)
+}
+
+// execute runs one operation of a batch, adding its complexity to spent
+func (t BatchPOST) execute(ctx context.Context, r *http.Request, exec graphql.GraphExecutor, params *graphql.RawParams, spent *int) *graphql.Response {
+	if params == nil {
+		return exec.DispatchError(ctx, gqlerror.List{gqlerror.Errorf("each operation must be an object")})
+	}
+	params.Headers = r.Header
+	start := graphql.Now()
+	params.ReadTime = graphql.TraceTiming{Start: start, End: start}
+
+	rc, errs := exec.CreateOperationContext(ctx, params)
+	opCtx := graphql.WithOperationContext(ctx, rc)
+	if errs != nil {
+		return exec.DispatchError(opCtx, errs)
+	}
+	if rc.Operation.Operation == ast.Subscription {
+		return exec.DispatchError(opCtx, gqlerror.List{gqlerror.Errorf("subscriptions can't be batched")})
+	}
+
+	complexity := 0
+	if stats := extension.GetComplexityStats(opCtx); stats != nil {
+		complexity = stats.Complexity
+	}
+	if *spent+complexity > t.MaxComplexity {
+		return exec.DispatchError(opCtx, gqlerror.List{gqlerror.Errorf(
+			"operation has complexity %d, which exceeds the %d left of the batch limit of %d", complexity, t.MaxComplexity-*spent, t.MaxComplexity,
+		)})
+	}
+	*spent += complexity
+
+	responses, ctx := exec.DispatchOperation(ctx, rc)
+	response := responses(ctx)
+	if response == nil {
+		response = &graphql.Response{}
+	}
+	if response.Extensions == nil {
+		response.Extensions = map[string]any{}
+	}
+	response.Extensions["complexity"] = complexity
+	return response
+}
+
+// writeBatchJSON writes a response like gqlgen's transports do, which panic on responses
+// that can't be marshalled
+func writeBatchJSON(w io.Writer, v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Errorf("unable to marshal batch response: %w", err))
+	}
+	w.Write(b)
+}
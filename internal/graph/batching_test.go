@@ -0,0 +1,75 @@
+package graph
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/testserver"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+)
+
+func TestBatchPOST(t *testing.T) {
+	srv := testserver.New()
+	srv.AddTransport(BatchPOST{MaxOperations: 3, MaxComplexity: 10, MaxBodyBytes: 1024})
+	srv.AddTransport(transport.POST{})
+	srv.Use(extension.FixedComplexityLimit(5))
+	srv.SetCalculatedComplexity(4)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+
+	// Single operations still go to POST
+	if w := post(`{"query": "{ name }"}`); w.Code != http.StatusOK || !strings.HasPrefix(w.Body.String(), "{") {
+		t.Fatalf("single operation = %d %s", w.Code, w.Body.String())
+	}
+
+	w := post(`[{"query": "{ name }"}, {"query": "{ name }"}, {"query": "{ name }"}]`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("batch = %d %s", w.Code, w.Body.String())
+	}
+	var results []struct {
+		Data       map[string]string `json:"data"`
+		Errors     []struct{ Message string }
+		Extensions map[string]int `json:"extensions"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatalf("decode %s: %v", w.Body.String(), err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	for i, result := range results[:2] {
+		if result.Data["name"] != "test" || result.Extensions["complexity"] != 4 {
+			t.Errorf("result %d = %+v, want data and complexity 4", i, result)
+		}
+	}
+	// 4 + 4 + 4 is over the batch limit of 10
+	if len(results[2].Errors) != 1 || !strings.Contains(results[2].Errors[0].Message, "batch limit") {
+		t.Errorf("third operation should exceed the batch limit, got %+v", results[2])
+	}
+
+	if w := post(`[{"query": "{ name }"}, {"query": "{ name }"}, {"query": "{ name }"}, {"query": "{ name }"}]`); w.Code != http.StatusBadRequest {
+		t.Errorf("too many operations = %d, want 400", w.Code)
+	}
+	if w := post(`[]`); w.Code != http.StatusBadRequest {
+		t.Errorf("empty batch = %d, want 400", w.Code)
+	}
+
+	// Bodies over the limit are cut off, batched or not
+	padding := strings.Repeat(" ", 1024)
+	if w := post(`[{"query": "{ name }"}` + padding + `]`); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("oversized batch = %d, want 413", w.Code)
+	}
+	if w := post(padding + `{"query": "{ name }"}`); !strings.Contains(w.Body.String(), "request body too large") {
+		t.Errorf("oversized operation = %d %s, want a body too large error", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,203 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+// LoadersContextKey is the key for storing the per-request dataloaders in context
+const LoadersContextKey = "dataloaders"
+
+const (
+	// loaderWait is how long a loader collects keys before fetching them in one query
+	loaderWait = 2 * time.Millisecond
+
+	// loaderMaxBatch caps the keys per query; a full batch is fetched right away
+	loaderMaxBatch = 500
+)
+
+// loaderResult is the outcome of one key, shared by every Load of that key in a request
+type loaderResult[V any] struct {
+	done  chan struct{}
+	value V
+	found bool
+	err   error
+}
+
+type loaderBatch[K comparable, V any] struct {
+	results    map[K]*loaderResult[V]
+	dispatched bool
+}
+
+// loader collapses the Load calls resolvers make while executing one request into
+// batched fetches, and caches results for the rest of the request. This turns the N+1
+// repository calls of nested queries into a single query per level.
+type loader[K comparable, V any] struct {
+	fetch func(keys []K) (map[K]V, error)
+	mu    sync.Mutex
+	cache map[K]*loaderResult[V]
+	batch *loaderBatch[K, V]
+}
+
+func newLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{fetch: fetch, cache: make(map[K]*loaderResult[V])}
+}
+
+// Load returns the value for key; found is false when it doesn't exist
+func (l *loader[K, V]) Load(ctx context.Context, key K) (value V, found bool, err error) {
+	return l.enqueue(key).wait(ctx)
+}
+
+// LoadMany returns the values of the keys that exist, in key order, from a single batch
+func (l *loader[K, V]) LoadMany(ctx context.Context, keys []K) ([]V, error) {
+	results := make([]*loaderResult[V], len(keys))
+	for i, key := range keys {
+		results[i] = l.enqueue(key)
+	}
+	values := make([]V, 0, len(keys))
+	for _, r := range results {
+		value, found, err := r.wait(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+func (l *loader[K, V]) enqueue(key K) *loaderResult[V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if r, ok := l.cache[key]; ok {
+		return r
+	}
+	r := &loaderResult[V]{done: make(chan struct{})}
+	l.cache[key] = r
+
+	if l.batch == nil {
+		b := &loaderBatch[K, V]{results: make(map[K]*loaderResult[V])}
+		l.batch = b
+		time.AfterFunc(loaderWait, func() { l.dispatch(b) })
+	}
+	l.batch.results[key] = r
+	if len(l.batch.results) >= loaderMaxBatch {
+		go l.dispatch(l.batch)
+		l.batch = nil
+	}
+	return r
+}
+
+func (l *loader[K, V]) dispatch(b *loaderBatch[K, V]) {
+	l.mu.Lock()
+	if b.dispatched {
+		l.mu.Unlock()
+		return
+	}
+	b.dispatched = true
+	if l.batch == b {
+		l.batch = nil
+	}
+	l.mu.Unlock()
+
+	keys := make([]K, 0, len(b.results))
+	for key := range b.results {
+		keys = append(keys, key)
+	}
+	values, err := l.fetch(keys)
+	for key, r := range b.results {
+		r.value, r.found = values[key]
+		r.err = err
+		close(r.done)
+	}
+}
+
+func (r *loaderResult[V]) wait(ctx context.Context) (V, bool, error) {
+	select {
+	case <-r.done:
+		return r.value, r.found, r.err
+	case <-ctx.Done():
+		var zero V
+		return zero, false, ctx.Err()
+	}
+}
+
+// Loaders holds the dataloaders of one GraphQL request
+type Loaders struct {
+	ItemsByExternalID *loader[string, *models.Item]
+}
+
+func NewLoaders(itemRepo *repository.ItemRepository) *Loaders {
+	return &Loaders{
+		ItemsByExternalID: newLoader(func(externalIDs []string) (map[string]*models.Item, error) {
+			items, err := itemRepo.FindByExternalIDs(externalIDs)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[string]*models.Item, len(items))
+			for i := range items {
+				byID[items[i].ExternalID] = &items[i]
+			}
+			return byID, nil
+		}),
+	}
+}
+
+// DataloaderMiddleware gives every GraphQL request its own loaders, so cached results
+// never leak between requests or users
+func DataloaderMiddleware(itemRepo *repository.ItemRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), LoadersContextKey, NewLoaders(itemRepo))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// LoadersFromContext returns the request's loaders, or nil outside a GraphQL request
+func LoadersFromContext(ctx context.Context) *Loaders {
+	loaders, _ := ctx.Value(LoadersContextKey).(*Loaders)
+	return loaders
+}
+
+// ItemByExternalID loads an item through the request's dataloader, falling back to a
+// direct lookup without one. Field resolvers such as blueprint progress items use it
+// instead of the repository.
+func (r *Resolver) ItemByExternalID(ctx context.Context, externalID string) (*models.Item, error) {
+	loaders := LoadersFromContext(ctx)
+	if loaders == nil {
+		items, err := r.ItemsByExternalIDs(ctx, []string{externalID})
+		if err != nil || len(items) == 0 {
+			return nil, err
+		}
+		return items[0], nil
+	}
+	item, found, err := loaders.ItemsByExternalID.Load(ctx, externalID)
+	if err != nil || !found {
+		return nil, err
+	}
+	return item, nil
+}
+
+// ItemsByExternalIDs loads the existing items among externalIDs, in order, in one batch
+func (r *Resolver) ItemsByExternalIDs(ctx context.Context, externalIDs []string) ([]*models.Item, error) {
+	loaders := LoadersFromContext(ctx)
+	if loaders == nil {
+		items, err := r.itemRepo.FindByExternalIDs(externalIDs)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]*models.Item, len(items))
+		for i := range items {
+			result[i] = &items[i]
+		}
+		return result, nil
+	}
+	return loaders.ItemsByExternalID.LoadMany(ctx, externalIDs)
+}
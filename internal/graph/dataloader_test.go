@@ -0,0 +1,136 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestLoaderBatchesAndCaches(t *testing.T) {
+	fetches := 0
+	l := newLoader(func(keys []int) (map[int]string, error) {
+		fetches++
+		values := make(map[int]string, len(keys))
+		for _, key := range keys {
+			if key != 3 {
+				values[key] = "v"
+			}
+		}
+		return values, nil
+	})
+
+	values, err := l.LoadMany(context.Background(), []int{0, 1, 2, 3, 4})
+	if err != nil || len(values) != 4 {
+		t.Fatalf("LoadMany = %v, %v; want 4 values", values, err)
+	}
+	if fetches != 1 {
+		t.Errorf("expected 1 batched fetch, got %d", fetches)
+	}
+
+	// Cached keys, including missing ones, don't trigger another fetch
+	if _, found, err := l.Load(context.Background(), 3); found || err != nil {
+		t.Errorf("Load(3) = found %v, err %v; want missing", found, err)
+	}
+	if _, found, _ := l.Load(context.Background(), 1); !found {
+		t.Error("Load(1) should be found")
+	}
+	if fetches != 1 {
+		t.Errorf("expected cached results, got %d fetches", fetches)
+	}
+}
+
+// dryRunConn stands in for a database connection that DryRun never uses
+type dryRunConn struct{}
+
+func (dryRunConn) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, nil
+}
+
+func (dryRunConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return nil, nil
+}
+
+func (dryRunConn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, nil
+}
+
+func (dryRunConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return nil
+}
+
+// countingLogger counts the statements gorm builds; with DryRun none reach a database
+type countingLogger struct {
+	logger.Interface
+	mu         sync.Mutex
+	statements []string
+}
+
+func (l *countingLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	l.mu.Lock()
+	l.statements = append(l.statements, sql)
+	l.mu.Unlock()
+}
+
+func TestItemFieldResolversShareOneQuery(t *testing.T) {
+	log := &countingLogger{Interface: logger.Discard}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: dryRunConn{}}), &gorm.Config{DryRun: true, Logger: log})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	itemRepo := repository.NewItemRepository(&repository.DB{DB: db}, nil)
+	resolver := &Resolver{itemRepo: itemRepo}
+	ctx := context.WithValue(context.Background(), LoadersContextKey, NewLoaders(itemRepo))
+
+	quests := []models.Quest{
+		{Data: models.JSONB{"rewardItemIds": []interface{}{"rusty-gear", "wires"}}},
+		{Data: models.JSONB{"rewardItemIds": []interface{}{map[string]interface{}{"itemId": "wires", "quantity": 2.0}}}},
+		{Data: models.JSONB{"rewardItemIds": []interface{}{"battery"}}},
+	}
+	blueprints := []models.UserBlueprintProgress{{ItemExternalID: "blueprint-a"}, {ItemExternalID: "rusty-gear"}}
+
+	// The executor resolves the fields of list elements concurrently, like this
+	var wg sync.WaitGroup
+	errs := make(chan error, len(quests)+len(blueprints))
+	for i := range quests {
+		wg.Add(1)
+		go func(quest *models.Quest) {
+			defer wg.Done()
+			_, err := resolver.Quest().RewardItems(ctx, quest)
+			errs <- err
+		}(&quests[i])
+	}
+	for i := range blueprints {
+		wg.Add(1)
+		go func(progress *models.UserBlueprintProgress) {
+			defer wg.Done()
+			_, err := resolver.BlueprintProgress().Item(ctx, progress)
+			errs <- err
+		}(&blueprints[i])
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("resolver: %v", err)
+		}
+	}
+
+	if len(log.statements) != 1 {
+		t.Fatalf("expected the item fields to share 1 query, got %d: %v", len(log.statements), log.statements)
+	}
+	for _, id := range []string{"rusty-gear", "wires", "battery", "blueprint-a"} {
+		if !strings.Contains(log.statements[0], "'"+id+"'") {
+			t.Errorf("expected %s in the batched query: %s", id, log.statements[0])
+		}
+	}
+}
@@ -4,52 +4,41 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/gin-gonic/gin"
 	"github.com/mat/arcapi/internal/config"
+	"github.com/mat/arcapi/internal/graph/generated"
 	"github.com/mat/arcapi/internal/middleware"
 	"github.com/mat/arcapi/internal/models"
 	"github.com/mat/arcapi/internal/services"
+	"github.com/vektah/gqlparser/v2/ast"
 )
 
 // GraphQLHandler handles GraphQL requests
-// Note: srv will be set after code generation
 type GraphQLHandler struct {
-	srv         interface{} // Will be *handler.Server after code generation
-	authService *services.AuthService
+	srv *handler.Server
 }
 
-// NewGraphQLHandler creates a new GraphQL handler with security middleware
-// NOTE: This function requires gqlgen code generation to work.
-// After running `go run github.com/99designs/gqlgen generate`, update this function
-// to use the generated NewExecutableSchema function.
-//
-// Example after generation:
-//
-//	cfg := Config{Resolvers: resolver}
-//	srv := handler.NewDefaultServer(NewExecutableSchema(cfg))
-//	setupSecurityMiddleware(srv, authService)
-//	return &GraphQLHandler{srv: srv, authService: authService}
-func NewGraphQLHandler(resolver *Resolver, authService *services.AuthService) *GraphQLHandler {
-	// TODO: After code generation, uncomment and update:
-	// cfg := Config{Resolvers: resolver}
-	// srv := handler.NewDefaultServer(NewExecutableSchema(cfg))
-	// setupSecurityMiddleware(srv, authService)
-	// return &GraphQLHandler{srv: srv, authService: authService}
-
-	// Temporary: return nil until code is generated
-	// Use NewGraphQLHandlerSimple for a placeholder implementation
-	return nil
+// NewGraphQLHandler creates a GraphQL handler serving the generated schema with security
+// middleware and persisted queries. Requests must already be authenticated by
+// GraphQLAuthMiddleware.
+func NewGraphQLHandler(resolver *Resolver, persistedQueries *PersistedQueryStore) *GraphQLHandler {
+	srv := handler.New(generated.NewExecutableSchema(generated.Config{Resolvers: resolver}))
+	setupSecurityMiddleware(srv)
+	setupPersistedQueries(srv, persistedQueries)
+	return &GraphQLHandler{srv: srv}
 }
 
-// GraphQLHandler handles POST requests to /graphql
+// GraphQLHandler serves queries over POST and subscription upgrades over GET
 func (h *GraphQLHandler) GraphQLHandler(c *gin.Context) {
-	// This will be implemented after code generation
-	// For now, return error
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error": "GraphQL handler not initialized. Run: make generate-graphql",
-	})
+	h.srv.ServeHTTP(c.Writer, c.Request)
 }
 
 // PlaygroundHandler serves GraphQL playground (only in development)
@@ -59,6 +48,46 @@ func (h *GraphQLHandler) PlaygroundHandler(c *gin.Context) {
 	playground.Handler("GraphQL Playground", "/api/v1/graphql").ServeHTTP(c.Writer, c.Request)
 }
 
+// setupSecurityMiddleware configures security middleware for GraphQL
+func setupSecurityMiddleware(srv *handler.Server) {
+	// Add query complexity analysis
+	srv.Use(extension.FixedComplexityLimit(MaxQueryComplexity))
+
+	// Add query caching (LRU cache for parsed queries)
+	// Cache stores *ast.QueryDocument objects
+	srv.SetQueryCache(lru.New[*ast.QueryDocument](1000))
+
+	// Configure transports (only POST for security - no GET to prevent CSRF). Batches
+	// come first, as POST would take them for a single operation.
+	srv.AddTransport(BatchPOST{MaxOperations: MaxBatchOperations, MaxComplexity: MaxBatchComplexity, MaxBodyBytes: MaxRequestBodyBytes})
+	srv.AddTransport(transport.POST{})
+	// WebSocket carries subscriptions. The upgrade request is authenticated like any
+	// other, and the connection keeps its user.
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+		InitFunc:              websocketInitFunc,
+	})
+
+	srv.Use(extension.Introspection{})
+
+	// Validate operations before execution
+	srv.AroundOperations(func(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+		if err := ValidateOperation(ctx); err != nil {
+			return graphql.OneShot(graphql.ErrorResponse(ctx, "%s", err.Error()))
+		}
+		return next(ctx)
+	})
+}
+
+// websocketInitFunc accepts a subscription connection once the user authenticated on the
+// upgrade request is in its context
+func websocketInitFunc(ctx context.Context, initPayload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+	if _, err := RequireAuth(ctx); err != nil {
+		return ctx, nil, err
+	}
+	return ctx, nil, nil
+}
+
 // GraphQLAuthMiddleware validates API requests before hitting the GraphQL handler
 func GraphQLAuthMiddleware(authService *services.AuthService, dataCacheService *services.DataCacheService, cfg *config.Config, supabaseAuthService *services.SupabaseAuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -69,10 +98,14 @@ func GraphQLAuthMiddleware(authService *services.AuthService, dataCacheService *
 			return
 		}
 
-		// Add user to Gin context (will be accessible in GraphQL resolvers via request context)
+		// Add user to the Gin context, and to the request context for the resolvers
 		c.Set("user", user)
 		c.Set("user_id", user.ID)
 		c.Set("token", token)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), UserContextKey, user))
+		if !middleware.ApplyRateLimit(c, user) {
+			return
+		}
 
 		c.Next()
 	}
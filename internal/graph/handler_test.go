@@ -0,0 +1,36 @@
+package graph
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGraphQLRoutesRequireCredentials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	auth := GraphQLAuthMiddleware(nil, nil, nil, nil)
+	served := false
+	serve := func(c *gin.Context) { served = true }
+	r.POST("/graphql", auth, serve)
+	r.GET("/graphql", auth, serve)
+
+	for _, method := range []string{http.MethodPost, http.MethodGet} {
+		req := httptest.NewRequest(method, "/graphql", strings.NewReader(`{"query": "{ me { id } }"}`))
+		if method == http.MethodGet {
+			req.Header.Set("Connection", "Upgrade")
+			req.Header.Set("Upgrade", "websocket")
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s /graphql without credentials = %d, want 401", method, w.Code)
+		}
+	}
+	if served {
+		t.Error("a request without credentials reached the GraphQL handler")
+	}
+}
@@ -24,6 +24,8 @@ func SetupGraphQLRoutes(
 	blueprintProgressRepo *repository.UserBlueprintProgressRepository,
 	authService *services.AuthService,
 	dataCacheService *services.DataCacheService,
+	eventBus *services.EventBus,
+	persistedQueries *PersistedQueryStore,
 	cfg *config.Config,
 	supabaseAuthService *services.SupabaseAuthService,
 ) {
@@ -42,26 +44,17 @@ func SetupGraphQLRoutes(
 		blueprintProgressRepo,
 		authService,
 		dataCacheService,
+		eventBus,
 	)
 
-	// Try to create GraphQL handler (will fail if code not generated)
-	graphqlHandler := NewGraphQLHandler(resolver, authService)
+	graphqlHandler := NewGraphQLHandler(resolver, persistedQueries)
+	auth := GraphQLAuthMiddleware(authService, dataCacheService, cfg, supabaseAuthService)
 
-	// If handler creation failed, use simple handler
-	if graphqlHandler == nil {
-		simpleHandler := NewGraphQLHandlerSimple(resolver, authService)
-
-		// GraphQL endpoint (POST only for security)
-		r.POST("/graphql", GraphQLAuthMiddleware(authService, dataCacheService, cfg, supabaseAuthService), simpleHandler.GraphQLHandler)
-
-		// GraphQL Playground (development only - consider protecting with admin auth)
-		r.GET("/graphql/playground", simpleHandler.PlaygroundHandler)
-
-		return
-	}
-
-	// GraphQL endpoint (POST only for security)
-	r.POST("/graphql", GraphQLAuthMiddleware(authService, dataCacheService, cfg, supabaseAuthService), graphqlHandler.GraphQLHandler)
+	// GraphQL endpoint: queries over POST, subscriptions over a WebSocket upgraded from GET.
+	// Both authenticate with the Authorization or X-API-Key header. A connection outlives
+	// the per-request dataloaders, so subscriptions go without them.
+	r.POST("/graphql", auth, DataloaderMiddleware(itemRepo), graphqlHandler.GraphQLHandler)
+	r.GET("/graphql", auth, graphqlHandler.GraphQLHandler)
 
 	// GraphQL Playground (development only - consider protecting with admin auth)
 	r.GET("/graphql/playground", graphqlHandler.PlaygroundHandler)
@@ -2,5 +2,84 @@
 
 package model
 
+import (
+	"github.com/mat/arcapi/internal/models"
+)
+
+type AlertConnection struct {
+	Edges    []*AlertEdge `json:"edges"`
+	PageInfo *PageInfo    `json:"pageInfo"`
+}
+
+type AlertEdge struct {
+	Node   *models.Alert `json:"node"`
+	Cursor string        `json:"cursor"`
+}
+
+type EnemyTypeConnection struct {
+	Edges    []*EnemyTypeEdge `json:"edges"`
+	PageInfo *PageInfo        `json:"pageInfo"`
+}
+
+type EnemyTypeEdge struct {
+	Node   *models.EnemyType `json:"node"`
+	Cursor string            `json:"cursor"`
+}
+
+type HideoutModuleConnection struct {
+	Edges    []*HideoutModuleEdge `json:"edges"`
+	PageInfo *PageInfo            `json:"pageInfo"`
+}
+
+type HideoutModuleEdge struct {
+	Node   *models.HideoutModule `json:"node"`
+	Cursor string                `json:"cursor"`
+}
+
+type ItemConnection struct {
+	Edges    []*ItemEdge `json:"edges"`
+	PageInfo *PageInfo   `json:"pageInfo"`
+}
+
+type ItemEdge struct {
+	Node   *models.Item `json:"node"`
+	Cursor string       `json:"cursor"`
+}
+
+type PageInfo struct {
+	Total   int  `json:"total"`
+	Limit   int  `json:"limit"`
+	Offset  int  `json:"offset"`
+	HasMore bool `json:"hasMore"`
+}
+
+type PaginationInput struct {
+	Limit  *int `json:"limit,omitempty"`
+	Offset *int `json:"offset,omitempty"`
+}
+
 type Query struct {
 }
+
+type QuestConnection struct {
+	Edges    []*QuestEdge `json:"edges"`
+	PageInfo *PageInfo    `json:"pageInfo"`
+}
+
+type QuestEdge struct {
+	Node   *models.Quest `json:"node"`
+	Cursor string        `json:"cursor"`
+}
+
+type SkillNodeConnection struct {
+	Edges    []*SkillNodeEdge `json:"edges"`
+	PageInfo *PageInfo        `json:"pageInfo"`
+}
+
+type SkillNodeEdge struct {
+	Node   *models.SkillNode `json:"node"`
+	Cursor string            `json:"cursor"`
+}
+
+type Subscription struct {
+}
@@ -0,0 +1,154 @@
+package graph
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/mat/arcapi/internal/config"
+	"github.com/mat/arcapi/internal/services"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// persistedQueryKeyPrefix namespaces persisted query documents in Redis
+const persistedQueryKeyPrefix = "graphql:apq:"
+
+// persistedQueryMemorySize caps how many client-registered documents are kept in memory,
+// dropping the least recently used, so clients can't grow it without bound
+const persistedQueryMemorySize = 1000
+
+// PersistedQueryStore holds GraphQL documents by their SHA-256 hash for automatic
+// persisted queries (APQ), so clients can send the hash instead of the full document.
+// Client-registered documents live in Redis so every instance shares them, or in a
+// bounded in-memory LRU without Redis. Documents from the GRAPHQL_PERSISTED_QUERIES_FILE
+// manifest are all kept in memory, and in allow-list mode they are the only ones accepted.
+type PersistedQueryStore struct {
+	cacheService  *services.CacheService
+	ttl           time.Duration
+	allowlistOnly bool
+	manifest      map[string]string
+	registered    *lru.LRU[string]
+}
+
+func NewPersistedQueryStore(cacheService *services.CacheService, cfg *config.Config) (*PersistedQueryStore, error) {
+	store := &PersistedQueryStore{
+		cacheService:  cacheService,
+		ttl:           time.Duration(cfg.GraphQLAPQTTLHours) * time.Hour,
+		allowlistOnly: cfg.GraphQLPersistedOnly,
+		manifest:      make(map[string]string),
+		registered:    lru.New[string](persistedQueryMemorySize),
+	}
+	if cfg.GraphQLPersistedQueriesFile == "" {
+		if store.allowlistOnly {
+			return nil, fmt.Errorf("GRAPHQL_PERSISTED_ONLY requires GRAPHQL_PERSISTED_QUERIES_FILE")
+		}
+		return store, nil
+	}
+
+	count, err := store.loadManifest(cfg.GraphQLPersistedQueriesFile)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Loaded %d persisted GraphQL queries", count)
+	return store, nil
+}
+
+// loadManifest registers the documents of a {"<sha256>": "<query>"} manifest, as
+// generated by client build tooling
+func (s *PersistedQueryStore) loadManifest(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read persisted queries: %w", err)
+	}
+	var manifest map[string]string
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return 0, fmt.Errorf("failed to parse persisted queries: %w", err)
+	}
+	for hash, query := range manifest {
+		if persistedQueryHash(query) != hash {
+			return 0, fmt.Errorf("persisted query %s does not match its hash", hash)
+		}
+		s.manifest[hash] = query
+	}
+	return len(manifest), nil
+}
+
+// Get implements graphql.Cache
+func (s *PersistedQueryStore) Get(ctx context.Context, hash string) (string, bool) {
+	if query, ok := s.manifest[hash]; ok || s.allowlistOnly {
+		return query, ok
+	}
+	if s.cacheService != nil {
+		if data, err := s.cacheService.Get(persistedQueryKeyPrefix + hash); err == nil {
+			return string(data), true
+		}
+	}
+	return s.registered.Get(ctx, hash)
+}
+
+// Add implements graphql.Cache. The APQ extension has already checked that hash matches
+// the query. In allow-list mode nothing is registered.
+func (s *PersistedQueryStore) Add(ctx context.Context, hash string, query string) {
+	if s.allowlistOnly {
+		return
+	}
+	if s.cacheService != nil {
+		err := s.cacheService.Set(persistedQueryKeyPrefix+hash, []byte(query), s.ttl)
+		if err == nil {
+			return
+		}
+		log.Printf("Warning: Failed to store persisted query in Redis, keeping it in memory: %v", err)
+	}
+	s.registered.Add(ctx, hash, query)
+}
+
+// persistedQueryAllowlist rejects operations that don't reference a known persisted query
+type persistedQueryAllowlist struct {
+	store *PersistedQueryStore
+}
+
+func (a persistedQueryAllowlist) ExtensionName() string {
+	return "PersistedQueryAllowlist"
+}
+
+func (a persistedQueryAllowlist) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (a persistedQueryAllowlist) MutateOperationParameters(ctx context.Context, rawParams *graphql.RawParams) *gqlerror.Error {
+	ext, _ := rawParams.Extensions["persistedQuery"].(map[string]interface{})
+	hash, _ := ext["sha256Hash"].(string)
+	if hash == "" {
+		return gqlerror.Errorf("only persisted queries are allowed")
+	}
+	if _, ok := a.store.Get(ctx, hash); !ok {
+		return gqlerror.Errorf("unknown persisted query")
+	}
+	return nil
+}
+
+// setupPersistedQueries enables APQ on srv and, in allow-list mode, rejects any
+// operation that isn't a registered persisted query
+func setupPersistedQueries(srv *handler.Server, store *PersistedQueryStore) {
+	if store == nil {
+		return
+	}
+	if store.allowlistOnly {
+		srv.Use(persistedQueryAllowlist{store: store})
+	}
+	srv.Use(extension.AutomaticPersistedQuery{Cache: store})
+}
+
+func persistedQueryHash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,100 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/mat/arcapi/internal/config"
+)
+
+func TestPersistedQueryAllowlist(t *testing.T) {
+	query := "{ health }"
+	hash := persistedQueryHash(query)
+	path := filepath.Join(t.TempDir(), "queries.json")
+	if err := os.WriteFile(path, []byte(`{"`+hash+`": "{ health }"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewPersistedQueryStore(nil, &config.Config{GraphQLPersistedOnly: true, GraphQLPersistedQueriesFile: path})
+	if err != nil {
+		t.Fatalf("NewPersistedQueryStore: %v", err)
+	}
+	store.Add(context.Background(), persistedQueryHash("{ me { id } }"), "{ me { id } }")
+
+	allowlist := persistedQueryAllowlist{store: store}
+	params := func(hash string) *graphql.RawParams {
+		return &graphql.RawParams{Extensions: map[string]interface{}{
+			"persistedQuery": map[string]interface{}{"version": 1, "sha256Hash": hash},
+		}}
+	}
+	if err := allowlist.MutateOperationParameters(context.Background(), params(hash)); err != nil {
+		t.Errorf("manifest query rejected: %v", err)
+	}
+	if err := allowlist.MutateOperationParameters(context.Background(), params(persistedQueryHash("{ me { id } }"))); err == nil {
+		t.Error("client-registered query should be rejected in allow-list mode")
+	}
+	if err := allowlist.MutateOperationParameters(context.Background(), &graphql.RawParams{Query: query}); err == nil {
+		t.Error("full query without a hash should be rejected in allow-list mode")
+	}
+}
+
+func TestPersistedQueryManifestHashMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queries.json")
+	if err := os.WriteFile(path, []byte(`{"deadbeef": "{ health }"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewPersistedQueryStore(nil, &config.Config{GraphQLPersistedQueriesFile: path}); err == nil {
+		t.Error("expected an error for a manifest entry that doesn't match its hash")
+	}
+}
+
+func TestPersistedQueryMemoryIsBounded(t *testing.T) {
+	store, err := NewPersistedQueryStore(nil, &config.Config{})
+	if err != nil {
+		t.Fatalf("NewPersistedQueryStore: %v", err)
+	}
+
+	ctx := context.Background()
+	query := func(i int) string { return fmt.Sprintf("{ item(id: %d) { id } }", i) }
+	for i := 0; i <= persistedQueryMemorySize; i++ {
+		store.Add(ctx, persistedQueryHash(query(i)), query(i))
+	}
+
+	if _, ok := store.Get(ctx, persistedQueryHash(query(0))); ok {
+		t.Error("expected the least recently registered query to be evicted")
+	}
+	if got, ok := store.Get(ctx, persistedQueryHash(query(persistedQueryMemorySize))); !ok || got != query(persistedQueryMemorySize) {
+		t.Errorf("expected the latest query to be kept, got %q", got)
+	}
+}
+
+func TestGraphQLHandlerServesPersistedQueries(t *testing.T) {
+	query := "{ health }"
+	hash := persistedQueryHash(query)
+	path := filepath.Join(t.TempDir(), "queries.json")
+	if err := os.WriteFile(path, []byte(`{"`+hash+`": "{ health }"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewPersistedQueryStore(nil, &config.Config{GraphQLPersistedOnly: true, GraphQLPersistedQueriesFile: path})
+	if err != nil {
+		t.Fatalf("NewPersistedQueryStore: %v", err)
+	}
+	c := client.New(NewGraphQLHandler(&Resolver{}, store).srv)
+	persisted := client.Extensions(map[string]any{
+		"persistedQuery": map[string]any{"version": 1, "sha256Hash": hash},
+	})
+
+	var resp struct{ Health string }
+	if err := c.Post("", &resp, persisted); err != nil || resp.Health != "ok" {
+		t.Errorf("persisted query by hash = %q, %v; want ok", resp.Health, err)
+	}
+	if err := c.Post(query, &resp); err == nil || !strings.Contains(err.Error(), "only persisted queries are allowed") {
+		t.Errorf("expected a full query to be rejected in allow-list mode, got %v", err)
+	}
+}
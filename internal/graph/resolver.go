@@ -1,8 +1,14 @@
 package graph
 
 import (
+	"errors"
+	"strconv"
+
+	"github.com/mat/arcapi/internal/graph/model"
+	"github.com/mat/arcapi/internal/middleware"
 	"github.com/mat/arcapi/internal/repository"
 	"github.com/mat/arcapi/internal/services"
+	"gorm.io/gorm"
 )
 
 // This file will be automatically regenerated based on the schema, any resolver implementations
@@ -12,21 +18,22 @@ import (
 // Resolver is the root resolver
 type Resolver struct {
 	// Repositories
-	userRepo            *repository.UserRepository
-	questRepo           *repository.QuestRepository
-	itemRepo            *repository.ItemRepository
-	skillNodeRepo       *repository.SkillNodeRepository
-	hideoutModuleRepo   *repository.HideoutModuleRepository
-	enemyTypeRepo       *repository.EnemyTypeRepository
-	alertRepo           *repository.AlertRepository
-	questProgressRepo   *repository.UserQuestProgressRepository
+	userRepo                  *repository.UserRepository
+	questRepo                 *repository.QuestRepository
+	itemRepo                  *repository.ItemRepository
+	skillNodeRepo             *repository.SkillNodeRepository
+	hideoutModuleRepo         *repository.HideoutModuleRepository
+	enemyTypeRepo             *repository.EnemyTypeRepository
+	alertRepo                 *repository.AlertRepository
+	questProgressRepo         *repository.UserQuestProgressRepository
 	hideoutModuleProgressRepo *repository.UserHideoutModuleProgressRepository
 	skillNodeProgressRepo     *repository.UserSkillNodeProgressRepository
 	blueprintProgressRepo     *repository.UserBlueprintProgressRepository
-	
+
 	// Services
 	authService      *services.AuthService
 	dataCacheService *services.DataCacheService
+	eventBus         eventSubscriber
 }
 
 // NewResolver creates a new resolver with all dependencies
@@ -44,6 +51,7 @@ func NewResolver(
 	blueprintProgressRepo *repository.UserBlueprintProgressRepository,
 	authService *services.AuthService,
 	dataCacheService *services.DataCacheService,
+	eventBus *services.EventBus,
 ) *Resolver {
 	return &Resolver{
 		userRepo:                  userRepo,
@@ -53,12 +61,63 @@ func NewResolver(
 		hideoutModuleRepo:         hideoutModuleRepo,
 		enemyTypeRepo:             enemyTypeRepo,
 		alertRepo:                 alertRepo,
-		questProgressRepo:          questProgressRepo,
+		questProgressRepo:         questProgressRepo,
 		hideoutModuleProgressRepo: hideoutModuleProgressRepo,
-		skillNodeProgressRepo:      skillNodeProgressRepo,
-		blueprintProgressRepo:      blueprintProgressRepo,
-		authService:                authService,
-		dataCacheService:           dataCacheService,
+		skillNodeProgressRepo:     skillNodeProgressRepo,
+		blueprintProgressRepo:     blueprintProgressRepo,
+		authService:               authService,
+		dataCacheService:          dataCacheService,
+		eventBus:                  eventBus,
 	}
 }
 
+// defaultPageSize is the page size of list queries that don't ask for one
+const defaultPageSize = 20
+
+// pageBounds returns the offset and limit of a list query, capping the limit like the
+// REST list endpoints do
+func pageBounds(pagination *model.PaginationInput) (offset, limit int) {
+	limit = defaultPageSize
+	if pagination != nil {
+		if pagination.Offset != nil && *pagination.Offset > 0 {
+			offset = *pagination.Offset
+		}
+		if pagination.Limit != nil && *pagination.Limit > 0 {
+			limit = *pagination.Limit
+		}
+	}
+	if limit > middleware.DefaultMaxPageSize {
+		limit = middleware.DefaultMaxPageSize
+	}
+	return offset, limit
+}
+
+func newPageInfo(total int64, offset, limit int) *model.PageInfo {
+	return &model.PageInfo{
+		Total:   int(total),
+		Limit:   limit,
+		Offset:  offset,
+		HasMore: int64(offset+limit) < total,
+	}
+}
+
+// cursor identifies an edge by its offset in the list
+func cursor(offset int) string {
+	return strconv.Itoa(offset)
+}
+
+// orNil turns a missing record into a null result instead of an error
+func orNil[T any](value *T, err error) (*T, error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return value, err
+}
+
+func pointers[T any](values []T) []*T {
+	result := make([]*T, len(values))
+	for i := range values {
+		result[i] = &values[i]
+	}
+	return result
+}
@@ -1,13 +1,324 @@
-//go:build !ignore_autogenerated
-// +build !ignore_autogenerated
-
-// This file will be automatically generated by gqlgen
-// After running `go run github.com/99designs/gqlgen generate`, this file will be populated
-// with resolver implementations based on the schema.graphqls file.
-
 package graph
 
 // This file will be automatically regenerated based on the schema, any resolver implementations
 // will be copied through when generating and any unknown code will be moved to the end.
-// Code generated by github.com/99designs/gqlgen
+// Code generated by github.com/99designs/gqlgen version v0.17.83
+
+import (
+	"context"
+
+	"github.com/mat/arcapi/internal/graph/generated"
+	"github.com/mat/arcapi/internal/graph/model"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+	"github.com/mat/arcapi/internal/services"
+)
+
+// Item is the resolver for the item field.
+func (r *blueprintProgressResolver) Item(ctx context.Context, obj *models.UserBlueprintProgress) (*models.Item, error) {
+	if obj.ItemExternalID == "" { // The item no longer exists
+		return nil, nil
+	}
+	return r.ItemByExternalID(ctx, obj.ItemExternalID)
+}
+
+// Health is the resolver for the health field.
+func (r *queryResolver) Health(ctx context.Context) (string, error) {
+	return "ok", nil
+}
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*models.User, error) {
+	return RequireAuth(ctx)
+}
+
+// Quest is the resolver for the quest field.
+func (r *queryResolver) Quest(ctx context.Context, id uint) (*models.Quest, error) {
+	if _, err := RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	return orNil(r.questRepo.FindByID(id))
+}
+
+// Quests is the resolver for the quests field.
+func (r *queryResolver) Quests(ctx context.Context, pagination *model.PaginationInput) (*model.QuestConnection, error) {
+	if _, err := RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	offset, limit := pageBounds(pagination)
+	quests, total, err := r.questRepo.FindAll(offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	edges := make([]*model.QuestEdge, len(quests))
+	for i := range quests {
+		edges[i] = &model.QuestEdge{Node: &quests[i], Cursor: cursor(offset + i)}
+	}
+	return &model.QuestConnection{Edges: edges, PageInfo: newPageInfo(total, offset, limit)}, nil
+}
+
+// QuestByExternalID is the resolver for the questByExternalId field.
+func (r *queryResolver) QuestByExternalID(ctx context.Context, externalID string) (*models.Quest, error) {
+	if _, err := RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	return orNil(r.questRepo.FindByExternalID(externalID))
+}
+
+// Item is the resolver for the item field.
+func (r *queryResolver) Item(ctx context.Context, id uint) (*models.Item, error) {
+	if _, err := RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	return orNil(r.itemRepo.FindByID(id))
+}
+
+// Items is the resolver for the items field.
+func (r *queryResolver) Items(ctx context.Context, pagination *model.PaginationInput, typeArg *string) (*model.ItemConnection, error) {
+	if _, err := RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	var filters []repository.Filter
+	if typeArg != nil {
+		filters = append(filters, repository.Filter{Column: "type", Operator: "eq", Value: *typeArg})
+	}
+	offset, limit := pageBounds(pagination)
+	items, total, err := r.itemRepo.FindFiltered(filters, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	edges := make([]*model.ItemEdge, len(items))
+	for i := range items {
+		edges[i] = &model.ItemEdge{Node: &items[i], Cursor: cursor(offset + i)}
+	}
+	return &model.ItemConnection{Edges: edges, PageInfo: newPageInfo(total, offset, limit)}, nil
+}
+
+// ItemByExternalID is the resolver for the itemByExternalId field.
+func (r *queryResolver) ItemByExternalID(ctx context.Context, externalID string) (*models.Item, error) {
+	if _, err := RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	return orNil(r.itemRepo.FindByExternalID(externalID))
+}
+
+// SkillNode is the resolver for the skillNode field.
+func (r *queryResolver) SkillNode(ctx context.Context, id uint) (*models.SkillNode, error) {
+	if _, err := RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	return orNil(r.skillNodeRepo.FindByID(id))
+}
+
+// SkillNodes is the resolver for the skillNodes field.
+func (r *queryResolver) SkillNodes(ctx context.Context, pagination *model.PaginationInput) (*model.SkillNodeConnection, error) {
+	if _, err := RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	offset, limit := pageBounds(pagination)
+	skillNodes, total, err := r.skillNodeRepo.FindAll(offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	edges := make([]*model.SkillNodeEdge, len(skillNodes))
+	for i := range skillNodes {
+		edges[i] = &model.SkillNodeEdge{Node: &skillNodes[i], Cursor: cursor(offset + i)}
+	}
+	return &model.SkillNodeConnection{Edges: edges, PageInfo: newPageInfo(total, offset, limit)}, nil
+}
+
+// SkillNodeByExternalID is the resolver for the skillNodeByExternalId field.
+func (r *queryResolver) SkillNodeByExternalID(ctx context.Context, externalID string) (*models.SkillNode, error) {
+	if _, err := RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	return orNil(r.skillNodeRepo.FindByExternalID(externalID))
+}
+
+// HideoutModule is the resolver for the hideoutModule field.
+func (r *queryResolver) HideoutModule(ctx context.Context, id uint) (*models.HideoutModule, error) {
+	if _, err := RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	return orNil(r.hideoutModuleRepo.FindByID(id))
+}
+
+// HideoutModules is the resolver for the hideoutModules field.
+func (r *queryResolver) HideoutModules(ctx context.Context, pagination *model.PaginationInput) (*model.HideoutModuleConnection, error) {
+	if _, err := RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	offset, limit := pageBounds(pagination)
+	hideoutModules, total, err := r.hideoutModuleRepo.FindAll(offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	edges := make([]*model.HideoutModuleEdge, len(hideoutModules))
+	for i := range hideoutModules {
+		edges[i] = &model.HideoutModuleEdge{Node: &hideoutModules[i], Cursor: cursor(offset + i)}
+	}
+	return &model.HideoutModuleConnection{Edges: edges, PageInfo: newPageInfo(total, offset, limit)}, nil
+}
+
+// HideoutModuleByExternalID is the resolver for the hideoutModuleByExternalId field.
+func (r *queryResolver) HideoutModuleByExternalID(ctx context.Context, externalID string) (*models.HideoutModule, error) {
+	if _, err := RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	return orNil(r.hideoutModuleRepo.FindByExternalID(externalID))
+}
+
+// EnemyType is the resolver for the enemyType field.
+func (r *queryResolver) EnemyType(ctx context.Context, id uint) (*models.EnemyType, error) {
+	if _, err := RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	return orNil(r.enemyTypeRepo.FindByID(id))
+}
+
+// EnemyTypes is the resolver for the enemyTypes field.
+func (r *queryResolver) EnemyTypes(ctx context.Context, pagination *model.PaginationInput) (*model.EnemyTypeConnection, error) {
+	if _, err := RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	offset, limit := pageBounds(pagination)
+	enemyTypes, total, err := r.enemyTypeRepo.FindAll(offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	edges := make([]*model.EnemyTypeEdge, len(enemyTypes))
+	for i := range enemyTypes {
+		edges[i] = &model.EnemyTypeEdge{Node: &enemyTypes[i], Cursor: cursor(offset + i)}
+	}
+	return &model.EnemyTypeConnection{Edges: edges, PageInfo: newPageInfo(total, offset, limit)}, nil
+}
+
+// EnemyTypeByExternalID is the resolver for the enemyTypeByExternalId field.
+func (r *queryResolver) EnemyTypeByExternalID(ctx context.Context, externalID string) (*models.EnemyType, error) {
+	if _, err := RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	return orNil(r.enemyTypeRepo.FindByExternalID(externalID))
+}
+
+// Alert is the resolver for the alert field.
+func (r *queryResolver) Alert(ctx context.Context, id uint) (*models.Alert, error) {
+	if _, err := RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	return orNil(r.alertRepo.FindByID(id))
+}
+
+// Alerts is the resolver for the alerts field.
+func (r *queryResolver) Alerts(ctx context.Context, pagination *model.PaginationInput) (*model.AlertConnection, error) {
+	if _, err := RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	offset, limit := pageBounds(pagination)
+	alerts, total, err := r.alertRepo.FindAll(offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	edges := make([]*model.AlertEdge, len(alerts))
+	for i := range alerts {
+		edges[i] = &model.AlertEdge{Node: &alerts[i], Cursor: cursor(offset + i)}
+	}
+	return &model.AlertConnection{Edges: edges, PageInfo: newPageInfo(total, offset, limit)}, nil
+}
+
+// ActiveAlerts is the resolver for the activeAlerts field.
+func (r *queryResolver) ActiveAlerts(ctx context.Context) ([]*models.Alert, error) {
+	if _, err := RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	alerts, err := r.alertRepo.FindActive()
+	return pointers(alerts), err
+}
+
+// MyQuestProgress is the resolver for the myQuestProgress field.
+func (r *queryResolver) MyQuestProgress(ctx context.Context) ([]*models.UserQuestProgress, error) {
+	user, err := RequireAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := r.questProgressRepo.FindByUserID(user.ID, true)
+	return pointers(progress), err
+}
+
+// MyHideoutModuleProgress is the resolver for the myHideoutModuleProgress field.
+func (r *queryResolver) MyHideoutModuleProgress(ctx context.Context) ([]*models.UserHideoutModuleProgress, error) {
+	user, err := RequireAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := r.hideoutModuleProgressRepo.FindByUserID(user.ID, true)
+	return pointers(progress), err
+}
+
+// MySkillNodeProgress is the resolver for the mySkillNodeProgress field.
+func (r *queryResolver) MySkillNodeProgress(ctx context.Context) ([]*models.UserSkillNodeProgress, error) {
+	user, err := RequireAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := r.skillNodeProgressRepo.FindByUserID(user.ID, true)
+	return pointers(progress), err
+}
+
+// MyBlueprintProgress is the resolver for the myBlueprintProgress field.
+func (r *queryResolver) MyBlueprintProgress(ctx context.Context) ([]*models.UserBlueprintProgress, error) {
+	user, err := RequireAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := r.blueprintProgressRepo.FindByUserID(user.ID, false)
+	return pointers(progress), err
+}
+
+// RewardItems is the resolver for the rewardItems field.
+func (r *questResolver) RewardItems(ctx context.Context, obj *models.Quest) ([]*models.Item, error) {
+	rewards := services.NormalizeQuest(*obj, "en").RewardItems
+	externalIDs := make([]string, len(rewards))
+	for i, reward := range rewards {
+		externalIDs[i] = reward.ItemID
+	}
+	return r.ItemsByExternalIDs(ctx, externalIDs)
+}
+
+// ActiveAlerts is the resolver for the activeAlerts field.
+func (r *subscriptionResolver) ActiveAlerts(ctx context.Context) (<-chan []*models.Alert, error) {
+	return r.ActiveAlertsStream(ctx)
+}
+
+// SyncCompleted is the resolver for the syncCompleted field.
+func (r *subscriptionResolver) SyncCompleted(ctx context.Context) (<-chan *services.SyncEvent, error) {
+	return r.SyncCompletedStream(ctx)
+}
+
+// Role is the resolver for the role field.
+func (r *userResolver) Role(ctx context.Context, obj *models.User) (string, error) {
+	return string(obj.Role), nil
+}
+
+// BlueprintProgress returns generated.BlueprintProgressResolver implementation.
+func (r *Resolver) BlueprintProgress() generated.BlueprintProgressResolver {
+	return &blueprintProgressResolver{r}
+}
+
+// Query returns generated.QueryResolver implementation.
+func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
+
+// Quest returns generated.QuestResolver implementation.
+func (r *Resolver) Quest() generated.QuestResolver { return &questResolver{r} }
+
+// Subscription returns generated.SubscriptionResolver implementation.
+func (r *Resolver) Subscription() generated.SubscriptionResolver { return &subscriptionResolver{r} }
+
+// User returns generated.UserResolver implementation.
+func (r *Resolver) User() generated.UserResolver { return &userResolver{r} }
 
+type blueprintProgressResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type questResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
@@ -6,8 +6,6 @@ import (
 	"strings"
 
 	"github.com/99designs/gqlgen/graphql"
-	"github.com/mat/arcapi/internal/config"
-	"github.com/mat/arcapi/internal/middleware"
 	"github.com/mat/arcapi/internal/models"
 	"github.com/mat/arcapi/internal/services"
 	"github.com/vektah/gqlparser/v2/ast"
@@ -22,6 +20,15 @@ const (
 
 	// MaxQueryCost limits the estimated cost of a query
 	MaxQueryCost = 500
+
+	// MaxBatchOperations limits the operations in one batched request
+	MaxBatchOperations = 20
+
+	// MaxBatchComplexity limits the summed complexity of the operations in a batch
+	MaxBatchComplexity = 3 * MaxQueryComplexity
+
+	// MaxRequestBodyBytes limits the body of a GraphQL POST, batched or not
+	MaxRequestBodyBytes = 1 << 20
 )
 
 // UserContextKey is the key for storing user in context
@@ -42,17 +49,29 @@ func RequireAuth(ctx context.Context) (*models.User, error) {
 }
 
 // RequireAdmin ensures a user is authenticated and is an admin
-func RequireAdmin(ctx context.Context) (*models.User, error) {
+func RequireAdmin(ctx context.Context, rbacService *services.RBACService) (*models.User, error) {
 	user, err := GetUserFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if user.Role != models.RoleAdmin {
+	if !rbacService.IsAdmin(user) {
 		return nil, fmt.Errorf("admin access required")
 	}
 	return user, nil
 }
 
+// RequirePermission ensures a user is authenticated and their role grants permission
+func RequirePermission(ctx context.Context, rbacService *services.RBACService, permission string) (*models.User, error) {
+	user, err := GetUserFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !rbacService.HasPermission(user, permission) {
+		return nil, fmt.Errorf("permission required: %s", permission)
+	}
+	return user, nil
+}
+
 // DepthLimitDirective validates query depth
 func DepthLimitDirective(ctx context.Context, obj interface{}, next graphql.Resolver, maxDepth int) (interface{}, error) {
 	depth := calculateDepth(ctx)
@@ -181,52 +200,16 @@ func ValidateQueryComplexity(ctx context.Context, maxComplexity int) error {
 	return nil
 }
 
-// GraphQLResolverAuthMiddleware validates authentication and adds user to context for GraphQL resolvers
-func GraphQLResolverAuthMiddleware(authService *services.AuthService, cfg *config.Config, supabaseAuthService *services.SupabaseAuthService) func(ctx context.Context, obj interface{}, next graphql.Resolver) (interface{}, error) {
-	return func(ctx context.Context, obj interface{}, next graphql.Resolver) (interface{}, error) {
-		// Get authorization header from request context
-		reqCtx := graphql.GetRequestContext(ctx)
-		if reqCtx == nil {
-			return nil, fmt.Errorf("request context not found")
-		}
-
-		// Get headers from request
-		headers := reqCtx.Headers
-		authHeader := headers.Get("Authorization")
-		if authHeader == "" {
-			return nil, fmt.Errorf("authorization header required")
-		}
-
-		// Extract token from "Bearer <token>"
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			return nil, fmt.Errorf("invalid authorization header format")
-		}
-
-		tokenString := parts[1]
-
-		user, err := middleware.ValidateTokenString(tokenString, authService, supabaseAuthService, cfg)
-		if err != nil {
-			return nil, fmt.Errorf("invalid or expired token: %w", err)
+// GraphQLAdminMiddleware ensures user is admin
+func GraphQLAdminMiddleware(rbacService *services.RBACService) graphql.FieldMiddleware {
+	return func(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+		if _, err := RequireAdmin(ctx, rbacService); err != nil {
+			return nil, err
 		}
-
-		// Add user to context
-		ctx = context.WithValue(ctx, UserContextKey, user)
-
 		return next(ctx)
 	}
 }
 
-// GraphQLAdminMiddleware ensures user is admin
-func GraphQLAdminMiddleware(ctx context.Context, obj interface{}, next graphql.Resolver) (interface{}, error) {
-	user, err := RequireAdmin(ctx)
-	if err != nil {
-		return nil, err
-	}
-	_ = user // Use user to avoid unused variable
-	return next(ctx)
-}
-
 // ValidateOperation validates GraphQL operation before execution
 func ValidateOperation(ctx context.Context) error {
 	opCtx := graphql.GetOperationContext(ctx)
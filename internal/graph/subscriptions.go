@@ -0,0 +1,92 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/services"
+)
+
+// Subscription streams. Events come from Redis pub/sub, so a subscriber connected to one
+// instance sees writes and syncs from every instance. The subscriptionResolver methods
+// delegate to these.
+
+// eventSubscriber is the part of services.EventBus the subscriptions use
+type eventSubscriber interface {
+	Subscribe(ctx context.Context, channel string) (<-chan []byte, error)
+}
+
+// ActiveAlertsStream sends the active alerts when subscribing and again after every alert write
+func (r *Resolver) ActiveAlertsStream(ctx context.Context) (<-chan []*models.Alert, error) {
+	if _, err := RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	events, err := r.eventBus.Subscribe(ctx, services.EventChannelAlerts)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan []*models.Alert, 1)
+	go func() {
+		defer close(out)
+		send := func() bool {
+			alerts, err := r.alertRepo.FindActive()
+			if err != nil {
+				log.Printf("Warning: Failed to fetch active alerts for subscription: %v", err)
+				return true
+			}
+			result := make([]*models.Alert, len(alerts))
+			for i := range alerts {
+				result[i] = &alerts[i]
+			}
+			select {
+			case out <- result:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		if !send() {
+			return
+		}
+		// events is closed once ctx is done
+		for range events {
+			if !send() {
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// SyncCompletedStream sends an event after each successful data sync
+func (r *Resolver) SyncCompletedStream(ctx context.Context) (<-chan *services.SyncEvent, error) {
+	if _, err := RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	events, err := r.eventBus.Subscribe(ctx, services.EventChannelSync)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan *services.SyncEvent, 1)
+	go func() {
+		defer close(out)
+		for payload := range events {
+			var event services.SyncEvent
+			if err := json.Unmarshal(payload, &event); err != nil {
+				log.Printf("Warning: Ignoring malformed sync event: %v", err)
+				continue
+			}
+			select {
+			case out <- &event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,100 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/mat/arcapi/internal/graph/generated"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/services"
+)
+
+// fakeEventBus hands every subscriber the same channel, fed by the test
+type fakeEventBus struct {
+	channel    string
+	events     chan []byte
+	subscribed chan struct{}
+}
+
+func (b *fakeEventBus) Subscribe(ctx context.Context, channel string) (<-chan []byte, error) {
+	b.channel = channel
+	close(b.subscribed)
+	return b.events, nil
+}
+
+// newSubscriptionServer serves the schema with the production middleware, with user
+// standing in for the one GraphQLAuthMiddleware authenticates
+func newSubscriptionServer(resolver *Resolver, user *models.User) http.Handler {
+	srv := handler.New(generated.NewExecutableSchema(generated.Config{Resolvers: resolver}))
+	setupSecurityMiddleware(srv)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user != nil {
+			r = r.WithContext(context.WithValue(r.Context(), UserContextKey, user))
+		}
+		srv.ServeHTTP(w, r)
+	})
+}
+
+func TestSyncCompletedSubscription(t *testing.T) {
+	bus := &fakeEventBus{events: make(chan []byte, 1), subscribed: make(chan struct{})}
+	c := client.New(newSubscriptionServer(&Resolver{eventBus: bus}, &models.User{ID: 1}))
+
+	sub := c.Websocket(`subscription { syncCompleted { dataVersion changes { entityType entityId fields } } }`)
+	defer sub.Close()
+
+	select {
+	case <-bus.subscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription never reached the event bus")
+	}
+	if bus.channel != services.EventChannelSync {
+		t.Errorf("subscribed to %q, want %q", bus.channel, services.EventChannelSync)
+	}
+
+	event, _ := json.Marshal(services.SyncEvent{
+		DataVersion: "v42",
+		Changes:     []services.EntityChange{{EntityType: "item", EntityID: "rusty-gear", Fields: []string{"name"}}},
+		CompletedAt: time.Now(),
+	})
+	bus.events <- event
+
+	var resp struct {
+		SyncCompleted struct {
+			DataVersion string
+			Changes     []struct {
+				EntityType string
+				EntityID   string
+				Fields     []string
+			}
+		}
+	}
+	if err := sub.Next(&resp); err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if resp.SyncCompleted.DataVersion != "v42" || len(resp.SyncCompleted.Changes) != 1 || resp.SyncCompleted.Changes[0].EntityID != "rusty-gear" {
+		t.Errorf("unexpected event: %+v", resp.SyncCompleted)
+	}
+}
+
+func TestSubscriptionRequiresAuthenticatedConnection(t *testing.T) {
+	bus := &fakeEventBus{events: make(chan []byte), subscribed: make(chan struct{})}
+	c := client.New(newSubscriptionServer(&Resolver{eventBus: bus}, nil))
+
+	sub := c.Websocket(`subscription { syncCompleted { dataVersion } }`)
+	defer sub.Close()
+
+	var resp struct{ SyncCompleted struct{ DataVersion string } }
+	if err := sub.Next(&resp); err == nil {
+		t.Fatal("expected an unauthenticated connection to be rejected")
+	}
+	select {
+	case <-bus.subscribed:
+		t.Error("an unauthenticated connection reached the event bus")
+	default:
+	}
+}
@@ -0,0 +1,358 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/config"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+	"github.com/mat/arcapi/internal/services"
+)
+
+// acquisitionCacheTTL bounds how long a plan is kept; keys already change with the data
+// and price versions, so this only evicts plans nobody asks for anymore
+const acquisitionCacheTTL = time.Hour
+
+// Acquisition methods
+const (
+	AcquireBuy    = "buy"
+	AcquireCraft  = "craft"
+	AcquireBarter = "barter"
+)
+
+type AcquisitionHandler struct {
+	recipeRepo     *repository.RecipeRepository
+	itemRepo       *repository.ItemRepository
+	tradersService *services.TradersService
+	syncService    *services.SyncService
+	cacheService   *services.CacheService
+	maxDepth       int
+}
+
+func NewAcquisitionHandler(
+	recipeRepo *repository.RecipeRepository,
+	itemRepo *repository.ItemRepository,
+	tradersService *services.TradersService,
+	syncService *services.SyncService,
+	cacheService *services.CacheService,
+	cfg *config.Config,
+) *AcquisitionHandler {
+	maxDepth := cfg.CraftCostMaxDepth
+	if maxDepth < 1 {
+		maxDepth = 1
+	}
+	return &AcquisitionHandler{
+		recipeRepo:     recipeRepo,
+		itemRepo:       itemRepo,
+		tradersService: tradersService,
+		syncService:    syncService,
+		cacheService:   cacheService,
+		maxDepth:       maxDepth,
+	}
+}
+
+// AcquisitionIngredient is one input of a craft or barter, priced at its own cheapest method
+type AcquisitionIngredient struct {
+	ItemID    string  `json:"item_id"`
+	Name      string  `json:"name,omitempty"`
+	Quantity  int     `json:"quantity"`
+	Method    string  `json:"method,omitempty"` // Cheapest way to get the ingredient; empty if unpriced
+	UnitCost  float64 `json:"unit_cost"`
+	TotalCost float64 `json:"total_cost"`
+}
+
+// AcquisitionOption is one way of obtaining the requested quantity of an item
+type AcquisitionOption struct {
+	Method      string                  `json:"method"`
+	Trader      string                  `json:"trader,omitempty"`
+	Bench       string                  `json:"bench,omitempty"`
+	Crafts      int                     `json:"crafts,omitempty"`
+	UnitCost    float64                 `json:"unit_cost"`
+	TotalCost   float64                 `json:"total_cost"`
+	Ingredients []AcquisitionIngredient `json:"ingredients,omitempty"`
+	// Complete is false when some ingredient has no known price, so TotalCost is a lower bound
+	Complete bool     `json:"complete"`
+	Missing  []string `json:"missing_prices,omitempty"`
+}
+
+// AcquisitionResponse compares the ways of obtaining an item, cheapest complete option first
+type AcquisitionResponse struct {
+	ItemID         uint                `json:"item_id"`
+	ExternalID     string              `json:"external_id"`
+	Name           string              `json:"name"`
+	Quantity       int                 `json:"quantity"`
+	Recommendation string              `json:"recommendation,omitempty"`
+	Options        []AcquisitionOption `json:"options"`
+	DataVersion    string              `json:"data_version"`
+	PriceVersion   string              `json:"price_version"`
+	Cached         bool                `json:"cached"`
+}
+
+// GetAcquisition suggests the cheapest way to obtain an item
+// @Summary Get acquisition suggestions for an item
+// @Description Compares buying from traders, crafting and bartering using the recipe graph and live trader prices. Crafting and barter inputs are priced at their own cheapest method, expanding sub-recipes up to CRAFT_COST_MAX_DEPTH. Results are cached per data and price version.
+// @Tags items
+// @Accept json
+// @Produce json
+// @Param id path int true "Item ID"
+// @Param quantity query int false "Number of items wanted (default: 1)"
+// @Success 200 {object} AcquisitionResponse "Acquisition options"
+// @Failure 400 {object} ErrorResponse "Invalid ID or quantity"
+// @Failure 404 {object} ErrorResponse "Item not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Failure 503 {object} ErrorResponse "Price data unavailable"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /items/{id}/acquisition [get]
+func (h *AcquisitionHandler) GetAcquisition(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+		return
+	}
+
+	quantity := 1
+	if q := c.Query("quantity"); q != "" {
+		parsed, err := strconv.Atoi(q)
+		if err != nil || parsed < 1 || parsed > 1000 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "quantity must be between 1 and 1000"})
+			return
+		}
+		quantity = parsed
+	}
+
+	if h.tradersService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Price data unavailable"})
+		return
+	}
+
+	item, err := h.itemRepo.FindByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		return
+	}
+
+	market, err := h.tradersService.GetMarket()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Price data unavailable"})
+		return
+	}
+	dataVersion := h.syncService.DataVersion()
+
+	cacheKey := fmt.Sprintf("acquisition:%s:%s:%s:%d", dataVersion, market.Version, item.ExternalID, quantity)
+	if h.cacheService != nil {
+		var cached AcquisitionResponse
+		if err := h.cacheService.GetJSON(cacheKey, &cached); err == nil {
+			cached.Cached = true
+			c.JSON(http.StatusOK, cached)
+			return
+		}
+	}
+
+	recipes, err := h.recipeRepo.ListAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recipes"})
+		return
+	}
+	items, err := h.itemRepo.ListAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch items"})
+		return
+	}
+
+	planner := newAcquisitionPlanner(recipes, items, market, h.maxDepth)
+	options := planner.options(item.ExternalID, quantity)
+
+	resp := AcquisitionResponse{
+		ItemID:       item.ID,
+		ExternalID:   item.ExternalID,
+		Name:         item.Name,
+		Quantity:     quantity,
+		Options:      options,
+		DataVersion:  dataVersion,
+		PriceVersion: market.Version,
+	}
+	if len(options) > 0 && options[0].Complete {
+		resp.Recommendation = options[0].Method
+	}
+
+	if h.cacheService != nil {
+		h.cacheService.SetJSON(cacheKey, resp, acquisitionCacheTTL)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// acquisitionChoice is the cheapest known way to obtain one unit of an item
+type acquisitionChoice struct {
+	method   string
+	unitCost float64
+	ok       bool
+}
+
+// acquisitionPlanner prices items by the cheapest of buying, crafting and bartering
+type acquisitionPlanner struct {
+	recipes  map[string]models.Recipe
+	names    map[string]string
+	prices   map[string][]services.TraderPrice
+	barters  map[string][]services.TraderBarter
+	maxDepth int
+	memo     map[string]acquisitionChoice
+	visiting map[string]bool
+}
+
+func newAcquisitionPlanner(recipes []models.Recipe, items []models.Item, market *services.TraderMarket, maxDepth int) *acquisitionPlanner {
+	p := &acquisitionPlanner{
+		recipes:  make(map[string]models.Recipe, len(recipes)),
+		names:    make(map[string]string, len(items)),
+		prices:   make(map[string][]services.TraderPrice),
+		barters:  make(map[string][]services.TraderBarter),
+		maxDepth: maxDepth,
+		memo:     make(map[string]acquisitionChoice),
+		visiting: make(map[string]bool),
+	}
+	for _, recipe := range recipes {
+		p.recipes[recipe.ItemExternalID] = recipe
+	}
+	for _, item := range items {
+		p.names[item.ExternalID] = item.Name
+	}
+	for _, price := range market.Prices {
+		p.prices[price.ItemID] = append(p.prices[price.ItemID], price)
+	}
+	for _, barter := range market.Barters {
+		p.barters[barter.ItemID] = append(p.barters[barter.ItemID], barter)
+	}
+	return p
+}
+
+// options lists every way to obtain quantity of itemID, cheapest complete option first
+func (p *acquisitionPlanner) options(itemID string, quantity int) []AcquisitionOption {
+	options := []AcquisitionOption{}
+	for _, price := range p.prices[itemID] {
+		options = append(options, AcquisitionOption{
+			Method:    AcquireBuy,
+			Trader:    price.TraderName,
+			UnitCost:  price.Price,
+			TotalCost: price.Price * float64(quantity),
+			Complete:  true,
+		})
+	}
+
+	p.visiting[itemID] = true
+	if option, ok := p.craftOption(itemID, quantity, 1); ok {
+		options = append(options, option)
+	}
+	for _, barter := range p.barters[itemID] {
+		options = append(options, p.barterOption(barter, quantity, 1))
+	}
+	delete(p.visiting, itemID)
+
+	sort.SliceStable(options, func(i, j int) bool {
+		if options[i].Complete != options[j].Complete {
+			return options[i].Complete
+		}
+		return options[i].TotalCost < options[j].TotalCost
+	})
+	return options
+}
+
+// best returns the cheapest way to get one unit of itemID at the given recipe depth.
+// Items already being priced further up the chain are skipped so cyclic recipes end.
+func (p *acquisitionPlanner) best(itemID string, depth int) acquisitionChoice {
+	key := itemID + "@" + strconv.Itoa(depth)
+	if choice, ok := p.memo[key]; ok {
+		return choice
+	}
+	if p.visiting[itemID] {
+		return acquisitionChoice{}
+	}
+	p.visiting[itemID] = true
+	defer delete(p.visiting, itemID)
+
+	var choice acquisitionChoice
+	consider := func(method string, unitCost float64) {
+		if !choice.ok || unitCost < choice.unitCost {
+			choice = acquisitionChoice{method: method, unitCost: unitCost, ok: true}
+		}
+	}
+	for _, price := range p.prices[itemID] {
+		consider(AcquireBuy, price.Price)
+	}
+	if recipe, ok := p.recipes[itemID]; ok && depth <= p.maxDepth {
+		output := max(recipe.OutputQuantity, 1)
+		if option, ok := p.craftOption(itemID, output, depth); ok && option.Complete {
+			consider(AcquireCraft, option.TotalCost/float64(output))
+		}
+	}
+	if depth <= p.maxDepth {
+		for _, barter := range p.barters[itemID] {
+			if option := p.barterOption(barter, 1, depth); option.Complete {
+				consider(AcquireBarter, option.UnitCost)
+			}
+		}
+	}
+
+	p.memo[key] = choice
+	return choice
+}
+
+// craftOption prices crafting quantity of itemID; ok is false if the item has no recipe
+func (p *acquisitionPlanner) craftOption(itemID string, quantity, depth int) (AcquisitionOption, bool) {
+	recipe, ok := p.recipes[itemID]
+	if !ok {
+		return AcquisitionOption{}, false
+	}
+	output := max(recipe.OutputQuantity, 1)
+	crafts := int(math.Ceil(float64(quantity) / float64(output)))
+
+	ids, quantities := recipeIngredients(recipe)
+	option := p.priceInputs(ids, quantities, crafts, quantity, depth)
+	option.Method = AcquireCraft
+	option.Bench = recipe.Bench
+	option.Crafts = crafts
+	return option, true
+}
+
+func (p *acquisitionPlanner) barterOption(barter services.TraderBarter, quantity, depth int) AcquisitionOption {
+	ids := make([]string, 0, len(barter.Cost))
+	for id := range barter.Cost {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	option := p.priceInputs(ids, barter.Cost, quantity, quantity, depth)
+	option.Method = AcquireBarter
+	option.Trader = barter.TraderName
+	return option
+}
+
+// priceInputs prices perUnit quantities of each input times multiplier, spread over quantity results
+func (p *acquisitionPlanner) priceInputs(ids []string, perUnit map[string]int, multiplier, quantity, depth int) AcquisitionOption {
+	option := AcquisitionOption{Complete: true}
+	for _, id := range ids {
+		ingredient := AcquisitionIngredient{
+			ItemID:   id,
+			Name:     p.names[id],
+			Quantity: perUnit[id] * multiplier,
+		}
+		if choice := p.best(id, depth+1); choice.ok {
+			ingredient.Method = choice.method
+			ingredient.UnitCost = choice.unitCost
+			ingredient.TotalCost = choice.unitCost * float64(ingredient.Quantity)
+			option.TotalCost += ingredient.TotalCost
+		} else {
+			option.Complete = false
+			option.Missing = append(option.Missing, id)
+		}
+		option.Ingredients = append(option.Ingredients, ingredient)
+	}
+	option.UnitCost = option.TotalCost / float64(quantity)
+	return option
+}
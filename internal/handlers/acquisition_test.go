@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/services"
+)
+
+func TestAcquisitionPlannerPrefersCheapestMethod(t *testing.T) {
+	recipes := []models.Recipe{
+		{ItemExternalID: "medkit", OutputQuantity: 1, Ingredients: models.JSONB{"bandage": float64(2), "herb": float64(1)}},
+		{ItemExternalID: "bandage", OutputQuantity: 2, Ingredients: models.JSONB{"fabric": float64(1)}},
+		// Cyclic recipe must not loop
+		{ItemExternalID: "fabric", OutputQuantity: 1, Ingredients: models.JSONB{"bandage": float64(1)}},
+	}
+	market := &services.TraderMarket{
+		Prices: []services.TraderPrice{
+			{TraderName: "Apollo", ItemID: "medkit", Price: 500},
+			{TraderName: "Apollo", ItemID: "bandage", Price: 100},
+			{TraderName: "Celeste", ItemID: "fabric", Price: 40},
+			{TraderName: "Celeste", ItemID: "herb", Price: 30},
+		},
+		Barters: []services.TraderBarter{
+			{TraderName: "Lance", ItemID: "medkit", Cost: map[string]int{"rare_part": 1}},
+		},
+	}
+
+	planner := newAcquisitionPlanner(recipes, nil, market, 10)
+	options := planner.options("medkit", 2)
+	if len(options) != 3 {
+		t.Fatalf("expected buy, craft and barter options, got %+v", options)
+	}
+
+	craft := options[0]
+	if craft.Method != AcquireCraft || !craft.Complete {
+		t.Fatalf("expected craft to be cheapest, got %+v", options)
+	}
+	// 4 bandages crafted from fabric at 20 each, plus 2 herbs at 30
+	if craft.TotalCost != 140 || craft.UnitCost != 70 {
+		t.Errorf("unexpected craft cost %+v", craft)
+	}
+	if options[1].Method != AcquireBuy || options[1].TotalCost != 1000 {
+		t.Errorf("unexpected buy option %+v", options[1])
+	}
+	barter := options[2]
+	if barter.Method != AcquireBarter || barter.Complete || len(barter.Missing) != 1 {
+		t.Errorf("expected incomplete barter last, got %+v", barter)
+	}
+}
@@ -3,18 +3,22 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/middleware"
 	"github.com/mat/arcapi/internal/models"
 	"github.com/mat/arcapi/internal/repository"
+	"github.com/mat/arcapi/internal/services"
 )
 
 type AlertHandler struct {
-	repo *repository.AlertRepository
+	repo     *repository.AlertRepository
+	eventBus *services.EventBus // Announces alert writes to GraphQL subscribers; may be nil
 }
 
-func NewAlertHandler(repo *repository.AlertRepository) *AlertHandler {
-	return &AlertHandler{repo: repo}
+func NewAlertHandler(repo *repository.AlertRepository, eventBus *services.EventBus) *AlertHandler {
+	return &AlertHandler{repo: repo, eventBus: eventBus}
 }
 
 // List returns all alerts (paginated)
@@ -39,7 +43,7 @@ func (h *AlertHandler) List(c *gin.Context) {
 		}
 	}
 	if l := c.Query("limit"); l != "" {
-		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= middleware.PageLimit(c) {
 			limit = parsed
 		}
 	}
@@ -156,6 +160,21 @@ func (h *AlertHandler) Create(c *gin.Context) {
 		return
 	}
 
+	if alert.IncidentStatus != "" {
+		if !models.IsIncidentStatus(alert.IncidentStatus) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "incident_status must be one of: investigating, identified, resolved"})
+			return
+		}
+		// A new incident starts its timeline with the description
+		now := time.Now().UTC()
+		if len(alert.IncidentUpdates) == 0 {
+			alert.IncidentUpdates = models.IncidentUpdates{{Status: alert.IncidentStatus, Message: alert.Description, CreatedAt: now}}
+		}
+		if alert.IncidentStatus == models.IncidentResolved && alert.ResolvedAt == nil {
+			alert.ResolvedAt = &now
+		}
+	}
+
 	// Default is_active to true if not provided
 	// The model has default:true in GORM, but we'll also set it here for consistency
 	// If the field wasn't provided in JSON, it will be false (zero value), so we default to true
@@ -172,10 +191,126 @@ func (h *AlertHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create alert"})
 		return
 	}
+	h.eventBus.PublishAlertChange(c.Request.Context(), "create", alert.ID)
 
 	c.JSON(http.StatusCreated, alert)
 }
 
+// IncidentUpdateRequest is a new entry for an incident's timeline
+type IncidentUpdateRequest struct {
+	Status  string `json:"status" binding:"required" example:"identified"`
+	Message string `json:"message" binding:"required" example:"Sync is failing because the upstream repository moved"`
+}
+
+// AddIncidentUpdate appends to an alert's incident timeline
+// @Summary Post an incident update
+// @Description Append a status update to an alert's incident timeline and move the incident to that status. Resolving an incident deactivates the alert and sets resolved_at; any other status reactivates it. Turns a plain alert into an incident.
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param id path int true "Alert ID"
+// @Param update body IncidentUpdateRequest true "Incident update"
+// @Success 200 {object} models.Alert "The alert with the update appended"
+// @Failure 400 {object} ErrorResponse "Invalid input or ID"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Alert not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /alerts/{id}/updates [post]
+func (h *AlertHandler) AddIncidentUpdate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alert ID"})
+		return
+	}
+
+	var req IncidentUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !models.IsIncidentStatus(req.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of: investigating, identified, resolved"})
+		return
+	}
+
+	alert, err := h.repo.FindByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alert not found"})
+		return
+	}
+
+	now := time.Now().UTC()
+	alert.IncidentUpdates = append(alert.IncidentUpdates, models.IncidentUpdate{Status: req.Status, Message: req.Message, CreatedAt: now})
+	alert.IncidentStatus = req.Status
+	alert.IsActive = req.Status != models.IncidentResolved
+	alert.ResolvedAt = nil
+	if req.Status == models.IncidentResolved {
+		alert.ResolvedAt = &now
+	}
+
+	if err := h.repo.Update(alert); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update alert"})
+		return
+	}
+	h.eventBus.PublishAlertChange(c.Request.Context(), "update", alert.ID)
+
+	c.JSON(http.StatusOK, alert)
+}
+
+// Incidents returns alerts that track an incident, with their timelines
+// @Summary List incidents
+// @Description Fetch alerts that track an incident, newest first, each with its status and timeline of updates, for status pages
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param status query string false "Only incidents with this status" Enums(investigating, identified, resolved)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} PaginatedResponse{data=[]models.Alert} "Successfully fetched incidents"
+// @Failure 400 {object} ErrorResponse "Invalid status"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /incidents [get]
+func (h *AlertHandler) Incidents(c *gin.Context) {
+	page := 1
+	limit := 20
+
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= middleware.PageLimit(c) {
+			limit = parsed
+		}
+	}
+
+	status := c.Query("status")
+	if status != "" && !models.IsIncidentStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of: investigating, identified, resolved"})
+		return
+	}
+
+	offset := (page - 1) * limit
+	incidents, count, err := h.repo.FindIncidents(status, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch incidents"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": incidents,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": count,
+		},
+	})
+}
+
 // Update modifies an existing alert
 // @Summary Update an alert
 // @Description Update an existing alert by its ID
@@ -219,12 +354,18 @@ func (h *AlertHandler) Update(c *gin.Context) {
 		}
 	}
 
+	if alert.IncidentStatus != "" && !models.IsIncidentStatus(alert.IncidentStatus) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "incident_status must be one of: investigating, identified, resolved"})
+		return
+	}
+
 	alert.ID = uint(id)
 	err = h.repo.Update(&alert)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update alert"})
 		return
 	}
+	h.eventBus.PublishAlertChange(c.Request.Context(), "update", alert.ID)
 
 	c.JSON(http.StatusOK, alert)
 }
@@ -256,6 +397,7 @@ func (h *AlertHandler) Delete(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete alert"})
 		return
 	}
+	h.eventBus.PublishAlertChange(c.Request.Context(), "delete", uint(id))
 
 	c.JSON(http.StatusNoContent, nil)
 }
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+)
+
+// appwriteMaxIDLength is Appwrite's limit on custom document IDs
+const appwriteMaxIDLength = 36
+
+// AppwriteCollections lists the collections served under /integrations/appwrite
+var AppwriteCollections = []string{
+	"quests", "items", "skill-nodes", "hideout-modules", "enemy-types",
+	"bots", "maps", "traders", "projects",
+}
+
+// AppwriteDocuments returns a collection shaped for direct import into Appwrite
+// @Summary Get Appwrite-shaped documents
+// @Description Fetch a whole collection as Appwrite documents: "$id" is derived from the external ID, localized fields are flattened to an English value plus "<field>_<lang>" attributes, and JSON arrays become string arrays. Replaces the CSV export/import round trip.
+// @Tags integrations
+// @Produce json
+// @Param collection path string true "Collection" Enums(quests, items, skill-nodes, hideout-modules, enemy-types, bots, maps, traders, projects)
+// @Success 200 {object} map[string]interface{} "Documents and total"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Unknown collection"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /integrations/appwrite/{collection} [get]
+func (h *ExportHandler) AppwriteDocuments(c *gin.Context) {
+	collection := c.Param("collection")
+
+	var documents []map[string]interface{}
+	var err error
+	switch collection {
+	case "quests":
+		documents, err = h.appwriteQuests()
+	case "items":
+		documents, err = h.appwriteItems()
+	case "skill-nodes":
+		documents, err = h.appwriteSkillNodes()
+	case "hideout-modules":
+		documents, err = h.appwriteHideoutModules()
+	case "enemy-types":
+		documents, err = h.appwriteEnemyTypes()
+	case "bots":
+		bots, _, findErr := h.botRepo.FindAll(0, 10000)
+		err = findErr
+		for _, bot := range bots {
+			documents = append(documents, h.appwriteBasicDocument(bot.ExternalID, bot.Name, bot.Data))
+		}
+	case "maps":
+		maps, _, findErr := h.mapRepo.FindAll(0, 10000)
+		err = findErr
+		for _, m := range maps {
+			documents = append(documents, h.appwriteBasicDocument(m.ExternalID, m.Name, m.Data))
+		}
+	case "traders":
+		traders, _, findErr := h.traderRepo.FindAll(0, 10000)
+		err = findErr
+		for _, trader := range traders {
+			documents = append(documents, h.appwriteBasicDocument(trader.ExternalID, trader.Name, trader.Data))
+		}
+	case "projects":
+		projects, _, findErr := h.projectRepo.FindAll(0, 10000)
+		err = findErr
+		for _, project := range projects {
+			documents = append(documents, h.appwriteBasicDocument(project.ExternalID, project.Name, project.Data))
+		}
+	default:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown collection", "collections": AppwriteCollections})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch " + collection})
+		return
+	}
+	if documents == nil {
+		documents = []map[string]interface{}{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"collection": collection,
+		"documents":  documents,
+		"total":      len(documents),
+	})
+}
+
+func (h *ExportHandler) appwriteQuests() ([]map[string]interface{}, error) {
+	quests, _, err := h.questRepo.FindAll(0, 10000)
+	if err != nil {
+		return nil, err
+	}
+	documents := make([]map[string]interface{}, 0, len(quests))
+	for _, quest := range quests {
+		doc := h.appwriteDocument(quest.ExternalID, quest.Data)
+		h.setAppwriteLocalized(doc, "name", quest.Name, quest.Data)
+		h.setAppwriteLocalized(doc, "description", quest.Description, quest.Data)
+		doc["trader"] = quest.Trader
+		doc["xp"] = quest.XP
+		doc["objectives"] = appwriteStringArray(quest.Objectives, "objectives")
+		doc["reward_item_ids"] = appwriteStringArray(quest.RewardItemIds, "reward_item_ids")
+		documents = append(documents, doc)
+	}
+	return documents, nil
+}
+
+func (h *ExportHandler) appwriteItems() ([]map[string]interface{}, error) {
+	items, _, err := h.itemRepo.FindAll(0, 10000)
+	if err != nil {
+		return nil, err
+	}
+	documents := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		doc := h.appwriteDocument(item.ExternalID, item.Data)
+		h.setAppwriteLocalized(doc, "name", item.Name, item.Data)
+		h.setAppwriteLocalized(doc, "description", item.Description, item.Data)
+		doc["type"] = item.Type
+		doc["image_url"] = item.ImageURL
+		doc["image_filename"] = item.ImageFilename
+		documents = append(documents, doc)
+	}
+	return documents, nil
+}
+
+func (h *ExportHandler) appwriteSkillNodes() ([]map[string]interface{}, error) {
+	skillNodes, _, err := h.skillNodeRepo.FindAll(0, 10000)
+	if err != nil {
+		return nil, err
+	}
+	documents := make([]map[string]interface{}, 0, len(skillNodes))
+	for _, node := range skillNodes {
+		doc := h.appwriteDocument(node.ExternalID, node.Data)
+		h.setAppwriteLocalized(doc, "name", node.Name, node.Data)
+		h.setAppwriteLocalized(doc, "description", node.Description, node.Data)
+		doc["impacted_skill"] = node.ImpactedSkill
+		doc["category"] = node.Category
+		doc["max_points"] = node.MaxPoints
+		doc["icon_name"] = node.IconName
+		doc["is_major"] = node.IsMajor
+		doc["position"] = h.appwriteJSONString(node.Position)
+		doc["known_value"] = appwriteStringArray(node.KnownValue, "known_value")
+		doc["prerequisite_node_ids"] = appwriteStringArray(node.PrerequisiteNodeIds, "prerequisite_node_ids")
+		documents = append(documents, doc)
+	}
+	return documents, nil
+}
+
+func (h *ExportHandler) appwriteHideoutModules() ([]map[string]interface{}, error) {
+	modules, _, err := h.hideoutModuleRepo.FindAll(0, 10000)
+	if err != nil {
+		return nil, err
+	}
+	documents := make([]map[string]interface{}, 0, len(modules))
+	for _, module := range modules {
+		doc := h.appwriteDocument(module.ExternalID, module.Data)
+		h.setAppwriteLocalized(doc, "name", module.Name, module.Data)
+		h.setAppwriteLocalized(doc, "description", module.Description, module.Data)
+		doc["max_level"] = module.MaxLevel
+		doc["levels"] = appwriteStringArray(module.Levels, "levels")
+		documents = append(documents, doc)
+	}
+	return documents, nil
+}
+
+func (h *ExportHandler) appwriteEnemyTypes() ([]map[string]interface{}, error) {
+	enemyTypes, _, err := h.enemyTypeRepo.FindAll(0, 10000)
+	if err != nil {
+		return nil, err
+	}
+	documents := make([]map[string]interface{}, 0, len(enemyTypes))
+	for _, enemyType := range enemyTypes {
+		doc := h.appwriteDocument(enemyType.ExternalID, enemyType.Data)
+		h.setAppwriteLocalized(doc, "name", enemyType.Name, enemyType.Data)
+		h.setAppwriteLocalized(doc, "description", enemyType.Description, enemyType.Data)
+		doc["type"] = enemyType.Type
+		doc["image_url"] = enemyType.ImageURL
+		doc["image_filename"] = enemyType.ImageFilename
+		doc["weakpoints"] = appwriteStringArray(enemyType.Weakpoints, "weakpoints")
+		documents = append(documents, doc)
+	}
+	return documents, nil
+}
+
+// appwriteBasicDocument shapes the entities that only carry a name and raw data
+func (h *ExportHandler) appwriteBasicDocument(externalID, name string, data models.JSONB) map[string]interface{} {
+	doc := h.appwriteDocument(externalID, data)
+	h.setAppwriteLocalized(doc, "name", name, data)
+	return doc
+}
+
+// appwriteDocument starts a document with the fields every collection shares. The raw
+// data is kept as a JSON string since Appwrite has no object attribute type.
+func (h *ExportHandler) appwriteDocument(externalID string, data models.JSONB) map[string]interface{} {
+	return map[string]interface{}{
+		"$id":         appwriteDocumentID(externalID),
+		"external_id": externalID,
+		"data":        h.appwriteJSONString(data),
+	}
+}
+
+func (h *ExportHandler) appwriteJSONString(jsonb models.JSONB) string {
+	if jsonb == nil {
+		return ""
+	}
+	return h.marshalValueToString(jsonb)
+}
+
+// setAppwriteLocalized sets field to its English value and adds a "<field>_<lang>"
+// attribute for every other translation found in the raw data
+func (h *ExportHandler) setAppwriteLocalized(doc map[string]interface{}, field, directValue string, data models.JSONB) {
+	doc[field] = h.extractEnglishValue(directValue, data, field)
+	if data == nil {
+		return
+	}
+
+	var translations map[string]interface{}
+	switch v := data[field].(type) {
+	case map[string]interface{}:
+		translations = v
+	case string:
+		if err := json.Unmarshal([]byte(v), &translations); err != nil {
+			return
+		}
+	}
+	for lang, value := range translations {
+		if text, ok := value.(string); ok && lang != "en" {
+			doc[field+"_"+strings.ToLower(lang)] = text
+		}
+	}
+}
+
+// appwriteStringArray unwraps an array stored under wrapKey (the sync service wraps arrays
+// in an object to fit JSONB) into a string array, JSON-encoding non-string elements
+func appwriteStringArray(jsonb models.JSONB, wrapKey string) []string {
+	if jsonb == nil {
+		return []string{}
+	}
+
+	arr, ok := jsonb[wrapKey].([]interface{})
+	if !ok {
+		data, err := json.Marshal(jsonb)
+		if err != nil {
+			return []string{}
+		}
+		return []string{string(data)}
+	}
+
+	result := make([]string, 0, len(arr))
+	for _, element := range arr {
+		if s, ok := element.(string); ok {
+			result = append(result, s)
+			continue
+		}
+		data, err := json.Marshal(element)
+		if err != nil {
+			continue
+		}
+		result = append(result, string(data))
+	}
+	return result
+}
+
+// appwriteDocumentID converts an external ID into a valid Appwrite document ID: at most 36
+// characters of a-z, A-Z, 0-9, '.', '-' and '_', not starting with a special character.
+// IDs that need rewriting get a hash suffix so distinct inputs stay distinct.
+func appwriteDocumentID(externalID string) string {
+	var b strings.Builder
+	for _, r := range externalID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	id := strings.TrimLeft(b.String(), ".-_")
+	if id == externalID && id != "" && len(id) <= appwriteMaxIDLength {
+		return id
+	}
+
+	sum := sha1.Sum([]byte(externalID))
+	suffix := hex.EncodeToString(sum[:])[:8]
+	if maxPrefix := appwriteMaxIDLength - len(suffix) - 1; len(id) > maxPrefix {
+		id = id[:maxPrefix]
+	}
+	if id == "" {
+		return suffix
+	}
+	return id + "_" + suffix
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestAppwriteDocumentID(t *testing.T) {
+	if got := appwriteDocumentID("ferro_rifle"); got != "ferro_rifle" {
+		t.Errorf("valid ID should be kept, got %q", got)
+	}
+
+	a := appwriteDocumentID("quest:a/b")
+	b := appwriteDocumentID("quest:a?b")
+	if a == b {
+		t.Errorf("distinct external IDs should map to distinct document IDs, both got %q", a)
+	}
+	if !strings.HasPrefix(a, "quest_a_b_") {
+		t.Errorf("expected sanitized prefix, got %q", a)
+	}
+
+	long := appwriteDocumentID(strings.Repeat("x", 60))
+	if len(long) > appwriteMaxIDLength {
+		t.Errorf("ID exceeds %d characters: %q", appwriteMaxIDLength, long)
+	}
+	if got := appwriteDocumentID("_hidden"); strings.HasPrefix(got, "_") {
+		t.Errorf("ID must not start with a special character, got %q", got)
+	}
+}
+
+func TestAppwriteStringArray(t *testing.T) {
+	wrapped := models.JSONB{"objectives": []interface{}{"Find the key", map[string]interface{}{"en": "Extract"}}}
+	got := appwriteStringArray(wrapped, "objectives")
+	if len(got) != 2 || got[0] != "Find the key" || got[1] != `{"en":"Extract"}` {
+		t.Errorf("unexpected array %v", got)
+	}
+
+	if got := appwriteStringArray(nil, "objectives"); got == nil || len(got) != 0 {
+		t.Errorf("nil JSONB should produce an empty array, got %v", got)
+	}
+}
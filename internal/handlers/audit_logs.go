@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/services"
+)
+
+type AuditLogHandler struct {
+	service *services.AuditLogRetentionService
+}
+
+func NewAuditLogHandler(service *services.AuditLogRetentionService) *AuditLogHandler {
+	return &AuditLogHandler{service: service}
+}
+
+// Stats reports the size of the audit log table
+// @Summary Get audit log table statistics
+// @Description Row count, size on disk including indexes, oldest and newest entry of the audit logs, plus the retention period, how many rows the next audit_log_prune run deletes and where pruned rows are archived.
+// @Tags management
+// @Accept json
+// @Produce json
+// @Success 200 {object} services.AuditLogRetentionStats "Audit log statistics"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/logs/stats [get]
+func (h *AuditLogHandler) Stats(c *gin.Context) {
+	stats, err := h.service.Stats(time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read audit log statistics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -103,3 +104,104 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		"token":   req.APIKey, // Return the key itself as the token to preserve context
 	})
 }
+
+// ListMySessions returns the active sessions of the authenticated user
+// @Summary List my sessions
+// @Description Returns the active JWTs and refresh tokens of the current user, including device, IP and last use.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string][]services.Session "Successfully fetched sessions"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /me/sessions [get]
+func (h *AuthHandler) ListMySessions(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	sessions, err := h.authService.ListSessions(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": sessions})
+}
+
+// RevokeMySession revokes one of the authenticated user's sessions
+// @Summary Revoke one of my sessions
+// @Description Revoke a single JWT or refresh token owned by the current user. Other sessions stay active.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID (e.g. jwt-12, refresh-4)"
+// @Success 200 {object} MessageResponse "Session revoked"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Session not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /me/sessions/{id} [delete]
+func (h *AuthHandler) RevokeMySession(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	err := h.authService.RevokeSession(user.ID, c.Param("id"))
+	if errors.Is(err, services.ErrSessionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+// Refresh exchanges a refresh token for a new access token and refresh token
+// @Summary Refresh an access token
+// @Description Exchange a refresh token for a short-lived access token and a new refresh token. The presented refresh token stops working; presenting it again revokes every refresh token descended from the same login.
+// @Tags auth
+// @Accept json
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param refresh_token formData string true "Refresh token"
+// @Success 200 {object} services.TokenPair "New access and refresh token"
+// @Failure 400 {object} ErrorResponse "refresh_token is required"
+// @Failure 401 {object} ErrorResponse "Invalid, expired or reused refresh token"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req struct {
+		RefreshToken string `form:"refresh_token" json:"refresh_token"`
+	}
+	_ = c.ShouldBind(&req)
+	if req.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "refresh_token is required"})
+		return
+	}
+
+	pair, _, err := h.authService.RefreshSession(req.RefreshToken, c.Request.UserAgent(), c.ClientIP())
+	if errors.Is(err, services.ErrRefreshTokenInvalid) || errors.Is(err, services.ErrRefreshTokenReused) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, pair)
+}
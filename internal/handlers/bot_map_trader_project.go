@@ -170,7 +170,8 @@ func NewTraderHandler(repo *repository.TraderRepository) *TraderHandler {
 // @Success 200 {object} PaginatedResponse{data=[]models.Trader} "Successfully fetched traders"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
 // @Failure 500 {object} ErrorResponse "Internal server error"
-// @Router /traders [get]
+// @Deprecated
+// @Router /repo-traders [get]
 func (h *TraderHandler) List(c *gin.Context) {
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
@@ -203,7 +204,8 @@ func (h *TraderHandler) List(c *gin.Context) {
 // @Failure 401 {object} ErrorResponse "Not authenticated"
 // @Failure 404 {object} ErrorResponse "Trader not found"
 // @Failure 500 {object} ErrorResponse "Internal server error"
-// @Router /traders/{id} [get]
+// @Deprecated
+// @Router /repo-traders/{id} [get]
 func (h *TraderHandler) Get(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
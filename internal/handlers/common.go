@@ -1,5 +1,12 @@
 package handlers
 
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/repository"
+)
+
 // ErrorResponse represents a standard error response
 type ErrorResponse struct {
 	Error string `json:"error" example:"Description of the error"`
@@ -23,3 +30,14 @@ type PaginatedResponse struct {
 	Data       interface{}       `json:"data"`
 	Pagination PaginationDetails `json:"pagination"`
 }
+
+// parseListFilters reads optional PostgREST-style filters (?type=eq.weapon&name=ilike.*alloy*)
+// for a list endpoint. It writes a 400 and returns false if a filter is malformed.
+func parseListFilters(c *gin.Context, columns map[string]string) ([]repository.Filter, bool) {
+	filters, err := repository.ParseFilters(c.Request.URL.Query(), columns)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return filters, true
+}
@@ -5,18 +5,21 @@ import (
 	"os"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/config"
 )
 
-type ConfigHandler struct{}
+type ConfigHandler struct {
+	branding config.Branding
+}
 
-func NewConfigHandler() *ConfigHandler {
-	return &ConfigHandler{}
+func NewConfigHandler(branding config.Branding) *ConfigHandler {
+	return &ConfigHandler{branding: branding}
 }
 
 // GetFrontendConfig returns frontend configuration (public config only)
 // GetFrontendConfig returns frontend configuration (public config only)
 // @Summary Get frontend configuration
-// @Description Returns public configuration settings for the frontend (e.g. Supabase details)
+// @Description Returns public configuration settings for the frontend (e.g. Supabase details and the BRAND_* white-label branding)
 // @Tags config
 // @Accept json
 // @Produce json
@@ -40,6 +43,7 @@ func (h *ConfigHandler) GetFrontendConfig(c *gin.Context) {
 			"url":       supabaseURL,
 			"anonKey":   supabaseAnonKey,
 		},
+		"branding": h.branding,
 	}
 
 	c.JSON(http.StatusOK, config)
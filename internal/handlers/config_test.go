@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/config"
+)
+
+func TestGetFrontendConfigServesBranding(t *testing.T) {
+	branding := config.Branding{
+		AppName:      "Raider Hub",
+		AccentColor:  "#f5a623",
+		SupportLinks: []config.BrandSupportLink{{Label: "Discord", URL: "https://discord.gg/raiders"}},
+	}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/config", NewConfigHandler(branding).GetFrontendConfig)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/config", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var resp struct {
+		Branding config.Branding `json:"branding"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(resp.Branding, branding) {
+		t.Errorf("branding = %+v, want %+v", resp.Branding, branding)
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/repository"
+	"github.com/mat/arcapi/internal/services"
+)
+
+// maxDashboardDays caps the ?days= window of new users per day
+const maxDashboardDays = 90
+
+// DashboardTotals counts the main records
+type DashboardTotals struct {
+	Users         int64 `json:"users" example:"1520"`
+	ActiveAPIKeys int64 `json:"active_api_keys" example:"48"`
+	Quests        int64 `json:"quests" example:"72"`
+	Items         int64 `json:"items" example:"410"`
+}
+
+// DashboardActivity is recent usage, from the audit logs and user sign-ups
+type DashboardActivity struct {
+	RequestsLast24h int64                   `json:"requests_last_24h" example:"35210"`
+	NewUsersPerDay  []repository.DailyCount `json:"new_users_per_day"`
+}
+
+// DashboardStats is everything the admin dashboard home shows
+type DashboardStats struct {
+	Totals      DashboardTotals     `json:"totals"`
+	Activity    DashboardActivity   `json:"activity"`
+	Sync        services.SyncHealth `json:"sync"`
+	GeneratedAt time.Time           `json:"generated_at"`
+}
+
+type DashboardHandler struct {
+	userRepo     *repository.UserRepository
+	apiKeyRepo   *repository.APIKeyRepository
+	questRepo    *repository.QuestRepository
+	itemRepo     *repository.ItemRepository
+	auditLogRepo *repository.AuditLogRepository
+	syncService  *services.SyncService
+}
+
+func NewDashboardHandler(
+	userRepo *repository.UserRepository,
+	apiKeyRepo *repository.APIKeyRepository,
+	questRepo *repository.QuestRepository,
+	itemRepo *repository.ItemRepository,
+	auditLogRepo *repository.AuditLogRepository,
+	syncService *services.SyncService,
+) *DashboardHandler {
+	return &DashboardHandler{
+		userRepo:     userRepo,
+		apiKeyRepo:   apiKeyRepo,
+		questRepo:    questRepo,
+		itemRepo:     itemRepo,
+		auditLogRepo: auditLogRepo,
+		syncService:  syncService,
+	}
+}
+
+// Stats returns the admin dashboard statistics
+// @Summary Get admin dashboard statistics
+// @Description Totals (users, active API keys, quests, items), activity (requests in the last 24 hours from the audit logs, new users per UTC day) and this instance's sync health in one call.
+// @Tags management
+// @Accept json
+// @Produce json
+// @Param days query int false "Days of new users per day, at most 90" default(30)
+// @Success 200 {object} DashboardStats "Dashboard statistics"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/stats [get]
+func (h *DashboardHandler) Stats(c *gin.Context) {
+	days := 30
+	if d := c.Query("days"); d != "" {
+		if parsed, err := strconv.Atoi(d); err == nil && parsed > 0 && parsed <= maxDashboardDays {
+			days = parsed
+		}
+	}
+
+	now := time.Now().UTC()
+	stats := DashboardStats{GeneratedAt: now}
+	var err error
+	if stats.Totals.Users, err = h.userRepo.Count(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count users"})
+		return
+	}
+	if stats.Totals.ActiveAPIKeys, err = h.apiKeyRepo.CountActive(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count API keys"})
+		return
+	}
+	if stats.Totals.Quests, err = h.questRepo.Count(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count quests"})
+		return
+	}
+	if stats.Totals.Items, err = h.itemRepo.Count(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count items"})
+		return
+	}
+
+	if stats.Activity.RequestsLast24h, err = h.auditLogRepo.CountSince(now.Add(-24 * time.Hour)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count requests"})
+		return
+	}
+	firstDay := now.Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+	newUsers, err := h.userRepo.CountCreatedPerDay(firstDay)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count new users"})
+		return
+	}
+	stats.Activity.NewUsersPerDay = fillDailyCounts(newUsers, firstDay, days)
+
+	stats.Sync = h.syncService.Health()
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// fillDailyCounts returns one count per day from firstDay, with 0 for days missing from
+// counts, so charts get an evenly spaced series
+func fillDailyCounts(counts []repository.DailyCount, firstDay time.Time, days int) []repository.DailyCount {
+	byDay := make(map[string]int64, len(counts))
+	for _, c := range counts {
+		byDay[c.Day] = c.Count
+	}
+	filled := make([]repository.DailyCount, days)
+	for i := range filled {
+		day := firstDay.AddDate(0, 0, i).Format("2006-01-02")
+		filled[i] = repository.DailyCount{Day: day, Count: byDay[day]}
+	}
+	return filled
+}
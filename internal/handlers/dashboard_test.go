@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mat/arcapi/internal/repository"
+)
+
+func TestFillDailyCounts(t *testing.T) {
+	firstDay := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	counts := []repository.DailyCount{{Day: "2026-10-13", Count: 4}, {Day: "2026-10-15", Count: 1}}
+
+	want := []repository.DailyCount{
+		{Day: "2026-10-12", Count: 0},
+		{Day: "2026-10-13", Count: 4},
+		{Day: "2026-10-14", Count: 0},
+		{Day: "2026-10-15", Count: 1},
+	}
+	if got := fillDailyCounts(counts, firstDay, 4); !reflect.DeepEqual(got, want) {
+		t.Errorf("fillDailyCounts() = %v, want %v", got, want)
+	}
+}
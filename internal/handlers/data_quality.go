@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/middleware"
+	"github.com/mat/arcapi/internal/models"
+)
+
+// Reasons an objective could not be resolved into an item
+const (
+	unparsedReasonNoPattern   = "no_pattern"   // Text did not match any "<Verb> X ItemName" pattern
+	unparsedReasonUnknownItem = "unknown_item" // Pattern matched but the item name is unknown
+)
+
+// maxObjectiveCandidates caps the suggested items returned per unresolved objective
+const maxObjectiveCandidates = 5
+
+// UnparsedObjective describes a quest objective the required-items extractor could not resolve
+type UnparsedObjective struct {
+	QuestID         uint     `json:"quest_id"`
+	QuestExternalID string   `json:"quest_external_id"`
+	QuestName       string   `json:"quest_name"`
+	Text            string   `json:"text"`
+	Reason          string   `json:"reason"`
+	ExtractedName   string   `json:"extracted_name,omitempty"`
+	Quantity        int      `json:"quantity,omitempty"`
+	Attempted       []string `json:"attempted,omitempty"`
+	Candidates      []string `json:"candidates,omitempty"` // external_ids of items with similar names
+}
+
+// UnparsedObjectives lists quest objectives that could not be resolved into items
+// @Summary List unparsed quest objectives
+// @Description List text objectives the required-items extractor could not resolve, with the name it extracted, the lookups it attempted and similar items, so admins know where aliases are needed.
+// @Tags management
+// @Accept json
+// @Produce json
+// @Param reason query string false "Filter by reason (no_pattern, unknown_item)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Objectives per page" default(50)
+// @Success 200 {object} PaginatedResponse{data=[]UnparsedObjective} "Successfully fetched unparsed objectives"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/data-quality/unparsed-objectives [get]
+func (h *ItemHandler) UnparsedObjectives(c *gin.Context) {
+	page := 1
+	limit := 50
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= middleware.PageLimit(c) {
+			limit = parsed
+		}
+	}
+	reason := c.Query("reason")
+
+	if h.questRepo == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Required repositories not initialized"})
+		return
+	}
+
+	allItems, _, err := h.repo.FindAll(0, 10000)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch items"})
+		return
+	}
+	quests, _, err := h.questRepo.FindAll(0, 10000)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quests"})
+		return
+	}
+	var aliases []models.ItemAlias
+	if h.itemAliasRepo != nil {
+		if aliases, err = h.itemAliasRepo.ListAll(); err != nil {
+			log.Printf("Warning: Failed to fetch item aliases: %v", err)
+		}
+	}
+
+	unparsed := findUnparsedObjectives(quests, newRequiredItemsIndex(allItems, aliases))
+	if reason != "" {
+		filtered := unparsed[:0]
+		for _, u := range unparsed {
+			if u.Reason == reason {
+				filtered = append(filtered, u)
+			}
+		}
+		unparsed = filtered
+	}
+
+	total := len(unparsed)
+	start := (page - 1) * limit
+	if start > total {
+		start = total
+	}
+	end := start + limit
+	if end > total {
+		end = total
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": unparsed[start:end],
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// findUnparsedObjectives runs every text objective through the same matcher used by
+// RequiredItems and reports those that did not resolve in any of their languages
+func findUnparsedObjectives(quests []models.Quest, idx *requiredItemsIndex) []UnparsedObjective {
+	unparsed := []UnparsedObjective{}
+	for _, quest := range quests {
+		for _, texts := range questObjectiveTexts(quest) {
+			var best objectiveMatch
+			bestText := texts[0]
+			resolved := false
+			for _, text := range texts {
+				match := matchTextObjective(text, idx)
+				if match.ItemID != "" {
+					resolved = true
+					break
+				}
+				if match.PatternMatched && !best.PatternMatched {
+					best, bestText = match, text
+				}
+			}
+			if resolved {
+				continue
+			}
+
+			entry := UnparsedObjective{
+				QuestID:         quest.ID,
+				QuestExternalID: quest.ExternalID,
+				QuestName:       quest.Name,
+				Text:            bestText,
+				Reason:          unparsedReasonNoPattern,
+			}
+			if best.PatternMatched {
+				entry.Reason = unparsedReasonUnknownItem
+				entry.ExtractedName = best.ExtractedName
+				entry.Quantity = best.Quantity
+				entry.Attempted = best.Attempted
+				entry.Candidates = similarItems(best.ExtractedName, idx)
+			}
+			unparsed = append(unparsed, entry)
+		}
+	}
+	return unparsed
+}
+
+// questObjectiveTexts returns the free-text objectives of a quest. Each entry holds the
+// alternatives for one objective (its languages, English first). Objectives RequiredItems
+// resolves from their structured fields are left out, whatever their text says.
+func questObjectiveTexts(quest models.Quest) [][]string {
+	var objectives []interface{}
+	if list, ok := quest.Data["objectives"].([]interface{}); ok {
+		objectives = append(objectives, list...)
+	}
+	if list, ok := quest.Objectives["objectives"].([]interface{}); ok {
+		objectives = append(objectives, list...)
+	}
+
+	var result [][]string
+	for _, obj := range objectives {
+		switch v := obj.(type) {
+		case string:
+			if strings.TrimSpace(v) != "" {
+				result = append(result, []string{v})
+			}
+		case map[string]interface{}:
+			if hasStructuredRequirement(v) {
+				continue
+			}
+			if texts := objectiveTextsByPreference(v, objectiveLanguageCodes); len(texts) > 0 {
+				result = append(result, texts)
+				continue
+			}
+			for _, field := range []string{"text", "description"} {
+				if text, ok := v[field].(string); ok && strings.TrimSpace(text) != "" {
+					result = append(result, []string{text})
+				}
+			}
+		}
+	}
+	return result
+}
+
+// hasStructuredRequirement reports whether an objective names its items in fields that
+// parseItemRequirement resolves, either itself or in a list of requirements
+func hasStructuredRequirement(objective map[string]interface{}) bool {
+	if itemID, qty := parseItemRequirement(objective); itemID != "" && qty > 0 {
+		return true
+	}
+	for _, field := range []string{"requirementItemIds", "requiredItems", "requirements", "required_item_ids", "requirement_items"} {
+		reqItems, _ := objective[field].([]interface{})
+		for _, reqItem := range reqItems {
+			if itemReq, ok := reqItem.(map[string]interface{}); ok {
+				if itemID, qty := parseItemRequirement(itemReq); itemID != "" && qty > 0 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// similarItems suggests items whose names share a word with the extracted name
+func similarItems(extractedName string, idx *requiredItemsIndex) []string {
+	var words []string
+	for _, w := range strings.Fields(extractedName) {
+		if len([]rune(w)) >= 3 {
+			words = append(words, w)
+		}
+	}
+
+	var candidates []string
+	for i, name := range idx.displayNames {
+		for _, w := range words {
+			if strings.Contains(name, w) {
+				candidates = append(candidates, idx.items[i].ExternalID)
+				break
+			}
+		}
+		if len(candidates) >= maxObjectiveCandidates {
+			break
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+// deepLinkAPIPaths maps the entity types used in deep links to their API collection
+var deepLinkAPIPaths = map[string]string{
+	"quest":          "quests",
+	"item":           "items",
+	"skill_node":     "skill-nodes",
+	"hideout_module": "hideout-modules",
+	"enemy_type":     "enemy-types",
+	"trader":         "traders",
+	"bot":            "bots",
+	"map":            "maps",
+	"project":        "projects",
+}
+
+var deepLinkIDPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,128}$`)
+
+var errInvalidDeepLink = errors.New("invalid deep link")
+
+// DeepLink is the canonical set of links for an entity
+type DeepLink struct {
+	Type     string      `json:"type"`
+	ID       string      `json:"id"`
+	DeepLink string      `json:"deep_link"`           // e.g. arcdb://item/arc_alloy
+	ShareURL string      `json:"share_url,omitempty"` // Web link that opens the app when installed
+	APIPath  string      `json:"api_path"`
+	Data     interface{} `json:"data,omitempty"` // The entity, when resolving
+}
+
+// DeepLinkHandler resolves and generates app deep links (<scheme>://<type>/<external_id>)
+// and their web share links (<web URL>/<type>/<external_id>), so clients don't each
+// implement the format
+type DeepLinkHandler struct {
+	scheme            string
+	webURL            string
+	questRepo         *repository.QuestRepository
+	itemRepo          *repository.ItemRepository
+	skillNodeRepo     *repository.SkillNodeRepository
+	hideoutModuleRepo *repository.HideoutModuleRepository
+	enemyTypeRepo     *repository.EnemyTypeRepository
+	traderRepo        *repository.TraderRepository
+	botRepo           *repository.BotRepository
+	mapRepo           *repository.MapRepository
+	projectRepo       *repository.ProjectRepository
+}
+
+func NewDeepLinkHandler(
+	scheme string,
+	webURL string,
+	questRepo *repository.QuestRepository,
+	itemRepo *repository.ItemRepository,
+	skillNodeRepo *repository.SkillNodeRepository,
+	hideoutModuleRepo *repository.HideoutModuleRepository,
+	enemyTypeRepo *repository.EnemyTypeRepository,
+	traderRepo *repository.TraderRepository,
+	botRepo *repository.BotRepository,
+	mapRepo *repository.MapRepository,
+	projectRepo *repository.ProjectRepository,
+) *DeepLinkHandler {
+	return &DeepLinkHandler{
+		scheme:            scheme,
+		webURL:            webURL,
+		questRepo:         questRepo,
+		itemRepo:          itemRepo,
+		skillNodeRepo:     skillNodeRepo,
+		hideoutModuleRepo: hideoutModuleRepo,
+		enemyTypeRepo:     enemyTypeRepo,
+		traderRepo:        traderRepo,
+		botRepo:           botRepo,
+		mapRepo:           mapRepo,
+		projectRepo:       projectRepo,
+	}
+}
+
+// parseDeepLink extracts the entity type and external ID from a deep link or share URL
+func parseDeepLink(raw, scheme, webURL string) (entityType, id string, err error) {
+	var path string
+	switch {
+	case strings.HasPrefix(raw, scheme+"://"):
+		path = strings.TrimPrefix(raw, scheme+"://")
+	case webURL != "" && strings.HasPrefix(raw, webURL+"/"):
+		path = strings.TrimPrefix(raw, webURL+"/")
+	default:
+		return "", "", errInvalidDeepLink
+	}
+	path, _, _ = strings.Cut(path, "?")
+	path, _, _ = strings.Cut(path, "#")
+
+	entityType, escapedID, ok := strings.Cut(strings.TrimSuffix(path, "/"), "/")
+	if !ok {
+		return "", "", errInvalidDeepLink
+	}
+	id, err = url.PathUnescape(escapedID)
+	if err != nil || !deepLinkIDPattern.MatchString(id) {
+		return "", "", errInvalidDeepLink
+	}
+	if _, ok := deepLinkAPIPaths[entityType]; !ok {
+		return "", "", errInvalidDeepLink
+	}
+	return entityType, id, nil
+}
+
+func (h *DeepLinkHandler) links(entityType, id string) DeepLink {
+	link := DeepLink{
+		Type:     entityType,
+		ID:       id,
+		DeepLink: h.scheme + "://" + entityType + "/" + url.PathEscape(id),
+		APIPath:  "/api/v1/" + deepLinkAPIPaths[entityType] + "/" + url.PathEscape(id),
+	}
+	if h.webURL != "" {
+		link.ShareURL = h.webURL + "/" + entityType + "/" + url.PathEscape(id)
+	}
+	return link
+}
+
+// find returns the entity a deep link points to
+func (h *DeepLinkHandler) find(entityType, id string) (interface{}, error) {
+	switch entityType {
+	case "quest":
+		return h.questRepo.FindByExternalID(id)
+	case "item":
+		return h.itemRepo.FindByExternalID(id)
+	case "skill_node":
+		return h.skillNodeRepo.FindByExternalID(id)
+	case "hideout_module":
+		return h.hideoutModuleRepo.FindByExternalID(id)
+	case "enemy_type":
+		return h.enemyTypeRepo.FindByExternalID(id)
+	case "trader":
+		return h.traderRepo.FindByExternalID(id)
+	case "bot":
+		return h.botRepo.FindByExternalID(id)
+	case "map":
+		return h.mapRepo.FindByExternalID(id)
+	default:
+		return h.projectRepo.FindByExternalID(id)
+	}
+}
+
+// Resolve resolves a deep link or share URL to its entity
+// @Summary Resolve a deep link
+// @Description Validate an app deep link (e.g. arcdb://item/arc_alloy) or web share link and return the entity it points to along with its canonical links and API path.
+// @Tags links
+// @Accept json
+// @Produce json
+// @Param url query string true "Deep link or share URL"
+// @Success 200 {object} DeepLink "Successfully resolved the link"
+// @Failure 400 {object} ErrorResponse "Invalid deep link"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Entity not found"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /resolve [get]
+func (h *DeepLinkHandler) Resolve(c *gin.Context) {
+	entityType, id, err := parseDeepLink(c.Query("url"), h.scheme, h.webURL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid deep link, expected " + h.scheme + "://<type>/<id>"})
+		return
+	}
+
+	entity, err := h.find(entityType, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Entity not found"})
+		return
+	}
+
+	link := h.links(entityType, id)
+	link.Data = entity
+	c.JSON(http.StatusOK, link)
+}
+
+// Links generates the deep link and share link of an entity
+// @Summary Get links for an entity
+// @Description Generate the app deep link and web share link of an entity by type and external ID.
+// @Tags links
+// @Accept json
+// @Produce json
+// @Param entity_type path string true "Entity type" Enums(quest, item, skill_node, hideout_module, enemy_type, trader, bot, map, project)
+// @Param id path string true "Entity external ID"
+// @Success 200 {object} DeepLink "Successfully generated links"
+// @Failure 400 {object} ErrorResponse "Invalid entity type or ID"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Entity not found"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /links/{entity_type}/{id} [get]
+func (h *DeepLinkHandler) Links(c *gin.Context) {
+	entityType := c.Param("entity_type")
+	id := c.Param("id")
+	if _, ok := deepLinkAPIPaths[entityType]; !ok || !deepLinkIDPattern.MatchString(id) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entity type or ID"})
+		return
+	}
+
+	if _, err := h.find(entityType, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Entity not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.links(entityType, id))
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import "testing"
+
+func TestParseDeepLink(t *testing.T) {
+	tests := []struct {
+		raw, wantType, wantID string
+		valid                 bool
+	}{
+		{"arcdb://item/arc_alloy", "item", "arc_alloy", true},
+		{"arcdb://enemy_type/rocketeer/?utm_source=x", "enemy_type", "rocketeer", true},
+		{"https://arcdb.app/quest/a_bad_feeling", "quest", "a_bad_feeling", true},
+		{"arcdb://item", "", "", false},
+		{"arcdb://weapon/arc_alloy", "", "", false},
+		{"arcdb://item/arc_alloy/extra", "", "", false},
+		{"https://evil.example/item/arc_alloy", "", "", false},
+		{"otherapp://item/arc_alloy", "", "", false},
+	}
+	for _, tt := range tests {
+		entityType, id, err := parseDeepLink(tt.raw, "arcdb", "https://arcdb.app")
+		if (err == nil) != tt.valid || entityType != tt.wantType || id != tt.wantID {
+			t.Errorf("parseDeepLink(%q) = %q, %q, %v", tt.raw, entityType, id, err)
+		}
+	}
+
+	h := &DeepLinkHandler{scheme: "arcdb", webURL: "https://arcdb.app"}
+	link := h.links("skill_node", "mobility_1")
+	if link.DeepLink != "arcdb://skill_node/mobility_1" || link.ShareURL != "https://arcdb.app/skill_node/mobility_1" || link.APIPath != "/api/v1/skill-nodes/mobility_1" {
+		t.Errorf("unexpected links %+v", link)
+	}
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/config"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/services"
+)
+
+// deviceCodeGrantType is the grant_type defined by RFC 8628 for device token polling
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+type DeviceAuthHandler struct {
+	deviceAuthService *services.DeviceAuthService
+	cfg               *config.Config
+}
+
+func NewDeviceAuthHandler(deviceAuthService *services.DeviceAuthService, cfg *config.Config) *DeviceAuthHandler {
+	return &DeviceAuthHandler{
+		deviceAuthService: deviceAuthService,
+		cfg:               cfg,
+	}
+}
+
+// RequestCode starts the device authorization grant
+// @Summary Start device login
+// @Description Start an OAuth 2.0 device authorization grant (RFC 8628). Show the returned user_code to the user and poll /auth/device/token with the device_code.
+// @Tags auth
+// @Accept json
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param client_id formData string false "Name of the client shown to the user (e.g. \"Stream overlay\")"
+// @Success 200 {object} map[string]interface{} "device_code, user_code, verification_uri, expires_in, interval"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /auth/device/code [post]
+func (h *DeviceAuthHandler) RequestCode(c *gin.Context) {
+	var req struct {
+		ClientID string `form:"client_id" json:"client_id"`
+	}
+	_ = c.ShouldBind(&req)
+
+	auth, err := h.deviceAuthService.RequestCode(req.ClientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start device authorization"})
+		return
+	}
+
+	verificationURI := h.verificationURI(c)
+	c.JSON(http.StatusOK, gin.H{
+		"device_code":               auth.DeviceCode,
+		"user_code":                 auth.UserCode,
+		"verification_uri":          verificationURI,
+		"verification_uri_complete": verificationURI + "?user_code=" + auth.UserCode,
+		"expires_in":                auth.ExpiresIn,
+		"interval":                  auth.Interval,
+	})
+}
+
+// Token is polled by the device until the user approves or denies it
+// @Summary Poll for device token
+// @Description Exchange a device_code for an access token and refresh token once the user has approved it. Returns RFC 8628 errors (authorization_pending, slow_down, access_denied, expired_token) while waiting. The access token is short-lived and renewed at /auth/refresh; device sessions can't perform privileged writes.
+// @Tags auth
+// @Accept json
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param device_code formData string true "Device code from /auth/device/code"
+// @Param grant_type formData string false "urn:ietf:params:oauth:grant-type:device_code"
+// @Success 200 {object} map[string]interface{} "access_token, token_type, expires_in, refresh_token and user"
+// @Failure 400 {object} map[string]string "RFC 8628 error code"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /auth/device/token [post]
+func (h *DeviceAuthHandler) Token(c *gin.Context) {
+	var req struct {
+		DeviceCode string `form:"device_code" json:"device_code"`
+		GrantType  string `form:"grant_type" json:"grant_type"`
+	}
+	_ = c.ShouldBind(&req)
+
+	if req.GrantType != "" && req.GrantType != deviceCodeGrantType {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported_grant_type"})
+		return
+	}
+	if req.DeviceCode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "error_description": "device_code is required"})
+		return
+	}
+
+	pair, user, err := h.deviceAuthService.ExchangeToken(req.DeviceCode, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrDeviceAuthorizationPending),
+			errors.Is(err, services.ErrDeviceSlowDown),
+			errors.Is(err, services.ErrDeviceAccessDenied),
+			errors.Is(err, services.ErrDeviceExpiredToken),
+			errors.Is(err, services.ErrDeviceInvalidGrant):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete device authorization"})
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"access_token":  pair.AccessToken,
+		"token_type":    pair.TokenType,
+		"expires_in":    pair.ExpiresIn,
+		"refresh_token": pair.RefreshToken,
+		"user":          user,
+	})
+}
+
+// Verify lets the signed-in user approve or deny a device by its user code
+// @Summary Approve or deny a device
+// @Description Approve (default) or deny the device showing the given user_code. The device then receives its own session, listed at /me/sessions where it can be revoked.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body map[string]interface{} true "user_code and optional approve (default true)"
+// @Success 200 {object} map[string]interface{} "Device approved or denied"
+// @Failure 400 {object} ErrorResponse "Invalid input data"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "User code not found or expired"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /auth/device/verify [post]
+func (h *DeviceAuthHandler) Verify(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	var req struct {
+		UserCode string `json:"user_code" binding:"required"`
+		Approve  *bool  `json:"approve"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	approve := req.Approve == nil || *req.Approve
+
+	code, err := h.deviceAuthService.Verify(user, req.UserCode, approve)
+	if err != nil {
+		if errors.Is(err, services.ErrDeviceUserCodeNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User code not found or expired"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update device authorization"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"approved":    approve,
+		"client_name": code.ClientName,
+	})
+}
+
+// verificationURI returns the configured device page, falling back to the dashboard on this host
+func (h *DeviceAuthHandler) verificationURI(c *gin.Context) string {
+	if h.cfg.DeviceVerificationURL != "" {
+		return strings.TrimRight(h.cfg.DeviceVerificationURL, "/")
+	}
+	scheme := "https"
+	if c.Request.TLS == nil && c.GetHeader("X-Forwarded-Proto") != "https" {
+		scheme = "http"
+	}
+	return scheme + "://" + c.Request.Host + "/dashboard/device"
+}
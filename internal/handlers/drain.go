@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/services"
+)
+
+type DrainHandler struct {
+	drainService *services.DrainService
+}
+
+func NewDrainHandler(drainService *services.DrainService) *DrainHandler {
+	return &DrainHandler{drainService: drainService}
+}
+
+// Drain starts a graceful drain of this instance
+// @Summary Drain this instance
+// @Description Stop accepting new requests on this instance, stop scheduling background jobs and wait for in-flight requests and running jobs to finish. Returns immediately; poll GET /admin/drain until the state is "drained", then stop the process. A drained instance keeps refusing requests until it is restarted.
+// @Tags management
+// @Accept json
+// @Produce json
+// @Success 202 {object} services.DrainStatus "Drain started"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/drain [post]
+func (h *DrainHandler) Drain(c *gin.Context) {
+	h.drainService.Drain()
+	c.JSON(http.StatusAccepted, h.drainService.Status())
+}
+
+// Status reports drain progress
+// @Summary Get drain progress
+// @Description Report whether this instance is serving, draining or drained, with the in-flight requests and busy background jobs a drain is waiting for.
+// @Tags management
+// @Accept json
+// @Produce json
+// @Success 200 {object} services.DrainStatus "Drain status"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/drain [get]
+func (h *DrainHandler) Status(c *gin.Context) {
+	c.JSON(http.StatusOK, h.drainService.Status())
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/middleware"
 	"github.com/mat/arcapi/internal/models"
 	"github.com/mat/arcapi/internal/repository"
 )
@@ -19,17 +20,23 @@ func NewEnemyTypeHandler(repo *repository.EnemyTypeRepository) *EnemyTypeHandler
 
 // List returns all enemy types (paginated)
 // @Summary List enemy types
-// @Description Fetch enemy types with optional pagination
+// @Description Fetch enemy types with optional pagination. Supports PostgREST-style filters on external_id, name and type (e.g. ?type=eq.Robot).
 // @Tags enemy-types
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
 // @Success 200 {object} PaginatedResponse{data=[]models.EnemyType} "Successfully fetched enemy types"
+// @Failure 400 {object} ErrorResponse "Invalid filter"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /enemy-types [get]
 func (h *EnemyTypeHandler) List(c *gin.Context) {
+	filters, ok := parseListFilters(c, repository.EnemyTypeFilterColumns)
+	if !ok {
+		return
+	}
+
 	page := 1
 	limit := 20
 
@@ -39,13 +46,13 @@ func (h *EnemyTypeHandler) List(c *gin.Context) {
 		}
 	}
 	if l := c.Query("limit"); l != "" {
-		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= middleware.PageLimit(c) {
 			limit = parsed
 		}
 	}
 
 	offset := (page - 1) * limit
-	enemyTypes, count, err := h.repo.FindAll(offset, limit)
+	enemyTypes, count, err := h.repo.FindFiltered(filters, offset, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch enemy types"})
 		return
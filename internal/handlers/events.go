@@ -0,0 +1,364 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+const (
+	// defaultEventDays is how far ahead GET /events looks without ?to=
+	defaultEventDays = 14
+	// maxEventRange bounds the window occurrences are expanded over
+	maxEventRange = 366 * 24 * time.Hour
+	// icalPastDays keeps recently ended events in the iCal feed
+	icalPastDays = 30
+	// icalTimeFormat is the UTC date-time form of RFC 5545
+	icalTimeFormat = "20060102T150405Z"
+)
+
+type GameEventHandler struct {
+	repo *repository.GameEventRepository
+}
+
+func NewGameEventHandler(repo *repository.GameEventRepository) *GameEventHandler {
+	return &GameEventHandler{repo: repo}
+}
+
+// List returns the event calendar
+// @Summary List game events
+// @Description Occurrences of in-game events between from and to (default: the next 14 days), soonest first, with recurring events expanded. With format=ics, returns an iCalendar feed of the events themselves, recurring ones as RRULEs, from 30 days ago onwards unless from/to are given, for calendar apps to subscribe to.
+// @Tags events
+// @Produce json,text/calendar
+// @Param from query string false "Start of the window (RFC 3339)"
+// @Param to query string false "End of the window (RFC 3339), at most a year after from"
+// @Param map query string false "Only events on this map external ID, including events on every map"
+// @Param mode query string false "Only events of this mode"
+// @Param format query string false "Response format" Enums(json, ics) default(json)
+// @Success 200 {array} models.GameEventOccurrence "Event occurrences"
+// @Failure 400 {object} ErrorResponse "Invalid window or format"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /events [get]
+func (h *GameEventHandler) List(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "ics" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or ics"})
+		return
+	}
+
+	now := time.Now().UTC()
+	from, to := now, now.AddDate(0, 0, defaultEventDays)
+	if format == "ics" {
+		from, to = now.AddDate(0, 0, -icalPastDays), time.Time{}
+	}
+	if f := c.Query("from"); f != "" {
+		parsed, err := time.Parse(time.RFC3339, f)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be an RFC 3339 time"})
+			return
+		}
+		from = parsed.UTC()
+		if format == "json" && c.Query("to") == "" {
+			to = from.AddDate(0, 0, defaultEventDays)
+		}
+	}
+	if t := c.Query("to"); t != "" {
+		parsed, err := time.Parse(time.RFC3339, t)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be an RFC 3339 time"})
+			return
+		}
+		to = parsed.UTC()
+	}
+	if !to.IsZero() && !to.After(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must be after from"})
+		return
+	}
+	if format == "json" && to.Sub(from) > maxEventRange {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be at most a year apart"})
+		return
+	}
+
+	events, err := h.repo.FindBetween(from, to, c.Query("map"), c.Query("mode"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+		return
+	}
+
+	if format == "ics" {
+		c.Header("Content-Type", "text/calendar; charset=utf-8")
+		c.Header("Content-Disposition", `inline; filename="events.ics"`)
+		if err := writeICalendar(c.Writer, events, now); err != nil {
+			c.Error(err)
+		}
+		return
+	}
+
+	occurrences := expandGameEvents(events, from, to)
+	c.JSON(http.StatusOK, gin.H{"data": occurrences, "from": from, "to": to, "total": len(occurrences)})
+}
+
+// expandGameEvents returns the occurrences of events within [from, to), soonest first
+func expandGameEvents(events []models.GameEvent, from, to time.Time) []models.GameEventOccurrence {
+	occurrences := []models.GameEventOccurrence{}
+	for i := range events {
+		occurrences = append(occurrences, events[i].Occurrences(from, to)...)
+	}
+	sort.Slice(occurrences, func(i, j int) bool {
+		if !occurrences[i].StartsAt.Equal(occurrences[j].StartsAt) {
+			return occurrences[i].StartsAt.Before(occurrences[j].StartsAt)
+		}
+		return occurrences[i].EventID < occurrences[j].EventID
+	})
+	return occurrences
+}
+
+// Get returns a single game event by ID
+// @Summary Get a game event
+// @Description Fetch a calendar event by its numeric ID, with its recurrence rather than its occurrences
+// @Tags events
+// @Produce json
+// @Param id path int true "Event ID"
+// @Success 200 {object} models.GameEvent "Successfully fetched the event"
+// @Failure 400 {object} ErrorResponse "Invalid event ID"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Event not found"
+// @Router /events/{id} [get]
+func (h *GameEventHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	event, err := h.repo.FindByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
+
+// Create adds an event to the calendar
+// @Summary Create a game event
+// @Description Add an in-game event to the calendar. Recurring events repeat daily, weekly or monthly from starts_at with the same duration, until recurrence_until if set.
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param event body models.GameEvent true "Event"
+// @Success 201 {object} models.GameEvent "Successfully created the event"
+// @Failure 400 {object} ErrorResponse "Invalid input data"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /events [post]
+func (h *GameEventHandler) Create(c *gin.Context) {
+	var event models.GameEvent
+	if err := c.ShouldBindJSON(&event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if msg := validateGameEvent(&event); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	event.ID = 0
+	if err := h.repo.Create(&event); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create event"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, event)
+}
+
+// Update replaces a calendar event
+// @Summary Update a game event
+// @Description Replace an existing calendar event by its ID
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param event body models.GameEvent true "Updated event"
+// @Success 200 {object} models.GameEvent "Successfully updated the event"
+// @Failure 400 {object} ErrorResponse "Invalid input or ID"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 404 {object} ErrorResponse "Event not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /events/{id} [put]
+func (h *GameEventHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	existing, err := h.repo.FindByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	var event models.GameEvent
+	if err := c.ShouldBindJSON(&event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if msg := validateGameEvent(&event); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	event.ID = existing.ID
+	event.CreatedAt = existing.CreatedAt
+	if err := h.repo.Update(&event); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event"})
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
+
+// Delete removes an event from the calendar
+// @Summary Delete a game event
+// @Description Delete a calendar event and all of its occurrences
+// @Tags events
+// @Param id path int true "Event ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse "Invalid event ID"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /events/{id} [delete]
+func (h *GameEventHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	if err := h.repo.Delete(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// validateGameEvent normalizes event's times to UTC and returns why it is invalid, or ""
+func validateGameEvent(event *models.GameEvent) string {
+	event.Name = strings.TrimSpace(event.Name)
+	if event.Name == "" {
+		return "name is required"
+	}
+	if event.StartsAt.IsZero() || event.EndsAt.IsZero() {
+		return "starts_at and ends_at are required"
+	}
+	event.StartsAt = event.StartsAt.UTC()
+	event.EndsAt = event.EndsAt.UTC()
+	if !event.EndsAt.After(event.StartsAt) {
+		return "ends_at must be after starts_at"
+	}
+	if !models.IsRecurrence(event.Recurrence) {
+		return "recurrence must be one of: daily, weekly, monthly"
+	}
+	if event.RecurrenceUntil != nil {
+		if event.Recurrence == "" {
+			return "recurrence_until requires a recurrence"
+		}
+		until := event.RecurrenceUntil.UTC()
+		if until.Before(event.StartsAt) {
+			return "recurrence_until must not be before starts_at"
+		}
+		event.RecurrenceUntil = &until
+	}
+	return ""
+}
+
+// writeICalendar writes events as an RFC 5545 calendar, recurring events as RRULEs
+func writeICalendar(w io.Writer, events []models.GameEvent, now time.Time) error {
+	var b strings.Builder
+	line := func(name, value string) {
+		writeICalLine(&b, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//ArcAPI//Game Events//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("X-WR-CALNAME", "Game events")
+	for _, e := range events {
+		stamp := e.UpdatedAt
+		if stamp.IsZero() {
+			stamp = now
+		}
+		line("BEGIN", "VEVENT")
+		line("UID", fmt.Sprintf("game-event-%d@arcapi", e.ID))
+		line("DTSTAMP", stamp.UTC().Format(icalTimeFormat))
+		line("DTSTART", e.StartsAt.UTC().Format(icalTimeFormat))
+		line("DTEND", e.EndsAt.UTC().Format(icalTimeFormat))
+		if e.Recurrence != "" {
+			rule := "FREQ=" + strings.ToUpper(e.Recurrence)
+			if e.RecurrenceUntil != nil {
+				rule += ";UNTIL=" + e.RecurrenceUntil.UTC().Format(icalTimeFormat)
+			}
+			line("RRULE", rule)
+		}
+		line("SUMMARY", escapeICalText(e.Name))
+		if e.Description != "" {
+			line("DESCRIPTION", escapeICalText(e.Description))
+		}
+		if e.MapID != "" {
+			line("LOCATION", escapeICalText(e.MapID))
+		}
+		if e.Mode != "" {
+			line("CATEGORIES", escapeICalText(e.Mode))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapeICalText escapes a TEXT value
+func escapeICalText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(s)
+}
+
+// writeICalLine writes a content line folded to 75 octets, without splitting a UTF-8
+// sequence, and ended with CRLF
+func writeICalLine(b *strings.Builder, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74 // Continuation lines start with a space
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestWriteICalendar(t *testing.T) {
+	start := time.Date(2026, 1, 5, 18, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 3, 30, 18, 0, 0, 0, time.UTC)
+	events := []models.GameEvent{
+		{ID: 7, Name: "Night raid; Dam, East", Description: "Line one\nLine two", StartsAt: start, EndsAt: start.Add(2 * time.Hour), Recurrence: models.RecurrenceWeekly, RecurrenceUntil: &until, MapID: "dam", Mode: "night_raid", UpdatedAt: start},
+		{ID: 8, Name: strings.Repeat("é", 60), StartsAt: start, EndsAt: start.Add(time.Hour)},
+	}
+
+	var b strings.Builder
+	if err := writeICalendar(&b, events, start); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:game-event-7@arcapi\r\n",
+		"DTSTART:20260105T180000Z\r\nDTEND:20260105T200000Z\r\n",
+		"RRULE:FREQ=WEEKLY;UNTIL=20260330T180000Z\r\n",
+		`SUMMARY:Night raid\; Dam\, East` + "\r\n",
+		`DESCRIPTION:Line one\nLine two` + "\r\n",
+		"LOCATION:dam\r\nCATEGORIES:night_raid\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("calendar is missing %q:\n%s", want, out)
+		}
+	}
+
+	// Long lines are folded at 75 octets without splitting characters
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 octets: %q", line)
+		}
+	}
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	if !strings.Contains(unfolded, "SUMMARY:"+strings.Repeat("é", 60)+"\r\n") {
+		t.Errorf("folded summary doesn't unfold to the name:\n%s", out)
+	}
+}
+
+func TestValidateGameEvent(t *testing.T) {
+	start := time.Date(2026, 1, 5, 18, 0, 0, 0, time.FixedZone("CET", 3600))
+	before := start.Add(-time.Hour)
+	cases := []struct {
+		name  string
+		event models.GameEvent
+		want  string
+	}{
+		{"valid", models.GameEvent{Name: "Cold Snap", StartsAt: start, EndsAt: start.Add(time.Hour), Recurrence: models.RecurrenceDaily}, ""},
+		{"no name", models.GameEvent{Name: " ", StartsAt: start, EndsAt: start.Add(time.Hour)}, "name is required"},
+		{"no end", models.GameEvent{Name: "x", StartsAt: start}, "starts_at and ends_at are required"},
+		{"ends first", models.GameEvent{Name: "x", StartsAt: start, EndsAt: start}, "ends_at must be after starts_at"},
+		{"bad recurrence", models.GameEvent{Name: "x", StartsAt: start, EndsAt: start.Add(time.Hour), Recurrence: "yearly"}, "recurrence must be one of: daily, weekly, monthly"},
+		{"until without recurrence", models.GameEvent{Name: "x", StartsAt: start, EndsAt: start.Add(time.Hour), RecurrenceUntil: &before}, "recurrence_until requires a recurrence"},
+		{"until before start", models.GameEvent{Name: "x", StartsAt: start, EndsAt: start.Add(time.Hour), Recurrence: models.RecurrenceWeekly, RecurrenceUntil: &before}, "recurrence_until must not be before starts_at"},
+	}
+	for _, tc := range cases {
+		if got := validateGameEvent(&tc.event); got != tc.want {
+			t.Errorf("%s: validateGameEvent() = %q, want %q", tc.name, got, tc.want)
+		}
+		if tc.want == "" && tc.event.StartsAt.Location() != time.UTC {
+			t.Errorf("%s: starts_at not normalized to UTC", tc.name)
+		}
+	}
+}
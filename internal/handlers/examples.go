@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/services"
+)
+
+type ExampleHandler struct {
+	exampleService *services.ExampleService
+}
+
+func NewExampleHandler(exampleService *services.ExampleService) *ExampleHandler {
+	return &ExampleHandler{exampleService: exampleService}
+}
+
+// Get returns an example record of an entity
+// @Summary Get an example record
+// @Description Fetch a real record of an entity, as returned by its detail endpoint, for documentation and client test fixtures. The record with the most data fields is picked after every sync, so examples follow the live data shape; its id and timestamps are replaced with fixed values.
+// @Tags meta
+// @Accept json
+// @Produce json
+// @Param entity path string true "Entity" Enums(quests, items, skill-nodes, hideout-modules, enemy-types, bots, maps, traders, projects)
+// @Success 200 {object} services.EntityExample "Example record"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Unknown entity, or no records to take an example from"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /meta/examples/{entity} [get]
+func (h *ExampleHandler) Get(c *gin.Context) {
+	example, err := h.exampleService.Example(c.Param("entity"))
+	switch {
+	case errors.Is(err, services.ErrUnknownExampleEntity):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown entity, expected one of: " + strings.Join(h.exampleService.Entities(), ", ")})
+		return
+	case errors.Is(err, services.ErrNoExample):
+		c.JSON(http.StatusNotFound, gin.H{"error": "No records to take an example from"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch example"})
+		return
+	}
+
+	c.JSON(http.StatusOK, example)
+}
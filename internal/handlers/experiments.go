@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/services"
+	"gorm.io/gorm"
+)
+
+type ExperimentHandler struct {
+	experimentService *services.ExperimentService
+}
+
+func NewExperimentHandler(experimentService *services.ExperimentService) *ExperimentHandler {
+	return &ExperimentHandler{experimentService: experimentService}
+}
+
+// MyExperiments returns the current user's experiment variants
+// @Summary List my experiment variants
+// @Description Fetch the variant the authenticated user is assigned in each enabled experiment they are enrolled in. Assignment is deterministic, so clients can cache it; experiments the user isn't enrolled in are omitted and should use the default behavior.
+// @Tags experiments
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string][]services.ExperimentAssignment "Successfully fetched experiment variants"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /me/experiments [get]
+func (h *ExperimentHandler) MyExperiments(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": h.experimentService.Assignments(user.ID)})
+}
+
+// List returns every experiment
+// @Summary List experiments
+// @Description Fetch all experiments with their rollout and variants, enabled or not.
+// @Tags management
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string][]models.Experiment "Successfully fetched experiments"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/experiments [get]
+func (h *ExperimentHandler) List(c *gin.Context) {
+	experiments, err := h.experimentService.ListExperiments()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch experiments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": experiments})
+}
+
+// Save creates an experiment or replaces its settings
+// @Summary Create or update an experiment
+// @Description Create an experiment or replace its settings. rollout_percent of users are enrolled and split between variants by weight. Raising the rollout only enrolls more users; changing variants or weights reassigns enrolled ones. Changes reach every instance within a minute.
+// @Tags management
+// @Accept json
+// @Produce json
+// @Param key path string true "Experiment key"
+// @Param experiment body map[string]interface{} true "description, enabled, rollout_percent and variants ([{name, weight}])"
+// @Success 200 {object} models.Experiment "Successfully saved the experiment"
+// @Failure 400 {object} ErrorResponse "Invalid input data"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/experiments/{key} [put]
+func (h *ExperimentHandler) Save(c *gin.Context) {
+	var req struct {
+		Description    string                    `json:"description"`
+		Enabled        bool                      `json:"enabled"`
+		RolloutPercent int                       `json:"rollout_percent"`
+		Variants       models.ExperimentVariants `json:"variants"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	experiment, err := h.experimentService.SaveExperiment(models.Experiment{
+		Key:            c.Param("key"),
+		Description:    req.Description,
+		Enabled:        req.Enabled,
+		RolloutPercent: req.RolloutPercent,
+		Variants:       req.Variants,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidExperiment) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save experiment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, experiment)
+}
+
+// Delete removes an experiment
+// @Summary Delete an experiment
+// @Description Delete an experiment. Its users fall back to the default behavior.
+// @Tags management
+// @Param key path string true "Experiment key"
+// @Success 204 "Successfully deleted the experiment"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 404 {object} ErrorResponse "Experiment not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/experiments/{key} [delete]
+func (h *ExperimentHandler) Delete(c *gin.Context) {
+	if err := h.experimentService.DeleteExperiment(c.Param("key")); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Experiment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete experiment"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -1,16 +1,21 @@
 package handlers
 
 import (
+	"archive/zip"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"strconv"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mat/arcapi/internal/models"
 	"github.com/mat/arcapi/internal/repository"
+	"github.com/xuri/excelize/v2"
 )
 
 type ExportHandler struct {
@@ -52,12 +57,14 @@ func NewExportHandler(
 	}
 }
 
-// ExportBots exports all bots as CSV (admin only)
+// ExportBots exports all bots (admin only)
 // @Summary Export bots
-// @Description Fetch all bot data in CSV format. Only admins can export data.
+// @Description Fetch all bot data as CSV, a JSON array or an XLSX workbook. Only admins can export data.
 // @Tags management
-// @Produce text/csv
-// @Success 200 {string} string "CSV file content"
+// @Produce text/csv,json,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "Export format" Enums(csv, json, xlsx) default(csv)
+// @Success 200 {string} string "Export file content"
+// @Failure 400 {object} ErrorResponse "Invalid format"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
 // @Failure 403 {object} ErrorResponse "Not an administrator"
 // @Failure 500 {object} ErrorResponse "Internal server error"
@@ -65,22 +72,17 @@ func NewExportHandler(
 // @Security BearerAuth
 // @Router /admin/export/bots [get]
 func (h *ExportHandler) ExportBots(c *gin.Context) {
-	bots, _, err := h.botRepo.FindAll(0, 10000) // Get all bots
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bots"})
-		return
-	}
-
-	csvData := h.botsToCSV(bots)
-	h.sendCSV(c, csvData, "bots")
+	h.sendExport(c, "bots", h.botsTable(h.botRepo.FindAllBatched))
 }
 
-// ExportMaps exports all maps as CSV (admin only)
+// ExportMaps exports all maps (admin only)
 // @Summary Export maps
-// @Description Fetch all map data in CSV format. Only admins can export data.
+// @Description Fetch all map data as CSV, a JSON array or an XLSX workbook. Only admins can export data.
 // @Tags management
-// @Produce text/csv
-// @Success 200 {string} string "CSV file content"
+// @Produce text/csv,json,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "Export format" Enums(csv, json, xlsx) default(csv)
+// @Success 200 {string} string "Export file content"
+// @Failure 400 {object} ErrorResponse "Invalid format"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
 // @Failure 403 {object} ErrorResponse "Not an administrator"
 // @Failure 500 {object} ErrorResponse "Internal server error"
@@ -88,22 +90,17 @@ func (h *ExportHandler) ExportBots(c *gin.Context) {
 // @Security BearerAuth
 // @Router /admin/export/maps [get]
 func (h *ExportHandler) ExportMaps(c *gin.Context) {
-	maps, _, err := h.mapRepo.FindAll(0, 10000) // Get all maps
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch maps"})
-		return
-	}
-
-	csvData := h.mapsToCSV(maps)
-	h.sendCSV(c, csvData, "maps")
+	h.sendExport(c, "maps", h.mapsTable(h.mapRepo.FindAllBatched))
 }
 
-// ExportTraders exports all traders as CSV (admin only)
+// ExportTraders exports all traders (admin only)
 // @Summary Export traders
-// @Description Fetch all trader data in CSV format. Only admins can export data.
+// @Description Fetch all trader data as CSV, a JSON array or an XLSX workbook. Only admins can export data.
 // @Tags management
-// @Produce text/csv
-// @Success 200 {string} string "CSV file content"
+// @Produce text/csv,json,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "Export format" Enums(csv, json, xlsx) default(csv)
+// @Success 200 {string} string "Export file content"
+// @Failure 400 {object} ErrorResponse "Invalid format"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
 // @Failure 403 {object} ErrorResponse "Not an administrator"
 // @Failure 500 {object} ErrorResponse "Internal server error"
@@ -111,22 +108,17 @@ func (h *ExportHandler) ExportMaps(c *gin.Context) {
 // @Security BearerAuth
 // @Router /admin/export/traders [get]
 func (h *ExportHandler) ExportTraders(c *gin.Context) {
-	traders, _, err := h.traderRepo.FindAll(0, 10000) // Get all traders
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch traders"})
-		return
-	}
-
-	csvData := h.tradersToCSV(traders)
-	h.sendCSV(c, csvData, "traders")
+	h.sendExport(c, "traders", h.tradersTable(h.traderRepo.FindAllBatched))
 }
 
-// ExportProjects exports all projects as CSV (admin only)
+// ExportProjects exports all projects (admin only)
 // @Summary Export projects
-// @Description Fetch all project data in CSV format. Only admins can export data.
+// @Description Fetch all project data as CSV, a JSON array or an XLSX workbook. Only admins can export data.
 // @Tags management
-// @Produce text/csv
-// @Success 200 {string} string "CSV file content"
+// @Produce text/csv,json,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "Export format" Enums(csv, json, xlsx) default(csv)
+// @Success 200 {string} string "Export file content"
+// @Failure 400 {object} ErrorResponse "Invalid format"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
 // @Failure 403 {object} ErrorResponse "Not an administrator"
 // @Failure 500 {object} ErrorResponse "Internal server error"
@@ -134,23 +126,17 @@ func (h *ExportHandler) ExportTraders(c *gin.Context) {
 // @Security BearerAuth
 // @Router /admin/export/projects [get]
 func (h *ExportHandler) ExportProjects(c *gin.Context) {
-	projects, _, err := h.projectRepo.FindAll(0, 10000) // Get all projects
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch projects"})
-		return
-	}
-
-	csvData := h.projectsToCSV(projects)
-	h.sendCSV(c, csvData, "projects")
+	h.sendExport(c, "projects", h.projectsTable(h.projectRepo.FindAllBatched))
 }
 
-// ExportQuests exports all quests as CSV
-// ExportQuests exports all quests as CSV (admin only)
+// ExportQuests exports all quests (admin only)
 // @Summary Export quests
-// @Description Fetch all quest data in CSV format. Only admins can export data.
+// @Description Fetch all quest data as CSV, a JSON array or an XLSX workbook. Only admins can export data.
 // @Tags management
-// @Produce text/csv
-// @Success 200 {string} string "CSV file content"
+// @Produce text/csv,json,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "Export format" Enums(csv, json, xlsx) default(csv)
+// @Success 200 {string} string "Export file content"
+// @Failure 400 {object} ErrorResponse "Invalid format"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
 // @Failure 403 {object} ErrorResponse "Not an administrator"
 // @Failure 500 {object} ErrorResponse "Internal server error"
@@ -158,23 +144,18 @@ func (h *ExportHandler) ExportProjects(c *gin.Context) {
 // @Security BearerAuth
 // @Router /admin/export/quests [get]
 func (h *ExportHandler) ExportQuests(c *gin.Context) {
-	quests, _, err := h.questRepo.FindAll(0, 10000) // Get all quests
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quests"})
-		return
-	}
-
-	csvData := h.questsToCSV(quests)
-	h.sendCSV(c, csvData, "quests")
+	h.sendExport(c, "quests", h.questsTable(h.questRepo.FindAllBatched))
 }
 
 // ExportItems exports all items as CSV
-// ExportItems exports all items as CSV (admin only)
+// ExportItems exports all items (admin only)
 // @Summary Export items
-// @Description Fetch all item data in CSV format. Only admins can export data.
+// @Description Fetch all item data as CSV, a JSON array or an XLSX workbook. Only admins can export data.
 // @Tags management
-// @Produce text/csv
-// @Success 200 {string} string "CSV file content"
+// @Produce text/csv,json,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "Export format" Enums(csv, json, xlsx) default(csv)
+// @Success 200 {string} string "Export file content"
+// @Failure 400 {object} ErrorResponse "Invalid format"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
 // @Failure 403 {object} ErrorResponse "Not an administrator"
 // @Failure 500 {object} ErrorResponse "Internal server error"
@@ -182,23 +163,18 @@ func (h *ExportHandler) ExportQuests(c *gin.Context) {
 // @Security BearerAuth
 // @Router /admin/export/items [get]
 func (h *ExportHandler) ExportItems(c *gin.Context) {
-	items, _, err := h.itemRepo.FindAll(0, 10000) // Get all items
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch items"})
-		return
-	}
-
-	csvData := h.itemsToCSV(items)
-	h.sendCSV(c, csvData, "items")
+	h.sendExport(c, "items", h.itemsTable(h.itemRepo.FindAllBatched))
 }
 
 // ExportSkillNodes exports all skill nodes as CSV
-// ExportSkillNodes exports all skill nodes as CSV (admin only)
+// ExportSkillNodes exports all skill nodes (admin only)
 // @Summary Export skill nodes
-// @Description Fetch all skill node data in CSV format. Only admins can export data.
+// @Description Fetch all skill node data as CSV, a JSON array or an XLSX workbook. Only admins can export data.
 // @Tags management
-// @Produce text/csv
-// @Success 200 {string} string "CSV file content"
+// @Produce text/csv,json,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "Export format" Enums(csv, json, xlsx) default(csv)
+// @Success 200 {string} string "Export file content"
+// @Failure 400 {object} ErrorResponse "Invalid format"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
 // @Failure 403 {object} ErrorResponse "Not an administrator"
 // @Failure 500 {object} ErrorResponse "Internal server error"
@@ -206,23 +182,18 @@ func (h *ExportHandler) ExportItems(c *gin.Context) {
 // @Security BearerAuth
 // @Router /admin/export/skill-nodes [get]
 func (h *ExportHandler) ExportSkillNodes(c *gin.Context) {
-	skillNodes, _, err := h.skillNodeRepo.FindAll(0, 10000) // Get all skill nodes
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch skill nodes"})
-		return
-	}
-
-	csvData := h.skillNodesToCSV(skillNodes)
-	h.sendCSV(c, csvData, "skill-nodes")
+	h.sendExport(c, "skill-nodes", h.skillNodesTable(h.skillNodeRepo.FindAllBatched))
 }
 
 // ExportHideoutModules exports all hideout modules as CSV
-// ExportHideoutModules exports all hideout modules as CSV (admin only)
+// ExportHideoutModules exports all hideout modules (admin only)
 // @Summary Export hideout modules
-// @Description Fetch all hideout module data in CSV format. Only admins can export data.
+// @Description Fetch all hideout module data as CSV, a JSON array or an XLSX workbook. Only admins can export data.
 // @Tags management
-// @Produce text/csv
-// @Success 200 {string} string "CSV file content"
+// @Produce text/csv,json,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "Export format" Enums(csv, json, xlsx) default(csv)
+// @Success 200 {string} string "Export file content"
+// @Failure 400 {object} ErrorResponse "Invalid format"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
 // @Failure 403 {object} ErrorResponse "Not an administrator"
 // @Failure 500 {object} ErrorResponse "Internal server error"
@@ -230,23 +201,18 @@ func (h *ExportHandler) ExportSkillNodes(c *gin.Context) {
 // @Security BearerAuth
 // @Router /admin/export/hideout-modules [get]
 func (h *ExportHandler) ExportHideoutModules(c *gin.Context) {
-	hideoutModules, _, err := h.hideoutModuleRepo.FindAll(0, 10000) // Get all hideout modules
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hideout modules"})
-		return
-	}
-
-	csvData := h.hideoutModulesToCSV(hideoutModules)
-	h.sendCSV(c, csvData, "hideout-modules")
+	h.sendExport(c, "hideout-modules", h.hideoutModulesTable(h.hideoutModuleRepo.FindAllBatched))
 }
 
 // ExportEnemyTypes exports all enemy types as CSV
-// ExportEnemyTypes exports all enemy types as CSV (admin only)
+// ExportEnemyTypes exports all enemy types (admin only)
 // @Summary Export enemy types
-// @Description Fetch all enemy type data in CSV format. Only admins can export data.
+// @Description Fetch all enemy type data as CSV, a JSON array or an XLSX workbook. Only admins can export data.
 // @Tags management
-// @Produce text/csv
-// @Success 200 {string} string "CSV file content"
+// @Produce text/csv,json,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "Export format" Enums(csv, json, xlsx) default(csv)
+// @Success 200 {string} string "Export file content"
+// @Failure 400 {object} ErrorResponse "Invalid format"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
 // @Failure 403 {object} ErrorResponse "Not an administrator"
 // @Failure 500 {object} ErrorResponse "Internal server error"
@@ -254,23 +220,18 @@ func (h *ExportHandler) ExportHideoutModules(c *gin.Context) {
 // @Security BearerAuth
 // @Router /admin/export/enemy-types [get]
 func (h *ExportHandler) ExportEnemyTypes(c *gin.Context) {
-	enemyTypes, _, err := h.enemyTypeRepo.FindAll(0, 10000) // Get all enemy types
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch enemy types"})
-		return
-	}
-
-	csvData := h.enemyTypesToCSV(enemyTypes)
-	h.sendCSV(c, csvData, "enemy-types")
+	h.sendExport(c, "enemy-types", h.enemyTypesTable(h.enemyTypeRepo.FindAllBatched))
 }
 
 // ExportAlerts exports all alerts as CSV
-// ExportAlerts exports all alerts as CSV (admin only)
+// ExportAlerts exports all alerts (admin only)
 // @Summary Export alerts
-// @Description Fetch all alert data in CSV format. Only admins can export data.
+// @Description Fetch all alert data as CSV, a JSON array or an XLSX workbook. Only admins can export data.
 // @Tags management
-// @Produce text/csv
-// @Success 200 {string} string "CSV file content"
+// @Produce text/csv,json,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "Export format" Enums(csv, json, xlsx) default(csv)
+// @Success 200 {string} string "Export file content"
+// @Failure 400 {object} ErrorResponse "Invalid format"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
 // @Failure 403 {object} ErrorResponse "Not an administrator"
 // @Failure 500 {object} ErrorResponse "Internal server error"
@@ -278,30 +239,376 @@ func (h *ExportHandler) ExportEnemyTypes(c *gin.Context) {
 // @Security BearerAuth
 // @Router /admin/export/alerts [get]
 func (h *ExportHandler) ExportAlerts(c *gin.Context) {
-	alerts, _, err := h.alertRepo.FindAll(0, 10000) // Get all alerts
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch alerts"})
+	h.sendExport(c, "alerts", h.alertsTable(h.alertRepo.FindAllBatched))
+}
+
+// ExportAll exports every entity in one file (admin only)
+// @Summary Export all data
+// @Description Fetch quests, items, skill nodes, hideout modules, enemy types, alerts, bots, maps, traders and projects in one file: a zip of one CSV per entity (the default, as uploaded by the backup job), a JSON object of arrays keyed by entity, or an XLSX workbook with one sheet per entity. Only admins can export data.
+// @Tags management
+// @Produce application/zip,json,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "Export format; csv produces a zip archive" Enums(csv, json, xlsx) default(csv)
+// @Success 200 {string} string "Export file content"
+// @Failure 400 {object} ErrorResponse "Invalid format"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Not an administrator"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/export/all [get]
+func (h *ExportHandler) ExportAll(c *gin.Context) {
+	h.sendExport(c, "arcapi-export", h.allTables()...)
+}
+
+// allTables lists every exported entity
+func (h *ExportHandler) allTables() []exportTable {
+	return []exportTable{
+		h.questsTable(h.questRepo.FindAllBatched),
+		h.itemsTable(h.itemRepo.FindAllBatched),
+		h.skillNodesTable(h.skillNodeRepo.FindAllBatched),
+		h.hideoutModulesTable(h.hideoutModuleRepo.FindAllBatched),
+		h.enemyTypesTable(h.enemyTypeRepo.FindAllBatched),
+		h.alertsTable(h.alertRepo.FindAllBatched),
+		h.botsTable(h.botRepo.FindAllBatched),
+		h.mapsTable(h.mapRepo.FindAllBatched),
+		h.tradersTable(h.traderRepo.FindAllBatched),
+		h.projectsTable(h.projectRepo.FindAllBatched),
+	}
+}
+
+// WriteArchive writes the full export, a zip of one CSV per entity, to w. The backup
+// job uploads it.
+func (h *ExportHandler) WriteArchive(w io.Writer) error {
+	return h.writeArchive(w, h.allTables(), func() {})
+}
+
+// Export formats selected with ?format=
+const (
+	exportFormatCSV  = "csv"
+	exportFormatJSON = "json"
+	exportFormatXLSX = "xlsx"
+)
+
+// exportBatchSize is how many rows an export reads and writes at a time
+const exportBatchSize = 1000
+
+// exportJSON marks a JSONB column. CSV keeps the Appwrite string-array encoding, JSON
+// exports embed the value as is, and XLSX cells hold it as compact JSON.
+type exportJSON struct {
+	value models.JSONB
+}
+
+// exportTable is one entity's export: column names, and a function that streams its rows
+// in batches with one typed value per column
+type exportTable struct {
+	name    string
+	columns []string
+	batches func(fn func(rows [][]interface{}) error) error
+}
+
+// exportRows adapts a repository's FindAllBatched to exportTable.batches, converting each
+// entity to a row
+func exportRows[T any](findAllBatched func(int, func([]T) error) error, row func(T) []interface{}) func(func([][]interface{}) error) error {
+	return func(fn func([][]interface{}) error) error {
+		return findAllBatched(exportBatchSize, func(batch []T) error {
+			rows := make([][]interface{}, len(batch))
+			for i, entity := range batch {
+				rows[i] = row(entity)
+			}
+			return fn(rows)
+		})
+	}
+}
+
+// exportFormat reads ?format=, writing a 400 and returning false if it is invalid
+func exportFormat(c *gin.Context) (string, bool) {
+	switch format := c.DefaultQuery("format", exportFormatCSV); format {
+	case exportFormatCSV, exportFormatJSON, exportFormatXLSX:
+		return format, true
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format, expected csv, json or xlsx"})
+	return "", false
+}
+
+// sendExport writes tables as an attachment in the requested format. CSV holds a single
+// table, or is a zip of one CSV per table when there are several; JSON is one array of
+// objects per table (keyed by table name when there are several) and XLSX one sheet per
+// table. CSV and JSON are streamed to the client a batch
+// at a time, so memory use doesn't grow with the table size.
+func (h *ExportHandler) sendExport(c *gin.Context, filename string, tables ...exportTable) {
+	format, ok := exportFormat(c)
+	if !ok {
 		return
 	}
+	extension := format
+	if format == exportFormatCSV && len(tables) > 1 {
+		extension = "zip"
+	}
+
+	filename = fmt.Sprintf("%s-%s.%s", filename, time.Now().Format("20060102-150405"), extension)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+
+	var err error
+	switch format {
+	case exportFormatJSON:
+		err = h.sendJSON(c, tables)
+	case exportFormatXLSX:
+		err = h.sendXLSX(c, tables)
+	default:
+		if len(tables) > 1 {
+			c.Header("Content-Type", "application/zip")
+			err = h.writeArchive(c.Writer, tables, c.Writer.Flush)
+		} else {
+			c.Header("Content-Type", "text/csv")
+			err = h.writeCSV(c.Writer, tables[0], c.Writer.Flush)
+		}
+	}
+	if err == nil {
+		return
+	}
+
+	// Once streaming has started the status is sent, so all that's left is to cut the
+	// response short and log why
+	log.Printf("Export %s failed: %v", filename, err)
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Disposition")
+		c.Writer.Header().Del("Content-Type")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export data"})
+	}
+	c.Abort()
+}
+
+// writeCSV writes a table as CSV, calling flush after every batch
+func (h *ExportHandler) writeCSV(w io.Writer, table exportTable, flush func()) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(table.columns); err != nil {
+		return err
+	}
+	record := make([]string, len(table.columns))
+	err := table.batches(func(rows [][]interface{}) error {
+		for _, row := range rows {
+			for i, value := range row {
+				record[i] = h.csvValue(value)
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeArchive writes a zip with one <table>.csv per table
+func (h *ExportHandler) writeArchive(w io.Writer, tables []exportTable, flush func()) error {
+	archive := zip.NewWriter(w)
+	for _, table := range tables {
+		entry, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     table.name + ".csv",
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		if err := h.writeCSV(entry, table, flush); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+func (h *ExportHandler) csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	case bool:
+		return strconv.FormatBool(v)
+	case exportJSON:
+		return h.jsonToStringArray(v.value)
+	}
+	return fmt.Sprint(value)
+}
+
+// sendJSON streams each table as an array of objects, flushed after every batch
+func (h *ExportHandler) sendJSON(c *gin.Context, tables []exportTable) error {
+	c.Header("Content-Type", "application/json; charset=utf-8")
+
+	combined := len(tables) > 1
+	if combined {
+		if _, err := c.Writer.WriteString("{"); err != nil {
+			return err
+		}
+	}
+	for i, table := range tables {
+		if combined {
+			name, _ := json.Marshal(table.name)
+			if i > 0 {
+				name = append([]byte(","), name...)
+			}
+			if _, err := c.Writer.Write(append(name, ':')); err != nil {
+				return err
+			}
+		}
+		if err := h.writeJSONArray(c, table); err != nil {
+			return err
+		}
+	}
+	if combined {
+		if _, err := c.Writer.WriteString("}"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *ExportHandler) writeJSONArray(c *gin.Context, table exportTable) error {
+	if _, err := c.Writer.WriteString("["); err != nil {
+		return err
+	}
+	first := true
+	err := table.batches(func(rows [][]interface{}) error {
+		for _, row := range rows {
+			object := make(map[string]interface{}, len(table.columns))
+			for i, column := range table.columns {
+				if v, ok := row[i].(exportJSON); ok {
+					object[column] = v.value
+				} else {
+					object[column] = row[i]
+				}
+			}
+			data, err := json.Marshal(object)
+			if err != nil {
+				return err
+			}
+			if !first {
+				data = append([]byte(","), data...)
+			}
+			first = false
+			if _, err := c.Writer.Write(data); err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.Writer.WriteString("]")
+	return err
+}
+
+// sendXLSX writes each table to its own sheet with a bold, frozen header row and an Excel
+// table for banded rows and filters. The workbook can only be sent once complete, but the
+// stream writer spills large sheets to a temporary file instead of keeping them in memory.
+func (h *ExportHandler) sendXLSX(c *gin.Context, tables []exportTable) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Color: "FFFFFF"},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"1F4E78"}},
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, table := range tables {
+		if err := h.writeXLSXSheet(f, table, i == 0, headerStyle); err != nil {
+			return err
+		}
+	}
 
-	csvData := h.alertsToCSV(alerts)
-	h.sendCSV(c, csvData, "alerts")
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	return f.Write(c.Writer)
 }
 
-// Helper function to send CSV response
-func (h *ExportHandler) sendCSV(c *gin.Context, csvData [][]string, filename string) {
-	c.Header("Content-Type", "text/csv")
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s-%s.csv", filename, time.Now().Format("20060102-150405")))
+func (h *ExportHandler) writeXLSXSheet(f *excelize.File, table exportTable, first bool, headerStyle int) error {
+	// A new workbook starts with Sheet1, which becomes the first table's sheet
+	if first {
+		if err := f.SetSheetName("Sheet1", table.name); err != nil {
+			return err
+		}
+	} else if _, err := f.NewSheet(table.name); err != nil {
+		return err
+	}
 
-	writer := csv.NewWriter(c.Writer)
-	defer writer.Flush()
+	sw, err := f.NewStreamWriter(table.name)
+	if err != nil {
+		return err
+	}
+	if err := sw.SetColWidth(1, len(table.columns), 20); err != nil {
+		return err
+	}
+	if err := sw.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
 
-	for _, record := range csvData {
-		if err := writer.Write(record); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write CSV"})
-			return
+	header := make([]interface{}, len(table.columns))
+	for i, column := range table.columns {
+		header[i] = column
+	}
+	if err := sw.SetRow("A1", header, excelize.RowOpts{StyleID: headerStyle}); err != nil {
+		return err
+	}
+	rowCount := 0
+	err = table.batches(func(rows [][]interface{}) error {
+		for _, row := range rows {
+			cells := make([]interface{}, len(row))
+			for j, value := range row {
+				cells[j] = h.xlsxValue(value)
+			}
+			rowCount++
+			cell, _ := excelize.CoordinatesToCellName(1, rowCount+1)
+			if err := sw.SetRow(cell, cells); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if rowCount > 0 {
+		lastCell, _ := excelize.CoordinatesToCellName(len(table.columns), rowCount+1)
+		if err := sw.AddTable(&excelize.Table{Range: "A1:" + lastCell, StyleName: "TableStyleMedium2"}); err != nil {
+			return err
+		}
+	}
+	return sw.Flush()
+}
+
+// xlsxValue keeps numbers and booleans typed and encodes JSON columns as compact JSON,
+// truncated to the cell size limit
+func (h *ExportHandler) xlsxValue(value interface{}) interface{} {
+	var s string
+	switch v := value.(type) {
+	case exportJSON:
+		if v.value == nil {
+			return nil
+		}
+		s = h.marshalValueToString(v.value)
+	case string:
+		s = v
+	default:
+		return value
 	}
+	if utf8.RuneCountInString(s) > excelize.TotalCellChars {
+		s = string([]rune(s)[:excelize.TotalCellChars])
+	}
+	return s
 }
 
 // Helper function to extract English ("en") value from a field, checking both direct field and Data JSONB
@@ -371,206 +678,181 @@ func (h *ExportHandler) marshalValueToString(value interface{}) string {
 	return string(bytes)
 }
 
-// Convert quests to CSV format
-func (h *ExportHandler) questsToCSV(quests []models.Quest) [][]string {
-	headers := []string{
+// Export columns per entity, in file order. Imports accept the same columns.
+var (
+	questExportColumns = []string{
 		"system_id", "external_id", "name", "description", "trader", "xp",
 		"objectives", "reward_item_ids", "data",
 	}
-
-	rows := [][]string{headers}
-
-	for _, quest := range quests {
-		objectives := h.jsonToStringArray(quest.Objectives)
-		rewardItemIds := h.jsonToStringArray(quest.RewardItemIds)
-		data := h.jsonToStringArray(quest.Data)
-
-		// Extract name, preferring "en" from JSON objects
-		name := h.extractEnglishValue(quest.Name, quest.Data, "name")
-
-		// Extract description, preferring "en" from JSON objects
-		description := h.extractEnglishValue(quest.Description, quest.Data, "description")
-
-		row := []string{
-			strconv.Itoa(int(quest.ID)),
-			quest.ExternalID,
-			name,
-			description,
-			quest.Trader,
-			strconv.Itoa(quest.XP),
-			objectives,
-			rewardItemIds,
-			data,
-		}
-		rows = append(rows, row)
-	}
-
-	return rows
-}
-
-// Convert items to CSV format
-func (h *ExportHandler) itemsToCSV(items []models.Item) [][]string {
-	headers := []string{
+	itemExportColumns = []string{
 		"system_id", "external_id", "name", "description", "type",
 		"image_url", "image_filename", "data",
 	}
-
-	rows := [][]string{headers}
-
-	for _, item := range items {
-		data := h.jsonToStringArray(item.Data)
-
-		// Extract name and description, preferring "en" from JSON objects
-		name := h.extractEnglishValue(item.Name, item.Data, "name")
-		description := h.extractEnglishValue(item.Description, item.Data, "description")
-
-		row := []string{
-			strconv.Itoa(int(item.ID)),
-			item.ExternalID,
-			name,
-			description,
-			item.Type,
-			item.ImageURL,
-			item.ImageFilename,
-			data,
-		}
-		rows = append(rows, row)
-	}
-
-	return rows
-}
-
-// Convert skill nodes to CSV format
-func (h *ExportHandler) skillNodesToCSV(skillNodes []models.SkillNode) [][]string {
-	headers := []string{
+	skillNodeExportColumns = []string{
 		"system_id", "external_id", "name", "description", "impacted_skill", "category",
 		"max_points", "icon_name", "is_major", "position", "known_value",
 		"prerequisite_node_ids", "data",
 	}
-
-	rows := [][]string{headers}
-
-	for _, node := range skillNodes {
-		position := h.jsonToStringArray(node.Position)
-		knownValue := h.jsonToStringArray(node.KnownValue)
-		prerequisiteNodeIds := h.jsonToStringArray(node.PrerequisiteNodeIds)
-		data := h.jsonToStringArray(node.Data)
-
-		// Extract name and description, preferring "en" from JSON objects
-		name := h.extractEnglishValue(node.Name, node.Data, "name")
-		description := h.extractEnglishValue(node.Description, node.Data, "description")
-
-		row := []string{
-			strconv.Itoa(int(node.ID)),
-			node.ExternalID,
-			name,
-			description,
-			node.ImpactedSkill,
-			node.Category,
-			strconv.Itoa(node.MaxPoints),
-			node.IconName,
-			strconv.FormatBool(node.IsMajor),
-			position,
-			knownValue,
-			prerequisiteNodeIds,
-			data,
-		}
-		rows = append(rows, row)
-	}
-
-	return rows
-}
-
-// Convert hideout modules to CSV format
-func (h *ExportHandler) hideoutModulesToCSV(modules []models.HideoutModule) [][]string {
-	headers := []string{
+	hideoutModuleExportColumns = []string{
 		"system_id", "external_id", "name", "description", "max_level",
 		"levels", "data",
 	}
-
-	rows := [][]string{headers}
-
-	for _, module := range modules {
-		levels := h.jsonToStringArray(module.Levels)
-		data := h.jsonToStringArray(module.Data)
-
-		// Extract name and description, preferring "en" from JSON objects
-		name := h.extractEnglishValue(module.Name, module.Data, "name")
-		description := h.extractEnglishValue(module.Description, module.Data, "description")
-
-		row := []string{
-			strconv.Itoa(int(module.ID)),
-			module.ExternalID,
-			name,
-			description,
-			strconv.Itoa(module.MaxLevel),
-			levels,
-			data,
-		}
-		rows = append(rows, row)
-	}
-
-	return rows
-}
-
-// Convert enemy types to CSV format
-func (h *ExportHandler) enemyTypesToCSV(enemyTypes []models.EnemyType) [][]string {
-	headers := []string{
+	enemyTypeExportColumns = []string{
 		"system_id", "external_id", "name", "description", "type",
 		"image_url", "image_filename", "weakpoints", "data",
 	}
-
-	rows := [][]string{headers}
-
-	for _, enemyType := range enemyTypes {
-		weakpoints := h.jsonToStringArray(enemyType.Weakpoints)
-		data := h.jsonToStringArray(enemyType.Data)
-
-		// Extract name and description, preferring "en" from JSON objects
-		name := h.extractEnglishValue(enemyType.Name, enemyType.Data, "name")
-		description := h.extractEnglishValue(enemyType.Description, enemyType.Data, "description")
-
-		row := []string{
-			strconv.Itoa(int(enemyType.ID)),
-			enemyType.ExternalID,
-			name,
-			description,
-			enemyType.Type,
-			enemyType.ImageURL,
-			enemyType.ImageFilename,
-			weakpoints,
-			data,
-		}
-		rows = append(rows, row)
+	alertExportColumns = []string{
+		"system_id", "name", "description", "severity", "is_active",
+		"data",
 	}
+	botExportColumns     = []string{"system_id", "external_id", "name", "data"}
+	mapExportColumns     = []string{"system_id", "external_id", "name", "data"}
+	traderExportColumns  = []string{"system_id", "external_id", "name", "data"}
+	projectExportColumns = []string{"system_id", "external_id", "name", "data"}
+)
 
-	return rows
+// Convert quests to an export table
+func (h *ExportHandler) questsTable(findAllBatched func(int, func([]models.Quest) error) error) exportTable {
+	return exportTable{
+		name:    "quests",
+		columns: questExportColumns,
+		batches: exportRows(findAllBatched, func(quest models.Quest) []interface{} {
+			// Extract name and description, preferring "en" from JSON objects
+			name := h.extractEnglishValue(quest.Name, quest.Data, "name")
+			description := h.extractEnglishValue(quest.Description, quest.Data, "description")
+
+			return []interface{}{
+				quest.ID,
+				quest.ExternalID,
+				name,
+				description,
+				quest.Trader,
+				quest.XP,
+				exportJSON{quest.Objectives},
+				exportJSON{quest.RewardItemIds},
+				exportJSON{quest.Data},
+			}
+		}),
+	}
 }
 
-// Convert alerts to CSV format
-func (h *ExportHandler) alertsToCSV(alerts []models.Alert) [][]string {
-	headers := []string{
-		"system_id", "name", "description", "severity", "is_active",
-		"data",
+// Convert items to an export table
+func (h *ExportHandler) itemsTable(findAllBatched func(int, func([]models.Item) error) error) exportTable {
+	return exportTable{
+		name:    "items",
+		columns: itemExportColumns,
+		batches: exportRows(findAllBatched, func(item models.Item) []interface{} {
+			// Extract name and description, preferring "en" from JSON objects
+			name := h.extractEnglishValue(item.Name, item.Data, "name")
+			description := h.extractEnglishValue(item.Description, item.Data, "description")
+
+			return []interface{}{
+				item.ID,
+				item.ExternalID,
+				name,
+				description,
+				item.Type,
+				item.ImageURL,
+				item.ImageFilename,
+				exportJSON{item.Data},
+			}
+		}),
 	}
+}
 
-	rows := [][]string{headers}
+// Convert skill nodes to an export table
+func (h *ExportHandler) skillNodesTable(findAllBatched func(int, func([]models.SkillNode) error) error) exportTable {
+	return exportTable{
+		name:    "skill-nodes",
+		columns: skillNodeExportColumns,
+		batches: exportRows(findAllBatched, func(node models.SkillNode) []interface{} {
+			// Extract name and description, preferring "en" from JSON objects
+			name := h.extractEnglishValue(node.Name, node.Data, "name")
+			description := h.extractEnglishValue(node.Description, node.Data, "description")
+
+			return []interface{}{
+				node.ID,
+				node.ExternalID,
+				name,
+				description,
+				node.ImpactedSkill,
+				node.Category,
+				node.MaxPoints,
+				node.IconName,
+				node.IsMajor,
+				exportJSON{node.Position},
+				exportJSON{node.KnownValue},
+				exportJSON{node.PrerequisiteNodeIds},
+				exportJSON{node.Data},
+			}
+		}),
+	}
+}
 
-	for _, alert := range alerts {
-		data := h.jsonToStringArray(alert.Data)
+// Convert hideout modules to an export table
+func (h *ExportHandler) hideoutModulesTable(findAllBatched func(int, func([]models.HideoutModule) error) error) exportTable {
+	return exportTable{
+		name:    "hideout-modules",
+		columns: hideoutModuleExportColumns,
+		batches: exportRows(findAllBatched, func(module models.HideoutModule) []interface{} {
+			// Extract name and description, preferring "en" from JSON objects
+			name := h.extractEnglishValue(module.Name, module.Data, "name")
+			description := h.extractEnglishValue(module.Description, module.Data, "description")
+
+			return []interface{}{
+				module.ID,
+				module.ExternalID,
+				name,
+				description,
+				module.MaxLevel,
+				exportJSON{module.Levels},
+				exportJSON{module.Data},
+			}
+		}),
+	}
+}
 
-		row := []string{
-			strconv.Itoa(int(alert.ID)),
-			alert.Name,
-			alert.Description,
-			alert.Severity,
-			strconv.FormatBool(alert.IsActive),
-			data,
-		}
-		rows = append(rows, row)
+// Convert enemy types to an export table
+func (h *ExportHandler) enemyTypesTable(findAllBatched func(int, func([]models.EnemyType) error) error) exportTable {
+	return exportTable{
+		name:    "enemy-types",
+		columns: enemyTypeExportColumns,
+		batches: exportRows(findAllBatched, func(enemyType models.EnemyType) []interface{} {
+			// Extract name and description, preferring "en" from JSON objects
+			name := h.extractEnglishValue(enemyType.Name, enemyType.Data, "name")
+			description := h.extractEnglishValue(enemyType.Description, enemyType.Data, "description")
+
+			return []interface{}{
+				enemyType.ID,
+				enemyType.ExternalID,
+				name,
+				description,
+				enemyType.Type,
+				enemyType.ImageURL,
+				enemyType.ImageFilename,
+				exportJSON{enemyType.Weakpoints},
+				exportJSON{enemyType.Data},
+			}
+		}),
 	}
+}
 
-	return rows
+// Convert alerts to an export table
+func (h *ExportHandler) alertsTable(findAllBatched func(int, func([]models.Alert) error) error) exportTable {
+	return exportTable{
+		name:    "alerts",
+		columns: alertExportColumns,
+		batches: exportRows(findAllBatched, func(alert models.Alert) []interface{} {
+			return []interface{}{
+				alert.ID,
+				alert.Name,
+				alert.Description,
+				alert.Severity,
+				alert.IsActive,
+				exportJSON{alert.Data},
+			}
+		}),
+	}
 }
 
 // Helper to convert JSONB to string array (for Appwrite compatibility)
@@ -619,82 +901,66 @@ func (h *ExportHandler) jsonToString(jsonb models.JSONB) string {
 	return h.jsonToStringArray(jsonb)
 }
 
-// Convert bots to CSV format
-func (h *ExportHandler) botsToCSV(bots []models.Bot) [][]string {
-	headers := []string{"system_id", "external_id", "name", "data"}
-
-	rows := [][]string{headers}
-
-	for _, bot := range bots {
-		data := h.jsonToStringArray(bot.Data)
-		row := []string{
-			strconv.Itoa(int(bot.ID)),
-			bot.ExternalID,
-			bot.Name,
-			data,
-		}
-		rows = append(rows, row)
+// Convert bots to an export table
+func (h *ExportHandler) botsTable(findAllBatched func(int, func([]models.Bot) error) error) exportTable {
+	return exportTable{
+		name:    "bots",
+		columns: botExportColumns,
+		batches: exportRows(findAllBatched, func(bot models.Bot) []interface{} {
+			return []interface{}{
+				bot.ID,
+				bot.ExternalID,
+				bot.Name,
+				exportJSON{bot.Data},
+			}
+		}),
 	}
-
-	return rows
 }
 
-// Convert maps to CSV format
-func (h *ExportHandler) mapsToCSV(maps []models.Map) [][]string {
-	headers := []string{"system_id", "external_id", "name", "data"}
-
-	rows := [][]string{headers}
-
-	for _, m := range maps {
-		data := h.jsonToStringArray(m.Data)
-		row := []string{
-			strconv.Itoa(int(m.ID)),
-			m.ExternalID,
-			m.Name,
-			data,
-		}
-		rows = append(rows, row)
+// Convert maps to an export table
+func (h *ExportHandler) mapsTable(findAllBatched func(int, func([]models.Map) error) error) exportTable {
+	return exportTable{
+		name:    "maps",
+		columns: mapExportColumns,
+		batches: exportRows(findAllBatched, func(m models.Map) []interface{} {
+			return []interface{}{
+				m.ID,
+				m.ExternalID,
+				m.Name,
+				exportJSON{m.Data},
+			}
+		}),
 	}
-
-	return rows
 }
 
-// Convert traders to CSV format
-func (h *ExportHandler) tradersToCSV(traders []models.Trader) [][]string {
-	headers := []string{"system_id", "external_id", "name", "data"}
-
-	rows := [][]string{headers}
-
-	for _, trader := range traders {
-		data := h.jsonToStringArray(trader.Data)
-		row := []string{
-			strconv.Itoa(int(trader.ID)),
-			trader.ExternalID,
-			trader.Name,
-			data,
-		}
-		rows = append(rows, row)
+// Convert traders to an export table
+func (h *ExportHandler) tradersTable(findAllBatched func(int, func([]models.Trader) error) error) exportTable {
+	return exportTable{
+		name:    "traders",
+		columns: traderExportColumns,
+		batches: exportRows(findAllBatched, func(trader models.Trader) []interface{} {
+			return []interface{}{
+				trader.ID,
+				trader.ExternalID,
+				trader.Name,
+				exportJSON{trader.Data},
+			}
+		}),
 	}
-
-	return rows
 }
 
-// Convert projects to CSV format
-func (h *ExportHandler) projectsToCSV(projects []models.Project) [][]string {
-	headers := []string{"system_id", "external_id", "name", "data"}
-
-	rows := [][]string{headers}
-
-	for _, project := range projects {
-		data := h.jsonToStringArray(project.Data)
-		row := []string{
-			strconv.Itoa(int(project.ID)),
-			project.ExternalID,
-			project.Name,
-			data,
-		}
-		rows = append(rows, row)
+// Convert projects to an export table
+func (h *ExportHandler) projectsTable(findAllBatched func(int, func([]models.Project) error) error) exportTable {
+	return exportTable{
+		name:    "projects",
+		columns: projectExportColumns,
+		batches: exportRows(findAllBatched, func(project models.Project) []interface{} {
+			return []interface{}{
+				project.ID,
+				project.ExternalID,
+				project.Name,
+				exportJSON{project.Data},
+			}
+		}),
 	}
-
-	return rows
 }
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestSendExportFormats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &ExportHandler{}
+	items := []models.Item{
+		{ID: 7, ExternalID: "rusted_gear", Name: "Rusted Gear", Data: models.JSONB{"value": 120}},
+		{ID: 8, ExternalID: "arc_alloy", Name: "ARC Alloy"},
+	}
+	// Streams one item per batch
+	findItems := func(batchSize int, fn func([]models.Item) error) error {
+		for i := range items {
+			if err := fn(items[i : i+1]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	findBots := func(batchSize int, fn func([]models.Bot) error) error { return nil }
+
+	export := func(format string, tables ...exportTable) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/?format="+format, nil)
+		h.sendExport(c, "items", tables...)
+		return w
+	}
+
+	w := export("csv", h.itemsTable(findItems))
+	if !strings.HasPrefix(w.Body.String(), "system_id,external_id,name") || !strings.Contains(w.Body.String(), "7,rusted_gear,Rusted Gear") || !strings.Contains(w.Body.String(), "8,arc_alloy,ARC Alloy") {
+		t.Errorf("unexpected CSV %q", w.Body.String())
+	}
+
+	w = export("json", h.itemsTable(findItems))
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &rows); err != nil || len(rows) != 2 {
+		t.Fatalf("expected a JSON array with two rows, got %q", w.Body.String())
+	}
+	if rows[0]["system_id"] != float64(7) || rows[0]["data"].(map[string]interface{})["value"] != float64(120) {
+		t.Errorf("expected typed JSON values, got %v", rows[0])
+	}
+
+	w = export("json", h.itemsTable(findItems), h.botsTable(findBots))
+	var combined map[string][]map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &combined); err != nil || len(combined["items"]) != 2 || combined["bots"] == nil {
+		t.Errorf("expected a JSON object keyed by table, got %q", w.Body.String())
+	}
+
+	w = export("xlsx", h.itemsTable(findItems), h.botsTable(findBots))
+	f, err := excelize.OpenReader(bytes.NewReader(w.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("invalid XLSX: %v", err)
+	}
+	if sheets := f.GetSheetList(); len(sheets) != 2 || sheets[0] != "items" || sheets[1] != "bots" {
+		t.Errorf("expected one sheet per table, got %v", sheets)
+	}
+	if v, _ := f.GetCellValue("items", "B2"); v != "rusted_gear" {
+		t.Errorf("expected rusted_gear in B2, got %q", v)
+	}
+
+	w = export("csv", h.itemsTable(findItems), h.botsTable(findBots))
+	archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil || len(archive.File) != 2 || archive.File[0].Name != "items.csv" || archive.File[1].Name != "bots.csv" {
+		t.Errorf("expected a zip of one CSV per table, got %v", err)
+	}
+	failing := func(batchSize int, fn func([]models.Item) error) error { return errors.New("connection reset") }
+	if w = export("csv", h.itemsTable(failing)); w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 when the first read fails, got %d %q", w.Code, w.Body.String())
+	}
+	if w = export("pdf", h.itemsTable(findItems)); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown format, got %d", w.Code)
+	}
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+// favoriteStore is the part of FavoriteRepository the handler uses
+type favoriteStore interface {
+	Add(userID uint, entityType, entityID string) (*models.Favorite, bool, error)
+	FindByUserID(userID uint, entityType string) ([]models.Favorite, error)
+	Remove(userID uint, entityType, entityID string) (int64, error)
+}
+
+type FavoriteHandler struct {
+	favoriteRepo favoriteStore
+	// entityExists reports whether entityID names an existing entity of entityType; ok is
+	// false for entity types that can't be favorited
+	entityExists func(entityType, entityID string) (exists, ok bool)
+}
+
+func NewFavoriteHandler(
+	favoriteRepo *repository.FavoriteRepository,
+	questRepo *repository.QuestRepository,
+	itemRepo *repository.ItemRepository,
+	mapRepo *repository.MapRepository,
+	enemyTypeRepo *repository.EnemyTypeRepository,
+) *FavoriteHandler {
+	return &FavoriteHandler{
+		favoriteRepo: favoriteRepo,
+		entityExists: func(entityType, entityID string) (bool, bool) {
+			var err error
+			switch entityType {
+			case models.FavoriteEntityQuest:
+				_, err = questRepo.FindByExternalID(entityID)
+			case models.FavoriteEntityItem:
+				_, err = itemRepo.FindByExternalID(entityID)
+			case models.FavoriteEntityMap:
+				_, err = mapRepo.FindByExternalID(entityID)
+			case models.FavoriteEntityEnemyType:
+				_, err = enemyTypeRepo.FindByExternalID(entityID)
+			default:
+				return false, false
+			}
+			return err == nil, true
+		},
+	}
+}
+
+func validFavoriteEntityType(entityType string) bool {
+	switch entityType {
+	case models.FavoriteEntityQuest, models.FavoriteEntityItem, models.FavoriteEntityMap, models.FavoriteEntityEnemyType:
+		return true
+	}
+	return false
+}
+
+// List returns the current user's favorites
+// @Summary List my favorites
+// @Description Fetch the entries the authenticated user has pinned, newest first, optionally for one entity type.
+// @Tags favorites
+// @Accept json
+// @Produce json
+// @Param entity_type query string false "Entity type" Enums(quest, item, map, enemy_type)
+// @Success 200 {object} map[string][]models.Favorite "Successfully fetched favorites"
+// @Failure 400 {object} ErrorResponse "Invalid entity type"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /me/favorites [get]
+func (h *FavoriteHandler) List(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	entityType := c.Query("entity_type")
+	if entityType != "" && !validFavoriteEntityType(entityType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "entity_type must be quest, item, map or enemy_type"})
+		return
+	}
+
+	favorites, err := h.favoriteRepo.FindByUserID(user.ID, entityType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch favorites"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": favorites})
+}
+
+// Add pins an entry for the current user
+// @Summary Add a favorite
+// @Description Pin a quest, item, map or enemy type by its external ID. Adding an existing favorite returns it unchanged with 200.
+// @Tags favorites
+// @Accept json
+// @Produce json
+// @Param favorite body map[string]string true "entity_type and entity_id"
+// @Success 200 {object} models.Favorite "Already a favorite"
+// @Success 201 {object} models.Favorite "Successfully added the favorite"
+// @Failure 400 {object} ErrorResponse "Invalid input or entity type"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Entity not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /me/favorites [post]
+func (h *FavoriteHandler) Add(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	var req struct {
+		EntityType string `json:"entity_type" binding:"required"`
+		EntityID   string `json:"entity_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	exists, ok := h.entityExists(req.EntityType, req.EntityID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "entity_type must be quest, item, map or enemy_type"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Entity not found"})
+		return
+	}
+
+	favorite, created, err := h.favoriteRepo.Add(user.ID, req.EntityType, req.EntityID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add favorite"})
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, favorite)
+}
+
+// Remove unpins an entry for the current user
+// @Summary Remove a favorite
+// @Description Unpin a quest, item, map or enemy type.
+// @Tags favorites
+// @Accept json
+// @Produce json
+// @Param entity_type path string true "Entity type" Enums(quest, item, map, enemy_type)
+// @Param entity_id path string true "Entity External ID"
+// @Success 204 "Successfully removed the favorite"
+// @Failure 400 {object} ErrorResponse "Invalid entity type"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Favorite not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /me/favorites/{entity_type}/{entity_id} [delete]
+func (h *FavoriteHandler) Remove(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	entityType := c.Param("entity_type")
+	if !validFavoriteEntityType(entityType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "entity_type must be quest, item, map or enemy_type"})
+		return
+	}
+
+	removed, err := h.favoriteRepo.Remove(user.ID, entityType, c.Param("entity_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove favorite"})
+		return
+	}
+	if removed == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Favorite not found"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
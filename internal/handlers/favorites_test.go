@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+)
+
+// memoryFavorites keeps favorites like FavoriteRepository, newest first
+type memoryFavorites struct {
+	favorites []models.Favorite
+}
+
+func (m *memoryFavorites) Add(userID uint, entityType, entityID string) (*models.Favorite, bool, error) {
+	for _, f := range m.favorites {
+		if f.UserID == userID && f.EntityType == entityType && f.EntityID == entityID {
+			return &f, false, nil
+		}
+	}
+	f := models.Favorite{ID: uint(len(m.favorites) + 1), UserID: userID, EntityType: entityType, EntityID: entityID}
+	m.favorites = append([]models.Favorite{f}, m.favorites...)
+	return &f, true, nil
+}
+
+func (m *memoryFavorites) FindByUserID(userID uint, entityType string) ([]models.Favorite, error) {
+	result := []models.Favorite{}
+	for _, f := range m.favorites {
+		if f.UserID == userID && (entityType == "" || f.EntityType == entityType) {
+			result = append(result, f)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryFavorites) Remove(userID uint, entityType, entityID string) (int64, error) {
+	for i, f := range m.favorites {
+		if f.UserID == userID && f.EntityType == entityType && f.EntityID == entityID {
+			m.favorites = append(m.favorites[:i], m.favorites[i+1:]...)
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func favoritesRouter(store favoriteStore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := &FavoriteHandler{
+		favoriteRepo: store,
+		entityExists: func(entityType, entityID string) (bool, bool) {
+			if !validFavoriteEntityType(entityType) {
+				return false, false
+			}
+			return entityID != "missing", true
+		},
+	}
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user", &models.User{ID: 1})
+	})
+	r.GET("/me/favorites", h.List)
+	r.POST("/me/favorites", h.Add)
+	r.DELETE("/me/favorites/:entity_type/:entity_id", h.Remove)
+	return r
+}
+
+func serveFavorites(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func listFavorites(t *testing.T, r *gin.Engine, query string) []models.Favorite {
+	w := serveFavorites(r, http.MethodGet, "/me/favorites"+query, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("list: status = %d (%s)", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data []models.Favorite `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp.Data
+}
+
+func TestFavoritesAddListRemove(t *testing.T) {
+	r := favoritesRouter(&memoryFavorites{})
+
+	if w := serveFavorites(r, http.MethodPost, "/me/favorites", `{"entity_type":"item","entity_id":"arc_alloy"}`); w.Code != http.StatusCreated {
+		t.Fatalf("add: status = %d, want 201 (%s)", w.Code, w.Body.String())
+	}
+	if w := serveFavorites(r, http.MethodPost, "/me/favorites", `{"entity_type":"map","entity_id":"dam"}`); w.Code != http.StatusCreated {
+		t.Fatalf("add map: status = %d, want 201", w.Code)
+	}
+
+	// Adding a favorite twice returns it unchanged rather than creating a second row
+	w := serveFavorites(r, http.MethodPost, "/me/favorites", `{"entity_type":"item","entity_id":"arc_alloy"}`)
+	var again models.Favorite
+	if err := json.Unmarshal(w.Body.Bytes(), &again); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || again.ID != 1 {
+		t.Errorf("duplicate add: status = %d, favorite %+v; want 200 with the first favorite", w.Code, again)
+	}
+
+	if favorites := listFavorites(t, r, ""); len(favorites) != 2 || favorites[0].EntityID != "dam" {
+		t.Errorf("expected 2 favorites, newest first, got %+v", favorites)
+	}
+	if favorites := listFavorites(t, r, "?entity_type=item"); len(favorites) != 1 || favorites[0].EntityID != "arc_alloy" {
+		t.Errorf("expected only the item favorite, got %+v", favorites)
+	}
+
+	if w := serveFavorites(r, http.MethodDelete, "/me/favorites/item/arc_alloy", ""); w.Code != http.StatusNoContent {
+		t.Errorf("remove: status = %d, want 204", w.Code)
+	}
+	if w := serveFavorites(r, http.MethodDelete, "/me/favorites/item/arc_alloy", ""); w.Code != http.StatusNotFound {
+		t.Errorf("second remove: status = %d, want 404", w.Code)
+	}
+	if favorites := listFavorites(t, r, ""); len(favorites) != 1 || favorites[0].EntityID != "dam" {
+		t.Errorf("expected only the map favorite to remain, got %+v", favorites)
+	}
+}
+
+func TestFavoritesRejectInvalidRequests(t *testing.T) {
+	r := favoritesRouter(&memoryFavorites{})
+
+	for _, tc := range []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodPost, "/me/favorites", `{"entity_type":"item"}`, http.StatusBadRequest},
+		{http.MethodPost, "/me/favorites", `{"entity_type":"trader","entity_id":"apollo"}`, http.StatusBadRequest},
+		{http.MethodPost, "/me/favorites", `{"entity_type":"quest","entity_id":"missing"}`, http.StatusNotFound},
+		{http.MethodGet, "/me/favorites?entity_type=trader", "", http.StatusBadRequest},
+		{http.MethodDelete, "/me/favorites/trader/apollo", "", http.StatusBadRequest},
+	} {
+		if w := serveFavorites(r, tc.method, tc.path, tc.body); w.Code != tc.want {
+			t.Errorf("%s %s %s: status = %d, want %d", tc.method, tc.path, tc.body, w.Code, tc.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -9,81 +10,171 @@ import (
 	"github.com/mat/arcapi/internal/services"
 )
 
+// Health statuses, of the whole system and of each dependency
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+	HealthDown     = "down"
+	HealthDisabled = "disabled" // An optional dependency that isn't configured
+	HealthPending  = "pending"  // No sync has finished since this instance started
+)
+
+// databaseSlowThreshold is the ping latency above which the database counts as degraded
+const databaseSlowThreshold = 500 * time.Millisecond
+
+// healthCheckTimeout bounds each dependency check
+const healthCheckTimeout = 3 * time.Second
+
 type HealthHandler struct {
 	db           *repository.DB
 	cacheService *services.CacheService
+	syncService  *services.SyncService
+	syncMaxAge   time.Duration
 }
 
-func NewHealthHandler(db *repository.DB, cacheService *services.CacheService) *HealthHandler {
+func NewHealthHandler(db *repository.DB, cacheService *services.CacheService, syncService *services.SyncService, syncMaxAge time.Duration) *HealthHandler {
 	return &HealthHandler{
 		db:           db,
 		cacheService: cacheService,
+		syncService:  syncService,
+		syncMaxAge:   syncMaxAge,
 	}
 }
 
-// HealthCheck performs a comprehensive health check
 // HealthCheck performs a comprehensive health check
 // @Summary Comprehensive health check
-// @Description Check the health of database, cache, and other services
+// @Description Check each dependency: database ping latency, Redis reachability, the age of this instance's last successful sync and GitHub reachability. Each check has a status of ok, degraded, down or disabled. The overall status is down (503) when the database is unreachable, degraded (200) when anything else is failing or slow, and ok otherwise.
 // @Tags health
 // @Accept json
 // @Produce json
-// @Success 200 {object} map[string]interface{} "System is healthy"
-// @Failure 503 {object} map[string]interface{} "System is degraded"
+// @Success 200 {object} map[string]interface{} "System is healthy or degraded"
+// @Failure 503 {object} map[string]interface{} "System is down"
 // @Router /health [get]
 func (h *HealthHandler) HealthCheck(c *gin.Context) {
-	status := gin.H{
-		"status":    "ok",
-		"timestamp": time.Now().UTC().Format(time.RFC3339),
-	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+	defer cancel()
 
 	checks := gin.H{}
-	allHealthy := true
+	statuses := map[string]string{}
 
-	// Check database
+	// Database: the only dependency the API can't serve without
 	sqlDB, err := h.db.DB.DB()
 	if err != nil {
-		checks["database"] = gin.H{"status": "error", "error": err.Error()}
-		allHealthy = false
+		checks["database"] = gin.H{"status": HealthDown, "error": err.Error()}
+		statuses["database"] = HealthDown
 	} else {
-		if err := sqlDB.Ping(); err != nil {
-			checks["database"] = gin.H{"status": "error", "error": err.Error()}
-			allHealthy = false
+		started := time.Now()
+		err := sqlDB.PingContext(ctx)
+		latency := time.Since(started)
+		if err != nil {
+			checks["database"] = gin.H{"status": HealthDown, "error": err.Error()}
+			statuses["database"] = HealthDown
 		} else {
+			statuses["database"] = HealthOK
+			if latency > databaseSlowThreshold {
+				statuses["database"] = HealthDegraded
+			}
 			stats := sqlDB.Stats()
 			checks["database"] = gin.H{
-				"status":          "healthy",
+				"status":           statuses["database"],
+				"latency_ms":       latency.Milliseconds(),
 				"open_connections": stats.OpenConnections,
-				"max_open":        stats.MaxOpenConnections,
+				"max_open":         stats.MaxOpenConnections,
 			}
 		}
 	}
 
-	// Check Redis cache (if available)
+	// Redis is optional: without it responses are just uncached
 	if h.cacheService != nil {
-		ctx := h.cacheService.Context()
-		client := h.cacheService.Client()
-		if err := client.Ping(ctx).Err(); err != nil {
-			checks["cache"] = gin.H{"status": "error", "error": err.Error()}
-			// Cache is optional, so don't mark as unhealthy
+		started := time.Now()
+		if err := h.cacheService.Client().Ping(ctx).Err(); err != nil {
+			checks["cache"] = gin.H{"status": HealthDown, "error": err.Error()}
+			statuses["cache"] = HealthDown
 		} else {
-			checks["cache"] = gin.H{"status": "healthy"}
+			checks["cache"] = gin.H{"status": HealthOK, "latency_ms": time.Since(started).Milliseconds()}
+			statuses["cache"] = HealthOK
 		}
 	} else {
-		checks["cache"] = gin.H{"status": "disabled"}
+		checks["cache"] = gin.H{"status": HealthDisabled}
 	}
 
-	status["checks"] = checks
+	if h.syncService != nil {
+		sync := h.syncService.Health()
+		statuses["sync"] = syncHealthStatus(sync, time.Now(), h.syncMaxAge)
+		check := gin.H{"status": statuses["sync"], "is_running": sync.IsRunning, "data_version": sync.DataVersion}
+		if sync.LastSuccessAt != nil {
+			check["last_success_at"] = sync.LastSuccessAt
+			check["age_seconds"] = int64(time.Since(*sync.LastSuccessAt).Seconds())
+		}
+		if !sync.Healthy {
+			check["error"] = sync.LastError
+		}
+		checks["sync"] = check
+
+		if h.syncService.UsesGitHub() {
+			github := h.syncService.CheckGitHub(ctx)
+			statuses["github"] = HealthOK
+			if !github.Reachable {
+				statuses["github"] = HealthDown
+			}
+			githubCheck := gin.H{
+				"status":               statuses["github"],
+				"latency_ms":           github.LatencyMS,
+				"rate_limit_remaining": github.RateLimitRemaining,
+				"checked_at":           github.CheckedAt,
+			}
+			if github.Error != "" {
+				githubCheck["error"] = github.Error
+			}
+			checks["github"] = githubCheck
+		} else {
+			// Data is synced from elsewhere, so GitHub being unreachable doesn't matter
+			checks["github"] = gin.H{"status": HealthDisabled}
+		}
+	}
 
-	if !allHealthy {
-		status["status"] = "degraded"
+	overall := overallHealthStatus(statuses)
+	status := gin.H{
+		"status":    overall,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"checks":    checks,
+	}
+	if overall == HealthDown {
 		c.JSON(http.StatusServiceUnavailable, status)
 		return
 	}
-
 	c.JSON(http.StatusOK, status)
 }
 
+// syncHealthStatus is degraded when the last finished sync failed or the last success is
+// older than maxAge, and pending before the first sync on this instance finishes
+func syncHealthStatus(sync services.SyncHealth, now time.Time, maxAge time.Duration) string {
+	if !sync.Healthy {
+		return HealthDegraded
+	}
+	if sync.LastSuccessAt == nil {
+		return HealthPending
+	}
+	if maxAge > 0 && now.Sub(*sync.LastSuccessAt) > maxAge {
+		return HealthDegraded
+	}
+	return HealthOK
+}
+
+// overallHealthStatus is down when the database is down and degraded when any dependency
+// is degraded or down
+func overallHealthStatus(statuses map[string]string) string {
+	if statuses["database"] == HealthDown {
+		return HealthDown
+	}
+	for _, status := range statuses {
+		if status == HealthDegraded || status == HealthDown {
+			return HealthDegraded
+		}
+	}
+	return HealthOK
+}
+
 // ReadinessCheck performs a lightweight readiness check
 // ReadinessCheck performs a lightweight readiness check
 // @Summary Readiness check
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mat/arcapi/internal/services"
+)
+
+func TestSyncHealthStatus(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	recent, old := now.Add(-10*time.Minute), now.Add(-2*time.Hour)
+
+	cases := []struct {
+		name   string
+		health services.SyncHealth
+		want   string
+	}{
+		{"no sync finished yet", services.SyncHealth{Healthy: true}, HealthPending},
+		{"recent success", services.SyncHealth{Healthy: true, LastSuccessAt: &recent}, HealthOK},
+		{"stale success", services.SyncHealth{Healthy: true, LastSuccessAt: &old}, HealthDegraded},
+		{"last sync failed", services.SyncHealth{Healthy: false, LastSuccessAt: &old, LastErrorAt: &recent}, HealthDegraded},
+	}
+	for _, tc := range cases {
+		if got := syncHealthStatus(tc.health, now, time.Hour); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestOverallHealthStatus(t *testing.T) {
+	cases := []struct {
+		statuses map[string]string
+		want     string
+	}{
+		{map[string]string{"database": HealthOK, "sync": HealthPending}, HealthOK},
+		{map[string]string{"database": HealthOK, "cache": HealthDown, "github": HealthOK}, HealthDegraded},
+		{map[string]string{"database": HealthDegraded, "sync": HealthOK}, HealthDegraded},
+		{map[string]string{"database": HealthDown, "cache": HealthOK}, HealthDown},
+	}
+	for _, tc := range cases {
+		if got := overallHealthStatus(tc.statuses); got != tc.want {
+			t.Errorf("overallHealthStatus(%v) = %s, want %s", tc.statuses, got, tc.want)
+		}
+	}
+}
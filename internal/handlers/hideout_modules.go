@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/middleware"
 	"github.com/mat/arcapi/internal/models"
 	"github.com/mat/arcapi/internal/repository"
 )
@@ -26,13 +27,19 @@ func NewHideoutModuleHandler(repo *repository.HideoutModuleRepository) *HideoutM
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
 // @Param all query bool false "Return all modules" default(false)
+// @Param lang query string false "Resolve multilingual text to this language, falling back to English (e.g. de)"
 // @Success 200 {object} PaginatedResponse{data=[]models.HideoutModule} "Successfully fetched hideout modules"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /hideout-modules [get]
 func (h *HideoutModuleHandler) List(c *gin.Context) {
+	lang, ok := requestLanguage(c)
+	if !ok {
+		return
+	}
+
 	if c.Query("all") == "true" {
-		h.ListAll(c)
+		h.ListAll(c, lang)
 		return
 	}
 
@@ -45,7 +52,7 @@ func (h *HideoutModuleHandler) List(c *gin.Context) {
 		}
 	}
 	if l := c.Query("limit"); l != "" {
-		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= middleware.PageLimit(c) {
 			limit = parsed
 		}
 	}
@@ -58,7 +65,7 @@ func (h *HideoutModuleHandler) List(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"data": hideoutModules,
+		"data": localizeAll(hideoutModules, lang, localizeHideoutModule),
 		"pagination": gin.H{
 			"page":  page,
 			"limit": limit,
@@ -67,7 +74,7 @@ func (h *HideoutModuleHandler) List(c *gin.Context) {
 	})
 }
 
-func (h *HideoutModuleHandler) ListAll(c *gin.Context) {
+func (h *HideoutModuleHandler) ListAll(c *gin.Context, lang string) {
 	hideoutModules, count, err := h.repo.FindAll(0, 999999)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hideout modules"})
@@ -75,7 +82,7 @@ func (h *HideoutModuleHandler) ListAll(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"data":  hideoutModules,
+		"data":  localizeAll(hideoutModules, lang, localizeHideoutModule),
 		"total": count,
 	})
 }
@@ -87,6 +94,7 @@ func (h *HideoutModuleHandler) ListAll(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Hideout Module ID"
+// @Param lang query string false "Resolve multilingual text to this language, falling back to English (e.g. de)"
 // @Success 200 {object} models.HideoutModule "Successfully fetched the hideout module"
 // @Failure 400 {object} ErrorResponse "Invalid hideout module ID"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
@@ -94,6 +102,11 @@ func (h *HideoutModuleHandler) ListAll(c *gin.Context) {
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /hideout-modules/{id} [get]
 func (h *HideoutModuleHandler) Get(c *gin.Context) {
+	lang, ok := requestLanguage(c)
+	if !ok {
+		return
+	}
+
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -107,12 +120,17 @@ func (h *HideoutModuleHandler) Get(c *gin.Context) {
 		return
 	}
 
+	if lang != "" {
+		localized := localizeHideoutModule(*hideoutModule, lang)
+		hideoutModule = &localized
+	}
+
 	c.JSON(http.StatusOK, hideoutModule)
 }
 
 // Create adds a new hideout module
 // @Summary Create a hideout module
-// @Description Add a new hideout module to the database
+// @Description Add a new hideout module to the database. Sync won't overwrite it; see /admin/sync/conflicts.
 // @Tags hideout-modules
 // @Accept json
 // @Produce json
@@ -136,6 +154,7 @@ func (h *HideoutModuleHandler) Create(c *gin.Context) {
 		return
 	}
 
+	hideoutModule.ManualOverride = true
 	err := h.repo.Create(&hideoutModule)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create hideout module"})
@@ -147,7 +166,7 @@ func (h *HideoutModuleHandler) Create(c *gin.Context) {
 
 // Update modifies an existing hideout module
 // @Summary Update a hideout module
-// @Description Update an existing hideout module by its ID
+// @Description Update an existing hideout module by its ID. Sync stops overwriting it, listing upstream changes to it under /admin/sync/conflicts instead.
 // @Tags hideout-modules
 // @Accept json
 // @Produce json
@@ -175,6 +194,7 @@ func (h *HideoutModuleHandler) Update(c *gin.Context) {
 	}
 
 	hideoutModule.ID = uint(id)
+	hideoutModule.ManualOverride = true
 	err = h.repo.Update(&hideoutModule)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update hideout module"})
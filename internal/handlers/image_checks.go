@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/middleware"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+type ImageCheckHandler struct {
+	repo *repository.ImageCheckRepository
+}
+
+func NewImageCheckHandler(repo *repository.ImageCheckRepository) *ImageCheckHandler {
+	return &ImageCheckHandler{repo: repo}
+}
+
+// BrokenImages lists image URLs that failed the last image check
+// @Summary List broken images
+// @Description List item and enemy type image URLs that failed the last run of the image_check job. Repaired images were switched to a working alternate encoding of their filename; broken ones still need a fix upstream.
+// @Tags management
+// @Accept json
+// @Produce json
+// @Param status query string false "Filter by status (broken, repaired)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Images per page" default(50)
+// @Success 200 {object} PaginatedResponse{data=[]models.ImageCheck} "Successfully fetched broken images"
+// @Failure 400 {object} ErrorResponse "Invalid status"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/data-quality/broken-images [get]
+func (h *ImageCheckHandler) BrokenImages(c *gin.Context) {
+	page := 1
+	limit := 50
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= middleware.PageLimit(c) {
+			limit = parsed
+		}
+	}
+	status := c.Query("status")
+	if status != "" && status != models.ImageStatusBroken && status != models.ImageStatusRepaired {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status, expected broken or repaired"})
+		return
+	}
+
+	checks, err := h.repo.FindByStatus(status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch image checks"})
+		return
+	}
+
+	total := len(checks)
+	start := (page - 1) * limit
+	if start > total {
+		start = total
+	}
+	end := start + limit
+	if end > total {
+		end = total
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": checks[start:end],
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
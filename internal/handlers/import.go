@@ -0,0 +1,496 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+)
+
+// Import formats selected with ?format=, or from the uploaded file's extension
+const (
+	importFormatCSV  = "csv"
+	importFormatJSON = "json"
+)
+
+// ImportRowError is a row that failed validation. Rows are numbered from 1, not counting
+// the CSV header.
+type ImportRowError struct {
+	Row   int    `json:"row" example:"3"`
+	Error string `json:"error" example:"external_id is required"`
+}
+
+// ImportResponse reports an import's outcome
+type ImportResponse struct {
+	Entity   string           `json:"entity" example:"items"`
+	Imported int              `json:"imported" example:"42"`
+	Errors   []ImportRowError `json:"errors,omitempty"`
+}
+
+// importRecord is one uploaded row keyed by column. CSV values are strings; JSON values
+// keep their JSON types.
+type importRecord map[string]interface{}
+
+// entityImporter validates and upserts the records of one entity
+type entityImporter struct {
+	columns []string
+	upsert  func(records []importRecord) (int, []ImportRowError, error)
+}
+
+// importers lists the importable entities by their export name. Alerts have no
+// external_id to upsert on, so they can't be imported.
+func (h *ExportHandler) importers() map[string]entityImporter {
+	return map[string]entityImporter{
+		"quests":          {questExportColumns, importRows(questFromRecord, h.questRepo.UpsertAllByExternalID)},
+		"items":           {itemExportColumns, importRows(itemFromRecord, h.itemRepo.UpsertAllByExternalID)},
+		"skill-nodes":     {skillNodeExportColumns, importRows(skillNodeFromRecord, h.skillNodeRepo.UpsertAllByExternalID)},
+		"hideout-modules": {hideoutModuleExportColumns, importRows(hideoutModuleFromRecord, h.hideoutModuleRepo.UpsertAllByExternalID)},
+		"enemy-types":     {enemyTypeExportColumns, importRows(enemyTypeFromRecord, h.enemyTypeRepo.UpsertAllByExternalID)},
+		"bots":            {botExportColumns, importRows(botFromRecord, h.botRepo.UpsertAllByExternalID)},
+		"maps":            {mapExportColumns, importRows(mapFromRecord, h.mapRepo.UpsertAllByExternalID)},
+		"traders":         {traderExportColumns, importRows(traderFromRecord, h.traderRepo.UpsertAllByExternalID)},
+		"projects":        {projectExportColumns, importRows(projectFromRecord, h.projectRepo.UpsertAllByExternalID)},
+	}
+}
+
+// Import upserts an uploaded export file (admin only)
+// @Summary Import data
+// @Description Upload a CSV or JSON file in the format produced by the matching export and upsert its rows by external_id. Every row is validated first; if any fail, nothing is written and the per-row errors are returned. Otherwise all rows are written in one transaction. system_id is ignored, and name and description are stored as given. Imported quests, items, skill nodes and hideout modules count as edited through the API, so sync won't overwrite them. JSON columns accept a JSON object or, in CSV, the export's string-array encoding of one. Alerts can't be imported. Only admins can import data.
+// @Tags management
+// @Accept multipart/form-data
+// @Produce json
+// @Param entity path string true "Entity" Enums(quests, items, skill-nodes, hideout-modules, enemy-types, bots, maps, traders, projects)
+// @Param file formData file true "CSV file, or a JSON array of objects"
+// @Param format query string false "File format; defaults to the file's extension, then csv" Enums(csv, json)
+// @Success 200 {object} ImportResponse "Rows imported"
+// @Failure 400 {object} ErrorResponse "Invalid entity, format or file"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Not an administrator"
+// @Failure 422 {object} ImportResponse "Some rows failed validation; nothing was imported"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/import/{entity} [post]
+func (h *ExportHandler) Import(c *gin.Context) {
+	entity := c.Param("entity")
+	importer, ok := h.importers()[entity]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown entity, expected quests, items, skill-nodes, hideout-modules, enemy-types, bots, maps, traders or projects"})
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing file upload"})
+		return
+	}
+	format := c.Query("format")
+	if format == "" {
+		format = importFormatCSV
+		if strings.EqualFold(filepath.Ext(file.Filename), ".json") {
+			format = importFormatJSON
+		}
+	}
+	if format != importFormatCSV && format != importFormatJSON {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format, expected csv or json"})
+		return
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return
+	}
+	defer f.Close()
+
+	var records []importRecord
+	if format == importFormatJSON {
+		records, err = readJSONRecords(f, importer.columns)
+	} else {
+		records, err = readCSVRecords(f, importer.columns)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	imported, rowErrors, err := importer.upsert(records)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import data"})
+		return
+	}
+	if len(rowErrors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, ImportResponse{Entity: entity, Errors: rowErrors})
+		return
+	}
+
+	c.JSON(http.StatusOK, ImportResponse{Entity: entity, Imported: imported})
+}
+
+// readCSVRecords reads a CSV file whose header names a subset of columns
+func readCSVRecords(r io.Reader, columns []string) ([]importRecord, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	// Files saved by spreadsheet tools often start with a byte order mark
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	if err := checkImportColumns(header, columns); err != nil {
+		return nil, err
+	}
+
+	var records []importRecord
+	for {
+		values, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		record := make(importRecord, len(header))
+		for i, column := range header {
+			record[column] = values[i]
+		}
+		records = append(records, record)
+	}
+}
+
+// readJSONRecords reads a JSON array of objects keyed by a subset of columns
+func readJSONRecords(r io.Reader, columns []string) ([]importRecord, error) {
+	var records []importRecord
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	if err := decoder.Decode(&records); err != nil {
+		return nil, fmt.Errorf("invalid JSON, expected an array of objects: %w", err)
+	}
+	for _, record := range records {
+		keys := make([]string, 0, len(record))
+		for key := range record {
+			keys = append(keys, key)
+		}
+		if err := checkImportColumns(keys, columns); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// checkImportColumns rejects columns the entity doesn't export
+func checkImportColumns(got, columns []string) error {
+	known := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		known[column] = true
+	}
+	for _, column := range got {
+		if !known[column] {
+			return fmt.Errorf("unknown column %q, expected %s", column, strings.Join(columns, ", "))
+		}
+	}
+	return nil
+}
+
+// importRows validates every record with convert and, if all pass, upserts them together.
+// external_id and name are required, and each external_id may only appear once.
+func importRows[T any](convert func(importRecord) (T, error), upsert func([]T) error) func([]importRecord) (int, []ImportRowError, error) {
+	return func(records []importRecord) (int, []ImportRowError, error) {
+		var rowErrors []ImportRowError
+		rows := make([]T, 0, len(records))
+		seen := make(map[string]int, len(records))
+		for i, record := range records {
+			externalID, err := record.requiredString("external_id")
+			if err == nil {
+				_, err = record.requiredString("name")
+			}
+			if err == nil {
+				if first, ok := seen[externalID]; ok {
+					err = fmt.Errorf("duplicate external_id %q, first seen in row %d", externalID, first)
+				}
+				seen[externalID] = i + 1
+			}
+			var row T
+			if err == nil {
+				row, err = convert(record)
+			}
+			if err != nil {
+				rowErrors = append(rowErrors, ImportRowError{Row: i + 1, Error: err.Error()})
+				continue
+			}
+			rows = append(rows, row)
+		}
+		if len(rowErrors) > 0 {
+			return 0, rowErrors, nil
+		}
+		if err := upsert(rows); err != nil {
+			return 0, nil, err
+		}
+		return len(rows), nil, nil
+	}
+}
+
+func (r importRecord) string(column string) (string, error) {
+	switch v := r[column].(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	}
+	return "", fmt.Errorf("%s must be a string", column)
+}
+
+func (r importRecord) requiredString(column string) (string, error) {
+	s, err := r.string(column)
+	if err == nil && strings.TrimSpace(s) == "" {
+		err = fmt.Errorf("%s is required", column)
+	}
+	return s, err
+}
+
+func (r importRecord) int(column string) (int, error) {
+	var s string
+	switch v := r[column].(type) {
+	case nil:
+		return 0, nil
+	case string:
+		if s = strings.TrimSpace(v); s == "" {
+			return 0, nil
+		}
+	case json.Number:
+		s = v.String()
+	default:
+		return 0, fmt.Errorf("%s must be an integer", column)
+	}
+	n, err := strconv.ParseInt(s, 10, 0)
+	if err != nil || n < math.MinInt32 || n > math.MaxInt32 {
+		return 0, fmt.Errorf("%s must be an integer", column)
+	}
+	return int(n), nil
+}
+
+func (r importRecord) bool(column string) (bool, error) {
+	switch v := r[column].(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return false, nil
+		}
+		if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+			return b, nil
+		}
+	}
+	return false, fmt.Errorf("%s must be true or false", column)
+}
+
+// json reads a JSON column. JSON files hold the object itself; CSV cells hold it encoded,
+// either as a plain JSON object or wrapped in the string array the export writes.
+func (r importRecord) json(column string) (models.JSONB, error) {
+	invalid := fmt.Errorf("%s must be a JSON object", column)
+	switch v := r[column].(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		return normalizeImportJSON(v)
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil, nil
+		}
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(v), &decoded); err != nil {
+			return nil, invalid
+		}
+		if wrapped, ok := decoded.([]interface{}); ok {
+			if len(wrapped) == 0 {
+				return nil, nil
+			}
+			inner, ok := wrapped[0].(string)
+			if len(wrapped) != 1 || !ok || json.Unmarshal([]byte(inner), &decoded) != nil {
+				return nil, invalid
+			}
+		}
+		if object, ok := decoded.(map[string]interface{}); ok {
+			return object, nil
+		}
+	}
+	return nil, invalid
+}
+
+// normalizeImportJSON converts the json.Numbers of a decoded JSON file back to float64,
+// as a CSV cell would decode
+func normalizeImportJSON(object map[string]interface{}) (models.JSONB, error) {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	var normalized models.JSONB
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// importReader collects the first error reading a record's columns, so converters can
+// read every column and check once
+type importReader struct {
+	record importRecord
+	err    error
+}
+
+func (r *importReader) string(column string) string {
+	s, err := r.record.string(column)
+	r.fail(err)
+	return s
+}
+
+func (r *importReader) int(column string) int {
+	n, err := r.record.int(column)
+	r.fail(err)
+	return n
+}
+
+func (r *importReader) bool(column string) bool {
+	b, err := r.record.bool(column)
+	r.fail(err)
+	return b
+}
+
+func (r *importReader) json(column string) models.JSONB {
+	j, err := r.record.json(column)
+	r.fail(err)
+	return j
+}
+
+func (r *importReader) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+func questFromRecord(record importRecord) (models.Quest, error) {
+	r := &importReader{record: record}
+	quest := models.Quest{
+		ExternalID:     r.string("external_id"),
+		Name:           r.string("name"),
+		Description:    r.string("description"),
+		Trader:         r.string("trader"),
+		XP:             r.int("xp"),
+		Objectives:     r.json("objectives"),
+		RewardItemIds:  r.json("reward_item_ids"),
+		Data:           r.json("data"),
+		ManualOverride: true,
+		SyncedAt:       time.Now(),
+	}
+	return quest, r.err
+}
+
+func itemFromRecord(record importRecord) (models.Item, error) {
+	r := &importReader{record: record}
+	item := models.Item{
+		ExternalID:     r.string("external_id"),
+		Name:           r.string("name"),
+		Description:    r.string("description"),
+		Type:           r.string("type"),
+		ImageURL:       r.string("image_url"),
+		ImageFilename:  r.string("image_filename"),
+		Data:           r.json("data"),
+		ManualOverride: true,
+		SyncedAt:       time.Now(),
+	}
+	return item, r.err
+}
+
+func skillNodeFromRecord(record importRecord) (models.SkillNode, error) {
+	r := &importReader{record: record}
+	node := models.SkillNode{
+		ExternalID:          r.string("external_id"),
+		Name:                r.string("name"),
+		Description:         r.string("description"),
+		ImpactedSkill:       r.string("impacted_skill"),
+		Category:            r.string("category"),
+		MaxPoints:           r.int("max_points"),
+		IconName:            r.string("icon_name"),
+		IsMajor:             r.bool("is_major"),
+		Position:            r.json("position"),
+		KnownValue:          r.json("known_value"),
+		PrerequisiteNodeIds: r.json("prerequisite_node_ids"),
+		Data:                r.json("data"),
+		ManualOverride:      true,
+		SyncedAt:            time.Now(),
+	}
+	return node, r.err
+}
+
+func hideoutModuleFromRecord(record importRecord) (models.HideoutModule, error) {
+	r := &importReader{record: record}
+	module := models.HideoutModule{
+		ExternalID:     r.string("external_id"),
+		Name:           r.string("name"),
+		Description:    r.string("description"),
+		MaxLevel:       r.int("max_level"),
+		Levels:         r.json("levels"),
+		Data:           r.json("data"),
+		ManualOverride: true,
+		SyncedAt:       time.Now(),
+	}
+	return module, r.err
+}
+
+func enemyTypeFromRecord(record importRecord) (models.EnemyType, error) {
+	r := &importReader{record: record}
+	enemyType := models.EnemyType{
+		ExternalID:    r.string("external_id"),
+		Name:          r.string("name"),
+		Description:   r.string("description"),
+		Type:          r.string("type"),
+		ImageURL:      r.string("image_url"),
+		ImageFilename: r.string("image_filename"),
+		Weakpoints:    r.json("weakpoints"),
+		Data:          r.json("data"),
+		SyncedAt:      time.Now(),
+	}
+	return enemyType, r.err
+}
+
+func botFromRecord(record importRecord) (models.Bot, error) {
+	r := &importReader{record: record}
+	bot := models.Bot{ExternalID: r.string("external_id"), Name: r.string("name"), Data: r.json("data"), SyncedAt: time.Now()}
+	return bot, r.err
+}
+
+func mapFromRecord(record importRecord) (models.Map, error) {
+	r := &importReader{record: record}
+	m := models.Map{ExternalID: r.string("external_id"), Name: r.string("name"), Data: r.json("data"), SyncedAt: time.Now()}
+	return m, r.err
+}
+
+func traderFromRecord(record importRecord) (models.Trader, error) {
+	r := &importReader{record: record}
+	trader := models.Trader{ExternalID: r.string("external_id"), Name: r.string("name"), Data: r.json("data"), SyncedAt: time.Now()}
+	return trader, r.err
+}
+
+func projectFromRecord(record importRecord) (models.Project, error) {
+	r := &importReader{record: record}
+	project := models.Project{ExternalID: r.string("external_id"), Name: r.string("name"), Data: r.json("data"), SyncedAt: time.Now()}
+	return project, r.err
+}
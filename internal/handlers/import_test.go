@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestImportRoundTripsCSVExport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &ExportHandler{}
+	items := []models.Item{
+		{ID: 7, ExternalID: "rusted_gear", Name: "Rusted Gear", Type: "Material", Data: models.JSONB{"value": float64(120)}},
+		{ID: 8, ExternalID: "arc_alloy", Name: "ARC Alloy"},
+	}
+	findItems := func(batchSize int, fn func([]models.Item) error) error { return fn(items) }
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	h.sendExport(c, "items", h.itemsTable(findItems))
+
+	records, err := readCSVRecords(bytes.NewReader(w.Body.Bytes()), itemExportColumns)
+	if err != nil {
+		t.Fatalf("failed to read exported CSV: %v", err)
+	}
+	var imported []models.Item
+	n, rowErrors, err := importRows(itemFromRecord, func(rows []models.Item) error {
+		imported = rows
+		return nil
+	})(records)
+	if err != nil || len(rowErrors) > 0 || n != 2 {
+		t.Fatalf("expected 2 rows imported, got %d, %v, %v", n, rowErrors, err)
+	}
+	if got := imported[0]; got.ID != 0 || got.ExternalID != "rusted_gear" || got.Type != "Material" || got.Data["value"] != float64(120) {
+		t.Errorf("unexpected imported item %+v", got)
+	}
+	if imported[1].Data != nil {
+		t.Errorf("expected an empty data column to import as nil, got %v", imported[1].Data)
+	}
+}
+
+func TestImportReportsRowErrors(t *testing.T) {
+	records, err := readJSONRecords(strings.NewReader(`[
+		{"external_id": "a", "name": "A", "max_points": 3, "is_major": true},
+		{"external_id": "", "name": "B"},
+		{"external_id": "a", "name": "A again"},
+		{"external_id": "c", "name": "C", "max_points": "lots"},
+		{"external_id": "d", "name": "D", "position": [1, 2]}
+	]`), skillNodeExportColumns)
+	if err != nil {
+		t.Fatalf("failed to read JSON: %v", err)
+	}
+
+	upserted := false
+	_, rowErrors, err := importRows(skillNodeFromRecord, func([]models.SkillNode) error {
+		upserted = true
+		return nil
+	})(records)
+	if err != nil || upserted {
+		t.Fatalf("expected validation to stop the upsert, got %v", err)
+	}
+	want := map[int]string{2: "external_id is required", 3: "duplicate external_id", 4: "max_points", 5: "position"}
+	if len(rowErrors) != len(want) {
+		t.Fatalf("expected %d row errors, got %v", len(want), rowErrors)
+	}
+	for _, rowError := range rowErrors {
+		if !strings.Contains(rowError.Error, want[rowError.Row]) {
+			t.Errorf("row %d: expected %q in %q", rowError.Row, want[rowError.Row], rowError.Error)
+		}
+	}
+
+	if _, err := readJSONRecords(strings.NewReader(`[{"external_id": "a", "colour": "red"}]`), skillNodeExportColumns); err == nil {
+		t.Error("expected an unknown column to be rejected")
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+type InventoryHandler struct {
+	inventoryRepo *repository.UserInventoryRepository
+	itemRepo      *repository.ItemRepository
+}
+
+func NewInventoryHandler(inventoryRepo *repository.UserInventoryRepository, itemRepo *repository.ItemRepository) *InventoryHandler {
+	return &InventoryHandler{
+		inventoryRepo: inventoryRepo,
+		itemRepo:      itemRepo,
+	}
+}
+
+// List returns the current user's stash
+// @Summary Get my inventory
+// @Description Fetch how many of each item the authenticated user holds in their stash.
+// @Tags progress
+// @Accept json
+// @Produce json
+// @Param include query string false "Set to \"entity\" to embed full entity rows instead of just external IDs"
+// @Success 200 {object} map[string][]models.UserInventoryItem "Successfully fetched inventory"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /progress/inventory [get]
+func (h *InventoryHandler) List(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	inventory, err := h.inventoryRepo.FindByUserID(user.ID, includeEntity(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch inventory"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": inventory})
+}
+
+// Update sets how many of an item the current user holds
+// @Summary Update my inventory
+// @Description Record how many of an item the authenticated user currently holds. GET /progress/items/needed subtracts these quantities.
+// @Tags progress
+// @Accept json
+// @Produce json
+// @Param item_id path string true "Item External ID"
+// @Param inventory body map[string]int true "Quantity held (quantity)"
+// @Success 200 {object} models.UserInventoryItem "Successfully updated inventory"
+// @Failure 400 {object} ErrorResponse "Invalid input"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Item not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /progress/inventory/{item_id} [put]
+func (h *InventoryHandler) Update(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	item, err := h.itemRepo.FindByExternalID(c.Param("item_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		return
+	}
+
+	var req struct {
+		Quantity *int `json:"quantity" binding:"required,min=0"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := h.inventoryRepo.Upsert(user.ID, item.ID, *req.Quantity)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update inventory"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
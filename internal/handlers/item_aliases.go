@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+type ItemAliasHandler struct {
+	repo     *repository.ItemAliasRepository
+	itemRepo *repository.ItemRepository
+}
+
+func NewItemAliasHandler(repo *repository.ItemAliasRepository, itemRepo *repository.ItemRepository) *ItemAliasHandler {
+	return &ItemAliasHandler{repo: repo, itemRepo: itemRepo}
+}
+
+// List returns all item aliases
+// @Summary List item aliases
+// @Description Fetch all admin-managed item name synonyms used for objective parsing
+// @Tags management
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string][]models.ItemAlias "Successfully fetched item aliases"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Admin access required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/item-aliases [get]
+func (h *ItemAliasHandler) List(c *gin.Context) {
+	aliases, err := h.repo.ListAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch item aliases"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": aliases})
+}
+
+// Create adds a synonym for an item
+// @Summary Create an item alias
+// @Description Add a synonym (e.g. "Alloy (ARC)") that objective parsing should resolve to the given item. Matching is case- and accent-insensitive.
+// @Tags management
+// @Accept json
+// @Produce json
+// @Param alias body map[string]string true "item_external_id and alias"
+// @Success 201 {object} models.ItemAlias "Successfully created the alias"
+// @Failure 400 {object} ErrorResponse "Invalid input data"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Admin access required"
+// @Failure 404 {object} ErrorResponse "Item not found"
+// @Failure 409 {object} ErrorResponse "Alias already exists"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/item-aliases [post]
+func (h *ItemAliasHandler) Create(c *gin.Context) {
+	var req struct {
+		ItemExternalID string `json:"item_external_id" binding:"required"`
+		Alias          string `json:"alias" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	normalized := normalizeItemName(req.Alias)
+	if normalized == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "alias is required"})
+		return
+	}
+
+	if _, err := h.itemRepo.FindByExternalID(req.ItemExternalID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		return
+	}
+
+	if existing, err := h.repo.FindByNormalizedName(normalized); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Alias already exists for item " + existing.ItemExternalID})
+		return
+	}
+
+	alias := &models.ItemAlias{
+		ItemExternalID: req.ItemExternalID,
+		Alias:          strings.TrimSpace(req.Alias),
+		NormalizedName: normalized,
+	}
+	if err := h.repo.Create(alias); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create item alias"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, alias)
+}
+
+// Delete removes an item alias
+// @Summary Delete an item alias
+// @Description Remove an item synonym by ID
+// @Tags management
+// @Accept json
+// @Produce json
+// @Param id path int true "Alias ID"
+// @Success 204 "Successfully deleted the alias"
+// @Failure 400 {object} ErrorResponse "Invalid alias ID"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Admin access required"
+// @Failure 404 {object} ErrorResponse "Alias not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/item-aliases/{id} [delete]
+func (h *ItemAliasHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alias ID"})
+		return
+	}
+
+	if _, err := h.repo.FindByID(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alias not found"})
+		return
+	}
+
+	if err := h.repo.Delete(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete item alias"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
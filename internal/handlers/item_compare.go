@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+// maxCompareItems caps how many items a single comparison may include
+const maxCompareItems = 10
+
+type ItemCompareHandler struct {
+	itemRepo     *repository.ItemRepository
+	itemStatRepo *repository.ItemStatRepository
+}
+
+func NewItemCompareHandler(itemRepo *repository.ItemRepository, itemStatRepo *repository.ItemStatRepository) *ItemCompareHandler {
+	return &ItemCompareHandler{
+		itemRepo:     itemRepo,
+		itemStatRepo: itemStatRepo,
+	}
+}
+
+// CompareItem identifies one column of the comparison matrix
+type CompareItem struct {
+	ID         uint   `json:"id"`
+	ExternalID string `json:"external_id"`
+	Name       string `json:"name"`
+}
+
+// ItemStatRow is one stat across all compared items; Values[i] belongs to Items[i] and is
+// null when that item doesn't have the stat
+type ItemStatRow struct {
+	Stat   string     `json:"stat"`
+	Unit   string     `json:"unit,omitempty"`
+	Values []*float64 `json:"values"`
+}
+
+// ItemCompareResponse is an aligned stat matrix for the requested items
+type ItemCompareResponse struct {
+	Items []CompareItem `json:"items"`
+	Stats []ItemStatRow `json:"stats"`
+}
+
+// Compare returns aligned stats for several items
+// @Summary Compare items
+// @Description Returns the normalized weapon/equipment stats of the given items as a matrix: one row per stat, one value per item in request order (null where an item lacks the stat).
+// @Tags items
+// @Accept json
+// @Produce json
+// @Param ids query string true "Comma-separated item external IDs (max 10)"
+// @Success 200 {object} ItemCompareResponse "Aligned stat matrix"
+// @Failure 400 {object} ErrorResponse "Missing or too many IDs"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Unknown item IDs"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /items/compare [get]
+func (h *ItemCompareHandler) Compare(c *gin.Context) {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, id := range strings.Split(c.Query("ids"), ",") {
+		id = strings.TrimSpace(id)
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ids is required"})
+		return
+	}
+	if len(ids) > maxCompareItems {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Too many items to compare", "max": maxCompareItems})
+		return
+	}
+
+	items, err := h.itemRepo.FindByExternalIDs(ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch items"})
+		return
+	}
+	byID := make(map[string]models.Item, len(items))
+	for _, item := range items {
+		byID[item.ExternalID] = item
+	}
+	var missing []string
+	columns := make([]CompareItem, 0, len(ids))
+	for _, id := range ids {
+		item, ok := byID[id]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		columns = append(columns, CompareItem{
+			ID:         item.ID,
+			ExternalID: item.ExternalID,
+			Name:       extractMultilingualField(item.Data, "name", item.Name),
+		})
+	}
+	if len(missing) > 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Items not found", "missing": missing})
+		return
+	}
+
+	stats, err := h.itemStatRepo.FindByItemExternalIDs(ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch item stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ItemCompareResponse{
+		Items: columns,
+		Stats: alignItemStats(ids, stats),
+	})
+}
+
+// alignItemStats pivots per-item stats into rows ordered by stat name, with one value
+// slot per item in ids order
+func alignItemStats(ids []string, stats []models.ItemStat) []ItemStatRow {
+	column := make(map[string]int, len(ids))
+	for i, id := range ids {
+		column[id] = i
+	}
+
+	rows := make(map[string]*ItemStatRow)
+	for _, s := range stats {
+		col, ok := column[s.ItemExternalID]
+		if !ok {
+			continue
+		}
+		row, ok := rows[s.Stat]
+		if !ok {
+			row = &ItemStatRow{Stat: s.Stat, Unit: s.Unit, Values: make([]*float64, len(ids))}
+			rows[s.Stat] = row
+		}
+		value := s.Value
+		row.Values[col] = &value
+	}
+
+	names := make([]string, 0, len(rows))
+	for name := range rows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]ItemStatRow, 0, len(names))
+	for _, name := range names {
+		result = append(result, *rows[name])
+	}
+	return result
+}
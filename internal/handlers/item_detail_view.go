@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+// ItemDetailViewVersion is bumped whenever the layout schema changes in a way older app
+// builds can't render; clients should fall back to their built-in screen on a newer version
+const ItemDetailViewVersion = 1
+
+// Section types understood by the app renderer
+const (
+	DetailSectionText  = "text"
+	DetailSectionStats = "stats"
+	DetailSectionLinks = "links"
+)
+
+// detailViewHandledKeys are data keys rendered in dedicated places rather than the generic stats table
+var detailViewHandledKeys = map[string]bool{
+	"id": true, "name": true, "description": true, "type": true, "rarity": true,
+	"imageFilename": true, "image_url": true, "recipe": true, "craftBench": true,
+	"craftQuantity": true, "recyclesInto": true, "salvagesInto": true, "updatedAt": true,
+}
+
+type ItemDetailViewHandler struct {
+	itemRepo   *repository.ItemRepository
+	recipeRepo *repository.RecipeRepository
+}
+
+func NewItemDetailViewHandler(itemRepo *repository.ItemRepository, recipeRepo *repository.RecipeRepository) *ItemDetailViewHandler {
+	return &ItemDetailViewHandler{
+		itemRepo:   itemRepo,
+		recipeRepo: recipeRepo,
+	}
+}
+
+// ItemDetailView is a server-driven layout for the mobile item detail screen
+type ItemDetailView struct {
+	Version    int             `json:"version"`
+	ItemID     uint            `json:"item_id"`
+	ExternalID string          `json:"external_id"`
+	Title      string          `json:"title"`
+	Subtitle   string          `json:"subtitle,omitempty"`
+	ImageURL   string          `json:"image_url,omitempty"`
+	Sections   []DetailSection `json:"sections"`
+}
+
+// DetailSection is one block of the detail screen; which fields are set depends on Type
+type DetailSection struct {
+	Type  string          `json:"type"`
+	Title string          `json:"title,omitempty"`
+	Text  string          `json:"text,omitempty"`
+	Rows  []DetailStatRow `json:"rows,omitempty"`
+	Links []DetailLink    `json:"links,omitempty"`
+}
+
+// DetailStatRow is a label/value pair in a stats table
+type DetailStatRow struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// DetailLink points at another entity the app can navigate to
+type DetailLink struct {
+	EntityType string `json:"entity_type"`
+	EntityID   string `json:"entity_id"`
+	Label      string `json:"label"`
+	Quantity   int    `json:"quantity,omitempty"`
+}
+
+// GetDetailView returns the server-driven layout for an item detail screen
+// @Summary Get item detail view
+// @Description Returns a versioned layout (text, stats tables and linked entities) assembled from the item data so the mobile app can render new fields without an update.
+// @Tags mobile
+// @Accept json
+// @Produce json
+// @Param id path int true "Item ID"
+// @Success 200 {object} ItemDetailView "Detail view layout"
+// @Failure 400 {object} ErrorResponse "Invalid item ID"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Item not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /items/{id}/detail-view [get]
+func (h *ItemDetailViewHandler) GetDetailView(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+		return
+	}
+
+	item, err := h.itemRepo.FindByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		return
+	}
+
+	// A missing recipe just means the item isn't craftable
+	recipe, _ := h.recipeRepo.FindByItemExternalID(item.ExternalID)
+
+	linked, err := h.itemRepo.FindByExternalIDs(detailViewLinkedIDs(item, recipe))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch linked items"})
+		return
+	}
+	names := make(map[string]string, len(linked))
+	for _, it := range linked {
+		names[it.ExternalID] = extractMultilingualField(it.Data, "name", it.Name)
+	}
+
+	c.JSON(http.StatusOK, buildItemDetailView(item, recipe, names))
+}
+
+// detailViewLinkedIDs collects the external IDs of every item the view links to
+func detailViewLinkedIDs(item *models.Item, recipe *models.Recipe) []string {
+	var ids []string
+	if recipe != nil {
+		ids, _ = recipeIngredients(*recipe)
+	}
+	for _, key := range []string{"recyclesInto", "salvagesInto"} {
+		if m, ok := item.Data[key].(map[string]interface{}); ok {
+			for id := range m {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+func buildItemDetailView(item *models.Item, recipe *models.Recipe, names map[string]string) ItemDetailView {
+	view := ItemDetailView{
+		Version:    ItemDetailViewVersion,
+		ItemID:     item.ID,
+		ExternalID: item.ExternalID,
+		Title:      extractMultilingualField(item.Data, "name", item.Name),
+		ImageURL:   item.ImageURL,
+		Sections:   []DetailSection{},
+	}
+
+	subtitle := []string{}
+	if rarity, ok := detailViewValue(item.Data["rarity"]); ok {
+		subtitle = append(subtitle, rarity)
+	}
+	if item.Type != "" {
+		subtitle = append(subtitle, item.Type)
+	}
+	view.Subtitle = strings.Join(subtitle, " · ")
+
+	description := extractMultilingualField(item.Data, "description", item.Description)
+	if description != "" {
+		view.Sections = append(view.Sections, DetailSection{Type: DetailSectionText, Text: description})
+	}
+
+	// Top-level scalars form the main stats table; nested objects of scalars (e.g. effects)
+	// get a table of their own so new upstream fields show up without app changes
+	keys := make([]string, 0, len(item.Data))
+	for key := range item.Data {
+		if !detailViewHandledKeys[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var mainRows []DetailStatRow
+	var nested []DetailSection
+	for _, key := range keys {
+		value := item.Data[key]
+		if text, ok := detailViewValue(value); ok {
+			mainRows = append(mainRows, DetailStatRow{Key: key, Label: humanizeKey(key), Value: text})
+			continue
+		}
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		rows := detailViewRows(obj)
+		if len(rows) > 0 {
+			nested = append(nested, DetailSection{Type: DetailSectionStats, Title: humanizeKey(key), Rows: rows})
+		}
+	}
+	if len(mainRows) > 0 {
+		view.Sections = append(view.Sections, DetailSection{Type: DetailSectionStats, Title: "Stats", Rows: mainRows})
+	}
+	view.Sections = append(view.Sections, nested...)
+
+	if recipe != nil {
+		ids, quantities := recipeIngredients(*recipe)
+		section := DetailSection{Type: DetailSectionLinks, Title: "Crafting", Links: detailViewLinks(ids, quantities, names)}
+		if recipe.Bench != "" {
+			section.Text = "Crafted at " + recipe.Bench
+		}
+		view.Sections = append(view.Sections, section)
+	}
+	for _, entry := range []struct{ key, title string }{{"recyclesInto", "Recycles Into"}, {"salvagesInto", "Salvages Into"}} {
+		m, ok := item.Data[entry.key].(map[string]interface{})
+		if !ok || len(m) == 0 {
+			continue
+		}
+		ids, quantities := recipeIngredients(models.Recipe{Ingredients: models.JSONB(m)})
+		view.Sections = append(view.Sections, DetailSection{Type: DetailSectionLinks, Title: entry.title, Links: detailViewLinks(ids, quantities, names)})
+	}
+
+	return view
+}
+
+func detailViewLinks(ids []string, quantities map[string]int, names map[string]string) []DetailLink {
+	links := make([]DetailLink, 0, len(ids))
+	for _, id := range ids {
+		label := names[id]
+		if label == "" {
+			label = id
+		}
+		links = append(links, DetailLink{EntityType: "item", EntityID: id, Label: label, Quantity: quantities[id]})
+	}
+	return links
+}
+
+func detailViewRows(obj map[string]interface{}) []DetailStatRow {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	rows := make([]DetailStatRow, 0, len(keys))
+	for _, key := range keys {
+		if text, ok := detailViewValue(obj[key]); ok {
+			rows = append(rows, DetailStatRow{Key: key, Label: humanizeKey(key), Value: text})
+		}
+	}
+	return rows
+}
+
+// detailViewValue formats a scalar, localized string or list of scalars for display
+func detailViewValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, v != ""
+	case bool:
+		if v {
+			return "Yes", true
+		}
+		return "No", true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case map[string]interface{}:
+		if en, ok := v["en"].(string); ok {
+			return en, en != ""
+		}
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, element := range v {
+			text, ok := detailViewValue(element)
+			if !ok {
+				return "", false
+			}
+			parts = append(parts, text)
+		}
+		return strings.Join(parts, ", "), len(parts) > 0
+	}
+	return "", false
+}
+
+// humanizeKey turns camelCase or snake_case data keys into labels: "weightKg" -> "Weight Kg"
+func humanizeKey(key string) string {
+	var b strings.Builder
+	prevLower := false
+	for _, r := range key {
+		switch {
+		case r == '_' || r == '-':
+			b.WriteRune(' ')
+			prevLower = false
+			continue
+		case unicode.IsUpper(r) && prevLower:
+			b.WriteRune(' ')
+		}
+		if b.Len() == 0 || strings.HasSuffix(b.String(), " ") {
+			r = unicode.ToUpper(r)
+		}
+		b.WriteRune(r)
+		prevLower = unicode.IsLower(r) || unicode.IsDigit(r)
+	}
+	return b.String()
+}
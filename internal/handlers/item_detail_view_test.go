@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestBuildItemDetailView(t *testing.T) {
+	item := &models.Item{
+		ID:         7,
+		ExternalID: "ferro_rifle",
+		Name:       "Ferro",
+		Type:       "Weapon",
+		Data: models.JSONB{
+			"name":         map[string]interface{}{"en": "Ferro", "de": "Ferro DE"},
+			"rarity":       "Common",
+			"weightKg":     float64(8),
+			"stackSize":    float64(1),
+			"effects":      map[string]interface{}{"damage": float64(40), "fire_rate": "slow"},
+			"recyclesInto": map[string]interface{}{"metal_parts": float64(3)},
+		},
+	}
+	recipe := &models.Recipe{ItemExternalID: "ferro_rifle", Bench: "weapon_bench", Ingredients: models.JSONB{"metal_parts": float64(5)}}
+
+	view := buildItemDetailView(item, recipe, map[string]string{"metal_parts": "Metal Parts"})
+
+	if view.Version != ItemDetailViewVersion || view.Title != "Ferro" || view.Subtitle != "Common · Weapon" {
+		t.Fatalf("unexpected header %+v", view)
+	}
+
+	var titles []string
+	for _, s := range view.Sections {
+		titles = append(titles, s.Title)
+	}
+	want := []string{"Stats", "Effects", "Crafting", "Recycles Into"}
+	if len(titles) != len(want) {
+		t.Fatalf("sections = %v, want %v", titles, want)
+	}
+	for i := range want {
+		if titles[i] != want[i] {
+			t.Fatalf("sections = %v, want %v", titles, want)
+		}
+	}
+
+	stats := view.Sections[0].Rows
+	if len(stats) != 2 || stats[0].Label != "Stack Size" || stats[1].Label != "Weight Kg" || stats[1].Value != "8" {
+		t.Errorf("unexpected stats rows %+v", stats)
+	}
+	crafting := view.Sections[2]
+	if crafting.Text != "Crafted at weapon_bench" || crafting.Links[0].Label != "Metal Parts" || crafting.Links[0].Quantity != 5 {
+		t.Errorf("unexpected crafting section %+v", crafting)
+	}
+}
@@ -2,22 +2,34 @@ package handlers
 
 import (
 	"fmt"
+	"log"
 	"net/http"
-	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/middleware"
 	"github.com/mat/arcapi/internal/models"
 	"github.com/mat/arcapi/internal/repository"
 	"github.com/mat/arcapi/internal/services"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
 )
 
 type ItemHandler struct {
-	repo              *repository.ItemRepository
-	questRepo         *repository.QuestRepository
-	hideoutModuleRepo *repository.HideoutModuleRepository
-	dataCacheService  *services.DataCacheService
+	repo                      *repository.ItemRepository
+	questRepo                 *repository.QuestRepository
+	hideoutModuleRepo         *repository.HideoutModuleRepository
+	itemAliasRepo             *repository.ItemAliasRepository
+	questProgressRepo         *repository.UserQuestProgressRepository
+	hideoutModuleProgressRepo *repository.UserHideoutModuleProgressRepository
+	inventoryRepo             *repository.UserInventoryRepository
+	tagRepo                   *repository.TagRepository
+	dataCacheService          *services.DataCacheService
 }
 
 func NewItemHandler(repo *repository.ItemRepository) *ItemHandler {
@@ -28,11 +40,21 @@ func NewItemHandlerWithRepos(
 	repo *repository.ItemRepository,
 	questRepo *repository.QuestRepository,
 	hideoutModuleRepo *repository.HideoutModuleRepository,
+	itemAliasRepo *repository.ItemAliasRepository,
+	questProgressRepo *repository.UserQuestProgressRepository,
+	hideoutModuleProgressRepo *repository.UserHideoutModuleProgressRepository,
+	inventoryRepo *repository.UserInventoryRepository,
+	tagRepo *repository.TagRepository,
 ) *ItemHandler {
 	return &ItemHandler{
-		repo:              repo,
-		questRepo:         questRepo,
-		hideoutModuleRepo: hideoutModuleRepo,
+		repo:                      repo,
+		questRepo:                 questRepo,
+		hideoutModuleRepo:         hideoutModuleRepo,
+		itemAliasRepo:             itemAliasRepo,
+		questProgressRepo:         questProgressRepo,
+		hideoutModuleProgressRepo: hideoutModuleProgressRepo,
+		inventoryRepo:             inventoryRepo,
+		tagRepo:                   tagRepo,
 	}
 }
 
@@ -40,20 +62,46 @@ func NewItemHandlerWithCache(
 	repo *repository.ItemRepository,
 	questRepo *repository.QuestRepository,
 	hideoutModuleRepo *repository.HideoutModuleRepository,
+	itemAliasRepo *repository.ItemAliasRepository,
+	questProgressRepo *repository.UserQuestProgressRepository,
+	hideoutModuleProgressRepo *repository.UserHideoutModuleProgressRepository,
+	inventoryRepo *repository.UserInventoryRepository,
+	tagRepo *repository.TagRepository,
 	dataCacheService *services.DataCacheService,
 ) *ItemHandler {
 	return &ItemHandler{
-		repo:              repo,
-		questRepo:         questRepo,
-		hideoutModuleRepo: hideoutModuleRepo,
-		dataCacheService:  dataCacheService,
+		repo:                      repo,
+		questRepo:                 questRepo,
+		hideoutModuleRepo:         hideoutModuleRepo,
+		itemAliasRepo:             itemAliasRepo,
+		questProgressRepo:         questProgressRepo,
+		hideoutModuleProgressRepo: hideoutModuleProgressRepo,
+		inventoryRepo:             inventoryRepo,
+		tagRepo:                   tagRepo,
+		dataCacheService:          dataCacheService,
 	}
 }
 
 func (h *ItemHandler) List(c *gin.Context) {
+	filters, ok := parseListFilters(c, repository.ItemFilterColumns)
+	if !ok {
+		return
+	}
+	tagFilter, ok := parseTagFilter(c, h.tagRepo, models.TagEntityItem)
+	if !ok {
+		return
+	}
+	if tagFilter != nil {
+		filters = append(filters, *tagFilter)
+	}
+	lang, ok := requestLanguage(c)
+	if !ok {
+		return
+	}
+
 	// Check if unpaginated request
 	if c.Query("all") == "true" {
-		h.ListAll(c)
+		h.ListAll(c, filters, lang)
 		return
 	}
 
@@ -66,7 +114,7 @@ func (h *ItemHandler) List(c *gin.Context) {
 		}
 	}
 	if l := c.Query("limit"); l != "" {
-		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= middleware.PageLimit(c) {
 			limit = parsed
 		}
 	}
@@ -76,8 +124,10 @@ func (h *ItemHandler) List(c *gin.Context) {
 	var count int64
 	var err error
 
-	// Use cache service if available
-	if h.dataCacheService != nil {
+	// Filtered listings bypass the cache, which only holds the unfiltered pages
+	if len(filters) > 0 {
+		items, count, err = h.repo.FindFiltered(filters, offset, limit)
+	} else if h.dataCacheService != nil {
 		items, count, err = h.dataCacheService.GetItems(offset, limit)
 	} else {
 		// Fallback to direct database query
@@ -88,9 +138,10 @@ func (h *ItemHandler) List(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch items"})
 		return
 	}
+	h.attachTags(items)
 
 	c.JSON(http.StatusOK, gin.H{
-		"data": items,
+		"data": localizeAll(items, lang, localizeItem),
 		"pagination": gin.H{
 			"page":  page,
 			"limit": limit,
@@ -99,13 +150,15 @@ func (h *ItemHandler) List(c *gin.Context) {
 	})
 }
 
-func (h *ItemHandler) ListAll(c *gin.Context) {
+func (h *ItemHandler) ListAll(c *gin.Context, filters []repository.Filter, lang string) {
 	var items []models.Item
 	var count int64
 	var err error
 
 	// Use cache service if available - get all items
-	if h.dataCacheService != nil {
+	if len(filters) > 0 {
+		items, count, err = h.repo.FindFiltered(filters, 0, 999999)
+	} else if h.dataCacheService != nil {
 		items, count, err = h.dataCacheService.GetItems(0, 999999)
 	} else {
 		// Fallback to direct database query
@@ -116,14 +169,32 @@ func (h *ItemHandler) ListAll(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch items"})
 		return
 	}
+	h.attachTags(items)
 
 	c.JSON(http.StatusOK, gin.H{
-		"data":  items,
+		"data":  localizeAll(items, lang, localizeItem),
 		"total": count,
 	})
 }
 
+// attachTags fills in the slugs of the tags applied to each item
+func (h *ItemHandler) attachTags(items []models.Item) {
+	ids := make([]string, len(items))
+	for i := range items {
+		ids[i] = items[i].ExternalID
+	}
+	slugs := loadTagSlugs(h.tagRepo, models.TagEntityItem, ids)
+	for i := range items {
+		items[i].Tags = slugs[items[i].ExternalID]
+	}
+}
+
 func (h *ItemHandler) Get(c *gin.Context) {
+	lang, ok := requestLanguage(c)
+	if !ok {
+		return
+	}
+
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -136,6 +207,17 @@ func (h *ItemHandler) Get(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
 		return
 	}
+	item.Tags = loadTagSlugs(h.tagRepo, models.TagEntityItem, []string{item.ExternalID})[item.ExternalID]
+
+	if c.Query("normalized") == "true" {
+		c.JSON(http.StatusOK, services.NormalizeItem(*item, lang))
+		return
+	}
+
+	if lang != "" {
+		localized := localizeItem(*item, lang)
+		item = &localized
+	}
 
 	c.JSON(http.StatusOK, item)
 }
@@ -152,12 +234,15 @@ func (h *ItemHandler) Create(c *gin.Context) {
 		return
 	}
 
+	item.ManualOverride = true
 	err := h.repo.Create(&item)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create item"})
 		return
 	}
 
+	middleware.RecordChange(c, middleware.AuditEntityItem, item.ID, nil, item)
+
 	// Invalidate cache on create
 	if h.dataCacheService != nil {
 		h.dataCacheService.InvalidateItemsCache()
@@ -180,13 +265,17 @@ func (h *ItemHandler) Update(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.repo.FindByID(uint(id))
 	item.ID = uint(id)
+	item.ManualOverride = true
 	err = h.repo.Update(&item)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update item"})
 		return
 	}
 
+	middleware.RecordChange(c, middleware.AuditEntityItem, item.ID, before, item)
+
 	// Invalidate cache on update
 	if h.dataCacheService != nil {
 		h.dataCacheService.InvalidateItemsCache()
@@ -203,12 +292,15 @@ func (h *ItemHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.repo.FindByID(uint(id))
 	err = h.repo.Delete(uint(id))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete item"})
 		return
 	}
 
+	middleware.RecordChange(c, middleware.AuditEntityItem, uint(id), before, nil)
+
 	// Invalidate cache on delete
 	if h.dataCacheService != nil {
 		h.dataCacheService.InvalidateItemsCache()
@@ -231,6 +323,10 @@ type RequiredItemResponse struct {
 	Item     *models.Item        `json:"item"`
 	TotalQty int                 `json:"total_quantity"`
 	Usages   []RequiredItemUsage `json:"usages"`
+
+	// Held and Missing are only set on a user's needed items
+	Held    *int `json:"held,omitempty"`    // Quantity in the user's stash
+	Missing *int `json:"missing,omitempty"` // Total quantity minus held
 }
 
 // RequiredItems returns all items required for quests and hideout modules
@@ -240,124 +336,318 @@ func (h *ItemHandler) RequiredItems(c *gin.Context) {
 		return
 	}
 
-	// Map to store item requirements: external_id -> RequiredItemResponse
-	itemMap := make(map[string]*RequiredItemResponse)
+	result, err := h.loadRequiredItems()
+	if err != nil {
+		log.Printf("Failed to load required items: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch required items"})
+		return
+	}
 
+	c.JSON(http.StatusOK, gin.H{
+		"data":  result,
+		"total": len(result),
+	})
+}
+
+// loadRequiredItems fetches items, quests, modules and aliases and builds the global
+// requirement list.
+func (h *ItemHandler) loadRequiredItems() ([]RequiredItemResponse, error) {
 	// Get all items once for name matching (used in text objective parsing)
 	allItems, _, err := h.repo.FindAll(0, 10000)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch items"})
+		return nil, fmt.Errorf("failed to fetch items: %w", err)
+	}
+
+	// Get all quests
+	quests, _, err := h.questRepo.FindAll(0, 10000) // Get all quests
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch quests: %w", err)
+	}
+
+	// Get all hideout modules
+	hideoutModules, _, err := h.hideoutModuleRepo.FindAll(0, 10000) // Get all modules
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch hideout modules: %w", err)
+	}
+
+	// Admin-managed synonyms are optional; matching still works on item names alone
+	var aliases []models.ItemAlias
+	if h.itemAliasRepo != nil {
+		if aliases, err = h.itemAliasRepo.ListAll(); err != nil {
+			log.Printf("Warning: Failed to fetch item aliases: %v", err)
+		}
+	}
+
+	return h.buildRequiredItems(allItems, aliases, quests, hideoutModules), nil
+}
+
+// NeededItems returns the current user's remaining item requirements
+// @Summary Get my needed items
+// @Description Personal shopping list: the global required items minus requirements of quests the authenticated user completed and hideout levels they already built, with remaining quantities. Quantities recorded in the user's inventory are subtracted; items the stash already covers are dropped.
+// @Tags progress
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Remaining required items"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /progress/items/needed [get]
+func (h *ItemHandler) NeededItems(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	if h.questRepo == nil || h.hideoutModuleRepo == nil || h.questProgressRepo == nil || h.hideoutModuleProgressRepo == nil || h.inventoryRepo == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Required repositories not initialized"})
 		return
 	}
 
-	// Helper function to extract multilingual name from item
-	extractItemName := func(item models.Item) string {
-		name := item.Name
-		if name == "" && item.Data != nil {
-			dataMap := map[string]interface{}(item.Data)
-			if nameObj, ok := dataMap["name"].(map[string]interface{}); ok {
-				// Try English first
-				if enName, ok := nameObj["en"].(string); ok && enName != "" {
-					name = enName
-				} else {
-					// Try any available language
-					for _, val := range nameObj {
-						if nameStr, ok := val.(string); ok && nameStr != "" {
-							name = nameStr
-							break
-						}
-					}
-				}
-			}
+	questProgress, err := h.questProgressRepo.FindByUserID(user.ID, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quest progress"})
+		return
+	}
+	completedQuests := make(map[uint]bool, len(questProgress))
+	for _, qp := range questProgress {
+		if qp.Completed {
+			completedQuests[qp.QuestID] = true
 		}
-		return name
 	}
 
-	// Create a map for quick item lookup by name (case-insensitive)
-	// Uses multilingual names when available
-	itemNameMap := make(map[string]string) // lowercase name -> external_id
-	for _, item := range allItems {
-		itemName := extractItemName(item)
-		if itemName == "" {
-			itemName = item.ExternalID // Fallback to external_id
+	moduleProgress, err := h.hideoutModuleProgressRepo.FindByUserID(user.ID, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hideout module progress"})
+		return
+	}
+	builtLevels := make(map[uint]int, len(moduleProgress))
+	for _, mp := range moduleProgress {
+		if mp.Unlocked {
+			builtLevels[mp.HideoutModuleID] = mp.Level
 		}
-		itemNameLower := strings.ToLower(itemName)
-		itemNameMap[itemNameLower] = item.ExternalID
-		// Also add partial matches for common variations
-		// Add without spaces, with underscores, etc.
-		itemNameMap[strings.ReplaceAll(itemNameLower, " ", "")] = item.ExternalID
-		itemNameMap[strings.ReplaceAll(itemNameLower, " ", "_")] = item.ExternalID
 	}
 
-	// Get all quests
-	quests, _, err := h.questRepo.FindAll(0, 10000) // Get all quests
+	required, err := h.loadRequiredItems()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quests"})
+		log.Printf("Failed to load required items: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch required items"})
 		return
 	}
 
-	// Process quests for item requirements
-	for _, quest := range quests {
-		// Check quest data for required items
-		// Items might be in objectives, data.requirementItemIds, or data.requiredItems
-		if quest.Data != nil || quest.Objectives != nil {
-			h.extractItemsFromQuest(quest, itemMap, itemNameMap, allItems)
+	inventory, err := h.inventoryRepo.FindByUserID(user.ID, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch inventory"})
+		return
+	}
+	held := make(map[uint]int, len(inventory))
+	for _, entry := range inventory {
+		held[entry.ItemID] = entry.Quantity
+	}
+
+	result := subtractHeldItems(subtractCompletedRequirements(required, completedQuests, builtLevels), held)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  result,
+		"total": len(result),
+	})
+}
+
+// subtractCompletedRequirements drops usages from completed quests and from hideout
+// levels at or below the built level, recomputing totals and dropping items with
+// nothing left to collect.
+func subtractCompletedRequirements(required []RequiredItemResponse, completedQuests map[uint]bool, builtLevels map[uint]int) []RequiredItemResponse {
+	result := make([]RequiredItemResponse, 0, len(required))
+	for _, reqItem := range required {
+		remaining := make([]RequiredItemUsage, 0, len(reqItem.Usages))
+		total := 0
+		for _, usage := range reqItem.Usages {
+			switch usage.SourceType {
+			case "quest":
+				if completedQuests[usage.SourceID] {
+					continue
+				}
+			case "hideout_module":
+				if usage.Level != nil && *usage.Level <= builtLevels[usage.SourceID] {
+					continue
+				}
+			}
+			remaining = append(remaining, usage)
+			total += usage.Quantity
+		}
+		if len(remaining) == 0 {
+			continue
 		}
+		reqItem.Usages = remaining
+		reqItem.TotalQty = total
+		result = append(result, reqItem)
 	}
+	return result
+}
 
-	// Get all hideout modules
-	hideoutModules, _, err := h.hideoutModuleRepo.FindAll(0, 10000) // Get all modules
+// subtractHeldItems sets held and missing quantities from the user's stash, keyed by
+// item ID, and drops items the stash already covers.
+func subtractHeldItems(required []RequiredItemResponse, held map[uint]int) []RequiredItemResponse {
+	result := make([]RequiredItemResponse, 0, len(required))
+	for _, reqItem := range required {
+		quantity := 0
+		if reqItem.Item != nil {
+			quantity = held[reqItem.Item.ID]
+		}
+		missing := reqItem.TotalQty - quantity
+		if missing <= 0 {
+			continue
+		}
+		reqItem.Held = &quantity
+		reqItem.Missing = &missing
+		result = append(result, reqItem)
+	}
+	return result
+}
+
+// requiredItemsIndex holds lookups built once per RequiredItems call so that
+// objective parsing and source-name resolution never rescan the full datasets.
+type requiredItemsIndex struct {
+	items        []models.Item
+	displayNames []string                // normalized display name per entry in items
+	externalIDs  []string                // normalized external_id per entry in items
+	byExternalID map[string]*models.Item // external_id -> item
+	byName       map[string]string       // normalized name variants and aliases -> external_id
+}
+
+// itemNameNormalizer strips diacritics so "Légère" and "legere" compare equal
+var itemNameNormalizer = transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+// normalizeItemName case-folds, strips accents and collapses whitespace so item names,
+// aliases and objective text can be compared regardless of language-specific spelling
+func normalizeItemName(name string) string {
+	stripped, _, err := transform.String(itemNameNormalizer, name)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hideout modules"})
-		return
+		stripped = name
 	}
+	return strings.Join(strings.Fields(cases.Fold().String(stripped)), " ")
+}
 
-	// Process hideout modules for item requirements
-	for _, module := range hideoutModules {
-		if module.Levels != nil {
-			h.extractItemsFromHideoutModule(module, itemMap)
+func (idx *requiredItemsIndex) addName(normalized, externalID string) {
+	idx.byName[normalized] = externalID
+	// Also add partial matches for common variations
+	// Add without spaces, with underscores, etc.
+	idx.byName[strings.ReplaceAll(normalized, " ", "")] = externalID
+	idx.byName[strings.ReplaceAll(normalized, " ", "_")] = externalID
+}
+
+func (idx *requiredItemsIndex) addNameIfAbsent(normalized, externalID string) {
+	for _, key := range []string{normalized, strings.ReplaceAll(normalized, " ", ""), strings.ReplaceAll(normalized, " ", "_")} {
+		if _, exists := idx.byName[key]; !exists {
+			idx.byName[key] = externalID
 		}
 	}
+}
+
+func newRequiredItemsIndex(items []models.Item, aliases []models.ItemAlias) *requiredItemsIndex {
+	idx := &requiredItemsIndex{
+		items:        items,
+		displayNames: make([]string, len(items)),
+		externalIDs:  make([]string, len(items)),
+		byExternalID: make(map[string]*models.Item, len(items)),
+		byName:       make(map[string]string, len(items)*3),
+	}
 
-	// Helper function to extract multilingual name (prefers English, falls back to first available)
-	extractMultilingualName := func(data map[string]interface{}, defaultName string) string {
-		if data == nil {
-			return defaultName
+	for i := range items {
+		item := &items[i]
+		idx.byExternalID[item.ExternalID] = item
+
+		itemName := item.Name
+		if itemName == "" {
+			itemName = extractMultilingualField(map[string]interface{}(item.Data), "name", "")
 		}
-		if nameObj, ok := data["name"].(map[string]interface{}); ok {
-			// Try English first
-			if enName, ok := nameObj["en"].(string); ok && enName != "" {
-				return enName
-			}
-			// Try any available language
-			for _, val := range nameObj {
-				if nameStr, ok := val.(string); ok && nameStr != "" {
-					return nameStr
-				}
-			}
+		normalized := normalizeItemName(itemName)
+		idx.displayNames[i] = normalized
+		idx.externalIDs[i] = normalizeItemName(item.ExternalID)
+
+		if normalized == "" {
+			normalized = idx.externalIDs[i] // Fallback to external_id
 		}
-		return defaultName
+		idx.addName(normalized, item.ExternalID)
 	}
 
-	// Helper function to extract multilingual text from item data
-	extractMultilingualText := func(data map[string]interface{}, field string, defaultText string) string {
-		if data == nil {
-			return defaultText
+	// Localized names let non-English objectives resolve. They never replace a name
+	// already claimed above, so translations cannot shadow another item's English name.
+	for i := range items {
+		nameObj, ok := items[i].Data["name"].(map[string]interface{})
+		if !ok {
+			continue
 		}
-		if fieldObj, ok := data[field].(map[string]interface{}); ok {
-			// Try English first
-			if enText, ok := fieldObj["en"].(string); ok && enText != "" {
-				return enText
-			}
-			// Try any available language
-			for _, val := range fieldObj {
-				if textStr, ok := val.(string); ok && textStr != "" {
-					return textStr
-				}
+		for _, val := range nameObj {
+			if name, ok := val.(string); ok && name != "" {
+				idx.addNameIfAbsent(normalizeItemName(name), items[i].ExternalID)
 			}
 		}
+	}
+
+	// Aliases are added last so an explicit synonym wins over a colliding item name
+	for _, alias := range aliases {
+		normalized := alias.NormalizedName
+		if normalized == "" {
+			normalized = normalizeItemName(alias.Alias)
+		}
+		idx.addName(normalized, alias.ItemExternalID)
+	}
+
+	return idx
+}
+
+// extractMultilingualField returns data[field] when it is a plain string or a
+// localized object (preferring English, then any available language), or defaultText.
+func extractMultilingualField(data map[string]interface{}, field string, defaultText string) string {
+	if data == nil {
 		return defaultText
 	}
+	if fieldObj, ok := data[field].(map[string]interface{}); ok {
+		// Try English first
+		if enText, ok := fieldObj["en"].(string); ok && enText != "" {
+			return enText
+		}
+		// Try any available language
+		for _, val := range fieldObj {
+			if textStr, ok := val.(string); ok && textStr != "" {
+				return textStr
+			}
+		}
+	}
+	return defaultText
+}
+
+// buildRequiredItems aggregates item requirements across quests and hideout modules.
+// All lookups are map-based, so the cost grows linearly with the number of usages.
+func (h *ItemHandler) buildRequiredItems(allItems []models.Item, aliases []models.ItemAlias, quests []models.Quest, hideoutModules []models.HideoutModule) []RequiredItemResponse {
+	// Map to store item requirements: external_id -> RequiredItemResponse
+	itemMap := make(map[string]*RequiredItemResponse)
+	idx := newRequiredItemsIndex(allItems, aliases)
+
+	// Process quests for item requirements
+	questNames := make(map[uint]string, len(quests))
+	for _, quest := range quests {
+		if quest.Data != nil {
+			questNames[quest.ID] = extractMultilingualField(map[string]interface{}(quest.Data), "name", "")
+		}
+		// Check quest data for required items
+		// Items might be in objectives, data.requirementItemIds, or data.requiredItems
+		if quest.Data != nil || quest.Objectives != nil {
+			h.extractItemsFromQuest(quest, itemMap, idx)
+		}
+	}
+
+	// Process hideout modules for item requirements
+	moduleNames := make(map[uint]string, len(hideoutModules))
+	for _, module := range hideoutModules {
+		if module.Data != nil {
+			moduleNames[module.ID] = extractMultilingualField(map[string]interface{}(module.Data), "name", "")
+		}
+		if module.Levels != nil {
+			h.extractItemsFromHideoutModule(module, itemMap, idx)
+		}
+	}
 
 	// Update source names in the item map with multilingual names
 	for _, reqItem := range itemMap {
@@ -365,12 +655,12 @@ func (h *ItemHandler) RequiredItems(c *gin.Context) {
 		if reqItem.Item != nil && reqItem.Item.Data != nil {
 			dataMap := map[string]interface{}(reqItem.Item.Data)
 			if reqItem.Item.Name == "" {
-				if name := extractMultilingualText(dataMap, "name", ""); name != "" {
+				if name := extractMultilingualField(dataMap, "name", ""); name != "" {
 					reqItem.Item.Name = name
 				}
 			}
 			if reqItem.Item.Description == "" {
-				if desc := extractMultilingualText(dataMap, "description", ""); desc != "" {
+				if desc := extractMultilingualField(dataMap, "description", ""); desc != "" {
 					reqItem.Item.Description = desc
 				}
 			}
@@ -379,30 +669,15 @@ func (h *ItemHandler) RequiredItems(c *gin.Context) {
 		// Update usage source names
 		for i := range reqItem.Usages {
 			usage := &reqItem.Usages[i]
-			if usage.SourceType == "quest" {
-				// Find the quest to get its data
-				for _, quest := range quests {
-					if quest.ID == usage.SourceID {
-						if quest.Data != nil {
-							if name := extractMultilingualName(map[string]interface{}(quest.Data), usage.SourceName); name != "" {
-								usage.SourceName = name
-							}
-						}
-						break
-					}
-				}
-			} else if usage.SourceType == "hideout_module" {
-				// Find the hideout module to get its data
-				for _, module := range hideoutModules {
-					if module.ID == usage.SourceID {
-						if module.Data != nil {
-							if name := extractMultilingualName(map[string]interface{}(module.Data), usage.SourceName); name != "" {
-								usage.SourceName = name
-							}
-						}
-						break
-					}
-				}
+			var name string
+			switch usage.SourceType {
+			case "quest":
+				name = questNames[usage.SourceID]
+			case "hideout_module":
+				name = moduleNames[usage.SourceID]
+			}
+			if name != "" {
+				usage.SourceName = name
 			}
 		}
 	}
@@ -412,11 +687,7 @@ func (h *ItemHandler) RequiredItems(c *gin.Context) {
 	for _, reqItem := range itemMap {
 		result = append(result, *reqItem)
 	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"data":  result,
-		"total": len(result),
-	})
+	return result
 }
 
 // BlueprintItem represents a blueprint item with relevant information
@@ -434,11 +705,7 @@ type BlueprintItem struct {
 	UpdatedAt     string                 `json:"updated_at"`
 }
 
-// GetBlueprints returns all blueprint items
-// Blueprints are identified by:
-// 1. Type field containing "Blueprint" (case-insensitive)
-// 2. Name containing "Blueprint" (case-insensitive)
-// 3. Data field containing blueprint-related keys
+// GetBlueprints returns all blueprint items (see isBlueprintItem)
 func (h *ItemHandler) GetBlueprints(c *gin.Context) {
 	// Get all items
 	allItems, _, err := h.repo.FindAll(0, 100000) // Get all items
@@ -450,60 +717,7 @@ func (h *ItemHandler) GetBlueprints(c *gin.Context) {
 	var blueprints []BlueprintItem
 
 	for _, item := range allItems {
-		isBlueprint := false
-
-		// Check 1: Type field
-		if strings.Contains(strings.ToLower(item.Type), "blueprint") {
-			isBlueprint = true
-		}
-
-		// Check 2: Name contains "Blueprint"
-		if !isBlueprint && strings.Contains(strings.ToLower(item.Name), "blueprint") {
-			isBlueprint = true
-		}
-
-		// Check 3: Data field contains blueprint indicators
-		if !isBlueprint && item.Data != nil {
-			dataMap := map[string]interface{}(item.Data)
-
-			// Check for common blueprint-related fields
-			blueprintFields := []string{
-				"blueprint", "isBlueprint", "is_blueprint", "blueprintType",
-				"blueprint_type", "craftable", "consumable", "recipe",
-			}
-
-			for _, field := range blueprintFields {
-				if val, exists := dataMap[field]; exists {
-					// If field exists and is truthy, it's likely a blueprint
-					if boolVal, ok := val.(bool); ok && boolVal {
-						isBlueprint = true
-						break
-					} else if val != nil && val != "" {
-						isBlueprint = true
-						break
-					}
-				}
-			}
-
-			// Also check if type in data is blueprint
-			if typeVal, ok := dataMap["type"].(string); ok {
-				if strings.Contains(strings.ToLower(typeVal), "blueprint") {
-					isBlueprint = true
-				}
-			}
-		}
-
-		// Check 4: External ID pattern (some games use IDs like "bp_*" or "*_blueprint")
-		if !isBlueprint {
-			lowerID := strings.ToLower(item.ExternalID)
-			if strings.Contains(lowerID, "blueprint") ||
-				strings.HasPrefix(lowerID, "bp_") ||
-				strings.HasSuffix(lowerID, "_bp") {
-				isBlueprint = true
-			}
-		}
-
-		if isBlueprint {
+		if isBlueprintItem(item) {
 			// Extract multilingual name and description
 			displayName := item.Name
 			displayDescription := item.Description
@@ -582,7 +796,7 @@ func (h *ItemHandler) GetBlueprints(c *gin.Context) {
 }
 
 // extractItemsFromQuest extracts required items from a quest's data
-func (h *ItemHandler) extractItemsFromQuest(quest models.Quest, itemMap map[string]*RequiredItemResponse, itemNameMap map[string]string, allItems []models.Item) {
+func (h *ItemHandler) extractItemsFromQuest(quest models.Quest, itemMap map[string]*RequiredItemResponse, idx *requiredItemsIndex) {
 	// Track processed items to avoid duplicates
 	processedItems := make(map[string]bool)
 
@@ -590,12 +804,12 @@ func (h *ItemHandler) extractItemsFromQuest(quest models.Quest, itemMap map[stri
 	processReqItems := func(reqItems []interface{}, questID uint, questName string) {
 		for _, reqItem := range reqItems {
 			if itemReq, ok := reqItem.(map[string]interface{}); ok {
-				itemID, qty := h.parseItemRequirement(itemReq)
+				itemID, qty := parseItemRequirement(itemReq)
 				if itemID != "" && qty > 0 {
 					// Create unique key to avoid duplicates
 					key := fmt.Sprintf("quest:%d:%s", questID, itemID)
 					if !processedItems[key] {
-						h.addItemRequirement(itemMap, itemID, "quest", questID, questName, qty, nil)
+						h.addItemRequirement(itemMap, idx, itemID, "quest", questID, questName, qty, nil)
 						processedItems[key] = true
 					}
 				}
@@ -618,10 +832,10 @@ func (h *ItemHandler) extractItemsFromQuest(quest models.Quest, itemMap map[stri
 			for _, obj := range objectives {
 				// Check if objective is a string (text objective like "Get 3 ARC Alloy for Shani")
 				if objStr, ok := obj.(string); ok {
-					if itemID, qty := h.parseTextObjective(objStr, itemNameMap, allItems); itemID != "" && qty > 0 {
+					if itemID, qty := h.parseTextObjective(objStr, idx); itemID != "" && qty > 0 {
 						key := fmt.Sprintf("quest:%d:%s", quest.ID, itemID)
 						if !processedItems[key] {
-							h.addItemRequirement(itemMap, itemID, "quest", quest.ID, quest.Name, qty, nil)
+							h.addItemRequirement(itemMap, idx, itemID, "quest", quest.ID, quest.Name, qty, nil)
 							processedItems[key] = true
 						}
 					}
@@ -631,7 +845,7 @@ func (h *ItemHandler) extractItemsFromQuest(quest models.Quest, itemMap map[stri
 				// Check if objective is a multilingual object (has language codes as keys)
 				if objMap, ok := obj.(map[string]interface{}); ok {
 					// Check if it's a multilingual text object (has language codes like "en", "de", etc.)
-					languageCodes := []string{"en", "de", "es", "fr", "it", "ja", "kr", "no", "pl", "pt", "ru", "tr", "uk", "zh-CN", "zh-TW", "da", "hr", "sr"}
+					languageCodes := objectiveLanguageCodes
 					isMultilingual := false
 					for key := range objMap {
 						for _, lang := range languageCodes {
@@ -646,27 +860,15 @@ func (h *ItemHandler) extractItemsFromQuest(quest models.Quest, itemMap map[stri
 					}
 
 					if isMultilingual {
-						// Extract English text first, fallback to any language
-						var objectiveText string
-						if enText, ok := objMap["en"].(string); ok && enText != "" {
-							objectiveText = enText
-						} else {
-							// Try any available language
-							for _, lang := range languageCodes {
-								if text, ok := objMap[lang].(string); ok && text != "" {
-									objectiveText = text
-									break
-								}
-							}
-						}
-
-						if objectiveText != "" {
-							if itemID, qty := h.parseTextObjective(objectiveText, itemNameMap, allItems); itemID != "" && qty > 0 {
+						// Try English first, then every other language until one parses
+						for _, objectiveText := range objectiveTextsByPreference(objMap, languageCodes) {
+							if itemID, qty := h.parseTextObjective(objectiveText, idx); itemID != "" && qty > 0 {
 								key := fmt.Sprintf("quest:%d:%s", quest.ID, itemID)
 								if !processedItems[key] {
-									h.addItemRequirement(itemMap, itemID, "quest", quest.ID, quest.Name, qty, nil)
+									h.addItemRequirement(itemMap, idx, itemID, "quest", quest.ID, quest.Name, qty, nil)
 									processedItems[key] = true
 								}
+								break
 							}
 						}
 						continue
@@ -682,28 +884,28 @@ func (h *ItemHandler) extractItemsFromQuest(quest models.Quest, itemMap map[stri
 						}
 					}
 					// Also check if the objective itself is a requirement object
-					if itemID, qty := h.parseItemRequirement(objMap); itemID != "" && qty > 0 {
+					if itemID, qty := parseItemRequirement(objMap); itemID != "" && qty > 0 {
 						key := fmt.Sprintf("quest:%d:%s", quest.ID, itemID)
 						if !processedItems[key] {
-							h.addItemRequirement(itemMap, itemID, "quest", quest.ID, quest.Name, qty, nil)
+							h.addItemRequirement(itemMap, idx, itemID, "quest", quest.ID, quest.Name, qty, nil)
 							processedItems[key] = true
 						}
 					}
 					// Check if objective has a text field that might contain item requirements
 					if textField, ok := objMap["text"].(string); ok {
-						if itemID, qty := h.parseTextObjective(textField, itemNameMap, allItems); itemID != "" && qty > 0 {
+						if itemID, qty := h.parseTextObjective(textField, idx); itemID != "" && qty > 0 {
 							key := fmt.Sprintf("quest:%d:%s", quest.ID, itemID)
 							if !processedItems[key] {
-								h.addItemRequirement(itemMap, itemID, "quest", quest.ID, quest.Name, qty, nil)
+								h.addItemRequirement(itemMap, idx, itemID, "quest", quest.ID, quest.Name, qty, nil)
 								processedItems[key] = true
 							}
 						}
 					}
 					if descField, ok := objMap["description"].(string); ok {
-						if itemID, qty := h.parseTextObjective(descField, itemNameMap, allItems); itemID != "" && qty > 0 {
+						if itemID, qty := h.parseTextObjective(descField, idx); itemID != "" && qty > 0 {
 							key := fmt.Sprintf("quest:%d:%s", quest.ID, itemID)
 							if !processedItems[key] {
-								h.addItemRequirement(itemMap, itemID, "quest", quest.ID, quest.Name, qty, nil)
+								h.addItemRequirement(itemMap, idx, itemID, "quest", quest.ID, quest.Name, qty, nil)
 								processedItems[key] = true
 							}
 						}
@@ -720,10 +922,10 @@ func (h *ItemHandler) extractItemsFromQuest(quest models.Quest, itemMap map[stri
 			for _, obj := range objectivesData {
 				// Check if objective is a string (text objective like "Get 3 ARC Alloy for Shani")
 				if objStr, ok := obj.(string); ok {
-					if itemID, qty := h.parseTextObjective(objStr, itemNameMap, allItems); itemID != "" && qty > 0 {
+					if itemID, qty := h.parseTextObjective(objStr, idx); itemID != "" && qty > 0 {
 						key := fmt.Sprintf("quest:%d:%s", quest.ID, itemID)
 						if !processedItems[key] {
-							h.addItemRequirement(itemMap, itemID, "quest", quest.ID, quest.Name, qty, nil)
+							h.addItemRequirement(itemMap, idx, itemID, "quest", quest.ID, quest.Name, qty, nil)
 							processedItems[key] = true
 						}
 					}
@@ -733,7 +935,7 @@ func (h *ItemHandler) extractItemsFromQuest(quest models.Quest, itemMap map[stri
 				// Check if objective is a multilingual object (has language codes as keys)
 				if objMap, ok := obj.(map[string]interface{}); ok {
 					// Check if it's a multilingual text object (has language codes like "en", "de", etc.)
-					languageCodes := []string{"en", "de", "es", "fr", "it", "ja", "kr", "no", "pl", "pt", "ru", "tr", "uk", "zh-CN", "zh-TW", "da", "hr", "sr"}
+					languageCodes := objectiveLanguageCodes
 					isMultilingual := false
 					for key := range objMap {
 						for _, lang := range languageCodes {
@@ -748,27 +950,15 @@ func (h *ItemHandler) extractItemsFromQuest(quest models.Quest, itemMap map[stri
 					}
 
 					if isMultilingual {
-						// Extract English text first, fallback to any language
-						var objectiveText string
-						if enText, ok := objMap["en"].(string); ok && enText != "" {
-							objectiveText = enText
-						} else {
-							// Try any available language
-							for _, lang := range languageCodes {
-								if text, ok := objMap[lang].(string); ok && text != "" {
-									objectiveText = text
-									break
-								}
-							}
-						}
-
-						if objectiveText != "" {
-							if itemID, qty := h.parseTextObjective(objectiveText, itemNameMap, allItems); itemID != "" && qty > 0 {
+						// Try English first, then every other language until one parses
+						for _, objectiveText := range objectiveTextsByPreference(objMap, languageCodes) {
+							if itemID, qty := h.parseTextObjective(objectiveText, idx); itemID != "" && qty > 0 {
 								key := fmt.Sprintf("quest:%d:%s", quest.ID, itemID)
 								if !processedItems[key] {
-									h.addItemRequirement(itemMap, itemID, "quest", quest.ID, quest.Name, qty, nil)
+									h.addItemRequirement(itemMap, idx, itemID, "quest", quest.ID, quest.Name, qty, nil)
 									processedItems[key] = true
 								}
+								break
 							}
 						}
 						continue
@@ -783,28 +973,28 @@ func (h *ItemHandler) extractItemsFromQuest(quest models.Quest, itemMap map[stri
 						}
 					}
 					// Also check if the objective itself is a requirement object
-					if itemID, qty := h.parseItemRequirement(objMap); itemID != "" && qty > 0 {
+					if itemID, qty := parseItemRequirement(objMap); itemID != "" && qty > 0 {
 						key := fmt.Sprintf("quest:%d:%s", quest.ID, itemID)
 						if !processedItems[key] {
-							h.addItemRequirement(itemMap, itemID, "quest", quest.ID, quest.Name, qty, nil)
+							h.addItemRequirement(itemMap, idx, itemID, "quest", quest.ID, quest.Name, qty, nil)
 							processedItems[key] = true
 						}
 					}
 					// Check if objective has a text field that might contain item requirements
 					if textField, ok := objMap["text"].(string); ok {
-						if itemID, qty := h.parseTextObjective(textField, itemNameMap, allItems); itemID != "" && qty > 0 {
+						if itemID, qty := h.parseTextObjective(textField, idx); itemID != "" && qty > 0 {
 							key := fmt.Sprintf("quest:%d:%s", quest.ID, itemID)
 							if !processedItems[key] {
-								h.addItemRequirement(itemMap, itemID, "quest", quest.ID, quest.Name, qty, nil)
+								h.addItemRequirement(itemMap, idx, itemID, "quest", quest.ID, quest.Name, qty, nil)
 								processedItems[key] = true
 							}
 						}
 					}
 					if descField, ok := objMap["description"].(string); ok {
-						if itemID, qty := h.parseTextObjective(descField, itemNameMap, allItems); itemID != "" && qty > 0 {
+						if itemID, qty := h.parseTextObjective(descField, idx); itemID != "" && qty > 0 {
 							key := fmt.Sprintf("quest:%d:%s", quest.ID, itemID)
 							if !processedItems[key] {
-								h.addItemRequirement(itemMap, itemID, "quest", quest.ID, quest.Name, qty, nil)
+								h.addItemRequirement(itemMap, idx, itemID, "quest", quest.ID, quest.Name, qty, nil)
 								processedItems[key] = true
 							}
 						}
@@ -816,7 +1006,7 @@ func (h *ItemHandler) extractItemsFromQuest(quest models.Quest, itemMap map[stri
 }
 
 // extractItemsFromHideoutModule extracts required items from hideout module levels
-func (h *ItemHandler) extractItemsFromHideoutModule(module models.HideoutModule, itemMap map[string]*RequiredItemResponse) {
+func (h *ItemHandler) extractItemsFromHideoutModule(module models.HideoutModule, itemMap map[string]*RequiredItemResponse, idx *requiredItemsIndex) {
 	// Track processed items per level to avoid duplicates
 	processedItems := make(map[string]bool)
 
@@ -824,12 +1014,12 @@ func (h *ItemHandler) extractItemsFromHideoutModule(module models.HideoutModule,
 	processLevelReqItems := func(reqItems []interface{}, moduleID uint, moduleName string, levelNum int) {
 		for _, reqItem := range reqItems {
 			if itemReq, ok := reqItem.(map[string]interface{}); ok {
-				itemID, qty := h.parseItemRequirement(itemReq)
+				itemID, qty := parseItemRequirement(itemReq)
 				if itemID != "" && qty > 0 {
 					// Create unique key to avoid duplicates (module:level:item)
 					key := fmt.Sprintf("hideout_module:%d:level:%d:%s", moduleID, levelNum, itemID)
 					if !processedItems[key] {
-						h.addItemRequirement(itemMap, itemID, "hideout_module", moduleID, moduleName, qty, &levelNum)
+						h.addItemRequirement(itemMap, idx, itemID, "hideout_module", moduleID, moduleName, qty, &levelNum)
 						processedItems[key] = true
 					}
 				}
@@ -875,7 +1065,7 @@ func (h *ItemHandler) extractItemsFromHideoutModule(module models.HideoutModule,
 }
 
 // parseItemRequirement extracts item ID and quantity from a requirement object
-func (h *ItemHandler) parseItemRequirement(itemReq map[string]interface{}) (string, int) {
+func parseItemRequirement(itemReq map[string]interface{}) (string, int) {
 	var itemID string
 	var qty int
 
@@ -904,102 +1094,85 @@ func (h *ItemHandler) parseItemRequirement(itemReq map[string]interface{}) (stri
 }
 
 // parseTextObjective extracts item name and quantity from text objectives like "Get 3 ARC Alloy for Shani"
-func (h *ItemHandler) parseTextObjective(objectiveText string, itemNameMap map[string]string, allItems []models.Item) (string, int) {
-	// Patterns to match:
-	// "Get 3 ARC Alloy for Shani"
-	// "Collect 5 Steel"
-	// "Obtain 10 Materials"
-	// "Get X ItemName" or "Collect X ItemName" or "Obtain X ItemName"
-
-	patterns := []*regexp.Regexp{
-		// "Get X ItemName" or "Get X ItemName for Y"
-		regexp.MustCompile(`(?i)^get\s+(\d+)\s+(.+?)(?:\s+for\s+|\s*$)`),
-		// "Collect X ItemName" or "Collect X ItemName for Y"
-		regexp.MustCompile(`(?i)^collect\s+(\d+)\s+(.+?)(?:\s+for\s+|\s*$)`),
-		// "Obtain X ItemName" or "Obtain X ItemName for Y"
-		regexp.MustCompile(`(?i)^obtain\s+(\d+)\s+(.+?)(?:\s+for\s+|\s*$)`),
-		// "Gather X ItemName"
-		regexp.MustCompile(`(?i)^gather\s+(\d+)\s+(.+?)(?:\s+for\s+|\s*$)`),
-		// "Find X ItemName"
-		regexp.MustCompile(`(?i)^find\s+(\d+)\s+(.+?)(?:\s+for\s+|\s*$)`),
+func (h *ItemHandler) parseTextObjective(objectiveText string, idx *requiredItemsIndex) (string, int) {
+	match := matchTextObjective(objectiveText, idx)
+	if match.ItemID == "" {
+		return "", 0
 	}
+	return match.ItemID, match.Quantity
+}
+
+// objectiveMatch records what matchTextObjective extracted from an objective, including
+// partial results when the verb pattern matched but no item could be resolved
+type objectiveMatch struct {
+	PatternMatched bool     // A "<Verb> X ItemName" pattern matched
+	ExtractedName  string   // Normalized item name taken from the objective
+	Quantity       int      // Quantity taken from the objective
+	Attempted      []string // Name keys looked up in the index
+	ItemID         string   // Resolved item external_id, empty if unresolved
+}
 
+func matchTextObjective(objectiveText string, idx *requiredItemsIndex) objectiveMatch {
 	objectiveText = strings.TrimSpace(objectiveText)
+	var result objectiveMatch
 
-	for _, pattern := range patterns {
+	for _, pattern := range textObjectivePatterns {
 		matches := pattern.FindStringSubmatch(objectiveText)
 		if len(matches) >= 3 {
 			qty, err := strconv.Atoi(matches[1])
 			if err != nil {
 				continue
 			}
-			itemName := strings.TrimSpace(matches[2])
-			itemNameLower := strings.ToLower(itemName)
+			itemNameLower := normalizeItemName(matches[2])
+			itemNameNoSpaces := strings.ReplaceAll(itemNameLower, " ", "")
+			result = objectiveMatch{
+				PatternMatched: true,
+				ExtractedName:  itemNameLower,
+				Quantity:       qty,
+				Attempted:      []string{itemNameLower, itemNameNoSpaces},
+			}
 
 			// First try exact match in the name map
-			if itemID, found := itemNameMap[itemNameLower]; found {
-				return itemID, qty
+			if itemID, found := idx.byName[itemNameLower]; found {
+				result.ItemID = itemID
+				return result
 			}
 
 			// Try without spaces (e.g., "ARC Alloy" -> "arcalloy")
-			itemNameNoSpaces := strings.ReplaceAll(itemNameLower, " ", "")
-			if itemID, found := itemNameMap[itemNameNoSpaces]; found {
-				return itemID, qty
-			}
-
-			// Try partial match - search through all items
-			// Helper to extract multilingual item name
-			getItemDisplayName := func(item models.Item) string {
-				name := item.Name
-				if name == "" && item.Data != nil {
-					dataMap := map[string]interface{}(item.Data)
-					if nameObj, ok := dataMap["name"].(map[string]interface{}); ok {
-						// Try English first
-						if enName, ok := nameObj["en"].(string); ok && enName != "" {
-							name = enName
-						} else {
-							// Try any available language
-							for _, val := range nameObj {
-								if nameStr, ok := val.(string); ok && nameStr != "" {
-									name = nameStr
-									break
-								}
-							}
-						}
-					}
-				}
-				return name
+			if itemID, found := idx.byName[itemNameNoSpaces]; found {
+				result.ItemID = itemID
+				return result
 			}
 
-			for _, item := range allItems {
-				itemDisplayName := getItemDisplayName(item)
-				itemNameLowerDB := strings.ToLower(itemDisplayName)
-				// Exact match
-				if itemNameLowerDB == itemNameLower {
-					return item.ExternalID, qty
+			// Try partial match - item name contains extracted name or vice versa
+			for i, itemNameLowerDB := range idx.displayNames {
+				if itemNameLowerDB == "" {
+					continue
 				}
-				// Partial match - item name contains extracted name or vice versa
 				if strings.Contains(itemNameLowerDB, itemNameLower) ||
 					strings.Contains(itemNameLower, itemNameLowerDB) {
-					return item.ExternalID, qty
+					result.ItemID = idx.items[i].ExternalID
+					return result
 				}
 			}
 
 			// If no match found, try searching by external_id containing the item name
-			for _, item := range allItems {
-				if strings.Contains(strings.ToLower(item.ExternalID), itemNameLower) {
-					return item.ExternalID, qty
+			for i, externalID := range idx.externalIDs {
+				if strings.Contains(externalID, itemNameLower) {
+					result.ItemID = idx.items[i].ExternalID
+					return result
 				}
 			}
 		}
 	}
 
-	return "", 0
+	return result
 }
 
 // addItemRequirement adds or updates an item requirement in the map
 func (h *ItemHandler) addItemRequirement(
 	itemMap map[string]*RequiredItemResponse,
+	idx *requiredItemsIndex,
 	itemID string,
 	sourceType string,
 	sourceID uint,
@@ -1014,8 +1187,15 @@ func (h *ItemHandler) addItemRequirement(
 	// Get or create the item response
 	reqItem, exists := itemMap[itemID]
 	if !exists {
-		// Look up the item by external_id
-		item, err := h.repo.FindByExternalID(itemID)
+		// Look up the item by external_id, only hitting the database for items outside the index
+		item, found := idx.byExternalID[itemID]
+		var err error
+		if !found {
+			err = gorm.ErrRecordNotFound
+			if h.repo != nil {
+				item, err = h.repo.FindByExternalID(itemID)
+			}
+		}
 		if err != nil {
 			// Item not found, create a placeholder with just the ID
 			item = &models.Item{
@@ -1061,3 +1241,66 @@ func (h *ItemHandler) addItemRequirement(
 		reqItem.TotalQty += quantity
 	}
 }
+
+// isBlueprintItem reports whether an item is a blueprint.
+// Blueprints are identified by:
+// 1. Type field containing "Blueprint" (case-insensitive)
+// 2. Name containing "Blueprint" (case-insensitive)
+// 3. Data field containing blueprint-related keys
+// 4. External ID pattern
+func isBlueprintItem(item models.Item) bool {
+	isBlueprint := false
+
+	// Check 1: Type field
+	if strings.Contains(strings.ToLower(item.Type), "blueprint") {
+		isBlueprint = true
+	}
+
+	// Check 2: Name contains "Blueprint"
+	if !isBlueprint && strings.Contains(strings.ToLower(item.Name), "blueprint") {
+		isBlueprint = true
+	}
+
+	// Check 3: Data field contains blueprint indicators
+	if !isBlueprint && item.Data != nil {
+		dataMap := map[string]interface{}(item.Data)
+
+		// Check for common blueprint-related fields
+		blueprintFields := []string{
+			"blueprint", "isBlueprint", "is_blueprint", "blueprintType",
+			"blueprint_type", "craftable", "consumable", "recipe",
+		}
+
+		for _, field := range blueprintFields {
+			if val, exists := dataMap[field]; exists {
+				// If field exists and is truthy, it's likely a blueprint
+				if boolVal, ok := val.(bool); ok && boolVal {
+					isBlueprint = true
+					break
+				} else if val != nil && val != "" {
+					isBlueprint = true
+					break
+				}
+			}
+		}
+
+		// Also check if type in data is blueprint
+		if typeVal, ok := dataMap["type"].(string); ok {
+			if strings.Contains(strings.ToLower(typeVal), "blueprint") {
+				isBlueprint = true
+			}
+		}
+	}
+
+	// Check 4: External ID pattern (some games use IDs like "bp_*" or "*_blueprint")
+	if !isBlueprint {
+		lowerID := strings.ToLower(item.ExternalID)
+		if strings.Contains(lowerID, "blueprint") ||
+			strings.HasPrefix(lowerID, "bp_") ||
+			strings.HasSuffix(lowerID, "_bp") {
+			isBlueprint = true
+		}
+	}
+
+	return isBlueprint
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+// requiredItemsLatencyBudget is the maximum time buildRequiredItems may take on the
+// synthetic 10k-item dataset below.
+const requiredItemsLatencyBudget = time.Second
+
+func requiredItemsDataset(itemCount, questCount, moduleCount int) ([]models.Item, []models.Quest, []models.HideoutModule) {
+	items := make([]models.Item, itemCount)
+	for i := range items {
+		items[i] = models.Item{
+			ID:         uint(i + 1),
+			ExternalID: fmt.Sprintf("item_%d", i),
+			Data: models.JSONB{
+				"name": map[string]interface{}{"en": fmt.Sprintf("Material %d", i), "de": fmt.Sprintf("Material %d DE", i)},
+			},
+		}
+	}
+
+	quests := make([]models.Quest, questCount)
+	for i := range quests {
+		quests[i] = models.Quest{
+			ID:         uint(i + 1),
+			ExternalID: fmt.Sprintf("quest_%d", i),
+			Name:       fmt.Sprintf("quest_%d", i),
+			Data: models.JSONB{
+				"name": map[string]interface{}{"en": fmt.Sprintf("Quest %d", i)},
+				"requirementItemIds": []interface{}{
+					map[string]interface{}{"itemId": fmt.Sprintf("item_%d", i%itemCount), "quantity": float64(2)},
+					map[string]interface{}{"itemId": fmt.Sprintf("item_%d", (i*7)%itemCount), "quantity": float64(1)},
+				},
+				"objectives": []interface{}{
+					fmt.Sprintf("Get 3 Material %d for Shani", (i*13)%itemCount),
+				},
+			},
+		}
+	}
+
+	modules := make([]models.HideoutModule, moduleCount)
+	for i := range modules {
+		levels := make([]interface{}, 3)
+		for l := range levels {
+			levels[l] = map[string]interface{}{
+				"requirementItemIds": []interface{}{
+					map[string]interface{}{"itemId": fmt.Sprintf("item_%d", (i*31+l)%itemCount), "quantity": float64(5)},
+				},
+			}
+		}
+		modules[i] = models.HideoutModule{
+			ID:         uint(i + 1),
+			ExternalID: fmt.Sprintf("module_%d", i),
+			Name:       fmt.Sprintf("module_%d", i),
+			Levels:     models.JSONB{"levels": levels},
+			Data:       models.JSONB{"name": map[string]interface{}{"en": fmt.Sprintf("Module %d", i)}},
+		}
+	}
+
+	return items, quests, modules
+}
+
+func TestBuildRequiredItemsResolvesSourceNames(t *testing.T) {
+	items, quests, modules := requiredItemsDataset(10, 2, 1)
+	h := &ItemHandler{}
+
+	result := h.buildRequiredItems(items, nil, quests, modules)
+	if len(result) == 0 {
+		t.Fatal("expected required items")
+	}
+
+	for _, reqItem := range result {
+		if reqItem.Item == nil || reqItem.Item.Name == "" {
+			t.Fatalf("expected item name to be resolved, got %+v", reqItem.Item)
+		}
+		for _, usage := range reqItem.Usages {
+			if usage.SourceType == "quest" && usage.SourceName != fmt.Sprintf("Quest %d", usage.SourceID-1) {
+				t.Errorf("unexpected quest source name %q for quest %d", usage.SourceName, usage.SourceID)
+			}
+			if usage.SourceType == "hideout_module" && usage.SourceName != fmt.Sprintf("Module %d", usage.SourceID-1) {
+				t.Errorf("unexpected module source name %q for module %d", usage.SourceName, usage.SourceID)
+			}
+		}
+	}
+}
+
+func TestParseTextObjectiveMatchesAccentsCaseAndAliases(t *testing.T) {
+	items := []models.Item{
+		{ID: 1, ExternalID: "arc_alloy", Name: "ARC Alloy"},
+		{ID: 2, ExternalID: "legere_fabric", Data: models.JSONB{"name": map[string]interface{}{"fr": "Tissu Légère"}}},
+		{ID: 3, ExternalID: "Rusted_GEAR_set", Name: "Old Cogs"},
+	}
+	aliases := []models.ItemAlias{{ItemExternalID: "arc_alloy", Alias: "Alloy (ARC)"}}
+	idx := newRequiredItemsIndex(items, aliases)
+	h := &ItemHandler{}
+
+	cases := map[string]string{
+		"Get 3 arc alloy for Shani": "arc_alloy",
+		"Get 2 ALLOY (arc)":         "arc_alloy",
+		"Collect 4 tissu legere":    "legere_fabric",
+		"Get 1 gear":                "Rusted_GEAR_set", // falls back to the external_id
+	}
+	for objective, want := range cases {
+		if got, _ := h.parseTextObjective(objective, idx); got != want {
+			t.Errorf("parseTextObjective(%q) = %q, want %q", objective, got, want)
+		}
+	}
+}
+
+func TestBuildRequiredItemsParsesNonEnglishObjectives(t *testing.T) {
+	items := []models.Item{
+		{ID: 1, ExternalID: "arc_alloy", Name: "ARC Alloy", Data: models.JSONB{
+			"name": map[string]interface{}{"en": "ARC Alloy", "de": "ARC-Legierung", "fr": "Alliage ARC"},
+		}},
+	}
+	quests := []models.Quest{
+		{ID: 1, Name: "de_only", Data: models.JSONB{"objectives": []interface{}{
+			map[string]interface{}{"de": "Sammle 3 ARC-Legierung für Shani"},
+		}}},
+		{ID: 2, Name: "fr_only", Data: models.JSONB{"objectives": []interface{}{
+			map[string]interface{}{"fr": "Récupérez 2 alliage arc pour Shani"},
+		}}},
+	}
+	h := &ItemHandler{}
+
+	result := h.buildRequiredItems(items, nil, quests, nil)
+	if len(result) != 1 || result[0].Item.ExternalID != "arc_alloy" {
+		t.Fatalf("expected arc_alloy to be required, got %+v", result)
+	}
+	if result[0].TotalQty != 5 || len(result[0].Usages) != 2 {
+		t.Fatalf("expected 2 usages totalling 5, got %d usages totalling %d", len(result[0].Usages), result[0].TotalQty)
+	}
+}
+
+func TestFindUnparsedObjectives(t *testing.T) {
+	items := []models.Item{{ID: 1, ExternalID: "arc_alloy", Name: "ARC Alloy"}}
+	quests := []models.Quest{{ID: 1, ExternalID: "q1", Name: "q1", Data: models.JSONB{"objectives": []interface{}{
+		"Get 3 ARC Alloy for Shani",
+		"Get 2 Rusted Gear for Shani",
+		"Defeat 5 Ticks",
+	}}}}
+
+	unparsed := findUnparsedObjectives(quests, newRequiredItemsIndex(items, nil))
+	if len(unparsed) != 2 {
+		t.Fatalf("expected 2 unparsed objectives, got %+v", unparsed)
+	}
+	if unparsed[0].Reason != unparsedReasonUnknownItem || unparsed[0].ExtractedName != "rusted gear" || unparsed[0].Quantity != 2 {
+		t.Errorf("unexpected unknown-item entry: %+v", unparsed[0])
+	}
+	if unparsed[1].Reason != unparsedReasonNoPattern {
+		t.Errorf("unexpected no-pattern entry: %+v", unparsed[1])
+	}
+}
+
+func TestFindUnparsedObjectivesSkipsStructuredObjectives(t *testing.T) {
+	items := []models.Item{{ID: 1, ExternalID: "arc_alloy", Name: "ARC Alloy"}}
+	quests := []models.Quest{{ID: 1, ExternalID: "q1", Name: "q1", Data: models.JSONB{"objectives": []interface{}{
+		map[string]interface{}{"itemId": "arc_alloy", "quantity": float64(3), "text": "Hand over the alloy"},
+		map[string]interface{}{"requiredItems": []interface{}{
+			map[string]interface{}{"item_id": "arc_alloy", "qty": float64(2)},
+		}, "description": "Bring supplies to Shani"},
+		map[string]interface{}{"text": "Defeat 5 Ticks"},
+	}}}}
+
+	unparsed := findUnparsedObjectives(quests, newRequiredItemsIndex(items, nil))
+	if len(unparsed) != 1 || unparsed[0].Text != "Defeat 5 Ticks" {
+		t.Fatalf("expected only the text objective to be reported, got %+v", unparsed)
+	}
+}
+
+func TestBuildRequiredItemsLatencyBudget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping latency budget test in short mode")
+	}
+
+	items, quests, modules := requiredItemsDataset(10000, 1000, 100)
+	h := &ItemHandler{}
+
+	start := time.Now()
+	result := h.buildRequiredItems(items, nil, quests, modules)
+	elapsed := time.Since(start)
+
+	if len(result) == 0 {
+		t.Fatal("expected required items")
+	}
+	if elapsed > requiredItemsLatencyBudget {
+		t.Fatalf("buildRequiredItems took %s on 10k items, budget is %s", elapsed, requiredItemsLatencyBudget)
+	}
+}
+
+func BenchmarkBuildRequiredItems10k(b *testing.B) {
+	items, quests, modules := requiredItemsDataset(10000, 1000, 100)
+	h := &ItemHandler{}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.buildRequiredItems(items, nil, quests, modules)
+	}
+}
+
+func TestSubtractHeldItems(t *testing.T) {
+	required := []RequiredItemResponse{
+		{Item: &models.Item{ID: 1}, TotalQty: 5},
+		{Item: &models.Item{ID: 2}, TotalQty: 3},
+		{Item: &models.Item{ID: 3}, TotalQty: 2},
+	}
+
+	result := subtractHeldItems(required, map[uint]int{1: 2, 2: 4})
+	if len(result) != 2 {
+		t.Fatalf("expected 2 items left, got %d", len(result))
+	}
+	if result[0].Item.ID != 1 || *result[0].Held != 2 || *result[0].Missing != 3 {
+		t.Errorf("item 1: expected held 2, missing 3, got held %d, missing %d", *result[0].Held, *result[0].Missing)
+	}
+	if result[1].Item.ID != 3 || *result[1].Held != 0 || *result[1].Missing != 2 {
+		t.Errorf("item 3: expected held 0, missing 2, got held %d, missing %d", *result[1].Held, *result[1].Missing)
+	}
+}
+
+func TestSubtractCompletedRequirements(t *testing.T) {
+	level := func(n int) *int { return &n }
+	required := []RequiredItemResponse{
+		{Item: &models.Item{ID: 1, ExternalID: "arc_alloy"}, TotalQty: 10, Usages: []RequiredItemUsage{
+			{SourceType: "quest", SourceID: 1, Quantity: 3},
+			{SourceType: "quest", SourceID: 2, Quantity: 2},
+			{SourceType: "hideout_module", SourceID: 5, Quantity: 4, Level: level(2)},
+			{SourceType: "hideout_module", SourceID: 5, Quantity: 1, Level: level(3)},
+		}},
+		{Item: &models.Item{ID: 2, ExternalID: "rusted_gear"}, TotalQty: 6, Usages: []RequiredItemUsage{
+			{SourceType: "quest", SourceID: 1, Quantity: 6},
+		}},
+	}
+
+	result := subtractCompletedRequirements(required, map[uint]bool{1: true}, map[uint]int{5: 2})
+	if len(result) != 1 || result[0].Item.ExternalID != "arc_alloy" {
+		t.Fatalf("expected only arc_alloy to remain, got %+v", result)
+	}
+	if result[0].TotalQty != 3 || len(result[0].Usages) != 2 {
+		t.Errorf("expected 2 remaining usages totalling 3, got %d usages totalling %d", len(result[0].Usages), result[0].TotalQty)
+	}
+	if len(required[0].Usages) != 4 {
+		t.Error("the global requirement list was modified")
+	}
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/middleware"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/services"
+)
+
+type LeaderboardHandler struct {
+	leaderboardService *services.LeaderboardService
+}
+
+func NewLeaderboardHandler(leaderboardService *services.LeaderboardService) *LeaderboardHandler {
+	return &LeaderboardHandler{leaderboardService: leaderboardService}
+}
+
+// List returns a page of a leaderboard
+// @Summary Get a leaderboard
+// @Description Fetch an opt-in leaderboard ranked by overall score, quests completed or hideout levels. Refreshed periodically by a background job, see refreshed_at.
+// @Tags leaderboards
+// @Accept json
+// @Produce json
+// @Param type path string true "Leaderboard type (overall, quests, hideout)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} PaginatedResponse{data=[]models.LeaderboardEntry} "Successfully fetched the leaderboard"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Unknown leaderboard type"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /leaderboards/{type} [get]
+func (h *LeaderboardHandler) List(c *gin.Context) {
+	boardType := c.Param("type")
+	if !services.IsValidLeaderboardType(boardType) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown leaderboard type"})
+		return
+	}
+
+	page := 1
+	limit := 20
+
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= middleware.PageLimit(c) {
+			limit = parsed
+		}
+	}
+
+	result, err := h.leaderboardService.Page(boardType, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch leaderboard"})
+		return
+	}
+
+	response := gin.H{
+		"data": result.Entries,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": result.Total,
+		},
+	}
+
+	// Include the caller's own row when they are on the board
+	val, _ := c.Get("user")
+	if user, ok := val.(*models.User); ok && user.LeaderboardOptIn {
+		if entry, err := h.leaderboardService.Entry(user.ID); err == nil {
+			response["me"] = entry
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateMyPrivacy changes the current user's leaderboard privacy settings
+// @Summary Update my privacy settings
+// @Description Opt in to or out of the leaderboards and optionally set the name shown there. Opting out takes effect immediately; opting in on the next leaderboard refresh. telemetry_opt_in allows client apps to report usage analytics to /telemetry/events. last_seen_public shows when you were last active to your teammates and on your public profile.
+// @Tags leaderboards
+// @Accept json
+// @Produce json
+// @Param privacy body map[string]interface{} true "leaderboard_opt_in (bool), leaderboard_name (string), telemetry_opt_in (bool) and/or last_seen_public (bool)"
+// @Success 200 {object} models.User "Successfully updated privacy settings"
+// @Failure 400 {object} ErrorResponse "Invalid input data"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /me/privacy [put]
+func (h *LeaderboardHandler) UpdateMyPrivacy(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	var req struct {
+		LeaderboardOptIn *bool   `json:"leaderboard_opt_in"`
+		LeaderboardName  *string `json:"leaderboard_name"`
+		TelemetryOptIn   *bool   `json:"telemetry_opt_in"`
+		LastSeenPublic   *bool   `json:"last_seen_public"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.leaderboardService.UpdatePrivacy(user, req.LeaderboardOptIn, req.LeaderboardName, req.TelemetryOptIn, req.LastSeenPublic); err != nil {
+		if errors.Is(err, services.ErrInvalidLeaderboardName) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update privacy settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/config"
+	"github.com/mat/arcapi/internal/middleware"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/services"
+)
+
+// RateLimit is a number of requests allowed per window; a limit of 0 means unlimited
+type RateLimit struct {
+	Limit         int `json:"limit" example:"120"`
+	WindowSeconds int `json:"window_seconds" example:"60"`
+}
+
+// RouteRateLimit is a rate limit with its own bucket for requests under a path prefix
+type RouteRateLimit struct {
+	PathPrefix string `json:"path_prefix" example:"/api/v1/auth/device/token"`
+	RateLimit
+}
+
+// Quota caps how much of something a caller can have, or do per window when WindowSeconds
+// is set
+type Quota struct {
+	Limit         int `json:"limit" example:"50"`
+	WindowSeconds int `json:"window_seconds,omitempty"`
+}
+
+// LimitsResponse is the caller's effective limits
+type LimitsResponse struct {
+	Tier        string           `json:"tier" example:"api_key"` // anonymous, user, api_key or admin
+	RateLimit   RateLimit        `json:"rate_limit"`
+	RouteLimits []RouteRateLimit `json:"route_limits"`
+	Quotas      map[string]Quota `json:"quotas"`
+	MaxPageSize int              `json:"max_page_size" example:"100"`
+	Scopes      []string         `json:"scopes"`
+	Permissions []string         `json:"permissions"`
+}
+
+type LimitsHandler struct {
+	cfg         *config.Config
+	rateLimits  *middleware.RateLimitRegistry
+	rbacService *services.RBACService
+}
+
+func NewLimitsHandler(cfg *config.Config, rateLimits *middleware.RateLimitRegistry, rbacService *services.RBACService) *LimitsHandler {
+	return &LimitsHandler{cfg: cfg, rateLimits: rateLimits, rbacService: rbacService}
+}
+
+// Get returns the caller's effective limits
+// @Summary Get my limits
+// @Description The rate limit, quotas, maximum page size, API key scopes and permissions that apply to the caller, so SDKs can pace themselves without hard-coding deployment-specific numbers. rate_limit is shared by every request except those under one of route_limits, which are counted in their own bucket. The X-RateLimit-* headers on every response report what is left of the current window.
+// @Tags meta
+// @Produce json
+// @Success 200 {object} LimitsResponse "Effective limits"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /meta/limits [get]
+func (h *LimitsHandler) Get(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	tier := middleware.RequestTier(c, user)
+	response := LimitsResponse{
+		Tier: string(tier),
+		RateLimit: RateLimit{
+			Limit:         middleware.TierLimit(h.cfg.GetRateLimitTiers(), tier),
+			WindowSeconds: h.cfg.RateLimitWindowSeconds,
+		},
+		RouteLimits: []RouteRateLimit{},
+		Quotas: map[string]Quota{
+			"saved_searches": {Limit: maxSavedSearches},
+			"team_members":   {Limit: maxTeamMembers},
+		},
+		MaxPageSize: middleware.PageLimit(c),
+		Scopes:      []string{},
+		Permissions: h.rbacService.Permissions(user),
+	}
+	for _, rule := range h.rateLimits.Rules() {
+		response.RouteLimits = append(response.RouteLimits, RouteRateLimit{
+			PathPrefix: rule.PathPrefix,
+			RateLimit:  RateLimit{Limit: rule.Limit, WindowSeconds: int(rule.Window / time.Second)},
+		})
+	}
+	if h.cfg.TelemetryEnabled {
+		// Counted on top of rate_limit
+		response.Quotas["telemetry_batches"] = Quota{Limit: h.cfg.TelemetryRateLimit, WindowSeconds: h.cfg.TelemetryRateLimitWindowSeconds}
+	}
+	if val, ok := c.Get(middleware.APIKeyContextKey); ok {
+		if apiKey, ok := val.(*models.APIKey); ok && len(apiKey.Scopes) > 0 {
+			response.Scopes = apiKey.Scopes
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
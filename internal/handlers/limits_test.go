@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/config"
+	"github.com/mat/arcapi/internal/middleware"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/services"
+)
+
+func TestLimitsForAdminAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{
+		RateLimitRequests:       20,
+		RateLimitUserRequests:   60,
+		RateLimitAPIKeyRequests: 120,
+		RateLimitAdminRequests:  300,
+		RateLimitWindowSeconds:  60,
+		BulkReadMaxPageSize:     1000,
+	}
+	rateLimits := middleware.NewRateLimitRegistry(nil).Declare("/api/v1/auth/device/token", 30, time.Minute)
+	handler := NewLimitsHandler(cfg, rateLimits, services.NewRBACService(nil))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/meta/limits", nil)
+	c.Set("user", &models.User{ID: 4, Role: models.RoleAdmin})
+	c.Set(middleware.APIKeyContextKey, &models.APIKey{ID: 9, Scopes: models.StringList{models.ScopeBulkRead}})
+	c.Set(middleware.MaxPageSizeContextKey, cfg.BulkReadMaxPageSize)
+	handler.Get(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var got LimitsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if got.Tier != "admin" || got.RateLimit != (RateLimit{Limit: 300, WindowSeconds: 60}) {
+		t.Errorf("expected the admin tier limit, got %s %+v", got.Tier, got.RateLimit)
+	}
+	if len(got.Permissions) != len(models.AllPermissions) {
+		t.Errorf("expected every permission, got %v", got.Permissions)
+	}
+	if got.MaxPageSize != 1000 || len(got.Scopes) != 1 || got.Scopes[0] != models.ScopeBulkRead {
+		t.Errorf("expected the bulk read page size and scope, got %d %v", got.MaxPageSize, got.Scopes)
+	}
+	if len(got.RouteLimits) != 1 || got.RouteLimits[0].Limit != 30 || got.RouteLimits[0].WindowSeconds != 60 {
+		t.Errorf("expected the declared route limit, got %+v", got.RouteLimits)
+	}
+	if got.Quotas["saved_searches"].Limit != maxSavedSearches {
+		t.Errorf("expected the saved search quota, got %+v", got.Quotas)
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+)
+
+// requestLanguage reads ?lang (e.g. "de", "zh-CN"), matching the known language codes
+// case-insensitively. An empty language means responses keep the full multilingual
+// objects. It writes a 400 and returns false for unknown languages.
+func requestLanguage(c *gin.Context) (string, bool) {
+	lang := strings.TrimSpace(c.Query("lang"))
+	if lang == "" {
+		return "", true
+	}
+	for _, code := range objectiveLanguageCodes {
+		if strings.EqualFold(code, lang) {
+			return code, true
+		}
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported lang, expected one of: " + strings.Join(objectiveLanguageCodes, ", ")})
+	return "", false
+}
+
+// localizedText picks lang from a multilingual string, falling back to English and then
+// to the first other language that has text
+func localizedText(m map[string]interface{}, lang string) string {
+	if text, ok := m[lang].(string); ok && text != "" {
+		return text
+	}
+	if text, ok := m["en"].(string); ok && text != "" {
+		return text
+	}
+	for _, code := range objectiveLanguageCodes {
+		if text, ok := m[code].(string); ok && text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// localizeValue returns a copy of v with every multilingual string resolved to lang.
+// The input is never modified, so cached rows can be localized safely.
+func localizeValue(v interface{}, lang string) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if models.IsTranslationMap(val) {
+			return localizedText(val, lang)
+		}
+		out := make(map[string]interface{}, len(val))
+		for key, element := range val {
+			out[key] = localizeValue(element, lang)
+		}
+		return out
+	case models.JSONB:
+		return localizeJSONB(val, lang)
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, element := range val {
+			out[i] = localizeValue(element, lang)
+		}
+		return out
+	}
+	return v
+}
+
+func localizeJSONB(j models.JSONB, lang string) models.JSONB {
+	if j == nil {
+		return nil
+	}
+	out := make(models.JSONB, len(j))
+	for key, val := range j {
+		out[key] = localizeValue(val, lang)
+	}
+	return out
+}
+
+// localizedField returns data[field] resolved to lang when it is a multilingual string,
+// or fallback
+func localizedField(data models.JSONB, field, lang, fallback string) string {
+	if m, ok := data[field].(map[string]interface{}); ok && models.IsTranslationMap(m) {
+		if text := localizedText(m, lang); text != "" {
+			return text
+		}
+	}
+	return fallback
+}
+
+func localizeItem(item models.Item, lang string) models.Item {
+	item.Name = localizedField(item.Data, "name", lang, item.Name)
+	item.Description = localizedField(item.Data, "description", lang, item.Description)
+	item.Data = localizeJSONB(item.Data, lang)
+	return item
+}
+
+func localizeQuest(quest models.Quest, lang string) models.Quest {
+	quest.Name = localizedField(quest.Data, "name", lang, quest.Name)
+	quest.Description = localizedField(quest.Data, "description", lang, quest.Description)
+	quest.Objectives = localizeJSONB(quest.Objectives, lang)
+	quest.Data = localizeJSONB(quest.Data, lang)
+	return quest
+}
+
+func localizeSkillNode(node models.SkillNode, lang string) models.SkillNode {
+	node.Name = localizedField(node.Data, "name", lang, node.Name)
+	node.Description = localizedField(node.Data, "description", lang, node.Description)
+	node.Data = localizeJSONB(node.Data, lang)
+	return node
+}
+
+func localizeHideoutModule(module models.HideoutModule, lang string) models.HideoutModule {
+	module.Name = localizedField(module.Data, "name", lang, module.Name)
+	module.Description = localizedField(module.Data, "description", lang, module.Description)
+	module.Levels = localizeJSONB(module.Levels, lang)
+	module.Data = localizeJSONB(module.Data, lang)
+	return module
+}
+
+// localizeAll applies localize to a copy of rows; rows are returned as-is without a language
+func localizeAll[T any](rows []T, lang string, localize func(T, string) T) []T {
+	if lang == "" {
+		return rows
+	}
+	out := make([]T, len(rows))
+	for i, row := range rows {
+		out[i] = localize(row, lang)
+	}
+	return out
+}
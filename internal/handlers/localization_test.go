@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestLocalizeQuestResolvesLanguageWithEnglishFallback(t *testing.T) {
+	quest := models.Quest{
+		Name: "Raw name",
+		Objectives: models.JSONB{"objectives": []interface{}{
+			map[string]interface{}{"en": "Get 3 ARC Alloy", "de": "Besorge 3 ARC-Legierung"},
+			map[string]interface{}{"en": "Talk to Shani"},
+		}},
+		Data: models.JSONB{
+			"name":        map[string]interface{}{"en": "Trash Into Treasure", "de": "Aus Müll wird Gold"},
+			"description": map[string]interface{}{"en": "English only"},
+			"position":    map[string]interface{}{"x": 1.0, "y": 2.0},
+		},
+	}
+
+	localized := localizeQuest(quest, "de")
+	if localized.Name != "Aus Müll wird Gold" {
+		t.Errorf("expected German name, got %q", localized.Name)
+	}
+	if localized.Description != "English only" {
+		t.Errorf("expected English fallback description, got %q", localized.Description)
+	}
+	objectives := localized.Objectives["objectives"].([]interface{})
+	if objectives[0] != "Besorge 3 ARC-Legierung" || objectives[1] != "Talk to Shani" {
+		t.Errorf("unexpected objectives: %v", objectives)
+	}
+	if _, ok := localized.Data["position"].(map[string]interface{}); !ok {
+		t.Errorf("expected non-language map to be kept, got %v", localized.Data["position"])
+	}
+	if _, ok := quest.Data["name"].(map[string]interface{}); !ok {
+		t.Error("expected the original quest data to be left untouched")
+	}
+}
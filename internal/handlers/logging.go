@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/logging"
+)
+
+var loggingLog = logging.For("logging")
+
+type LoggingHandler struct{}
+
+func NewLoggingHandler() *LoggingHandler {
+	return &LoggingHandler{}
+}
+
+// GetLevel returns the current log level
+// @Summary Get the log level
+// @Description The minimum level this instance logs at: debug, info, warn or error.
+// @Tags management
+// @Produce json
+// @Success 200 {object} map[string]string "Current level"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/logging/level [get]
+func (h *LoggingHandler) GetLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"level": logging.Level()})
+}
+
+// SetLevel changes the log level without restarting
+// @Summary Set the log level
+// @Description Change the minimum level this instance logs at, e.g. to debug while investigating an incident and back to info afterwards. Takes effect immediately, only on the instance serving the request, and lasts until it is changed again or the process restarts, which goes back to LOG_LEVEL.
+// @Tags management
+// @Accept json
+// @Produce json
+// @Param level body map[string]string true "New level ({\"level\": \"debug\"})"
+// @Success 200 {object} map[string]string "Level changed"
+// @Failure 400 {object} ErrorResponse "Unknown level"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/logging/level [put]
+func (h *LoggingHandler) SetLevel(c *gin.Context) {
+	var req struct {
+		Level string `json:"level" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	previous, err := logging.SetLevel(req.Level)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Logged at warn so the change shows up whatever the old and new levels are
+	loggingLog.Warn("Log level changed", "from", previous, "to", logging.Level())
+
+	c.JSON(http.StatusOK, gin.H{"level": logging.Level(), "previous": previous})
+}
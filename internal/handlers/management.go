@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mat/arcapi/internal/middleware"
@@ -18,6 +21,7 @@ type ManagementHandler struct {
 	auditLogRepo      *repository.AuditLogRepository
 	userRepo          *repository.UserRepository
 	hideoutModuleRepo *repository.HideoutModuleRepository
+	rbacService       *services.RBACService
 }
 
 func NewManagementHandler(
@@ -26,6 +30,7 @@ func NewManagementHandler(
 	auditLogRepo *repository.AuditLogRepository,
 	userRepo *repository.UserRepository,
 	hideoutModuleRepo *repository.HideoutModuleRepository,
+	rbacService *services.RBACService,
 ) *ManagementHandler {
 	return &ManagementHandler{
 		authService:       authService,
@@ -33,6 +38,7 @@ func NewManagementHandler(
 		auditLogRepo:      auditLogRepo,
 		userRepo:          userRepo,
 		hideoutModuleRepo: hideoutModuleRepo,
+		rbacService:       rbacService,
 	}
 }
 
@@ -58,14 +64,15 @@ func (h *ManagementHandler) CreateAPIKey(c *gin.Context) {
 	ctx := authCtx.(*middleware.AuthContext)
 	user := ctx.User.(*models.User)
 
-	// Security check: Only admins can create API keys
-	if user.Role != models.RoleAdmin {
+	// Security check: Only user managers can create API keys
+	if !h.rbacService.HasPermission(user, models.PermManageUsers) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can create API keys"})
 		return
 	}
 
 	var req struct {
-		Name string `json:"name" binding:"required"`
+		Name   string   `json:"name" binding:"required"`
+		Scopes []string `json:"scopes"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -73,7 +80,14 @@ func (h *ManagementHandler) CreateAPIKey(c *gin.Context) {
 		return
 	}
 
-	key, err := h.authService.CreateAPIKey(user.ID, req.Name)
+	for _, scope := range req.Scopes {
+		if !models.IsValidAPIKeyScope(scope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown scope: " + scope})
+			return
+		}
+	}
+
+	key, err := h.authService.CreateAPIKeyWithScopes(user.ID, req.Name, req.Scopes)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
 		return
@@ -82,6 +96,7 @@ func (h *ManagementHandler) CreateAPIKey(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{
 		"api_key": key,
 		"name":    req.Name,
+		"scopes":  req.Scopes,
 		"warning": "Save this API key now. You won't be able to see it again.",
 	})
 }
@@ -107,8 +122,8 @@ func (h *ManagementHandler) ListAPIKeys(c *gin.Context) {
 	var keys []models.APIKey
 	var err error
 
-	// Admins can see all keys, regular users only see their own
-	if user.Role == models.RoleAdmin {
+	// User managers can see all keys, regular users only see their own
+	if h.rbacService.HasPermission(user, models.PermManageUsers) {
 		// Get all API keys for admins
 		keys, err = h.apiKeyRepo.FindAll()
 	} else {
@@ -164,7 +179,7 @@ func (h *ManagementHandler) RevokeAPIKey(c *gin.Context) {
 		return
 	}
 
-	if key.UserID != user.ID && user.Role != models.RoleAdmin {
+	if key.UserID != user.ID && !h.rbacService.HasPermission(user, models.PermManageUsers) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
 		return
 	}
@@ -181,6 +196,40 @@ func (h *ManagementHandler) RevokeAPIKey(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
 }
 
+// RevokeJWT revokes a single JWT by its jti
+// @Summary Revoke JWT by jti
+// @Description Revoke exactly one token by its jti (or Supabase session ID) without affecting the user's other sessions
+// @Tags management
+// @Accept json
+// @Produce json
+// @Param jti path string true "JWT ID"
+// @Success 200 {object} map[string]string "Successfully revoked token"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Admin access required"
+// @Failure 404 {object} ErrorResponse "Token not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/jwts/{jti} [delete]
+func (h *ManagementHandler) RevokeJWT(c *gin.Context) {
+	jti := c.Param("jti")
+	if jti == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token ID"})
+		return
+	}
+
+	if err := h.authService.RevokeJWTByJTI(jti); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Token not found or already revoked"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Token revoked"})
+}
+
 // QueryLogs queries audit logs with filters
 // QueryLogs queries audit logs with filters
 // @Summary Query audit logs
@@ -194,6 +243,10 @@ func (h *ManagementHandler) RevokeAPIKey(c *gin.Context) {
 // @Param user_id query int false "Filter by User ID"
 // @Param method query string false "Filter by HTTP method"
 // @Param endpoint query string false "Filter by endpoint"
+// @Param entity_type query string false "Filter by changed entity type" Enums(quest, item, user)
+// @Param entity_id query string false "Filter by changed entity ID"
+// @Param field query string false "Filter by changed field, e.g. xp or data.rarity"
+// @Param request_id query string false "Filter by request ID, as returned in X-Request-ID"
 // @Param start_time query string false "Filter by start time (RFC3339)"
 // @Param end_time query string false "Filter by end time (RFC3339)"
 // @Success 200 {object} PaginatedResponse{data=[]models.AuditLog} "Successfully fetched logs"
@@ -213,7 +266,7 @@ func (h *ManagementHandler) QueryLogs(c *gin.Context) {
 		}
 	}
 	if l := c.Query("limit"); l != "" {
-		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= middleware.PageLimit(c) {
 			limit = parsed
 		}
 	}
@@ -221,7 +274,7 @@ func (h *ManagementHandler) QueryLogs(c *gin.Context) {
 	offset := (page - 1) * limit
 
 	var apiKeyID, jwtTokenID, userID *uint
-	var endpoint, method, startTime, endTime *string
+	var endpoint, method, entityType, entityID, changedField, requestID, startTime, endTime *string
 
 	if k := c.Query("api_key_id"); k != "" {
 		if id, err := strconv.ParseUint(k, 10, 32); err == nil {
@@ -247,6 +300,18 @@ func (h *ManagementHandler) QueryLogs(c *gin.Context) {
 	if m := c.Query("method"); m != "" {
 		method = &m
 	}
+	if t := c.Query("entity_type"); t != "" {
+		entityType = &t
+	}
+	if i := c.Query("entity_id"); i != "" {
+		entityID = &i
+	}
+	if f := c.Query("field"); f != "" {
+		changedField = &f
+	}
+	if r := c.Query("request_id"); r != "" {
+		requestID = &r
+	}
 	if s := c.Query("start_time"); s != "" {
 		startTime = &s
 	}
@@ -255,7 +320,7 @@ func (h *ManagementHandler) QueryLogs(c *gin.Context) {
 	}
 
 	logs, count, err := h.auditLogRepo.FindByFilters(
-		apiKeyID, jwtTokenID, userID, endpoint, method, startTime, endTime, offset, limit,
+		apiKeyID, jwtTokenID, userID, endpoint, method, entityType, entityID, changedField, requestID, startTime, endTime, offset, limit,
 	)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query logs"})
@@ -284,7 +349,7 @@ func (h *ManagementHandler) QueryLogs(c *gin.Context) {
 // @Success 200 {object} map[string]interface{} "Successfully updated user access"
 // @Failure 400 {object} ErrorResponse "Invalid user ID or input"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
-// @Failure 403 {object} ErrorResponse "Not an administrator"
+// @Failure 403 {object} ErrorResponse "Permission required, or the role grants more than the caller holds"
 // @Failure 404 {object} ErrorResponse "User not found"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Security ApiKeyAuth
@@ -319,13 +384,14 @@ func (h *ManagementHandler) UpdateUserAccess(c *gin.Context) {
 		return
 	}
 
-	// Security check: Only admins can update user access (enforced by AdminMiddleware, but double-check)
-	if currentUser.Role != models.RoleAdmin {
+	// Security check: Only user managers can update user access (enforced by RequirePermission, but double-check)
+	if !h.rbacService.HasPermission(currentUser, models.PermManageUsers) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can update user access"})
 		return
 	}
 
 	// Update access
+	before := *targetUser
 	targetUser.CanAccessData = req.CanAccessData
 	err = h.userRepo.Update(targetUser)
 	if err != nil {
@@ -333,6 +399,8 @@ func (h *ManagementHandler) UpdateUserAccess(c *gin.Context) {
 		return
 	}
 
+	middleware.RecordChange(c, middleware.AuditEntityUser, targetUser.ID, before, targetUser)
+
 	// Invalidate cached auth data for this user to ensure changes take effect immediately
 	h.authService.InvalidateUserCache(targetUser.ID)
 
@@ -345,7 +413,7 @@ func (h *ManagementHandler) UpdateUserAccess(c *gin.Context) {
 // UpdateUserRole updates a user's role (admin only)
 // UpdateUserRole updates a user's role (admin only)
 // @Summary Update user role
-// @Description Change a user's role to 'admin' or 'user'. Only admins can update roles.
+// @Description Change a user's role. Requires the manage_users permission; only admins can change an admin's role or grant admin, and only roles whose permissions the caller holds can be assigned.
 // @Tags management
 // @Accept json
 // @Produce json
@@ -369,7 +437,7 @@ func (h *ManagementHandler) UpdateUserRole(c *gin.Context) {
 	}
 
 	var req struct {
-		Role string `json:"role" binding:"required,oneof=admin user"`
+		Role string `json:"role" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -389,19 +457,31 @@ func (h *ManagementHandler) UpdateUserRole(c *gin.Context) {
 		return
 	}
 
-	// Security check: Only admins can update user roles (enforced by AdminMiddleware, but double-check)
-	if currentUser.Role != models.RoleAdmin {
+	// Security check: Only user managers can update user roles (enforced by RequirePermission, but double-check)
+	if !h.rbacService.HasPermission(currentUser, models.PermManageUsers) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can update user roles"})
 		return
 	}
 
-	// Prevent admins from demoting themselves
-	if currentUser.ID == targetUser.ID && req.Role != string(models.RoleAdmin) {
+	if !h.rbacService.RoleExists(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown role: " + req.Role})
+		return
+	}
+
+	// Only admins can grant or take away admin, and nobody can hand out more than they hold
+	if err := h.rbacService.CheckAssignRole(currentUser, targetUser, req.Role); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only assign roles to non-admins, and only roles whose permissions you hold yourself"})
+		return
+	}
+
+	// Prevent users from removing their own ability to manage users
+	if currentUser.ID == targetUser.ID && !h.rbacService.HasPermission(&models.User{Role: models.UserRole(req.Role)}, models.PermManageUsers) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot change your own admin role"})
 		return
 	}
 
 	// Update role
+	before := *targetUser
 	targetUser.Role = models.UserRole(req.Role)
 	err = h.userRepo.Update(targetUser)
 	if err != nil {
@@ -409,6 +489,8 @@ func (h *ManagementHandler) UpdateUserRole(c *gin.Context) {
 		return
 	}
 
+	middleware.RecordChange(c, middleware.AuditEntityUser, targetUser.ID, before, targetUser)
+
 	// Invalidate cached auth data for this user to ensure changes take effect immediately
 	h.authService.InvalidateUserCache(targetUser.ID)
 
@@ -418,6 +500,91 @@ func (h *ManagementHandler) UpdateUserRole(c *gin.Context) {
 	})
 }
 
+// ListRoles lists all roles and their permissions
+// @Summary List roles
+// @Description Fetch all roles with their permission strings, plus the list of known permissions
+// @Tags management
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Successfully fetched roles"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/roles [get]
+func (h *ManagementHandler) ListRoles(c *gin.Context) {
+	roles, err := h.rbacService.ListRoles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch roles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":        roles,
+		"permissions": models.AllPermissions,
+	})
+}
+
+// SaveRole creates a role or replaces its permissions
+// @Summary Create or update a role
+// @Description Create a role or replace the permissions of an existing one. Requires the manage_roles permission, and only permissions the caller holds can be granted; roles with permissions the caller lacks can't be changed. The admin role always keeps every permission.
+// @Tags management
+// @Accept json
+// @Produce json
+// @Param name path string true "Role name"
+// @Param role body map[string]interface{} true "description and permissions"
+// @Success 200 {object} models.Role "Successfully saved the role"
+// @Failure 400 {object} ErrorResponse "Invalid input data"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/roles/{name} [put]
+func (h *ManagementHandler) SaveRole(c *gin.Context) {
+	name := strings.ToLower(strings.TrimSpace(c.Param("name")))
+	if name == "" || len(name) > 20 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role name must be 1-20 characters"})
+		return
+	}
+	if name == string(models.RoleAdmin) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The admin role always has every permission"})
+		return
+	}
+
+	var req struct {
+		Description string   `json:"description"`
+		Permissions []string `json:"permissions"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Permissions == nil {
+		req.Permissions = []string{}
+	}
+
+	authCtx, _ := c.Get(middleware.AuthContextKey)
+	currentUser := authCtx.(*middleware.AuthContext).User.(*models.User)
+	if err := h.rbacService.CheckSaveRole(currentUser, name, req.Permissions); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only grant permissions you hold, and only change roles whose permissions you hold"})
+		return
+	}
+
+	role, err := h.rbacService.SaveRole(name, req.Description, req.Permissions)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidPermission) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown permission; valid permissions are " + strings.Join(models.AllPermissions, ", ")})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save role"})
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
 // ListUsers lists all users (admin only)
 // ListUsers lists all users (admin only)
 // @Summary List all users
@@ -427,7 +594,9 @@ func (h *ManagementHandler) UpdateUserRole(c *gin.Context) {
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Users per page" default(50)
+// @Param seen_since query string false "Only users whose last request was at or after this time (RFC3339), most recently seen first"
 // @Success 200 {object} PaginatedResponse{data=[]models.User} "Successfully fetched users"
+// @Failure 400 {object} ErrorResponse "Invalid seen_since"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
 // @Failure 403 {object} ErrorResponse "Not an administrator"
 // @Failure 500 {object} ErrorResponse "Internal server error"
@@ -444,13 +613,25 @@ func (h *ManagementHandler) ListUsers(c *gin.Context) {
 		}
 	}
 	if l := c.Query("limit"); l != "" {
-		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= middleware.PageLimit(c) {
 			limit = parsed
 		}
 	}
 
 	offset := (page - 1) * limit
-	users, count, err := h.userRepo.FindAll(offset, limit)
+	var users []models.User
+	var count int64
+	var err error
+	if seenSince := c.Query("seen_since"); seenSince != "" {
+		since, parseErr := time.Parse(time.RFC3339, seenSince)
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "seen_since must be an RFC3339 time"})
+			return
+		}
+		users, count, err = h.userRepo.FindSeenSince(since, offset, limit)
+	} else {
+		users, count, err = h.userRepo.FindAll(offset, limit)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
 		return
@@ -497,8 +678,8 @@ func (h *ManagementHandler) GetUser(c *gin.Context) {
 	ctx := authCtx.(*middleware.AuthContext)
 	currentUser := ctx.User.(*models.User)
 
-	// Security check: Users can only view their own data unless they're admin
-	if currentUser.Role != models.RoleAdmin && currentUser.ID != uint(id) {
+	// Security check: Users can only view their own data unless they manage users
+	if !h.rbacService.HasPermission(currentUser, models.PermManageUsers) && currentUser.ID != uint(id) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own user data"})
 		return
 	}
@@ -555,8 +736,8 @@ func (h *ManagementHandler) UpdateUserProfile(c *gin.Context) {
 	ctx := authCtx.(*middleware.AuthContext)
 	currentUser := ctx.User.(*models.User)
 
-	// Security check: Users can only update their own profile unless they're admin
-	if currentUser.Role != models.RoleAdmin && currentUser.ID != uint(id) {
+	// Security check: Users can only update their own profile unless they manage users
+	if !h.rbacService.HasPermission(currentUser, models.PermManageUsers) && currentUser.ID != uint(id) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own profile"})
 		return
 	}
@@ -578,8 +759,8 @@ func (h *ManagementHandler) UpdateUserProfile(c *gin.Context) {
 		return
 	}
 
-	// Permission check: Regular users can only update username, admins can update everything
-	if currentUser.Role != models.RoleAdmin {
+	// Permission check: Regular users can only update username, user managers can update everything
+	if !h.rbacService.HasPermission(currentUser, models.PermManageUsers) {
 		// Regular user can only update username
 		if req.Email != nil {
 			c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your username. Contact an administrator to change your email."})
@@ -659,6 +840,8 @@ func (h *ManagementHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
+	middleware.RecordChange(c, middleware.AuditEntityUser, targetUser.ID, targetUser, nil)
+
 	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
 }
 
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+type MapMarkerHandler struct {
+	repo    *repository.MapMarkerRepository
+	mapRepo *repository.MapRepository
+}
+
+func NewMapMarkerHandler(repo *repository.MapMarkerRepository, mapRepo *repository.MapRepository) *MapMarkerHandler {
+	return &MapMarkerHandler{repo: repo, mapRepo: mapRepo}
+}
+
+// mapMarkerRequest is the body accepted when creating or updating a marker
+type mapMarkerRequest struct {
+	Type            models.MapMarkerType `json:"type" binding:"required"`
+	Name            string               `json:"name" binding:"required"`
+	Description     string               `json:"description"`
+	X               *float64             `json:"x" binding:"required"`
+	Y               *float64             `json:"y" binding:"required"`
+	Z               *float64             `json:"z"`
+	QuestExternalID string               `json:"quest_external_id"`
+	Data            models.JSONB         `json:"data"`
+}
+
+func (req mapMarkerRequest) apply(marker *models.MapMarker) {
+	marker.Type = req.Type
+	marker.Name = strings.TrimSpace(req.Name)
+	marker.Description = req.Description
+	marker.X = *req.X
+	marker.Y = *req.Y
+	marker.Z = req.Z
+	marker.QuestExternalID = req.QuestExternalID
+	marker.Data = req.Data
+}
+
+// bindMapMarkerRequest parses and validates a marker body, writing a 400 on failure
+func bindMapMarkerRequest(c *gin.Context) (*mapMarkerRequest, bool) {
+	var req mapMarkerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if !req.Type.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be one of extraction, loot_zone, quest_location, other"})
+		return nil, false
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return nil, false
+	}
+	return &req, true
+}
+
+// List returns the markers placed on a map
+// @Summary List map markers
+// @Description Fetch structured points of interest (extraction points, loot zones, quest locations) for a map, optionally filtered by type
+// @Tags maps
+// @Accept json
+// @Produce json
+// @Param id path int true "Map ID"
+// @Param type query string false "Marker type" Enums(extraction, loot_zone, quest_location, other)
+// @Success 200 {object} map[string]interface{} "Successfully fetched markers"
+// @Failure 400 {object} ErrorResponse "Invalid map ID or marker type"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Map not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /maps/{id}/markers [get]
+func (h *MapMarkerHandler) List(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid map ID"})
+		return
+	}
+
+	markerType := models.MapMarkerType(c.Query("type"))
+	if markerType != "" && !markerType.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid marker type"})
+		return
+	}
+
+	if _, err := h.mapRepo.FindByID(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Map not found"})
+		return
+	}
+
+	markers, err := h.repo.FindByMap(uint(id), markerType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch map markers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"map_id": uint(id),
+		"data":   markers,
+		"total":  len(markers),
+	})
+}
+
+// Create adds a marker to a map
+// @Summary Create a map marker
+// @Description Place a point of interest on a map. Coordinates are in the map's in-game units; z is optional.
+// @Tags management
+// @Accept json
+// @Produce json
+// @Param id path int true "Map ID"
+// @Param marker body mapMarkerRequest true "Marker"
+// @Success 201 {object} models.MapMarker "Successfully created the marker"
+// @Failure 400 {object} ErrorResponse "Invalid input data"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Admin access required"
+// @Failure 404 {object} ErrorResponse "Map not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/maps/{id}/markers [post]
+func (h *MapMarkerHandler) Create(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid map ID"})
+		return
+	}
+
+	req, ok := bindMapMarkerRequest(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.mapRepo.FindByID(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Map not found"})
+		return
+	}
+
+	marker := &models.MapMarker{MapID: uint(id)}
+	req.apply(marker)
+	if err := h.repo.Create(marker); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create map marker"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, marker)
+}
+
+// Update replaces a marker's fields
+// @Summary Update a map marker
+// @Description Replace the type, name, coordinates and details of an existing marker
+// @Tags management
+// @Accept json
+// @Produce json
+// @Param id path int true "Marker ID"
+// @Param marker body mapMarkerRequest true "Marker"
+// @Success 200 {object} models.MapMarker "Successfully updated the marker"
+// @Failure 400 {object} ErrorResponse "Invalid input data"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Admin access required"
+// @Failure 404 {object} ErrorResponse "Marker not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/map-markers/{id} [put]
+func (h *MapMarkerHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid marker ID"})
+		return
+	}
+
+	req, ok := bindMapMarkerRequest(c)
+	if !ok {
+		return
+	}
+
+	marker, err := h.repo.FindByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Marker not found"})
+		return
+	}
+
+	req.apply(marker)
+	if err := h.repo.Update(marker); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update map marker"})
+		return
+	}
+
+	c.JSON(http.StatusOK, marker)
+}
+
+// Delete removes a map marker
+// @Summary Delete a map marker
+// @Description Remove a point of interest by ID
+// @Tags management
+// @Accept json
+// @Produce json
+// @Param id path int true "Marker ID"
+// @Success 204 "Successfully deleted the marker"
+// @Failure 400 {object} ErrorResponse "Invalid marker ID"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Admin access required"
+// @Failure 404 {object} ErrorResponse "Marker not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/map-markers/{id} [delete]
+func (h *MapMarkerHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid marker ID"})
+		return
+	}
+
+	if _, err := h.repo.FindByID(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Marker not found"})
+		return
+	}
+
+	if err := h.repo.Delete(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete map marker"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+type MigrationHandler struct {
+	db *repository.DB
+}
+
+func NewMigrationHandler(db *repository.DB) *MigrationHandler {
+	return &MigrationHandler{db: db}
+}
+
+// Status reports the database schema migrations
+// @Summary Get migration status
+// @Description List the schema migrations this build knows and whether each has been applied, plus any applied by a newer build.
+// @Tags management
+// @Accept json
+// @Produce json
+// @Success 200 {object} repository.MigrationStatus "Migration status"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/migrations/status [get]
+func (h *MigrationHandler) Status(c *gin.Context) {
+	status, err := h.db.MigrationStatus()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read migration status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
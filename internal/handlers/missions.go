@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/middleware"
 	"github.com/mat/arcapi/internal/models"
 	"github.com/mat/arcapi/internal/repository"
 )
@@ -27,7 +28,7 @@ func (h *MissionHandler) List(c *gin.Context) {
 		}
 	}
 	if l := c.Query("limit"); l != "" {
-		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= middleware.PageLimit(c) {
 			limit = parsed
 		}
 	}
@@ -78,12 +79,15 @@ func (h *MissionHandler) Create(c *gin.Context) {
 		return
 	}
 
+	mission.ManualOverride = true
 	err := h.repo.Create(&mission)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create mission"})
 		return
 	}
 
+	middleware.RecordChange(c, middleware.AuditEntityQuest, mission.ID, nil, mission)
+
 	c.JSON(http.StatusCreated, mission)
 }
 
@@ -101,13 +105,17 @@ func (h *MissionHandler) Update(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.repo.FindByID(uint(id))
 	mission.ID = uint(id)
+	mission.ManualOverride = true
 	err = h.repo.Update(&mission)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update mission"})
 		return
 	}
 
+	middleware.RecordChange(c, middleware.AuditEntityQuest, mission.ID, before, mission)
+
 	c.JSON(http.StatusOK, mission)
 }
 
@@ -119,11 +127,14 @@ func (h *MissionHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.repo.FindByID(uint(id))
 	err = h.repo.Delete(uint(id))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete mission"})
 		return
 	}
 
+	middleware.RecordChange(c, middleware.AuditEntityQuest, uint(id), before, nil)
+
 	c.JSON(http.StatusNoContent, nil)
 }
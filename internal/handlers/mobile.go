@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/config"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+	"github.com/mat/arcapi/internal/services"
+)
+
+type MobileHandler struct {
+	alertRepo                 *repository.AlertRepository
+	questProgressRepo         *repository.UserQuestProgressRepository
+	hideoutModuleProgressRepo *repository.UserHideoutModuleProgressRepository
+	skillNodeProgressRepo     *repository.UserSkillNodeProgressRepository
+	blueprintProgressRepo     *repository.UserBlueprintProgressRepository
+	syncService               *services.SyncService
+	cfg                       *config.Config
+}
+
+func NewMobileHandler(
+	alertRepo *repository.AlertRepository,
+	questProgressRepo *repository.UserQuestProgressRepository,
+	hideoutModuleProgressRepo *repository.UserHideoutModuleProgressRepository,
+	skillNodeProgressRepo *repository.UserSkillNodeProgressRepository,
+	blueprintProgressRepo *repository.UserBlueprintProgressRepository,
+	syncService *services.SyncService,
+	cfg *config.Config,
+) *MobileHandler {
+	return &MobileHandler{
+		alertRepo:                 alertRepo,
+		questProgressRepo:         questProgressRepo,
+		hideoutModuleProgressRepo: hideoutModuleProgressRepo,
+		skillNodeProgressRepo:     skillNodeProgressRepo,
+		blueprintProgressRepo:     blueprintProgressRepo,
+		syncService:               syncService,
+		cfg:                       cfg,
+	}
+}
+
+// ProgressSummary holds per-category progress counts for a user
+type ProgressSummary struct {
+	QuestsCompleted        int64 `json:"quests_completed"`
+	HideoutModulesUnlocked int64 `json:"hideout_modules_unlocked"`
+	SkillNodesUnlocked     int64 `json:"skill_nodes_unlocked"`
+	BlueprintsConsumed     int64 `json:"blueprints_consumed"`
+}
+
+// Bootstrap returns everything the mobile app needs on cold start in a single response
+// @Summary Mobile bootstrap
+// @Description Returns the current user, active alerts, data version, feature flags and a progress summary in one call.
+// @Tags mobile
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Bootstrap payload"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /mobile/bootstrap [get]
+func (h *MobileHandler) Bootstrap(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	alerts, err := h.alertRepo.FindActive()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch active alerts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":          user,
+		"alerts":        alerts,
+		"data_version":  h.syncService.DataVersion(),
+		"feature_flags": h.cfg.GetFeatureFlags(),
+		"progress":      h.progressSummary(user.ID),
+	})
+}
+
+// progressSummary counts progress per category. Failures are logged and reported
+// as zero so a single slow table does not block app start.
+func (h *MobileHandler) progressSummary(userID uint) ProgressSummary {
+	var summary ProgressSummary
+	var err error
+
+	if summary.QuestsCompleted, err = h.questProgressRepo.CountCompleted(userID); err != nil {
+		log.Printf("Warning: Failed to count quest progress for user %d: %v", userID, err)
+	}
+	if summary.HideoutModulesUnlocked, err = h.hideoutModuleProgressRepo.CountUnlocked(userID); err != nil {
+		log.Printf("Warning: Failed to count hideout module progress for user %d: %v", userID, err)
+	}
+	if summary.SkillNodesUnlocked, err = h.skillNodeProgressRepo.CountUnlocked(userID); err != nil {
+		log.Printf("Warning: Failed to count skill node progress for user %d: %v", userID, err)
+	}
+	if summary.BlueprintsConsumed, err = h.blueprintProgressRepo.CountConsumed(userID); err != nil {
+		log.Printf("Warning: Failed to count blueprint progress for user %d: %v", userID, err)
+	}
+
+	return summary
+}
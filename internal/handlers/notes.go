@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+// maxNoteLength caps a note's body, in characters
+const maxNoteLength = 4000
+
+type NoteHandler struct {
+	noteRepo          *repository.UserNoteRepository
+	questRepo         *repository.QuestRepository
+	itemRepo          *repository.ItemRepository
+	hideoutModuleRepo *repository.HideoutModuleRepository
+}
+
+func NewNoteHandler(
+	noteRepo *repository.UserNoteRepository,
+	questRepo *repository.QuestRepository,
+	itemRepo *repository.ItemRepository,
+	hideoutModuleRepo *repository.HideoutModuleRepository,
+) *NoteHandler {
+	return &NoteHandler{
+		noteRepo:          noteRepo,
+		questRepo:         questRepo,
+		itemRepo:          itemRepo,
+		hideoutModuleRepo: hideoutModuleRepo,
+	}
+}
+
+// entityExists reports whether entityID names an existing entity of entityType; ok is
+// false for entity types notes can't be attached to
+func (h *NoteHandler) entityExists(entityType, entityID string) (exists, ok bool) {
+	var err error
+	switch entityType {
+	case models.NoteEntityQuest:
+		_, err = h.questRepo.FindByExternalID(entityID)
+	case models.NoteEntityItem:
+		_, err = h.itemRepo.FindByExternalID(entityID)
+	case models.NoteEntityHideoutModule:
+		_, err = h.hideoutModuleRepo.FindByExternalID(entityID)
+	default:
+		return false, false
+	}
+	return err == nil, true
+}
+
+func validNoteEntityType(entityType string) bool {
+	switch entityType {
+	case models.NoteEntityQuest, models.NoteEntityItem, models.NoteEntityHideoutModule:
+		return true
+	}
+	return false
+}
+
+// List returns the current user's notes
+// @Summary List my notes
+// @Description Fetch the authenticated user's notes, most recently edited first, optionally for one entity type.
+// @Tags progress
+// @Accept json
+// @Produce json
+// @Param entity_type query string false "Entity type" Enums(quest, item, hideout_module)
+// @Success 200 {object} map[string][]models.UserNote "Successfully fetched notes"
+// @Failure 400 {object} ErrorResponse "Invalid entity type"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /progress/notes [get]
+func (h *NoteHandler) List(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	entityType := c.Query("entity_type")
+	if entityType != "" && !validNoteEntityType(entityType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "entity_type must be quest, item or hideout_module"})
+		return
+	}
+
+	notes, err := h.noteRepo.FindByUserID(user.ID, entityType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": notes})
+}
+
+// Get returns the current user's note on an entity
+// @Summary Get my note on an entity
+// @Description Fetch the authenticated user's note on a quest, item or hideout module by its external ID.
+// @Tags progress
+// @Accept json
+// @Produce json
+// @Param entity_type path string true "Entity type" Enums(quest, item, hideout_module)
+// @Param entity_id path string true "Entity External ID"
+// @Success 200 {object} models.UserNote "Successfully fetched the note"
+// @Failure 400 {object} ErrorResponse "Invalid entity type"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Note not found"
+// @Security BearerAuth
+// @Router /progress/notes/{entity_type}/{entity_id} [get]
+func (h *NoteHandler) Get(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	entityType := c.Param("entity_type")
+	if !validNoteEntityType(entityType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "entity_type must be quest, item or hideout_module"})
+		return
+	}
+
+	note, err := h.noteRepo.Find(user.ID, entityType, c.Param("entity_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, note)
+}
+
+// Upsert creates or replaces the current user's note on an entity
+// @Summary Save my note on an entity
+// @Description Create or replace the authenticated user's note on a quest, item or hideout module. Notes are limited to 4000 characters.
+// @Tags progress
+// @Accept json
+// @Produce json
+// @Param entity_type path string true "Entity type" Enums(quest, item, hideout_module)
+// @Param entity_id path string true "Entity External ID"
+// @Param note body map[string]string true "Note (body)"
+// @Success 200 {object} models.UserNote "Successfully saved the note"
+// @Failure 400 {object} ErrorResponse "Invalid input or entity type"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Entity not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /progress/notes/{entity_type}/{entity_id} [put]
+func (h *NoteHandler) Upsert(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	entityType := c.Param("entity_type")
+	entityID := c.Param("entity_id")
+	if !validNoteEntityType(entityType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "entity_type must be quest, item or hideout_module"})
+		return
+	}
+
+	var req struct {
+		Body string `json:"body" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "body is required"})
+		return
+	}
+	if utf8.RuneCountInString(body) > maxNoteLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "body must be at most 4000 characters"})
+		return
+	}
+
+	if exists, _ := h.entityExists(entityType, entityID); !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Entity not found"})
+		return
+	}
+
+	note, err := h.noteRepo.Upsert(user.ID, entityType, entityID, body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save note"})
+		return
+	}
+
+	c.JSON(http.StatusOK, note)
+}
+
+// Delete removes the current user's note on an entity
+// @Summary Delete my note on an entity
+// @Description Remove the authenticated user's note on a quest, item or hideout module.
+// @Tags progress
+// @Accept json
+// @Produce json
+// @Param entity_type path string true "Entity type" Enums(quest, item, hideout_module)
+// @Param entity_id path string true "Entity External ID"
+// @Success 204 "Successfully deleted the note"
+// @Failure 400 {object} ErrorResponse "Invalid entity type"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Note not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /progress/notes/{entity_type}/{entity_id} [delete]
+func (h *NoteHandler) Delete(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	entityType := c.Param("entity_type")
+	if !validNoteEntityType(entityType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "entity_type must be quest, item or hideout_module"})
+		return
+	}
+	entityID := c.Param("entity_id")
+
+	if _, err := h.noteRepo.Find(user.ID, entityType, entityID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		return
+	}
+
+	if err := h.noteRepo.Delete(user.ID, entityType, entityID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete note"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+)
+
+// notesRouter serves the note routes as user 1. Requests that fail validation are answered
+// before any repository is used, so the handler has none.
+func notesRouter(authenticated bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	if authenticated {
+		r.Use(func(c *gin.Context) {
+			c.Set("user", &models.User{ID: 1})
+		})
+	}
+	h := &NoteHandler{}
+	r.GET("/progress/notes", h.List)
+	r.GET("/progress/notes/:entity_type/:entity_id", h.Get)
+	r.PUT("/progress/notes/:entity_type/:entity_id", h.Upsert)
+	r.DELETE("/progress/notes/:entity_type/:entity_id", h.Delete)
+	return r
+}
+
+func TestNoteRoutesRejectInvalidRequests(t *testing.T) {
+	for _, tc := range []struct {
+		name, method, path, body string
+		authenticated            bool
+		want                     int
+	}{
+		{"anonymous list", http.MethodGet, "/progress/notes", "", false, http.StatusUnauthorized},
+		{"anonymous save", http.MethodPut, "/progress/notes/quest/q1", `{"body":"x"}`, false, http.StatusUnauthorized},
+		{"list of unknown type", http.MethodGet, "/progress/notes?entity_type=map", "", true, http.StatusBadRequest},
+		{"get of unknown type", http.MethodGet, "/progress/notes/map/dam", "", true, http.StatusBadRequest},
+		{"save on unknown type", http.MethodPut, "/progress/notes/map/dam", `{"body":"x"}`, true, http.StatusBadRequest},
+		{"delete of unknown type", http.MethodDelete, "/progress/notes/map/dam", "", true, http.StatusBadRequest},
+		{"save without body", http.MethodPut, "/progress/notes/item/arc_alloy", `{}`, true, http.StatusBadRequest},
+		{"save of blank body", http.MethodPut, "/progress/notes/item/arc_alloy", `{"body":"  \n "}`, true, http.StatusBadRequest},
+		{"save of long body", http.MethodPut, "/progress/notes/hideout_module/workbench", `{"body":"` + strings.Repeat("é", maxNoteLength+1) + `"}`, true, http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		notesRouter(tc.authenticated).ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tc.name, w.Code, tc.want, w.Body.String())
+		}
+	}
+}
+
+func TestValidNoteEntityType(t *testing.T) {
+	for _, entityType := range []string{models.NoteEntityQuest, models.NoteEntityItem, models.NoteEntityHideoutModule} {
+		if !validNoteEntityType(entityType) {
+			t.Errorf("%s was rejected", entityType)
+		}
+	}
+	for _, entityType := range []string{"", "map", "Quest"} {
+		if validNoteEntityType(entityType) {
+			t.Errorf("%q was accepted", entityType)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/logging"
+	"github.com/mat/arcapi/internal/middleware"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+	"github.com/mat/arcapi/internal/services"
+)
+
+var notificationLog = logging.For("notifications")
+
+const (
+	// defaultPollWait and maxPollWait bound how long a poll is held open. maxPollWait stays
+	// under the usual 60s idle timeout of proxies and load balancers.
+	defaultPollWait = 25 * time.Second
+	maxPollWait     = 55 * time.Second
+	// pollRecheckInterval is how often a held poll checks the database while the hub
+	// can't receive events from other instances
+	pollRecheckInterval = 3 * time.Second
+	// pollBatchSize caps the notifications returned by one poll
+	pollBatchSize = 100
+)
+
+type NotificationHandler struct {
+	repo *repository.NotificationRepository
+	hub  *services.NotificationHub
+}
+
+func NewNotificationHandler(repo *repository.NotificationRepository, hub *services.NotificationHub) *NotificationHandler {
+	return &NotificationHandler{repo: repo, hub: hub}
+}
+
+// List returns the current user's notification feed
+// @Summary List my notifications
+// @Description Fetch the authenticated user's notifications, newest first. Sync adds an "entity_changed" notification when a favorited or in-progress quest, item, map or hideout module changes upstream.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param unread query bool false "Only return unread notifications"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} PaginatedResponse{data=[]models.Notification} "Successfully fetched notifications"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /me/notifications [get]
+func (h *NotificationHandler) List(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	page := 1
+	limit := 20
+
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= middleware.PageLimit(c) {
+			limit = parsed
+		}
+	}
+
+	offset := (page - 1) * limit
+	notifications, count, err := h.repo.FindByUserID(user.ID, c.Query("unread") == "true", offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+	unread, err := h.repo.CountUnread(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   notifications,
+		"unread": unread,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": count,
+		},
+	})
+}
+
+// MarkRead marks one notification as read
+// @Summary Mark a notification read
+// @Description Mark one of the authenticated user's notifications as read.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param id path int true "Notification ID"
+// @Success 204 "Notification marked read"
+// @Failure 400 {object} ErrorResponse "Invalid notification ID"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Unread notification not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /me/notifications/{id}/read [post]
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	updated, err := h.repo.MarkRead(user.ID, uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification"})
+		return
+	}
+	if updated == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unread notification not found"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// MarkAllRead marks every notification as read
+// @Summary Mark all notifications read
+// @Description Mark all of the authenticated user's unread notifications as read.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]int64 "Number of notifications marked read"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /me/notifications/read-all [post]
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	updated, err := h.repo.MarkAllRead(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"marked_read": updated})
+}
+
+// parsePollWait reads the wait parameter of a poll: a Go duration such as 25s, or a plain
+// number of seconds
+func parsePollWait(raw string) (time.Duration, bool) {
+	if raw == "" {
+		return defaultPollWait, true
+	}
+	wait, err := time.ParseDuration(raw)
+	if err != nil {
+		seconds, convErr := strconv.Atoi(raw)
+		if convErr != nil {
+			return 0, false
+		}
+		wait = time.Duration(seconds) * time.Second
+	}
+	if wait < 0 || wait > maxPollWait {
+		return 0, false
+	}
+	return wait, true
+}
+
+// Poll holds the request open until the current user has notifications newer than a cursor
+// @Summary Long-poll my notifications
+// @Description For clients that can't keep a WebSocket or event stream open. Returns the authenticated user's notifications with an ID above after, oldest first, as soon as there are any, or an empty list once wait has passed. Pass the returned cursor as after on the next poll. Without after, returns straight away with the cursor of the newest notification, to start polling from.
+// @Tags notifications
+// @Produce json
+// @Param after query int false "Cursor from the previous poll"
+// @Param wait query string false "How long to wait for a notification, e.g. 25s, at most 55s" default(25s)
+// @Success 200 {object} map[string]interface{} "data, unread and cursor"
+// @Failure 400 {object} ErrorResponse "Invalid cursor or wait"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /me/notifications/poll [get]
+func (h *NotificationHandler) Poll(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	wait, ok := parsePollWait(c.Query("wait"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "wait must be a duration of at most 55s"})
+		return
+	}
+
+	rawAfter := c.Query("after")
+	if rawAfter == "" {
+		cursor, err := h.repo.LatestID(user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+			return
+		}
+		h.respondPoll(c, user.ID, []models.Notification{}, cursor)
+		return
+	}
+	after, err := parseUint(rawAfter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+		return
+	}
+
+	// The server's write timeout is shorter than a poll can be held
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(wait + 10*time.Second)); err != nil {
+		notificationLog.Warn("Failed to extend write deadline of notification poll", "error", err)
+	}
+
+	// A held poll is idle, so it doesn't count toward load shedding, and isn't mirrored
+	middleware.ReleaseInFlight(c)
+	middleware.SkipShadowTraffic(c)
+
+	// Register before the first check, so a notification created in between still wakes us
+	wake, stop := h.hub.Wait(user.ID)
+	defer stop()
+	timeout := time.NewTimer(wait)
+	defer timeout.Stop()
+	recheck := time.NewTicker(pollRecheckInterval)
+	defer recheck.Stop()
+
+	for {
+		notifications, err := h.repo.FindAfter(user.ID, after, pollBatchSize)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+			return
+		}
+		if len(notifications) > 0 {
+			h.respondPoll(c, user.ID, notifications, notifications[len(notifications)-1].ID)
+			return
+		}
+
+		// While the hub is live, every notification for the user wakes us, so the ticker only
+		// leads to the database when it isn't
+		for check := false; !check; {
+			select {
+			case <-wake:
+				check = true
+			case <-recheck.C:
+				check = !h.hub.Live()
+			case <-timeout.C:
+				h.respondPoll(c, user.ID, []models.Notification{}, after)
+				return
+			case <-c.Request.Context().Done():
+				return
+			}
+		}
+	}
+}
+
+func (h *NotificationHandler) respondPoll(c *gin.Context, userID uint, notifications []models.Notification, cursor uint) {
+	unread, err := h.repo.CountUnread(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   notifications,
+		"unread": unread,
+		"cursor": cursor,
+	})
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePollWait(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want time.Duration
+		ok   bool
+	}{
+		{"", defaultPollWait, true},
+		{"25s", 25 * time.Second, true},
+		{"10", 10 * time.Second, true},
+		{"0s", 0, true},
+		{"55s", 55 * time.Second, true},
+		{"2m", 0, false},
+		{"-1s", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tc := range cases {
+		got, ok := parsePollWait(tc.raw)
+		if ok != tc.ok || got != tc.want {
+			t.Errorf("parsePollWait(%q) = %v, %v; want %v, %v", tc.raw, got, ok, tc.want, tc.ok)
+		}
+	}
+}
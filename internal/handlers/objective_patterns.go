@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+// objectiveLanguageCodes are the language keys recognised in multilingual objective objects
+var objectiveLanguageCodes = models.TranslationLanguages
+
+// objectiveLanguage describes how a language phrases "<Verb> X ItemName [for Y]" objectives
+type objectiveLanguage struct {
+	code       string
+	verbs      []string
+	connectors []string // Words introducing the recipient, e.g. "for" in "Get 3 ARC Alloy for Shani"
+}
+
+// objectiveLanguages lists the verb tables used to parse text objectives. English comes
+// first so it wins when an objective happens to match several languages.
+var objectiveLanguages = []objectiveLanguage{
+	{code: "en", verbs: []string{"get", "collect", "obtain", "gather", "find"}, connectors: []string{"for"}},
+	{code: "de", verbs: []string{"besorge", "besorgt", "sammle", "sammelt", "beschaffe", "beschafft", "finde", "findet", "bringe", "bringt", "liefere", "liefert"}, connectors: []string{"für"}},
+	{code: "fr", verbs: []string{"obtenez", "obtiens", "récupérez", "récupère", "collectez", "collecte", "rassemblez", "rassemble", "trouvez", "trouve", "apportez", "apporte"}, connectors: []string{"pour"}},
+	{code: "es", verbs: []string{"consigue", "obtén", "obten", "recoge", "reúne", "encuentra", "entrega"}, connectors: []string{"para"}},
+	{code: "it", verbs: []string{"ottieni", "raccogli", "procurati", "trova", "consegna"}, connectors: []string{"per"}},
+	{code: "pt", verbs: []string{"obtenha", "consiga", "colete", "recolha", "encontre", "entregue"}, connectors: []string{"para"}},
+	{code: "pl", verbs: []string{"zdobądź", "zbierz", "znajdź", "przynieś", "dostarcz"}, connectors: []string{"dla"}},
+	{code: "ru", verbs: []string{"достаньте", "добудьте", "соберите", "найдите", "принесите", "получите", "раздобудьте"}, connectors: []string{"для"}},
+	{code: "tr", verbs: []string{"topla", "bul", "getir", "edin"}, connectors: []string{"için"}},
+}
+
+// textObjectivePatterns match objectives like "Get 3 ARC Alloy for Shani" or
+// "Sammle 5 Stahl für Shani" - i.e. "<Verb> X ItemName" optionally followed by a recipient
+var textObjectivePatterns = compileObjectivePatterns(objectiveLanguages)
+
+func compileObjectivePatterns(languages []objectiveLanguage) []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, 0, len(languages))
+	for _, lang := range languages {
+		verbs := make([]string, len(lang.verbs))
+		for i, v := range lang.verbs {
+			verbs[i] = regexp.QuoteMeta(v)
+		}
+		connectors := make([]string, len(lang.connectors))
+		for i, c := range lang.connectors {
+			connectors[i] = regexp.QuoteMeta(c)
+		}
+		patterns = append(patterns, regexp.MustCompile(
+			`(?i)^(?:`+strings.Join(verbs, "|")+`)\s+(\d+)\s+(.+?)(?:\s+(?:`+strings.Join(connectors, "|")+`)\s+|\s*$)`,
+		))
+	}
+	return patterns
+}
+
+// objectiveTextsByPreference returns the texts of a multilingual objective, English first
+// and then the remaining languages, so non-English-only quests can still be parsed
+func objectiveTextsByPreference(objMap map[string]interface{}, languageCodes []string) []string {
+	texts := make([]string, 0, len(languageCodes))
+	if en, ok := objMap["en"].(string); ok && en != "" {
+		texts = append(texts, en)
+	}
+	for _, lang := range languageCodes {
+		if lang == "en" {
+			continue
+		}
+		if text, ok := objMap[lang].(string); ok && text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return texts
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+// PublicProfile is what other users can see of a user, limited to what they opted in to
+type PublicProfile struct {
+	ID         uint       `json:"id" example:"42"`
+	Name       string     `json:"name,omitempty" example:"Raider42"` // Leaderboard name, only for users on the leaderboards
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`            // Only for users who made it public
+}
+
+type ProfileHandler struct {
+	userRepo *repository.UserRepository
+}
+
+func NewProfileHandler(userRepo *repository.UserRepository) *ProfileHandler {
+	return &ProfileHandler{userRepo: userRepo}
+}
+
+// Get returns a user's public profile
+// @Summary Get a public user profile
+// @Description Fetch what a user shares publicly: their leaderboard name if they opted in to the leaderboards, and when they were last active if they set last_seen_public on /me/privacy. Users who share neither have no public profile.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} PublicProfile "Public profile"
+// @Failure 400 {object} ErrorResponse "Invalid user ID"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "User not found or profile private"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /users/{id}/profile [get]
+func (h *ProfileHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	user, err := h.userRepo.FindByID(uint(id))
+	if err != nil || (!user.LeaderboardOptIn && !user.LastSeenPublic) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, publicProfile(user))
+}
+
+// publicProfile keeps only the fields user opted in to sharing
+func publicProfile(user *models.User) PublicProfile {
+	profile := PublicProfile{ID: user.ID}
+	if user.LeaderboardOptIn {
+		profile.Name = user.LeaderboardName
+		if profile.Name == "" {
+			profile.Name = user.Username
+		}
+	}
+	if user.LastSeenPublic {
+		profile.LastSeenAt = user.LastSeenAt
+	}
+	return profile
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestPublicProfile(t *testing.T) {
+	seen := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	user := &models.User{ID: 7, Username: "raider", Email: "raider@example.com", LastSeenAt: &seen}
+
+	if p := publicProfile(user); p.Name != "" || p.LastSeenAt != nil {
+		t.Errorf("expected nothing shared without opting in, got %+v", p)
+	}
+
+	user.LeaderboardOptIn = true
+	if p := publicProfile(user); p.Name != "raider" || p.LastSeenAt != nil {
+		t.Errorf("expected only the username on the leaderboards, got %+v", p)
+	}
+
+	user.LeaderboardName = "Raider Seven"
+	user.LastSeenPublic = true
+	if p := publicProfile(user); p.Name != "Raider Seven" || p.LastSeenAt == nil || !p.LastSeenAt.Equal(seen) {
+		t.Errorf("expected the leaderboard name and last seen, got %+v", p)
+	}
+}
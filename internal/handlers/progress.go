@@ -2,12 +2,15 @@ package handlers
 
 import (
 	"log"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mat/arcapi/internal/models"
 	"github.com/mat/arcapi/internal/repository"
+	"github.com/mat/arcapi/internal/services"
 )
 
 type ProgressHandler struct {
@@ -15,9 +18,12 @@ type ProgressHandler struct {
 	hideoutModuleProgressRepo *repository.UserHideoutModuleProgressRepository
 	skillNodeProgressRepo     *repository.UserSkillNodeProgressRepository
 	blueprintProgressRepo     *repository.UserBlueprintProgressRepository
+	traderProgressRepo        *repository.UserTraderProgressRepository
+	userProgressRepo          *repository.UserProgressRepository
 	questRepo                 *repository.QuestRepository
 	hideoutModuleRepo         *repository.HideoutModuleRepository
 	skillNodeRepo             *repository.SkillNodeRepository
+	traderRepo                *repository.TraderRepository
 	itemRepo                  *repository.ItemRepository
 	userRepo                  *repository.UserRepository
 }
@@ -27,9 +33,12 @@ func NewProgressHandler(
 	hideoutModuleProgressRepo *repository.UserHideoutModuleProgressRepository,
 	skillNodeProgressRepo *repository.UserSkillNodeProgressRepository,
 	blueprintProgressRepo *repository.UserBlueprintProgressRepository,
+	traderProgressRepo *repository.UserTraderProgressRepository,
+	userProgressRepo *repository.UserProgressRepository,
 	questRepo *repository.QuestRepository,
 	hideoutModuleRepo *repository.HideoutModuleRepository,
 	skillNodeRepo *repository.SkillNodeRepository,
+	traderRepo *repository.TraderRepository,
 	itemRepo *repository.ItemRepository,
 	userRepo *repository.UserRepository,
 ) *ProgressHandler {
@@ -38,9 +47,12 @@ func NewProgressHandler(
 		hideoutModuleProgressRepo: hideoutModuleProgressRepo,
 		skillNodeProgressRepo:     skillNodeProgressRepo,
 		blueprintProgressRepo:     blueprintProgressRepo,
+		traderProgressRepo:        traderProgressRepo,
+		userProgressRepo:          userProgressRepo,
 		questRepo:                 questRepo,
 		hideoutModuleRepo:         hideoutModuleRepo,
 		skillNodeRepo:             skillNodeRepo,
+		traderRepo:                traderRepo,
 		itemRepo:                  itemRepo,
 		userRepo:                  userRepo,
 	}
@@ -53,6 +65,7 @@ func NewProgressHandler(
 // @Tags progress
 // @Accept json
 // @Produce json
+// @Param include query string false "Set to \"entity\" to embed full entity rows instead of just external IDs"
 // @Success 200 {object} map[string][]models.UserQuestProgress "Successfully fetched quest progress"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
 // @Failure 500 {object} ErrorResponse "Internal server error"
@@ -66,7 +79,7 @@ func (h *ProgressHandler) GetMyQuestProgress(c *gin.Context) {
 	}
 	userModel := user.(*models.User)
 
-	progress, err := h.questProgressRepo.FindByUserID(userModel.ID)
+	progress, err := h.questProgressRepo.FindByUserID(userModel.ID, includeEntity(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quest progress"})
 		return
@@ -75,6 +88,72 @@ func (h *ProgressHandler) GetMyQuestProgress(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": progress})
 }
 
+// GetAvailableQuests returns the quests the current user can start next
+// @Summary Get my available quests
+// @Description Intersect the quest prerequisite graph with the authenticated user's completed quests and return the quests that are not completed and whose prerequisites are all done. Quests that require a higher trader level than the user has recorded via /progress/traders are left out.
+// @Tags progress
+// @Accept json
+// @Produce json
+// @Param group_by query string false "Group results by \"trader\" or \"map\""
+// @Success 200 {object} map[string]interface{} "Available quests, as a list or grouped object"
+// @Failure 400 {object} ErrorResponse "Invalid group_by"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /progress/quests/available [get]
+func (h *ProgressHandler) GetAvailableQuests(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	userModel := user.(*models.User)
+
+	groupBy := c.Query("group_by")
+	if groupBy != "" && groupBy != "trader" && groupBy != "map" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "group_by must be trader or map"})
+		return
+	}
+
+	progress, err := h.questProgressRepo.FindByUserID(userModel.ID, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quest progress"})
+		return
+	}
+	completed := make(map[string]bool, len(progress))
+	for _, p := range progress {
+		if p.Completed {
+			completed[p.QuestExternalID] = true
+		}
+	}
+
+	traderProgress, err := h.traderProgressRepo.FindByUserID(userModel.ID, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trader progress"})
+		return
+	}
+	// Quest data names traders inconsistently, so index levels by both name and external ID
+	traderLevels := make(map[string]int, len(traderProgress)*2)
+	for _, p := range traderProgress {
+		traderLevels[services.TraderKey(p.TraderExternalID)] = p.Level
+		traderLevels[services.TraderKey(p.TraderName)] = p.Level
+	}
+
+	quests, err := h.questRepo.ListAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quests"})
+		return
+	}
+
+	available := availableQuests(quests, completed, traderLevels)
+	if groupBy != "" {
+		c.JSON(http.StatusOK, gin.H{"data": groupQuests(available, groupBy), "total": len(available)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": available, "total": len(available)})
+}
+
 // UpdateQuestProgress updates quest completion status for the current user
 // Accepts external_id (e.g., "ss1") instead of internal database ID
 // UpdateQuestProgress updates quest completion status for the current user
@@ -138,6 +217,7 @@ func (h *ProgressHandler) UpdateQuestProgress(c *gin.Context) {
 // @Tags progress
 // @Accept json
 // @Produce json
+// @Param include query string false "Set to \"entity\" to embed full entity rows instead of just external IDs"
 // @Success 200 {object} map[string][]models.UserHideoutModuleProgress "Successfully fetched hideout module progress"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
 // @Failure 500 {object} ErrorResponse "Internal server error"
@@ -151,7 +231,7 @@ func (h *ProgressHandler) GetMyHideoutModuleProgress(c *gin.Context) {
 	}
 	userModel := user.(*models.User)
 
-	progress, err := h.hideoutModuleProgressRepo.FindByUserID(userModel.ID)
+	progress, err := h.hideoutModuleProgressRepo.FindByUserID(userModel.ID, includeEntity(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hideout module progress"})
 		return
@@ -224,6 +304,7 @@ func (h *ProgressHandler) UpdateHideoutModuleProgress(c *gin.Context) {
 // @Tags progress
 // @Accept json
 // @Produce json
+// @Param include query string false "Set to \"entity\" to embed full entity rows instead of just external IDs"
 // @Success 200 {object} map[string][]models.UserSkillNodeProgress "Successfully fetched skill node progress"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
 // @Failure 500 {object} ErrorResponse "Internal server error"
@@ -237,7 +318,7 @@ func (h *ProgressHandler) GetMySkillNodeProgress(c *gin.Context) {
 	}
 	userModel := user.(*models.User)
 
-	progress, err := h.skillNodeProgressRepo.FindByUserID(userModel.ID)
+	progress, err := h.skillNodeProgressRepo.FindByUserID(userModel.ID, includeEntity(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch skill node progress"})
 		return
@@ -310,6 +391,7 @@ func (h *ProgressHandler) UpdateSkillNodeProgress(c *gin.Context) {
 // @Tags progress
 // @Accept json
 // @Produce json
+// @Param include query string false "Set to \"entity\" to embed full entity rows instead of just external IDs"
 // @Success 200 {object} map[string][]models.UserBlueprintProgress "Successfully fetched blueprint progress"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
 // @Failure 500 {object} ErrorResponse "Internal server error"
@@ -323,7 +405,7 @@ func (h *ProgressHandler) GetMyBlueprintProgress(c *gin.Context) {
 	}
 	userModel := user.(*models.User)
 
-	progress, err := h.blueprintProgressRepo.FindByUserID(userModel.ID)
+	progress, err := h.blueprintProgressRepo.FindByUserID(userModel.ID, includeEntity(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch blueprint progress"})
 		return
@@ -388,6 +470,89 @@ func (h *ProgressHandler) UpdateBlueprintProgress(c *gin.Context) {
 	c.JSON(http.StatusOK, progress)
 }
 
+// GetMyTraderProgress returns all trader progress for the current user
+// @Summary Get my trader progress
+// @Description Fetch the authenticated user's level and reputation with each trader.
+// @Tags progress
+// @Accept json
+// @Produce json
+// @Param include query string false "Set to \"entity\" to embed full entity rows instead of just external IDs"
+// @Success 200 {object} map[string][]models.UserTraderProgress "Successfully fetched trader progress"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /progress/traders [get]
+func (h *ProgressHandler) GetMyTraderProgress(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	userModel := user.(*models.User)
+
+	progress, err := h.traderProgressRepo.FindByUserID(userModel.ID, includeEntity(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trader progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": progress})
+}
+
+// UpdateTraderProgress updates the current user's level and reputation with a trader
+// @Summary Update my trader progress
+// @Description Set the level and reputation with a specific trader using its external ID. Trader levels gate which quests are returned by /progress/quests/available.
+// @Tags progress
+// @Accept json
+// @Produce json
+// @Param trader_id path string true "Trader External ID"
+// @Param progress body map[string]int true "Progress data (level, reputation)"
+// @Success 200 {object} models.UserTraderProgress "Successfully updated trader progress"
+// @Failure 400 {object} ErrorResponse "Invalid input or ID"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Trader not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /progress/traders/{trader_id} [put]
+func (h *ProgressHandler) UpdateTraderProgress(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	userModel := user.(*models.User)
+
+	traderExternalID := c.Param("trader_id")
+	if traderExternalID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Trader external_id is required"})
+		return
+	}
+
+	trader, err := h.traderRepo.FindByExternalID(traderExternalID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Trader not found"})
+		return
+	}
+
+	var req struct {
+		Level      int `json:"level" binding:"required,min=1"`
+		Reputation int `json:"reputation" binding:"min=0"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	progress, err := h.traderProgressRepo.Upsert(userModel.ID, trader.ID, req.Level, req.Reputation)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update trader progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
 // ========================================
 // ADMIN ENDPOINTS - View/Manage All Users
 // ========================================
@@ -400,6 +565,7 @@ func (h *ProgressHandler) UpdateBlueprintProgress(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
+// @Param include query string false "Set to \"entity\" to embed full entity rows instead of just external IDs"
 // @Success 200 {object} map[string]interface{} "Successfully fetched quest progress"
 // @Failure 400 {object} ErrorResponse "Invalid user ID"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
@@ -423,7 +589,7 @@ c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 return
 }
 
-progress, err := h.questProgressRepo.FindByUserID(userID)
+progress, err := h.questProgressRepo.FindByUserID(userID, includeEntity(c))
 if err != nil {
 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quest progress"})
 return
@@ -504,6 +670,7 @@ c.JSON(http.StatusOK, progress)
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
+// @Param include query string false "Set to \"entity\" to embed full entity rows instead of just external IDs"
 // @Success 200 {object} map[string]interface{} "Successfully fetched hideout module progress"
 // @Failure 400 {object} ErrorResponse "Invalid user ID"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
@@ -527,7 +694,7 @@ c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 return
 }
 
-progress, err := h.hideoutModuleProgressRepo.FindByUserID(userID)
+progress, err := h.hideoutModuleProgressRepo.FindByUserID(userID, includeEntity(c))
 if err != nil {
 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hideout module progress"})
 return
@@ -609,6 +776,7 @@ c.JSON(http.StatusOK, progress)
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
+// @Param include query string false "Set to \"entity\" to embed full entity rows instead of just external IDs"
 // @Success 200 {object} map[string]interface{} "Successfully fetched skill node progress"
 // @Failure 400 {object} ErrorResponse "Invalid user ID"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
@@ -632,7 +800,7 @@ c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 return
 }
 
-progress, err := h.skillNodeProgressRepo.FindByUserID(userID)
+progress, err := h.skillNodeProgressRepo.FindByUserID(userID, includeEntity(c))
 if err != nil {
 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch skill node progress"})
 return
@@ -714,6 +882,7 @@ c.JSON(http.StatusOK, progress)
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
+// @Param include query string false "Set to \"entity\" to embed full entity rows instead of just external IDs"
 // @Success 200 {object} map[string]interface{} "Successfully fetched blueprint progress"
 // @Failure 400 {object} ErrorResponse "Invalid user ID"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
@@ -737,7 +906,7 @@ c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 return
 }
 
-progress, err := h.blueprintProgressRepo.FindByUserID(userID)
+progress, err := h.blueprintProgressRepo.FindByUserID(userID, includeEntity(c))
 if err != nil {
 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch blueprint progress"})
 return
@@ -818,6 +987,7 @@ c.JSON(http.StatusOK, progress)
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
+// @Param include query string false "Set to \"entity\" to embed full entity rows instead of just external IDs"
 // @Success 200 {object} map[string]interface{} "Successfully fetched comprehensive progress"
 // @Failure 400 {object} ErrorResponse "Invalid user ID"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
@@ -842,6 +1012,8 @@ c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 return
 }
 
+withEntity := includeEntity(c)
+
 // Fetch all progress types in parallel
 type result struct {
 quests         []models.UserQuestProgress
@@ -864,22 +1036,22 @@ resultChan <- result{err: http.ErrAbortHandler}
 var r result
 var err error
 
-r.quests, err = h.questProgressRepo.FindByUserID(userID)
+r.quests, err = h.questProgressRepo.FindByUserID(userID, withEntity)
 if err != nil {
 log.Printf("Warning: Failed to fetch quest progress for user %d: %v", userID, err)
 }
 
-r.hideoutModules, err = h.hideoutModuleProgressRepo.FindByUserID(userID)
+r.hideoutModules, err = h.hideoutModuleProgressRepo.FindByUserID(userID, withEntity)
 if err != nil {
 log.Printf("Warning: Failed to fetch hideout module progress for user %d: %v", userID, err)
 }
 
-r.skillNodes, err = h.skillNodeProgressRepo.FindByUserID(userID)
+r.skillNodes, err = h.skillNodeProgressRepo.FindByUserID(userID, withEntity)
 if err != nil {
 log.Printf("Warning: Failed to fetch skill node progress for user %d: %v", userID, err)
 }
 
-r.blueprints, err = h.blueprintProgressRepo.FindByUserID(userID)
+r.blueprints, err = h.blueprintProgressRepo.FindByUserID(userID, withEntity)
 if err != nil {
 log.Printf("Warning: Failed to fetch blueprint progress for user %d: %v", userID, err)
 }
@@ -910,6 +1082,442 @@ c.JSON(http.StatusOK, gin.H{
 })
 }
 
+// CompletionStat is progress within one category as done out of total
+type CompletionStat struct {
+	Done    int64   `json:"done"`
+	Total   int64   `json:"total"`
+	Percent float64 `json:"percent"`
+}
+
+// newCompletionStat clamps done to total. Progress rows can outlive entities removed
+// upstream, e.g. a consumed blueprint whose item no longer is one.
+func newCompletionStat(done, total int64) CompletionStat {
+	if done > total {
+		done = total
+	}
+	stat := CompletionStat{Done: done, Total: total}
+	if total > 0 {
+		stat.Percent = math.Round(float64(done)/float64(total)*1000) / 10
+	}
+	return stat
+}
+
+// ProgressCompletionSummary reports completion per progress category
+type ProgressCompletionSummary struct {
+	// Quests counts completed quests out of all quests
+	Quests CompletionStat `json:"quests"`
+	// HideoutLevels counts hideout levels reached out of the sum of every module's max level
+	HideoutLevels CompletionStat `json:"hideout_levels"`
+	// SkillPoints counts skill points spent out of the sum of every node's max points
+	SkillPoints CompletionStat `json:"skill_points"`
+	// Blueprints counts consumed blueprints out of all blueprint items
+	Blueprints CompletionStat `json:"blueprints"`
+}
+
+// GetMyProgressSummary returns completion percentages per progress category
+// @Summary Get my progress summary
+// @Description Compute completion per category server-side: quests completed, hideout levels reached, skill points spent and blueprints consumed, each with its total and percentage.
+// @Tags progress
+// @Accept json
+// @Produce json
+// @Success 200 {object} ProgressCompletionSummary "Successfully computed progress summary"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /progress/summary [get]
+func (h *ProgressHandler) GetMyProgressSummary(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	userModel := user.(*models.User)
+
+	var summary ProgressCompletionSummary
+
+	questsDone, err := h.questProgressRepo.CountCompleted(userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quest progress"})
+		return
+	}
+	questsTotal, err := h.questRepo.Count()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quests"})
+		return
+	}
+	summary.Quests = newCompletionStat(questsDone, questsTotal)
+
+	moduleProgress, err := h.hideoutModuleProgressRepo.FindByUserID(userModel.ID, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hideout module progress"})
+		return
+	}
+	modules, err := h.hideoutModuleRepo.ListAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hideout modules"})
+		return
+	}
+	var levelsReached, levelsMax int64
+	for _, mp := range moduleProgress {
+		if mp.Unlocked {
+			levelsReached += int64(mp.Level)
+		}
+	}
+	for _, m := range modules {
+		levelsMax += int64(hideoutModuleMaxLevel(m))
+	}
+	summary.HideoutLevels = newCompletionStat(levelsReached, levelsMax)
+
+	skillProgress, err := h.skillNodeProgressRepo.FindByUserID(userModel.ID, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch skill node progress"})
+		return
+	}
+	nodes, err := h.skillNodeRepo.ListAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch skill nodes"})
+		return
+	}
+	var pointsSpent, pointsMax int64
+	for _, sp := range skillProgress {
+		if sp.Unlocked {
+			pointsSpent += int64(sp.Level)
+		}
+	}
+	for _, n := range nodes {
+		pointsMax += int64(n.MaxPoints)
+	}
+	summary.SkillPoints = newCompletionStat(pointsSpent, pointsMax)
+
+	blueprintsConsumed, err := h.blueprintProgressRepo.CountConsumed(userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch blueprint progress"})
+		return
+	}
+	items, err := h.itemRepo.ListAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch items"})
+		return
+	}
+	var blueprintsTotal int64
+	for _, item := range items {
+		if isBlueprintItem(item) {
+			blueprintsTotal++
+		}
+	}
+	summary.Blueprints = newCompletionStat(blueprintsConsumed, blueprintsTotal)
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// hideoutModuleMaxLevel falls back to the number of level entries when max_level is unset
+func hideoutModuleMaxLevel(m models.HideoutModule) int {
+	if m.MaxLevel > 0 {
+		return m.MaxLevel
+	}
+	if levels, ok := m.Levels["levels"].([]interface{}); ok {
+		return len(levels)
+	}
+	return 0
+}
+
+// BulkSetUserProgress replaces a user's progress in one transaction (admin only)
+// @Summary Bulk set user progress
+// @Description Overwrite a user's progress from a payload in the same format as GET /admin/users/{id}/progress. Entities are matched by external ID (falling back to internal ID). Omitted progress types are left untouched; an empty list clears that type.
+// @Tags management
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param progress body map[string]interface{} true "Progress payload ({\"progress\": {\"quests\": [...], \"hideout_modules\": [...], \"skill_nodes\": [...], \"blueprints\": [...]}})"
+// @Success 200 {object} map[string]interface{} "Successfully replaced progress"
+// @Failure 400 {object} ErrorResponse "Invalid payload, unknown entities or entities listed more than once"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Not an administrator"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/users/{id}/progress/bulk [post]
+func (h *ProgressHandler) BulkSetUserProgress(c *gin.Context) {
+	userID, err := parseUint(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if _, err := h.userRepo.FindByID(userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var req struct {
+		Progress bulkProgressPayload `json:"progress" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p := req.Progress
+
+	// Only the entity types the payload sets are looked up
+	var questIDs, moduleIDs, nodeIDs, itemIDs *externalIDIndex
+	if p.Quests != nil {
+		quests, err := h.questRepo.ListAll()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quests"})
+			return
+		}
+		questIDs = newExternalIDIndex(len(quests))
+		for _, q := range quests {
+			questIDs.add(q.ID, q.ExternalID)
+		}
+	}
+	if p.HideoutModules != nil {
+		modules, err := h.hideoutModuleRepo.ListAll()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hideout modules"})
+			return
+		}
+		moduleIDs = newExternalIDIndex(len(modules))
+		for _, m := range modules {
+			moduleIDs.add(m.ID, m.ExternalID)
+		}
+	}
+	if p.SkillNodes != nil {
+		nodes, err := h.skillNodeRepo.ListAll()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch skill nodes"})
+			return
+		}
+		nodeIDs = newExternalIDIndex(len(nodes))
+		for _, n := range nodes {
+			nodeIDs.add(n.ID, n.ExternalID)
+		}
+	}
+	if p.Blueprints != nil {
+		items, err := h.itemRepo.ListAll()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch items"})
+			return
+		}
+		itemIDs = newExternalIDIndex(len(items))
+		for _, item := range items {
+			itemIDs.add(item.ID, item.ExternalID)
+		}
+	}
+
+	unknown, duplicates := p.resolve(questIDs, moduleIDs, nodeIDs, itemIDs)
+	if len(unknown) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Payload references unknown entities", "unknown": unknown})
+		return
+	}
+	if len(duplicates) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Payload lists entities more than once", "duplicates": duplicates})
+		return
+	}
+
+	if err := h.userProgressRepo.ReplaceAll(userID, p.Quests, p.HideoutModules, p.SkillNodes, p.Blueprints); err != nil {
+		log.Printf("Failed to bulk set progress for user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Progress updated",
+		"counts": gin.H{
+			"quests":          countIfSet(len(p.Quests), p.Quests != nil),
+			"hideout_modules": countIfSet(len(p.HideoutModules), p.HideoutModules != nil),
+			"skill_nodes":     countIfSet(len(p.SkillNodes), p.SkillNodes != nil),
+			"blueprints":      countIfSet(len(p.Blueprints), p.Blueprints != nil),
+		},
+	})
+}
+
+// CopyUserProgress copies progress from one user to another (admin only)
+// @Summary Copy progress between users
+// @Description Copy selected progress categories from one user to another in one transaction, e.g. when a player switches accounts. mode controls conflicts with the target's existing rows: overwrite clears the target's category first, merge lets source rows win, skip keeps the target's rows.
+// @Tags management
+// @Accept json
+// @Produce json
+// @Param from query int true "Source user ID"
+// @Param to query int true "Target user ID"
+// @Param categories query string false "Comma-separated categories: quests, hideout_modules, skill_nodes, blueprints (default: all)"
+// @Param mode query string false "Conflict mode: overwrite, merge or skip" default(skip)
+// @Success 200 {object} map[string]interface{} "Rows copied per category"
+// @Failure 400 {object} ErrorResponse "Invalid parameters"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Not an administrator"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/progress/copy [post]
+func (h *ProgressHandler) CopyUserProgress(c *gin.Context) {
+	fromID, err := parseUint(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid source user ID"})
+		return
+	}
+	toID, err := parseUint(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target user ID"})
+		return
+	}
+	if fromID == toID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Source and target users must differ"})
+		return
+	}
+
+	mode := c.DefaultQuery("mode", repository.CopyModeSkip)
+	if mode != repository.CopyModeOverwrite && mode != repository.CopyModeMerge && mode != repository.CopyModeSkip {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be one of overwrite, merge, skip"})
+		return
+	}
+
+	categories := repository.ProgressCategories
+	if raw := c.Query("categories"); raw != "" {
+		categories = nil
+		seen := make(map[string]bool)
+		for _, part := range strings.Split(raw, ",") {
+			category := strings.TrimSpace(part)
+			if category == "" || seen[category] {
+				continue
+			}
+			if !isProgressCategory(category) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown category: " + category})
+				return
+			}
+			seen[category] = true
+			categories = append(categories, category)
+		}
+	}
+
+	if _, err := h.userRepo.FindByID(fromID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Source user not found"})
+		return
+	}
+	if _, err := h.userRepo.FindByID(toID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Target user not found"})
+		return
+	}
+
+	copied, err := h.userProgressRepo.CopyProgress(fromID, toID, categories, mode)
+	if err != nil {
+		log.Printf("Failed to copy progress from user %d to %d: %v", fromID, toID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to copy progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":   fromID,
+		"to":     toID,
+		"mode":   mode,
+		"copied": copied,
+	})
+}
+
+func isProgressCategory(category string) bool {
+	for _, known := range repository.ProgressCategories {
+		if category == known {
+			return true
+		}
+	}
+	return false
+}
+
+// externalIDIndex resolves progress payload entity references to internal IDs
+// bulkProgressPayload is the progress of a BulkSetUserProgress request. A nil list leaves
+// that progress type untouched.
+type bulkProgressPayload struct {
+	Quests         []models.UserQuestProgress         `json:"quests"`
+	HideoutModules []models.UserHideoutModuleProgress `json:"hideout_modules"`
+	SkillNodes     []models.UserSkillNodeProgress     `json:"skill_nodes"`
+	Blueprints     []models.UserBlueprintProgress     `json:"blueprints"`
+}
+
+// resolve sets the internal entity ID of every row from the indexes of the types the
+// payload sets. It returns the references that match no entity, and those that resolve to
+// an entity an earlier row of the same type already holds, since a user has one row per
+// entity.
+func (p *bulkProgressPayload) resolve(quests, modules, nodes, items *externalIDIndex) (unknown, duplicates []string) {
+	check := func(kind string, ids *externalIDIndex, seen map[uint]bool, externalID string, id uint) uint {
+		resolved, ok := ids.resolve(externalID, id)
+		if !ok {
+			unknown = append(unknown, kind+":"+describeEntityRef(externalID, id))
+		} else if seen[resolved] {
+			duplicates = append(duplicates, kind+":"+describeEntityRef(externalID, id))
+		}
+		seen[resolved] = true
+		return resolved
+	}
+
+	seen := make(map[uint]bool)
+	for i := range p.Quests {
+		p.Quests[i].QuestID = check("quest", quests, seen, p.Quests[i].QuestExternalID, p.Quests[i].QuestID)
+	}
+	seen = make(map[uint]bool)
+	for i := range p.HideoutModules {
+		p.HideoutModules[i].HideoutModuleID = check("hideout_module", modules, seen, p.HideoutModules[i].HideoutModuleExternalID, p.HideoutModules[i].HideoutModuleID)
+	}
+	seen = make(map[uint]bool)
+	for i := range p.SkillNodes {
+		p.SkillNodes[i].SkillNodeID = check("skill_node", nodes, seen, p.SkillNodes[i].SkillNodeExternalID, p.SkillNodes[i].SkillNodeID)
+	}
+	seen = make(map[uint]bool)
+	for i := range p.Blueprints {
+		p.Blueprints[i].ItemID = check("blueprint", items, seen, p.Blueprints[i].ItemExternalID, p.Blueprints[i].ItemID)
+	}
+	return unknown, duplicates
+}
+
+type externalIDIndex struct {
+	byExternalID map[string]uint
+	ids          map[uint]bool
+}
+
+func newExternalIDIndex(size int) *externalIDIndex {
+	return &externalIDIndex{byExternalID: make(map[string]uint, size), ids: make(map[uint]bool, size)}
+}
+
+func (x *externalIDIndex) add(id uint, externalID string) {
+	x.byExternalID[externalID] = id
+	x.ids[id] = true
+}
+
+// resolve prefers the external ID, since internal IDs differ between environments
+func (x *externalIDIndex) resolve(externalID string, id uint) (uint, bool) {
+	if externalID != "" {
+		resolved, ok := x.byExternalID[externalID]
+		return resolved, ok
+	}
+	return id, id != 0 && x.ids[id]
+}
+
+func describeEntityRef(externalID string, id uint) string {
+	if externalID != "" {
+		return externalID
+	}
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// countIfSet returns nil for progress types omitted from a bulk payload
+func countIfSet(n int, set bool) interface{} {
+	if !set {
+		return nil
+	}
+	return n
+}
+
+// includeEntity reports whether the caller asked for full entity rows via ?include=entity
+func includeEntity(c *gin.Context) bool {
+	for _, part := range strings.Split(c.Query("include"), ",") {
+		if strings.TrimSpace(part) == "entity" {
+			return true
+		}
+	}
+	return false
+}
+
 // Helper function to parse uint from string
 func parseUint(s string) (uint, error) {
 id, err := strconv.ParseUint(s, 10, 32)
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/logging"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/services"
+)
+
+var snapshotLog = logging.For("progress_snapshots")
+
+type ProgressSnapshotHandler struct {
+	snapshotService *services.ProgressSnapshotService
+}
+
+func NewProgressSnapshotHandler(snapshotService *services.ProgressSnapshotService) *ProgressSnapshotHandler {
+	return &ProgressSnapshotHandler{snapshotService: snapshotService}
+}
+
+// List returns the current user's progress snapshots
+// @Summary List my progress snapshots
+// @Description Snapshots of the authenticated user's quest, hideout module, skill node and blueprint progress, newest first. One is taken every day and kept for the retention period, and one just before each restore (reason pre_restore), so a restore can be undone.
+// @Tags progress
+// @Produce json
+// @Success 200 {object} map[string][]models.ProgressSnapshot "Successfully fetched snapshots"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /me/snapshots [get]
+func (h *ProgressSnapshotHandler) List(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	snapshots, err := h.snapshotService.List(user.ID)
+	if err != nil {
+		snapshotLog.Error("Failed to list progress snapshots", "user_id", user.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch snapshots"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": snapshots})
+}
+
+// Restore overwrites the current user's progress with one of their snapshots
+// @Summary Restore a progress snapshot
+// @Description Replace the authenticated user's quest, hideout module, skill node and blueprint progress with a snapshot. The progress being replaced is snapshotted first and its ID returned as backup_id. Rows for entities that have since been removed are skipped.
+// @Tags progress
+// @Produce json
+// @Param id path int true "Snapshot ID"
+// @Success 200 {object} services.ProgressSnapshotRestore "Progress restored"
+// @Failure 400 {object} ErrorResponse "Invalid snapshot ID"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Snapshot not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /me/snapshots/{id}/restore [post]
+func (h *ProgressSnapshotHandler) Restore(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	// Not parseUint: snapshots kept on disk have millisecond timestamps as IDs
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid snapshot ID"})
+		return
+	}
+
+	restored, err := h.snapshotService.Restore(user.ID, uint(id), time.Now())
+	if errors.Is(err, services.ErrProgressSnapshotNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Snapshot not found"})
+		return
+	}
+	if err != nil {
+		snapshotLog.Error("Failed to restore progress snapshot", "snapshot_id", id, "user_id", user.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore snapshot"})
+		return
+	}
+
+	c.JSON(http.StatusOK, restored)
+}
+
+// ListUserSnapshots returns a user's progress snapshots (admin only)
+// @Summary List a user's progress snapshots
+// @Description A user's progress snapshots, newest first, to pick the snapshots to diff or to look up what a user restored.
+// @Tags management
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string][]models.ProgressSnapshot "Successfully fetched snapshots"
+// @Failure 400 {object} ErrorResponse "Invalid user ID"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/users/{id}/snapshots [get]
+func (h *ProgressSnapshotHandler) ListUserSnapshots(c *gin.Context) {
+	userID, err := parseUint(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	snapshots, err := h.snapshotService.List(userID)
+	if err != nil {
+		snapshotLog.Error("Failed to list progress snapshots", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch snapshots"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": snapshots})
+}
+
+// Diff compares a user's progress between two snapshots (admin only)
+// @Summary Diff a user's progress
+// @Description List the quest, hideout module, skill node and blueprint progress rows added, removed and changed between two of a user's snapshots, or between a snapshot and their current progress, with counts per category. Used to investigate reports of lost progress; snapshot IDs come from the user's snapshot list.
+// @Tags management
+// @Produce json
+// @Param id path int true "User ID"
+// @Param from query int true "Snapshot ID to compare from"
+// @Param to query string false "Snapshot ID to compare to, or now for the current progress" default(now)
+// @Success 200 {object} services.ProgressDiff "Progress diff"
+// @Failure 400 {object} ErrorResponse "Invalid parameters"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 404 {object} ErrorResponse "Snapshot not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/users/{id}/progress/diff [get]
+func (h *ProgressSnapshotHandler) Diff(c *gin.Context) {
+	userID, err := parseUint(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	fromID, err := strconv.ParseUint(c.Query("from"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a snapshot ID"})
+		return
+	}
+	var toID uint64
+	if to := c.DefaultQuery("to", "now"); to != "now" {
+		if toID, err = strconv.ParseUint(to, 10, 64); err != nil || toID == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a snapshot ID or now"})
+			return
+		}
+	}
+
+	diff, err := h.snapshotService.Diff(userID, uint(fromID), uint(toID), time.Now())
+	if errors.Is(err, services.ErrProgressSnapshotNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Snapshot not found"})
+		return
+	}
+	if err != nil {
+		snapshotLog.Error("Failed to diff progress", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to diff progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
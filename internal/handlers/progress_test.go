@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestBulkProgressPayloadResolve(t *testing.T) {
+	quests := newExternalIDIndex(2)
+	quests.add(1, "first_steps")
+	quests.add(2, "supply_run")
+	items := newExternalIDIndex(1)
+	items.add(9, "anvil_blueprint")
+
+	p := bulkProgressPayload{
+		Quests: []models.UserQuestProgress{
+			{QuestExternalID: "first_steps", Completed: true},
+			{QuestID: 2},
+			{QuestExternalID: "missing"},
+		},
+		Blueprints: []models.UserBlueprintProgress{{ItemExternalID: "anvil_blueprint"}},
+	}
+	unknown, duplicates := p.resolve(quests, nil, nil, items)
+	if !reflect.DeepEqual(unknown, []string{"quest:missing"}) || len(duplicates) != 0 {
+		t.Fatalf("resolve = %v, %v; want one unknown quest", unknown, duplicates)
+	}
+	if p.Quests[0].QuestID != 1 || p.Quests[1].QuestID != 2 || p.Blueprints[0].ItemID != 9 {
+		t.Errorf("expected internal IDs to be set, got %+v %+v", p.Quests, p.Blueprints)
+	}
+}
+
+func TestBulkProgressPayloadResolveReportsDuplicates(t *testing.T) {
+	quests := newExternalIDIndex(1)
+	quests.add(1, "first_steps")
+
+	// The same quest by external and by internal ID would break the one row per quest
+	p := bulkProgressPayload{Quests: []models.UserQuestProgress{
+		{QuestExternalID: "first_steps"},
+		{QuestID: 1},
+	}}
+	unknown, duplicates := p.resolve(quests, nil, nil, nil)
+	if len(unknown) != 0 || !reflect.DeepEqual(duplicates, []string{"quest:1"}) {
+		t.Errorf("resolve = %v, %v; want quest:1 reported as a duplicate", unknown, duplicates)
+	}
+}
+
+func TestNewCompletionStat(t *testing.T) {
+	for _, tc := range []struct {
+		done, total int64
+		want        CompletionStat
+	}{
+		{1, 3, CompletionStat{Done: 1, Total: 3, Percent: 33.3}},
+		{0, 0, CompletionStat{}},
+		{5, 4, CompletionStat{Done: 4, Total: 4, Percent: 100}},
+		{2, 0, CompletionStat{}},
+	} {
+		if got := newCompletionStat(tc.done, tc.total); got != tc.want {
+			t.Errorf("newCompletionStat(%d, %d) = %+v, want %+v", tc.done, tc.total, got, tc.want)
+		}
+	}
+}
+
+func TestCopyUserProgressValidatesParameters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	// Invalid requests are rejected before any repository is used
+	r.POST("/admin/progress/copy", (&ProgressHandler{}).CopyUserProgress)
+
+	for _, query := range []string{
+		"to=2",
+		"from=1&to=x",
+		"from=1&to=1",
+		"from=1&to=2&mode=replace",
+		"from=1&to=2&categories=quests,achievements",
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/progress/copy?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, w.Code)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"sort"
+
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/services"
+)
+
+// unspecifiedGroup collects quests without a trader or map when grouping
+const unspecifiedGroup = "unspecified"
+
+// stringList reads a string or array-of-strings field from quest data
+func stringList(data models.JSONB, field string) []string {
+	switch v := data[field].(type) {
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, entry := range v {
+			if s, ok := entry.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// questPrerequisites maps each quest's external ID to the quests that must be completed
+// first. The upstream data lists both previousQuestIds and nextQuestIds; either side of
+// the edge is enough.
+func questPrerequisites(quests []models.Quest) map[string]map[string]bool {
+	prereqs := make(map[string]map[string]bool, len(quests))
+	addEdge := func(quest, prerequisite string) {
+		if quest == prerequisite {
+			return
+		}
+		if prereqs[quest] == nil {
+			prereqs[quest] = make(map[string]bool)
+		}
+		prereqs[quest][prerequisite] = true
+	}
+
+	for _, q := range quests {
+		for _, prev := range stringList(q.Data, "previousQuestIds") {
+			addEdge(q.ExternalID, prev)
+		}
+		for _, next := range stringList(q.Data, "nextQuestIds") {
+			addEdge(next, q.ExternalID)
+		}
+	}
+	return prereqs
+}
+
+// questTraderLevelFields are checked in order for the trader level a quest requires
+var questTraderLevelFields = []string{"requiredTraderLevel", "traderLevel", "trader_level"}
+
+// questTraderLevel returns the trader level a quest requires, or 0 if the data sets none
+func questTraderLevel(q models.Quest) int {
+	for _, field := range questTraderLevelFields {
+		if level, ok := q.Data[field].(float64); ok {
+			return int(level)
+		}
+	}
+	return 0
+}
+
+// availableQuests returns the quests that are not completed and whose prerequisites are
+// all completed. Prerequisites that reference unknown quests are ignored, since the
+// player has no way to complete them. traderLevels maps services.TraderKey of a trader's
+// name or external ID to the user's level with them; quests for traders the user hasn't
+// recorded a level for are not gated, so users who don't track reputation see every quest.
+func availableQuests(quests []models.Quest, completed map[string]bool, traderLevels map[string]int) []models.Quest {
+	known := make(map[string]bool, len(quests))
+	for _, q := range quests {
+		known[q.ExternalID] = true
+	}
+	prereqs := questPrerequisites(quests)
+
+	available := make([]models.Quest, 0)
+	for _, q := range quests {
+		if completed[q.ExternalID] {
+			continue
+		}
+		ready := true
+		for prerequisite := range prereqs[q.ExternalID] {
+			if known[prerequisite] && !completed[prerequisite] {
+				ready = false
+				break
+			}
+		}
+		if level, tracked := traderLevels[services.TraderKey(q.Trader)]; tracked && level < questTraderLevel(q) {
+			ready = false
+		}
+		if ready {
+			available = append(available, q)
+		}
+	}
+	return available
+}
+
+// questMaps returns the maps a quest takes place on, if the data lists any
+func questMaps(q models.Quest) []string {
+	for _, field := range []string{"maps", "map", "locations"} {
+		if maps := stringList(q.Data, field); len(maps) > 0 {
+			return maps
+		}
+	}
+	return nil
+}
+
+// groupQuests groups quests by trader or map. A quest on several maps appears in each group.
+func groupQuests(quests []models.Quest, groupBy string) map[string][]models.Quest {
+	groups := make(map[string][]models.Quest)
+	for _, q := range quests {
+		var keys []string
+		switch groupBy {
+		case "trader":
+			if q.Trader != "" {
+				keys = []string{q.Trader}
+			}
+		case "map":
+			keys = questMaps(q)
+		}
+		if len(keys) == 0 {
+			keys = []string{unspecifiedGroup}
+		}
+		for _, key := range keys {
+			groups[key] = append(groups[key], q)
+		}
+	}
+	for key := range groups {
+		sort.SliceStable(groups[key], func(i, j int) bool { return groups[key][i].ID < groups[key][j].ID })
+	}
+	return groups
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestAvailableQuests(t *testing.T) {
+	quests := []models.Quest{
+		{ID: 1, ExternalID: "q1", Trader: "Shani", Data: models.JSONB{"nextQuestIds": []interface{}{"q2"}, "map": "Dam"}},
+		{ID: 2, ExternalID: "q2", Trader: "Shani", Data: models.JSONB{"maps": []interface{}{"Dam", "Spaceport"}}},
+		{ID: 3, ExternalID: "q3", Trader: "Celeste", Data: models.JSONB{"previousQuestIds": []interface{}{"q2", "removed_quest"}}},
+		{ID: 4, ExternalID: "q4"},
+		{ID: 5, ExternalID: "q5", Trader: "Celeste", Data: models.JSONB{"requiredTraderLevel": float64(3)}},
+	}
+
+	ids := func(qs []models.Quest) []string {
+		out := make([]string, len(qs))
+		for i, q := range qs {
+			out[i] = q.ExternalID
+		}
+		return out
+	}
+
+	cases := []struct {
+		completed    map[string]bool
+		traderLevels map[string]int
+		want         []string
+	}{
+		{map[string]bool{}, nil, []string{"q1", "q4", "q5"}},
+		{map[string]bool{"q1": true}, nil, []string{"q2", "q4", "q5"}},
+		{map[string]bool{"q1": true, "q2": true}, nil, []string{"q3", "q4", "q5"}},
+		{map[string]bool{}, map[string]int{"celeste": 2}, []string{"q1", "q4"}},
+		{map[string]bool{}, map[string]int{"celeste": 3}, []string{"q1", "q4", "q5"}},
+	}
+	for _, tc := range cases {
+		got := ids(availableQuests(quests, tc.completed, tc.traderLevels))
+		if len(got) != len(tc.want) {
+			t.Errorf("completed=%v: got %v, want %v", tc.completed, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("completed=%v: got %v, want %v", tc.completed, got, tc.want)
+				break
+			}
+		}
+	}
+
+	byMap := groupQuests(quests, "map")
+	if len(byMap["Dam"]) != 2 || len(byMap["Spaceport"]) != 1 || len(byMap[unspecifiedGroup]) != 3 {
+		t.Errorf("unexpected map grouping: %v", byMap)
+	}
+	byTrader := groupQuests(quests, "trader")
+	if len(byTrader["Shani"]) != 2 || len(byTrader["Celeste"]) != 2 || len(byTrader[unspecifiedGroup]) != 1 {
+		t.Errorf("unexpected trader grouping: %v", byTrader)
+	}
+}
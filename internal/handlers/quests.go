@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/middleware"
 	"github.com/mat/arcapi/internal/models"
 	"github.com/mat/arcapi/internal/repository"
 	"github.com/mat/arcapi/internal/services"
@@ -12,38 +13,61 @@ import (
 
 type QuestHandler struct {
 	repo             *repository.QuestRepository
+	tagRepo          *repository.TagRepository
 	dataCacheService *services.DataCacheService
 }
 
-func NewQuestHandler(repo *repository.QuestRepository) *QuestHandler {
-	return &QuestHandler{repo: repo}
+func NewQuestHandler(repo *repository.QuestRepository, tagRepo *repository.TagRepository) *QuestHandler {
+	return &QuestHandler{repo: repo, tagRepo: tagRepo}
 }
 
-func NewQuestHandlerWithCache(repo *repository.QuestRepository, dataCacheService *services.DataCacheService) *QuestHandler {
+func NewQuestHandlerWithCache(repo *repository.QuestRepository, tagRepo *repository.TagRepository, dataCacheService *services.DataCacheService) *QuestHandler {
 	return &QuestHandler{
 		repo:             repo,
+		tagRepo:          tagRepo,
 		dataCacheService: dataCacheService,
 	}
 }
 
 // List returns all quests
 // @Summary List all quests
-// @Description Fetch all quests from the database or cache
+// @Description Fetch all quests from the database or cache. Supports PostgREST-style filters on external_id, name, description, trader and xp (e.g. ?trader=eq.Celeste&xp=gte.1000), and by tag.
 // @Tags quests
 // @Accept json
 // @Produce json
+// @Param tag query string false "Only quests carrying any of these comma-separated tag slugs"
+// @Param lang query string false "Resolve multilingual text to this language, falling back to English (e.g. de)"
 // @Success 200 {object} PaginatedResponse{data=[]models.Quest} "Successfully fetched quests"
+// @Failure 400 {object} ErrorResponse "Invalid filter"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /quests [get]
 func (h *QuestHandler) List(c *gin.Context) {
+	filters, ok := parseListFilters(c, repository.QuestFilterColumns)
+	if !ok {
+		return
+	}
+	tagFilter, ok := parseTagFilter(c, h.tagRepo, models.TagEntityQuest)
+	if !ok {
+		return
+	}
+	if tagFilter != nil {
+		filters = append(filters, *tagFilter)
+	}
+	lang, ok := requestLanguage(c)
+	if !ok {
+		return
+	}
+
 	// Return all quests without pagination
 	var quests []models.Quest
 	var count int64
 	var err error
 
-	// Use cache service if available
-	if h.dataCacheService != nil {
+	// Filtered listings bypass the cache, which only holds the full quest list
+	if len(filters) > 0 {
+		quests, count, err = h.repo.FindFiltered(filters, 0, 1000000)
+	} else if h.dataCacheService != nil {
 		quests, count, err = h.dataCacheService.GetQuests()
 	} else {
 		// Fallback to direct database query
@@ -54,13 +78,26 @@ func (h *QuestHandler) List(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quests"})
 		return
 	}
+	h.attachTags(quests)
 
 	c.JSON(http.StatusOK, gin.H{
-		"data":  quests,
+		"data":  localizeAll(quests, lang, localizeQuest),
 		"total": count,
 	})
 }
 
+// attachTags fills in the slugs of the tags applied to each quest
+func (h *QuestHandler) attachTags(quests []models.Quest) {
+	ids := make([]string, len(quests))
+	for i := range quests {
+		ids[i] = quests[i].ExternalID
+	}
+	slugs := loadTagSlugs(h.tagRepo, models.TagEntityQuest, ids)
+	for i := range quests {
+		quests[i].Tags = slugs[quests[i].ExternalID]
+	}
+}
+
 // Get returns a single quest by ID
 // @Summary Get a single quest
 // @Description Fetch a quest by its numeric ID
@@ -68,6 +105,8 @@ func (h *QuestHandler) List(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Quest ID"
+// @Param lang query string false "Resolve multilingual text to this language, falling back to English (e.g. de)"
+// @Param normalized query bool false "Return the typed services.NormalizedQuest view instead of the raw data"
 // @Success 200 {object} models.Quest "Successfully fetched the quest"
 // @Failure 400 {object} ErrorResponse "Invalid quest ID"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
@@ -75,6 +114,11 @@ func (h *QuestHandler) List(c *gin.Context) {
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /quests/{id} [get]
 func (h *QuestHandler) Get(c *gin.Context) {
+	lang, ok := requestLanguage(c)
+	if !ok {
+		return
+	}
+
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -87,13 +131,24 @@ func (h *QuestHandler) Get(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Quest not found"})
 		return
 	}
+	quest.Tags = loadTagSlugs(h.tagRepo, models.TagEntityQuest, []string{quest.ExternalID})[quest.ExternalID]
+
+	if c.Query("normalized") == "true" {
+		c.JSON(http.StatusOK, services.NormalizeQuest(*quest, lang))
+		return
+	}
+
+	if lang != "" {
+		localized := localizeQuest(*quest, lang)
+		quest = &localized
+	}
 
 	c.JSON(http.StatusOK, quest)
 }
 
 // Create adds a new quest
 // @Summary Create a quest
-// @Description Add a new quest to the database
+// @Description Add a new quest to the database. Sync won't overwrite it; see /admin/sync/conflicts.
 // @Tags quests
 // @Accept json
 // @Produce json
@@ -117,12 +172,15 @@ func (h *QuestHandler) Create(c *gin.Context) {
 		return
 	}
 
+	quest.ManualOverride = true
 	err := h.repo.Create(&quest)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create quest"})
 		return
 	}
 
+	middleware.RecordChange(c, middleware.AuditEntityQuest, quest.ID, nil, quest)
+
 	// Invalidate cache on create
 	if h.dataCacheService != nil {
 		h.dataCacheService.InvalidateQuestsCache()
@@ -133,7 +191,7 @@ func (h *QuestHandler) Create(c *gin.Context) {
 
 // Update modifies an existing quest
 // @Summary Update a quest
-// @Description Update an existing quest by its ID
+// @Description Update an existing quest by its ID. Sync stops overwriting it, listing upstream changes to it under /admin/sync/conflicts instead.
 // @Tags quests
 // @Accept json
 // @Produce json
@@ -160,13 +218,17 @@ func (h *QuestHandler) Update(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.repo.FindByID(uint(id))
 	quest.ID = uint(id)
+	quest.ManualOverride = true
 	err = h.repo.Update(&quest)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update quest"})
 		return
 	}
 
+	middleware.RecordChange(c, middleware.AuditEntityQuest, quest.ID, before, quest)
+
 	// Invalidate cache on update
 	if h.dataCacheService != nil {
 		h.dataCacheService.InvalidateQuestsCache()
@@ -197,12 +259,15 @@ func (h *QuestHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.repo.FindByID(uint(id))
 	err = h.repo.Delete(uint(id))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete quest"})
 		return
 	}
 
+	middleware.RecordChange(c, middleware.AuditEntityQuest, uint(id), before, nil)
+
 	// Invalidate cache on delete
 	if h.dataCacheService != nil {
 		h.dataCacheService.InvalidateQuestsCache()
@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/config"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+type RecipeHandler struct {
+	recipeRepo *repository.RecipeRepository
+	itemRepo   *repository.ItemRepository
+	maxDepth   int
+}
+
+func NewRecipeHandler(recipeRepo *repository.RecipeRepository, itemRepo *repository.ItemRepository, cfg *config.Config) *RecipeHandler {
+	maxDepth := cfg.CraftCostMaxDepth
+	if maxDepth < 1 {
+		maxDepth = 1
+	}
+	return &RecipeHandler{
+		recipeRepo: recipeRepo,
+		itemRepo:   itemRepo,
+		maxDepth:   maxDepth,
+	}
+}
+
+// MaterialNode is one ingredient in a material breakdown. Components is only set when the
+// ingredient is itself craftable and a recursive breakdown was requested.
+type MaterialNode struct {
+	ItemID     string         `json:"item_id"`
+	Name       string         `json:"name,omitempty"`
+	Quantity   int            `json:"quantity"`
+	Crafts     int            `json:"crafts,omitempty"` // Times the ingredient must be crafted to cover Quantity
+	Components []MaterialNode `json:"components,omitempty"`
+}
+
+// MaterialsResponse is the material breakdown for crafting an item
+type MaterialsResponse struct {
+	ItemID     uint           `json:"item_id"`
+	ExternalID string         `json:"external_id"`
+	Name       string         `json:"name"`
+	Quantity   int            `json:"quantity"`
+	Bench      string         `json:"bench,omitempty"`
+	Recursive  bool           `json:"recursive"`
+	Materials  []MaterialNode `json:"materials"`
+	// RawMaterials totals the uncraftable leaves of the tree (recursive only)
+	RawMaterials []MaterialNode `json:"raw_materials,omitempty"`
+}
+
+// GetMaterials returns the crafting materials for an item
+// @Summary Get crafting materials for an item
+// @Description Returns the ingredients needed to craft an item. With recursive=true, craftable ingredients are expanded down to raw materials and the totals are returned in raw_materials.
+// @Tags items
+// @Accept json
+// @Produce json
+// @Param id path int true "Item ID"
+// @Param recursive query bool false "Expand craftable ingredients down to raw materials"
+// @Param quantity query int false "Number of items to craft (default: 1)"
+// @Success 200 {object} MaterialsResponse "Material breakdown"
+// @Failure 400 {object} ErrorResponse "Invalid ID or quantity"
+// @Failure 404 {object} ErrorResponse "Item not found or not craftable"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /items/{id}/materials [get]
+func (h *RecipeHandler) GetMaterials(c *gin.Context) {
+	rc, ok := h.loadRecipeContext(c)
+	if !ok {
+		return
+	}
+	recursive := c.Query("recursive") == "true"
+
+	maxDepth := 1
+	if recursive {
+		maxDepth = 0
+	}
+	breakdown := buildMaterialTree(rc.item.ExternalID, rc.quantity, rc.recipes, rc.names, maxDepth)
+
+	resp := MaterialsResponse{
+		ItemID:     rc.item.ID,
+		ExternalID: rc.item.ExternalID,
+		Name:       rc.item.Name,
+		Quantity:   rc.quantity,
+		Bench:      rc.recipe.Bench,
+		Recursive:  recursive,
+		Materials:  breakdown.Materials,
+	}
+	if recursive {
+		resp.RawMaterials = breakdown.Raw
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// CraftCostResponse is the flattened raw-material cost of crafting an item
+type CraftCostResponse struct {
+	ItemID       uint           `json:"item_id"`
+	ExternalID   string         `json:"external_id"`
+	Name         string         `json:"name"`
+	Quantity     int            `json:"quantity"`
+	MaxDepth     int            `json:"max_depth"`
+	RawMaterials []MaterialNode `json:"raw_materials"`
+	TotalItems   int            `json:"total_items"`
+	// Intermediates lists the craftable ingredients made along the way, with craft counts
+	Intermediates []MaterialNode `json:"intermediates"`
+	// Cycles lists ingredients whose recipe leads back to an ancestor; they are counted as raw
+	Cycles []string `json:"cycles,omitempty"`
+	// DepthLimited is set when craftable ingredients were left unexpanded at max_depth
+	DepthLimited bool `json:"depth_limited"`
+}
+
+// GetCraftCost returns the flattened raw-material cost of crafting an item
+// @Summary Get craft cost rollup
+// @Description Recursively expands sub-recipes into a flat list of raw materials with totals. Cyclic recipes are reported and counted as raw; expansion stops at the requested depth.
+// @Tags items
+// @Accept json
+// @Produce json
+// @Param id path int true "Item ID"
+// @Param quantity query int false "Number of items to craft (default: 1)"
+// @Param depth query int false "Maximum recipe depth to expand (default and cap: CRAFT_COST_MAX_DEPTH)"
+// @Success 200 {object} CraftCostResponse "Craft cost rollup"
+// @Failure 400 {object} ErrorResponse "Invalid ID, quantity or depth"
+// @Failure 404 {object} ErrorResponse "Item not found or not craftable"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /items/{id}/craft-cost [get]
+func (h *RecipeHandler) GetCraftCost(c *gin.Context) {
+	maxDepth := h.maxDepth
+	if d := c.Query("depth"); d != "" {
+		parsed, err := strconv.Atoi(d)
+		if err != nil || parsed < 1 || parsed > h.maxDepth {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "depth must be between 1 and " + strconv.Itoa(h.maxDepth)})
+			return
+		}
+		maxDepth = parsed
+	}
+
+	rc, ok := h.loadRecipeContext(c)
+	if !ok {
+		return
+	}
+
+	breakdown := buildMaterialTree(rc.item.ExternalID, rc.quantity, rc.recipes, rc.names, maxDepth)
+
+	total := 0
+	for _, m := range breakdown.Raw {
+		total += m.Quantity
+	}
+
+	c.JSON(http.StatusOK, CraftCostResponse{
+		ItemID:        rc.item.ID,
+		ExternalID:    rc.item.ExternalID,
+		Name:          rc.item.Name,
+		Quantity:      rc.quantity,
+		MaxDepth:      maxDepth,
+		RawMaterials:  breakdown.Raw,
+		TotalItems:    total,
+		Intermediates: breakdown.Intermediates,
+		Cycles:        breakdown.Cycles,
+		DepthLimited:  breakdown.DepthLimited,
+	})
+}
+
+// recipeContext is the data shared by the recipe endpoints for one request
+type recipeContext struct {
+	item     *models.Item
+	recipe   models.Recipe
+	recipes  map[string]models.Recipe
+	names    map[string]string
+	quantity int
+}
+
+// loadRecipeContext parses the item ID and quantity and loads the recipe graph. On failure
+// it writes the error response and returns false.
+func (h *RecipeHandler) loadRecipeContext(c *gin.Context) (*recipeContext, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+		return nil, false
+	}
+
+	quantity := 1
+	if q := c.Query("quantity"); q != "" {
+		parsed, err := strconv.Atoi(q)
+		if err != nil || parsed < 1 || parsed > 1000 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "quantity must be between 1 and 1000"})
+			return nil, false
+		}
+		quantity = parsed
+	}
+
+	item, err := h.itemRepo.FindByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		return nil, false
+	}
+
+	recipes, err := h.recipeRepo.ListAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recipes"})
+		return nil, false
+	}
+	recipesByItem := make(map[string]models.Recipe, len(recipes))
+	for _, recipe := range recipes {
+		recipesByItem[recipe.ItemExternalID] = recipe
+	}
+
+	recipe, ok := recipesByItem[item.ExternalID]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item has no recipe"})
+		return nil, false
+	}
+
+	items, err := h.itemRepo.ListAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch items"})
+		return nil, false
+	}
+	names := make(map[string]string, len(items))
+	for _, it := range items {
+		names[it.ExternalID] = it.Name
+	}
+
+	return &recipeContext{
+		item:     item,
+		recipe:   recipe,
+		recipes:  recipesByItem,
+		names:    names,
+		quantity: quantity,
+	}, true
+}
+
+// recipeIngredients reads the ingredient map of a recipe in a stable order
+func recipeIngredients(recipe models.Recipe) ([]string, map[string]int) {
+	ids := make([]string, 0, len(recipe.Ingredients))
+	quantities := make(map[string]int, len(recipe.Ingredients))
+	for id, qty := range recipe.Ingredients {
+		var q int
+		switch v := qty.(type) {
+		case float64:
+			q = int(v)
+		case int:
+			q = v
+		}
+		if q <= 0 {
+			continue
+		}
+		ids = append(ids, id)
+		quantities[id] = q
+	}
+	sort.Strings(ids)
+	return ids, quantities
+}
+
+// materialBreakdown is the result of expanding a recipe tree
+type materialBreakdown struct {
+	Materials     []MaterialNode
+	Raw           []MaterialNode
+	Intermediates []MaterialNode
+	Cycles        []string
+	DepthLimited  bool
+}
+
+// buildMaterialTree expands the recipe for rootID to produce quantity items, down to
+// maxDepth levels of ingredients (0 for no limit). Crafts are whole, so intermediate
+// quantities round up to the recipe's output size. Ingredients that appear in their own
+// ancestry are recorded as cycles and treated as raw, as are craftable ingredients at
+// the depth limit.
+func buildMaterialTree(rootID string, quantity int, recipes map[string]models.Recipe, names map[string]string, maxDepth int) materialBreakdown {
+	var result materialBreakdown
+	rawTotals := make(map[string]int)
+	craftTotals := make(map[string]int)
+	cycles := make(map[string]bool)
+	ancestors := map[string]bool{rootID: true}
+
+	var expand func(itemID string, qty, depth int) []MaterialNode
+	expand = func(itemID string, qty, depth int) []MaterialNode {
+		recipe := recipes[itemID]
+		output := recipe.OutputQuantity
+		if output < 1 {
+			output = 1
+		}
+		crafts := int(math.Ceil(float64(qty) / float64(output)))
+
+		ids, quantities := recipeIngredients(recipe)
+		nodes := make([]MaterialNode, 0, len(ids))
+		for _, id := range ids {
+			node := MaterialNode{
+				ItemID:   id,
+				Name:     names[id],
+				Quantity: quantities[id] * crafts,
+			}
+			sub, craftable := recipes[id]
+			switch {
+			case !craftable:
+				rawTotals[id] += node.Quantity
+			case ancestors[id]:
+				cycles[id] = true
+				rawTotals[id] += node.Quantity
+			case maxDepth > 0 && depth >= maxDepth:
+				result.DepthLimited = true
+				rawTotals[id] += node.Quantity
+			default:
+				subOutput := sub.OutputQuantity
+				if subOutput < 1 {
+					subOutput = 1
+				}
+				node.Crafts = int(math.Ceil(float64(node.Quantity) / float64(subOutput)))
+				craftTotals[id] += node.Crafts
+				ancestors[id] = true
+				node.Components = expand(id, node.Quantity, depth+1)
+				delete(ancestors, id)
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+
+	result.Materials = expand(rootID, quantity, 1)
+	result.Raw = materialTotals(rawTotals, names, false)
+	result.Intermediates = materialTotals(craftTotals, names, true)
+	for id := range cycles {
+		result.Cycles = append(result.Cycles, id)
+	}
+	sort.Strings(result.Cycles)
+	return result
+}
+
+// materialTotals turns per-item totals into nodes sorted by item ID; crafts selects
+// whether the totals are craft counts or quantities
+func materialTotals(totals map[string]int, names map[string]string, crafts bool) []MaterialNode {
+	ids := make([]string, 0, len(totals))
+	for id := range totals {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	nodes := make([]MaterialNode, 0, len(ids))
+	for _, id := range ids {
+		node := MaterialNode{ItemID: id, Name: names[id]}
+		if crafts {
+			node.Crafts = totals[id]
+		} else {
+			node.Quantity = totals[id]
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+func testRecipes() map[string]models.Recipe {
+	return map[string]models.Recipe{
+		"rifle":      {ItemExternalID: "rifle", OutputQuantity: 1, Ingredients: models.JSONB{"mech_parts": float64(3), "metal": float64(2)}},
+		"mech_parts": {ItemExternalID: "mech_parts", OutputQuantity: 2, Ingredients: models.JSONB{"metal": float64(1), "spring": float64(1)}},
+	}
+}
+
+func TestBuildMaterialTree(t *testing.T) {
+	recipes := testRecipes()
+	names := map[string]string{"metal": "Metal Parts"}
+
+	direct := buildMaterialTree("rifle", 1, recipes, names, 1)
+	if len(direct.Materials) != 2 || len(direct.Materials[0].Components) != 0 {
+		t.Fatalf("depth 1 should list direct ingredients only, got %+v", direct.Materials)
+	}
+	if !direct.DepthLimited {
+		t.Error("expected depth limit to be reported when a craftable ingredient is left unexpanded")
+	}
+
+	full := buildMaterialTree("rifle", 1, recipes, names, 0)
+	if full.Materials[0].ItemID != "mech_parts" || full.Materials[0].Crafts != 2 {
+		t.Fatalf("expected 3 mech_parts to need 2 crafts, got %+v", full.Materials[0])
+	}
+	want := map[string]int{"metal": 4, "spring": 2}
+	if len(full.Raw) != len(want) {
+		t.Fatalf("unexpected raw materials %+v", full.Raw)
+	}
+	for _, m := range full.Raw {
+		if want[m.ItemID] != m.Quantity {
+			t.Errorf("raw %s = %d, want %d", m.ItemID, m.Quantity, want[m.ItemID])
+		}
+	}
+	if full.Raw[0].Name != "Metal Parts" {
+		t.Errorf("expected name to be resolved, got %q", full.Raw[0].Name)
+	}
+	if len(full.Intermediates) != 1 || full.Intermediates[0].Crafts != 2 || full.DepthLimited {
+		t.Errorf("unexpected intermediates %+v (depth limited: %v)", full.Intermediates, full.DepthLimited)
+	}
+}
+
+func TestBuildMaterialTreeCycle(t *testing.T) {
+	recipes := map[string]models.Recipe{
+		"a": {ItemExternalID: "a", Ingredients: models.JSONB{"b": float64(1)}},
+		"b": {ItemExternalID: "b", Ingredients: models.JSONB{"a": float64(1)}},
+	}
+
+	breakdown := buildMaterialTree("a", 1, recipes, nil, 0)
+	if len(breakdown.Raw) != 1 || breakdown.Raw[0].ItemID != "a" {
+		t.Fatalf("cyclic recipe should stop at the repeated item, got %+v", breakdown.Raw)
+	}
+	if len(breakdown.Cycles) != 1 || breakdown.Cycles[0] != "a" {
+		t.Errorf("expected cycle on a, got %v", breakdown.Cycles)
+	}
+}
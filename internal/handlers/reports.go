@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+// inactiveUsersColumns is the header row of the inactive users CSV
+var inactiveUsersColumns = []string{"id", "username", "email", "role", "created_at", "last_seen_at", "last_progress_at"}
+
+type ReportHandler struct {
+	userRepo *repository.UserRepository
+}
+
+func NewReportHandler(userRepo *repository.UserRepository) *ReportHandler {
+	return &ReportHandler{userRepo: userRepo}
+}
+
+// InactiveUsers reports the users without recent activity
+// @Summary Report inactive users
+// @Description Users created more than days ago who have not made a request or changed any progress (quests, hideout modules, skill nodes, blueprints, traders, player level, inventory) since, least recently seen first. Feeds retention policy and manual cleanup decisions.
+// @Tags management
+// @Produce json,text/csv
+// @Param days query int false "Days without activity" default(90)
+// @Param format query string false "Report format" Enums(json, csv) default(json)
+// @Success 200 {array} repository.InactiveUser "Inactive users"
+// @Failure 400 {object} ErrorResponse "Invalid days or format"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/reports/inactive-users [get]
+func (h *ReportHandler) InactiveUsers(c *gin.Context) {
+	days := 90
+	if d := c.Query("days"); d != "" {
+		parsed, err := strconv.Atoi(d)
+		if err != nil || parsed < 1 || parsed > 3650 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 3650"})
+			return
+		}
+		days = parsed
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+
+	now := time.Now().UTC()
+	users, err := h.userRepo.FindInactive(now.AddDate(0, 0, -days))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find inactive users"})
+		return
+	}
+
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="inactive-users-%dd-%s.csv"`, days, now.Format("2006-01-02")))
+		if err := writeInactiveUsersCSV(c.Writer, users); err != nil {
+			c.Error(err)
+		}
+		return
+	}
+	if users == nil {
+		users = []repository.InactiveUser{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": users, "days": days, "total": len(users), "generated_at": now})
+}
+
+// writeInactiveUsersCSV writes users as CSV, with RFC 3339 timestamps and empty cells for
+// users never seen or without progress
+func writeInactiveUsersCSV(w io.Writer, users []repository.InactiveUser) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(inactiveUsersColumns); err != nil {
+		return err
+	}
+	for _, u := range users {
+		record := []string{
+			strconv.FormatUint(uint64(u.ID), 10),
+			u.Username,
+			u.Email,
+			string(u.Role),
+			u.CreatedAt.UTC().Format(time.RFC3339),
+			optionalTime(u.LastSeenAt),
+			optionalTime(u.LastProgressAt),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func optionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+func TestWriteInactiveUsersCSV(t *testing.T) {
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	seen := time.Date(2026, 5, 2, 8, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	users := []repository.InactiveUser{
+		{ID: 7, Username: "raider", Email: "raider@example.com", Role: models.RoleUser, CreatedAt: created, LastSeenAt: &seen},
+		{ID: 9, Username: "quiet, one", Email: "q@example.com", Role: models.RoleAdmin, CreatedAt: created},
+	}
+
+	var buf bytes.Buffer
+	if err := writeInactiveUsersCSV(&buf, users); err != nil {
+		t.Fatal(err)
+	}
+	want := "id,username,email,role,created_at,last_seen_at,last_progress_at\n" +
+		"7,raider,raider@example.com,user,2025-03-01T12:00:00Z,2026-05-02T06:30:00Z,\n" +
+		"9,\"quiet, one\",q@example.com,admin,2025-03-01T12:00:00Z,,\n"
+	if got := buf.String(); got != want {
+		t.Errorf("writeInactiveUsersCSV() =\n%s\nwant\n%s", got, want)
+	}
+}
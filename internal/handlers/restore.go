@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/services"
+	"gorm.io/gorm"
+)
+
+type RestoreHandler struct {
+	service *services.SoftDeleteService
+}
+
+func NewRestoreHandler(service *services.SoftDeleteService) *RestoreHandler {
+	return &RestoreHandler{service: service}
+}
+
+// Restore undoes the soft delete of a content entity
+// @Summary Restore a deleted entity
+// @Description Restore a quest, item, skill node, hideout module or enemy type deleted through the write API. Deleted rows are kept, and can be restored, until they are purged SOFT_DELETE_RETENTION_DAYS after deletion.
+// @Tags management
+// @Accept json
+// @Produce json
+// @Param entity path string true "Entity" Enums(quests, items, skill-nodes, hideout-modules, enemy-types)
+// @Param id path int true "Entity ID"
+// @Success 200 {object} MessageResponse "Entity restored"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 404 {object} ErrorResponse "Unknown entity or no deleted row with that ID"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/{entity}/{id}/restore [post]
+func (h *RestoreHandler) Restore(c *gin.Context) {
+	entity := c.Param("entity")
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	err = h.service.Restore(entity, uint(id))
+	switch {
+	case errors.Is(err, services.ErrUnknownSoftDeleteEntity):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown entity, expected one of " + strings.Join(h.service.Entities(), ", ")})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("No deleted %s with ID %d", entity, id)})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore " + entity})
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Restored %s %d", entity, id)})
+	}
+}
@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+// maxSavedSearches caps how many saved searches a single user can keep
+const maxSavedSearches = 50
+
+// maxSavedSearchQueryLength caps the stored query string
+const maxSavedSearchQueryLength = 2000
+
+// savedSearchFilterColumns maps each entity type a search can be saved for to the columns
+// its list endpoint filters on
+var savedSearchFilterColumns = map[string]map[string]string{
+	"item":       repository.ItemFilterColumns,
+	"quest":      repository.QuestFilterColumns,
+	"skill_node": repository.SkillNodeFilterColumns,
+	"enemy_type": repository.EnemyTypeFilterColumns,
+}
+
+type SavedSearchHandler struct {
+	savedSearchRepo *repository.SavedSearchRepository
+}
+
+func NewSavedSearchHandler(savedSearchRepo *repository.SavedSearchRepository) *SavedSearchHandler {
+	return &SavedSearchHandler{savedSearchRepo: savedSearchRepo}
+}
+
+// savedSearchRequest is the body of a create or update
+type savedSearchRequest struct {
+	Name       string `json:"name" binding:"required"`
+	EntityType string `json:"entity_type" binding:"required"`
+	Query      string `json:"query"`
+}
+
+// normalizeSavedSearchQuery checks that raw is a valid query string for the entity type's
+// list endpoint and returns it in canonical form. Parameters other than filters, such as
+// tag or lang, are kept as they are.
+func normalizeSavedSearchQuery(entityType, raw string) (string, error) {
+	columns, ok := savedSearchFilterColumns[entityType]
+	if !ok {
+		return "", errors.New("entity_type must be item, quest, skill_node or enemy_type")
+	}
+	raw = strings.TrimPrefix(strings.TrimSpace(raw), "?")
+	if len(raw) > maxSavedSearchQueryLength {
+		return "", fmt.Errorf("query must be at most %d characters", maxSavedSearchQueryLength)
+	}
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid query: %w", err)
+	}
+	if _, err := repository.ParseFilters(values, columns); err != nil {
+		return "", err
+	}
+	return values.Encode(), nil
+}
+
+// bind reads and validates a create or update body, writing a 400 and returning false if
+// it is invalid
+func (h *SavedSearchHandler) bind(c *gin.Context) (savedSearchRequest, bool) {
+	var req savedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return req, false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must be 1 to 100 characters"})
+		return req, false
+	}
+	query, err := normalizeSavedSearchQuery(req.EntityType, req.Query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return req, false
+	}
+	req.Query = query
+	return req, true
+}
+
+// List returns the current user's saved searches
+// @Summary List my saved searches
+// @Description Fetch the authenticated user's saved filter presets by name, optionally for one entity type. Append query to the entity type's list endpoint to run one.
+// @Tags saved-searches
+// @Produce json
+// @Param entity_type query string false "Entity type" Enums(item, quest, skill_node, enemy_type)
+// @Success 200 {object} map[string][]models.SavedSearch "Successfully fetched saved searches"
+// @Failure 400 {object} ErrorResponse "Invalid entity type"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /me/saved-searches [get]
+func (h *SavedSearchHandler) List(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	entityType := c.Query("entity_type")
+	if _, ok := savedSearchFilterColumns[entityType]; entityType != "" && !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "entity_type must be item, quest, skill_node or enemy_type"})
+		return
+	}
+
+	searches, err := h.savedSearchRepo.FindByUserID(user.ID, entityType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch saved searches"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": searches})
+}
+
+// Create saves a filter preset for the current user
+// @Summary Save a search
+// @Description Save a named filter preset: the entity type and the query string of its list endpoint, e.g. type=eq.weapon&name=ilike.*alloy*&tag=event. Filters are validated like the list endpoint does; other parameters are stored as they are. Names are unique per user.
+// @Tags saved-searches
+// @Accept json
+// @Produce json
+// @Param search body map[string]string true "name, entity_type and query"
+// @Success 201 {object} models.SavedSearch "Successfully saved the search"
+// @Failure 400 {object} ErrorResponse "Invalid input, entity type or filter"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 409 {object} ErrorResponse "Name already used or too many saved searches"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /me/saved-searches [post]
+func (h *SavedSearchHandler) Create(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	req, ok := h.bind(c)
+	if !ok {
+		return
+	}
+
+	count, err := h.savedSearchRepo.CountByUserID(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save search"})
+		return
+	}
+	if count >= maxSavedSearches {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("At most %d saved searches are allowed", maxSavedSearches)})
+		return
+	}
+	if !h.nameAvailable(c, user.ID, req.Name, 0) {
+		return
+	}
+
+	search := &models.SavedSearch{
+		UserID:     user.ID,
+		Name:       req.Name,
+		EntityType: req.EntityType,
+		Query:      req.Query,
+	}
+	if err := h.savedSearchRepo.Create(search); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save search"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, search)
+}
+
+// Update replaces one of the current user's saved searches
+// @Summary Update a saved search
+// @Description Replace the name, entity type and query of a saved search.
+// @Tags saved-searches
+// @Accept json
+// @Produce json
+// @Param id path int true "Saved search ID"
+// @Param search body map[string]string true "name, entity_type and query"
+// @Success 200 {object} models.SavedSearch "Successfully updated the search"
+// @Failure 400 {object} ErrorResponse "Invalid input, entity type or filter"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Saved search not found"
+// @Failure 409 {object} ErrorResponse "Name already used"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /me/saved-searches/{id} [put]
+func (h *SavedSearchHandler) Update(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	id, err := parseUint(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid saved search ID"})
+		return
+	}
+	search, err := h.savedSearchRepo.FindByID(user.ID, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Saved search not found"})
+		return
+	}
+
+	req, ok := h.bind(c)
+	if !ok {
+		return
+	}
+	if !h.nameAvailable(c, user.ID, req.Name, search.ID) {
+		return
+	}
+
+	search.Name = req.Name
+	search.EntityType = req.EntityType
+	search.Query = req.Query
+	if err := h.savedSearchRepo.Update(search); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update saved search"})
+		return
+	}
+
+	c.JSON(http.StatusOK, search)
+}
+
+// Delete removes one of the current user's saved searches
+// @Summary Delete a saved search
+// @Tags saved-searches
+// @Param id path int true "Saved search ID"
+// @Success 204 "Successfully deleted the search"
+// @Failure 400 {object} ErrorResponse "Invalid saved search ID"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Saved search not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /me/saved-searches/{id} [delete]
+func (h *SavedSearchHandler) Delete(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	id, err := parseUint(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid saved search ID"})
+		return
+	}
+
+	deleted, err := h.savedSearchRepo.Delete(user.ID, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete saved search"})
+		return
+	}
+	if deleted == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Saved search not found"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// nameAvailable checks the user has no other saved search with the name, writing a 409 or
+// 500 and returning false if they do or it can't be checked
+func (h *SavedSearchHandler) nameAvailable(c *gin.Context, userID uint, name string, exceptID uint) bool {
+	taken, err := h.savedSearchRepo.NameTaken(userID, name, exceptID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save search"})
+		return false
+	}
+	if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": "A saved search with this name already exists"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import "testing"
+
+func TestNormalizeSavedSearchQuery(t *testing.T) {
+	got, err := normalizeSavedSearchQuery("item", "?type=eq.weapon&tag=event&name=ilike.*alloy*")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "name=ilike.%2Aalloy%2A&tag=event&type=eq.weapon"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	if got, err := normalizeSavedSearchQuery("quest", ""); err != nil || got != "" {
+		t.Errorf("expected an empty query to be allowed, got %q (%v)", got, err)
+	}
+
+	for _, tc := range []struct{ entityType, query string }{
+		{"map", "name=eq.dam"},
+		{"item", "type=weapon"},
+		{"quest", "xp=between.1"},
+		{"item", "name=%zz"},
+	} {
+		if _, err := normalizeSavedSearchQuery(tc.entityType, tc.query); err == nil {
+			t.Errorf("expected %s query %q to be rejected", tc.entityType, tc.query)
+		}
+	}
+}
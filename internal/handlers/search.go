@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/middleware"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+const (
+	// maxSearchLength caps q, which every document is matched against
+	maxSearchLength      = 100
+	defaultAutocompletes = 10
+	maxAutocompletes     = 25
+)
+
+type SearchHandler struct {
+	repo *repository.TranslationRepository
+}
+
+func NewSearchHandler(repo *repository.TranslationRepository) *SearchHandler {
+	return &SearchHandler{repo: repo}
+}
+
+// searchQuery reads q, lang and type, writing a 400 and returning false when one is invalid
+func searchQuery(c *gin.Context) (repository.SearchQuery, bool) {
+	query := repository.SearchQuery{Text: strings.TrimSpace(c.Query("q"))}
+	if query.Text == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return query, false
+	}
+	if utf8.RuneCountInString(query.Text) > maxSearchLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q must be at most " + strconv.Itoa(maxSearchLength) + " characters"})
+		return query, false
+	}
+
+	lang, ok := requestLanguage(c)
+	if !ok {
+		return query, false
+	}
+	if lang == "" {
+		lang = "en"
+	}
+	query.Lang = lang
+
+	if types := c.Query("type"); types != "" {
+		for _, entityType := range strings.Split(types, ",") {
+			entityType = strings.TrimSpace(entityType)
+			if !validTranslationEntityType(entityType) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "type must be quest, item, skill_node or hideout_module"})
+				return query, false
+			}
+			query.EntityTypes = append(query.EntityTypes, entityType)
+		}
+	}
+	return query, true
+}
+
+// Search finds quests, items, skill nodes and hideout modules in one language
+// @Summary Search game data
+// @Description Case-insensitive search of the names, descriptions and quest objectives of quests, items, skill nodes and hideout modules in one language, from a search index rebuilt at every sync. Texts without a translation in the language are searched in English. Results whose name matches come first, then by how closely the name matches.
+// @Tags search
+// @Produce json
+// @Param q query string true "Text to search for"
+// @Param lang query string false "Language code (e.g. de)" default(en)
+// @Param type query string false "Comma-separated entity types" Enums(quest, item, skill_node, hideout_module)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Results per page" default(20)
+// @Success 200 {object} PaginatedResponse{data=[]models.SearchDocument} "Search results"
+// @Failure 400 {object} ErrorResponse "Missing q, or invalid language or type"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /search [get]
+func (h *SearchHandler) Search(c *gin.Context) {
+	query, ok := searchQuery(c)
+	if !ok {
+		return
+	}
+
+	page := 1
+	limit := 20
+
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= middleware.PageLimit(c) {
+			limit = parsed
+		}
+	}
+
+	documents, count, err := h.repo.Search(query, (page-1)*limit, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": documents,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": count,
+		},
+	})
+}
+
+// Autocomplete suggests names as they're typed
+// @Summary Autocomplete names
+// @Description Names of quests, items, skill nodes and hideout modules in one language with a word starting with q, names starting with it first and shorter names before longer ones. Names without a translation in the language are matched in English.
+// @Tags search
+// @Produce json
+// @Param q query string true "Start of a word of the name"
+// @Param lang query string false "Language code (e.g. de)" default(en)
+// @Param type query string false "Comma-separated entity types" Enums(quest, item, skill_node, hideout_module)
+// @Param limit query int false "Maximum suggestions" default(10) maximum(25)
+// @Success 200 {object} map[string][]models.SearchDocument "Suggestions"
+// @Failure 400 {object} ErrorResponse "Missing q, or invalid language or type"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /search/autocomplete [get]
+func (h *SearchHandler) Autocomplete(c *gin.Context) {
+	query, ok := searchQuery(c)
+	if !ok {
+		return
+	}
+
+	limit := defaultAutocompletes
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= maxAutocompletes {
+			limit = parsed
+		}
+	}
+
+	documents, err := h.repo.Autocomplete(query, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to autocomplete"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": documents})
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSearchQuery(t *testing.T) {
+	parse := func(params url.Values) (*httptest.ResponseRecorder, bool, []string, string) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/search?"+params.Encode(), nil)
+		query, ok := searchQuery(c)
+		return w, ok, query.EntityTypes, query.Lang
+	}
+
+	_, ok, types, lang := parse(url.Values{"q": {" legierung "}, "lang": {"DE"}, "type": {"quest, item"}})
+	if !ok || lang != "de" || !reflect.DeepEqual(types, []string{"quest", "item"}) {
+		t.Errorf("expected a German query for quests and items, got ok=%v lang=%q types=%v", ok, lang, types)
+	}
+	if _, ok, _, lang := parse(url.Values{"q": {"alloy"}}); !ok || lang != "en" {
+		t.Errorf("expected English by default, got ok=%v lang=%q", ok, lang)
+	}
+
+	for name, params := range map[string]url.Values{
+		"missing q":    {"lang": {"de"}},
+		"blank q":      {"q": {"  "}},
+		"long q":       {"q": {strings.Repeat("a", maxSearchLength+1)}},
+		"unknown lang": {"q": {"alloy"}, "lang": {"xx"}},
+		"unknown type": {"q": {"alloy"}, "type": {"item,enemy_type"}},
+	} {
+		w, ok, _, _ := parse(params)
+		if ok || w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got ok=%v status %d", name, ok, w.Code)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/config"
+	"github.com/mat/arcapi/internal/middleware"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+	"github.com/mat/arcapi/internal/services"
+)
+
+// maxSecurityReportBodyBytes leaves room for the longest description
+const maxSecurityReportBodyBytes = 64 * 1024
+
+type SecurityReportHandler struct {
+	reportService *services.SecurityReportService
+	eventRepo     *repository.SecurityEventRepository
+	securityTxt   *config.SecurityTxt
+}
+
+func NewSecurityReportHandler(reportService *services.SecurityReportService, eventRepo *repository.SecurityEventRepository, securityTxt *config.SecurityTxt) *SecurityReportHandler {
+	return &SecurityReportHandler{reportService: reportService, eventRepo: eventRepo, securityTxt: securityTxt}
+}
+
+// SecurityTxt serves /.well-known/security.txt
+// @Summary Get security.txt
+// @Description Security contact information as defined by RFC 9116. Not found when no security contact is configured.
+// @Tags security
+// @Produce plain
+// @Success 200 {string} string "security.txt"
+// @Failure 404 {string} string "No security contact configured"
+// @Router /.well-known/security.txt [get]
+func (h *SecurityReportHandler) SecurityTxt(c *gin.Context) {
+	if h.securityTxt == nil {
+		c.String(http.StatusNotFound, "Not found\n")
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.String(http.StatusOK, renderSecurityTxt(h.securityTxt, time.Now()))
+}
+
+// renderSecurityTxt writes txt in the RFC 9116 format
+func renderSecurityTxt(txt *config.SecurityTxt, now time.Time) string {
+	expires := now.UTC().Truncate(24*time.Hour).AddDate(1, 0, 0)
+	if txt.Expires != nil {
+		expires = txt.Expires.UTC()
+	}
+
+	var b strings.Builder
+	for _, contact := range txt.Contacts {
+		fmt.Fprintf(&b, "Contact: %s\n", contact)
+	}
+	fmt.Fprintf(&b, "Expires: %s\n", expires.Format(time.RFC3339))
+	if txt.Policy != "" {
+		fmt.Fprintf(&b, "Policy: %s\n", txt.Policy)
+	}
+	b.WriteString("Preferred-Languages: en\n")
+	b.WriteString("# Reports can also be sent as JSON to POST /api/v1/security/reports\n")
+	return b.String()
+}
+
+// Submit takes a vulnerability report
+// @Summary Report a vulnerability
+// @Description Report a security vulnerability. No account is needed. Reports are stored for admins to triage and every admin is notified. A captcha_token is required when the server has a captcha secret configured. Limited to a few reports per IP per hour.
+// @Tags security
+// @Accept json
+// @Produce json
+// @Param report body services.SecurityReport true "Vulnerability report"
+// @Success 202 {object} map[string]interface{} "Report received"
+// @Failure 400 {object} ErrorResponse "Invalid report or captcha"
+// @Failure 429 {object} ErrorResponse "Rate limit exceeded"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /security/reports [post]
+func (h *SecurityReportHandler) Submit(c *gin.Context) {
+	var report services.SecurityReport
+	decoder := json.NewDecoder(http.MaxBytesReader(c.Writer, c.Request.Body, maxSecurityReportBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&report); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report: " + err.Error()})
+		return
+	}
+
+	event, err := h.reportService.Submit(c.Request.Context(), report, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidSecurityReport), errors.Is(err, services.ErrCaptchaFailed):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit report"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"id": event.ID, "status": "received"})
+}
+
+// ListEvents lists security events for triage
+// @Summary List security events
+// @Description Fetch security events such as vulnerability reports, newest first.
+// @Tags management
+// @Accept json
+// @Produce json
+// @Param type query string false "Filter by type, e.g. vulnerability_report"
+// @Param status query string false "Filter by status" Enums(new, triaged, resolved)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} PaginatedResponse{data=[]models.SecurityEvent} "Security events"
+// @Failure 400 {object} ErrorResponse "Invalid status"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/security/events [get]
+func (h *SecurityReportHandler) ListEvents(c *gin.Context) {
+	status := c.Query("status")
+	if status != "" && !models.IsSecurityEventStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of: new, triaged, resolved"})
+		return
+	}
+
+	page := 1
+	limit := 20
+
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= middleware.PageLimit(c) {
+			limit = parsed
+		}
+	}
+
+	offset := (page - 1) * limit
+	events, count, err := h.eventRepo.FindAll(c.Query("type"), status, offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch security events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": events,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": count,
+		},
+	})
+}
+
+// SecurityEventStatusRequest is the body of PUT /admin/security/events/:id/status
+type SecurityEventStatusRequest struct {
+	Status string `json:"status" binding:"required" example:"triaged"`
+}
+
+// UpdateEventStatus moves a security event through triage
+// @Summary Update a security event's status
+// @Description Mark a security event new, triaged or resolved.
+// @Tags management
+// @Accept json
+// @Produce json
+// @Param id path int true "Security event ID"
+// @Param status body SecurityEventStatusRequest true "New status"
+// @Success 200 {object} map[string]interface{} "Status updated"
+// @Failure 400 {object} ErrorResponse "Invalid input or ID"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 404 {object} ErrorResponse "Security event not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/security/events/{id}/status [put]
+func (h *SecurityReportHandler) UpdateEventStatus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid security event ID"})
+		return
+	}
+
+	var req SecurityEventStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !models.IsSecurityEventStatus(req.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of: new, triaged, resolved"})
+		return
+	}
+
+	updated, err := h.eventRepo.UpdateStatus(uint(id), req.Status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update security event"})
+		return
+	}
+	if updated == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Security event not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "status": req.Status})
+}
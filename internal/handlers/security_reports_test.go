@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mat/arcapi/internal/config"
+)
+
+func TestRenderSecurityTxt(t *testing.T) {
+	now := time.Date(2026, 10, 15, 13, 45, 0, 0, time.UTC)
+	txt := &config.SecurityTxt{
+		Contacts: []string{"mailto:security@example.com", "https://example.com/security"},
+		Policy:   "https://example.com/disclosure",
+	}
+
+	want := "Contact: mailto:security@example.com\n" +
+		"Contact: https://example.com/security\n" +
+		"Expires: 2027-10-15T00:00:00Z\n" +
+		"Policy: https://example.com/disclosure\n" +
+		"Preferred-Languages: en\n" +
+		"# Reports can also be sent as JSON to POST /api/v1/security/reports\n"
+	if got := renderSecurityTxt(txt, now); got != want {
+		t.Errorf("renderSecurityTxt() =\n%s\nwant\n%s", got, want)
+	}
+
+	expires := time.Date(2027, 1, 1, 0, 0, 0, 0, time.FixedZone("CET", 60*60))
+	txt = &config.SecurityTxt{Contacts: []string{"mailto:security@example.com"}, Expires: &expires}
+	want = "Contact: mailto:security@example.com\n" +
+		"Expires: 2026-12-31T23:00:00Z\n" +
+		"Preferred-Languages: en\n" +
+		"# Reports can also be sent as JSON to POST /api/v1/security/reports\n"
+	if got := renderSecurityTxt(txt, now); got != want {
+		t.Errorf("renderSecurityTxt() with a fixed expiry =\n%s\nwant\n%s", got, want)
+	}
+}
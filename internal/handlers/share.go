@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+	"github.com/mat/arcapi/internal/services"
+)
+
+type ShareHandler struct {
+	shareLinkService          *services.ShareLinkService
+	userRepo                  *repository.UserRepository
+	questProgressRepo         *repository.UserQuestProgressRepository
+	hideoutModuleProgressRepo *repository.UserHideoutModuleProgressRepository
+	skillNodeProgressRepo     *repository.UserSkillNodeProgressRepository
+	blueprintProgressRepo     *repository.UserBlueprintProgressRepository
+}
+
+func NewShareHandler(
+	shareLinkService *services.ShareLinkService,
+	userRepo *repository.UserRepository,
+	questProgressRepo *repository.UserQuestProgressRepository,
+	hideoutModuleProgressRepo *repository.UserHideoutModuleProgressRepository,
+	skillNodeProgressRepo *repository.UserSkillNodeProgressRepository,
+	blueprintProgressRepo *repository.UserBlueprintProgressRepository,
+) *ShareHandler {
+	return &ShareHandler{
+		shareLinkService:          shareLinkService,
+		userRepo:                  userRepo,
+		questProgressRepo:         questProgressRepo,
+		hideoutModuleProgressRepo: hideoutModuleProgressRepo,
+		skillNodeProgressRepo:     skillNodeProgressRepo,
+		blueprintProgressRepo:     blueprintProgressRepo,
+	}
+}
+
+// ShareLinkResponse is returned when a share link is created
+type ShareLinkResponse struct {
+	Token     string    `json:"token"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SharedProgressSnapshot is the read-only progress view served for a share token
+type SharedProgressSnapshot struct {
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Progress  struct {
+		Quests         []models.UserQuestProgress         `json:"quests"`
+		HideoutModules []models.UserHideoutModuleProgress `json:"hideout_modules"`
+		SkillNodes     []models.UserSkillNodeProgress     `json:"skill_nodes"`
+		Blueprints     []models.UserBlueprintProgress     `json:"blueprints"`
+	} `json:"progress"`
+}
+
+// CreateShareLink issues a signed, expiring link to the current user's progress
+// @Summary Create a progress share link
+// @Description Generate a signed token that lets anyone holding it view a read-only snapshot of your progress until it expires
+// @Tags progress
+// @Accept json
+// @Produce json
+// @Param share body map[string]int false "Optional expires_in_hours (capped at the server maximum)"
+// @Success 201 {object} ShareLinkResponse "Successfully created the share link"
+// @Failure 400 {object} ErrorResponse "Invalid input data"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Security BearerAuth
+// @Router /progress/share [post]
+func (h *ShareHandler) CreateShareLink(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	var req struct {
+		ExpiresInHours int `json:"expires_in_hours"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.ExpiresInHours < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_in_hours must be positive"})
+		return
+	}
+
+	token, expiresAt := h.shareLinkService.Sign(user.ID, time.Duration(req.ExpiresInHours)*time.Hour)
+
+	scheme := "https"
+	if c.Request.TLS == nil && c.GetHeader("X-Forwarded-Proto") != "https" {
+		scheme = "http"
+	}
+
+	c.JSON(http.StatusCreated, ShareLinkResponse{
+		Token:     token,
+		URL:       scheme + "://" + c.Request.Host + "/api/v1/shared/" + token,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// GetSharedProgress returns the progress snapshot for a share token
+// @Summary View shared progress
+// @Description Fetch a read-only snapshot of a user's progress using a share token. No authentication required.
+// @Tags progress
+// @Accept json
+// @Produce json
+// @Param token path string true "Share token"
+// @Success 200 {object} SharedProgressSnapshot "Successfully fetched shared progress"
+// @Failure 404 {object} ErrorResponse "Invalid share link"
+// @Failure 410 {object} ErrorResponse "Share link expired"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /shared/{token} [get]
+func (h *ShareHandler) GetSharedProgress(c *gin.Context) {
+	userID, expiresAt, err := h.shareLinkService.Verify(c.Param("token"))
+	if errors.Is(err, services.ErrShareTokenExpired) {
+		c.JSON(http.StatusGone, gin.H{"error": "Share link expired"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invalid share link"})
+		return
+	}
+
+	user, err := h.userRepo.FindByID(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invalid share link"})
+		return
+	}
+
+	snapshot := SharedProgressSnapshot{Username: user.Username, ExpiresAt: expiresAt}
+	if snapshot.Progress.Quests, err = h.questProgressRepo.FindByUserID(userID, false); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quest progress"})
+		return
+	}
+	if snapshot.Progress.HideoutModules, err = h.hideoutModuleProgressRepo.FindByUserID(userID, false); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hideout module progress"})
+		return
+	}
+	if snapshot.Progress.SkillNodes, err = h.skillNodeProgressRepo.FindByUserID(userID, false); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch skill node progress"})
+		return
+	}
+	if snapshot.Progress.Blueprints, err = h.blueprintProgressRepo.FindByUserID(userID, false); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch blueprint progress"})
+		return
+	}
+
+	// Snapshots change as the owner plays; let Discord/link unfurlers cache briefly only
+	c.Header("Cache-Control", "public, max-age=60")
+	c.JSON(http.StatusOK, snapshot)
+}
@@ -5,34 +5,47 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/middleware"
 	"github.com/mat/arcapi/internal/models"
 	"github.com/mat/arcapi/internal/repository"
 )
 
 type SkillNodeHandler struct {
-	repo *repository.SkillNodeRepository
+	repo                  *repository.SkillNodeRepository
+	skillNodeProgressRepo *repository.UserSkillNodeProgressRepository
 }
 
-func NewSkillNodeHandler(repo *repository.SkillNodeRepository) *SkillNodeHandler {
-	return &SkillNodeHandler{repo: repo}
+func NewSkillNodeHandler(repo *repository.SkillNodeRepository, skillNodeProgressRepo *repository.UserSkillNodeProgressRepository) *SkillNodeHandler {
+	return &SkillNodeHandler{repo: repo, skillNodeProgressRepo: skillNodeProgressRepo}
 }
 
 // List returns all skill nodes (paginated)
 // @Summary List skill nodes
-// @Description Fetch skill nodes with optional pagination. If ?all=true is passed, returns all skill nodes unpaginated.
+// @Description Fetch skill nodes with optional pagination. If ?all=true is passed, returns all skill nodes unpaginated. Supports PostgREST-style filters on external_id, name, impacted_skill, category, max_points and is_major (e.g. ?category=eq.Survival&is_major=is.true).
 // @Tags skill-nodes
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(20)
 // @Param all query bool false "Return all nodes" default(false)
+// @Param lang query string false "Resolve multilingual text to this language, falling back to English (e.g. de)"
 // @Success 200 {object} PaginatedResponse{data=[]models.SkillNode} "Successfully fetched skill nodes"
+// @Failure 400 {object} ErrorResponse "Invalid filter"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /skill-nodes [get]
 func (h *SkillNodeHandler) List(c *gin.Context) {
+	filters, ok := parseListFilters(c, repository.SkillNodeFilterColumns)
+	if !ok {
+		return
+	}
+	lang, ok := requestLanguage(c)
+	if !ok {
+		return
+	}
+
 	if c.Query("all") == "true" {
-		h.ListAll(c)
+		h.ListAll(c, filters, lang)
 		return
 	}
 
@@ -45,20 +58,20 @@ func (h *SkillNodeHandler) List(c *gin.Context) {
 		}
 	}
 	if l := c.Query("limit"); l != "" {
-		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= middleware.PageLimit(c) {
 			limit = parsed
 		}
 	}
 
 	offset := (page - 1) * limit
-	skillNodes, count, err := h.repo.FindAll(offset, limit)
+	skillNodes, count, err := h.repo.FindFiltered(filters, offset, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch skill nodes"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"data": skillNodes,
+		"data": localizeAll(skillNodes, lang, localizeSkillNode),
 		"pagination": gin.H{
 			"page":  page,
 			"limit": limit,
@@ -67,19 +80,83 @@ func (h *SkillNodeHandler) List(c *gin.Context) {
 	})
 }
 
-func (h *SkillNodeHandler) ListAll(c *gin.Context) {
-	skillNodes, count, err := h.repo.FindAll(0, 999999)
+func (h *SkillNodeHandler) ListAll(c *gin.Context, filters []repository.Filter, lang string) {
+	skillNodes, count, err := h.repo.FindFiltered(filters, 0, 999999)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch skill nodes"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"data":  skillNodes,
+		"data":  localizeAll(skillNodes, lang, localizeSkillNode),
 		"total": count,
 	})
 }
 
+// SkillPathResponse lists the nodes to unlock, in order, to reach a target skill node
+type SkillPathResponse struct {
+	Target         string             `json:"target"`
+	Nodes          []models.SkillNode `json:"nodes"`
+	PointsRequired int                `json:"points_required"`
+}
+
+// Path returns the minimal set of nodes to unlock to reach a target skill node
+// @Summary Solve skill tree path
+// @Description Walks prerequisiteNodeIds to find the fewest locked nodes, in unlock order, needed to reach the target node. Nodes the authenticated user already unlocked are free. Each node costs one point to unlock.
+// @Tags skill-nodes
+// @Accept json
+// @Produce json
+// @Param target query string true "Target skill node external ID"
+// @Success 200 {object} SkillPathResponse "Nodes to unlock and total points"
+// @Failure 400 {object} ErrorResponse "Missing target"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Target not found or unreachable"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /skill-nodes/path [get]
+func (h *SkillNodeHandler) Path(c *gin.Context) {
+	target := c.Query("target")
+	if target == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "target is required"})
+		return
+	}
+
+	nodes, err := h.repo.ListAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch skill nodes"})
+		return
+	}
+
+	unlocked := make(map[string]bool)
+	val, _ := c.Get("user")
+	if user, ok := val.(*models.User); ok && h.skillNodeProgressRepo != nil {
+		progress, err := h.skillNodeProgressRepo.FindByUserID(user.ID, false)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch skill node progress"})
+			return
+		}
+		for _, p := range progress {
+			if p.Unlocked && p.SkillNodeExternalID != "" {
+				unlocked[p.SkillNodeExternalID] = true
+			}
+		}
+	}
+
+	path, ok := solveSkillPath(nodes, unlocked, target)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Skill node not found or unreachable"})
+		return
+	}
+	if path == nil {
+		path = []models.SkillNode{}
+	}
+
+	c.JSON(http.StatusOK, SkillPathResponse{
+		Target:         target,
+		Nodes:          path,
+		PointsRequired: len(path),
+	})
+}
+
 // Get returns a single skill node by ID
 // @Summary Get a single skill node
 // @Description Fetch a skill node by its numeric ID
@@ -87,6 +164,7 @@ func (h *SkillNodeHandler) ListAll(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Skill Node ID"
+// @Param lang query string false "Resolve multilingual text to this language, falling back to English (e.g. de)"
 // @Success 200 {object} models.SkillNode "Successfully fetched the skill node"
 // @Failure 400 {object} ErrorResponse "Invalid skill node ID"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
@@ -94,6 +172,11 @@ func (h *SkillNodeHandler) ListAll(c *gin.Context) {
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /skill-nodes/{id} [get]
 func (h *SkillNodeHandler) Get(c *gin.Context) {
+	lang, ok := requestLanguage(c)
+	if !ok {
+		return
+	}
+
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -107,12 +190,17 @@ func (h *SkillNodeHandler) Get(c *gin.Context) {
 		return
 	}
 
+	if lang != "" {
+		localized := localizeSkillNode(*skillNode, lang)
+		skillNode = &localized
+	}
+
 	c.JSON(http.StatusOK, skillNode)
 }
 
 // Create adds a new skill node
 // @Summary Create a skill node
-// @Description Add a new skill node to the database
+// @Description Add a new skill node to the database. Sync won't overwrite it; see /admin/sync/conflicts.
 // @Tags skill-nodes
 // @Accept json
 // @Produce json
@@ -136,6 +224,7 @@ func (h *SkillNodeHandler) Create(c *gin.Context) {
 		return
 	}
 
+	skillNode.ManualOverride = true
 	err := h.repo.Create(&skillNode)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create skill node"})
@@ -147,7 +236,7 @@ func (h *SkillNodeHandler) Create(c *gin.Context) {
 
 // Update modifies an existing skill node
 // @Summary Update a skill node
-// @Description Update an existing skill node by its ID
+// @Description Update an existing skill node by its ID. Sync stops overwriting it, listing upstream changes to it under /admin/sync/conflicts instead.
 // @Tags skill-nodes
 // @Accept json
 // @Produce json
@@ -175,6 +264,7 @@ func (h *SkillNodeHandler) Update(c *gin.Context) {
 	}
 
 	skillNode.ID = uint(id)
+	skillNode.ManualOverride = true
 	err = h.repo.Update(&skillNode)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update skill node"})
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"container/heap"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+// skillNodePrerequisites reads a node's prerequisite external IDs, falling back to the raw
+// upstream data for nodes created before the column was populated
+func skillNodePrerequisites(node models.SkillNode) []string {
+	if ids := stringList(node.PrerequisiteNodeIds, "prerequisite_node_ids"); len(ids) > 0 {
+		return ids
+	}
+	return stringList(node.Data, "prerequisiteNodeIds")
+}
+
+// solveSkillPath returns the cheapest ordered set of locked nodes to unlock so that target
+// becomes unlocked, root first. A node is reachable once any one of its prerequisites is
+// unlocked, as in the in-game tree, so this is a shortest path where locked nodes cost one
+// point and unlocked nodes are free. ok is false if the target can't be reached.
+func solveSkillPath(nodes []models.SkillNode, unlocked map[string]bool, target string) ([]models.SkillNode, bool) {
+	byID := make(map[string]models.SkillNode, len(nodes))
+	dependents := make(map[string][]string)
+	for _, node := range nodes {
+		byID[node.ExternalID] = node
+	}
+	if _, ok := byID[target]; !ok {
+		return nil, false
+	}
+
+	cost := func(id string) int {
+		if unlocked[id] {
+			return 0
+		}
+		return 1
+	}
+
+	dist := make(map[string]int, len(nodes))
+	parent := make(map[string]string, len(nodes))
+	queue := &skillPathQueue{}
+	for _, node := range nodes {
+		prereqs := skillNodePrerequisites(node)
+		known := 0
+		for _, prereq := range prereqs {
+			if _, ok := byID[prereq]; ok {
+				dependents[prereq] = append(dependents[prereq], node.ExternalID)
+				known++
+			}
+		}
+		// Roots and already-unlocked nodes are where paths may start
+		if known == 0 || unlocked[node.ExternalID] {
+			dist[node.ExternalID] = cost(node.ExternalID)
+			heap.Push(queue, skillPathEntry{id: node.ExternalID, dist: dist[node.ExternalID]})
+		}
+	}
+
+	done := make(map[string]bool, len(nodes))
+	for queue.Len() > 0 {
+		entry := heap.Pop(queue).(skillPathEntry)
+		if done[entry.id] {
+			continue
+		}
+		done[entry.id] = true
+		if entry.id == target {
+			break
+		}
+		for _, next := range dependents[entry.id] {
+			d := entry.dist + cost(next)
+			if current, seen := dist[next]; !seen || d < current {
+				dist[next] = d
+				parent[next] = entry.id
+				heap.Push(queue, skillPathEntry{id: next, dist: d})
+			}
+		}
+	}
+	if !done[target] {
+		return nil, false
+	}
+
+	var path []models.SkillNode
+	for id := target; ; {
+		if !unlocked[id] {
+			path = append(path, byID[id])
+		}
+		prev, ok := parent[id]
+		if !ok {
+			break
+		}
+		id = prev
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, true
+}
+
+type skillPathEntry struct {
+	id   string
+	dist int
+}
+
+// skillPathQueue is a min-heap of path entries ordered by distance, then ID for stable output
+type skillPathQueue []skillPathEntry
+
+func (q skillPathQueue) Len() int { return len(q) }
+func (q skillPathQueue) Less(i, j int) bool {
+	if q[i].dist != q[j].dist {
+		return q[i].dist < q[j].dist
+	}
+	return q[i].id < q[j].id
+}
+func (q skillPathQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *skillPathQueue) Push(x interface{}) { *q = append(*q, x.(skillPathEntry)) }
+func (q *skillPathQueue) Pop() interface{} {
+	old := *q
+	entry := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return entry
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+func skillNode(id string, prereqs ...string) models.SkillNode {
+	list := make([]interface{}, len(prereqs))
+	for i, p := range prereqs {
+		list[i] = p
+	}
+	return models.SkillNode{ExternalID: id, PrerequisiteNodeIds: models.JSONB{"prerequisite_node_ids": list}}
+}
+
+func pathIDs(path []models.SkillNode) []string {
+	ids := make([]string, len(path))
+	for i, n := range path {
+		ids[i] = n.ExternalID
+	}
+	return ids
+}
+
+func TestSolveSkillPath(t *testing.T) {
+	// root -> a -> b -> target, and root -> c -> target (shorter)
+	nodes := []models.SkillNode{
+		skillNode("root"),
+		skillNode("a", "root"),
+		skillNode("b", "a"),
+		skillNode("c", "root"),
+		skillNode("target", "b", "c"),
+	}
+
+	path, ok := solveSkillPath(nodes, nil, "target")
+	if !ok {
+		t.Fatal("expected target to be reachable")
+	}
+	if got := pathIDs(path); len(got) != 3 || got[0] != "root" || got[1] != "c" || got[2] != "target" {
+		t.Errorf("path = %v, want [root c target]", got)
+	}
+
+	// With a and b already unlocked, going through b is cheaper
+	path, _ = solveSkillPath(nodes, map[string]bool{"root": true, "a": true, "b": true}, "target")
+	if got := pathIDs(path); len(got) != 1 || got[0] != "target" {
+		t.Errorf("path = %v, want [target]", got)
+	}
+
+	if _, ok := solveSkillPath(nodes, nil, "missing"); ok {
+		t.Error("unknown target should not be reachable")
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/repository"
+	"github.com/mat/arcapi/internal/services"
+)
+
+type StatsHandler struct {
+	statsRepo    *repository.StatsRepository
+	statsService *services.StatsService
+}
+
+func NewStatsHandler(statsRepo *repository.StatsRepository, statsService *services.StatsService) *StatsHandler {
+	return &StatsHandler{statsRepo: statsRepo, statsService: statsService}
+}
+
+// QuestStats returns how many users completed each quest
+// @Summary Get quest completion stats
+// @Description Fetch per-quest completion counts. Refreshed periodically by a background job, see refreshed_at.
+// @Tags stats
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string][]models.QuestCompletionStat "Successfully fetched quest stats"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /stats/quests [get]
+func (h *StatsHandler) QuestStats(c *gin.Context) {
+	stats, err := h.statsRepo.ListQuestStats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quest stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": stats})
+}
+
+// ListJobs returns the refresh status of every background aggregation job
+// @Summary List background jobs
+// @Description Fetch the status of the leaderboard/stats refresh jobs, including last run time, duration and error
+// @Tags management
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string][]services.JobStatus "Successfully fetched job statuses"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Admin access required"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/jobs [get]
+func (h *StatsHandler) ListJobs(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": h.statsService.Jobs()})
+}
+
+// RunJob triggers a background aggregation job immediately
+// @Summary Run a background job
+// @Description Start a leaderboard/stats refresh job now instead of waiting for its schedule
+// @Tags management
+// @Accept json
+// @Produce json
+// @Param name path string true "Job name (leaderboard, quest_stats)"
+// @Success 202 {object} MessageResponse "Job started"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Admin access required"
+// @Failure 404 {object} ErrorResponse "Job not found"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/jobs/{name}/run [post]
+func (h *StatsHandler) RunJob(c *gin.Context) {
+	name := c.Param("name")
+	if !h.statsService.HasJob(name) {
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrJobNotFound.Error()})
+		return
+	}
+
+	// Errors are recorded on the job status and visible via GET /admin/jobs
+	go func() { _ = h.statsService.RunJob(name) }()
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Job started"})
+}
@@ -1,10 +1,16 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/middleware"
+	"github.com/mat/arcapi/internal/models"
 	"github.com/mat/arcapi/internal/services"
+	"gorm.io/gorm"
 )
 
 type SyncHandler struct {
@@ -55,25 +61,160 @@ func (h *SyncHandler) ForceSync(c *gin.Context) {
 	})
 }
 
+// SyncEntity triggers a sync of a single dataset
+// @Summary Sync one dataset
+// @Description Re-sync a single dataset from GitHub, e.g. after an upstream fix, instead of waiting for or forcing a full sync. Runs in the background and is recorded in the sync history with its entity. The data version is left as it is, since the other datasets haven't been synced.
+// @Tags sync
+// @Produce json
+// @Param entity path string true "Dataset" Enums(quests, items, skill-nodes, hideout-modules, bots, maps, traders, projects)
+// @Success 202 {object} map[string]string "Sync triggered successfully"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 404 {object} ErrorResponse "Unknown dataset"
+// @Failure 409 {object} ErrorResponse "Sync already running"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/sync/{entity} [post]
+func (h *SyncHandler) SyncEntity(c *gin.Context) {
+	// Paths use dashes, sync steps use the table names
+	entity := strings.ReplaceAll(c.Param("entity"), "-", "_")
+
+	err := h.syncService.SyncEntity(entity)
+	if errors.Is(err, services.ErrUnknownSyncEntity) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown dataset, expected quests, items, skill-nodes, hideout-modules, bots, maps, traders or projects"})
+		return
+	}
+	if errors.Is(err, services.ErrSyncRunning) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Sync is already running. Please wait for it to complete.",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to trigger sync"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Sync triggered successfully",
+		"status":  "running",
+		"entity":  c.Param("entity"),
+	})
+}
+
 // SyncStatus returns the current sync status
 // SyncStatus returns the current sync status
 // @Summary Get sync status
-// @Description Check if a synchronization process is currently running. Only admins can check status.
+// @Description Check if a synchronization process is currently running, and how the latest recorded run went. Only admins can check status.
 // @Tags sync
 // @Accept json
 // @Produce json
-// @Success 200 {object} map[string]bool "Sync status"
+// @Success 200 {object} map[string]interface{} "Sync status"
 // @Failure 401 {object} ErrorResponse "Not authenticated"
 // @Failure 403 {object} ErrorResponse "Not an administrator"
 // @Security ApiKeyAuth
 // @Security BearerAuth
 // @Router /admin/sync/status [get]
 func (h *SyncHandler) SyncStatus(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"is_running": h.syncService.IsRunning(),
+		"last_run":   nil,
+	}
+	if runs, _, err := h.syncService.Runs("", 0, 1); err == nil && len(runs) > 0 {
+		response["last_run"] = runs[0]
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// History lists recorded sync runs
+// @Summary Get sync history
+// @Description List recorded sync runs, newest first: what triggered each (startup, scheduled or manual) and, for a partial sync, the entity it synced, when it started and finished, its status (running, succeeded, failed or cancelled), the source commit, per-entity counts of rows read, rows that failed to save and rows kept because they were edited through the API, and its errors. A succeeded run can still list errors for rows or entity types that failed. Runs are kept for SYNC_RUN_RETENTION_DAYS.
+// @Tags sync
+// @Produce json
+// @Param status query string false "Only runs with this status" Enums(running, succeeded, failed, cancelled)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Runs per page" default(20)
+// @Success 200 {object} PaginatedResponse{data=[]models.SyncRun} "Sync runs"
+// @Failure 400 {object} ErrorResponse "Invalid status"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/sync/history [get]
+func (h *SyncHandler) History(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", models.SyncRunRunning, models.SyncRunSucceeded, models.SyncRunFailed, models.SyncRunCancelled:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be running, succeeded, failed or cancelled"})
+		return
+	}
+
+	page := 1
+	limit := 20
+
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= middleware.PageLimit(c) {
+			limit = parsed
+		}
+	}
+
+	runs, count, err := h.syncService.Runs(status, (page-1)*limit, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sync history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": runs,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": count,
+		},
 	})
 }
 
+// GetRun returns one recorded sync run
+// @Summary Get a sync run
+// @Tags sync
+// @Produce json
+// @Param id path int true "Sync run ID"
+// @Success 200 {object} models.SyncRun "Sync run"
+// @Failure 400 {object} ErrorResponse "Invalid sync run ID"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 404 {object} ErrorResponse "Sync run not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/sync/history/{id} [get]
+func (h *SyncHandler) GetRun(c *gin.Context) {
+	id, err := parseUint(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sync run ID"})
+		return
+	}
+
+	run, err := h.syncService.FindRun(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sync run not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sync run"})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
 // GetSnapshot returns a full data snapshot for client hydration
 // GetSnapshot returns a full data snapshot for client hydration
 // @Summary Get data snapshot
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/middleware"
+	"github.com/mat/arcapi/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Ways to resolve a sync conflict
+const (
+	syncConflictKeepLocal    = "local"
+	syncConflictKeepUpstream = "upstream"
+)
+
+type SyncConflictHandler struct {
+	repo *repository.SyncConflictRepository
+}
+
+func NewSyncConflictHandler(repo *repository.SyncConflictRepository) *SyncConflictHandler {
+	return &SyncConflictHandler{repo: repo}
+}
+
+// ResolveSyncConflictRequest picks which side of a sync conflict wins
+type ResolveSyncConflictRequest struct {
+	Keep string `json:"keep" binding:"required" enums:"local,upstream" example:"local"`
+}
+
+// List returns conflicts between content edited through the API and upstream
+// @Summary List sync conflicts
+// @Description Quests, items, skill nodes and hideout modules created, edited or imported through the API are no longer overwritten by sync. When upstream changes one of them anyway, it's listed here with the row as it is (local) and as sync would have written it (upstream), most recently detected first. A conflict goes away by itself once upstream matches the row's data again.
+// @Tags sync
+// @Produce json
+// @Param entity query string false "Only conflicts of this dataset" Enums(quests, items, skill-nodes, hideout-modules)
+// @Param dismissed query bool false "Include conflicts resolved by keeping the local edits"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Conflicts per page" default(20)
+// @Success 200 {object} PaginatedResponse{data=[]models.SyncConflict} "Sync conflicts"
+// @Failure 400 {object} ErrorResponse "Invalid dataset"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/sync/conflicts [get]
+func (h *SyncConflictHandler) List(c *gin.Context) {
+	// Paths use dashes, conflicts use the table names
+	entity := strings.ReplaceAll(c.Query("entity"), "-", "_")
+	switch entity {
+	case "", "quests", "items", "skill_nodes", "hideout_modules":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "entity must be quests, items, skill-nodes or hideout-modules"})
+		return
+	}
+	dismissed, _ := strconv.ParseBool(c.Query("dismissed"))
+
+	page := 1
+	limit := 20
+
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= middleware.PageLimit(c) {
+			limit = parsed
+		}
+	}
+
+	conflicts, count, err := h.repo.List(entity, dismissed, (page-1)*limit, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sync conflicts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": conflicts,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": count,
+		},
+	})
+}
+
+// Resolve settles a sync conflict
+// @Summary Resolve a sync conflict
+// @Description Keep the local edits, dismissing the conflict until upstream changes the row again, or take upstream, letting sync overwrite the row again. Upstream is applied by the next sync of the dataset, which POST /admin/sync/{entity} starts right away.
+// @Tags sync
+// @Accept json
+// @Produce json
+// @Param id path int true "Sync conflict ID"
+// @Param request body ResolveSyncConflictRequest true "Side to keep"
+// @Success 200 {object} map[string]string "Conflict resolved"
+// @Failure 400 {object} ErrorResponse "Invalid ID or side"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 404 {object} ErrorResponse "Sync conflict not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/sync/conflicts/{id}/resolve [post]
+func (h *SyncConflictHandler) Resolve(c *gin.Context) {
+	id, err := parseUint(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sync conflict ID"})
+		return
+	}
+
+	var req ResolveSyncConflictRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Keep != syncConflictKeepLocal && req.Keep != syncConflictKeepUpstream {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "keep must be local or upstream"})
+		return
+	}
+
+	conflict, err := h.repo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sync conflict not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sync conflict"})
+		return
+	}
+
+	if req.Keep == syncConflictKeepLocal {
+		err = h.repo.Dismiss(conflict)
+	} else {
+		err = h.repo.ReleaseOverride(conflict)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve sync conflict"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Sync conflict resolved",
+		"kept":    req.Keep,
+	})
+}
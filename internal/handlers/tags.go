@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/logging"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+	"gorm.io/gorm"
+)
+
+var tagLog = logging.For("tags")
+
+var (
+	tagSlugPattern  = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	tagColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+)
+
+// tagSlugMaxLength matches the slug column
+const tagSlugMaxLength = 50
+
+// Past this many entities, loading every tag of the type is cheaper than a huge IN list
+const tagLookupMaxIDs = 500
+
+type TagHandler struct {
+	tagRepo   *repository.TagRepository
+	itemRepo  *repository.ItemRepository
+	questRepo *repository.QuestRepository
+}
+
+func NewTagHandler(tagRepo *repository.TagRepository, itemRepo *repository.ItemRepository, questRepo *repository.QuestRepository) *TagHandler {
+	return &TagHandler{tagRepo: tagRepo, itemRepo: itemRepo, questRepo: questRepo}
+}
+
+func validTagSlug(slug string) bool {
+	return len(slug) <= tagSlugMaxLength && tagSlugPattern.MatchString(slug)
+}
+
+// entityExists reports whether entityID names an existing entity of entityType; ok is
+// false for entity types that can't be tagged
+func (h *TagHandler) entityExists(entityType, entityID string) (exists, ok bool) {
+	var err error
+	switch entityType {
+	case models.TagEntityItem:
+		_, err = h.itemRepo.FindByExternalID(entityID)
+	case models.TagEntityQuest:
+		_, err = h.questRepo.FindByExternalID(entityID)
+	default:
+		return false, false
+	}
+	return err == nil, true
+}
+
+// List returns every tag
+// @Summary List tags
+// @Description Fetch the admin-managed tags that can be applied to items and quests. Items and quests carry the slugs of their tags in tags, and their list endpoints filter by them with ?tag=.
+// @Tags tags
+// @Produce json
+// @Success 200 {object} map[string][]models.Tag "Successfully fetched tags"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /tags [get]
+func (h *TagHandler) List(c *gin.Context) {
+	tags, err := h.tagRepo.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tags})
+}
+
+// Save creates a tag or replaces its settings
+// @Summary Create or update a tag
+// @Description Create a tag or replace its name, description and color. The slug is lowercase letters, digits and dashes (e.g. hideout-critical) and can't be changed once created.
+// @Tags management
+// @Accept json
+// @Produce json
+// @Param slug path string true "Tag slug"
+// @Param tag body map[string]string true "name, description and color (#rrggbb)"
+// @Success 200 {object} models.Tag "Successfully updated the tag"
+// @Success 201 {object} models.Tag "Successfully created the tag"
+// @Failure 400 {object} ErrorResponse "Invalid input data"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/tags/{slug} [put]
+func (h *TagHandler) Save(c *gin.Context) {
+	slug := c.Param("slug")
+	if !validTagSlug(slug) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "slug must be up to 50 lowercase letters, digits and dashes"})
+		return
+	}
+
+	var req struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+		Color       string `json:"color"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Color != "" && !tagColorPattern.MatchString(req.Color) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "color must be a hex color like #ff9900"})
+		return
+	}
+
+	tag, err := h.tagRepo.FindBySlug(slug)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save tag"})
+		return
+	}
+
+	status := http.StatusOK
+	if tag == nil {
+		tag = &models.Tag{Slug: slug}
+		status = http.StatusCreated
+	}
+	tag.Name = strings.TrimSpace(req.Name)
+	tag.Description = req.Description
+	tag.Color = req.Color
+
+	if status == http.StatusCreated {
+		err = h.tagRepo.Create(tag)
+	} else {
+		err = h.tagRepo.Update(tag)
+	}
+	if err != nil {
+		tagLog.Error("Failed to save tag", "slug", slug, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save tag"})
+		return
+	}
+
+	c.JSON(status, tag)
+}
+
+// Delete removes a tag
+// @Summary Delete a tag
+// @Description Delete a tag and remove it from every item and quest it was applied to.
+// @Tags management
+// @Param slug path string true "Tag slug"
+// @Success 204 "Successfully deleted the tag"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 404 {object} ErrorResponse "Tag not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/tags/{slug} [delete]
+func (h *TagHandler) Delete(c *gin.Context) {
+	tag, ok := h.findTag(c)
+	if !ok {
+		return
+	}
+
+	if err := h.tagRepo.Delete(tag.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete tag"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// Assign applies a tag to an item or quest
+// @Summary Tag an item or quest
+// @Description Apply a tag to an item or quest by its external ID. Tagging an entity that already carries the tag does nothing. Tags are kept apart from the synced data, so they survive syncs.
+// @Tags management
+// @Param slug path string true "Tag slug"
+// @Param entity_type path string true "Entity type" Enums(item, quest)
+// @Param entity_id path string true "External ID of the entity"
+// @Success 204 "Successfully tagged the entity"
+// @Failure 400 {object} ErrorResponse "Invalid entity type"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 404 {object} ErrorResponse "Tag or entity not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/tags/{slug}/{entity_type}/{entity_id} [put]
+func (h *TagHandler) Assign(c *gin.Context) {
+	entityType, entityID := c.Param("entity_type"), c.Param("entity_id")
+	exists, ok := h.entityExists(entityType, entityID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "entity_type must be item or quest"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Entity not found"})
+		return
+	}
+
+	tag, ok := h.findTag(c)
+	if !ok {
+		return
+	}
+
+	if err := h.tagRepo.Assign(tag.ID, entityType, entityID); err != nil {
+		tagLog.Error("Failed to tag entity", "entity_type", entityType, "entity_id", entityID, "tag", tag.Slug, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to tag entity"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// Unassign removes a tag from an item or quest
+// @Summary Untag an item or quest
+// @Description Remove a tag from an item or quest by its external ID.
+// @Tags management
+// @Param slug path string true "Tag slug"
+// @Param entity_type path string true "Entity type" Enums(item, quest)
+// @Param entity_id path string true "External ID of the entity"
+// @Success 204 "Successfully untagged the entity"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 404 {object} ErrorResponse "Tag not found or not applied to the entity"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/tags/{slug}/{entity_type}/{entity_id} [delete]
+func (h *TagHandler) Unassign(c *gin.Context) {
+	tag, ok := h.findTag(c)
+	if !ok {
+		return
+	}
+
+	removed, err := h.tagRepo.Unassign(tag.ID, c.Param("entity_type"), c.Param("entity_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to untag entity"})
+		return
+	}
+	if removed == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tag is not applied to the entity"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// findTag loads the tag named by the slug path parameter, writing a 404 or 500 and
+// returning false if it can't
+func (h *TagHandler) findTag(c *gin.Context) (*models.Tag, bool) {
+	tag, err := h.tagRepo.FindBySlug(c.Param("slug"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tag"})
+		return nil, false
+	}
+	return tag, true
+}
+
+// parseTagFilter turns ?tag=slug[,slug] on a list endpoint into a filter on external_id
+// matching the entities carrying any of the tags. It returns nil without the parameter, and
+// writes a 500 and returns false if the tagged entities can't be loaded.
+func parseTagFilter(c *gin.Context, tagRepo *repository.TagRepository, entityType string) (*repository.Filter, bool) {
+	var slugs []string
+	for _, raw := range c.QueryArray("tag") {
+		for _, slug := range strings.Split(raw, ",") {
+			if slug = strings.TrimSpace(slug); slug != "" {
+				slugs = append(slugs, slug)
+			}
+		}
+	}
+	if len(slugs) == 0 || tagRepo == nil {
+		return nil, true
+	}
+
+	ids, err := tagRepo.EntityIDsWithTags(entityType, slugs)
+	if err != nil {
+		tagLog.Error("Failed to load tagged entities", "entity_type", entityType, "tags", slugs, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to filter by tag"})
+		return nil, false
+	}
+	return &repository.Filter{Column: "external_id", Operator: "in", Value: ids}, true
+}
+
+// loadTagSlugs returns the tag slugs of the given entities by external ID. Tags only
+// decorate a response, so a failure is logged and nothing is returned rather than failing
+// the request.
+func loadTagSlugs(tagRepo *repository.TagRepository, entityType string, externalIDs []string) map[string][]string {
+	if tagRepo == nil || len(externalIDs) == 0 {
+		return nil
+	}
+	if len(externalIDs) > tagLookupMaxIDs {
+		externalIDs = nil
+	}
+	slugs, err := tagRepo.SlugsByEntity(entityType, externalIDs)
+	if err != nil {
+		tagLog.Warn("Failed to load tags", "entity_type", entityType, "error", err)
+		return nil
+	}
+	return slugs
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidTagSlug(t *testing.T) {
+	for _, slug := range []string{"event", "keep-for-quests", "tier-2", strings.Repeat("a", tagSlugMaxLength)} {
+		if !validTagSlug(slug) {
+			t.Errorf("expected %q to be a valid slug", slug)
+		}
+	}
+	for _, slug := range []string{"", "Event", "keep for quests", "-event", "event-", "keep--quests", "hideout_critical", strings.Repeat("a", tagSlugMaxLength+1)} {
+		if validTagSlug(slug) {
+			t.Errorf("expected %q to be rejected", slug)
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+// maxTeamMembers caps how many users can join a single team
+const maxTeamMembers = 10
+
+type TeamHandler struct {
+	teamRepo                  *repository.TeamRepository
+	questProgressRepo         *repository.UserQuestProgressRepository
+	hideoutModuleProgressRepo *repository.UserHideoutModuleProgressRepository
+}
+
+func NewTeamHandler(
+	teamRepo *repository.TeamRepository,
+	questProgressRepo *repository.UserQuestProgressRepository,
+	hideoutModuleProgressRepo *repository.UserHideoutModuleProgressRepository,
+) *TeamHandler {
+	return &TeamHandler{
+		teamRepo:                  teamRepo,
+		questProgressRepo:         questProgressRepo,
+		hideoutModuleProgressRepo: hideoutModuleProgressRepo,
+	}
+}
+
+// TeamMemberProgress is one member's slice of the shared team progress view
+type TeamMemberProgress struct {
+	UserID          uint           `json:"user_id"`
+	Username        string         `json:"username"`
+	Role            string         `json:"role"`
+	LastSeenAt      *time.Time     `json:"last_seen_at,omitempty"` // Only for members who made it public
+	CompletedQuests []string       `json:"completed_quests"`
+	HideoutModules  map[string]int `json:"hideout_modules"`
+}
+
+// TeamProgressResponse aggregates progress across all members of a team
+type TeamProgressResponse struct {
+	TeamID  uint                 `json:"team_id"`
+	Name    string               `json:"name"`
+	Members []TeamMemberProgress `json:"members"`
+	// QuestCompletions counts how many members completed each quest
+	QuestCompletions map[string]int `json:"quest_completions"`
+	// HideoutMinLevels is the lowest level any member has reached per module
+	HideoutMinLevels map[string]int `json:"hideout_min_levels"`
+}
+
+func generateInviteCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString(b)), nil
+}
+
+// Create creates a new team owned by the current user
+// @Summary Create a team
+// @Description Create a team and become its owner. Share the returned invite code with squadmates.
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param team body map[string]string true "Team name"
+// @Success 201 {object} models.Team "Successfully created the team"
+// @Failure 400 {object} ErrorResponse "Invalid input data"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /teams [post]
+func (h *TeamHandler) Create(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 64 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must be between 1 and 64 characters"})
+		return
+	}
+
+	code, err := generateInviteCode()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate invite code"})
+		return
+	}
+
+	team := &models.Team{Name: name, OwnerID: user.ID, InviteCode: code}
+	if err := h.teamRepo.Create(team); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create team"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, team)
+}
+
+// List returns the teams the current user belongs to
+// @Summary List my teams
+// @Description Fetch all teams the authenticated user is a member of
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string][]models.Team "Successfully fetched teams"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /teams [get]
+func (h *TeamHandler) List(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	teams, err := h.teamRepo.FindByUserID(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch teams"})
+		return
+	}
+
+	// Only owners can hand out the invite code
+	for i := range teams {
+		if teams[i].OwnerID != user.ID {
+			teams[i].InviteCode = ""
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": teams})
+}
+
+// Join adds the current user to a team using its invite code
+// @Summary Join a team
+// @Description Join a team using an invite code shared by its owner
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param invite body map[string]string true "Invite code"
+// @Success 200 {object} models.Team "Successfully joined the team"
+// @Failure 400 {object} ErrorResponse "Invalid input data"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Invalid invite code"
+// @Failure 409 {object} ErrorResponse "Already a member or team is full"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /teams/join [post]
+func (h *TeamHandler) Join(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	var req struct {
+		InviteCode string `json:"invite_code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	team, err := h.teamRepo.FindByInviteCode(strings.ToUpper(strings.TrimSpace(req.InviteCode)))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invalid invite code"})
+		return
+	}
+
+	if _, err := h.teamRepo.Join(team.ID, user.ID, maxTeamMembers); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrAlreadyTeamMember):
+			c.JSON(http.StatusConflict, gin.H{"error": "Already a member of this team"})
+		case errors.Is(err, repository.ErrTeamFull):
+			c.JSON(http.StatusConflict, gin.H{"error": "Team is full"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join team"})
+		}
+		return
+	}
+
+	team.InviteCode = ""
+	c.JSON(http.StatusOK, team)
+}
+
+// Leave removes the current user from a team; the owner leaving disbands it
+// @Summary Leave a team
+// @Description Leave a team. If the owner leaves, the team is deleted.
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path int true "Team ID"
+// @Success 200 {object} map[string]string "Successfully left the team"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Team not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /teams/{id}/members/me [delete]
+func (h *TeamHandler) Leave(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	member, err := h.teamRepo.FindMember(uint(id), user.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		return
+	}
+
+	if member.Role == models.TeamRoleOwner {
+		err = h.teamRepo.Delete(uint(id))
+	} else {
+		err = h.teamRepo.RemoveMember(uint(id), user.ID)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave team"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left team"})
+}
+
+// GetProgress returns the shared progress view for a team
+// @Summary Get team progress
+// @Description Aggregate quest and hideout progress for every member of a team. Only members can view it.
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param id path int true "Team ID"
+// @Success 200 {object} TeamProgressResponse "Successfully fetched team progress"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Team not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /teams/{id}/progress [get]
+func (h *TeamHandler) GetProgress(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	// Non-members get the same 404 as a missing team so team IDs can't be probed
+	if _, err := h.teamRepo.FindMember(uint(id), user.ID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		return
+	}
+
+	team, err := h.teamRepo.FindByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		return
+	}
+
+	resp := TeamProgressResponse{
+		TeamID:           team.ID,
+		Name:             team.Name,
+		Members:          make([]TeamMemberProgress, 0, len(team.Members)),
+		QuestCompletions: make(map[string]int),
+		HideoutMinLevels: make(map[string]int),
+	}
+
+	for i, member := range team.Members {
+		entry := TeamMemberProgress{
+			UserID:          member.UserID,
+			Role:            string(member.Role),
+			CompletedQuests: []string{},
+			HideoutModules:  make(map[string]int),
+		}
+		if member.User != nil {
+			entry.Username = member.User.Username
+			if member.User.LastSeenPublic {
+				entry.LastSeenAt = member.User.LastSeenAt
+			}
+		}
+
+		quests, err := h.questProgressRepo.FindByUserID(member.UserID, false)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quest progress"})
+			return
+		}
+		for _, qp := range quests {
+			if qp.Completed && qp.QuestExternalID != "" {
+				entry.CompletedQuests = append(entry.CompletedQuests, qp.QuestExternalID)
+				resp.QuestCompletions[qp.QuestExternalID]++
+			}
+		}
+
+		modules, err := h.hideoutModuleProgressRepo.FindByUserID(member.UserID, false)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hideout progress"})
+			return
+		}
+		for _, mp := range modules {
+			if mp.HideoutModuleExternalID != "" {
+				entry.HideoutModules[mp.HideoutModuleExternalID] = mp.Level
+			}
+		}
+
+		resp.Members = append(resp.Members, entry)
+
+		// A module missing from a member's progress counts as level 0 for that member
+		if i == 0 {
+			for moduleID, level := range entry.HideoutModules {
+				resp.HideoutMinLevels[moduleID] = level
+			}
+			continue
+		}
+		for moduleID, level := range resp.HideoutMinLevels {
+			if memberLevel, ok := entry.HideoutModules[moduleID]; !ok || memberLevel < level {
+				resp.HideoutMinLevels[moduleID] = memberLevel
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
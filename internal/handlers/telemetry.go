@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/services"
+)
+
+// maxTelemetryBodyBytes is far above any valid batch; larger bodies are rejected unread
+const maxTelemetryBodyBytes = 32 * 1024
+
+type TelemetryHandler struct {
+	telemetryService *services.TelemetryService
+}
+
+func NewTelemetryHandler(telemetryService *services.TelemetryService) *TelemetryHandler {
+	return &TelemetryHandler{telemetryService: telemetryService}
+}
+
+// TelemetryBatch is the body of POST /telemetry/events
+type TelemetryBatch struct {
+	Events []services.TelemetryEvent `json:"events"`
+}
+
+// Ingest records a batch of client analytics events
+// @Summary Report usage analytics
+// @Description Report up to 50 screen_view or feature_use events from a client app. Only users who opted in with telemetry_opt_in on /me/privacy can report events. Events are stored as anonymous daily counts; unknown fields and invalid events reject the whole batch. Heavily rate limited, so clients should buffer events and send them in batches.
+// @Tags telemetry
+// @Accept json
+// @Produce json
+// @Param batch body TelemetryBatch true "Events to report"
+// @Success 202 {object} map[string]int "Number of events accepted"
+// @Failure 400 {object} ErrorResponse "Invalid batch"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Telemetry consent required"
+// @Failure 404 {object} ErrorResponse "Telemetry disabled"
+// @Failure 429 {object} ErrorResponse "Rate limit exceeded"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /telemetry/events [post]
+func (h *TelemetryHandler) Ingest(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	var batch TelemetryBatch
+	decoder := json.NewDecoder(http.MaxBytesReader(c.Writer, c.Request.Body, maxTelemetryBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&batch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid telemetry batch: " + err.Error()})
+		return
+	}
+
+	accepted, err := h.telemetryService.Record(user, batch.Events, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTelemetryDisabled):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrTelemetryConsentRequired):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidTelemetryEvent):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record telemetry"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"accepted": accepted})
+}
+
+// Summary totals the reported analytics events
+// @Summary Summarize usage analytics
+// @Description Total the screen_view and feature_use events client apps reported over the last days, per event and platform, most reported first.
+// @Tags management
+// @Accept json
+// @Produce json
+// @Param days query int false "Days to cover, including today" default(30)
+// @Param type query string false "Filter by event type" Enums(screen_view, feature_use)
+// @Success 200 {object} map[string][]repository.TelemetrySummary "Successfully summarized telemetry"
+// @Failure 400 {object} ErrorResponse "Invalid parameters"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 403 {object} ErrorResponse "Permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/telemetry [get]
+func (h *TelemetryHandler) Summary(c *gin.Context) {
+	days := 30
+	if d := c.Query("days"); d != "" {
+		parsed, err := strconv.Atoi(d)
+		if err != nil || parsed <= 0 || parsed > 366 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 366"})
+			return
+		}
+		days = parsed
+	}
+	eventType := c.Query("type")
+	if eventType != "" && eventType != models.TelemetryEventScreenView && eventType != models.TelemetryEventFeatureUse {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid type, expected screen_view or feature_use"})
+		return
+	}
+
+	summary, err := h.telemetryService.Summarize(days, eventType, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to summarize telemetry"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": summary, "days": days})
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/robfig/cron/v3"
+)
+
+// ResetTime is the next occurrence of a scheduled reset
+type ResetTime struct {
+	At           time.Time `json:"at" example:"2026-10-16T00:00:00Z"`
+	SecondsUntil int64     `json:"seconds_until" example:"36000"`
+}
+
+// ServerTime is the body of GET /time
+type ServerTime struct {
+	ServerTime  time.Time `json:"server_time" example:"2026-10-15T14:00:00Z"`
+	UnixMillis  int64     `json:"unix_ms" example:"1792072800000"`
+	DailyReset  ResetTime `json:"daily_reset"`
+	WeeklyReset ResetTime `json:"weekly_reset"`
+}
+
+type TimeHandler struct {
+	dailyReset  cron.Schedule
+	weeklyReset cron.Schedule
+}
+
+// NewTimeHandler parses the daily and weekly reset schedules, standard cron expressions
+// evaluated in UTC unless they start with CRON_TZ=
+func NewTimeHandler(dailyResetCron, weeklyResetCron string) (*TimeHandler, error) {
+	daily, err := cron.ParseStandard(dailyResetCron)
+	if err != nil {
+		return nil, fmt.Errorf("invalid daily reset schedule %q: %w", dailyResetCron, err)
+	}
+	weekly, err := cron.ParseStandard(weeklyResetCron)
+	if err != nil {
+		return nil, fmt.Errorf("invalid weekly reset schedule %q: %w", weeklyResetCron, err)
+	}
+	return &TimeHandler{dailyReset: daily, weeklyReset: weekly}, nil
+}
+
+// Get returns the server time and the next resets
+// @Summary Get server time
+// @Description The server's current time and the next daily and weekly resets of repeatable tasks, so clients with a skewed clock can show accurate countdowns. Clients should compare server_time with their own clock once and apply the offset, rather than trusting seconds_until after a slow response.
+// @Tags config
+// @Produce json
+// @Success 200 {object} ServerTime "Server time and next resets"
+// @Router /time [get]
+func (h *TimeHandler) Get(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, h.serverTime(time.Now()))
+}
+
+func (h *TimeHandler) serverTime(now time.Time) ServerTime {
+	now = now.UTC()
+	return ServerTime{
+		ServerTime:  now,
+		UnixMillis:  now.UnixMilli(),
+		DailyReset:  nextReset(h.dailyReset, now),
+		WeeklyReset: nextReset(h.weeklyReset, now),
+	}
+}
+
+func nextReset(schedule cron.Schedule, now time.Time) ResetTime {
+	at := schedule.Next(now).UTC()
+	return ResetTime{At: at, SecondsUntil: int64(at.Sub(now) / time.Second)}
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestServerTimeResets(t *testing.T) {
+	h, err := NewTimeHandler("0 0 * * *", "0 0 * * 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 15, 14, 0, 30, 0, time.UTC) // a Thursday
+	got := h.serverTime(now)
+
+	if !got.ServerTime.Equal(now) || got.UnixMillis != now.UnixMilli() {
+		t.Errorf("unexpected server time %v / %d", got.ServerTime, got.UnixMillis)
+	}
+	if want := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC); !got.DailyReset.At.Equal(want) || got.DailyReset.SecondsUntil != 35970 {
+		t.Errorf("daily reset = %+v, want %v in 35970s", got.DailyReset, want)
+	}
+	if want := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC); !got.WeeklyReset.At.Equal(want) {
+		t.Errorf("weekly reset = %v, want %v", got.WeeklyReset.At, want)
+	}
+
+	// Schedules can name their own time zone; resets are still reported in UTC
+	h, err = NewTimeHandler("CRON_TZ=Europe/Berlin 0 6 * * *", "0 0 * * 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 10, 16, 4, 0, 0, 0, time.UTC); !h.serverTime(now).DailyReset.At.Equal(want) {
+		t.Errorf("daily reset in Berlin = %v, want %v", h.serverTime(now).DailyReset.At, want)
+	}
+
+	if _, err := NewTimeHandler("every day", "0 0 * * 1"); err == nil {
+		t.Error("expected an invalid schedule to be rejected")
+	}
+}
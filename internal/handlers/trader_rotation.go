@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/services"
+)
+
+type TraderRotationHandler struct {
+	rotationService *services.TraderRotationService
+}
+
+func NewTraderRotationHandler(rotationService *services.TraderRotationService) *TraderRotationHandler {
+	return &TraderRotationHandler{rotationService: rotationService}
+}
+
+// Get returns the current trader stock rotation
+// @Summary Get the trader stock rotation
+// @Description Each trader's stock in the current weekly rotation, as seen in the live trader feed since the rotation started, with when the next rotation starts. Items not stocked by the trader in the previous rotation are marked new_this_rotation, and items from the previous rotation not listed since are under removed. rotating is set when the feed flags an item as rotating stock. Users who favorited an item are notified when it comes into stock.
+// @Tags traders
+// @Produce json
+// @Param trader query string false "Only this trader (name or ID)"
+// @Param item query string false "Only traders stocking this item external ID"
+// @Success 200 {object} services.TraderRotation "Current rotation"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /traders/rotation [get]
+func (h *TraderRotationHandler) Get(c *gin.Context) {
+	rotation, err := h.rotationService.Current(time.Now(), services.TraderKey(c.Query("trader")), strings.TrimSpace(c.Query("item")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trader rotation"})
+		return
+	}
+	c.JSON(http.StatusOK, rotation)
+}
@@ -1,24 +1,205 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/middleware"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
 	"github.com/mat/arcapi/internal/services"
 )
 
+// TradersHandler serves traders from the database merged with the live inventory from the
+// external traders feed. tradersService is nil when Redis is unavailable; trader records
+// are still served, just without inventory.
 type TradersHandler struct {
-	tradersService *services.TradersService
+	traderRepo       *repository.TraderRepository
+	tradersService   *services.TradersService
+	priceHistoryRepo *repository.TraderPriceHistoryRepository
 }
 
-func NewTradersHandler(tradersService *services.TradersService) *TradersHandler {
+func NewTradersHandler(
+	traderRepo *repository.TraderRepository,
+	tradersService *services.TradersService,
+	priceHistoryRepo *repository.TraderPriceHistoryRepository,
+) *TradersHandler {
 	return &TradersHandler{
-		tradersService: tradersService,
+		traderRepo:       traderRepo,
+		tradersService:   tradersService,
+		priceHistoryRepo: priceHistoryRepo,
 	}
 }
 
-// GetTraders returns the traders data from cache or external API
+// TraderWithInventory is a trader record with its live inventory from the external feed
+type TraderWithInventory struct {
+	models.Trader
+	// Inventory is only set with ?include=inventory; nil when the feed has no entry for the trader
+	Inventory []interface{} `json:"inventory,omitempty"`
+}
+
+// PricePoint is one recorded trader price
+type PricePoint struct {
+	Price      float64   `json:"price"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// PriceHistoryResponse is an item's price trend at a trader
+type PriceHistoryResponse struct {
+	TraderID string       `json:"trader_id"`
+	ItemID   string       `json:"item_id"`
+	Days     int          `json:"days"`
+	Points   []PricePoint `json:"points"`
+	Latest   *float64     `json:"latest,omitempty"`
+	Min      *float64     `json:"min,omitempty"`
+	Max      *float64     `json:"max,omitempty"`
+}
+
+// tradersSuccessor is where clients of the raw feed at GET /traders move to
+const tradersSuccessor = "/api/v1/traders?include=inventory"
+
+// List returns traders merged with their live inventory, or the raw external feed
+// /traders has always served when include=inventory isn't set
+// @Summary List traders
+// @Description With ?include=inventory, fetch traders with optional pagination, each carrying its live inventory and prices from the external feed. Without it, returns the raw external traders feed as before; that response is deprecated, marked with a Deprecation header, and returns 503 without Redis.
+// @Tags traders
+// @Accept json
+// @Produce json
+// @Param offset query int false "Offset" default(0)
+// @Param limit query int false "Limit" default(20)
+// @Param include query string false "Set to inventory for traders merged with their live inventory"
+// @Success 200 {object} PaginatedResponse{data=[]TraderWithInventory} "Successfully fetched traders"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Failure 503 {object} ErrorResponse "Raw feed unavailable without Redis"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /traders [get]
+func (h *TradersHandler) List(c *gin.Context) {
+	if c.Query("include") != "inventory" {
+		middleware.MarkDeprecated(c, tradersSuccessor)
+		h.GetTraders(c)
+		return
+	}
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || limit > middleware.PageLimit(c) {
+		limit = 20
+	}
+
+	traders, count, err := h.traderRepo.FindAll(offset, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch traders"})
+		return
+	}
+
+	inventories := h.inventories(c)
+	data := make([]TraderWithInventory, 0, len(traders))
+	for _, trader := range traders {
+		data = append(data, mergeTraderInventory(trader, inventories))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": data,
+		"pagination": gin.H{
+			"total":  count,
+			"offset": offset,
+			"limit":  limit,
+		},
+	})
+}
+
+// Get returns a single trader merged with its live inventory
+// @Summary Get a single trader
+// @Description Fetch a trader by numeric ID or external ID. With ?include=inventory the live inventory and prices from the external feed are included.
+// @Tags traders
+// @Accept json
+// @Produce json
+// @Param id path string true "Trader ID or external ID"
+// @Param include query string false "Set to inventory to include live inventory"
+// @Success 200 {object} TraderWithInventory "Successfully fetched the trader"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 404 {object} ErrorResponse "Trader not found"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /traders/{id} [get]
+func (h *TradersHandler) Get(c *gin.Context) {
+	idStr := c.Param("id")
+	var trader *models.Trader
+	var err error
+	if id, parseErr := strconv.ParseUint(idStr, 10, 32); parseErr == nil {
+		trader, err = h.traderRepo.FindByID(uint(id))
+	} else {
+		trader, err = h.traderRepo.FindByExternalID(idStr)
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Trader not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, mergeTraderInventory(*trader, h.inventories(c)))
+}
+
+// inventories loads the live inventories when the request asked for them. Feed failures
+// are logged and the traders are served without inventory rather than failing the request.
+func (h *TradersHandler) inventories(c *gin.Context) map[string]services.TraderInventory {
+	if c.Query("include") != "inventory" || h.tradersService == nil {
+		return nil
+	}
+	inventories, err := h.tradersService.GetInventories()
+	if err != nil {
+		log.Printf("Warning: Failed to load trader inventories: %v", err)
+		return nil
+	}
+	return inventories
+}
+
+// mergeTraderInventory attaches the feed inventory matching the trader's name or external ID
+func mergeTraderInventory(trader models.Trader, inventories map[string]services.TraderInventory) TraderWithInventory {
+	merged := TraderWithInventory{Trader: trader}
+	if inventories == nil {
+		return merged
+	}
+	for _, key := range []string{
+		services.TraderKey(extractMultilingualField(trader.Data, "name", trader.Name)),
+		services.TraderKey(trader.ExternalID),
+	} {
+		if inventory, ok := inventories[key]; ok {
+			merged.Inventory = inventory.Items
+			if merged.Inventory == nil {
+				merged.Inventory = []interface{}{}
+			}
+			break
+		}
+	}
+	return merged
+}
+
+// GetTraders returns the raw external traders feed from cache or the external API.
+// Deprecated: use GET /traders?include=inventory.
+// @Summary Get raw traders feed
+// @Description Returns the external traders feed as-is, like GET /traders without include. Use GET /traders?include=inventory instead.
+// @Tags traders
+// @Produce json
+// @Success 200 {object} map[string]interface{} "External traders feed"
+// @Failure 503 {object} ErrorResponse "Feed unavailable without Redis"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Deprecated
+// @Router /traders/external [get]
 func (h *TradersHandler) GetTraders(c *gin.Context) {
+	if h.tradersService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Traders feed requires Redis"})
+		return
+	}
+
 	data, err := h.tradersService.GetTraders()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch traders data"})
@@ -27,3 +208,69 @@ func (h *TradersHandler) GetTraders(c *gin.Context) {
 
 	c.JSON(http.StatusOK, data)
 }
+
+// GetPriceHistory returns the recorded prices of an item at a trader
+// @Summary Get trader price history
+// @Description Returns the price snapshots recorded for an item at a trader whenever its price changed, oldest first, with latest/min/max over the window. The first point is the price in effect when the window opens and may be older.
+// @Tags traders
+// @Accept json
+// @Produce json
+// @Param id path string true "Trader ID (trader name, case-insensitive)"
+// @Param item_id path string true "Item external ID"
+// @Param days query int false "Days of history to return (default 30, max 365)"
+// @Success 200 {object} PriceHistoryResponse "Price history"
+// @Failure 400 {object} ErrorResponse "Invalid days"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /traders/{id}/items/{item_id}/history [get]
+func (h *TradersHandler) GetPriceHistory(c *gin.Context) {
+	days := 30
+	if d := c.Query("days"); d != "" {
+		parsed, err := strconv.Atoi(d)
+		if err != nil || parsed < 1 || parsed > 365 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 365"})
+			return
+		}
+		days = parsed
+	}
+
+	traderID := services.TraderKey(c.Param("id"))
+	itemID := c.Param("item_id")
+	history, err := h.priceHistoryRepo.FindHistory(traderID, itemID, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch price history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summarizePriceHistory(traderID, itemID, days, history))
+}
+
+func summarizePriceHistory(traderID, itemID string, days int, history []models.TraderPriceHistory) PriceHistoryResponse {
+	resp := PriceHistoryResponse{
+		TraderID: traderID,
+		ItemID:   itemID,
+		Days:     days,
+		Points:   make([]PricePoint, 0, len(history)),
+	}
+	for i, snapshot := range history {
+		resp.Points = append(resp.Points, PricePoint{Price: snapshot.Price, RecordedAt: snapshot.RecordedAt})
+		price := snapshot.Price
+		if i == 0 {
+			resp.Min, resp.Max = &price, &price
+			continue
+		}
+		if price < *resp.Min {
+			resp.Min = &price
+		}
+		if price > *resp.Max {
+			resp.Max = &price
+		}
+	}
+	if len(history) > 0 {
+		latest := history[len(history)-1].Price
+		resp.Latest = &latest
+	}
+	return resp
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestListTradersWithoutIncludeServesDeprecatedFeed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewTradersHandler(nil, nil, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/traders", nil)
+	h.List(c)
+
+	// Without Redis the raw feed is unavailable, as it always was at this path
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 for the raw feed without Redis, got %d", w.Code)
+	}
+	if w.Header().Get("Deprecation") != "true" || w.Header().Get("Link") != `<`+tradersSuccessor+`>; rel="successor-version"` {
+		t.Errorf("expected the raw feed to be marked deprecated, got headers %v", w.Header())
+	}
+}
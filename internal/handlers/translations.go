@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+type TranslationHandler struct {
+	repo *repository.TranslationRepository
+}
+
+func NewTranslationHandler(repo *repository.TranslationRepository) *TranslationHandler {
+	return &TranslationHandler{repo: repo}
+}
+
+func validTranslationEntityType(entityType string) bool {
+	switch entityType {
+	case models.TranslationEntityQuest, models.TranslationEntityItem, models.TranslationEntitySkillNode, models.TranslationEntityHideoutModule:
+		return true
+	}
+	return false
+}
+
+// preferTranslations keeps one row per entity and field, preferring lang over the English fallback
+func preferTranslations(rows []models.Translation, lang string) []models.Translation {
+	type key struct{ entityID, field string }
+	index := make(map[key]int, len(rows))
+	result := make([]models.Translation, 0, len(rows))
+	for _, row := range rows {
+		k := key{row.EntityID, row.Field}
+		if i, ok := index[k]; ok {
+			if row.Lang == lang {
+				result[i] = row
+			}
+			continue
+		}
+		index[k] = len(result)
+		result = append(result, row)
+	}
+	return result
+}
+
+// List returns the translations of an entity type in one language
+// @Summary List translations
+// @Description Fetch the text of one entity type's multilingual fields in a single language, normalized at sync time, falling back to English where a translation is missing. Field is the dotted path within the entity data, e.g. "name" or "objectives.0". With q only texts in the requested language are searched.
+// @Tags translations
+// @Accept json
+// @Produce json
+// @Param entity_type path string true "Entity type" Enums(quest, item, skill_node, hideout_module)
+// @Param lang query string true "Language code (e.g. de)"
+// @Param field query string false "Only this field (e.g. name)"
+// @Param ids query string false "Comma-separated entity external IDs"
+// @Param q query string false "Case-insensitive text search"
+// @Success 200 {object} map[string][]models.Translation "Successfully fetched translations"
+// @Failure 400 {object} ErrorResponse "Invalid entity type or language"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /translations/{entity_type} [get]
+func (h *TranslationHandler) List(c *gin.Context) {
+	entityType := c.Param("entity_type")
+	if !validTranslationEntityType(entityType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "entity_type must be quest, item, skill_node or hideout_module"})
+		return
+	}
+	lang, ok := requestLanguage(c)
+	if !ok {
+		return
+	}
+	if lang == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "lang is required"})
+		return
+	}
+
+	query := repository.TranslationQuery{
+		EntityType: entityType,
+		Langs:      []string{lang},
+		Field:      c.Query("field"),
+		Search:     strings.TrimSpace(c.Query("q")),
+	}
+	if query.Search == "" && lang != "en" {
+		query.Langs = append(query.Langs, "en")
+	}
+	if ids := c.Query("ids"); ids != "" {
+		for _, id := range strings.Split(ids, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				query.EntityIDs = append(query.EntityIDs, id)
+			}
+		}
+	}
+
+	rows, err := h.repo.Find(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch translations"})
+		return
+	}
+
+	translations := preferTranslations(rows, lang)
+	c.JSON(http.StatusOK, gin.H{
+		"data":  translations,
+		"total": len(translations),
+	})
+}
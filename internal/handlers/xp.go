@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+	"gorm.io/gorm"
+)
+
+type XPHandler struct {
+	playerLevelRepo   *repository.UserPlayerLevelRepository
+	questProgressRepo *repository.UserQuestProgressRepository
+	questRepo         *repository.QuestRepository
+	levelThresholds   []int
+}
+
+func NewXPHandler(
+	playerLevelRepo *repository.UserPlayerLevelRepository,
+	questProgressRepo *repository.UserQuestProgressRepository,
+	questRepo *repository.QuestRepository,
+	levelThresholds []int,
+) *XPHandler {
+	return &XPHandler{
+		playerLevelRepo:   playerLevelRepo,
+		questProgressRepo: questProgressRepo,
+		questRepo:         questRepo,
+		levelThresholds:   levelThresholds,
+	}
+}
+
+// XPSummary compares a user's declared level and XP with the XP of their completed quests
+type XPSummary struct {
+	Declared        *models.UserPlayerLevel `json:"declared"`
+	EarnedXP        int                     `json:"earned_xp"` // Sum of XP rewards of completed quests
+	CompletedQuests int                     `json:"completed_quests"`
+	RemainingXP     int                     `json:"remaining_xp"` // Sum of XP rewards of quests not yet completed
+	RemainingQuests int                     `json:"remaining_quests"`
+	// Discrepancy is declared XP minus quest XP; positive values are XP from other sources,
+	// negative values mean the declared XP is out of date or quests were marked by mistake
+	Discrepancy *int `json:"discrepancy,omitempty"`
+	ProjectedXP int  `json:"projected_xp"` // Declared XP (or quest XP if none) plus remaining quest XP
+
+	// Levels are only derived when PLAYER_LEVEL_XP is configured
+	LevelFromDeclaredXP *int `json:"level_from_declared_xp,omitempty"`
+	LevelMismatch       bool `json:"level_mismatch"` // Declared level doesn't match the declared XP
+	ProjectedLevel      *int `json:"projected_level,omitempty"`
+}
+
+// levelForXP returns the level reached with xp, given the total XP needed for levels 2, 3, ...
+func levelForXP(thresholds []int, xp int) int {
+	level := 1
+	for _, threshold := range thresholds {
+		if xp < threshold {
+			break
+		}
+		level++
+	}
+	return level
+}
+
+func summarizeXP(quests []models.Quest, completed map[string]bool, declared *models.UserPlayerLevel, thresholds []int) XPSummary {
+	summary := XPSummary{Declared: declared}
+	for _, q := range quests {
+		if completed[q.ExternalID] {
+			summary.EarnedXP += q.XP
+			summary.CompletedQuests++
+		} else {
+			summary.RemainingXP += q.XP
+			summary.RemainingQuests++
+		}
+	}
+
+	baseXP := summary.EarnedXP
+	if declared != nil {
+		baseXP = declared.XP
+		discrepancy := declared.XP - summary.EarnedXP
+		summary.Discrepancy = &discrepancy
+	}
+	summary.ProjectedXP = baseXP + summary.RemainingXP
+
+	if len(thresholds) > 0 {
+		projected := levelForXP(thresholds, summary.ProjectedXP)
+		summary.ProjectedLevel = &projected
+		if declared != nil {
+			level := levelForXP(thresholds, declared.XP)
+			summary.LevelFromDeclaredXP = &level
+			summary.LevelMismatch = level != declared.Level
+		}
+	}
+	return summary
+}
+
+// GetXP returns the current user's XP rollup
+// @Summary Get my XP summary
+// @Description Compare the authenticated user's declared level and XP with the XP earned from completed quests, and project the XP (and level, when PLAYER_LEVEL_XP is configured) after the remaining quests.
+// @Tags progress
+// @Accept json
+// @Produce json
+// @Success 200 {object} XPSummary "XP summary"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /progress/xp [get]
+func (h *XPHandler) GetXP(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	declared, err := h.playerLevelRepo.FindByUserID(user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch player level"})
+		return
+	}
+
+	progress, err := h.questProgressRepo.FindByUserID(user.ID, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quest progress"})
+		return
+	}
+	completed := make(map[string]bool, len(progress))
+	for _, p := range progress {
+		if p.Completed {
+			completed[p.QuestExternalID] = true
+		}
+	}
+
+	quests, err := h.questRepo.ListAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summarizeXP(quests, completed, declared, h.levelThresholds))
+}
+
+// UpdateXP records the current user's declared level and XP
+// @Summary Update my player level
+// @Description Set the player level and total XP shown in game for the authenticated user.
+// @Tags progress
+// @Accept json
+// @Produce json
+// @Param level body map[string]int true "Player level and XP (level, xp)"
+// @Success 200 {object} models.UserPlayerLevel "Successfully updated player level"
+// @Failure 400 {object} ErrorResponse "Invalid input"
+// @Failure 401 {object} ErrorResponse "Not authenticated"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /progress/xp [put]
+func (h *XPHandler) UpdateXP(c *gin.Context) {
+	val, _ := c.Get("user")
+	user, ok := val.(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	var req struct {
+		Level int `json:"level" binding:"required,min=1"`
+		XP    int `json:"xp" binding:"min=0"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	playerLevel, err := h.playerLevelRepo.Upsert(user.ID, req.Level, req.XP)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update player level"})
+		return
+	}
+
+	c.JSON(http.StatusOK, playerLevel)
+}
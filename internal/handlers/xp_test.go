@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestSummarizeXP(t *testing.T) {
+	quests := []models.Quest{
+		{ExternalID: "q1", XP: 500},
+		{ExternalID: "q2", XP: 700},
+		{ExternalID: "q3", XP: 1000},
+	}
+	completed := map[string]bool{"q1": true, "q2": true}
+	thresholds := []int{1000, 2500, 4500}
+
+	summary := summarizeXP(quests, completed, nil, thresholds)
+	if summary.EarnedXP != 1200 || summary.RemainingXP != 1000 || summary.CompletedQuests != 2 || summary.RemainingQuests != 1 {
+		t.Errorf("unexpected rollup %+v", summary)
+	}
+	if summary.Discrepancy != nil || summary.ProjectedXP != 2200 || *summary.ProjectedLevel != 2 {
+		t.Errorf("unexpected projection without declared XP %+v", summary)
+	}
+
+	declared := &models.UserPlayerLevel{Level: 2, XP: 2600}
+	summary = summarizeXP(quests, completed, declared, thresholds)
+	if *summary.Discrepancy != 1400 || summary.ProjectedXP != 3600 || *summary.ProjectedLevel != 3 {
+		t.Errorf("unexpected projection with declared XP %+v", summary)
+	}
+	if *summary.LevelFromDeclaredXP != 3 || !summary.LevelMismatch {
+		t.Errorf("expected declared level 2 to mismatch 2600 XP, got %+v", summary)
+	}
+
+	if summary = summarizeXP(quests, completed, declared, nil); summary.ProjectedLevel != nil || summary.LevelMismatch {
+		t.Errorf("expected no level projection without thresholds, got %+v", summary)
+	}
+}
@@ -0,0 +1,149 @@
+// Package logging configures the process-wide structured logger. Logs are written to
+// stderr as JSON lines, or as logfmt-style text, at the level set by LOG_LEVEL, which can
+// be changed while the server runs with SetLevel. The standard log package is routed
+// through the same handler, so code that hasn't moved to component loggers still produces
+// parseable lines.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Output formats for LOG_FORMAT
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// level is the minimum level of the handler Setup installs. slog.LevelVar is read
+// atomically, so SetLevel takes effect on the next record without locking.
+var level = new(slog.LevelVar)
+
+// Setup installs the default logger for level and format, writing to w
+func Setup(levelName, format string, w io.Writer) error {
+	lvl, err := ParseLevel(levelName)
+	if err != nil {
+		return err
+	}
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case FormatJSON, "":
+		handler = slog.NewJSONHandler(w, opts)
+	case FormatText:
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return fmt.Errorf("unknown log format %q, expected json or text", format)
+	}
+	level.Set(lvl)
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// Level returns the current minimum level as debug, info, warn or error
+func Level() string {
+	return LevelName(level.Level())
+}
+
+// SetLevel changes the minimum level of the installed logger without restarting and
+// returns the level it replaced
+func SetLevel(levelName string) (string, error) {
+	lvl, err := ParseLevel(levelName)
+	if err != nil {
+		return "", err
+	}
+	previous := Level()
+	level.Set(lvl)
+	return previous, nil
+}
+
+// LevelName is the lowercase name ParseLevel accepts for l
+func LevelName(l slog.Level) string {
+	return strings.ToLower(l.String())
+}
+
+// ParseLevel parses debug, info, warn (or warning) and error
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", name)
+}
+
+// For returns a logger that tags every record with component. It writes through whatever
+// default logger is installed when it logs, so it can be created in package variables
+// before Setup runs.
+func For(component string) *slog.Logger {
+	return slog.New(&componentHandler{
+		build: func(h slog.Handler) slog.Handler {
+			return h.WithAttrs([]slog.Attr{slog.String("component", component)})
+		},
+	})
+}
+
+// Fatal logs msg at error level and exits, like log.Fatal
+func Fatal(logger *slog.Logger, msg string, args ...any) {
+	logger.Error(msg, args...)
+	os.Exit(1)
+}
+
+// componentHandler defers to the default handler, replaying the attributes and groups
+// added to it. The handler built on top of the default is kept until Setup installs
+// another, so records don't pay for the replay.
+type componentHandler struct {
+	build func(slog.Handler) slog.Handler
+	built atomic.Pointer[builtHandler]
+}
+
+// builtHandler is build applied to base
+type builtHandler struct {
+	base    slog.Handler
+	handler slog.Handler
+}
+
+// handler returns build applied to the current default handler
+func (h *componentHandler) handler() slog.Handler {
+	base := slog.Default().Handler()
+	if built := h.built.Load(); built != nil && built.base == base {
+		return built.handler
+	}
+	built := &builtHandler{base: base, handler: h.build(base)}
+	h.built.Store(built)
+	return built.handler
+}
+
+func (h *componentHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return slog.Default().Handler().Enabled(ctx, level)
+}
+
+func (h *componentHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler().Handle(ctx, r)
+}
+
+func (h *componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	build := h.build
+	return &componentHandler{build: func(next slog.Handler) slog.Handler {
+		return build(next).WithAttrs(attrs)
+	}}
+}
+
+func (h *componentHandler) WithGroup(name string) slog.Handler {
+	build := h.build
+	return &componentHandler{build: func(next slog.Handler) slog.Handler {
+		return build(next).WithGroup(name)
+	}}
+}
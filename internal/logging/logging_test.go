@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestForWritesThroughSetup(t *testing.T) {
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+
+	// Created before Setup, as package-level loggers are
+	logger := For("sync").With("run", 3)
+
+	var buf bytes.Buffer
+	if err := Setup("warn", "json", &buf); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("Below the level")
+	logger.Warn("Could not read entity data from zip", "entity", "maps")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want 1:\n%s", len(lines), buf.String())
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]interface{}{"level": "WARN", "component": "sync", "run": float64(3), "entity": "maps"} {
+		if record[key] != want {
+			t.Errorf("%s = %v, want %v", key, record[key], want)
+		}
+	}
+}
+
+func TestForFollowsANewSetup(t *testing.T) {
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+
+	logger := For("sync")
+	var first, second bytes.Buffer
+	if err := Setup("info", "json", &first); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("First")
+	if err := Setup("info", "text", &second); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("Second")
+
+	if !strings.Contains(first.String(), `"msg":"First"`) || strings.Contains(first.String(), "Second") {
+		t.Errorf("first handler got %q", first.String())
+	}
+	if !strings.Contains(second.String(), "msg=Second component=sync") {
+		t.Errorf("second handler got %q", second.String())
+	}
+}
+
+func TestSetupRejectsUnknownSettings(t *testing.T) {
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+
+	if err := Setup("verbose", "json", &bytes.Buffer{}); err == nil {
+		t.Error("accepted an unknown level")
+	}
+	if err := Setup("info", "xml", &bytes.Buffer{}); err == nil {
+		t.Error("accepted an unknown format")
+	}
+	if err := Setup("INFO", "text", &bytes.Buffer{}); err != nil {
+		t.Errorf("Setup(INFO, text) = %v", err)
+	}
+}
+
+func TestSetLevelAtRuntime(t *testing.T) {
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+
+	logger := For("server")
+	var buf bytes.Buffer
+	if err := Setup("info", "json", &buf); err != nil {
+		t.Fatal(err)
+	}
+	logger.Debug("Hidden")
+
+	was, err := SetLevel("debug")
+	if err != nil || was != "info" {
+		t.Fatalf("SetLevel(debug) = %q, %v; want info, nil", was, err)
+	}
+	logger.Debug("Shown")
+	if Level() != "debug" {
+		t.Errorf("Level() = %q, want debug", Level())
+	}
+	if _, err := SetLevel("loud"); err == nil {
+		t.Error("accepted an unknown level")
+	}
+	if Level() != "debug" {
+		t.Errorf("a rejected level changed Level() to %q", Level())
+	}
+
+	if out := buf.String(); strings.Contains(out, "Hidden") || !strings.Contains(out, "Shown") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
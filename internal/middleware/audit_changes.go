@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+)
+
+// AuditChangeContextKey holds the AuditChange a handler recorded for LoggerMiddleware
+const AuditChangeContextKey = "audit_change"
+
+// Entity types recorded on audit logs of write requests
+const (
+	AuditEntityQuest = "quest"
+	AuditEntityItem  = "item"
+	AuditEntityUser  = "user"
+)
+
+// auditIgnoredFields are bookkeeping fields that change on every write, left out of diffs
+var auditIgnoredFields = map[string]bool{"created_at": true, "updated_at": true, "synced_at": true}
+
+// AuditChange is the entity a write request changed and the fields it changed
+type AuditChange struct {
+	EntityType string
+	EntityID   string
+	Changes    models.JSONB
+}
+
+// RecordChange attaches the entity a write request changed, and a diff of it, to the
+// request's audit log. before is nil for creates and after is nil for deletes.
+func RecordChange(c *gin.Context, entityType string, entityID uint, before, after interface{}) {
+	c.Set(AuditChangeContextKey, &AuditChange{
+		EntityType: entityType,
+		EntityID:   strconv.FormatUint(uint64(entityID), 10),
+		Changes:    DiffFields(before, after),
+	})
+}
+
+// DiffFields compares two values in their JSON form and returns the fields that differ as
+// {"field": {"from": old, "to": new}}, leaving out "from" for added fields and "to" for
+// removed ones. Nested objects are compared field by field, with dotted paths such as
+// data.rarity; arrays are compared as a whole. It returns nil when nothing changed.
+func DiffFields(before, after interface{}) models.JSONB {
+	changes := models.JSONB{}
+	diffObjects("", auditObject(before), auditObject(after), changes)
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+func diffObjects(prefix string, before, after map[string]interface{}, changes models.JSONB) {
+	keys := make(map[string]bool, len(before)+len(after))
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+
+	for key := range keys {
+		if prefix == "" && auditIgnoredFields[key] {
+			continue
+		}
+		from, to := before[key], after[key]
+		fromObject, fromIsObject := from.(map[string]interface{})
+		toObject, toIsObject := to.(map[string]interface{})
+		switch {
+		case fromIsObject && toIsObject:
+			diffObjects(prefix+key+".", fromObject, toObject, changes)
+		case !reflect.DeepEqual(from, to):
+			change := map[string]interface{}{}
+			if from != nil {
+				change["from"] = from
+			}
+			if to != nil {
+				change["to"] = to
+			}
+			changes[prefix+key] = change
+		}
+	}
+}
+
+// auditObject converts v to its JSON object form, so diffs use API field names and skip
+// fields never exposed such as secrets
+func auditObject(v interface{}) map[string]interface{} {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil
+	}
+	return object
+}
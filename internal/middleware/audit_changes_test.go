@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestDiffFields(t *testing.T) {
+	before := &models.Quest{
+		ID:        12,
+		Name:      "Clean Sweep",
+		XP:        500,
+		Data:      models.JSONB{"rarity": "Common", "map": "Dam", "objectives": []interface{}{"a"}},
+		UpdatedAt: time.Now().Add(-time.Hour),
+	}
+	after := models.Quest{
+		ID:        12,
+		Name:      "Clean Sweep",
+		XP:        750,
+		Data:      models.JSONB{"rarity": "Rare", "objectives": []interface{}{"a", "b"}, "trader": "Shani"},
+		UpdatedAt: time.Now(),
+	}
+
+	want := models.JSONB{
+		"xp":              map[string]interface{}{"from": float64(500), "to": float64(750)},
+		"data.rarity":     map[string]interface{}{"from": "Common", "to": "Rare"},
+		"data.map":        map[string]interface{}{"from": "Dam"},
+		"data.trader":     map[string]interface{}{"to": "Shani"},
+		"data.objectives": map[string]interface{}{"from": []interface{}{"a"}, "to": []interface{}{"a", "b"}},
+	}
+	if got := DiffFields(before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffFields() = %v, want %v", got, want)
+	}
+
+	if got := DiffFields(before, before); got != nil {
+		t.Errorf("expected no changes for identical values, got %v", got)
+	}
+
+	var missing *models.Quest
+	created := DiffFields(missing, models.Item{ExternalID: "ferro_i"})
+	if created["external_id"] == nil || created["external_id"].(map[string]interface{})["from"] != nil {
+		t.Errorf("expected a create to list new fields without from, got %v", created)
+	}
+}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -19,6 +21,28 @@ type AuthContext struct {
 
 const AuthContextKey = "auth_context"
 
+// JWTTokenContextKey holds the tracked *models.JWTToken for Bearer-authenticated requests
+const JWTTokenContextKey = "jwt_token"
+
+// APIKeyContextKey holds the *models.APIKey for X-API-Key-authenticated requests
+const APIKeyContextKey = "api_key"
+
+// MaxPageSizeContextKey raises the list endpoint page size cap for the request (see PageLimit)
+const MaxPageSizeContextKey = "max_page_size"
+
+// DefaultMaxPageSize is the page size cap for list endpoints
+const DefaultMaxPageSize = 100
+
+// PageLimit returns the largest page size the request may ask for
+func PageLimit(c *gin.Context) int {
+	if val, ok := c.Get(MaxPageSizeContextKey); ok {
+		if max, ok := val.(int); ok && max > DefaultMaxPageSize {
+			return max
+		}
+	}
+	return DefaultMaxPageSize
+}
+
 // AuthenticateRequest validates request using Supabase JWT or API Key.
 // It returns the associated user and the raw credentials (token or key).
 func AuthenticateRequest(c *gin.Context, authService *services.AuthService, supabaseService *services.SupabaseAuthService, cfg *config.Config) (*models.User, string, error) {
@@ -29,6 +53,11 @@ func AuthenticateRequest(c *gin.Context, authService *services.AuthService, supa
 		if err == nil {
 			user, err := authService.UserRepo().FindByID(apiKey.UserID)
 			if err == nil {
+				c.Set(APIKeyContextKey, apiKey)
+				if apiKey.HasScope(models.ScopeBulkRead) && cfg != nil {
+					c.Set(MaxPageSizeContextKey, cfg.BulkReadMaxPageSize)
+				}
+				authService.TouchLastSeen(user)
 				return user, apiKeyString, nil
 			}
 		}
@@ -40,8 +69,12 @@ func AuthenticateRequest(c *gin.Context, authService *services.AuthService, supa
 		parts := strings.Split(authHeader, " ")
 		if len(parts) == 2 && parts[0] == "Bearer" {
 			tokenString := parts[1]
-			user, err := ValidateTokenString(tokenString, authService, supabaseService, cfg)
+			user, jwtToken, err := validateTokenSession(c.Request.Context(), tokenString, authService, supabaseService, c.Request.UserAgent(), c.ClientIP())
 			if err == nil {
+				if jwtToken != nil {
+					c.Set(JWTTokenContextKey, jwtToken)
+				}
+				authService.TouchLastSeen(user)
 				return user, tokenString, nil
 			}
 		}
@@ -51,17 +84,42 @@ func AuthenticateRequest(c *gin.Context, authService *services.AuthService, supa
 }
 
 // ValidateTokenString validates a raw token string using Supabase.
-func ValidateTokenString(tokenString string, authService *services.AuthService, supabaseService *services.SupabaseAuthService, cfg *config.Config) (*models.User, error) {
+func ValidateTokenString(ctx context.Context, tokenString string, authService *services.AuthService, supabaseService *services.SupabaseAuthService, cfg *config.Config) (*models.User, error) {
+	user, _, err := validateTokenSession(ctx, tokenString, authService, supabaseService, "", "")
+	return user, err
+}
+
+// validateTokenSession validates an access token issued by the API or a Supabase token,
+// syncs its user and tracks it by jti, rejecting tokens whose jti has been revoked.
+func validateTokenSession(ctx context.Context, tokenString string, authService *services.AuthService, supabaseService *services.SupabaseAuthService, userAgent, ipAddress string) (*models.User, *models.JWTToken, error) {
+	user, jwtToken, err := authService.ValidateAccessToken(tokenString, userAgent, ipAddress)
+	if err == nil {
+		return user, jwtToken, nil
+	}
+	if !errors.Is(err, services.ErrNotAccessToken) {
+		return nil, nil, fmt.Errorf("invalid token: %w", err)
+	}
+
 	if supabaseService == nil {
-		return nil, fmt.Errorf("supabase auth service not available")
+		return nil, nil, fmt.Errorf("supabase auth service not available")
 	}
 
 	claims, err := supabaseService.ValidateToken(tokenString)
 	if err != nil {
-		return nil, fmt.Errorf("invalid token: %w", err)
+		return nil, nil, fmt.Errorf("invalid token: %w", err)
 	}
 
-	return authService.SyncSupabaseUser(claims)
+	user, err = authService.SyncSupabaseUser(ctx, claims)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	jwtToken, err = authService.TrackJWT(user, claims, tokenString, userAgent, ipAddress)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	return user, jwtToken, nil
 }
 
 // JWTAuthMiddleware validates authentication for read operations
@@ -81,14 +139,18 @@ func JWTAuthMiddleware(authService *services.AuthService, cfg *config.Config, su
 		})
 		c.Set("user", user)
 		c.Set("user_id", user.ID)
+		if !ApplyRateLimit(c, user) {
+			return
+		}
 
 		c.Next()
 	}
 }
 
-// WriteAuthMiddleware only allows admin users to perform write operations
-// Regular users are restricted to read-only access, even with API keys
-func WriteAuthMiddleware(authService *services.AuthService, cfg *config.Config, supabaseService *services.SupabaseAuthService) gin.HandlerFunc {
+// WriteAuthMiddleware only allows users holding at least one permission to perform write operations.
+// Regular users are restricted to read-only access, even with API keys; individual routes
+// then require the specific permission via RequirePermission.
+func WriteAuthMiddleware(authService *services.AuthService, cfg *config.Config, supabaseService *services.SupabaseAuthService, rbacService *services.RBACService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		user, token, err := AuthenticateRequest(c, authService, supabaseService, cfg)
 		if err != nil {
@@ -97,14 +159,21 @@ func WriteAuthMiddleware(authService *services.AuthService, cfg *config.Config,
 			return
 		}
 
-		// Only admin users can perform write operations
-		if user.Role != models.RoleAdmin {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Write operations are restricted to admin users only"})
+		// Devices logged in with a user code only get the access of a regular user
+		if jwtToken, ok := c.Get(JWTTokenContextKey); ok && jwtToken.(*models.JWTToken).Scope == models.SessionScopeDevice {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Device sessions cannot perform write operations"})
+			c.Abort()
+			return
+		}
+
+		// Only users with a privileged role can perform write operations
+		if len(rbacService.Permissions(user)) == 0 {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Write operations are restricted to privileged users only"})
 			c.Abort()
 			return
 		}
 
-		// Admin users can write with JWT only (no API key required)
+		// Privileged users can write with JWT only (no API key required)
 		c.Set(AuthContextKey, &AuthContext{
 			User:     user,
 			APIKey:   nil,
@@ -112,14 +181,17 @@ func WriteAuthMiddleware(authService *services.AuthService, cfg *config.Config,
 		})
 		c.Set("user", user)
 		c.Set("user_id", user.ID)
+		if !ApplyRateLimit(c, user) {
+			return
+		}
 
 		c.Next()
 	}
 }
 
 // AuthMiddleware validates both API key and JWT token (legacy, kept for backward compatibility)
-func AuthMiddleware(authService *services.AuthService, cfg *config.Config, supabaseService *services.SupabaseAuthService) gin.HandlerFunc {
-	return WriteAuthMiddleware(authService, cfg, supabaseService)
+func AuthMiddleware(authService *services.AuthService, cfg *config.Config, supabaseService *services.SupabaseAuthService, rbacService *services.RBACService) gin.HandlerFunc {
+	return WriteAuthMiddleware(authService, cfg, supabaseService, rbacService)
 }
 
 // ProgressAuthMiddleware allows all authenticated users to read and update their own progress
@@ -145,6 +217,9 @@ func ProgressAuthMiddleware(authService *services.AuthService, cfg *config.Confi
 		})
 		c.Set("user", user)
 		c.Set("user_id", user.ID)
+		if !ApplyRateLimit(c, user) {
+			return
+		}
 
 		c.Next()
 	}
@@ -177,3 +252,31 @@ func AdminMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequirePermission checks that the authenticated user's role grants the given permission
+func RequirePermission(rbacService *services.RBACService, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authCtx, exists := c.Get(AuthContextKey)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			c.Abort()
+			return
+		}
+
+		ctx := authCtx.(*AuthContext)
+		user, ok := ctx.User.(*models.User)
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Invalid user context"})
+			c.Abort()
+			return
+		}
+
+		if !rbacService.HasPermission(user, permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission required: " + permission})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeprecatedMiddleware marks responses from a deprecated route with the Deprecation header
+// and a Link to the route that replaces it, so clients can migrate before it is removed
+func DeprecatedMiddleware(successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		MarkDeprecated(c, successor)
+		c.Next()
+	}
+}
+
+// MarkDeprecated sets the headers of DeprecatedMiddleware, for handlers that serve a
+// deprecated response shape only for some requests
+func MarkDeprecated(c *gin.Context, successor string) {
+	c.Header("Deprecation", "true")
+	c.Header("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+}
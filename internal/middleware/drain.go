@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/services"
+)
+
+// drainExemptPaths are served during a drain and not waited for, so orchestration can
+// follow its progress
+var drainExemptPaths = map[string]bool{
+	"/health/live":        true,
+	"/api/v1/admin/drain": true,
+}
+
+// DrainMiddleware counts the in-flight requests a drain waits for and, once a drain has
+// started, refuses new requests with 503 and closes their connections so load balancers
+// move traffic to other instances. Readiness checks fail the same way.
+func DrainMiddleware(drain *services.DrainService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if drainExemptPaths[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		// Count the request before checking, so a drain starting now still waits for it
+		drain.RequestStarted()
+		defer drain.RequestFinished()
+
+		if drain.Draining() {
+			c.Header("Connection", "close")
+			c.Header("Retry-After", "5")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Server is restarting. Please try again."})
+			return
+		}
+
+		c.Next()
+	}
+}
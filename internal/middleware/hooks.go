@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/services"
+)
+
+// PreWriteHooks runs the configured pre-write hooks before a data write reaches its
+// handler. A hook rejection returns 422 with the hook's message; an unreachable hook 502.
+func PreWriteHooks(hooks *services.HookRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hooks.HasPreWrite() {
+			c.Next()
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(c.Request.Body)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		event := services.WriteEvent{
+			EntityType: writeEntityType(c.FullPath()),
+			Action:     writeAction(c.Request.Method),
+			EntityID:   c.Param("id"),
+		}
+		if json.Valid(body) {
+			event.Body = body
+		}
+		if val, ok := c.Get("user"); ok {
+			if user, ok := val.(*models.User); ok {
+				event.UserID = user.ID
+			}
+		}
+
+		if err := hooks.RunPreWrite(c.Request.Context(), event); err != nil {
+			var rejected *services.WriteRejectedError
+			if errors.As(err, &rejected) {
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": rejected.Message})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusBadGateway, gin.H{"error": "Pre-write hook failed"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// writeEntityType returns the collection of a route, e.g. "items" for /api/v1/items/:id
+func writeEntityType(fullPath string) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(fullPath, "/api/v1/"), "/")
+	return segment
+}
+
+func writeAction(method string) string {
+	switch method {
+	case http.MethodPost:
+		return "create"
+	case http.MethodDelete:
+		return "delete"
+	}
+	return "update"
+}
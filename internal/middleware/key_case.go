@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Key cases clients can ask for with the X-Key-Case header or ?key_case=
+const (
+	KeyCaseCamel = "camel"
+	KeyCaseSnake = "snake"
+)
+
+// keyCaseWriter holds back a response body so its keys can be rewritten once the handler
+// is done
+type keyCaseWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *keyCaseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *keyCaseWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// Unwrap lets http.ResponseController reach the connection, e.g. to extend a write
+// deadline
+func (w *keyCaseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush is a no-op, as flushing would send the headers before the body is rewritten
+func (w *keyCaseWriter) Flush() {}
+
+// KeyCaseMiddleware rewrites every object key in JSON responses to camelCase or snake_case
+// when the client sends X-Key-Case: camel|snake (or ?key_case=), so model fields and
+// synced data blobs share one convention. Without either, responses are left untouched.
+// Only identifier-like keys are rewritten, so keys such as "zh-CN" or "$id" keep their form.
+func KeyCaseMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keyCase := strings.ToLower(c.GetHeader("X-Key-Case"))
+		if keyCase == "" {
+			keyCase = strings.ToLower(c.Query("key_case"))
+		}
+		c.Writer.Header().Add("Vary", "X-Key-Case")
+		if keyCase == "" {
+			c.Next()
+			return
+		}
+
+		var convert func(string) string
+		switch keyCase {
+		case KeyCaseCamel:
+			convert = toCamelCase
+		case KeyCaseSnake:
+			convert = toSnakeCase
+		default:
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid key case, expected camel or snake"})
+			return
+		}
+
+		w := &keyCaseWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		body := w.body.Bytes()
+		if strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+			if converted, err := convertJSONKeys(body, convert); err == nil {
+				body = converted
+			}
+		}
+		if len(body) > 0 {
+			w.ResponseWriter.Write(body)
+		}
+	}
+}
+
+// convertJSONKeys rewrites every object key in a JSON document. Numbers are kept as
+// written.
+func convertJSONKeys(data []byte, convert func(string) string) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	// Match gin's c.JSON, which doesn't escape HTML
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(convertKeys(value, convert)); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func convertKeys(value interface{}, convert func(string) string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			newKey := key
+			if isIdentifierKey(key) {
+				newKey = convert(key)
+			}
+			// Keep the original key if two keys would collide, e.g. both itemId and item_id
+			if _, taken := v[newKey]; taken && newKey != key {
+				newKey = key
+			}
+			converted[newKey] = convertKeys(child, convert)
+		}
+		return converted
+	case []interface{}:
+		for i, child := range v {
+			v[i] = convertKeys(child, convert)
+		}
+		return v
+	}
+	return value
+}
+
+// isIdentifierKey reports whether key is made of ASCII letters, digits and underscores
+func isIdentifierKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// toCamelCase converts snake_case to camelCase: reward_item_ids becomes rewardItemIds.
+// Keys that are already camelCase are left as they are.
+func toCamelCase(key string) string {
+	if !strings.Contains(strings.Trim(key, "_"), "_") {
+		return key
+	}
+	var b strings.Builder
+	upper := false
+	for i, r := range key {
+		switch {
+		case r == '_' && i > 0:
+			upper = true
+		case upper:
+			b.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// toSnakeCase converts camelCase to snake_case, keeping acronyms together: imageURL and
+// imageUrl both become image_url.
+func toSnakeCase(key string) string {
+	runes := []rune(key)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && runes[i-1] != '_' {
+				prev := runes[i-1]
+				nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+					b.WriteByte('_')
+				}
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
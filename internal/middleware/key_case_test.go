@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestKeyCaseConversions(t *testing.T) {
+	camel := map[string]string{
+		"reward_item_ids": "rewardItemIds",
+		"external_id":     "externalId",
+		"imageFilename":   "imageFilename",
+		"_id":             "_id",
+	}
+	for in, want := range camel {
+		if got := toCamelCase(in); got != want {
+			t.Errorf("toCamelCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	snake := map[string]string{
+		"rewardItemIds": "reward_item_ids",
+		"imageURL":      "image_url",
+		"HTTPServer":    "http_server",
+		"level2Cost":    "level2_cost",
+		"external_id":   "external_id",
+	}
+	for in, want := range snake {
+		if got := toSnakeCase(in); got != want {
+			t.Errorf("toSnakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestKeyCaseMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(KeyCaseMiddleware())
+	r.GET("/item", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{
+			"external_id": "rusted_gear",
+			"data":        gin.H{"imageFilename": "gear.png", "name": gin.H{"en": "Gear", "zh-CN": "齿轮"}, "value": 12345678901234567},
+		})
+	})
+
+	request := func(target, header string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if header != "" {
+			req.Header.Set("X-Key-Case", header)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("/item", "camel")
+	want := `{"data":{"imageFilename":"gear.png","name":{"en":"Gear","zh-CN":"齿轮"},"value":12345678901234567},"externalId":"rusted_gear"}`
+	if w.Code != http.StatusCreated || w.Body.String() != want {
+		t.Errorf("camel: got %d %s", w.Code, w.Body.String())
+	}
+
+	w = request("/item?key_case=snake", "")
+	want = `{"data":{"image_filename":"gear.png","name":{"en":"Gear","zh-CN":"齿轮"},"value":12345678901234567},"external_id":"rusted_gear"}`
+	if w.Body.String() != want {
+		t.Errorf("snake: got %s", w.Body.String())
+	}
+
+	if w = request("/item", "kebab"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown case, got %d", w.Code)
+	}
+}
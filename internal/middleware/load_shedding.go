@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/config"
+)
+
+// RequestPriority decides which requests are shed first under load
+type RequestPriority int
+
+const (
+	PriorityLow      RequestPriority = iota // exports, unpaginated listings
+	PriorityNormal                          // everything else
+	PriorityCritical                        // auth, progress and health; never shed
+)
+
+// requestPriority classifies a request by path and query
+func requestPriority(c *gin.Context) RequestPriority {
+	path := c.Request.URL.Path
+
+	switch {
+	case strings.HasPrefix(path, "/health"),
+		strings.HasPrefix(path, "/api/v1/auth"),
+		strings.HasPrefix(path, "/api/v1/me"),
+		strings.HasPrefix(path, "/api/v1/progress"):
+		return PriorityCritical
+	case strings.Contains(path, "/export/"),
+		strings.HasPrefix(path, "/api/v1/sync/snapshot"),
+		strings.HasPrefix(path, "/api/v1/integrations/"),
+		c.Query("all") == "true":
+		return PriorityLow
+	}
+	return PriorityNormal
+}
+
+// loadShedReleaseKey holds the func that stops counting a request as in flight
+const loadShedReleaseKey = "load_shed_release"
+
+// ReleaseInFlight stops counting the request toward load shedding. Handlers that hold a
+// request open while idle, like notification long-polls, call it before waiting so that
+// clients waiting for updates don't make the server look saturated and shed everyone else.
+func ReleaseInFlight(c *gin.Context) {
+	if release, ok := c.Get(loadShedReleaseKey); ok {
+		release.(func())()
+	}
+}
+
+// loadShedder tracks in-flight requests and DB pool usage to decide when to shed
+type loadShedder struct {
+	inFlight        atomic.Int64
+	maxInFlight     int64
+	lowThreshold    float64
+	normalThreshold float64
+	retryAfter      string
+	poolStats       func() sql.DBStats
+}
+
+// saturation returns the higher of in-flight request and DB pool utilization, from 0 to 1+
+func (s *loadShedder) saturation(inFlight int64) float64 {
+	saturation := float64(inFlight) / float64(s.maxInFlight)
+	if s.poolStats != nil {
+		stats := s.poolStats()
+		if stats.MaxOpenConnections > 0 {
+			if pool := float64(stats.InUse) / float64(stats.MaxOpenConnections); pool > saturation {
+				saturation = pool
+			}
+		}
+	}
+	return saturation
+}
+
+func (s *loadShedder) shouldShed(priority RequestPriority, saturation float64) bool {
+	switch priority {
+	case PriorityLow:
+		return saturation >= s.lowThreshold
+	case PriorityNormal:
+		return saturation >= s.normalThreshold
+	}
+	return false
+}
+
+// LoadSheddingMiddleware rejects low-priority traffic with 503 and Retry-After when the
+// server is under pressure, so auth and progress endpoints stay responsive during spikes.
+// Low-priority requests (exports, ?all=true listings) are shed first, then normal ones;
+// critical requests are never shed. Disabled when cfg.LoadShedMaxInFlight is 0.
+func LoadSheddingMiddleware(cfg *config.Config, poolStats func() sql.DBStats) gin.HandlerFunc {
+	if cfg.LoadShedMaxInFlight <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	shedder := &loadShedder{
+		maxInFlight:     int64(cfg.LoadShedMaxInFlight),
+		lowThreshold:    cfg.LoadShedLowThreshold,
+		normalThreshold: cfg.LoadShedNormalThreshold,
+		retryAfter:      strconv.Itoa(cfg.LoadShedRetryAfterSeconds),
+		poolStats:       poolStats,
+	}
+
+	return func(c *gin.Context) {
+		inFlight := shedder.inFlight.Add(1)
+		var once sync.Once
+		release := func() {
+			once.Do(func() { shedder.inFlight.Add(-1) })
+		}
+		defer release()
+
+		priority := requestPriority(c)
+		if priority != PriorityCritical {
+			if saturation := shedder.saturation(inFlight); shedder.shouldShed(priority, saturation) {
+				log.Printf("Load shedding %s %s (priority=%d, saturation=%.2f)", c.Request.Method, c.Request.URL.Path, priority, saturation)
+				c.Header("Retry-After", shedder.retryAfter)
+				c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+					"error":       "Server is under heavy load. Please try again later.",
+					"retry_after": cfg.LoadShedRetryAfterSeconds,
+				})
+				return
+			}
+		}
+
+		c.Set(loadShedReleaseKey, release)
+		c.Next()
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/config"
+)
+
+func TestRequestPriority(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := map[string]RequestPriority{
+		"/health/ready":                PriorityCritical,
+		"/api/v1/progress/quests":      PriorityCritical,
+		"/api/v1/auth/device/token":    PriorityCritical,
+		"/api/v1/admin/export/quests":  PriorityLow,
+		"/api/v1/items?all=true":       PriorityLow,
+		"/api/v1/sync/snapshot":        PriorityLow,
+		"/api/v1/items?page=2":         PriorityNormal,
+		"/api/v1/leaderboards/overall": PriorityNormal,
+	}
+	for target, want := range cases {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", target, nil)
+		if got := requestPriority(c); got != want {
+			t.Errorf("requestPriority(%s) = %d, want %d", target, got, want)
+		}
+	}
+}
+
+func TestLoadShedderShedsLowPriorityFirst(t *testing.T) {
+	s := &loadShedder{maxInFlight: 10, lowThreshold: 0.7, normalThreshold: 0.9}
+
+	if s.shouldShed(PriorityLow, s.saturation(6)) || s.shouldShed(PriorityNormal, s.saturation(6)) {
+		t.Error("nothing should be shed at 60% saturation")
+	}
+	if !s.shouldShed(PriorityLow, s.saturation(8)) || s.shouldShed(PriorityNormal, s.saturation(8)) {
+		t.Error("only low priority should be shed at 80% saturation")
+	}
+	if !s.shouldShed(PriorityNormal, s.saturation(10)) || s.shouldShed(PriorityCritical, s.saturation(50)) {
+		t.Error("normal priority should be shed at full saturation, critical never")
+	}
+
+	// An exhausted DB pool counts as saturated even with few requests in flight
+	s.poolStats = func() sql.DBStats { return sql.DBStats{MaxOpenConnections: 25, InUse: 25} }
+	if got := s.saturation(1); got != 1 {
+		t.Errorf("saturation with exhausted pool = %.2f, want 1", got)
+	}
+}
+
+func TestLoadSheddingIgnoresHeldLongPolls(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{LoadShedMaxInFlight: 4, LoadShedLowThreshold: 0.7, LoadShedNormalThreshold: 0.9, LoadShedRetryAfterSeconds: 5}
+
+	held := make(chan struct{})
+	waiting := make(chan struct{}, 10)
+	r := gin.New()
+	r.Use(LoadSheddingMiddleware(cfg, nil))
+	r.GET("/api/v1/me/notifications/poll", func(c *gin.Context) {
+		ReleaseInFlight(c)
+		waiting <- struct{}{}
+		<-held
+		c.Status(http.StatusOK)
+	})
+	r.GET("/api/v1/items", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	// More held polls than the whole in-flight budget
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/v1/me/notifications/poll", nil))
+		}()
+		<-waiting
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/items", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected a listing to be served while polls are held, got %d", w.Code)
+	}
+
+	close(held)
+	wg.Wait()
+}
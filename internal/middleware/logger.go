@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -21,6 +22,11 @@ func (w responseBodyWriter) Write(b []byte) (int, error) {
 	return w.ResponseWriter.Write(b)
 }
 
+// Unwrap returns the writer being captured, for http.ResponseController
+func (w *responseBodyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // LoggerMiddleware logs all requests to the audit_logs table
 func LoggerMiddleware(auditLogRepo *repository.AuditLogRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -56,8 +62,12 @@ func LoggerMiddleware(auditLogRepo *repository.AuditLogRepository) gin.HandlerFu
 					userID = &key.UserID
 				}
 			}
-			// JWT token ID would need to be extracted differently
-			// For now, we'll just use the user ID from API key
+		}
+		if val, exists := c.Get(JWTTokenContextKey); exists {
+			if token, ok := val.(*models.JWTToken); ok {
+				jwtTokenID = &token.ID
+				userID = &token.UserID
+			}
 		}
 
 		// Parse request body as JSON if possible
@@ -81,6 +91,16 @@ func LoggerMiddleware(auditLogRepo *repository.AuditLogRepository) gin.HandlerFu
 			RequestBody:    requestBodyJSON,
 			ResponseTimeMs: responseTime,
 			IPAddress:      c.ClientIP(),
+			RequestID:      RequestID(c),
+		}
+		if val, exists := c.Get(AuditChangeContextKey); exists {
+			if change, ok := val.(*AuditChange); ok {
+				auditLog.EntityType = change.EntityType
+				auditLog.EntityID = change.EntityID
+				if change.Changes != nil {
+					auditLog.Changes = &change.Changes
+				}
+			}
 		}
 
 		// Save audit log asynchronously
@@ -1,74 +1,389 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/mat/arcapi/internal/config"
+	"github.com/mat/arcapi/internal/models"
 	"github.com/mat/arcapi/internal/services"
 )
 
-// RateLimitMiddleware implements rate limiting with configurable limits
-func RateLimitMiddleware(cacheService *services.CacheService, limit int, windowSeconds int) gin.HandlerFunc {
+// RateLimitTier decides which of config.RateLimitTiers limits a request
+type RateLimitTier string
+
+const (
+	TierAnonymous RateLimitTier = "anonymous" // no credentials; counted per IP
+	TierUser      RateLimitTier = "user"      // Bearer token; counted per user
+	TierAPIKey    RateLimitTier = "api_key"   // X-API-Key; counted per key
+	TierAdmin     RateLimitTier = "admin"     // admin role with either; counted per user
+)
+
+// rateLimitContextKey holds the *pendingRateLimit of a request whose credentials have not
+// been checked yet
+const rateLimitContextKey = "rate_limit_pending"
+
+// rateLimitHeaders are the response headers describing the request's rate limit
+var rateLimitHeaders = []string{"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"}
+
+// errNoRedis is returned by rateLimiter.incr when Redis is not configured
+var errNoRedis = errors.New("redis is not configured")
+
+// rateLimiter counts requests in Redis, in per-tier or per-route buckets. When Redis is
+// not configured or a command fails it falls back to local, limiting per instance.
+type rateLimiter struct {
+	cacheService *services.CacheService
+	local        *localRateLimiter
+	tiers        config.RateLimitTiers
+	window       time.Duration
+	routes       *RateLimitRegistry
+}
+
+// pendingRateLimit is a request left uncounted until its credentials are checked
+type pendingRateLimit struct {
+	limiter *rateLimiter
+	applied bool
+}
+
+// RateLimitMiddleware implements rate limiting with per-tier limits. Requests matching
+// one of the routes' rules (longest path prefix wins) are counted in that rule's own
+// bucket instead of the shared one, so e.g. device login polling gets a stricter limit and
+// cheap cached reads a looser one without touching the shared quota.
+//
+// Requests without credentials are limited per IP at the anonymous tier straight away.
+// Requests with credentials are counted once an auth middleware has identified them (see
+// ApplyRateLimit), at the user, API key or admin tier. Those that fail authentication, or
+// reach a route that never checks them, are counted per IP at the anonymous tier instead.
+func RateLimitMiddleware(cacheService *services.CacheService, tiers config.RateLimitTiers, windowSeconds int, routes *RateLimitRegistry) gin.HandlerFunc {
+	limiter := &rateLimiter{
+		cacheService: cacheService,
+		local:        newLocalRateLimiter(),
+		tiers:        tiers,
+		window:       time.Duration(windowSeconds) * time.Second,
+		routes:       routes,
+	}
 	return func(c *gin.Context) {
-		// Skip rate limiting for health check endpoint
-		if c.Request.URL.Path == "/health" {
-			c.Next()
+		// Behind an auth middleware the request is identified already
+		if val, exists := c.Get("user"); exists {
+			if user, ok := val.(*models.User); ok {
+				tier, identifier := requestIdentity(c, user)
+				if limiter.allow(c, tier, identifier) {
+					c.Next()
+				}
+				return
+			}
+		}
+
+		if !hasCredentials(c) {
+			if limiter.allow(c, TierAnonymous, c.ClientIP()) {
+				c.Next()
+			}
 			return
 		}
 
-		// Get identifier for rate limiting (use user ID if authenticated, otherwise IP)
-		identifier := c.ClientIP()
+		// Credentials that keep failing are held to the anonymous limit. The headers set
+		// here are replaced once the request is authenticated.
+		unverified := "unverified:" + c.ClientIP()
+		if !limiter.peek(c, unverified) {
+			return
+		}
 
-		// Try to get user ID from context if available
-		if userID, exists := c.Get("user_id"); exists {
-			if id, ok := userID.(uint); ok {
-				identifier = "user:" + strconv.Itoa(int(id))
-			}
+		pending := &pendingRateLimit{limiter: limiter}
+		c.Set(rateLimitContextKey, pending)
+		c.Next()
+		if !pending.applied {
+			limiter.count(c, TierAnonymous, unverified)
+		}
+	}
+}
+
+// ApplyRateLimit counts an authenticated request against its tier's limit, if
+// RateLimitMiddleware deferred it. It returns false if the request was rejected.
+func ApplyRateLimit(c *gin.Context, user *models.User) bool {
+	val, exists := c.Get(rateLimitContextKey)
+	if !exists {
+		return true
+	}
+	pending, ok := val.(*pendingRateLimit)
+	if !ok || pending.applied {
+		return true
+	}
+	pending.applied = true
+
+	tier, identifier := requestIdentity(c, user)
+	return pending.limiter.allow(c, tier, identifier)
+}
+
+// RequestTier returns the tier an authenticated request is rate limited at
+func RequestTier(c *gin.Context, user *models.User) RateLimitTier {
+	tier, _ := requestIdentity(c, user)
+	return tier
+}
+
+// requestIdentity resolves the tier of an authenticated request and the identifier it is
+// counted under: the API key for API key requests, the user otherwise
+func requestIdentity(c *gin.Context, user *models.User) (RateLimitTier, string) {
+	userIdentifier := "user:" + strconv.Itoa(int(user.ID))
+	if user.Role == models.RoleAdmin {
+		return TierAdmin, userIdentifier
+	}
+	if val, ok := c.Get(APIKeyContextKey); ok {
+		if apiKey, ok := val.(*models.APIKey); ok {
+			return TierAPIKey, "key:" + strconv.Itoa(int(apiKey.ID))
 		}
+	}
+	return TierUser, userIdentifier
+}
 
-		// Use configured rate limits
-		window := time.Duration(windowSeconds) * time.Second
-		key := "rate_limit:" + identifier
+func hasCredentials(c *gin.Context) bool {
+	return c.GetHeader("X-API-Key") != "" || strings.HasPrefix(c.GetHeader("Authorization"), "Bearer ")
+}
 
-		if cacheService != nil {
-			// Use Redis for distributed rate limiting
-			ctx := cacheService.Context()
-			client := cacheService.Client()
+// bucket returns the limit, window and key prefix for a request at tier. A limit of 0
+// means the request is not rate limited.
+func (l *rateLimiter) bucket(c *gin.Context, tier RateLimitTier) (int, time.Duration, string) {
+	if rule := l.routes.match(c.Request.URL.Path); rule != nil {
+		return rule.Limit, rule.Window, rule.PathPrefix + ":"
+	}
+	return l.tierLimit(tier), l.window, ""
+}
 
-			count, err := client.Incr(ctx, key).Result()
-			if err == nil {
-				// Set expiration on first request
-				if count == 1 {
-					client.Expire(ctx, key, window)
-				}
+func (l *rateLimiter) tierLimit(tier RateLimitTier) int {
+	return TierLimit(l.tiers, tier)
+}
 
-				// Check if limit exceeded
-				if count > int64(limit) {
-					c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
-					c.Header("X-RateLimit-Remaining", "0")
-					c.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(window).Unix(), 10))
-					c.JSON(http.StatusTooManyRequests, gin.H{
-						"error":       "Rate limit exceeded. Please try again later.",
-						"retry_after": int(window.Seconds()),
-					})
-					c.Abort()
-					return
-				}
+// TierLimit returns the request limit per window of tier; 0 means unlimited
+func TierLimit(tiers config.RateLimitTiers, tier RateLimitTier) int {
+	switch tier {
+	case TierUser:
+		return tiers.User
+	case TierAPIKey:
+		return tiers.APIKey
+	case TierAdmin:
+		return tiers.Admin
+	}
+	return tiers.Anonymous
+}
 
-				// Set rate limit headers
-				remaining := limit - int(count)
-				if remaining < 0 {
-					remaining = 0
-				}
-				c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
-				c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
-				c.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(window).Unix(), 10))
-			}
-			// If Redis error, allow request (fail open) but log it
+// allow counts the request and sets the rate limit headers, responding with 429 and
+// returning false once the limit is exceeded
+func (l *rateLimiter) allow(c *gin.Context, tier RateLimitTier, identifier string) bool {
+	limit, window, bucket := l.bucket(c, tier)
+	if limit == 0 {
+		// Headers set before authentication don't apply to an unlimited tier
+		for _, header := range rateLimitHeaders {
+			c.Writer.Header().Del(header)
 		}
+		return true
+	}
 
-		c.Next()
+	key := "rate_limit:" + bucket + identifier
+	count, reset, err := l.incr(key, window)
+	if err != nil {
+		// Without Redis each instance keeps its own token buckets
+		allowed, remaining, reset := l.local.take(key, limit, window, time.Now())
+		if !allowed {
+			l.reject(c, limit, reset)
+			return false
+		}
+		setRateLimitHeaders(c, limit, remaining, reset)
+		return true
+	}
+
+	// Check if limit exceeded
+	if count > int64(limit) {
+		l.reject(c, limit, reset)
+		return false
+	}
+	setRateLimitHeaders(c, limit, limit-int(count), reset)
+	return true
+}
+
+// incr counts a request in key, starting a window on the first one, and returns the
+// count and the time left until the window resets
+func (l *rateLimiter) incr(key string, window time.Duration) (int64, time.Duration, error) {
+	if l.cacheService == nil {
+		return 0, 0, errNoRedis
+	}
+	ctx := l.cacheService.Context()
+	client := l.cacheService.Client()
+
+	pipe := client.Pipeline()
+	incr := pipe.Incr(ctx, key)
+	ttl := pipe.TTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, 0, err
+	}
+
+	reset := ttl.Val()
+	if reset <= 0 {
+		// A new key has no expiry yet
+		client.Expire(ctx, key, window)
+		reset = window
+	}
+	return incr.Val(), reset, nil
+}
+
+// count adds a finished request to a bucket without checking it
+func (l *rateLimiter) count(c *gin.Context, tier RateLimitTier, identifier string) {
+	limit, window, bucket := l.bucket(c, tier)
+	if limit == 0 {
+		return
+	}
+	key := "rate_limit:" + bucket + identifier
+	if _, _, err := l.incr(key, window); err != nil {
+		l.local.take(key, limit, window, time.Now())
+	}
+}
+
+// peek sets the rate limit headers for a request that will be counted in identifier's
+// anonymous-tier bucket, responding with 429 and returning false if it is already full
+func (l *rateLimiter) peek(c *gin.Context, identifier string) bool {
+	limit, window, bucket := l.bucket(c, TierAnonymous)
+	if limit == 0 {
+		return true
+	}
+
+	key := "rate_limit:" + bucket + identifier
+	if l.cacheService == nil {
+		return l.peekLocal(c, key, limit, window)
+	}
+	ctx := l.cacheService.Context()
+	pipe := l.cacheService.Client().Pipeline()
+	get := pipe.Get(ctx, key)
+	ttl := pipe.TTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return l.peekLocal(c, key, limit, window)
+	}
+
+	count, err := get.Int64()
+	if err != nil {
+		count = 0
+	}
+	reset := ttl.Val()
+	if reset <= 0 {
+		reset = window
+	}
+	if count >= int64(limit) {
+		l.reject(c, limit, reset)
+		return false
+	}
+	setRateLimitHeaders(c, limit, limit-int(count)-1, reset)
+	return true
+}
+
+func (l *rateLimiter) peekLocal(c *gin.Context, key string, limit int, window time.Duration) bool {
+	allowed, remaining, reset := l.local.peek(key, limit, window, time.Now())
+	if !allowed {
+		l.reject(c, limit, reset)
+		return false
+	}
+	// This request takes one of the remaining tokens
+	setRateLimitHeaders(c, limit, remaining-1, reset)
+	return true
+}
+
+func (l *rateLimiter) reject(c *gin.Context, limit int, reset time.Duration) {
+	setRateLimitHeaders(c, limit, 0, reset)
+	retryAfter := retryAfterSeconds(reset)
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Rate limit exceeded. Please try again later.",
+		"retry_after": retryAfter,
+	})
+	c.Abort()
+}
+
+// setRateLimitHeaders sets the X-RateLimit-* headers. Reset is a Unix timestamp.
+func setRateLimitHeaders(c *gin.Context, limit, remaining int, reset time.Duration) {
+	if remaining < 0 {
+		remaining = 0
+	}
+	c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(reset).Unix(), 10))
+}
+
+// retryAfterSeconds rounds the time until reset up to whole seconds, so clients waiting
+// that long never retry early
+func retryAfterSeconds(reset time.Duration) int {
+	seconds := int((reset + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// RateLimitRegistry holds the per-route rate limits. Route groups declare the limits they
+// need as they are registered; rules configured in RATE_LIMIT_ROUTES override a declared
+// limit with the same path prefix, or add new ones.
+type RateLimitRegistry struct {
+	mu        sync.RWMutex
+	declared  map[string]config.RateLimitRule
+	overrides map[string]config.RateLimitRule
+}
+
+// NewRateLimitRegistry returns a registry with the configured overrides
+func NewRateLimitRegistry(overrides []config.RateLimitRule) *RateLimitRegistry {
+	r := &RateLimitRegistry{
+		declared:  make(map[string]config.RateLimitRule),
+		overrides: make(map[string]config.RateLimitRule, len(overrides)),
+	}
+	for _, rule := range overrides {
+		r.overrides[rule.PathPrefix] = rule
+	}
+	return r
+}
+
+// Declare gives requests under pathPrefix their own bucket of limit requests per window.
+// A limit of 0 exempts them. It returns the registry so declarations can be chained.
+func (r *RateLimitRegistry) Declare(pathPrefix string, limit int, window time.Duration) *RateLimitRegistry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.declared[pathPrefix] = config.RateLimitRule{PathPrefix: pathPrefix, Limit: limit, Window: window}
+	return r
+}
+
+// Rules returns the effective rules, sorted by path prefix
+func (r *RateLimitRegistry) Rules() []config.RateLimitRule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rules := make([]config.RateLimitRule, 0, len(r.declared)+len(r.overrides))
+	for prefix, rule := range r.declared {
+		if _, overridden := r.overrides[prefix]; !overridden {
+			rules = append(rules, rule)
+		}
+	}
+	for _, rule := range r.overrides {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].PathPrefix < rules[j].PathPrefix })
+	return rules
+}
+
+// match returns the rule with the longest prefix matching path, if any. Overrides win
+// over declarations with the same prefix.
+func (r *RateLimitRegistry) match(path string) *config.RateLimitRule {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var best *config.RateLimitRule
+	for _, rules := range []map[string]config.RateLimitRule{r.overrides, r.declared} {
+		for prefix, rule := range rules {
+			if strings.HasPrefix(path, prefix) && (best == nil || len(prefix) > len(best.PathPrefix)) {
+				rule := rule
+				best = &rule
+			}
+		}
 	}
+	return best
 }
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+)
+
+// localBucketSweepInterval is how often full, idle buckets are dropped from memory
+const localBucketSweepInterval = time.Minute
+
+// localRateLimiter is a process-local token bucket limiter used while Redis is not
+// configured or unreachable. Each bucket holds up to limit tokens and refills at limit per
+// window, so it allows the same average rate as the Redis windows, counted per instance.
+type localRateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	fullAt time.Time
+}
+
+func newLocalRateLimiter() *localRateLimiter {
+	return &localRateLimiter{buckets: make(map[string]*tokenBucket)}
+}
+
+// take removes a token from key's bucket if one is left. It returns whether the request
+// is allowed, the whole tokens remaining and the time until the bucket is full again, or
+// until the next token when none are left.
+func (l *localRateLimiter) take(key string, limit int, window time.Duration, now time.Time) (bool, int, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.refill(key, limit, window, now)
+	if b.tokens < 1 {
+		return false, 0, tokenWait(1-b.tokens, limit, window)
+	}
+	b.tokens--
+	b.fullAt = now.Add(tokenWait(float64(limit)-b.tokens, limit, window))
+	return true, int(b.tokens), b.fullAt.Sub(now)
+}
+
+// peek is take without removing a token
+func (l *localRateLimiter) peek(key string, limit int, window time.Duration, now time.Time) (bool, int, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.refill(key, limit, window, now)
+	if b.tokens < 1 {
+		return false, 0, tokenWait(1-b.tokens, limit, window)
+	}
+	return true, int(b.tokens), b.fullAt.Sub(now)
+}
+
+// refill returns key's bucket with the tokens earned since it was last used. Callers hold
+// l.mu.
+func (l *localRateLimiter) refill(key string, limit int, window time.Duration, now time.Time) *tokenBucket {
+	if now.Sub(l.lastSweep) >= localBucketSweepInterval {
+		for k, b := range l.buckets {
+			if !now.Before(b.fullAt) {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(limit), last: now, fullAt: now}
+		l.buckets[key] = b
+		return b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * float64(limit) / window.Seconds()
+	if b.tokens > float64(limit) {
+		b.tokens = float64(limit)
+	}
+	b.last = now
+	return b
+}
+
+// tokenWait is how long it takes to earn tokens at limit per window
+func tokenWait(tokens float64, limit int, window time.Duration) time.Duration {
+	return time.Duration(tokens / float64(limit) * float64(window))
+}
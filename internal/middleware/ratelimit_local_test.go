@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/config"
+)
+
+func TestLocalRateLimiterRefills(t *testing.T) {
+	l := newLocalRateLimiter()
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	for i := 2; i >= 0; i-- {
+		allowed, remaining, _ := l.take("k", 3, time.Minute, now)
+		if !allowed || remaining != i {
+			t.Fatalf("take = %v, %d, want true, %d", allowed, remaining, i)
+		}
+	}
+	allowed, _, wait := l.take("k", 3, time.Minute, now)
+	if allowed || wait != 20*time.Second {
+		t.Errorf("expected the empty bucket to reject with a 20s wait, got %v, %s", allowed, wait)
+	}
+
+	// One token refills every 20 seconds
+	if allowed, remaining, _ := l.take("k", 3, time.Minute, now.Add(20*time.Second)); !allowed || remaining != 0 {
+		t.Errorf("expected a refilled token, got %v, %d", allowed, remaining)
+	}
+	if allowed, _, _ := l.peek("other", 3, time.Minute, now); !allowed {
+		t.Error("buckets should be independent")
+	}
+}
+
+func TestLocalRateLimiterSweepsFullBuckets(t *testing.T) {
+	l := newLocalRateLimiter()
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	l.take("idle", 10, time.Minute, now)
+	l.take("busy", 1, time.Hour, now)
+
+	l.peek("idle", 10, time.Minute, now.Add(2*time.Minute))
+	if _, ok := l.buckets["busy"]; !ok {
+		t.Error("a bucket still refilling should be kept")
+	}
+	if len(l.buckets) != 2 {
+		t.Errorf("buckets = %d, want 2", len(l.buckets))
+	}
+	// idle was full again, so it was swept and the peek started a new bucket
+	if b := l.buckets["idle"]; b.tokens != 10 {
+		t.Errorf("expected a fresh idle bucket, got %v tokens", b.tokens)
+	}
+}
+
+func TestRateLimiterFallsBackWithoutRedis(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	l := &rateLimiter{local: newLocalRateLimiter(), tiers: config.RateLimitTiers{Anonymous: 2}, window: time.Minute}
+
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/v1/items", nil)
+		allowed := l.allow(c, TierAnonymous, "203.0.113.7")
+		if allowed != (i < 2) {
+			t.Fatalf("request %d: allowed = %v", i+1, allowed)
+		}
+		if !allowed && (w.Code != 429 || w.Header().Get("Retry-After") != "30") {
+			t.Errorf("expected a 429 with Retry-After 30, got %d %q", w.Code, w.Header().Get("Retry-After"))
+		}
+	}
+}
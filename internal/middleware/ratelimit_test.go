@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/config"
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestRequestIdentity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	user := &models.User{ID: 4, Role: models.RoleUser}
+	admin := &models.User{ID: 1, Role: models.RoleAdmin}
+	key := &models.APIKey{ID: 9}
+
+	cases := []struct {
+		name           string
+		user           *models.User
+		apiKey         *models.APIKey
+		wantTier       RateLimitTier
+		wantIdentifier string
+	}{
+		{"bearer user", user, nil, TierUser, "user:4"},
+		{"api key", user, key, TierAPIKey, "key:9"},
+		{"admin", admin, nil, TierAdmin, "user:1"},
+		{"admin api key", admin, key, TierAdmin, "user:1"},
+	}
+	for _, tc := range cases {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		if tc.apiKey != nil {
+			c.Set(APIKeyContextKey, tc.apiKey)
+		}
+		tier, identifier := requestIdentity(c, tc.user)
+		if tier != tc.wantTier || identifier != tc.wantIdentifier {
+			t.Errorf("%s: requestIdentity() = %s, %s, want %s, %s", tc.name, tier, identifier, tc.wantTier, tc.wantIdentifier)
+		}
+	}
+}
+
+func TestRateLimiterBucket(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	l := &rateLimiter{
+		tiers:  config.RateLimitTiers{Anonymous: 20, User: 60, APIKey: 120, Admin: 300},
+		window: time.Minute,
+		routes: NewRateLimitRegistry(nil).Declare("/api/v1/config", 5, time.Hour),
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/api/v1/items", nil)
+	for tier, want := range map[RateLimitTier]int{TierAnonymous: 20, TierUser: 60, TierAPIKey: 120, TierAdmin: 300} {
+		if limit, window, bucket := l.bucket(c, tier); limit != want || window != time.Minute || bucket != "" {
+			t.Errorf("bucket(%s) = %d, %s, %q", tier, limit, window, bucket)
+		}
+	}
+
+	// Route rules apply to every tier
+	c.Request = httptest.NewRequest("GET", "/api/v1/config/app", nil)
+	if limit, window, bucket := l.bucket(c, TierAPIKey); limit != 5 || window != time.Hour || bucket != "/api/v1/config:" {
+		t.Errorf("bucket under a rule = %d, %s, %q", limit, window, bucket)
+	}
+}
+
+func TestRateLimitRegistry(t *testing.T) {
+	routes := NewRateLimitRegistry([]config.RateLimitRule{
+		{PathPrefix: "/api/v1/config", Limit: 0, Window: time.Minute},
+	}).
+		Declare("/api/v1/config", 300, time.Minute).
+		Declare("/api/v1/auth/device", 30, time.Minute).
+		Declare("/api/v1/auth/device/code", 10, time.Minute)
+
+	cases := []struct {
+		path       string
+		wantPrefix string
+		wantLimit  int
+	}{
+		{"/api/v1/items", "", 0},
+		{"/api/v1/config/app", "/api/v1/config", 0}, // the override wins over the declaration
+		{"/api/v1/auth/device/token", "/api/v1/auth/device", 30},
+		{"/api/v1/auth/device/code", "/api/v1/auth/device/code", 10}, // longest prefix wins
+	}
+	for _, tc := range cases {
+		rule := routes.match(tc.path)
+		if tc.wantPrefix == "" {
+			if rule != nil {
+				t.Errorf("match(%q) = %+v, want nil", tc.path, rule)
+			}
+			continue
+		}
+		if rule == nil || rule.PathPrefix != tc.wantPrefix || rule.Limit != tc.wantLimit {
+			t.Errorf("match(%q) = %+v, want %s=%d", tc.path, rule, tc.wantPrefix, tc.wantLimit)
+		}
+	}
+
+	if rules := routes.Rules(); len(rules) != 3 || rules[0].PathPrefix != "/api/v1/auth/device" || rules[2].Limit != 0 {
+		t.Errorf("Rules() = %+v", rules)
+	}
+	if (*RateLimitRegistry)(nil).match("/api/v1/config") != nil {
+		t.Error("nil registry matched a rule")
+	}
+}
+
+func TestHasCredentials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		header, value string
+		want          bool
+	}{
+		{"", "", false},
+		{"X-API-Key", "abc", true},
+		{"Authorization", "Bearer x", true},
+		{"Authorization", "Basic x", false},
+	}
+	for _, tc := range cases {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/api/v1/items", nil)
+		if tc.header != "" {
+			c.Request.Header.Set(tc.header, tc.value)
+		}
+		if got := hasCredentials(c); got != tc.want {
+			t.Errorf("hasCredentials(%s: %s) = %v, want %v", tc.header, tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestRateLimiterReject(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/items", nil)
+
+	before := time.Now()
+	(&rateLimiter{}).reject(c, 60, 41500*time.Millisecond)
+
+	if w.Code != 429 || !c.IsAborted() {
+		t.Fatalf("expected an aborted 429, got %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "42" {
+		t.Errorf("Retry-After = %q, want 42", got)
+	}
+	if w.Header().Get("X-RateLimit-Limit") != "60" || w.Header().Get("X-RateLimit-Remaining") != "0" {
+		t.Errorf("unexpected headers %v", w.Header())
+	}
+	reset, err := strconv.ParseInt(w.Header().Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil || reset < before.Add(41*time.Second).Unix() || reset > time.Now().Add(42*time.Second).Unix() {
+		t.Errorf("X-RateLimit-Reset = %q", w.Header().Get("X-RateLimit-Reset"))
+	}
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	cases := map[time.Duration]int{
+		0:                       1,
+		300 * time.Millisecond:  1,
+		time.Second:             1,
+		1001 * time.Millisecond: 2,
+		time.Minute:             60,
+	}
+	for reset, want := range cases {
+		if got := retryAfterSeconds(reset); got != want {
+			t.Errorf("retryAfterSeconds(%s) = %d, want %d", reset, got, want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the request ID in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDContextKey is the context key the request ID is stored under
+const RequestIDContextKey = "request_id"
+
+// maxRequestIDLength bounds the request IDs accepted from clients and proxies
+const maxRequestIDLength = 128
+
+// RequestID returns the ID of the request, or "" outside RequestIDMiddleware
+func RequestID(c *gin.Context) string {
+	return c.GetString(RequestIDContextKey)
+}
+
+// requestIDWriter holds back JSON error responses so the request ID can be added to them;
+// every other response is passed straight through
+type requestIDWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	decided   bool
+	buffering bool
+}
+
+func (w *requestIDWriter) Write(b []byte) (int, error) {
+	if w.holdBack() {
+		return w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *requestIDWriter) WriteString(s string) (int, error) {
+	if w.holdBack() {
+		return w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Flush is a no-op while an error body is held back
+func (w *requestIDWriter) Flush() {
+	if !w.buffering {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// Unwrap exposes the wrapped writer to http.ResponseController
+func (w *requestIDWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// holdBack decides on the first write whether the body is a JSON error
+func (w *requestIDWriter) holdBack() bool {
+	if !w.decided {
+		w.decided = true
+		w.buffering = w.Status() >= 400 && strings.HasPrefix(w.Header().Get("Content-Type"), "application/json")
+	}
+	return w.buffering
+}
+
+// RequestIDMiddleware gives every request an ID, taken from a well-formed X-Request-ID
+// header set by the client or a proxy or generated otherwise. The ID is stored in the
+// context, echoed in the X-Request-ID response header and added as "request_id" to JSON
+// error bodies, so users can cite it when reporting a failure.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !isValidRequestID(id) {
+			id = newRequestID()
+		}
+		c.Set(RequestIDContextKey, id)
+		c.Header(RequestIDHeader, id)
+
+		w := &requestIDWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if w.buffering {
+			body := w.body.Bytes()
+			if withID, err := addRequestID(body, id); err == nil {
+				body = withID
+			}
+			w.ResponseWriter.Write(body)
+		}
+	}
+}
+
+// addRequestID adds "request_id" to a JSON object with an "error" key
+func addRequestID(body []byte, id string) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	if _, ok := fields["error"]; !ok {
+		return body, nil
+	}
+	if _, ok := fields[RequestIDContextKey]; ok {
+		return body, nil
+	}
+	encoded, err := json.Marshal(id)
+	if err != nil {
+		return nil, err
+	}
+	fields[RequestIDContextKey] = encoded
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	// Match gin's c.JSON, which doesn't escape HTML
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(fields); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// isValidRequestID accepts IDs of letters, digits and -_.:, which covers UUIDs and the
+// IDs common proxies and load balancers generate, and keeps anything else out of logs
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		switch ch := id[i]; {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9':
+		case ch == '-', ch == '_', ch == '.', ch == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns 128 random bits as 26 base32 characters
+func newRequestID() string {
+	return rand.Text()
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestIDMiddleware())
+	r.GET("/ok", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"request_id": RequestID(c)})
+	})
+	r.GET("/fail", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+	})
+	r.GET("/text", func(c *gin.Context) {
+		c.String(http.StatusBadRequest, "bad")
+	})
+
+	// A well-formed ID is echoed and stored in the context
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/ok", nil)
+	req.Header.Set(RequestIDHeader, "lb-1234:abcd")
+	r.ServeHTTP(w, req)
+	if got := w.Header().Get(RequestIDHeader); got != "lb-1234:abcd" {
+		t.Errorf("X-Request-ID = %q, want the client's", got)
+	}
+	if w.Body.String() != `{"request_id":"lb-1234:abcd"}` {
+		t.Errorf("body = %s", w.Body.String())
+	}
+
+	// A malformed ID is replaced, and error bodies carry the ID
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/fail", nil)
+	req.Header.Set(RequestIDHeader, "bad id\n")
+	r.ServeHTTP(w, req)
+	id := w.Header().Get(RequestIDHeader)
+	if id == "" || id == "bad id\n" || !isValidRequestID(id) {
+		t.Fatalf("X-Request-ID = %q, want a generated ID", id)
+	}
+	if want := `{"error":"Item not found","request_id":"` + id + `"}`; w.Code != http.StatusNotFound || w.Body.String() != want {
+		t.Errorf("error response = %d %s, want %s", w.Code, w.Body.String(), want)
+	}
+
+	// Non-JSON errors are left alone
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/text", nil))
+	if w.Code != http.StatusBadRequest || w.Body.String() != "bad" || w.Header().Get(RequestIDHeader) == "" {
+		t.Errorf("text error = %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestIsValidRequestID(t *testing.T) {
+	cases := map[string]bool{
+		"":                                     false,
+		"0b6f3a2e-8f1c-4c1e-9d7a-1f2e3d4c5b6a": true,
+		"Root=1-67891233-abcdef012345678912345678": false, // '=' isn't allowed
+		"trace.id_01:2": true,
+		"a b":           false,
+	}
+	for id, want := range cases {
+		if got := isValidRequestID(id); got != want {
+			t.Errorf("isValidRequestID(%q) = %v, want %v", id, got, want)
+		}
+	}
+	if isValidRequestID(string(make([]byte, maxRequestIDLength+1))) {
+		t.Error("accepted an over-long request ID")
+	}
+}
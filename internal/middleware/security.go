@@ -8,8 +8,49 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mat/arcapi/internal/config"
 )
 
+// devCORSFrontend allows localhost origins when no frontends are configured
+var devCORSFrontend = &config.CORSFrontend{Name: "development", Headers: config.DefaultCORSHeaders, Credentials: true}
+
+// matchCORSFrontend returns the frontend origin belongs to. Exact origins take precedence
+// over wildcard subdomains; otherwise frontends are tried in order.
+func matchCORSFrontend(frontends []config.CORSFrontend, origin string) *config.CORSFrontend {
+	if len(frontends) == 0 {
+		// If no origins configured, allow localhost and same origin for development
+		if strings.Contains(origin, "localhost") || strings.Contains(origin, "127.0.0.1") {
+			return devCORSFrontend
+		}
+		return nil
+	}
+	for i := range frontends {
+		for _, allowed := range frontends[i].Origins {
+			if origin == allowed {
+				return &frontends[i]
+			}
+		}
+	}
+	for i := range frontends {
+		for _, allowed := range frontends[i].Origins {
+			if matchWildcardOrigin(allowed, origin) {
+				return &frontends[i]
+			}
+		}
+	}
+	return nil
+}
+
+// matchWildcardOrigin matches "scheme://*.domain" patterns against subdomains of domain
+func matchWildcardOrigin(pattern, origin string) bool {
+	scheme, host, ok := strings.Cut(pattern, "://*.")
+	if !ok {
+		return false
+	}
+	prefix := scheme + "://"
+	return strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, "."+host) && len(origin) > len(prefix)+len(host)+1
+}
+
 // appendCSPDomain parses the provided URL and appends its origin to the given CSP directive value.
 // Falls back to the raw value if parsing fails so configuration values added via env vars still work.
 func appendCSPDomain(directiveValue, rawURL string) string {
@@ -23,8 +64,9 @@ func appendCSPDomain(directiveValue, rawURL string) string {
 	return directiveValue + " " + rawURL
 }
 
-// SecurityMiddleware adds security headers and CORS support
-func SecurityMiddleware(allowedOrigins []string) gin.HandlerFunc {
+// SecurityMiddleware adds security headers and CORS support. Each frontend gets the
+// allowed headers and credentials policy it is configured with.
+func SecurityMiddleware(frontends []config.CORSFrontend) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get Supabase URL from environment for CSP
 		supabaseURL := os.Getenv("NEXT_PUBLIC_SUPABASE_URL")
@@ -66,30 +108,20 @@ func SecurityMiddleware(allowedOrigins []string) gin.HandlerFunc {
 		c.Header("Content-Security-Policy", csp)
 
 		// CORS headers
+		c.Header("Vary", "Origin")
 		origin := c.GetHeader("Origin")
 		if origin != "" {
-			allowed := false
-
-			// Check if origin is in allowed list
-			if len(allowedOrigins) > 0 {
-				for _, allowedOrigin := range allowedOrigins {
-					if origin == allowedOrigin {
-						allowed = true
-						break
-					}
-				}
-			} else {
-				// If no origins configured, allow localhost and same origin for development
-				if strings.Contains(origin, "localhost") || strings.Contains(origin, "127.0.0.1") {
-					allowed = true
-				}
-			}
+			frontend := matchCORSFrontend(frontends, origin)
+			allowed := frontend != nil
 
 			if allowed {
 				c.Header("Access-Control-Allow-Origin", origin)
-				c.Header("Access-Control-Allow-Credentials", "true")
+				if frontend.Credentials {
+					c.Header("Access-Control-Allow-Credentials", "true")
+				}
 				c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-				c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, X-Requested-With")
+				c.Header("Access-Control-Allow-Headers", strings.Join(frontend.Headers, ", "))
+				c.Header("Access-Control-Expose-Headers", "X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset, Retry-After, X-Request-ID")
 				c.Header("Access-Control-Max-Age", "3600")
 			}
 
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/mat/arcapi/internal/config"
+)
+
+func TestMatchCORSFrontend(t *testing.T) {
+	frontends := []config.CORSFrontend{
+		{Name: "community", Origins: []string{"https://*.arcdb.gg"}},
+		{Name: "dashboard", Origins: []string{"https://admin.arcdb.gg"}, Credentials: true},
+		{Name: "webview", Origins: []string{"capacitor://localhost"}},
+	}
+	cases := map[string]string{
+		"https://admin.arcdb.gg":  "dashboard", // exact beats an earlier wildcard
+		"https://www.arcdb.gg":    "community",
+		"https://a.b.arcdb.gg":    "community",
+		"capacitor://localhost":   "webview",
+		"https://arcdb.gg":        "",
+		"http://www.arcdb.gg":     "",
+		"https://evilarcdb.gg":    "",
+		"http://localhost:3000":   "",
+		"https://admin.arcdb.gg.": "",
+	}
+	for origin, want := range cases {
+		got := ""
+		if frontend := matchCORSFrontend(frontends, origin); frontend != nil {
+			got = frontend.Name
+		}
+		if got != want {
+			t.Errorf("matchCORSFrontend(%q) = %q, want %q", origin, got, want)
+		}
+	}
+
+	// Without frontends only local development origins are allowed
+	if f := matchCORSFrontend(nil, "http://localhost:3000"); f == nil || !f.Credentials {
+		t.Errorf("expected localhost to be allowed in development, got %v", f)
+	}
+	if f := matchCORSFrontend(nil, "https://admin.arcdb.gg"); f != nil {
+		t.Errorf("expected other origins to be rejected in development, got %v", f)
+	}
+}
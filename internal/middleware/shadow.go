@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// shadowTimeout bounds each mirrored request
+	shadowTimeout = 10 * time.Second
+
+	// shadowMaxInFlight caps concurrent mirrored requests; further samples are dropped
+	// rather than queued so a slow shadow deployment never builds up a backlog
+	shadowMaxInFlight = 64
+)
+
+// shadowSkipHeaders are not copied to mirrored requests
+var shadowSkipHeaders = map[string]bool{
+	"Connection":        true,
+	"Keep-Alive":        true,
+	"Proxy-Connection":  true,
+	"Te":                true,
+	"Trailer":           true,
+	"Transfer-Encoding": true,
+	"Upgrade":           true,
+	"Content-Length":    true,
+}
+
+// shadowCredentialHeaders are only copied to mirrored requests when forwarding credentials
+// is enabled, so a sampled request never hands a user's session to another deployment
+var shadowCredentialHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"X-Api-Key":           true,
+	"Cookie":              true,
+}
+
+// shadowSkipKey marks a request that must not be mirrored
+const shadowSkipKey = "shadow_skip"
+
+// SkipShadowTraffic keeps the request from being mirrored. Handlers that hold a request
+// open, like notification long-polls, call it so the shadow deployment doesn't tie up a
+// slot for the whole wait.
+func SkipShadowTraffic(c *gin.Context) {
+	c.Set(shadowSkipKey, true)
+}
+
+// ShadowTrafficMiddleware mirrors percent of read requests under /api/v1 to target after
+// they've been served, and logs those where the shadow deployment answers with a
+// different status code. Mirroring happens in the background and never affects the
+// primary response. Mirrored requests carry X-Shadow-Request: 1, and credentials only when
+// forwardCredentials is set.
+func ShadowTrafficMiddleware(target *url.URL, percent float64, forwardCredentials bool) gin.HandlerFunc {
+	client := &http.Client{
+		Timeout: shadowTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	slots := make(chan struct{}, shadowMaxInFlight)
+
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		if (method != http.MethodGet && method != http.MethodHead) ||
+			!strings.HasPrefix(c.Request.URL.Path, "/api/v1/") ||
+			rand.Float64()*100 >= percent {
+			c.Next()
+			return
+		}
+
+		uri := c.Request.URL.RequestURI()
+		header := c.Request.Header.Clone()
+		c.Next()
+		primary := c.Writer.Status()
+		if c.GetBool(shadowSkipKey) {
+			return
+		}
+
+		select {
+		case slots <- struct{}{}:
+		default:
+			return
+		}
+		go func() {
+			defer func() { <-slots }()
+			shadow, err := mirrorRequest(client, target, method, uri, header, forwardCredentials)
+			if err != nil {
+				log.Printf("Shadow request failed: %s %s: %v", method, uri, err)
+				return
+			}
+			if shadow != primary {
+				log.Printf("Shadow divergence: %s %s primary=%d shadow=%d", method, uri, primary, shadow)
+			}
+		}()
+	}
+}
+
+// mirrorRequest replays a request against target and returns the response status
+func mirrorRequest(client *http.Client, target *url.URL, method, uri string, header http.Header, forwardCredentials bool) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), shadowTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String()+uri, nil)
+	if err != nil {
+		return 0, err
+	}
+	for key, values := range header {
+		if !shadowSkipHeaders[key] && (forwardCredentials || !shadowCredentialHeaders[key]) {
+			req.Header[key] = values
+		}
+	}
+	req.Header.Set("X-Shadow-Request", "1")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
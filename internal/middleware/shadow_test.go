@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// shadowTarget starts a shadow deployment that reports each request's headers on the
+// returned channel
+func shadowTarget(t *testing.T) (*url.URL, <-chan http.Header) {
+	received := make(chan http.Header, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Clone()
+	}))
+	t.Cleanup(server.Close)
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return target, received
+}
+
+func shadowRouter(target *url.URL, forwardCredentials bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ShadowTrafficMiddleware(target, 100, forwardCredentials))
+	r.GET("/api/v1/items", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	r.GET("/api/v1/me/notifications/poll", func(c *gin.Context) {
+		SkipShadowTraffic(c)
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func credentialedRequest(path string) *http.Request {
+	req := httptest.NewRequest("GET", path, nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-API-Key", "key")
+	req.Header.Set("Cookie", "session=abc")
+	req.Header.Set("Accept-Language", "de")
+	return req
+}
+
+func awaitShadow(t *testing.T, received <-chan http.Header) http.Header {
+	select {
+	case header := <-received:
+		return header
+	case <-time.After(5 * time.Second):
+		t.Fatal("request was not mirrored")
+		return nil
+	}
+}
+
+func TestShadowTrafficStripsCredentials(t *testing.T) {
+	target, received := shadowTarget(t)
+	r := shadowRouter(target, false)
+
+	r.ServeHTTP(httptest.NewRecorder(), credentialedRequest("/api/v1/items"))
+	header := awaitShadow(t, received)
+	for _, name := range []string{"Authorization", "X-Api-Key", "Cookie"} {
+		if header.Get(name) != "" {
+			t.Errorf("%s was forwarded to the shadow deployment", name)
+		}
+	}
+	if header.Get("Accept-Language") != "de" || header.Get("X-Shadow-Request") != "1" {
+		t.Errorf("expected other headers and the shadow marker, got %v", header)
+	}
+}
+
+func TestShadowTrafficForwardsCredentialsWhenEnabled(t *testing.T) {
+	target, received := shadowTarget(t)
+	r := shadowRouter(target, true)
+
+	r.ServeHTTP(httptest.NewRecorder(), credentialedRequest("/api/v1/items"))
+	header := awaitShadow(t, received)
+	if header.Get("Authorization") != "Bearer secret" || header.Get("X-Api-Key") != "key" || header.Get("Cookie") != "session=abc" {
+		t.Errorf("expected credentials to be forwarded, got %v", header)
+	}
+}
+
+func TestShadowTrafficSkipsLongPolls(t *testing.T) {
+	target, received := shadowTarget(t)
+	r := shadowRouter(target, false)
+
+	r.ServeHTTP(httptest.NewRecorder(), credentialedRequest("/api/v1/me/notifications/poll"))
+	// Only the request after the poll should reach the shadow deployment
+	r.ServeHTTP(httptest.NewRecorder(), credentialedRequest("/api/v1/items"))
+	awaitShadow(t, received)
+	select {
+	case <-received:
+		t.Error("the long-poll was mirrored")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -1,18 +1,73 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"time"
 )
 
+// Incident statuses an alert moves through when it tracks an incident
+const (
+	IncidentInvestigating = "investigating"
+	IncidentIdentified    = "identified"
+	IncidentResolved      = "resolved"
+)
+
+// IsIncidentStatus reports whether status is a known incident status
+func IsIncidentStatus(status string) bool {
+	switch status {
+	case IncidentInvestigating, IncidentIdentified, IncidentResolved:
+		return true
+	}
+	return false
+}
+
+// IncidentUpdate is one entry of an incident's timeline
+type IncidentUpdate struct {
+	Status    string    `json:"status" example:"identified"`
+	Message   string    `json:"message" example:"Sync is failing because the upstream repository moved"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IncidentUpdates is an incident timeline, oldest first, stored as a JSON array
+type IncidentUpdates []IncidentUpdate
+
+func (u IncidentUpdates) Value() (driver.Value, error) {
+	if u == nil {
+		return "[]", nil
+	}
+	return json.Marshal(u)
+}
+
+func (u *IncidentUpdates) Scan(value interface{}) error {
+	if value == nil {
+		*u = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		str, ok := value.(string)
+		if !ok {
+			return errors.New("type assertion to []byte failed")
+		}
+		bytes = []byte(str)
+	}
+	return json.Unmarshal(bytes, u)
+}
+
 type Alert struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	Name        string    `gorm:"not null" json:"name"`
-	Description string    `gorm:"type:text" json:"description"`
-	Severity    string    `gorm:"not null" json:"severity"`         // e.g., "info", "warning", "error", "critical"
-	IsActive    bool      `gorm:"default:true" json:"is_active"`    // Whether the alert is currently active
-	Data        JSONB     `gorm:"type:jsonb" json:"data,omitempty"` // Full data including multilingual content
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID              uint            `gorm:"primaryKey" json:"id"`
+	Name            string          `gorm:"not null" json:"name"`
+	Description     string          `gorm:"type:text" json:"description"`
+	Severity        string          `gorm:"not null" json:"severity"`                       // e.g., "info", "warning", "error", "critical"
+	IsActive        bool            `gorm:"default:true" json:"is_active"`                  // Whether the alert is currently active
+	Data            JSONB           `gorm:"type:jsonb" json:"data,omitempty"`               // Full data including multilingual content
+	IncidentStatus  string          `gorm:"size:20;index" json:"incident_status,omitempty"` // investigating, identified or resolved; empty for plain banners
+	IncidentUpdates IncidentUpdates `gorm:"type:jsonb" json:"incident_updates,omitempty"`
+	ResolvedAt      *time.Time      `json:"resolved_at,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
 }
 
 func (Alert) TableName() string {
@@ -4,12 +4,32 @@ import (
 	"time"
 )
 
+// API key scopes grant capabilities beyond the owner's role
+const (
+	// ScopeBulkRead allows page sizes above the default cap, for approved mirroring services
+	ScopeBulkRead = "bulk:read"
+)
+
+// AllAPIKeyScopes lists every scope an API key can be granted
+var AllAPIKeyScopes = []string{ScopeBulkRead}
+
+// IsValidAPIKeyScope reports whether scope is one of AllAPIKeyScopes
+func IsValidAPIKeyScope(scope string) bool {
+	for _, s := range AllAPIKeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
 type APIKey struct {
 	ID         uint       `gorm:"primaryKey" json:"id"`
 	UserID     uint       `gorm:"not null;index" json:"user_id"`
 	User       User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
 	KeyHash    string     `gorm:"not null;uniqueIndex" json:"-"`
 	Name       string     `gorm:"not null" json:"name"`
+	Scopes     StringList `gorm:"type:jsonb" json:"scopes,omitempty"`
 	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
 	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
 	CreatedAt  time.Time  `json:"created_at"`
@@ -22,3 +42,12 @@ func (APIKey) TableName() string {
 func (k *APIKey) IsRevoked() bool {
 	return k.RevokedAt != nil
 }
+
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
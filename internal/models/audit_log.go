@@ -18,6 +18,10 @@ type AuditLog struct {
 	RequestBody    *JSONB    `gorm:"type:jsonb" json:"request_body,omitempty"`
 	ResponseTimeMs int64     `gorm:"not null" json:"response_time_ms"`
 	IPAddress      string    `gorm:"index" json:"ip_address"`
+	RequestID      string    `gorm:"size:128;index" json:"request_id,omitempty"`               // X-Request-ID of the request, also sent back to the client
+	EntityType     string    `gorm:"index:idx_audit_logs_entity" json:"entity_type,omitempty"` // quest, item or user, for write requests
+	EntityID       string    `gorm:"index:idx_audit_logs_entity" json:"entity_id,omitempty"`
+	Changes        *JSONB    `gorm:"type:jsonb" json:"changes,omitempty"` // {field: {from, to}}, nested fields as dotted paths
 	CreatedAt      time.Time `json:"created_at"`
 }
 
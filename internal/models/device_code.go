@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// DeviceCode tracks a pending OAuth 2.0 device authorization grant (RFC 8628).
+// The device polls with the secret device code while the user approves the short
+// user code from a signed-in browser. Device codes are hashed at rest.
+type DeviceCode struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	DeviceCodeHash string     `gorm:"not null;uniqueIndex" json:"-"`
+	UserCode       string     `gorm:"not null;uniqueIndex" json:"user_code"`
+	ClientName     string     `json:"client_name"`
+	UserID         *uint      `gorm:"index" json:"user_id,omitempty"`
+	ApprovedAt     *time.Time `json:"approved_at,omitempty"`
+	DeniedAt       *time.Time `json:"denied_at,omitempty"`
+	ConsumedAt     *time.Time `json:"consumed_at,omitempty"`
+	LastPolledAt   *time.Time `json:"last_polled_at,omitempty"`
+	ExpiresAt      time.Time  `gorm:"not null;index" json:"expires_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func (DeviceCode) TableName() string { return "device_codes" }
+
+func (d *DeviceCode) IsExpired() bool  { return time.Now().After(d.ExpiresAt) }
+func (d *DeviceCode) IsApproved() bool { return d.ApprovedAt != nil }
+func (d *DeviceCode) IsDenied() bool   { return d.DeniedAt != nil }
+func (d *DeviceCode) IsConsumed() bool { return d.ConsumedAt != nil }
@@ -2,21 +2,24 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type EnemyType struct {
-	ID            uint      `gorm:"primaryKey" json:"id"`
-	ExternalID    string    `gorm:"uniqueIndex;not null" json:"external_id"`
-	Name          string    `gorm:"not null" json:"name"`
-	Description   string    `gorm:"type:text" json:"description,omitempty"`
-	Type          string    `json:"type,omitempty"` // e.g., "Human", "Robot", "Alien"
-	ImageURL      string    `json:"image_url,omitempty"`
-	ImageFilename string    `json:"image_filename,omitempty"`
-	Weakpoints    JSONB     `gorm:"type:jsonb" json:"weakpoints,omitempty"` // Array of weakpoint objects
-	Data          JSONB     `gorm:"type:jsonb" json:"data,omitempty"`       // Full data including multilingual content
-	SyncedAt      time.Time `json:"synced_at"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	ExternalID    string         `gorm:"uniqueIndex;not null" json:"external_id"`
+	Name          string         `gorm:"not null" json:"name"`
+	Description   string         `gorm:"type:text" json:"description,omitempty"`
+	Type          string         `json:"type,omitempty"` // e.g., "Human", "Robot", "Alien"
+	ImageURL      string         `json:"image_url,omitempty"`
+	ImageFilename string         `json:"image_filename,omitempty"`
+	Weakpoints    JSONB          `gorm:"type:jsonb" json:"weakpoints,omitempty"` // Array of weakpoint objects
+	Data          JSONB          `gorm:"type:jsonb" json:"data,omitempty"`       // Full data including multilingual content
+	SyncedAt      time.Time      `json:"synced_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 func (EnemyType) TableName() string {
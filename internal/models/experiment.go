@@ -0,0 +1,59 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ExperimentVariant is one arm of an experiment; users are split between variants in
+// proportion to their weights
+type ExperimentVariant struct {
+	Name   string `json:"name"`
+	Weight int    `json:"weight"`
+}
+
+// ExperimentVariants is a list of variants stored as a JSON array
+type ExperimentVariants []ExperimentVariant
+
+func (v ExperimentVariants) Value() (driver.Value, error) {
+	if v == nil {
+		return "[]", nil
+	}
+	return json.Marshal(v)
+}
+
+func (v *ExperimentVariants) Scan(value interface{}) error {
+	if value == nil {
+		*v = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		str, ok := value.(string)
+		if !ok {
+			return errors.New("type assertion to []byte failed")
+		}
+		bytes = []byte(str)
+	}
+	return json.Unmarshal(bytes, v)
+}
+
+// Experiment rolls a feature out to a share of authenticated users. Assignment is derived
+// from a hash of the experiment key and user ID, so a user keeps their variant across
+// requests and instances without storing assignments.
+type Experiment struct {
+	ID             uint               `gorm:"primaryKey" json:"id"`
+	Key            string             `gorm:"uniqueIndex;type:varchar(64);not null" json:"key"` // e.g. remaining_items_calculator
+	Description    string             `json:"description"`
+	Enabled        bool               `gorm:"default:false;not null" json:"enabled"`
+	RolloutPercent int                `gorm:"default:0;not null" json:"rollout_percent"` // Share of users enrolled, 0-100
+	Variants       ExperimentVariants `gorm:"type:jsonb" json:"variants"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+func (Experiment) TableName() string {
+	return "experiments"
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// Entity types a user can favorite
+const (
+	FavoriteEntityQuest     = "quest"
+	FavoriteEntityItem      = "item"
+	FavoriteEntityMap       = "map"
+	FavoriteEntityEnemyType = "enemy_type"
+)
+
+// Favorite pins an entry a user consults often. The entity is referenced by type and
+// external ID so one table covers every favoritable entity.
+type Favorite struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"uniqueIndex:idx_user_favorite;not null" json:"user_id"`
+	EntityType string    `gorm:"type:varchar(32);uniqueIndex:idx_user_favorite;not null" json:"entity_type"`
+	EntityID   string    `gorm:"uniqueIndex:idx_user_favorite;not null" json:"entity_id"` // External ID of the entity
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (Favorite) TableName() string {
+	return "favorites"
+}
@@ -0,0 +1,123 @@
+package models
+
+import "time"
+
+// Recurrences a game event repeats on
+const (
+	RecurrenceDaily   = "daily"
+	RecurrenceWeekly  = "weekly"
+	RecurrenceMonthly = "monthly"
+)
+
+// maxGameEventOccurrences bounds how many occurrences one event expands to
+const maxGameEventOccurrences = 500
+
+// IsRecurrence reports whether recurrence is a known recurrence, or empty for one-off events
+func IsRecurrence(recurrence string) bool {
+	switch recurrence {
+	case "", RecurrenceDaily, RecurrenceWeekly, RecurrenceMonthly:
+		return true
+	}
+	return false
+}
+
+// GameEvent is an in-game event on the calendar. Recurring events repeat with the same
+// duration from StartsAt until RecurrenceUntil, or forever when it is nil.
+type GameEvent struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	Name            string     `gorm:"not null" json:"name" example:"Cold Snap"`
+	Description     string     `gorm:"type:text" json:"description"`
+	StartsAt        time.Time  `gorm:"not null;index" json:"starts_at"`
+	EndsAt          time.Time  `gorm:"not null" json:"ends_at"`
+	Recurrence      string     `gorm:"size:20" json:"recurrence,omitempty" example:"weekly"` // daily, weekly or monthly; empty for one-off events
+	RecurrenceUntil *time.Time `json:"recurrence_until,omitempty"`                           // Last time an occurrence may start
+	MapID           string     `gorm:"size:100;index" json:"map_id,omitempty" example:"dam"` // External ID of the map; empty for every map
+	Mode            string     `gorm:"size:50;index" json:"mode,omitempty" example:"night_raid"`
+	Data            JSONB      `gorm:"type:jsonb" json:"data,omitempty"` // Extra metadata such as multilingual names or modifiers
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func (GameEvent) TableName() string {
+	return "game_events"
+}
+
+// GameEventOccurrence is one run of a game event
+type GameEventOccurrence struct {
+	EventID     uint      `json:"event_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	Recurrence  string    `json:"recurrence,omitempty"`
+	MapID       string    `json:"map_id,omitempty"`
+	Mode        string    `json:"mode,omitempty"`
+	Data        JSONB     `json:"data,omitempty"`
+}
+
+// Occurrences returns the runs of the event that overlap [from, to), soonest first
+func (e *GameEvent) Occurrences(from, to time.Time) []GameEventOccurrence {
+	duration := e.EndsAt.Sub(e.StartsAt)
+	var occurrences []GameEventOccurrence
+	n := e.firstCandidate(from.Add(-duration))
+	for len(occurrences) < maxGameEventOccurrences {
+		start := e.nth(n)
+		if !start.Before(to) || (e.RecurrenceUntil != nil && start.After(*e.RecurrenceUntil)) {
+			break
+		}
+		if end := start.Add(duration); end.After(from) {
+			occurrences = append(occurrences, GameEventOccurrence{
+				EventID:     e.ID,
+				Name:        e.Name,
+				Description: e.Description,
+				StartsAt:    start,
+				EndsAt:      end,
+				Recurrence:  e.Recurrence,
+				MapID:       e.MapID,
+				Mode:        e.Mode,
+				Data:        e.Data,
+			})
+		}
+		if e.Recurrence == "" {
+			break
+		}
+		n++
+	}
+	return occurrences
+}
+
+// nth returns the start of the nth occurrence. Occurrences are counted from StartsAt so
+// monthly events keep their day of the month.
+func (e *GameEvent) nth(n int) time.Time {
+	switch e.Recurrence {
+	case RecurrenceDaily:
+		return e.StartsAt.AddDate(0, 0, n)
+	case RecurrenceWeekly:
+		return e.StartsAt.AddDate(0, 0, 7*n)
+	case RecurrenceMonthly:
+		return e.StartsAt.AddDate(0, n, 0)
+	}
+	return e.StartsAt
+}
+
+// firstCandidate is the index of the last occurrence starting at or before t, so
+// expanding a long-running recurrence doesn't walk every occurrence since StartsAt
+func (e *GameEvent) firstCandidate(t time.Time) int {
+	if !t.After(e.StartsAt) {
+		return 0
+	}
+	var n int
+	switch e.Recurrence {
+	case RecurrenceDaily:
+		n = int(t.Sub(e.StartsAt) / (24 * time.Hour))
+	case RecurrenceWeekly:
+		n = int(t.Sub(e.StartsAt) / (7 * 24 * time.Hour))
+	case RecurrenceMonthly:
+		n = (t.Year()-e.StartsAt.Year())*12 + int(t.Month()-e.StartsAt.Month())
+	}
+	// Daylight saving shifts and short months can overshoot by one
+	for n > 0 && e.nth(n).After(t) {
+		n--
+	}
+	return n
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGameEventOccurrences(t *testing.T) {
+	start := time.Date(2026, 1, 5, 18, 0, 0, 0, time.UTC) // a Monday
+	until := time.Date(2026, 2, 2, 18, 0, 0, 0, time.UTC)
+	weekly := GameEvent{ID: 1, Name: "Night raid", StartsAt: start, EndsAt: start.Add(2 * time.Hour), Recurrence: RecurrenceWeekly, RecurrenceUntil: &until}
+
+	// A window starting mid-occurrence includes the running one
+	from := time.Date(2026, 1, 12, 19, 0, 0, 0, time.UTC)
+	got := weekly.Occurrences(from, from.AddDate(0, 0, 14))
+	if len(got) != 3 {
+		t.Fatalf("weekly occurrences = %d, want 3: %+v", len(got), got)
+	}
+	for i, want := range []time.Time{start.AddDate(0, 0, 7), start.AddDate(0, 0, 14), start.AddDate(0, 0, 21)} {
+		if !got[i].StartsAt.Equal(want) || got[i].EndsAt.Sub(got[i].StartsAt) != 2*time.Hour || got[i].EventID != 1 {
+			t.Errorf("occurrence %d = %+v, want start %s", i, got[i], want)
+		}
+	}
+
+	// Nothing after recurrence_until
+	if got := weekly.Occurrences(until.Add(time.Hour*3), until.AddDate(0, 1, 0)); len(got) != 0 {
+		t.Errorf("occurrences after until = %+v", got)
+	}
+
+	oneOff := GameEvent{ID: 2, StartsAt: start, EndsAt: start.Add(time.Hour)}
+	if got := oneOff.Occurrences(start.Add(-time.Hour), start.Add(time.Minute)); len(got) != 1 {
+		t.Errorf("one-off occurrences = %+v", got)
+	}
+	if got := oneOff.Occurrences(start.Add(time.Hour), start.AddDate(0, 0, 1)); len(got) != 0 {
+		t.Errorf("ended one-off occurrences = %+v", got)
+	}
+
+	// Monthly events keep their day of the month
+	monthly := GameEvent{StartsAt: start, EndsAt: start.Add(time.Hour), Recurrence: RecurrenceMonthly}
+	got = monthly.Occurrences(time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2027, 5, 1, 0, 0, 0, 0, time.UTC))
+	if len(got) != 2 || !got[0].StartsAt.Equal(time.Date(2027, 3, 5, 18, 0, 0, 0, time.UTC)) || !got[1].StartsAt.Equal(time.Date(2027, 4, 5, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("monthly occurrences = %+v", got)
+	}
+}
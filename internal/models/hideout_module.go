@@ -2,19 +2,23 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type HideoutModule struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	ExternalID  string    `gorm:"uniqueIndex;not null" json:"external_id"`
-	Name        string    `gorm:"not null" json:"name"`
-	Description string    `gorm:"type:text" json:"description"`
-	MaxLevel    int       `json:"max_level,omitempty"`
-	Levels      JSONB     `gorm:"type:jsonb" json:"levels,omitempty"` // Array of level objects
-	Data        JSONB     `gorm:"type:jsonb" json:"data,omitempty"`
-	SyncedAt    time.Time `json:"synced_at"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	ExternalID     string         `gorm:"uniqueIndex;not null" json:"external_id"`
+	Name           string         `gorm:"not null" json:"name"`
+	Description    string         `gorm:"type:text" json:"description"`
+	MaxLevel       int            `json:"max_level,omitempty"`
+	Levels         JSONB          `gorm:"type:jsonb" json:"levels,omitempty"` // Array of level objects
+	Data           JSONB          `gorm:"type:jsonb" json:"data,omitempty"`
+	ManualOverride bool           `gorm:"not null;default:false" json:"manual_override"` // Edited through the API; sync leaves it alone
+	SyncedAt       time.Time      `json:"synced_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 func (HideoutModule) TableName() string {
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+)
+
+// Image check outcomes
+const (
+	ImageStatusOK       = "ok"
+	ImageStatusBroken   = "broken"
+	ImageStatusRepaired = "repaired" // The original URL is broken but an alternate encoding works
+)
+
+// Entity types whose images are checked
+const (
+	ImageEntityItem      = "item"
+	ImageEntityEnemyType = "enemy_type"
+)
+
+// ImageCheck records the last verification of an entity's image URL
+type ImageCheck struct {
+	ID          uint      `gorm:"primaryKey" json:"-"`
+	EntityType  string    `gorm:"uniqueIndex:idx_image_check;not null" json:"entity_type"` // item or enemy_type
+	EntityID    string    `gorm:"uniqueIndex:idx_image_check;not null" json:"entity_id"`   // Entity external ID
+	URL         string    `gorm:"not null" json:"url"`                                     // URL as provided upstream
+	RepairedURL string    `json:"repaired_url,omitempty"`                                  // Working alternate the entity now uses
+	Status      string    `gorm:"index;not null" json:"status"`
+	HTTPStatus  int       `json:"http_status,omitempty"` // Response status of the original URL; 0 if the request failed
+	Error       string    `json:"error,omitempty"`
+	CheckedAt   time.Time `json:"checked_at"`
+}
+
+func (ImageCheck) TableName() string {
+	return "image_checks"
+}
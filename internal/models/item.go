@@ -2,20 +2,25 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type Item struct {
-	ID            uint      `gorm:"primaryKey" json:"id"`
-	ExternalID    string    `gorm:"uniqueIndex;not null" json:"external_id"`
-	Name          string    `gorm:"not null" json:"name"`
-	Description   string    `gorm:"type:text" json:"description"`
-	Type          string    `json:"type,omitempty"` // e.g., "Material"
-	ImageURL      string    `json:"image_url,omitempty"`
-	ImageFilename string    `json:"image_filename,omitempty"` // Original filename from JSON
-	Data          JSONB     `gorm:"type:jsonb" json:"data,omitempty"`
-	SyncedAt      time.Time `json:"synced_at"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	ExternalID     string         `gorm:"uniqueIndex;not null" json:"external_id"`
+	Name           string         `gorm:"not null" json:"name"`
+	Description    string         `gorm:"type:text" json:"description"`
+	Type           string         `json:"type,omitempty"` // e.g., "Material"
+	ImageURL       string         `json:"image_url,omitempty"`
+	ImageFilename  string         `json:"image_filename,omitempty"` // Original filename from JSON
+	Data           JSONB          `gorm:"type:jsonb" json:"data,omitempty"`
+	ManualOverride bool           `gorm:"not null;default:false" json:"manual_override"` // Edited through the API; sync leaves it alone
+	SyncedAt       time.Time      `json:"synced_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	Tags           []string       `gorm:"-" json:"tags,omitempty"` // Slugs of the admin-managed tags applied to it
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 func (Item) TableName() string {
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// ItemAlias is an admin-managed synonym for an item name (e.g. "Alloy (ARC)" for "ARC Alloy"),
+// used when matching free-text quest objectives to items
+type ItemAlias struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	ItemExternalID string    `gorm:"index;not null" json:"item_external_id"`
+	Alias          string    `gorm:"not null" json:"alias"`
+	NormalizedName string    `gorm:"uniqueIndex;not null" json:"normalized_name"` // Case-folded, accent-stripped alias
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (ItemAlias) TableName() string {
+	return "item_aliases"
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// ItemStat is one numeric stat (e.g. damage, fire_rate) parsed out of an item's stat blocks
+// at sync time so items can be compared without digging through Data
+type ItemStat struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	ItemExternalID string    `gorm:"uniqueIndex:idx_item_stat;not null" json:"item_external_id"`
+	Stat           string    `gorm:"uniqueIndex:idx_item_stat;not null" json:"stat"` // snake_case stat key
+	Value          float64   `gorm:"not null" json:"value"`
+	Unit           string    `json:"unit,omitempty"` // e.g. "%" when the upstream value carried one
+	SyncedAt       time.Time `json:"synced_at"`
+}
+
+func (ItemStat) TableName() string {
+	return "item_stats"
+}
@@ -4,14 +4,23 @@ import (
 	"time"
 )
 
+// SessionScopeDevice marks tokens issued through the device authorization grant, which
+// can read data and manage the user's own data but not perform privileged writes
+const SessionScopeDevice = "device"
+
 type JWTToken struct {
-	ID        uint       `gorm:"primaryKey" json:"id"`
-	UserID    uint       `gorm:"not null;index" json:"user_id"`
-	User      User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	TokenHash string     `gorm:"not null;index" json:"-"`
-	ExpiresAt time.Time  `gorm:"not null;index" json:"expires_at"`
-	RevokedAt *time.Time `json:"revoked_at,omitempty"`
-	CreatedAt time.Time  `json:"created_at"`
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	User       User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	JTI        string     `gorm:"index" json:"jti,omitempty"` // JWT ID claim (or Supabase session_id) identifying this token
+	TokenHash  string     `gorm:"not null;index" json:"-"`
+	UserAgent  string     `json:"user_agent,omitempty"`
+	IPAddress  string     `json:"ip_address,omitempty"`
+	Scope      string     `gorm:"type:varchar(32);not null;default:''" json:"scope,omitempty"` // Empty for full access, or SessionScopeDevice
+	ExpiresAt  time.Time  `gorm:"not null;index" json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
 }
 
 func (JWTToken) TableName() string {
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+)
+
+// MapMarkerType classifies a point of interest on a map
+type MapMarkerType string
+
+const (
+	MapMarkerExtraction    MapMarkerType = "extraction"
+	MapMarkerLootZone      MapMarkerType = "loot_zone"
+	MapMarkerQuestLocation MapMarkerType = "quest_location"
+	MapMarkerOther         MapMarkerType = "other"
+)
+
+// IsValid reports whether t is one of the known marker types
+func (t MapMarkerType) IsValid() bool {
+	switch t {
+	case MapMarkerExtraction, MapMarkerLootZone, MapMarkerQuestLocation, MapMarkerOther:
+		return true
+	}
+	return false
+}
+
+// MapMarker is an admin-curated point of interest on a map with in-game coordinates
+type MapMarker struct {
+	ID              uint          `gorm:"primaryKey" json:"id"`
+	MapID           uint          `gorm:"index;not null" json:"map_id"`
+	Type            MapMarkerType `gorm:"type:varchar(32);index;not null" json:"type"`
+	Name            string        `gorm:"not null" json:"name"`
+	Description     string        `json:"description,omitempty"`
+	X               float64       `gorm:"not null" json:"x"`
+	Y               float64       `gorm:"not null" json:"y"`
+	Z               *float64      `json:"z,omitempty"`
+	QuestExternalID string        `gorm:"index" json:"quest_external_id,omitempty"` // Set for quest_location markers
+	Data            JSONB         `gorm:"type:jsonb" json:"data,omitempty"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+}
+
+func (MapMarker) TableName() string {
+	return "map_markers"
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// Notification types
+const (
+	// NotificationEntityChanged is sent when sync changes an entity the user favorited or tracks
+	NotificationEntityChanged = "entity_changed"
+	// NotificationSecurityReport is sent to admins when a vulnerability is reported
+	NotificationSecurityReport = "security_report"
+	// NotificationTraderStock is sent when a trader's new stock rotation has an item the user favorited
+	NotificationTraderStock = "trader_stock"
+)
+
+// Notification is an entry in a user's notification feed
+type Notification struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"index:idx_notification_user;not null" json:"user_id"`
+	Type       string     `gorm:"type:varchar(32);not null" json:"type"`
+	EntityType string     `gorm:"type:varchar(32)" json:"entity_type,omitempty"`
+	EntityID   string     `json:"entity_id,omitempty"`
+	Title      string     `gorm:"not null" json:"title"`
+	Body       string     `gorm:"type:text" json:"body,omitempty"`
+	Data       JSONB      `gorm:"type:jsonb" json:"data,omitempty"`
+	ReadAt     *time.Time `gorm:"index:idx_notification_user" json:"read_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Reasons a progress snapshot was taken
+const (
+	ProgressSnapshotScheduled  = "scheduled"   // The daily progress_snapshot job
+	ProgressSnapshotPreRestore = "pre_restore" // Just before another snapshot was restored over it
+)
+
+// ProgressSnapshotData is a copy of a user's progress rows, stored as a JSON object
+type ProgressSnapshotData struct {
+	Quests         []UserQuestProgress         `json:"quests"`
+	HideoutModules []UserHideoutModuleProgress `json:"hideout_modules"`
+	SkillNodes     []UserSkillNodeProgress     `json:"skill_nodes"`
+	Blueprints     []UserBlueprintProgress     `json:"blueprints"`
+}
+
+func (d ProgressSnapshotData) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
+
+func (d *ProgressSnapshotData) Scan(value interface{}) error {
+	if value == nil {
+		*d = ProgressSnapshotData{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		str, ok := value.(string)
+		if !ok {
+			return errors.New("type assertion to []byte failed")
+		}
+		bytes = []byte(str)
+	}
+	return json.Unmarshal(bytes, d)
+}
+
+// ProgressSnapshotCounts is how many progress rows of each type a snapshot holds
+type ProgressSnapshotCounts struct {
+	Quests         int `json:"quests"`
+	HideoutModules int `json:"hideout_modules"`
+	SkillNodes     int `json:"skill_nodes"`
+	Blueprints     int `json:"blueprints"`
+}
+
+// Counts returns how many rows of each progress type d holds
+func (d *ProgressSnapshotData) Counts() ProgressSnapshotCounts {
+	return ProgressSnapshotCounts{
+		Quests:         len(d.Quests),
+		HideoutModules: len(d.HideoutModules),
+		SkillNodes:     len(d.SkillNodes),
+		Blueprints:     len(d.Blueprints),
+	}
+}
+
+// ProgressSnapshot is a point-in-time copy of a user's quest, hideout module, skill node and
+// blueprint progress, kept so progress lost to an accidental reset or a bad client sync can
+// be restored. Progress is only loaded when a snapshot is fetched on its own.
+type ProgressSnapshot struct {
+	ID       uint                   `gorm:"primaryKey" json:"id"`
+	UserID   uint                   `gorm:"not null;index" json:"user_id"`
+	TakenAt  time.Time              `gorm:"not null;index" json:"taken_at"`
+	Reason   string                 `gorm:"size:20;not null" json:"reason" example:"scheduled"` // scheduled or pre_restore
+	Counts   ProgressSnapshotCounts `gorm:"embedded;embeddedPrefix:count_" json:"counts"`
+	Progress *ProgressSnapshotData  `gorm:"type:jsonb" json:"progress,omitempty"`
+}
+
+func (ProgressSnapshot) TableName() string {
+	return "progress_snapshots"
+}
@@ -4,7 +4,11 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type JSONB map[string]interface{}
@@ -28,19 +32,46 @@ func (j *JSONB) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, j)
 }
 
+// MarshalGQL writes the value as a GraphQL JSON scalar
+func (j JSONB) MarshalGQL(w io.Writer) {
+	if j == nil {
+		io.WriteString(w, "null")
+		return
+	}
+	data, err := json.Marshal(j)
+	if err != nil {
+		io.WriteString(w, "null")
+		return
+	}
+	w.Write(data)
+}
+
+// UnmarshalGQL reads a GraphQL JSON scalar, which must be an object
+func (j *JSONB) UnmarshalGQL(v interface{}) error {
+	m, ok := v.(map[string]interface{})
+	if !ok && v != nil {
+		return fmt.Errorf("JSON must be an object")
+	}
+	*j = m
+	return nil
+}
+
 type Quest struct {
-	ID            uint      `gorm:"primaryKey" json:"id"`
-	ExternalID    string    `gorm:"uniqueIndex;not null" json:"external_id"`
-	Name          string    `gorm:"not null" json:"name"`
-	Description   string    `gorm:"type:text" json:"description"`
-	Trader        string    `json:"trader,omitempty"`
-	Objectives    JSONB     `gorm:"type:jsonb" json:"objectives,omitempty"`      // Array of strings
-	RewardItemIds JSONB     `gorm:"type:jsonb" json:"reward_item_ids,omitempty"` // Array of {itemId, quantity}
-	XP            int       `json:"xp,omitempty"`
-	Data          JSONB     `gorm:"type:jsonb" json:"data,omitempty"`
-	SyncedAt      time.Time `json:"synced_at"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	ExternalID     string         `gorm:"uniqueIndex;not null" json:"external_id"`
+	Name           string         `gorm:"not null" json:"name"`
+	Description    string         `gorm:"type:text" json:"description"`
+	Trader         string         `json:"trader,omitempty"`
+	Objectives     JSONB          `gorm:"type:jsonb" json:"objectives,omitempty"`      // Array of strings
+	RewardItemIds  JSONB          `gorm:"type:jsonb" json:"reward_item_ids,omitempty"` // Array of {itemId, quantity}
+	XP             int            `json:"xp,omitempty"`
+	Data           JSONB          `gorm:"type:jsonb" json:"data,omitempty"`
+	ManualOverride bool           `gorm:"not null;default:false" json:"manual_override"` // Edited through the API; sync leaves it alone
+	SyncedAt       time.Time      `json:"synced_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	Tags           []string       `gorm:"-" json:"tags,omitempty"` // Slugs of the admin-managed tags applied to it
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 func (Quest) TableName() string {
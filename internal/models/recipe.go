@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// Recipe describes how an item is crafted, extracted from the upstream item data during sync
+type Recipe struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	ItemExternalID string    `gorm:"uniqueIndex;not null" json:"item_external_id"` // Crafted item
+	Bench          string    `json:"bench,omitempty"`                              // Workbench required to craft
+	OutputQuantity int       `gorm:"not null;default:1" json:"output_quantity"`    // Items produced per craft
+	Ingredients    JSONB     `gorm:"type:jsonb" json:"ingredients"`                // Ingredient external ID -> quantity per craft
+	SyncedAt       time.Time `json:"synced_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (Recipe) TableName() string {
+	return "recipes"
+}
@@ -3,12 +3,17 @@ package models
 import "time"
 
 // RefreshToken represents a long-lived token allowing JWT renewal
-// Tokens are hashed at rest and can be revoked.
+// Tokens are hashed at rest and can be revoked. Every rotation stays in the
+// same family so a replayed token can revoke the whole chain.
 type RefreshToken struct {
 	ID         uint       `gorm:"primaryKey" json:"id"`
 	UserID     uint       `gorm:"not null;index" json:"user_id"`
 	User       User       `gorm:"foreignKey:UserID" json:"-"`
 	TokenHash  string     `gorm:"not null;uniqueIndex" json:"-"`
+	FamilyID   string     `gorm:"index" json:"family_id"`
+	UserAgent  string     `json:"user_agent,omitempty"`
+	IPAddress  string     `json:"ip_address,omitempty"`
+	Scope      string     `gorm:"type:varchar(32);not null;default:''" json:"scope,omitempty"` // Carried over to every rotation and access token of the family
 	ExpiresAt  time.Time  `gorm:"not null;index" json:"expires_at"`
 	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
 	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Permission strings checked by the RBAC middleware
+const (
+	PermManageUsers  = "manage_users"  // Users, roles, API keys, sessions and per-user progress
+	PermManageData   = "manage_data"   // Game data writes, sync, exports and item aliases
+	PermReadLogs     = "read_logs"     // Audit log queries
+	PermManageAlerts = "manage_alerts" // Create, update and delete alerts and game events
+	PermManageRoles  = "manage_roles"  // Create roles and change their permissions
+)
+
+// AllPermissions lists every known permission
+var AllPermissions = []string{PermManageUsers, PermManageData, PermReadLogs, PermManageAlerts, PermManageRoles}
+
+// RoleModerator is a built-in role that can manage alerts without full admin rights
+const RoleModerator UserRole = "moderator"
+
+// StringList is a list of strings stored as a JSON array
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	return json.Marshal(l)
+}
+
+func (l *StringList) Scan(value interface{}) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		str, ok := value.(string)
+		if !ok {
+			return errors.New("type assertion to []byte failed")
+		}
+		bytes = []byte(str)
+	}
+	return json.Unmarshal(bytes, l)
+}
+
+// Role maps a role name (stored on User.Role) to a set of permissions
+type Role struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Name        string     `gorm:"uniqueIndex;type:varchar(20);not null" json:"name"`
+	Description string     `json:"description"`
+	Permissions StringList `gorm:"type:jsonb" json:"permissions"`
+	BuiltIn     bool       `gorm:"default:false;not null" json:"built_in"` // Built-in roles cannot be deleted
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (Role) TableName() string {
+	return "roles"
+}
+
+// HasPermission reports whether the role grants the given permission
+func (r *Role) HasPermission(permission string) bool {
+	for _, p := range r.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidPermission reports whether permission is one of AllPermissions
+func IsValidPermission(permission string) bool {
+	for _, p := range AllPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultRoles are created on startup if missing
+func DefaultRoles() []Role {
+	return []Role{
+		{Name: string(RoleAdmin), Description: "Full access", Permissions: StringList(AllPermissions), BuiltIn: true},
+		{Name: string(RoleModerator), Description: "Manage alerts and game events", Permissions: StringList{PermManageAlerts}, BuiltIn: true},
+		{Name: string(RoleUser), Description: "Read-only access to game data and own progress", Permissions: StringList{}, BuiltIn: true},
+	}
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// SavedSearch is a named filter preset a user built for a list endpoint, kept on the server
+// so it follows them across devices
+type SavedSearch struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"uniqueIndex:idx_user_saved_search;not null" json:"user_id"`
+	Name       string    `gorm:"type:varchar(100);uniqueIndex:idx_user_saved_search;not null" json:"name"`
+	EntityType string    `gorm:"type:varchar(32);not null" json:"entity_type"`
+	Query      string    `gorm:"type:text;not null" json:"query" example:"type=eq.weapon&name=ilike.*alloy*"` // Query string for the entity type's list endpoint
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (SavedSearch) TableName() string {
+	return "saved_searches"
+}
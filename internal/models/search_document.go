@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// SearchDocument is the searchable text of one entity in one language, built at sync time
+// from its translations: the name, then the description and objectives, one per line.
+// Fields missing in a language fall back to English, so every entity can be found in
+// every language.
+type SearchDocument struct {
+	ID         uint      `gorm:"primaryKey" json:"-"`
+	EntityType string    `gorm:"type:varchar(32);uniqueIndex:idx_search_document;not null" json:"entity_type" example:"item"`
+	EntityID   string    `gorm:"uniqueIndex:idx_search_document;not null" json:"entity_id"` // Entity external ID
+	Lang       string    `gorm:"type:varchar(8);uniqueIndex:idx_search_document;index;not null" json:"lang" example:"de"`
+	Name       string    `gorm:"type:text;not null" json:"name"`
+	Body       string    `gorm:"type:text;not null" json:"-"`
+	SyncedAt   time.Time `json:"synced_at"`
+}
+
+func (SearchDocument) TableName() string {
+	return "search_documents"
+}
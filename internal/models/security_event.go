@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+)
+
+// Security event types
+const (
+	// SecurityEventVulnerabilityReport is a vulnerability reported through POST /security/reports
+	SecurityEventVulnerabilityReport = "vulnerability_report"
+)
+
+// Security event statuses
+const (
+	SecurityEventStatusNew      = "new"
+	SecurityEventStatusTriaged  = "triaged"
+	SecurityEventStatusResolved = "resolved"
+)
+
+// IsSecurityEventStatus reports whether status is a known security event status
+func IsSecurityEventStatus(status string) bool {
+	switch status {
+	case SecurityEventStatusNew, SecurityEventStatusTriaged, SecurityEventStatusResolved:
+		return true
+	}
+	return false
+}
+
+// SecurityEvent is a security-relevant report or incident kept for admins to triage
+type SecurityEvent struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	Type          string    `gorm:"type:varchar(32);not null;index" json:"type"`
+	Status        string    `gorm:"type:varchar(16);default:'new';not null;index" json:"status"`
+	Severity      string    `gorm:"type:varchar(16)" json:"severity,omitempty"` // As claimed by the reporter: low, medium, high or critical
+	Title         string    `gorm:"not null" json:"title"`
+	Description   string    `gorm:"type:text" json:"description"`
+	ReporterName  string    `json:"reporter_name,omitempty"`
+	ReporterEmail string    `json:"reporter_email,omitempty"`
+	IPAddress     string    `json:"ip_address,omitempty"`
+	UserAgent     string    `json:"user_agent,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (SecurityEvent) TableName() string {
+	return "security_events"
+}
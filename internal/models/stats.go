@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// LeaderboardEntry is a materialized row of the progress leaderboards.
+// Rows are rebuilt by the stats refresh job for opted-in users only.
+type LeaderboardEntry struct {
+	UserID             uint      `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	Username           string    `gorm:"not null" json:"username"` // Leaderboard name, falling back to the username
+	QuestsCompleted    int64     `gorm:"not null;default:0" json:"quests_completed"`
+	HideoutLevels      int64     `gorm:"not null;default:0" json:"hideout_levels"`
+	SkillLevels        int64     `gorm:"not null;default:0" json:"skill_levels"`
+	BlueprintsConsumed int64     `gorm:"not null;default:0" json:"blueprints_consumed"`
+	Score              int64     `gorm:"not null;default:0;index" json:"score"`
+	Rank               int64     `gorm:"not null;index" json:"rank"` // Overall rank by score
+	QuestsRank         int64     `gorm:"not null;default:0;index" json:"quests_rank"`
+	HideoutRank        int64     `gorm:"not null;default:0;index" json:"hideout_rank"`
+	RefreshedAt        time.Time `json:"refreshed_at"`
+}
+
+func (LeaderboardEntry) TableName() string {
+	return "leaderboard_entries"
+}
+
+// QuestCompletionStat is a materialized per-quest completion count
+type QuestCompletionStat struct {
+	QuestID         uint      `gorm:"primaryKey;autoIncrement:false" json:"quest_id"`
+	QuestExternalID string    `gorm:"index;not null" json:"quest_external_id"`
+	Completions     int64     `gorm:"not null;default:0" json:"completions"`
+	RefreshedAt     time.Time `json:"refreshed_at"`
+}
+
+func (QuestCompletionStat) TableName() string {
+	return "quest_completion_stats"
+}
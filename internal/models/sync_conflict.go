@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// SyncConflict is a row admins edited through the API, which sync leaves alone, whose
+// upstream data has changed since. Local and Upstream hold the row as it is and as sync
+// would have written it, for comparing the two.
+type SyncConflict struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	EntityType  string     `gorm:"type:varchar(32);uniqueIndex:idx_sync_conflict_entity;not null" json:"entity_type" example:"quests"`
+	ExternalID  string     `gorm:"uniqueIndex:idx_sync_conflict_entity;not null" json:"external_id"`
+	EntityID    uint       `gorm:"not null" json:"entity_id"`
+	Local       JSONB      `gorm:"type:jsonb" json:"local"`
+	Upstream    JSONB      `gorm:"type:jsonb" json:"upstream"`
+	DismissedAt *time.Time `gorm:"index" json:"dismissed_at,omitempty"` // Set when admins keep the local edits, until upstream changes again
+	DetectedAt  time.Time  `gorm:"not null" json:"detected_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (SyncConflict) TableName() string {
+	return "sync_conflicts"
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// What started a sync run
+const (
+	SyncTriggerStartup   = "startup"
+	SyncTriggerScheduled = "scheduled"
+	SyncTriggerManual    = "manual"
+)
+
+// Statuses of a sync run
+const (
+	SyncRunRunning   = "running"
+	SyncRunSucceeded = "succeeded"
+	SyncRunFailed    = "failed"
+	SyncRunCancelled = "cancelled"
+)
+
+// SyncRunEntityCounts is how many rows of one entity type a sync run read from the archive,
+// how many of them failed to save and how many it kept because admins edited them
+type SyncRunEntityCounts struct {
+	Total  int `json:"total"`
+	Failed int `json:"failed"`
+	Kept   int `json:"kept,omitempty"`
+}
+
+// SyncRunEntities holds a run's counts by entity type, stored as a JSON object
+type SyncRunEntities map[string]SyncRunEntityCounts
+
+func (e SyncRunEntities) Value() (driver.Value, error) {
+	if e == nil {
+		return "{}", nil
+	}
+	return json.Marshal(e)
+}
+
+func (e *SyncRunEntities) Scan(value interface{}) error {
+	if value == nil {
+		*e = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		str, ok := value.(string)
+		if !ok {
+			return errors.New("type assertion to []byte failed")
+		}
+		bytes = []byte(str)
+	}
+	return json.Unmarshal(bytes, e)
+}
+
+// SyncRun records one sync of the game data: what started it, how it ended, how many
+// entities of each type it saved and the errors it ran into on the way
+type SyncRun struct {
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	Trigger     string          `gorm:"type:varchar(16);not null" json:"trigger" example:"scheduled"`
+	Entity      string          `gorm:"type:varchar(32)" json:"entity,omitempty" example:"quests"` // The only entity type synced by a partial sync
+	Status      string          `gorm:"type:varchar(16);not null;index" json:"status" example:"succeeded"`
+	DataVersion string          `json:"data_version,omitempty" example:"3f2a9c1"` // Source commit SHA
+	StartedAt   time.Time       `gorm:"not null;index" json:"started_at"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+	DurationMS  int64           `json:"duration_ms"`
+	Entities    SyncRunEntities `gorm:"type:jsonb" json:"entities"`
+	Errors      StringList      `gorm:"type:jsonb" json:"errors"`         // Non-fatal errors, such as rows that failed to save
+	Error       string          `gorm:"type:text" json:"error,omitempty"` // Why the run failed
+}
+
+func (SyncRun) TableName() string {
+	return "sync_runs"
+}
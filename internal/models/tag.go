@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+// Entity types a tag can be applied to
+const (
+	TagEntityItem  = "item"
+	TagEntityQuest = "quest"
+)
+
+// Tag is an admin-defined label, such as "keep-for-quests" or "hideout-critical", kept
+// apart from the synced dataset so it survives every sync
+type Tag struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Slug        string    `gorm:"type:varchar(50);uniqueIndex;not null" json:"slug"`
+	Name        string    `gorm:"not null" json:"name"`
+	Description string    `gorm:"type:text" json:"description,omitempty"`
+	Color       string    `gorm:"type:varchar(20)" json:"color,omitempty"` // e.g. #ff9900, for clients to render the tag
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// EntityTag applies a tag to an item or quest. The entity is referenced by type and
+// external ID, like favorites, so the tag stays attached when a sync recreates the row.
+type EntityTag struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	TagID      uint      `gorm:"uniqueIndex:idx_entity_tag;not null" json:"tag_id"`
+	EntityType string    `gorm:"type:varchar(32);uniqueIndex:idx_entity_tag;index:idx_entity_tag_entity;not null" json:"entity_type"`
+	EntityID   string    `gorm:"uniqueIndex:idx_entity_tag;index:idx_entity_tag_entity;not null" json:"entity_id"` // External ID of the entity
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (EntityTag) TableName() string {
+	return "entity_tags"
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+)
+
+// TeamMemberRole is a member's role within a team
+type TeamMemberRole string
+
+const (
+	TeamRoleOwner  TeamMemberRole = "owner"
+	TeamRoleMember TeamMemberRole = "member"
+)
+
+// Team is a squad of users sharing a read-only view of each other's progress
+type Team struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Name       string    `gorm:"not null" json:"name"`
+	OwnerID    uint      `gorm:"not null;index" json:"owner_id"`
+	InviteCode string    `gorm:"uniqueIndex;not null" json:"invite_code,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	// Relations
+	Members []TeamMember `gorm:"foreignKey:TeamID" json:"members,omitempty"`
+}
+
+func (Team) TableName() string {
+	return "teams"
+}
+
+// TeamMember links a user to a team
+type TeamMember struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	TeamID    uint           `gorm:"uniqueIndex:idx_team_member;not null" json:"team_id"`
+	UserID    uint           `gorm:"uniqueIndex:idx_team_member;index;not null" json:"user_id"`
+	Role      TeamMemberRole `gorm:"type:varchar(20);default:'member';not null" json:"role"`
+	CreatedAt time.Time      `json:"joined_at"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (TeamMember) TableName() string {
+	return "team_members"
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+)
+
+// Telemetry event types client apps can report
+const (
+	TelemetryEventScreenView = "screen_view"
+	TelemetryEventFeatureUse = "feature_use"
+)
+
+// Telemetry platforms client apps can report
+const (
+	TelemetryPlatformIOS     = "ios"
+	TelemetryPlatformAndroid = "android"
+	TelemetryPlatformWeb     = "web"
+	TelemetryPlatformDesktop = "desktop"
+)
+
+// TelemetryCount is the number of times an event was reported on a day. Events are only
+// stored as these daily counts, without the reporting user, so they can't be traced back
+// to anyone.
+type TelemetryCount struct {
+	ID         uint      `gorm:"primaryKey" json:"-"`
+	Day        time.Time `gorm:"type:date;uniqueIndex:idx_telemetry_count;not null" json:"day"`
+	EventType  string    `gorm:"type:varchar(32);uniqueIndex:idx_telemetry_count;not null" json:"event_type"`
+	Name       string    `gorm:"type:varchar(64);uniqueIndex:idx_telemetry_count;not null" json:"name"` // Screen or feature name, e.g. quests.detail
+	Platform   string    `gorm:"type:varchar(16);uniqueIndex:idx_telemetry_count;not null" json:"platform"`
+	AppVersion string    `gorm:"type:varchar(32);uniqueIndex:idx_telemetry_count;not null;default:''" json:"app_version"`
+	Count      int64     `gorm:"not null" json:"count"`
+}
+
+func (TelemetryCount) TableName() string {
+	return "telemetry_counts"
+}
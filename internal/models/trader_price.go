@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// TraderPriceHistory is a snapshot of one item's price at a trader, recorded on a refresh
+// of the external trader data when the price differs from the latest snapshot
+type TraderPriceHistory struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	TraderID       string    `gorm:"index:idx_trader_price_item;not null" json:"trader_id"` // Lowercased trader name from the external feed
+	TraderName     string    `json:"trader_name"`
+	ItemExternalID string    `gorm:"index:idx_trader_price_item;not null" json:"item_id"`
+	ItemName       string    `json:"item_name,omitempty"`
+	Price          float64   `gorm:"not null" json:"price"`
+	RecordedAt     time.Time `gorm:"index:idx_trader_price_item;index;not null" json:"recorded_at"`
+}
+
+func (TraderPriceHistory) TableName() string {
+	return "trader_price_history"
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// TraderStockItem records that a trader stocked an item during a weekly stock rotation,
+// from the first to the last refresh of the external trader data that listed it
+type TraderStockItem struct {
+	ID             uint      `gorm:"primaryKey" json:"-"`
+	TraderID       string    `gorm:"uniqueIndex:idx_trader_stock_rotation;not null" json:"trader_id"` // Lowercased trader name from the external feed
+	TraderName     string    `json:"trader_name"`
+	ItemExternalID string    `gorm:"uniqueIndex:idx_trader_stock_rotation;not null" json:"item_id"`
+	ItemName       string    `json:"item_name,omitempty"`
+	RotationStart  time.Time `gorm:"uniqueIndex:idx_trader_stock_rotation;index;not null" json:"-"`
+	Rotating       bool      `gorm:"not null;default:false" json:"rotating"` // Flagged as rotating stock by the feed
+	FirstSeenAt    time.Time `gorm:"not null" json:"first_seen_at"`
+	LastSeenAt     time.Time `gorm:"not null" json:"last_seen_at"`
+}
+
+func (TraderStockItem) TableName() string {
+	return "trader_stock_items"
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+)
+
+// Entity types translations are stored for
+const (
+	TranslationEntityQuest         = "quest"
+	TranslationEntityItem          = "item"
+	TranslationEntitySkillNode     = "skill_node"
+	TranslationEntityHideoutModule = "hideout_module"
+)
+
+// TranslationLanguages are the language keys of upstream multilingual strings
+var TranslationLanguages = []string{"en", "de", "es", "fr", "it", "ja", "kr", "no", "pl", "pt", "ru", "tr", "uk", "zh-CN", "zh-TW", "da", "hr", "sr"}
+
+// IsTranslationLanguage reports whether key is one of TranslationLanguages
+func IsTranslationLanguage(key string) bool {
+	for _, lang := range TranslationLanguages {
+		if lang == key {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTranslationMap reports whether m is a multilingual string like {"en": "...", "de": "..."}
+func IsTranslationMap(m map[string]interface{}) bool {
+	if _, ok := m["en"].(string); !ok {
+		return false
+	}
+	for key, val := range m {
+		if _, ok := val.(string); !ok || !IsTranslationLanguage(key) {
+			return false
+		}
+	}
+	return true
+}
+
+// Translation is one language's text for a multilingual field, normalized out of an
+// entity's Data at sync time. Field is the dotted path within Data, e.g. "name" or
+// "objectives.0".
+type Translation struct {
+	ID         uint      `gorm:"primaryKey" json:"-"`
+	EntityType string    `gorm:"uniqueIndex:idx_translation;index:idx_translation_lookup,priority:1;not null" json:"entity_type"`
+	EntityID   string    `gorm:"uniqueIndex:idx_translation;not null" json:"entity_id"` // Entity external ID
+	Field      string    `gorm:"uniqueIndex:idx_translation;index:idx_translation_lookup,priority:2;not null" json:"field"`
+	Lang       string    `gorm:"uniqueIndex:idx_translation;index:idx_translation_lookup,priority:3;not null" json:"lang"`
+	Text       string    `gorm:"type:text;not null" json:"text"`
+	SyncedAt   time.Time `json:"synced_at"`
+}
+
+func (Translation) TableName() string {
+	return "translations"
+}
@@ -12,16 +12,24 @@ const (
 )
 
 type User struct {
-	ID            uint      `gorm:"primaryKey" json:"id"`
-	GithubID      *string   `gorm:"uniqueIndex;null" json:"github_id,omitempty"`
-	DiscordID     *string   `gorm:"uniqueIndex;null" json:"discord_id,omitempty"`
-	Email         string    `gorm:"uniqueIndex;not null" json:"email"`
-	Username      string    `gorm:"uniqueIndex;not null" json:"username"`
-	Role          UserRole  `gorm:"type:varchar(20);default:'user';not null" json:"role"`
-	CanAccessData bool      `gorm:"default:false;not null" json:"can_access_data"` // Admin-controlled access (deprecated - all users have read access by default)
-	CreatedViaApp bool      `gorm:"default:false;not null" json:"created_via_app"` // True if user was created via mobile app
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            uint     `gorm:"primaryKey" json:"id"`
+	GithubID      *string  `gorm:"uniqueIndex;null" json:"github_id,omitempty"`
+	DiscordID     *string  `gorm:"uniqueIndex;null" json:"discord_id,omitempty"`
+	Email         string   `gorm:"uniqueIndex;not null" json:"email"`
+	Username      string   `gorm:"uniqueIndex;not null" json:"username"`
+	Role          UserRole `gorm:"type:varchar(20);default:'user';not null" json:"role"`
+	CanAccessData bool     `gorm:"default:false;not null" json:"can_access_data"` // Admin-controlled access (deprecated - all users have read access by default)
+	CreatedViaApp bool     `gorm:"default:false;not null" json:"created_via_app"` // True if user was created via mobile app
+
+	// Privacy settings
+	LeaderboardOptIn bool   `gorm:"default:false;not null" json:"leaderboard_opt_in"` // Users only appear on leaderboards after opting in
+	LeaderboardName  string `gorm:"size:32" json:"leaderboard_name,omitempty"`        // Shown on leaderboards instead of the username when set
+	TelemetryOptIn   bool   `gorm:"default:false;not null" json:"telemetry_opt_in"`   // Client apps may only report usage analytics after opting in
+	LastSeenPublic   bool   `gorm:"default:false;not null" json:"last_seen_public"`   // Teammates and the public profile only see last_seen_at after opting in
+
+	LastSeenAt *time.Time `gorm:"index" json:"last_seen_at,omitempty"` // Last authenticated request, updated at most every few minutes
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
 }
 
 func (User) TableName() string {
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// Entity types a user note can be attached to
+const (
+	NoteEntityQuest         = "quest"
+	NoteEntityItem          = "item"
+	NoteEntityHideoutModule = "hideout_module"
+)
+
+// UserNote is a free-text annotation a user keeps on a quest, item or hideout module,
+// e.g. "farm this in Dam Battlegrounds". Each user has at most one note per entity.
+type UserNote struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"uniqueIndex:idx_user_note;not null" json:"user_id"`
+	EntityType string    `gorm:"type:varchar(32);uniqueIndex:idx_user_note;not null" json:"entity_type"`
+	EntityID   string    `gorm:"uniqueIndex:idx_user_note;not null" json:"entity_id"` // External ID of the entity
+	Body       string    `gorm:"type:text;not null" json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (UserNote) TableName() string {
+	return "user_notes"
+}
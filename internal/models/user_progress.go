@@ -13,9 +13,12 @@ type UserQuestProgress struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
-	// Relations
-	User  User  `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	Quest Quest `gorm:"foreignKey:QuestID" json:"quest,omitempty"`
+	// QuestExternalID is read from a join on list queries; it is not a column
+	QuestExternalID string `gorm:"->;-:migration" json:"quest_external_id,omitempty"`
+
+	// Relations (only populated when the entity is explicitly preloaded)
+	User  *User  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Quest *Quest `gorm:"foreignKey:QuestID" json:"quest,omitempty"`
 }
 
 func (UserQuestProgress) TableName() string {
@@ -32,9 +35,12 @@ type UserHideoutModuleProgress struct {
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
 
-	// Relations
-	User          User          `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	HideoutModule HideoutModule `gorm:"foreignKey:HideoutModuleID" json:"hideout_module,omitempty"`
+	// HideoutModuleExternalID is read from a join on list queries; it is not a column
+	HideoutModuleExternalID string `gorm:"->;-:migration" json:"hideout_module_external_id,omitempty"`
+
+	// Relations (only populated when the entity is explicitly preloaded)
+	User          *User          `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	HideoutModule *HideoutModule `gorm:"foreignKey:HideoutModuleID" json:"hideout_module,omitempty"`
 }
 
 func (UserHideoutModuleProgress) TableName() string {
@@ -51,9 +57,12 @@ type UserSkillNodeProgress struct {
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 
-	// Relations
-	User      User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	SkillNode SkillNode `gorm:"foreignKey:SkillNodeID" json:"skill_node,omitempty"`
+	// SkillNodeExternalID is read from a join on list queries; it is not a column
+	SkillNodeExternalID string `gorm:"->;-:migration" json:"skill_node_external_id,omitempty"`
+
+	// Relations (only populated when the entity is explicitly preloaded)
+	User      *User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	SkillNode *SkillNode `gorm:"foreignKey:SkillNodeID" json:"skill_node,omitempty"`
 }
 
 func (UserSkillNodeProgress) TableName() string {
@@ -69,11 +78,72 @@ type UserBlueprintProgress struct {
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 
-	// Relations
-	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	Item Item `gorm:"foreignKey:ItemID" json:"item,omitempty"`
+	// ItemExternalID is read from a join on list queries; it is not a column
+	ItemExternalID string `gorm:"->;-:migration" json:"item_external_id,omitempty"`
+
+	// Relations (only populated when the entity is explicitly preloaded)
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Item *Item `gorm:"foreignKey:ItemID" json:"item,omitempty"`
 }
 
 func (UserBlueprintProgress) TableName() string {
 	return "user_blueprint_progress"
 }
+
+// UserTraderProgress tracks a user's level and reputation with a trader
+type UserTraderProgress struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"uniqueIndex:idx_user_trader;not null" json:"user_id"`
+	TraderID   uint      `gorm:"uniqueIndex:idx_user_trader;not null" json:"trader_id"`
+	Level      int       `gorm:"default:1;not null" json:"level"`
+	Reputation int       `gorm:"default:0;not null" json:"reputation"` // Reputation points towards the next level
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	// TraderExternalID and TraderName are read from a join on list queries; they are not columns
+	TraderExternalID string `gorm:"->;-:migration" json:"trader_external_id,omitempty"`
+	TraderName       string `gorm:"->;-:migration" json:"trader_name,omitempty"`
+
+	// Relations (only populated when the entity is explicitly preloaded)
+	User   *User   `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Trader *Trader `gorm:"foreignKey:TraderID" json:"trader,omitempty"`
+}
+
+func (UserTraderProgress) TableName() string {
+	return "user_trader_progress"
+}
+
+// UserPlayerLevel is the player level and total XP a user declares for their character
+type UserPlayerLevel struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"uniqueIndex;not null" json:"user_id"`
+	Level     int       `gorm:"default:1;not null" json:"level"`
+	XP        int       `gorm:"default:0;not null" json:"xp"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (UserPlayerLevel) TableName() string {
+	return "user_player_levels"
+}
+
+// UserInventoryItem records how many of an item a user currently holds in their stash
+type UserInventoryItem struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"uniqueIndex:idx_user_inventory_item;not null" json:"user_id"`
+	ItemID    uint      `gorm:"uniqueIndex:idx_user_inventory_item;not null" json:"item_id"`
+	Quantity  int       `gorm:"default:0;not null" json:"quantity"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// ItemExternalID is read from a join on list queries; it is not a column
+	ItemExternalID string `gorm:"->;-:migration" json:"item_external_id,omitempty"`
+
+	// Relations (only populated when the entity is explicitly preloaded)
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Item *Item `gorm:"foreignKey:ItemID" json:"item,omitempty"`
+}
+
+func (UserInventoryItem) TableName() string {
+	return "user_inventory_items"
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+	"gorm.io/gorm"
+)
+
+type DeviceCodeRepository struct{ db *gorm.DB }
+
+func NewDeviceCodeRepository(db *DB) *DeviceCodeRepository { return &DeviceCodeRepository{db: db.DB} }
+
+func (r *DeviceCodeRepository) Create(plainDeviceCode, userCode, clientName string, ttl time.Duration) (*models.DeviceCode, error) {
+	hash := sha256.Sum256([]byte(plainDeviceCode))
+	code := models.DeviceCode{
+		DeviceCodeHash: hex.EncodeToString(hash[:]),
+		UserCode:       userCode,
+		ClientName:     clientName,
+		ExpiresAt:      time.Now().Add(ttl),
+	}
+	if err := r.db.Create(&code).Error; err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+func (r *DeviceCodeRepository) FindByPlainDeviceCode(plainDeviceCode string) (*models.DeviceCode, error) {
+	hash := sha256.Sum256([]byte(plainDeviceCode))
+	var code models.DeviceCode
+	if err := r.db.Where("device_code_hash = ?", hex.EncodeToString(hash[:])).First(&code).Error; err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+func (r *DeviceCodeRepository) FindByUserCode(userCode string) (*models.DeviceCode, error) {
+	var code models.DeviceCode
+	if err := r.db.Where("user_code = ?", userCode).First(&code).Error; err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+func (r *DeviceCodeRepository) Approve(code *models.DeviceCode, userID uint) error {
+	now := time.Now()
+	return r.db.Model(code).Updates(map[string]interface{}{"user_id": userID, "approved_at": &now}).Error
+}
+
+func (r *DeviceCodeRepository) Deny(code *models.DeviceCode) error {
+	now := time.Now()
+	return r.db.Model(code).Update("denied_at", &now).Error
+}
+
+func (r *DeviceCodeRepository) MarkPolled(code *models.DeviceCode) error {
+	now := time.Now()
+	return r.db.Model(code).Update("last_polled_at", &now).Error
+}
+
+// Consume marks an approved code as exchanged. It returns false if another poll consumed it first.
+func (r *DeviceCodeRepository) Consume(code *models.DeviceCode) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&models.DeviceCode{}).
+		Where("id = ? AND consumed_at IS NULL", code.ID).
+		Update("consumed_at", &now)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteExpired removes device codes that expired before the given time
+func (r *DeviceCodeRepository) DeleteExpired(before time.Time) error {
+	return r.db.Where("expires_at < ?", before).Delete(&models.DeviceCode{}).Error
+}
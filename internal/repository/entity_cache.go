@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+)
+
+// entityCacheKeyPrefix namespaces cached detail lookups in Redis
+const entityCacheKeyPrefix = "entity:"
+
+// EntityCacheStore is the key-value store detail lookups are cached in, implemented by
+// services.CacheService
+type EntityCacheStore interface {
+	Get(key string) ([]byte, error)
+	Set(key string, value []byte, ttl time.Duration) error
+	Incr(key string) (int64, error)
+}
+
+// EntityCache is a read-through cache for FindByID and FindByExternalID of synced
+// entities, which rarely change between syncs. Keys carry a version per entity type that
+// Invalidate bumps, so every instance stops reading the old entries at once and they are
+// left to expire. A nil EntityCache, or one with a TTL of 0, caches nothing.
+type EntityCache struct {
+	store EntityCacheStore
+	ttl   time.Duration
+}
+
+func NewEntityCache(store EntityCacheStore, ttl time.Duration) *EntityCache {
+	return &EntityCache{store: store, ttl: ttl}
+}
+
+// scope returns the part of the cache holding one entity type
+func (c *EntityCache) scope(entity string) *entityCacheScope {
+	return &entityCacheScope{cache: c, entity: entity}
+}
+
+// entityCacheScope caches the rows of one entity type
+type entityCacheScope struct {
+	cache  *EntityCache
+	entity string
+}
+
+func (s *entityCacheScope) enabled() bool {
+	return s != nil && s.cache != nil && s.cache.ttl > 0
+}
+
+func (s *entityCacheScope) versionKey() string {
+	return entityCacheKeyPrefix + s.entity + ":version"
+}
+
+// key returns the cache key of a lookup under the current version, or false if the
+// version can't be read
+func (s *entityCacheScope) key(lookup string) (string, bool) {
+	version, err := s.cache.store.Get(s.versionKey())
+	if err != nil {
+		return "", false
+	}
+	if version == nil {
+		version = []byte("0")
+	}
+	return entityCacheKeyPrefix + s.entity + ":v" + string(version) + ":" + lookup, true
+}
+
+// invalidate drops every cached row of the entity type. Failures are only logged; rows
+// then stay cached until they expire.
+func (s *entityCacheScope) invalidate() {
+	if !s.enabled() {
+		return
+	}
+	if _, err := s.cache.store.Incr(s.versionKey()); err != nil {
+		log.Printf("Warning: Failed to invalidate cached %s lookups: %v", s.entity, err)
+	}
+}
+
+// findCached returns the row cached for lookup, or loads it with load and caches it.
+// Lookups that fail, including for rows that don't exist, aren't cached, and the cache
+// being unreachable only costs the database query.
+func findCached[T any](s *entityCacheScope, lookup string, load func() (*T, error)) (*T, error) {
+	if !s.enabled() {
+		return load()
+	}
+	key, ok := s.key(lookup)
+	if !ok {
+		return load()
+	}
+	if raw, err := s.cache.store.Get(key); err == nil && raw != nil {
+		var row T
+		if err := json.Unmarshal(raw, &row); err == nil {
+			return &row, nil
+		}
+	}
+
+	row, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if raw, err := json.Marshal(row); err == nil {
+		_ = s.cache.store.Set(key, raw, s.cache.ttl)
+	}
+	return row, nil
+}
+
+func idLookup(id uint) string {
+	return "id:" + strconv.FormatUint(uint64(id), 10)
+}
+
+func externalIDLookup(externalID string) string {
+	return "external_id:" + externalID
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+	"gorm.io/gorm"
+)
+
+type memoryCacheStore struct {
+	values map[string][]byte
+	err    error
+}
+
+func newMemoryCacheStore() *memoryCacheStore {
+	return &memoryCacheStore{values: make(map[string][]byte)}
+}
+
+func (s *memoryCacheStore) Get(key string) ([]byte, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.values[key], nil
+}
+
+func (s *memoryCacheStore) Set(key string, value []byte, ttl time.Duration) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.values[key] = value
+	return nil
+}
+
+func (s *memoryCacheStore) Incr(key string) (int64, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	n, _ := strconv.ParseInt(string(s.values[key]), 10, 64)
+	n++
+	s.values[key] = []byte(strconv.FormatInt(n, 10))
+	return n, nil
+}
+
+func TestFindCachedReadsThrough(t *testing.T) {
+	scope := NewEntityCache(newMemoryCacheStore(), time.Hour).scope("items")
+	loads := 0
+	load := func() (*models.Item, error) {
+		loads++
+		return &models.Item{ExternalID: "rusted_gear", Name: "Rusted Gear"}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		item, err := findCached(scope, externalIDLookup("rusted_gear"), load)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if item.Name != "Rusted Gear" {
+			t.Errorf("unexpected item %+v", item)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("expected the second lookup to be served from the cache, loaded %d times", loads)
+	}
+
+	scope.invalidate()
+	if _, err := findCached(scope, externalIDLookup("rusted_gear"), load); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loads != 2 {
+		t.Errorf("expected a lookup after invalidation to load again, loaded %d times", loads)
+	}
+}
+
+func TestFindCachedSkipsMisses(t *testing.T) {
+	scope := NewEntityCache(newMemoryCacheStore(), time.Hour).scope("quests")
+	loads := 0
+	load := func() (*models.Quest, error) {
+		loads++
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := findCached(scope, idLookup(7), load); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("expected not found, got %v", err)
+		}
+	}
+	if loads != 2 {
+		t.Errorf("rows that don't exist must not be cached, loaded %d times", loads)
+	}
+}
+
+func TestFindCachedFallsBackToLoad(t *testing.T) {
+	store := newMemoryCacheStore()
+	store.err = errors.New("connection refused")
+	var nilCache *EntityCache
+
+	for name, scope := range map[string]*entityCacheScope{
+		"unreachable": NewEntityCache(store, time.Hour).scope("maps"),
+		"disabled":    NewEntityCache(newMemoryCacheStore(), 0).scope("maps"),
+		"nil":         nilCache.scope("maps"),
+	} {
+		loads := 0
+		for i := 0; i < 2; i++ {
+			m, err := findCached(scope, idLookup(1), func() (*models.Map, error) {
+				loads++
+				return &models.Map{Name: "Dam"}, nil
+			})
+			if err != nil || m.Name != "Dam" {
+				t.Fatalf("%s: unexpected result %+v, %v", name, m, err)
+			}
+		}
+		if loads != 2 {
+			t.Errorf("%s: expected every lookup to load, loaded %d times", name, loads)
+		}
+		scope.invalidate()
+	}
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Filter is a single PostgREST-style condition, e.g. ?name=ilike.*alloy* becomes
+// {Column: "name", Operator: "ilike", Value: "%alloy%"}
+type Filter struct {
+	Column   string
+	Operator string
+	Value    interface{}
+	Negate   bool
+}
+
+// postgrestOperators maps PostgREST operators to SQL; "in" and "is" are handled separately
+var postgrestOperators = map[string]string{
+	"eq":    "=",
+	"neq":   "<>",
+	"gt":    ">",
+	"gte":   ">=",
+	"lt":    "<",
+	"lte":   "<=",
+	"like":  "LIKE",
+	"ilike": "ILIKE",
+}
+
+// ParseFilters reads PostgREST-style filters (?column=op.value, ?column=not.op.value) from a
+// query string. Only parameters named in columns are considered, which maps the public
+// parameter name to its SQL column so nothing user-supplied ends up in the query text.
+func ParseFilters(query url.Values, columns map[string]string) ([]Filter, error) {
+	var filters []Filter
+	for param, column := range columns {
+		for _, raw := range query[param] {
+			filter, err := parseFilter(column, raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter %s=%s: %w", param, raw, err)
+			}
+			filters = append(filters, filter)
+		}
+	}
+	return filters, nil
+}
+
+func parseFilter(column, raw string) (Filter, error) {
+	filter := Filter{Column: column}
+	if rest, ok := strings.CutPrefix(raw, "not."); ok {
+		filter.Negate = true
+		raw = rest
+	}
+
+	op, value, ok := strings.Cut(raw, ".")
+	if !ok {
+		return filter, fmt.Errorf("expected operator.value")
+	}
+	filter.Operator = op
+
+	switch op {
+	case "eq", "neq", "gt", "gte", "lt", "lte":
+		filter.Value = value
+	case "like", "ilike":
+		// PostgREST uses * as the wildcard so it doesn't need URL encoding
+		filter.Value = strings.ReplaceAll(value, "*", "%")
+	case "in":
+		if !strings.HasPrefix(value, "(") || !strings.HasSuffix(value, ")") {
+			return filter, fmt.Errorf("in expects a list like in.(a,b)")
+		}
+		values := strings.Split(strings.TrimSuffix(strings.TrimPrefix(value, "("), ")"), ",")
+		for i := range values {
+			values[i] = strings.Trim(strings.TrimSpace(values[i]), `"`)
+		}
+		filter.Value = values
+	case "is":
+		switch strings.ToLower(value) {
+		case "null", "true", "false":
+			filter.Value = strings.ToUpper(value)
+		default:
+			return filter, fmt.Errorf("is expects null, true or false")
+		}
+	default:
+		return filter, fmt.Errorf("unsupported operator %q", op)
+	}
+	return filter, nil
+}
+
+// ApplyFilters adds the filters to a query as WHERE conditions
+func ApplyFilters(db *gorm.DB, filters []Filter) *gorm.DB {
+	for _, f := range filters {
+		var clause string
+		var args []interface{}
+		switch f.Operator {
+		case "in":
+			clause = f.Column + " IN ?"
+			args = []interface{}{f.Value}
+		case "is":
+			// Value is one of the fixed keywords validated in parseFilter
+			clause = fmt.Sprintf("%s IS %s", f.Column, f.Value)
+		default:
+			clause = fmt.Sprintf("%s %s ?", f.Column, postgrestOperators[f.Operator])
+			args = []interface{}{f.Value}
+		}
+		if f.Negate {
+			clause = "NOT (" + clause + ")"
+		}
+		db = db.Where(clause, args...)
+	}
+	return db
+}
+
+// findFiltered runs a filtered, paginated listing for any model ordered by id
+func findFiltered[T any](db *DB, filters []Filter, offset, limit int) ([]T, int64, error) {
+	var results []T
+	var count int64
+	var model T
+	err := ApplyFilters(db.Model(&model), filters).Count(&count).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	err = ApplyFilters(db.Model(&model), filters).Order("id ASC").Offset(offset).Limit(limit).Find(&results).Error
+	return results, count, err
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseFilters(t *testing.T) {
+	columns := map[string]string{"type": "type", "name": "name", "xp": "xp"}
+	query, _ := url.ParseQuery("type=eq.weapon&name=ilike.*alloy*&xp=not.in.(1,2)&page=2")
+
+	filters, err := ParseFilters(query, columns)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := make(map[string]Filter, len(filters))
+	for _, f := range filters {
+		got[f.Column] = f
+	}
+
+	if f := got["type"]; f.Operator != "eq" || f.Value != "weapon" {
+		t.Errorf("unexpected type filter %+v", f)
+	}
+	if f := got["name"]; f.Operator != "ilike" || f.Value != "%alloy%" {
+		t.Errorf("unexpected name filter %+v", f)
+	}
+	if f := got["xp"]; f.Operator != "in" || !f.Negate || !reflect.DeepEqual(f.Value, []string{"1", "2"}) {
+		t.Errorf("unexpected xp filter %+v", f)
+	}
+	if len(filters) != 3 {
+		t.Errorf("parameters outside the column list must be ignored, got %d filters", len(filters))
+	}
+}
+
+func TestParseFiltersRejectsInvalid(t *testing.T) {
+	columns := map[string]string{"name": "name"}
+	for _, raw := range []string{"weapon", "regex.^a", "in.a,b", "is.maybe"} {
+		if _, err := ParseFilters(url.Values{"name": {raw}}, columns); err == nil {
+			t.Errorf("expected %q to be rejected", raw)
+		}
+	}
+}
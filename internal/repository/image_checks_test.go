@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestUpdateImageURLOnlyWritesTheColumn(t *testing.T) {
+	var statements []string
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: recordingPool{recordingConn{&statements}}}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		Logger:                 recordingLogger{logger.Discard, &statements},
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+
+	if err := NewItemRepository(&DB{db}, nil).UpdateImageURL(7, "https://example.com/a.png"); err != nil {
+		t.Fatalf("item UpdateImageURL: %v", err)
+	}
+	if err := NewEnemyTypeRepository(&DB{db}).UpdateImageURL(3, "https://example.com/b.png"); err != nil {
+		t.Fatalf("enemy type UpdateImageURL: %v", err)
+	}
+
+	// A concurrent sync may have changed any other column since the check loaded the row
+	for i, table := range []string{"items", "enemy_types"} {
+		stmt := statements[i]
+		if !strings.HasPrefix(stmt, `UPDATE "`+table+`" SET "image_url"=`) || strings.Contains(stmt, `"name"`) {
+			t.Errorf("expected only image_url to be written, got %q", stmt)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mat/arcapi/migrations"
+	"gorm.io/gorm"
+)
+
+// migrationTable records the IDs of applied migrations
+const migrationTable = "schema_migrations"
+
+// migrationLockKey identifies the Postgres advisory lock held while migrating, so
+// instances starting at the same time apply migrations one at a time
+const migrationLockKey = 4_171_200_001
+
+// MigrationState is one migration and whether it has been applied
+type MigrationState struct {
+	ID      string `json:"id" example:"202610150000_baseline"`
+	Applied bool   `json:"applied" example:"true"`
+}
+
+// MigrationStatus compares the migrations this build knows with those the database has
+// applied. Unknown migrations were applied by a newer build.
+type MigrationStatus struct {
+	Migrations []MigrationState `json:"migrations"`
+	Pending    []string         `json:"pending"`
+	Unknown    []string         `json:"unknown,omitempty"`
+	UpToDate   bool             `json:"up_to_date" example:"true"`
+}
+
+func migrationOptions() *gormigrate.Options {
+	return &gormigrate.Options{
+		TableName:      migrationTable,
+		IDColumnName:   "id",
+		IDColumnSize:   255,
+		UseTransaction: true,
+		// Rolling deploys run older builds against a database a newer one has migrated
+		ValidateUnknownMigrations: false,
+	}
+}
+
+// Migrate applies every pending migration in order, each recorded once it succeeds. It
+// holds an advisory lock for the duration, so concurrent callers wait rather than race.
+func (d *DB) Migrate() error {
+	return d.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+			return fmt.Errorf("failed to take the migration lock: %w", err)
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey)
+
+		return gormigrate.New(conn, migrationOptions(), migrations.All).Migrate()
+	})
+}
+
+// MigrationStatus reports which migrations are applied and which are pending
+func (d *DB) MigrationStatus() (*MigrationStatus, error) {
+	applied := make(map[string]bool)
+	if d.Migrator().HasTable(migrationTable) {
+		var ids []string
+		if err := d.Table(migrationTable).Pluck("id", &ids).Error; err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			applied[id] = true
+		}
+	}
+
+	status := &MigrationStatus{
+		Migrations: make([]MigrationState, 0, len(migrations.All)),
+		Pending:    []string{},
+	}
+	known := make(map[string]bool, len(migrations.All))
+	for _, m := range migrations.All {
+		known[m.ID] = true
+		status.Migrations = append(status.Migrations, MigrationState{ID: m.ID, Applied: applied[m.ID]})
+		if !applied[m.ID] {
+			status.Pending = append(status.Pending, m.ID)
+		}
+	}
+	for id := range applied {
+		if !known[id] {
+			status.Unknown = append(status.Unknown, id)
+		}
+	}
+	status.UpToDate = len(status.Pending) == 0
+	return status, nil
+}
@@ -1,12 +1,12 @@
 package repository
 
 import (
+	"database/sql"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/mat/arcapi/internal/config"
-	"github.com/mat/arcapi/internal/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -26,8 +26,44 @@ func (d *DB) Ping() error {
 	return sqlDB.Ping()
 }
 
-// NewDB creates a new database connection with retry logic for cold starts
+// PoolStats returns connection pool statistics, or zero values if the pool is unavailable
+func (d *DB) PoolStats() sql.DBStats {
+	sqlDB, err := d.DB.DB()
+	if err != nil {
+		return sql.DBStats{}
+	}
+	return sqlDB.Stats()
+}
+
+// NewDB connects to the database and brings its schema up to date, or refuses to when
+// DB_MIGRATE_ON_STARTUP is off and migrations are pending
 func NewDB(cfg *config.Config) (*DB, error) {
+	d, err := Connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply pending schema migrations, or refuse to start against an outdated schema
+	// when migrations are run separately
+	if cfg.DBMigrateOnStartup {
+		if err := d.Migrate(); err != nil {
+			return nil, fmt.Errorf("failed to apply database migrations: %w", err)
+		}
+		return d, nil
+	}
+	status, err := d.MigrationStatus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check database migrations: %w", err)
+	}
+	if !status.UpToDate {
+		return nil, fmt.Errorf("database has %d pending migrations (%v); run `make migrate` or set DB_MIGRATE_ON_STARTUP=true", len(status.Pending), status.Pending)
+	}
+	return d, nil
+}
+
+// Connect creates a new database connection with retry logic for cold starts, without
+// touching the schema
+func Connect(cfg *config.Config) (*DB, error) {
 	var logLevel logger.LogLevel
 	switch cfg.LogLevel {
 	case "debug":
@@ -96,33 +132,5 @@ func NewDB(cfg *config.Config) (*DB, error) {
 		return nil, fmt.Errorf("database ping failed after connection: %w", err)
 	}
 
-	// Auto-migrate all models
-	err = db.AutoMigrate(
-		&models.User{},
-		&models.APIKey{},
-		&models.JWTToken{},
-		&models.Quest{},
-		&models.Item{},
-		&models.SkillNode{},
-		&models.HideoutModule{},
-		&models.EnemyType{},
-		&models.Alert{},
-		&models.AuditLog{},
-		&models.UserQuestProgress{},
-		&models.UserHideoutModuleProgress{},
-		&models.UserSkillNodeProgress{},
-		&models.UserBlueprintProgress{},
-		&models.AuthorizationCode{},
-		&models.RefreshToken{},
-		&models.Bot{},
-		&models.Map{},
-		&models.Trader{},
-		&models.Project{},
-		&models.Metadata{},
-	)
-	if err != nil {
-		return nil, err
-	}
-
 	return &DB{DB: db}, nil
 }
@@ -13,10 +13,31 @@ type RefreshTokenRepository struct { db *gorm.DB }
 
 func NewRefreshTokenRepository(db *DB) *RefreshTokenRepository { return &RefreshTokenRepository{db: db.DB} }
 
-func (r *RefreshTokenRepository) Create(userID uint, plainToken string, expiry time.Time) error {
+// CreateInFamily stores a new refresh token as part of an existing (or new) token family.
+// It's issued in response to a request from the device, which counts as its first use.
+func (r *RefreshTokenRepository) CreateInFamily(userID uint, plainToken, familyID, scope string, expiry time.Time, userAgent, ipAddress string) (*models.RefreshToken, error) {
 	hash := sha256.Sum256([]byte(plainToken))
-	rt := models.RefreshToken{ UserID: userID, TokenHash: hex.EncodeToString(hash[:]), ExpiresAt: expiry }
-	return r.db.Create(&rt).Error
+	now := time.Now()
+	rt := models.RefreshToken{
+		UserID:     userID,
+		TokenHash:  hex.EncodeToString(hash[:]),
+		FamilyID:   familyID,
+		Scope:      scope,
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+		ExpiresAt:  expiry,
+		LastUsedAt: &now,
+	}
+	if err := r.db.Create(&rt).Error; err != nil { return nil, err }
+	return &rt, nil
+}
+
+// RevokeFamily revokes every still-active token in a family and returns how many were revoked.
+func (r *RefreshTokenRepository) RevokeFamily(familyID string) (int64, error) {
+	result := r.db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
 }
 
 func (r *RefreshTokenRepository) FindByPlain(plainToken string) (*models.RefreshToken, error) {
@@ -27,11 +48,31 @@ func (r *RefreshTokenRepository) FindByPlain(plainToken string) (*models.Refresh
 	return &rt, nil
 }
 
+func (r *RefreshTokenRepository) FindByID(id uint) (*models.RefreshToken, error) {
+	var rt models.RefreshToken
+	if err := r.db.First(&rt, id).Error; err != nil { return nil, err }
+	return &rt, nil
+}
+
+func (r *RefreshTokenRepository) FindActiveByUserID(userID uint) ([]models.RefreshToken, error) {
+	var tokens []models.RefreshToken
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).Order("created_at DESC").Find(&tokens).Error
+	return tokens, err
+}
+
 func (r *RefreshTokenRepository) Revoke(rt *models.RefreshToken) error {
 	now := time.Now()
 	return r.db.Model(rt).Updates(map[string]interface{}{"revoked_at": &now}).Error
 }
 
+// RevokeActive revokes the token unless it already is, returning false if it was
+func (r *RefreshTokenRepository) RevokeActive(rt *models.RefreshToken) (bool, error) {
+	result := r.db.Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", rt.ID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
 func (r *RefreshTokenRepository) Touch(rt *models.RefreshToken) error {
 	now := time.Now()
 	return r.db.Model(rt).Update("last_used_at", &now).Error
@@ -1,8 +1,16 @@
 package repository
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
 	"github.com/mat/arcapi/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository struct {
@@ -26,6 +34,15 @@ func (r *UserRepository) FindByID(id uint) (*models.User, error) {
 	return &user, nil
 }
 
+func (r *UserRepository) FindByIDs(ids []uint) ([]models.User, error) {
+	var users []models.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
 func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
 	var user models.User
 	err := r.db.Where("email = ?", email).First(&user).Error
@@ -72,6 +89,75 @@ func (r *UserRepository) FindAll(offset, limit int) ([]models.User, int64, error
 	return users, count, err
 }
 
+// FindSeenSince pages through users whose last request was at or after since, most
+// recently seen first
+func (r *UserRepository) FindSeenSince(since time.Time, offset, limit int) ([]models.User, int64, error) {
+	query := r.db.Model(&models.User{}).Where("last_seen_at >= ?", since)
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	var users []models.User
+	err := query.Order("last_seen_at DESC").Offset(offset).Limit(limit).Find(&users).Error
+	return users, count, err
+}
+
+// InactiveUser is a user without recent activity, with the last time they were seen and
+// last changed their progress
+type InactiveUser struct {
+	ID             uint            `json:"id" example:"42"`
+	Username       string          `json:"username" example:"raider"`
+	Email          string          `json:"email" example:"raider@example.com"`
+	Role           models.UserRole `json:"role" example:"user"`
+	CreatedAt      time.Time       `json:"created_at"`
+	LastSeenAt     *time.Time      `json:"last_seen_at"`
+	LastProgressAt *time.Time      `json:"last_progress_at"`
+}
+
+// inactiveUserProgressTables are the per-user tables a progress change is written to
+var inactiveUserProgressTables = []string{
+	"user_quest_progress",
+	"user_hideout_module_progress",
+	"user_skill_node_progress",
+	"user_blueprint_progress",
+	"user_trader_progress",
+	"user_player_levels",
+	"user_inventory_items",
+}
+
+// FindInactive lists the users created before cutoff who have not been seen, made an
+// audited request or changed their progress since, least recently seen first
+func (r *UserRepository) FindInactive(cutoff time.Time) ([]InactiveUser, error) {
+	progress := make([]string, len(inactiveUserProgressTables))
+	for i, table := range inactiveUserProgressTables {
+		progress[i] = "SELECT user_id, updated_at FROM " + table
+	}
+	var users []InactiveUser
+	err := r.db.Raw(`
+		SELECT u.id, u.username, u.email, u.role, u.created_at, u.last_seen_at, p.last_progress_at
+		FROM users u
+		LEFT JOIN (
+			SELECT user_id, MAX(updated_at) AS last_progress_at
+			FROM (`+strings.Join(progress, " UNION ALL ")+`) AS progress
+			GROUP BY user_id
+		) p ON p.user_id = u.id
+		WHERE u.created_at < ?
+			AND (u.last_seen_at IS NULL OR u.last_seen_at < ?)
+			AND (p.last_progress_at IS NULL OR p.last_progress_at < ?)
+			AND NOT EXISTS (SELECT 1 FROM audit_logs a WHERE a.user_id = u.id AND a.created_at >= ?)
+		ORDER BY COALESCE(u.last_seen_at, u.created_at) ASC, u.id ASC`,
+		cutoff, cutoff, cutoff, cutoff).Scan(&users).Error
+	return users, err
+}
+
+// TouchLastSeen sets a user's last_seen_at to now unless it is already more recent than
+// interval ago, without bumping updated_at
+func (r *UserRepository) TouchLastSeen(id uint, interval time.Duration) error {
+	return r.db.Model(&models.User{}).
+		Where("id = ? AND (last_seen_at IS NULL OR last_seen_at < ?)", id, time.Now().Add(-interval)).
+		UpdateColumn("last_seen_at", gorm.Expr("NOW()")).Error
+}
+
 type APIKeyRepository struct {
 	db *DB
 }
@@ -122,6 +208,13 @@ func (r *APIKeyRepository) FindAllActive() ([]models.APIKey, error) {
 	return keys, err
 }
 
+// CountActive counts the API keys that have not been revoked
+func (r *APIKeyRepository) CountActive() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.APIKey{}).Where("revoked_at IS NULL").Count(&count).Error
+	return count, err
+}
+
 func (r *APIKeyRepository) FindAll() ([]models.APIKey, error) {
 	var keys []models.APIKey
 	err := r.db.Preload("User").Order("id ASC").Find(&keys).Error
@@ -149,6 +242,15 @@ func (r *JWTTokenRepository) FindByHash(hash string) (*models.JWTToken, error) {
 	return &token, nil
 }
 
+func (r *JWTTokenRepository) FindByID(id uint) (*models.JWTToken, error) {
+	var token models.JWTToken
+	err := r.db.First(&token, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
 func (r *JWTTokenRepository) FindActiveByUserID(userID uint) ([]models.JWTToken, error) {
 	var tokens []models.JWTToken
 	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, gorm.Expr("NOW()")).Find(&tokens).Error
@@ -163,34 +265,64 @@ func (r *JWTTokenRepository) RevokeByHash(hash string) error {
 	return r.db.Model(&models.JWTToken{}).Where("token_hash = ?", hash).Update("revoked_at", gorm.Expr("NOW()")).Error
 }
 
+func (r *JWTTokenRepository) FindByJTI(jti string) (*models.JWTToken, error) {
+	var token models.JWTToken
+	err := r.db.Where("jti = ?", jti).Order("id DESC").First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RevokeByJTI revokes the token(s) carrying the given jti and returns how many rows were revoked
+func (r *JWTTokenRepository) RevokeByJTI(jti string) (int64, error) {
+	result := r.db.Model(&models.JWTToken{}).Where("jti = ? AND revoked_at IS NULL", jti).Update("revoked_at", gorm.Expr("NOW()"))
+	return result.RowsAffected, result.Error
+}
+
+func (r *JWTTokenRepository) Touch(id uint) error {
+	return r.db.Model(&models.JWTToken{}).Where("id = ?", id).Update("last_used_at", gorm.Expr("NOW()")).Error
+}
+
 type QuestRepository struct {
-	db *DB
+	db    *DB
+	cache *entityCacheScope
+}
+
+func NewQuestRepository(db *DB, cache *EntityCache) *QuestRepository {
+	return &QuestRepository{db: db, cache: cache.scope("quests")}
 }
 
-func NewQuestRepository(db *DB) *QuestRepository {
-	return &QuestRepository{db: db}
+// InvalidateCache drops the cached detail lookups of every quest
+func (r *QuestRepository) InvalidateCache() {
+	r.cache.invalidate()
 }
 
 func (r *QuestRepository) Create(quest *models.Quest) error {
+	defer r.cache.invalidate()
 	return r.db.Create(quest).Error
 }
 
 func (r *QuestRepository) FindByID(id uint) (*models.Quest, error) {
-	var quest models.Quest
-	err := r.db.First(&quest, id).Error
-	if err != nil {
-		return nil, err
-	}
-	return &quest, nil
+	return findCached(r.cache, idLookup(id), func() (*models.Quest, error) {
+		var quest models.Quest
+		err := r.db.First(&quest, id).Error
+		if err != nil {
+			return nil, err
+		}
+		return &quest, nil
+	})
 }
 
 func (r *QuestRepository) FindByExternalID(externalID string) (*models.Quest, error) {
-	var quest models.Quest
-	err := r.db.Where("external_id = ?", externalID).First(&quest).Error
-	if err != nil {
-		return nil, err
-	}
-	return &quest, nil
+	return findCached(r.cache, externalIDLookup(externalID), func() (*models.Quest, error) {
+		var quest models.Quest
+		err := r.db.Where("external_id = ?", externalID).First(&quest).Error
+		if err != nil {
+			return nil, err
+		}
+		return &quest, nil
+	})
 }
 
 func (r *QuestRepository) FindAll(offset, limit int) ([]models.Quest, int64, error) {
@@ -204,6 +336,158 @@ func (r *QuestRepository) FindAll(offset, limit int) ([]models.Quest, int64, err
 	return quests, count, err
 }
 
+// findAllBatched calls fn with every row of a model in id order, batchSize rows at a
+// time, so callers can stream whole tables without loading them into memory. It stops at
+// the first error fn returns.
+func findAllBatched[T any](db *DB, batchSize int, fn func([]T) error) error {
+	var batch []T
+	return db.FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}).Error
+}
+
+// upsertBatchSize is how many rows upsertAllByExternalID writes per statement
+const upsertBatchSize = 500
+
+// upsertAllByExternalID inserts rows, updating the existing row instead when one has the
+// same external_id, in a single transaction so a failure leaves the table untouched.
+// created_at is kept on updated rows.
+func upsertAllByExternalID[T any](db *DB, rows []T) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "external_id"}},
+			UpdateAll: true,
+		}).CreateInBatches(rows, upsertBatchSize).Error
+	})
+}
+
+// ErrManualOverride is returned by UpsertByExternalID when the row was edited through the
+// API, and so was left as it is
+var ErrManualOverride = errors.New("record was edited manually and is not overwritten by sync")
+
+// recordSyncConflict keeps track of whether upstream has changed a row edited through the
+// API, which sync leaves as local. changed is whether the upstream data differs from the
+// data the row has. A conflict admins dismissed stays dismissed until upstream changes again.
+func recordSyncConflict(db *DB, entityType string, entityID uint, externalID string, local, upstream interface{}, changed bool) error {
+	var conflict models.SyncConflict
+	err := db.Where("entity_type = ? AND external_id = ?", entityType, externalID).First(&conflict).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+	found := err == nil
+
+	if !changed {
+		if !found {
+			return nil
+		}
+		return db.Delete(&conflict).Error
+	}
+
+	upstreamSnapshot := conflictSnapshot(upstream)
+	if found && reflect.DeepEqual(conflict.Upstream, upstreamSnapshot) {
+		return nil
+	}
+	if !found {
+		conflict = models.SyncConflict{EntityType: entityType, ExternalID: externalID, DetectedAt: time.Now()}
+	}
+	conflict.EntityID = entityID
+	conflict.Local = conflictSnapshot(local)
+	conflict.Upstream = upstreamSnapshot
+	conflict.DismissedAt = nil
+	return db.Save(&conflict).Error
+}
+
+// conflictSnapshot returns the fields of a content row that sync and admins set, for
+// comparing its local and upstream versions
+func conflictSnapshot(row interface{}) models.JSONB {
+	var snapshot models.JSONB
+	data, err := json.Marshal(row)
+	if err != nil || json.Unmarshal(data, &snapshot) != nil {
+		return nil
+	}
+	for _, field := range []string{"id", "manual_override", "synced_at", "created_at", "updated_at", "tags"} {
+		delete(snapshot, field)
+	}
+	return snapshot
+}
+
+// softDeleteDependent is a table whose rows reference a soft-deletable model by column,
+// and are deleted along with it when it is purged
+type softDeleteDependent struct {
+	model  interface{}
+	column string
+}
+
+// restoreDeleted clears deleted_at on the soft-deleted row of model with id. It returns
+// gorm.ErrRecordNotFound when there is no such row, including when it is not deleted.
+func restoreDeleted(db *DB, model interface{}, id uint) error {
+	result := db.Unscoped().Model(model).Where("id = ? AND deleted_at IS NOT NULL", id).Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// purgeDeletedBefore permanently deletes rows of model soft-deleted before cutoff, and the
+// rows of dependents referencing them, in one transaction
+func purgeDeletedBefore(db *DB, model interface{}, cutoff time.Time, dependents ...softDeleteDependent) (int64, error) {
+	var purged int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		if err := tx.Unscoped().Model(model).Where("deleted_at < ?", cutoff).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		for _, d := range dependents {
+			if err := tx.Where(d.column+" IN ?", ids).Delete(d.model).Error; err != nil {
+				return err
+			}
+		}
+		result := tx.Unscoped().Where("id IN ?", ids).Delete(model)
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
+}
+
+// FindAllBatched calls fn with every quest in id order, batchSize at a time
+func (r *QuestRepository) FindAllBatched(batchSize int, fn func([]models.Quest) error) error {
+	return findAllBatched(r.db, batchSize, fn)
+}
+
+// UpsertAllByExternalID inserts or updates quests by external_id in one transaction
+func (r *QuestRepository) UpsertAllByExternalID(quests []models.Quest) error {
+	defer r.cache.invalidate()
+	return upsertAllByExternalID(r.db, quests)
+}
+
+// QuestFilterColumns are the quest columns clients may filter on with PostgREST syntax
+var QuestFilterColumns = map[string]string{
+	"external_id": "external_id",
+	"name":        "name",
+	"description": "description",
+	"trader":      "trader",
+	"xp":          "xp",
+}
+
+func (r *QuestRepository) FindFiltered(filters []Filter, offset, limit int) ([]models.Quest, int64, error) {
+	return findFiltered[models.Quest](r.db, filters, offset, limit)
+}
+
+func (r *QuestRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Quest{}).Count(&count).Error
+	return count, err
+}
+
 func (r *QuestRepository) ListAll() ([]models.Quest, error) {
 	var quests []models.Quest
 	err := r.db.Order("id ASC").Find(&quests).Error
@@ -211,61 +495,110 @@ func (r *QuestRepository) ListAll() ([]models.Quest, error) {
 }
 
 func (r *QuestRepository) Update(quest *models.Quest) error {
+	defer r.cache.invalidate()
 	return r.db.Save(quest).Error
 }
 
+// Delete soft-deletes a quest; it can be restored until purged
 func (r *QuestRepository) Delete(id uint) error {
+	defer r.cache.invalidate()
 	return r.db.Delete(&models.Quest{}, id).Error
 }
 
+// Restore undoes the soft delete of the quest with id
+func (r *QuestRepository) Restore(id uint) error {
+	defer r.cache.invalidate()
+	return restoreDeleted(r.db, &models.Quest{}, id)
+}
+
+// PurgeDeleted permanently deletes quests soft-deleted before cutoff and the progress and
+// stats rows referencing them
+func (r *QuestRepository) PurgeDeleted(cutoff time.Time) (int64, error) {
+	defer r.cache.invalidate()
+	return purgeDeletedBefore(r.db, &models.Quest{}, cutoff,
+		softDeleteDependent{&models.UserQuestProgress{}, "quest_id"},
+		softDeleteDependent{&models.QuestCompletionStat{}, "quest_id"},
+	)
+}
+
+// UpsertByExternalID returns ErrManualOverride, leaving the row as it is, for quests edited
+// through the API, recording a conflict when upstream has changed them. It doesn't
+// invalidate cached lookups, as sync upserts row by row; call InvalidateCache once done.
 func (r *QuestRepository) UpsertByExternalID(quest *models.Quest) error {
+	// Unscoped so a soft-deleted row is updated, and restored, rather than duplicated
 	var existing models.Quest
-	err := r.db.Where("external_id = ?", quest.ExternalID).First(&existing).Error
+	err := r.db.Unscoped().Where("external_id = ?", quest.ExternalID).First(&existing).Error
 	if err == gorm.ErrRecordNotFound {
 		return r.db.Create(quest).Error
 	}
 	if err != nil {
 		return err
 	}
+	if existing.ManualOverride {
+		if err := recordSyncConflict(r.db, "quests", existing.ID, existing.ExternalID, &existing, quest, !reflect.DeepEqual(existing.Data, quest.Data)); err != nil {
+			return err
+		}
+		return ErrManualOverride
+	}
 	quest.ID = existing.ID
-	return r.db.Save(quest).Error
+	return r.db.Unscoped().Save(quest).Error
 }
 
 // MissionRepository is deprecated, use QuestRepository instead
 type MissionRepository = QuestRepository
 
 func NewMissionRepository(db *DB) *MissionRepository {
-	return NewQuestRepository(db)
+	return NewQuestRepository(db, nil)
 }
 
 type ItemRepository struct {
-	db *DB
+	db    *DB
+	cache *entityCacheScope
 }
 
-func NewItemRepository(db *DB) *ItemRepository {
-	return &ItemRepository{db: db}
+func NewItemRepository(db *DB, cache *EntityCache) *ItemRepository {
+	return &ItemRepository{db: db, cache: cache.scope("items")}
+}
+
+// InvalidateCache drops the cached detail lookups of every item
+func (r *ItemRepository) InvalidateCache() {
+	r.cache.invalidate()
 }
 
 func (r *ItemRepository) Create(item *models.Item) error {
+	defer r.cache.invalidate()
 	return r.db.Create(item).Error
 }
 
 func (r *ItemRepository) FindByID(id uint) (*models.Item, error) {
-	var item models.Item
-	err := r.db.First(&item, id).Error
-	if err != nil {
-		return nil, err
-	}
-	return &item, nil
+	return findCached(r.cache, idLookup(id), func() (*models.Item, error) {
+		var item models.Item
+		err := r.db.First(&item, id).Error
+		if err != nil {
+			return nil, err
+		}
+		return &item, nil
+	})
 }
 
 func (r *ItemRepository) FindByExternalID(externalID string) (*models.Item, error) {
-	var item models.Item
-	err := r.db.Where("external_id = ?", externalID).First(&item).Error
-	if err != nil {
-		return nil, err
+	return findCached(r.cache, externalIDLookup(externalID), func() (*models.Item, error) {
+		var item models.Item
+		err := r.db.Where("external_id = ?", externalID).First(&item).Error
+		if err != nil {
+			return nil, err
+		}
+		return &item, nil
+	})
+}
+
+func (r *ItemRepository) FindByExternalIDs(externalIDs []string) ([]models.Item, error) {
+	var items []models.Item
+	if len(externalIDs) == 0 {
+		return items, nil
 	}
-	return &item, nil
+	err := r.db.Where("external_id IN ?", externalIDs).Find(&items).Error
+	return items, err
 }
 
 func (r *ItemRepository) FindAll(offset, limit int) ([]models.Item, int64, error) {
@@ -279,6 +612,29 @@ func (r *ItemRepository) FindAll(offset, limit int) ([]models.Item, int64, error
 	return items, count, err
 }
 
+// FindAllBatched calls fn with every item in id order, batchSize at a time
+func (r *ItemRepository) FindAllBatched(batchSize int, fn func([]models.Item) error) error {
+	return findAllBatched(r.db, batchSize, fn)
+}
+
+// UpsertAllByExternalID inserts or updates items by external_id in one transaction
+func (r *ItemRepository) UpsertAllByExternalID(items []models.Item) error {
+	defer r.cache.invalidate()
+	return upsertAllByExternalID(r.db, items)
+}
+
+// ItemFilterColumns are the item columns clients may filter on with PostgREST syntax
+var ItemFilterColumns = map[string]string{
+	"external_id": "external_id",
+	"name":        "name",
+	"description": "description",
+	"type":        "type",
+}
+
+func (r *ItemRepository) FindFiltered(filters []Filter, offset, limit int) ([]models.Item, int64, error) {
+	return findFiltered[models.Item](r.db, filters, offset, limit)
+}
+
 func (r *ItemRepository) ListAll() ([]models.Item, error) {
 	var items []models.Item
 	err := r.db.Order("id ASC").Find(&items).Error
@@ -286,54 +642,105 @@ func (r *ItemRepository) ListAll() ([]models.Item, error) {
 }
 
 func (r *ItemRepository) Update(item *models.Item) error {
+	defer r.cache.invalidate()
 	return r.db.Save(item).Error
 }
 
+// UpdateImageURL points an item at another image without touching its other columns
+func (r *ItemRepository) UpdateImageURL(id uint, imageURL string) error {
+	defer r.cache.invalidate()
+	return r.db.Model(&models.Item{}).Where("id = ?", id).Update("image_url", imageURL).Error
+}
+
+func (r *ItemRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Item{}).Count(&count).Error
+	return count, err
+}
+
+// Delete soft-deletes an item; it can be restored until purged
 func (r *ItemRepository) Delete(id uint) error {
+	defer r.cache.invalidate()
 	return r.db.Delete(&models.Item{}, id).Error
 }
 
+// Restore undoes the soft delete of the item with id
+func (r *ItemRepository) Restore(id uint) error {
+	defer r.cache.invalidate()
+	return restoreDeleted(r.db, &models.Item{}, id)
+}
+
+// PurgeDeleted permanently deletes items soft-deleted before cutoff and the progress rows referencing them
+func (r *ItemRepository) PurgeDeleted(cutoff time.Time) (int64, error) {
+	defer r.cache.invalidate()
+	return purgeDeletedBefore(r.db, &models.Item{}, cutoff,
+		softDeleteDependent{&models.UserBlueprintProgress{}, "item_id"},
+		softDeleteDependent{&models.UserInventoryItem{}, "item_id"},
+	)
+}
+
+// UpsertByExternalID returns ErrManualOverride, leaving the row as it is, for items edited
+// through the API, recording a conflict when upstream has changed them. It doesn't
+// invalidate cached lookups, as sync upserts row by row; call InvalidateCache once done.
 func (r *ItemRepository) UpsertByExternalID(item *models.Item) error {
+	// Unscoped so a soft-deleted row is updated, and restored, rather than duplicated
 	var existing models.Item
-	err := r.db.Where("external_id = ?", item.ExternalID).First(&existing).Error
+	err := r.db.Unscoped().Where("external_id = ?", item.ExternalID).First(&existing).Error
 	if err == gorm.ErrRecordNotFound {
 		return r.db.Create(item).Error
 	}
 	if err != nil {
 		return err
 	}
+	if existing.ManualOverride {
+		if err := recordSyncConflict(r.db, "items", existing.ID, existing.ExternalID, &existing, item, !reflect.DeepEqual(existing.Data, item.Data)); err != nil {
+			return err
+		}
+		return ErrManualOverride
+	}
 	item.ID = existing.ID
-	return r.db.Save(item).Error
+	return r.db.Unscoped().Save(item).Error
 }
 
 type SkillNodeRepository struct {
-	db *DB
+	db    *DB
+	cache *entityCacheScope
+}
+
+func NewSkillNodeRepository(db *DB, cache *EntityCache) *SkillNodeRepository {
+	return &SkillNodeRepository{db: db, cache: cache.scope("skill_nodes")}
 }
 
-func NewSkillNodeRepository(db *DB) *SkillNodeRepository {
-	return &SkillNodeRepository{db: db}
+// InvalidateCache drops the cached detail lookups of every skill node
+func (r *SkillNodeRepository) InvalidateCache() {
+	r.cache.invalidate()
 }
 
 func (r *SkillNodeRepository) Create(skillNode *models.SkillNode) error {
+	defer r.cache.invalidate()
 	return r.db.Create(skillNode).Error
 }
 
 func (r *SkillNodeRepository) FindByID(id uint) (*models.SkillNode, error) {
-	var skillNode models.SkillNode
-	err := r.db.First(&skillNode, id).Error
-	if err != nil {
-		return nil, err
-	}
-	return &skillNode, nil
+	return findCached(r.cache, idLookup(id), func() (*models.SkillNode, error) {
+		var skillNode models.SkillNode
+		err := r.db.First(&skillNode, id).Error
+		if err != nil {
+			return nil, err
+		}
+		return &skillNode, nil
+	})
 }
 
 func (r *SkillNodeRepository) FindByExternalID(externalID string) (*models.SkillNode, error) {
-	var skillNode models.SkillNode
-	err := r.db.Where("external_id = ?", externalID).First(&skillNode).Error
-	if err != nil {
-		return nil, err
-	}
-	return &skillNode, nil
+	return findCached(r.cache, externalIDLookup(externalID), func() (*models.SkillNode, error) {
+		var skillNode models.SkillNode
+		err := r.db.Where("external_id = ?", externalID).First(&skillNode).Error
+		if err != nil {
+			return nil, err
+		}
+		return &skillNode, nil
+	})
 }
 
 func (r *SkillNodeRepository) FindAll(offset, limit int) ([]models.SkillNode, int64, error) {
@@ -347,6 +754,31 @@ func (r *SkillNodeRepository) FindAll(offset, limit int) ([]models.SkillNode, in
 	return skillNodes, count, err
 }
 
+// FindAllBatched calls fn with every skill node in id order, batchSize at a time
+func (r *SkillNodeRepository) FindAllBatched(batchSize int, fn func([]models.SkillNode) error) error {
+	return findAllBatched(r.db, batchSize, fn)
+}
+
+// UpsertAllByExternalID inserts or updates skill nodes by external_id in one transaction
+func (r *SkillNodeRepository) UpsertAllByExternalID(skillNodes []models.SkillNode) error {
+	defer r.cache.invalidate()
+	return upsertAllByExternalID(r.db, skillNodes)
+}
+
+// SkillNodeFilterColumns are the skill node columns clients may filter on with PostgREST syntax
+var SkillNodeFilterColumns = map[string]string{
+	"external_id":    "external_id",
+	"name":           "name",
+	"impacted_skill": "impacted_skill",
+	"category":       "category",
+	"max_points":     "max_points",
+	"is_major":       "is_major",
+}
+
+func (r *SkillNodeRepository) FindFiltered(filters []Filter, offset, limit int) ([]models.SkillNode, int64, error) {
+	return findFiltered[models.SkillNode](r.db, filters, offset, limit)
+}
+
 func (r *SkillNodeRepository) ListAll() ([]models.SkillNode, error) {
 	var skillNodes []models.SkillNode
 	err := r.db.Order("id ASC").Find(&skillNodes).Error
@@ -354,54 +786,92 @@ func (r *SkillNodeRepository) ListAll() ([]models.SkillNode, error) {
 }
 
 func (r *SkillNodeRepository) Update(skillNode *models.SkillNode) error {
+	defer r.cache.invalidate()
 	return r.db.Save(skillNode).Error
 }
 
+// Delete soft-deletes a skill node; it can be restored until purged
 func (r *SkillNodeRepository) Delete(id uint) error {
+	defer r.cache.invalidate()
 	return r.db.Delete(&models.SkillNode{}, id).Error
 }
 
+// Restore undoes the soft delete of the skill node with id
+func (r *SkillNodeRepository) Restore(id uint) error {
+	defer r.cache.invalidate()
+	return restoreDeleted(r.db, &models.SkillNode{}, id)
+}
+
+// PurgeDeleted permanently deletes skill nodes soft-deleted before cutoff and the progress rows referencing them
+func (r *SkillNodeRepository) PurgeDeleted(cutoff time.Time) (int64, error) {
+	defer r.cache.invalidate()
+	return purgeDeletedBefore(r.db, &models.SkillNode{}, cutoff,
+		softDeleteDependent{&models.UserSkillNodeProgress{}, "skill_node_id"},
+	)
+}
+
+// UpsertByExternalID returns ErrManualOverride, leaving the row as it is, for skill nodes edited
+// through the API, recording a conflict when upstream has changed them. It doesn't
+// invalidate cached lookups, as sync upserts row by row; call InvalidateCache once done.
 func (r *SkillNodeRepository) UpsertByExternalID(skillNode *models.SkillNode) error {
+	// Unscoped so a soft-deleted row is updated, and restored, rather than duplicated
 	var existing models.SkillNode
-	err := r.db.Where("external_id = ?", skillNode.ExternalID).First(&existing).Error
+	err := r.db.Unscoped().Where("external_id = ?", skillNode.ExternalID).First(&existing).Error
 	if err == gorm.ErrRecordNotFound {
 		return r.db.Create(skillNode).Error
 	}
 	if err != nil {
 		return err
 	}
+	if existing.ManualOverride {
+		if err := recordSyncConflict(r.db, "skill_nodes", existing.ID, existing.ExternalID, &existing, skillNode, !reflect.DeepEqual(existing.Data, skillNode.Data)); err != nil {
+			return err
+		}
+		return ErrManualOverride
+	}
 	skillNode.ID = existing.ID
-	return r.db.Save(skillNode).Error
+	return r.db.Unscoped().Save(skillNode).Error
 }
 
 type HideoutModuleRepository struct {
-	db *DB
+	db    *DB
+	cache *entityCacheScope
+}
+
+func NewHideoutModuleRepository(db *DB, cache *EntityCache) *HideoutModuleRepository {
+	return &HideoutModuleRepository{db: db, cache: cache.scope("hideout_modules")}
 }
 
-func NewHideoutModuleRepository(db *DB) *HideoutModuleRepository {
-	return &HideoutModuleRepository{db: db}
+// InvalidateCache drops the cached detail lookups of every hideout module
+func (r *HideoutModuleRepository) InvalidateCache() {
+	r.cache.invalidate()
 }
 
 func (r *HideoutModuleRepository) Create(hideoutModule *models.HideoutModule) error {
+	defer r.cache.invalidate()
 	return r.db.Create(hideoutModule).Error
 }
 
 func (r *HideoutModuleRepository) FindByID(id uint) (*models.HideoutModule, error) {
-	var hideoutModule models.HideoutModule
-	err := r.db.First(&hideoutModule, id).Error
-	if err != nil {
-		return nil, err
-	}
-	return &hideoutModule, nil
+	return findCached(r.cache, idLookup(id), func() (*models.HideoutModule, error) {
+		var hideoutModule models.HideoutModule
+		err := r.db.First(&hideoutModule, id).Error
+		if err != nil {
+			return nil, err
+		}
+		return &hideoutModule, nil
+	})
 }
 
 func (r *HideoutModuleRepository) FindByExternalID(externalID string) (*models.HideoutModule, error) {
-	var hideoutModule models.HideoutModule
-	err := r.db.Where("external_id = ?", externalID).First(&hideoutModule).Error
-	if err != nil {
-		return nil, err
-	}
-	return &hideoutModule, nil
+	return findCached(r.cache, externalIDLookup(externalID), func() (*models.HideoutModule, error) {
+		var hideoutModule models.HideoutModule
+		err := r.db.Where("external_id = ?", externalID).First(&hideoutModule).Error
+		if err != nil {
+			return nil, err
+		}
+		return &hideoutModule, nil
+	})
 }
 
 func (r *HideoutModuleRepository) FindAll(offset, limit int) ([]models.HideoutModule, int64, error) {
@@ -414,6 +884,7 @@ func (r *HideoutModuleRepository) FindAll(offset, limit int) ([]models.HideoutMo
 		SELECT DISTINCT ON (external_id) 
 			id, external_id, name, description, max_level, levels, data, synced_at, created_at, updated_at
 		FROM hideout_modules
+		WHERE deleted_at IS NULL
 		ORDER BY external_id, id ASC
 		OFFSET ? LIMIT ?
 	`, offset, limit).Scan(&hideoutModules).Error
@@ -423,7 +894,7 @@ func (r *HideoutModuleRepository) FindAll(offset, limit int) ([]models.HideoutMo
 
 	// Count unique external_ids
 	var count int64
-	err = r.db.Raw(`SELECT COUNT(DISTINCT external_id) FROM hideout_modules`).Scan(&count).Error
+	err = r.db.Raw(`SELECT COUNT(DISTINCT external_id) FROM hideout_modules WHERE deleted_at IS NULL`).Scan(&count).Error
 	if err != nil {
 		return nil, 0, err
 	}
@@ -431,36 +902,75 @@ func (r *HideoutModuleRepository) FindAll(offset, limit int) ([]models.HideoutMo
 	return hideoutModules, count, nil
 }
 
+// FindAllBatched calls fn with every hideout module in id order, batchSize at a time
+func (r *HideoutModuleRepository) FindAllBatched(batchSize int, fn func([]models.HideoutModule) error) error {
+	return findAllBatched(r.db, batchSize, fn)
+}
+
+// UpsertAllByExternalID inserts or updates hideout modules by external_id in one transaction
+func (r *HideoutModuleRepository) UpsertAllByExternalID(hideoutModules []models.HideoutModule) error {
+	defer r.cache.invalidate()
+	return upsertAllByExternalID(r.db, hideoutModules)
+}
+
 func (r *HideoutModuleRepository) ListAll() ([]models.HideoutModule, error) {
 	var hideoutModules []models.HideoutModule
 	err := r.db.Raw(`
 		SELECT DISTINCT ON (external_id) 
 			id, external_id, name, description, max_level, levels, data, synced_at, created_at, updated_at
 		FROM hideout_modules
+		WHERE deleted_at IS NULL
 		ORDER BY external_id, id ASC
 	`).Scan(&hideoutModules).Error
 	return hideoutModules, err
 }
 
 func (r *HideoutModuleRepository) Update(hideoutModule *models.HideoutModule) error {
+	defer r.cache.invalidate()
 	return r.db.Save(hideoutModule).Error
 }
 
+// Delete soft-deletes a hideout module; it can be restored until purged
 func (r *HideoutModuleRepository) Delete(id uint) error {
+	defer r.cache.invalidate()
 	return r.db.Delete(&models.HideoutModule{}, id).Error
 }
 
+// Restore undoes the soft delete of the hideout module with id
+func (r *HideoutModuleRepository) Restore(id uint) error {
+	defer r.cache.invalidate()
+	return restoreDeleted(r.db, &models.HideoutModule{}, id)
+}
+
+// PurgeDeleted permanently deletes hideout modules soft-deleted before cutoff and the progress rows referencing them
+func (r *HideoutModuleRepository) PurgeDeleted(cutoff time.Time) (int64, error) {
+	defer r.cache.invalidate()
+	return purgeDeletedBefore(r.db, &models.HideoutModule{}, cutoff,
+		softDeleteDependent{&models.UserHideoutModuleProgress{}, "hideout_module_id"},
+	)
+}
+
+// UpsertByExternalID returns ErrManualOverride, leaving the row as it is, for hideout modules edited
+// through the API, recording a conflict when upstream has changed them. It doesn't
+// invalidate cached lookups, as sync upserts row by row; call InvalidateCache once done.
 func (r *HideoutModuleRepository) UpsertByExternalID(hideoutModule *models.HideoutModule) error {
+	// Unscoped so a soft-deleted row is updated, and restored, rather than duplicated
 	var existing models.HideoutModule
-	err := r.db.Where("external_id = ?", hideoutModule.ExternalID).First(&existing).Error
+	err := r.db.Unscoped().Where("external_id = ?", hideoutModule.ExternalID).First(&existing).Error
 	if err == gorm.ErrRecordNotFound {
 		return r.db.Create(hideoutModule).Error
 	}
 	if err != nil {
 		return err
 	}
+	if existing.ManualOverride {
+		if err := recordSyncConflict(r.db, "hideout_modules", existing.ID, existing.ExternalID, &existing, hideoutModule, !reflect.DeepEqual(existing.Data, hideoutModule.Data)); err != nil {
+			return err
+		}
+		return ErrManualOverride
+	}
 	hideoutModule.ID = existing.ID
-	return r.db.Save(hideoutModule).Error
+	return r.db.Unscoped().Save(hideoutModule).Error
 }
 
 type EnemyTypeRepository struct {
@@ -504,6 +1014,27 @@ func (r *EnemyTypeRepository) FindAll(offset, limit int) ([]models.EnemyType, in
 	return enemyTypes, count, err
 }
 
+// FindAllBatched calls fn with every enemy type in id order, batchSize at a time
+func (r *EnemyTypeRepository) FindAllBatched(batchSize int, fn func([]models.EnemyType) error) error {
+	return findAllBatched(r.db, batchSize, fn)
+}
+
+// UpsertAllByExternalID inserts or updates enemy types by external_id in one transaction
+func (r *EnemyTypeRepository) UpsertAllByExternalID(enemyTypes []models.EnemyType) error {
+	return upsertAllByExternalID(r.db, enemyTypes)
+}
+
+// EnemyTypeFilterColumns are the enemy type columns clients may filter on with PostgREST syntax
+var EnemyTypeFilterColumns = map[string]string{
+	"external_id": "external_id",
+	"name":        "name",
+	"type":        "type",
+}
+
+func (r *EnemyTypeRepository) FindFiltered(filters []Filter, offset, limit int) ([]models.EnemyType, int64, error) {
+	return findFiltered[models.EnemyType](r.db, filters, offset, limit)
+}
+
 func (r *EnemyTypeRepository) ListAll() ([]models.EnemyType, error) {
 	var enemyTypes []models.EnemyType
 	err := r.db.Order("id ASC").Find(&enemyTypes).Error
@@ -514,10 +1045,26 @@ func (r *EnemyTypeRepository) Update(enemyType *models.EnemyType) error {
 	return r.db.Save(enemyType).Error
 }
 
+// UpdateImageURL points an enemy type at another image without touching its other columns
+func (r *EnemyTypeRepository) UpdateImageURL(id uint, imageURL string) error {
+	return r.db.Model(&models.EnemyType{}).Where("id = ?", id).Update("image_url", imageURL).Error
+}
+
+// Delete soft-deletes an enemy type; it can be restored until purged
 func (r *EnemyTypeRepository) Delete(id uint) error {
 	return r.db.Delete(&models.EnemyType{}, id).Error
 }
 
+// Restore undoes the soft delete of the enemy type with id
+func (r *EnemyTypeRepository) Restore(id uint) error {
+	return restoreDeleted(r.db, &models.EnemyType{}, id)
+}
+
+// PurgeDeleted permanently deletes enemy types soft-deleted before cutoff
+func (r *EnemyTypeRepository) PurgeDeleted(cutoff time.Time) (int64, error) {
+	return purgeDeletedBefore(r.db, &models.EnemyType{}, cutoff)
+}
+
 type AlertRepository struct {
 	db *DB
 }
@@ -550,6 +1097,11 @@ func (r *AlertRepository) FindAll(offset, limit int) ([]models.Alert, int64, err
 	return alerts, count, err
 }
 
+// FindAllBatched calls fn with every alert in id order, batchSize at a time
+func (r *AlertRepository) FindAllBatched(batchSize int, fn func([]models.Alert) error) error {
+	return findAllBatched(r.db, batchSize, fn)
+}
+
 func (r *AlertRepository) ListAll() ([]models.Alert, error) {
 	var alerts []models.Alert
 	err := r.db.Order("id ASC").Find(&alerts).Error
@@ -562,6 +1114,22 @@ func (r *AlertRepository) FindActive() ([]models.Alert, error) {
 	return alerts, err
 }
 
+// FindIncidents pages through alerts that track an incident, newest first, optionally
+// only those with status
+func (r *AlertRepository) FindIncidents(status string, offset, limit int) ([]models.Alert, int64, error) {
+	query := r.db.Model(&models.Alert{}).Where("incident_status <> ''")
+	if status != "" {
+		query = query.Where("incident_status = ?", status)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	var alerts []models.Alert
+	err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&alerts).Error
+	return alerts, count, err
+}
+
 func (r *AlertRepository) Update(alert *models.Alert) error {
 	return r.db.Save(alert).Error
 }
@@ -570,288 +1138,2246 @@ func (r *AlertRepository) Delete(id uint) error {
 	return r.db.Delete(&models.Alert{}, id).Error
 }
 
-type AuditLogRepository struct {
+type GameEventRepository struct {
 	db *DB
 }
 
-func NewAuditLogRepository(db *DB) *AuditLogRepository {
-	return &AuditLogRepository{db: db}
+func NewGameEventRepository(db *DB) *GameEventRepository {
+	return &GameEventRepository{db: db}
 }
 
-func (r *AuditLogRepository) Create(log *models.AuditLog) error {
-	return r.db.Create(log).Error
+func (r *GameEventRepository) Create(event *models.GameEvent) error {
+	return r.db.Create(event).Error
 }
 
-func (r *AuditLogRepository) FindByFilters(apiKeyID, jwtTokenID, userID *uint, endpoint, method *string, startTime, endTime *string, offset, limit int) ([]models.AuditLog, int64, error) {
-	query := r.db.Model(&models.AuditLog{})
-
-	if apiKeyID != nil {
-		query = query.Where("api_key_id = ?", *apiKeyID)
-	}
-	if jwtTokenID != nil {
-		query = query.Where("jwt_token_id = ?", *jwtTokenID)
-	}
-	if userID != nil {
-		query = query.Where("user_id = ?", *userID)
-	}
-	if endpoint != nil {
-		query = query.Where("endpoint = ?", *endpoint)
+func (r *GameEventRepository) FindByID(id uint) (*models.GameEvent, error) {
+	var event models.GameEvent
+	err := r.db.First(&event, id).Error
+	if err != nil {
+		return nil, err
 	}
-	if method != nil {
-		query = query.Where("method = ?", *method)
+	return &event, nil
+}
+
+// FindBetween returns the events that may run between from and to, by start time. A zero to
+// leaves the range open. A mapID also matches events on every map; callers expand
+// recurring events to find their occurrences.
+func (r *GameEventRepository) FindBetween(from, to time.Time, mapID, mode string) ([]models.GameEvent, error) {
+	query := r.db.Where("(recurrence = '' AND ends_at > ?) OR (recurrence <> '' AND (recurrence_until IS NULL OR recurrence_until + (ends_at - starts_at) > ?))", from, from)
+	if !to.IsZero() {
+		query = query.Where("starts_at < ?", to)
 	}
-	if startTime != nil {
-		query = query.Where("created_at >= ?", *startTime)
+	if mapID != "" {
+		query = query.Where("map_id = ? OR map_id = ''", mapID)
 	}
-	if endTime != nil {
-		query = query.Where("created_at <= ?", *endTime)
+	if mode != "" {
+		query = query.Where("mode = ?", mode)
 	}
+	var events []models.GameEvent
+	err := query.Order("starts_at ASC, id ASC").Find(&events).Error
+	return events, err
+}
 
-	var count int64
-	err := query.Count(&count).Error
-	if err != nil {
-		return nil, 0, err
-	}
+func (r *GameEventRepository) Update(event *models.GameEvent) error {
+	return r.db.Save(event).Error
+}
 
-	var logs []models.AuditLog
-	err = query.Preload("APIKey").Preload("JWTToken").Preload("User").
-		Order("created_at DESC").Offset(offset).Limit(limit).Find(&logs).Error
-	return logs, count, err
+func (r *GameEventRepository) Delete(id uint) error {
+	return r.db.Delete(&models.GameEvent{}, id).Error
 }
 
-// UserQuestProgressRepository handles user quest progress
-type UserQuestProgressRepository struct {
+type RoleRepository struct {
 	db *DB
 }
 
-func NewUserQuestProgressRepository(db *DB) *UserQuestProgressRepository {
-	return &UserQuestProgressRepository{db: db}
+func NewRoleRepository(db *DB) *RoleRepository {
+	return &RoleRepository{db: db}
 }
 
-func (r *UserQuestProgressRepository) Upsert(userID, questID uint, completed bool) (*models.UserQuestProgress, error) {
-	var progress models.UserQuestProgress
-	err := r.db.Where("user_id = ? AND quest_id = ?", userID, questID).First(&progress).Error
+func (r *RoleRepository) Create(role *models.Role) error {
+	return r.db.Create(role).Error
+}
 
-	if err == gorm.ErrRecordNotFound {
-		// Create new
-		progress = models.UserQuestProgress{
-			UserID:    userID,
-			QuestID:   questID,
-			Completed: completed,
-		}
-		err = r.db.Create(&progress).Error
-		return &progress, err
-	} else if err != nil {
+func (r *RoleRepository) FindByName(name string) (*models.Role, error) {
+	var role models.Role
+	err := r.db.Where("name = ?", name).First(&role).Error
+	if err != nil {
 		return nil, err
 	}
+	return &role, nil
+}
+
+func (r *RoleRepository) ListAll() ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.Order("id ASC").Find(&roles).Error
+	return roles, err
+}
+
+func (r *RoleRepository) Update(role *models.Role) error {
+	return r.db.Save(role).Error
+}
+
+func (r *RoleRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Role{}, id).Error
+}
+
+// EnsureDefaults creates any missing built-in roles without touching existing ones
+func (r *RoleRepository) EnsureDefaults() error {
+	for _, role := range models.DefaultRoles() {
+		role := role
+		err := r.db.Where("name = ?", role.Name).FirstOrCreate(&role).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DailyCount is the number of rows created on one UTC day
+type DailyCount struct {
+	Day   string `json:"day" example:"2026-10-15"`
+	Count int64  `json:"count" example:"12"`
+}
+
+// Count counts all users
+func (r *UserRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Count(&count).Error
+	return count, err
+}
+
+// CountCreatedPerDay counts the users created on each UTC day since since, oldest first.
+// Days without new users are left out.
+func (r *UserRepository) CountCreatedPerDay(since time.Time) ([]DailyCount, error) {
+	var counts []DailyCount
+	err := r.db.Model(&models.User{}).
+		Select("to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day, COUNT(*) AS count").
+		Where("created_at >= ?", since).
+		Group("day").Order("day ASC").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// FindIDsByRole returns the IDs of the users with role
+func (r *UserRepository) FindIDsByRole(role models.UserRole) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.User{}).Where("role = ?", role).Order("id ASC").Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *UserRepository) CountByRole(role string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Where("role = ?", role).Count(&count).Error
+	return count, err
+}
+
+type TeamRepository struct {
+	db *DB
+}
+
+func NewTeamRepository(db *DB) *TeamRepository {
+	return &TeamRepository{db: db}
+}
+
+// Create stores a team and adds its owner as the first member in one transaction
+func (r *TeamRepository) Create(team *models.Team) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(team).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.TeamMember{
+			TeamID: team.ID,
+			UserID: team.OwnerID,
+			Role:   models.TeamRoleOwner,
+		}).Error
+	})
+}
+
+func (r *TeamRepository) FindByID(id uint) (*models.Team, error) {
+	var team models.Team
+	err := r.db.Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("team_members.id ASC")
+	}).Preload("Members.User").First(&team, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (r *TeamRepository) FindByInviteCode(code string) (*models.Team, error) {
+	var team models.Team
+	err := r.db.Where("invite_code = ?", code).First(&team).Error
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (r *TeamRepository) FindByUserID(userID uint) ([]models.Team, error) {
+	var teams []models.Team
+	err := r.db.Joins("JOIN team_members ON team_members.team_id = teams.id").
+		Where("team_members.user_id = ?", userID).
+		Order("teams.id ASC").
+		Find(&teams).Error
+	return teams, err
+}
+
+func (r *TeamRepository) FindMember(teamID, userID uint) (*models.TeamMember, error) {
+	var member models.TeamMember
+	err := r.db.Where("team_id = ? AND user_id = ?", teamID, userID).First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+var (
+	ErrAlreadyTeamMember = errors.New("already a member of this team")
+	ErrTeamFull          = errors.New("team is full")
+)
+
+// Join adds the user to the team unless they're already a member or it has maxMembers.
+// The team row is locked until the member is inserted, so concurrent joins can't overfill it.
+func (r *TeamRepository) Join(teamID, userID uint, maxMembers int64) (*models.TeamMember, error) {
+	member := &models.TeamMember{TeamID: teamID, UserID: userID, Role: models.TeamRoleMember}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var team models.Team
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&team, teamID).Error; err != nil {
+			return err
+		}
+
+		var existing int64
+		if err := tx.Model(&models.TeamMember{}).Where("team_id = ? AND user_id = ?", teamID, userID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrAlreadyTeamMember
+		}
+
+		var count int64
+		if err := tx.Model(&models.TeamMember{}).Where("team_id = ?", teamID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= maxMembers {
+			return ErrTeamFull
+		}
+
+		return tx.Create(member).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+func (r *TeamRepository) RemoveMember(teamID, userID uint) error {
+	return r.db.Where("team_id = ? AND user_id = ?", teamID, userID).Delete(&models.TeamMember{}).Error
+}
+
+// Delete removes a team and all of its memberships
+func (r *TeamRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("team_id = ?", id).Delete(&models.TeamMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Team{}, id).Error
+	})
+}
+
+type StatsRepository struct {
+	db *DB
+}
+
+func NewStatsRepository(db *DB) *StatsRepository {
+	return &StatsRepository{db: db}
+}
+
+// RefreshLeaderboard rebuilds leaderboard_entries from the progress tables in one
+// transaction, so readers never see a half-built leaderboard. Only users who opted in
+// are ranked. Returns the row count.
+func (r *StatsRepository) RefreshLeaderboard(refreshedAt time.Time) (int64, error) {
+	var rows int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM leaderboard_entries").Error; err != nil {
+			return err
+		}
+		result := tx.Exec(`
+			INSERT INTO leaderboard_entries
+				(user_id, username, quests_completed, hideout_levels, skill_levels, blueprints_consumed, score,
+				 rank, quests_rank, hideout_rank, refreshed_at)
+			SELECT user_id, display_name, quests, hideout, skills, blueprints, score,
+				RANK() OVER (ORDER BY score DESC),
+				RANK() OVER (ORDER BY quests DESC),
+				RANK() OVER (ORDER BY hideout DESC),
+				?
+			FROM (
+				SELECT u.id AS user_id,
+					COALESCE(NULLIF(u.leaderboard_name, ''), u.username) AS display_name,
+					COALESCE(q.n, 0) AS quests,
+					COALESCE(h.n, 0) AS hideout,
+					COALESCE(s.n, 0) AS skills,
+					COALESCE(b.n, 0) AS blueprints,
+					COALESCE(q.n, 0) + COALESCE(h.n, 0) + COALESCE(s.n, 0) + COALESCE(b.n, 0) AS score
+				FROM users u
+				LEFT JOIN (SELECT user_id, COUNT(*) AS n FROM user_quest_progress WHERE completed GROUP BY user_id) q ON q.user_id = u.id
+				LEFT JOIN (SELECT user_id, SUM(level) AS n FROM user_hideout_module_progress WHERE unlocked GROUP BY user_id) h ON h.user_id = u.id
+				LEFT JOIN (SELECT user_id, SUM(level) AS n FROM user_skill_node_progress WHERE unlocked GROUP BY user_id) s ON s.user_id = u.id
+				LEFT JOIN (SELECT user_id, COUNT(*) AS n FROM user_blueprint_progress WHERE consumed GROUP BY user_id) b ON b.user_id = u.id
+				WHERE u.leaderboard_opt_in
+			) totals
+			WHERE score > 0`, refreshedAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		rows = result.RowsAffected
+		return nil
+	})
+	return rows, err
+}
+
+// RefreshQuestStats rebuilds quest_completion_stats from user_quest_progress
+func (r *StatsRepository) RefreshQuestStats(refreshedAt time.Time) (int64, error) {
+	var rows int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM quest_completion_stats").Error; err != nil {
+			return err
+		}
+		result := tx.Exec(`
+			INSERT INTO quest_completion_stats (quest_id, quest_external_id, completions, refreshed_at)
+			SELECT q.id, q.external_id, COUNT(p.id), ?
+			FROM quests q
+			LEFT JOIN user_quest_progress p ON p.quest_id = q.id AND p.completed
+			WHERE q.deleted_at IS NULL
+			GROUP BY q.id, q.external_id`, refreshedAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		rows = result.RowsAffected
+		return nil
+	})
+	return rows, err
+}
+
+// FindLeaderboard returns a page of entries ordered by rankColumn (one of rank, quests_rank, hideout_rank)
+func (r *StatsRepository) FindLeaderboard(rankColumn string, offset, limit int) ([]models.LeaderboardEntry, int64, error) {
+	var entries []models.LeaderboardEntry
+	var count int64
+	err := r.db.Model(&models.LeaderboardEntry{}).Count(&count).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	err = r.db.Order(rankColumn + " ASC, user_id ASC").Offset(offset).Limit(limit).Find(&entries).Error
+	return entries, count, err
+}
+
+func (r *StatsRepository) FindLeaderboardEntry(userID uint) (*models.LeaderboardEntry, error) {
+	var entry models.LeaderboardEntry
+	err := r.db.Where("user_id = ?", userID).First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// DeleteLeaderboardEntry removes a user from the leaderboards until the next refresh
+func (r *StatsRepository) DeleteLeaderboardEntry(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&models.LeaderboardEntry{}).Error
+}
+
+func (r *StatsRepository) ListQuestStats() ([]models.QuestCompletionStat, error) {
+	var stats []models.QuestCompletionStat
+	err := r.db.Order("completions DESC, quest_id ASC").Find(&stats).Error
+	return stats, err
+}
+
+type ItemAliasRepository struct {
+	db *DB
+}
+
+func NewItemAliasRepository(db *DB) *ItemAliasRepository {
+	return &ItemAliasRepository{db: db}
+}
+
+func (r *ItemAliasRepository) Create(alias *models.ItemAlias) error {
+	return r.db.Create(alias).Error
+}
+
+func (r *ItemAliasRepository) FindByID(id uint) (*models.ItemAlias, error) {
+	var alias models.ItemAlias
+	err := r.db.First(&alias, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &alias, nil
+}
+
+func (r *ItemAliasRepository) FindByNormalizedName(normalized string) (*models.ItemAlias, error) {
+	var alias models.ItemAlias
+	err := r.db.Where("normalized_name = ?", normalized).First(&alias).Error
+	if err != nil {
+		return nil, err
+	}
+	return &alias, nil
+}
+
+func (r *ItemAliasRepository) ListAll() ([]models.ItemAlias, error) {
+	var aliases []models.ItemAlias
+	err := r.db.Order("item_external_id ASC, id ASC").Find(&aliases).Error
+	return aliases, err
+}
+
+func (r *ItemAliasRepository) Delete(id uint) error {
+	return r.db.Delete(&models.ItemAlias{}, id).Error
+}
+
+type RecipeRepository struct {
+	db *DB
+}
+
+func NewRecipeRepository(db *DB) *RecipeRepository {
+	return &RecipeRepository{db: db}
+}
+
+func (r *RecipeRepository) FindByItemExternalID(itemExternalID string) (*models.Recipe, error) {
+	var recipe models.Recipe
+	err := r.db.Where("item_external_id = ?", itemExternalID).First(&recipe).Error
+	if err != nil {
+		return nil, err
+	}
+	return &recipe, nil
+}
+
+func (r *RecipeRepository) ListAll() ([]models.Recipe, error) {
+	var recipes []models.Recipe
+	err := r.db.Order("item_external_id ASC").Find(&recipes).Error
+	return recipes, err
+}
+
+// ReplaceAll swaps the full recipe set in one transaction so recipes removed upstream don't linger
+func (r *RecipeRepository) ReplaceAll(recipes []models.Recipe) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.Recipe{}).Error; err != nil {
+			return err
+		}
+		if len(recipes) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(recipes, 200).Error
+	})
+}
+
+type ItemStatRepository struct {
+	db *DB
+}
+
+func NewItemStatRepository(db *DB) *ItemStatRepository {
+	return &ItemStatRepository{db: db}
+}
+
+func (r *ItemStatRepository) FindByItemExternalIDs(itemExternalIDs []string) ([]models.ItemStat, error) {
+	var stats []models.ItemStat
+	err := r.db.Where("item_external_id IN ?", itemExternalIDs).Order("stat ASC").Find(&stats).Error
+	return stats, err
+}
+
+// ReplaceAll swaps the full stat set in one transaction, like RecipeRepository.ReplaceAll
+func (r *ItemStatRepository) ReplaceAll(stats []models.ItemStat) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.ItemStat{}).Error; err != nil {
+			return err
+		}
+		if len(stats) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(stats, 500).Error
+	})
+}
+
+type TranslationRepository struct {
+	db *DB
+}
+
+func NewTranslationRepository(db *DB) *TranslationRepository {
+	return &TranslationRepository{db: db}
+}
+
+// ReplaceForEntityType swaps all translations of one entity type in one transaction
+func (r *TranslationRepository) ReplaceForEntityType(entityType string, translations []models.Translation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("entity_type = ?", entityType).Delete(&models.Translation{}).Error; err != nil {
+			return err
+		}
+		if len(translations) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(translations, 500).Error
+	})
+}
+
+// TranslationQuery selects translations of one entity type in the given languages.
+// Field and EntityIDs are optional; Search matches the text case-insensitively.
+type TranslationQuery struct {
+	EntityType string
+	Langs      []string
+	Field      string
+	EntityIDs  []string
+	Search     string
+}
+
+func (r *TranslationRepository) Find(q TranslationQuery) ([]models.Translation, error) {
+	var translations []models.Translation
+	query := r.db.Where("entity_type = ? AND lang IN ?", q.EntityType, q.Langs)
+	if q.Field != "" {
+		query = query.Where("field = ?", q.Field)
+	}
+	if len(q.EntityIDs) > 0 {
+		query = query.Where("entity_id IN ?", q.EntityIDs)
+	}
+	if q.Search != "" {
+		query = query.Where("text ILIKE ?", "%"+escapeLike(q.Search)+"%")
+	}
+	err := query.Order("entity_id ASC, field ASC").Find(&translations).Error
+	return translations, err
+}
+
+// ReplaceSearchDocuments swaps all search documents of one entity type in one transaction
+func (r *TranslationRepository) ReplaceSearchDocuments(entityType string, documents []models.SearchDocument) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("entity_type = ?", entityType).Delete(&models.SearchDocument{}).Error; err != nil {
+			return err
+		}
+		if len(documents) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(documents, 500).Error
+	})
+}
+
+// SearchQuery selects the search documents of one language containing Text. EntityTypes
+// is optional.
+type SearchQuery struct {
+	Lang        string
+	Text        string
+	EntityTypes []string
+}
+
+func (q SearchQuery) apply(db *gorm.DB, column string) *gorm.DB {
+	query := db.Model(&models.SearchDocument{}).Where("lang = ?", q.Lang)
+	if len(q.EntityTypes) > 0 {
+		query = query.Where("entity_type IN ?", q.EntityTypes)
+	}
+	return query.Where(column+" ILIKE ?", "%"+escapeLike(q.Text)+"%")
+}
+
+// Search returns the documents whose name, description or objectives contain the text,
+// those whose name does first and then by how closely the name matches
+func (r *TranslationRepository) Search(q SearchQuery, offset, limit int) ([]models.SearchDocument, int64, error) {
+	documents := []models.SearchDocument{}
+	var count int64
+	if err := q.apply(r.db.DB, "body").Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	err := q.apply(r.db.DB, "body").
+		Order(clause.Expr{SQL: "name ILIKE ? DESC, similarity(name, ?) DESC, entity_type ASC, entity_id ASC", Vars: []interface{}{"%" + escapeLike(q.Text) + "%", q.Text}, WithoutParentheses: true}).
+		Offset(offset).Limit(limit).Find(&documents).Error
+	return documents, count, err
+}
+
+// Autocomplete returns up to limit documents with a word of their name starting with the
+// text, names starting with it first and shorter names before longer ones
+func (r *TranslationRepository) Autocomplete(q SearchQuery, limit int) ([]models.SearchDocument, error) {
+	documents := []models.SearchDocument{}
+	prefix := escapeLike(q.Text) + "%"
+	err := q.apply(r.db.DB, "name").
+		Where("name ILIKE ? OR name ILIKE ?", prefix, "% "+prefix).
+		Order(clause.Expr{SQL: "name ILIKE ? DESC, length(name) ASC, name ASC", Vars: []interface{}{prefix}, WithoutParentheses: true}).
+		Limit(limit).Find(&documents).Error
+	return documents, err
+}
+
+// escapeLike escapes the wildcards of LIKE patterns in s, so user input matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+type ImageCheckRepository struct {
+	db *DB
+}
+
+func NewImageCheckRepository(db *DB) *ImageCheckRepository {
+	return &ImageCheckRepository{db: db}
+}
+
+func (r *ImageCheckRepository) ListAll() ([]models.ImageCheck, error) {
+	var checks []models.ImageCheck
+	err := r.db.Order("entity_type ASC, entity_id ASC").Find(&checks).Error
+	return checks, err
+}
+
+// FindByStatus lists checks with the given status, or every check that isn't ok when status is empty
+func (r *ImageCheckRepository) FindByStatus(status string) ([]models.ImageCheck, error) {
+	var checks []models.ImageCheck
+	query := r.db.Model(&models.ImageCheck{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	} else {
+		query = query.Where("status <> ?", models.ImageStatusOK)
+	}
+	err := query.Order("entity_type ASC, entity_id ASC").Find(&checks).Error
+	return checks, err
+}
+
+// ReplaceAll swaps the full set of check results in one transaction, like ItemStatRepository.ReplaceAll
+func (r *ImageCheckRepository) ReplaceAll(checks []models.ImageCheck) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.ImageCheck{}).Error; err != nil {
+			return err
+		}
+		if len(checks) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(checks, 500).Error
+	})
+}
+
+type TelemetryRepository struct {
+	db *DB
+}
+
+func NewTelemetryRepository(db *DB) *TelemetryRepository {
+	return &TelemetryRepository{db: db}
+}
+
+// Increment adds counts to the stored daily counts, creating the ones that don't exist yet
+func (r *TelemetryRepository) Increment(counts []models.TelemetryCount) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, count := range counts {
+			err := tx.Exec(`
+				INSERT INTO telemetry_counts (day, event_type, name, platform, app_version, count)
+				VALUES (?, ?, ?, ?, ?, ?)
+				ON CONFLICT (day, event_type, name, platform, app_version)
+				DO UPDATE SET count = telemetry_counts.count + EXCLUDED.count`,
+				count.Day, count.EventType, count.Name, count.Platform, count.AppVersion, count.Count,
+			).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// TelemetrySummary is the total count of an event over a period
+type TelemetrySummary struct {
+	EventType string `json:"event_type"`
+	Name      string `json:"name"`
+	Platform  string `json:"platform"`
+	Count     int64  `json:"count"`
+}
+
+// Summarize totals the counts since day per event and platform, most reported first
+func (r *TelemetryRepository) Summarize(since time.Time, eventType string) ([]TelemetrySummary, error) {
+	var summary []TelemetrySummary
+	query := r.db.Model(&models.TelemetryCount{}).
+		Select("event_type, name, platform, SUM(count) AS count").
+		Where("day >= ?", since)
+	if eventType != "" {
+		query = query.Where("event_type = ?", eventType)
+	}
+	err := query.Group("event_type, name, platform").Order("count DESC, name ASC").Scan(&summary).Error
+	return summary, err
+}
+
+// DeleteBefore removes daily counts older than day
+func (r *TelemetryRepository) DeleteBefore(day time.Time) (int64, error) {
+	result := r.db.Where("day < ?", day).Delete(&models.TelemetryCount{})
+	return result.RowsAffected, result.Error
+}
+
+type ExperimentRepository struct {
+	db *DB
+}
+
+func NewExperimentRepository(db *DB) *ExperimentRepository {
+	return &ExperimentRepository{db: db}
+}
+
+func (r *ExperimentRepository) Create(experiment *models.Experiment) error {
+	return r.db.Create(experiment).Error
+}
+
+func (r *ExperimentRepository) FindByKey(key string) (*models.Experiment, error) {
+	var experiment models.Experiment
+	err := r.db.Where("key = ?", key).First(&experiment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &experiment, nil
+}
+
+func (r *ExperimentRepository) ListAll() ([]models.Experiment, error) {
+	var experiments []models.Experiment
+	err := r.db.Order("key ASC").Find(&experiments).Error
+	return experiments, err
+}
+
+func (r *ExperimentRepository) Update(experiment *models.Experiment) error {
+	return r.db.Save(experiment).Error
+}
+
+func (r *ExperimentRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Experiment{}, id).Error
+}
+
+type TraderPriceHistoryRepository struct {
+	db *DB
+}
+
+func NewTraderPriceHistoryRepository(db *DB) *TraderPriceHistoryRepository {
+	return &TraderPriceHistoryRepository{db: db}
+}
+
+func (r *TraderPriceHistoryRepository) CreateBatch(snapshots []models.TraderPriceHistory) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(snapshots, 500).Error
+}
+
+// Latest returns the most recent snapshot of every item at every trader
+func (r *TraderPriceHistoryRepository) Latest() ([]models.TraderPriceHistory, error) {
+	var latest []models.TraderPriceHistory
+	err := r.db.Raw(`SELECT DISTINCT ON (trader_id, item_external_id) * FROM trader_price_history
+		ORDER BY trader_id, item_external_id, recorded_at DESC`).Scan(&latest).Error
+	return latest, err
+}
+
+// FindHistory returns an item's price snapshots at a trader since the given time, oldest
+// first. Snapshots are only recorded when the price changes, so the list starts with the
+// last snapshot before since, the price in effect when the window opens.
+func (r *TraderPriceHistoryRepository) FindHistory(traderID, itemExternalID string, since time.Time) ([]models.TraderPriceHistory, error) {
+	var before []models.TraderPriceHistory
+	if err := r.db.Where("trader_id = ? AND item_external_id = ? AND recorded_at < ?", traderID, itemExternalID, since).
+		Order("recorded_at DESC").Limit(1).Find(&before).Error; err != nil {
+		return nil, err
+	}
+
+	var history []models.TraderPriceHistory
+	err := r.db.Where("trader_id = ? AND item_external_id = ? AND recorded_at >= ?", traderID, itemExternalID, since).
+		Order("recorded_at ASC").Find(&history).Error
+	return append(before, history...), err
+}
+
+// DeleteOlderThan prunes snapshots recorded before the cutoff, keeping the latest snapshot
+// of every item at every trader since it holds the current price
+func (r *TraderPriceHistoryRepository) DeleteOlderThan(cutoff time.Time) (int64, error) {
+	result := r.db.Where(`recorded_at < ? AND id NOT IN (SELECT DISTINCT ON (trader_id, item_external_id) id
+		FROM trader_price_history ORDER BY trader_id, item_external_id, recorded_at DESC)`, cutoff).
+		Delete(&models.TraderPriceHistory{})
+	return result.RowsAffected, result.Error
+}
+
+type TraderStockRepository struct {
+	db *DB
+}
+
+func NewTraderStockRepository(db *DB) *TraderStockRepository {
+	return &TraderStockRepository{db: db}
+}
+
+// UpsertBatch records stock, extending last_seen_at of items already recorded in the same
+// rotation. first_seen_at is kept.
+func (r *TraderStockRepository) UpsertBatch(items []models.TraderStockItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "trader_id"}, {Name: "item_external_id"}, {Name: "rotation_start"}},
+		DoUpdates: clause.AssignmentColumns([]string{"trader_name", "item_name", "rotating", "last_seen_at"}),
+	}).CreateInBatches(items, 500).Error
+}
+
+// FindByRotation returns the stock of the rotation that started at rotationStart, by trader
+// and item. An empty traderID returns every trader's stock.
+func (r *TraderStockRepository) FindByRotation(rotationStart time.Time, traderID string) ([]models.TraderStockItem, error) {
+	query := r.db.Where("rotation_start = ?", rotationStart)
+	if traderID != "" {
+		query = query.Where("trader_id = ?", traderID)
+	}
+	var items []models.TraderStockItem
+	err := query.Order("trader_id ASC, item_external_id ASC").Find(&items).Error
+	return items, err
+}
+
+// FindPreviousRotation returns the start of the latest rotation with stock recorded before
+// the given time, or nil if there is none
+func (r *TraderStockRepository) FindPreviousRotation(before time.Time) (*time.Time, error) {
+	var start *time.Time
+	err := r.db.Model(&models.TraderStockItem{}).
+		Where("rotation_start < ?", before).
+		Select("MAX(rotation_start)").
+		Scan(&start).Error
+	return start, err
+}
+
+func (r *TraderStockRepository) DeleteOlderThan(cutoff time.Time) (int64, error) {
+	result := r.db.Where("rotation_start < ?", cutoff).Delete(&models.TraderStockItem{})
+	return result.RowsAffected, result.Error
+}
+
+type MapMarkerRepository struct {
+	db *DB
+}
+
+func NewMapMarkerRepository(db *DB) *MapMarkerRepository {
+	return &MapMarkerRepository{db: db}
+}
+
+func (r *MapMarkerRepository) Create(marker *models.MapMarker) error {
+	return r.db.Create(marker).Error
+}
+
+func (r *MapMarkerRepository) FindByID(id uint) (*models.MapMarker, error) {
+	var marker models.MapMarker
+	err := r.db.First(&marker, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &marker, nil
+}
+
+// FindByMap lists a map's markers, optionally restricted to one type
+func (r *MapMarkerRepository) FindByMap(mapID uint, markerType models.MapMarkerType) ([]models.MapMarker, error) {
+	var markers []models.MapMarker
+	query := r.db.Where("map_id = ?", mapID)
+	if markerType != "" {
+		query = query.Where("type = ?", markerType)
+	}
+	err := query.Order("type ASC, name ASC, id ASC").Find(&markers).Error
+	return markers, err
+}
+
+func (r *MapMarkerRepository) Update(marker *models.MapMarker) error {
+	return r.db.Save(marker).Error
+}
+
+func (r *MapMarkerRepository) Delete(id uint) error {
+	return r.db.Delete(&models.MapMarker{}, id).Error
+}
+
+type NotificationRepository struct {
+	db *DB
+}
+
+func NewNotificationRepository(db *DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) CreateBatch(notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(notifications, 500).Error
+}
+
+// FindByUserID returns a page of a user's notifications, newest first
+func (r *NotificationRepository) FindByUserID(userID uint, unreadOnly bool, offset, limit int) ([]models.Notification, int64, error) {
+	var notifications []models.Notification
+	var count int64
+	query := r.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&notifications).Error
+	return notifications, count, err
+}
+
+// CountUnread returns how many notifications the user hasn't read
+func (r *NotificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// FindAfter returns up to limit of a user's notifications with an ID above afterID, oldest
+// first
+func (r *NotificationRepository) FindAfter(userID, afterID uint, limit int) ([]models.Notification, error) {
+	var notifications []models.Notification
+	err := r.db.Where("user_id = ? AND id > ?", userID, afterID).
+		Order("id ASC").Limit(limit).Find(&notifications).Error
+	return notifications, err
+}
+
+// LatestID returns the ID of the user's newest notification, or 0 if they have none
+func (r *NotificationRepository) LatestID(userID uint) (uint, error) {
+	var id uint
+	err := r.db.Model(&models.Notification{}).Where("user_id = ?", userID).
+		Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
+
+// MarkRead marks one of the user's notifications read, returning the number of rows updated
+func (r *NotificationRepository) MarkRead(userID, id uint) (int64, error) {
+	result := r.db.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", id, userID).
+		Update("read_at", gorm.Expr("NOW()"))
+	return result.RowsAffected, result.Error
+}
+
+// MarkAllRead marks every unread notification of the user read
+func (r *NotificationRepository) MarkAllRead(userID uint) (int64, error) {
+	result := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", gorm.Expr("NOW()"))
+	return result.RowsAffected, result.Error
+}
+
+type SecurityEventRepository struct {
+	db *DB
+}
+
+func NewSecurityEventRepository(db *DB) *SecurityEventRepository {
+	return &SecurityEventRepository{db: db}
+}
+
+func (r *SecurityEventRepository) Create(event *models.SecurityEvent) error {
+	return r.db.Create(event).Error
+}
+
+// FindAll returns a page of security events, newest first, optionally filtered by type
+// and status
+func (r *SecurityEventRepository) FindAll(eventType, status string, offset, limit int) ([]models.SecurityEvent, int64, error) {
+	query := r.db.Model(&models.SecurityEvent{})
+	if eventType != "" {
+		query = query.Where("type = ?", eventType)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	var events []models.SecurityEvent
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&events).Error
+	return events, count, err
+}
+
+// UpdateStatus sets a security event's status, returning the number of rows updated
+func (r *SecurityEventRepository) UpdateStatus(id uint, status string) (int64, error) {
+	result := r.db.Model(&models.SecurityEvent{}).Where("id = ?", id).Update("status", status)
+	return result.RowsAffected, result.Error
+}
+
+type AuditLogRepository struct {
+	db *DB
+}
+
+func NewAuditLogRepository(db *DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+func (r *AuditLogRepository) Create(log *models.AuditLog) error {
+	return r.db.Create(log).Error
+}
+
+// FindByFilters pages through audit logs matching every filter given, newest first.
+// changedField matches logs whose changes include that field, e.g. xp or data.rarity.
+func (r *AuditLogRepository) FindByFilters(apiKeyID, jwtTokenID, userID *uint, endpoint, method, entityType, entityID, changedField, requestID *string, startTime, endTime *string, offset, limit int) ([]models.AuditLog, int64, error) {
+	query := r.db.Model(&models.AuditLog{})
+
+	if apiKeyID != nil {
+		query = query.Where("api_key_id = ?", *apiKeyID)
+	}
+	if jwtTokenID != nil {
+		query = query.Where("jwt_token_id = ?", *jwtTokenID)
+	}
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	if endpoint != nil {
+		query = query.Where("endpoint = ?", *endpoint)
+	}
+	if method != nil {
+		query = query.Where("method = ?", *method)
+	}
+	if entityType != nil {
+		query = query.Where("entity_type = ?", *entityType)
+	}
+	if entityID != nil {
+		query = query.Where("entity_id = ?", *entityID)
+	}
+	if changedField != nil {
+		query = query.Where("jsonb_exists(changes, ?)", *changedField)
+	}
+	if requestID != nil {
+		query = query.Where("request_id = ?", *requestID)
+	}
+	if startTime != nil {
+		query = query.Where("created_at >= ?", *startTime)
+	}
+	if endTime != nil {
+		query = query.Where("created_at <= ?", *endTime)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var logs []models.AuditLog
+	err = query.Preload("APIKey").Preload("JWTToken").Preload("User").
+		Order("created_at DESC").Offset(offset).Limit(limit).Find(&logs).Error
+	return logs, count, err
+}
+
+// AuditLogStats describes the size of the audit_logs table
+type AuditLogStats struct {
+	Rows        int64      `gorm:"column:row_count" json:"rows" example:"125000"`
+	TableBytes  int64      `gorm:"column:table_bytes" json:"table_bytes" example:"73400320"`
+	OldestEntry *time.Time `gorm:"column:oldest_entry" json:"oldest_entry,omitempty"`
+	NewestEntry *time.Time `gorm:"column:newest_entry" json:"newest_entry,omitempty"`
+}
+
+// Stats counts the audit logs and reports the table's size on disk, including indexes
+func (r *AuditLogRepository) Stats() (*AuditLogStats, error) {
+	var stats AuditLogStats
+	err := r.db.Raw(`
+		SELECT COUNT(*) AS row_count,
+			pg_total_relation_size('audit_logs') AS table_bytes,
+			MIN(created_at) AS oldest_entry,
+			MAX(created_at) AS newest_entry
+		FROM audit_logs`).Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// CountSince counts the audit logs created at or after since
+func (r *AuditLogRepository) CountSince(since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.AuditLog{}).Where("created_at >= ?", since).Count(&count).Error
+	return count, err
+}
+
+// CountBefore counts the audit logs created before cutoff
+func (r *AuditLogRepository) CountBefore(cutoff time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.AuditLog{}).Where("created_at < ?", cutoff).Count(&count).Error
+	return count, err
+}
+
+// FindBefore returns up to limit audit logs created before cutoff, in id order
+func (r *AuditLogRepository) FindBefore(cutoff time.Time, limit int) ([]models.AuditLog, error) {
+	var logs []models.AuditLog
+	err := r.db.Where("created_at < ?", cutoff).Order("id ASC").Limit(limit).Find(&logs).Error
+	return logs, err
+}
+
+// DeleteByIDs removes the audit logs with the given ids
+func (r *AuditLogRepository) DeleteByIDs(ids []uint) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := r.db.Where("id IN ?", ids).Delete(&models.AuditLog{})
+	return result.RowsAffected, result.Error
+}
+
+// UserQuestProgressRepository handles user quest progress
+type UserQuestProgressRepository struct {
+	db *DB
+}
+
+func NewUserQuestProgressRepository(db *DB) *UserQuestProgressRepository {
+	return &UserQuestProgressRepository{db: db}
+}
+
+func (r *UserQuestProgressRepository) Upsert(userID, questID uint, completed bool) (*models.UserQuestProgress, error) {
+	var progress models.UserQuestProgress
+	err := r.db.Where("user_id = ? AND quest_id = ?", userID, questID).First(&progress).Error
+
+	if err == gorm.ErrRecordNotFound {
+		// Create new
+		progress = models.UserQuestProgress{
+			UserID:    userID,
+			QuestID:   questID,
+			Completed: completed,
+		}
+		err = r.db.Create(&progress).Error
+		return &progress, err
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Update existing
+	progress.Completed = completed
+	err = r.db.Save(&progress).Error
+	return &progress, err
+}
+
+// FindByUserID returns a user's progress with the external ID of each Quest joined in.
+// The full Quest row is only preloaded when includeEntity is set.
+func (r *UserQuestProgressRepository) FindByUserID(userID uint, includeEntity bool) ([]models.UserQuestProgress, error) {
+	var progress []models.UserQuestProgress
+	query := r.db.Select("user_quest_progress.*, quests.external_id AS quest_external_id").
+		Joins("LEFT JOIN quests ON quests.id = user_quest_progress.quest_id")
+	if includeEntity {
+		query = query.Preload("Quest")
+	}
+	err := query.Where("user_quest_progress.user_id = ?", userID).Order("user_quest_progress.id ASC").Find(&progress).Error
+	return progress, err
+}
+
+func (r *UserQuestProgressRepository) FindByUserAndQuest(userID, questID uint) (*models.UserQuestProgress, error) {
+	var progress models.UserQuestProgress
+	err := r.db.Preload("Quest").Where("user_id = ? AND quest_id = ?", userID, questID).First(&progress).Error
+	if err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+func (r *UserQuestProgressRepository) Delete(userID, questID uint) error {
+	return r.db.Where("user_id = ? AND quest_id = ?", userID, questID).Delete(&models.UserQuestProgress{}).Error
+}
+
+// CountCompleted returns how many quests the user has completed
+func (r *UserQuestProgressRepository) CountCompleted(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.UserQuestProgress{}).Where("user_id = ? AND completed = ?", userID, true).Count(&count).Error
+	return count, err
+}
+
+// FindUsersTracking returns, for each of the given quests, the users who have it in their
+// progress without having completed it
+func (r *UserQuestProgressRepository) FindUsersTracking(questExternalIDs []string) ([]EntityUser, error) {
+	var rows []EntityUser
+	if len(questExternalIDs) == 0 {
+		return rows, nil
+	}
+	err := r.db.Model(&models.UserQuestProgress{}).
+		Select("quests.external_id AS entity_id, user_quest_progress.user_id AS user_id").
+		Joins("JOIN quests ON quests.id = user_quest_progress.quest_id").
+		Where("quests.external_id IN ? AND user_quest_progress.completed = ?", questExternalIDs, false).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// UserHideoutModuleProgressRepository handles user hideout module progress
+type UserHideoutModuleProgressRepository struct {
+	db *DB
+}
+
+func NewUserHideoutModuleProgressRepository(db *DB) *UserHideoutModuleProgressRepository {
+	return &UserHideoutModuleProgressRepository{db: db}
+}
+
+func (r *UserHideoutModuleProgressRepository) Upsert(userID, hideoutModuleID uint, unlocked bool, level int) (*models.UserHideoutModuleProgress, error) {
+	var progress models.UserHideoutModuleProgress
+	err := r.db.Where("user_id = ? AND hideout_module_id = ?", userID, hideoutModuleID).First(&progress).Error
+
+	if err == gorm.ErrRecordNotFound {
+		// Create new
+		progress = models.UserHideoutModuleProgress{
+			UserID:          userID,
+			HideoutModuleID: hideoutModuleID,
+			Unlocked:        unlocked,
+			Level:           level,
+		}
+		err = r.db.Create(&progress).Error
+		return &progress, err
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Update existing
+	progress.Unlocked = unlocked
+	progress.Level = level
+	err = r.db.Save(&progress).Error
+	return &progress, err
+}
+
+// FindByUserID returns a user's progress with the external ID of each HideoutModule joined in.
+// The full HideoutModule row is only preloaded when includeEntity is set.
+func (r *UserHideoutModuleProgressRepository) FindByUserID(userID uint, includeEntity bool) ([]models.UserHideoutModuleProgress, error) {
+	var progress []models.UserHideoutModuleProgress
+	query := r.db.Select("user_hideout_module_progress.*, hideout_modules.external_id AS hideout_module_external_id").
+		Joins("LEFT JOIN hideout_modules ON hideout_modules.id = user_hideout_module_progress.hideout_module_id")
+	if includeEntity {
+		query = query.Preload("HideoutModule")
+	}
+	err := query.Where("user_hideout_module_progress.user_id = ?", userID).Order("user_hideout_module_progress.id ASC").Find(&progress).Error
+	return progress, err
+}
+
+func (r *UserHideoutModuleProgressRepository) FindByUserAndModule(userID, hideoutModuleID uint) (*models.UserHideoutModuleProgress, error) {
+	var progress models.UserHideoutModuleProgress
+	err := r.db.Preload("HideoutModule").Where("user_id = ? AND hideout_module_id = ?", userID, hideoutModuleID).First(&progress).Error
+	if err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+func (r *UserHideoutModuleProgressRepository) Delete(userID, hideoutModuleID uint) error {
+	return r.db.Where("user_id = ? AND hideout_module_id = ?", userID, hideoutModuleID).Delete(&models.UserHideoutModuleProgress{}).Error
+}
+
+// FindUsersTracking returns, for each of the given modules, the users with progress on it
+func (r *UserHideoutModuleProgressRepository) FindUsersTracking(moduleExternalIDs []string) ([]EntityUser, error) {
+	var rows []EntityUser
+	if len(moduleExternalIDs) == 0 {
+		return rows, nil
+	}
+	err := r.db.Model(&models.UserHideoutModuleProgress{}).
+		Select("hideout_modules.external_id AS entity_id, user_hideout_module_progress.user_id AS user_id").
+		Joins("JOIN hideout_modules ON hideout_modules.id = user_hideout_module_progress.hideout_module_id").
+		Where("hideout_modules.external_id IN ?", moduleExternalIDs).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// CountUnlocked returns how many hideout modules the user has unlocked
+func (r *UserHideoutModuleProgressRepository) CountUnlocked(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.UserHideoutModuleProgress{}).Where("user_id = ? AND unlocked = ?", userID, true).Count(&count).Error
+	return count, err
+}
+
+// UserSkillNodeProgressRepository handles user skill node progress
+type UserSkillNodeProgressRepository struct {
+	db *DB
+}
+
+func NewUserSkillNodeProgressRepository(db *DB) *UserSkillNodeProgressRepository {
+	return &UserSkillNodeProgressRepository{db: db}
+}
+
+func (r *UserSkillNodeProgressRepository) Upsert(userID, skillNodeID uint, unlocked bool, level int) (*models.UserSkillNodeProgress, error) {
+	var progress models.UserSkillNodeProgress
+	err := r.db.Where("user_id = ? AND skill_node_id = ?", userID, skillNodeID).First(&progress).Error
+
+	if err == gorm.ErrRecordNotFound {
+		// Create new
+		progress = models.UserSkillNodeProgress{
+			UserID:      userID,
+			SkillNodeID: skillNodeID,
+			Unlocked:    unlocked,
+			Level:       level,
+		}
+		err = r.db.Create(&progress).Error
+		return &progress, err
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Update existing
+	progress.Unlocked = unlocked
+	progress.Level = level
+	err = r.db.Save(&progress).Error
+	return &progress, err
+}
+
+// FindByUserID returns a user's progress with the external ID of each SkillNode joined in.
+// The full SkillNode row is only preloaded when includeEntity is set.
+func (r *UserSkillNodeProgressRepository) FindByUserID(userID uint, includeEntity bool) ([]models.UserSkillNodeProgress, error) {
+	var progress []models.UserSkillNodeProgress
+	query := r.db.Select("user_skill_node_progress.*, skill_nodes.external_id AS skill_node_external_id").
+		Joins("LEFT JOIN skill_nodes ON skill_nodes.id = user_skill_node_progress.skill_node_id")
+	if includeEntity {
+		query = query.Preload("SkillNode")
+	}
+	err := query.Where("user_skill_node_progress.user_id = ?", userID).Order("user_skill_node_progress.id ASC").Find(&progress).Error
+	return progress, err
+}
+
+func (r *UserSkillNodeProgressRepository) FindByUserAndSkillNode(userID, skillNodeID uint) (*models.UserSkillNodeProgress, error) {
+	var progress models.UserSkillNodeProgress
+	err := r.db.Preload("SkillNode").Where("user_id = ? AND skill_node_id = ?", userID, skillNodeID).First(&progress).Error
+	if err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+func (r *UserSkillNodeProgressRepository) Delete(userID, skillNodeID uint) error {
+	return r.db.Where("user_id = ? AND skill_node_id = ?", userID, skillNodeID).Delete(&models.UserSkillNodeProgress{}).Error
+}
+
+// CountUnlocked returns how many skill nodes the user has unlocked
+func (r *UserSkillNodeProgressRepository) CountUnlocked(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.UserSkillNodeProgress{}).Where("user_id = ? AND unlocked = ?", userID, true).Count(&count).Error
+	return count, err
+}
+
+// UserBlueprintProgressRepository handles user blueprint progress (tracking consumed blueprints)
+type UserBlueprintProgressRepository struct {
+	db *DB
+}
+
+func NewUserBlueprintProgressRepository(db *DB) *UserBlueprintProgressRepository {
+	return &UserBlueprintProgressRepository{db: db}
+}
+
+func (r *UserBlueprintProgressRepository) Upsert(userID, itemID uint, consumed bool) (*models.UserBlueprintProgress, error) {
+	var progress models.UserBlueprintProgress
+	err := r.db.Where("user_id = ? AND item_id = ?", userID, itemID).First(&progress).Error
+
+	if err == gorm.ErrRecordNotFound {
+		// Create new
+		progress = models.UserBlueprintProgress{
+			UserID:   userID,
+			ItemID:   itemID,
+			Consumed: consumed,
+		}
+		err = r.db.Create(&progress).Error
+		return &progress, err
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Update existing
+	progress.Consumed = consumed
+	err = r.db.Save(&progress).Error
+	return &progress, err
+}
+
+// FindByUserID returns a user's progress with the external ID of each Item joined in.
+// The full Item row is only preloaded when includeEntity is set.
+func (r *UserBlueprintProgressRepository) FindByUserID(userID uint, includeEntity bool) ([]models.UserBlueprintProgress, error) {
+	var progress []models.UserBlueprintProgress
+	query := r.db.Select("user_blueprint_progress.*, items.external_id AS item_external_id").
+		Joins("LEFT JOIN items ON items.id = user_blueprint_progress.item_id")
+	if includeEntity {
+		query = query.Preload("Item")
+	}
+	err := query.Where("user_blueprint_progress.user_id = ?", userID).Order("user_blueprint_progress.id ASC").Find(&progress).Error
+	return progress, err
+}
+
+func (r *UserBlueprintProgressRepository) FindByUserAndItem(userID, itemID uint) (*models.UserBlueprintProgress, error) {
+	var progress models.UserBlueprintProgress
+	err := r.db.Preload("Item").Where("user_id = ? AND item_id = ?", userID, itemID).First(&progress).Error
+	if err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+func (r *UserBlueprintProgressRepository) Delete(userID, itemID uint) error {
+	return r.db.Where("user_id = ? AND item_id = ?", userID, itemID).Delete(&models.UserBlueprintProgress{}).Error
+}
+
+// CountConsumed returns how many blueprints the user has consumed
+func (r *UserBlueprintProgressRepository) CountConsumed(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.UserBlueprintProgress{}).Where("user_id = ? AND consumed = ?", userID, true).Count(&count).Error
+	return count, err
+}
+
+// UserTraderProgressRepository handles user trader level and reputation progress
+type UserTraderProgressRepository struct {
+	db *DB
+}
+
+func NewUserTraderProgressRepository(db *DB) *UserTraderProgressRepository {
+	return &UserTraderProgressRepository{db: db}
+}
+
+func (r *UserTraderProgressRepository) Upsert(userID, traderID uint, level, reputation int) (*models.UserTraderProgress, error) {
+	var progress models.UserTraderProgress
+	err := r.db.Where("user_id = ? AND trader_id = ?", userID, traderID).First(&progress).Error
+
+	if err == gorm.ErrRecordNotFound {
+		progress = models.UserTraderProgress{
+			UserID:     userID,
+			TraderID:   traderID,
+			Level:      level,
+			Reputation: reputation,
+		}
+		err = r.db.Create(&progress).Error
+		return &progress, err
+	} else if err != nil {
+		return nil, err
+	}
+
+	progress.Level = level
+	progress.Reputation = reputation
+	err = r.db.Save(&progress).Error
+	return &progress, err
+}
+
+// FindByUserID returns a user's trader progress with each trader's external ID and name
+// joined in. The full Trader row is only preloaded when includeEntity is set.
+func (r *UserTraderProgressRepository) FindByUserID(userID uint, includeEntity bool) ([]models.UserTraderProgress, error) {
+	var progress []models.UserTraderProgress
+	query := r.db.Select("user_trader_progress.*, traders.external_id AS trader_external_id, traders.name AS trader_name").
+		Joins("LEFT JOIN traders ON traders.id = user_trader_progress.trader_id")
+	if includeEntity {
+		query = query.Preload("Trader")
+	}
+	err := query.Where("user_trader_progress.user_id = ?", userID).Order("user_trader_progress.id ASC").Find(&progress).Error
+	return progress, err
+}
+
+// UserInventoryRepository handles users' stash quantities
+type UserInventoryRepository struct {
+	db *DB
+}
+
+func NewUserInventoryRepository(db *DB) *UserInventoryRepository {
+	return &UserInventoryRepository{db: db}
+}
+
+func (r *UserInventoryRepository) Upsert(userID, itemID uint, quantity int) (*models.UserInventoryItem, error) {
+	var entry models.UserInventoryItem
+	err := r.db.Where("user_id = ? AND item_id = ?", userID, itemID).First(&entry).Error
+
+	if err == gorm.ErrRecordNotFound {
+		entry = models.UserInventoryItem{
+			UserID:   userID,
+			ItemID:   itemID,
+			Quantity: quantity,
+		}
+		err = r.db.Create(&entry).Error
+		return &entry, err
+	} else if err != nil {
+		return nil, err
+	}
+
+	entry.Quantity = quantity
+	err = r.db.Save(&entry).Error
+	return &entry, err
+}
+
+// FindByUserID returns a user's stash with each item's external ID joined in. The full
+// Item row is only preloaded when includeEntity is set.
+func (r *UserInventoryRepository) FindByUserID(userID uint, includeEntity bool) ([]models.UserInventoryItem, error) {
+	var inventory []models.UserInventoryItem
+	query := r.db.Select("user_inventory_items.*, items.external_id AS item_external_id").
+		Joins("LEFT JOIN items ON items.id = user_inventory_items.item_id")
+	if includeEntity {
+		query = query.Preload("Item")
+	}
+	err := query.Where("user_inventory_items.user_id = ?", userID).Order("user_inventory_items.id ASC").Find(&inventory).Error
+	return inventory, err
+}
+
+// UserPlayerLevelRepository handles users' declared player level and XP
+type UserPlayerLevelRepository struct {
+	db *DB
+}
+
+func NewUserPlayerLevelRepository(db *DB) *UserPlayerLevelRepository {
+	return &UserPlayerLevelRepository{db: db}
+}
+
+func (r *UserPlayerLevelRepository) Upsert(userID uint, level, xp int) (*models.UserPlayerLevel, error) {
+	var playerLevel models.UserPlayerLevel
+	err := r.db.Where("user_id = ?", userID).First(&playerLevel).Error
+
+	if err == gorm.ErrRecordNotFound {
+		playerLevel = models.UserPlayerLevel{
+			UserID: userID,
+			Level:  level,
+			XP:     xp,
+		}
+		err = r.db.Create(&playerLevel).Error
+		return &playerLevel, err
+	} else if err != nil {
+		return nil, err
+	}
+
+	playerLevel.Level = level
+	playerLevel.XP = xp
+	err = r.db.Save(&playerLevel).Error
+	return &playerLevel, err
+}
+
+func (r *UserPlayerLevelRepository) FindByUserID(userID uint) (*models.UserPlayerLevel, error) {
+	var playerLevel models.UserPlayerLevel
+	err := r.db.Where("user_id = ?", userID).First(&playerLevel).Error
+	if err != nil {
+		return nil, err
+	}
+	return &playerLevel, nil
+}
+
+// UserNoteRepository handles users' notes on quests, items and hideout modules
+type UserNoteRepository struct {
+	db *DB
+}
+
+func NewUserNoteRepository(db *DB) *UserNoteRepository {
+	return &UserNoteRepository{db: db}
+}
+
+func (r *UserNoteRepository) Upsert(userID uint, entityType, entityID, body string) (*models.UserNote, error) {
+	note, err := r.Find(userID, entityType, entityID)
+	if err == gorm.ErrRecordNotFound {
+		note = &models.UserNote{
+			UserID:     userID,
+			EntityType: entityType,
+			EntityID:   entityID,
+			Body:       body,
+		}
+		err = r.db.Create(note).Error
+		return note, err
+	} else if err != nil {
+		return nil, err
+	}
+
+	note.Body = body
+	err = r.db.Save(note).Error
+	return note, err
+}
+
+func (r *UserNoteRepository) Find(userID uint, entityType, entityID string) (*models.UserNote, error) {
+	var note models.UserNote
+	err := r.db.Where("user_id = ? AND entity_type = ? AND entity_id = ?", userID, entityType, entityID).First(&note).Error
+	if err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+// FindByUserID lists a user's notes, most recently edited first, optionally for one entity type
+func (r *UserNoteRepository) FindByUserID(userID uint, entityType string) ([]models.UserNote, error) {
+	var notes []models.UserNote
+	query := r.db.Where("user_id = ?", userID)
+	if entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+	err := query.Order("updated_at DESC, id DESC").Find(&notes).Error
+	return notes, err
+}
+
+func (r *UserNoteRepository) Delete(userID uint, entityType, entityID string) error {
+	return r.db.Where("user_id = ? AND entity_type = ? AND entity_id = ?", userID, entityType, entityID).Delete(&models.UserNote{}).Error
+}
+
+// EntityUser pairs an entity's external ID with a user interested in it
+type EntityUser struct {
+	EntityID string
+	UserID   uint
+}
+
+type FavoriteRepository struct {
+	db *DB
+}
+
+func NewFavoriteRepository(db *DB) *FavoriteRepository {
+	return &FavoriteRepository{db: db}
+}
+
+// Add favorites an entity, returning the existing row if it was already a favorite.
+// created reports whether a new row was inserted.
+func (r *FavoriteRepository) Add(userID uint, entityType, entityID string) (favorite *models.Favorite, created bool, err error) {
+	var existing models.Favorite
+	err = r.db.Where("user_id = ? AND entity_type = ? AND entity_id = ?", userID, entityType, entityID).First(&existing).Error
+	if err == nil {
+		return &existing, false, nil
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, false, err
+	}
+
+	favorite = &models.Favorite{
+		UserID:     userID,
+		EntityType: entityType,
+		EntityID:   entityID,
+	}
+	if err := r.db.Create(favorite).Error; err != nil {
+		return nil, false, err
+	}
+	return favorite, true, nil
+}
+
+// FindByUserID lists a user's favorites, newest first, optionally for one entity type
+func (r *FavoriteRepository) FindByUserID(userID uint, entityType string) ([]models.Favorite, error) {
+	var favorites []models.Favorite
+	query := r.db.Where("user_id = ?", userID)
+	if entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+	err := query.Order("created_at DESC, id DESC").Find(&favorites).Error
+	return favorites, err
+}
+
+// FindUsersByEntities returns the users who favorited any of the given entities
+func (r *FavoriteRepository) FindUsersByEntities(entityType string, entityIDs []string) ([]EntityUser, error) {
+	var rows []EntityUser
+	if len(entityIDs) == 0 {
+		return rows, nil
+	}
+	err := r.db.Model(&models.Favorite{}).
+		Select("entity_id, user_id").
+		Where("entity_type = ? AND entity_id IN ?", entityType, entityIDs).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// Remove unfavorites an entity, returning the number of rows deleted
+func (r *FavoriteRepository) Remove(userID uint, entityType, entityID string) (int64, error) {
+	result := r.db.Where("user_id = ? AND entity_type = ? AND entity_id = ?", userID, entityType, entityID).Delete(&models.Favorite{})
+	return result.RowsAffected, result.Error
+}
+
+// TagRepository handles the admin-managed tags and the items and quests they are applied to
+type TagRepository struct {
+	db *DB
+}
+
+func NewTagRepository(db *DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+// List returns every tag, by slug
+func (r *TagRepository) List() ([]models.Tag, error) {
+	var tags []models.Tag
+	err := r.db.Order("slug ASC").Find(&tags).Error
+	return tags, err
+}
+
+func (r *TagRepository) FindBySlug(slug string) (*models.Tag, error) {
+	var tag models.Tag
+	err := r.db.Where("slug = ?", slug).First(&tag).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *TagRepository) Create(tag *models.Tag) error {
+	return r.db.Create(tag).Error
+}
+
+func (r *TagRepository) Update(tag *models.Tag) error {
+	return r.db.Save(tag).Error
+}
+
+// Delete removes a tag along with every application of it
+func (r *TagRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tag_id = ?", id).Delete(&models.EntityTag{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Tag{}, id).Error
+	})
+}
+
+// Assign applies a tag to an entity; applying it twice is a no-op
+func (r *TagRepository) Assign(tagID uint, entityType, entityID string) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.EntityTag{
+		TagID:      tagID,
+		EntityType: entityType,
+		EntityID:   entityID,
+	}).Error
+}
+
+// Unassign removes a tag from an entity, returning the number of rows deleted
+func (r *TagRepository) Unassign(tagID uint, entityType, entityID string) (int64, error) {
+	result := r.db.Where("tag_id = ? AND entity_type = ? AND entity_id = ?", tagID, entityType, entityID).Delete(&models.EntityTag{})
+	return result.RowsAffected, result.Error
+}
+
+// SlugsByEntity returns the slugs of the tags applied to each of the given entities, keyed
+// by external ID. A nil entityIDs covers every entity of the type.
+func (r *TagRepository) SlugsByEntity(entityType string, entityIDs []string) (map[string][]string, error) {
+	var rows []struct {
+		EntityID string
+		Slug     string
+	}
+	query := r.db.Model(&models.EntityTag{}).
+		Select("entity_tags.entity_id, tags.slug").
+		Joins("JOIN tags ON tags.id = entity_tags.tag_id").
+		Where("entity_tags.entity_type = ?", entityType)
+	if entityIDs != nil {
+		if len(entityIDs) == 0 {
+			return map[string][]string{}, nil
+		}
+		query = query.Where("entity_tags.entity_id IN ?", entityIDs)
+	}
+	if err := query.Order("tags.slug ASC").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	slugs := make(map[string][]string)
+	for _, row := range rows {
+		slugs[row.EntityID] = append(slugs[row.EntityID], row.Slug)
+	}
+	return slugs, nil
+}
+
+// EntityIDsWithTags returns the external IDs of the entities carrying any of the given tags
+func (r *TagRepository) EntityIDsWithTags(entityType string, slugs []string) ([]string, error) {
+	ids := []string{}
+	err := r.db.Model(&models.EntityTag{}).
+		Distinct().
+		Joins("JOIN tags ON tags.id = entity_tags.tag_id").
+		Where("entity_tags.entity_type = ? AND tags.slug IN ?", entityType, slugs).
+		Pluck("entity_tags.entity_id", &ids).Error
+	return ids, err
+}
+
+// SavedSearchRepository handles users' saved filter presets
+type SavedSearchRepository struct {
+	db *DB
+}
 
-	// Update existing
-	progress.Completed = completed
-	err = r.db.Save(&progress).Error
-	return &progress, err
+func NewSavedSearchRepository(db *DB) *SavedSearchRepository {
+	return &SavedSearchRepository{db: db}
 }
 
-func (r *UserQuestProgressRepository) FindByUserID(userID uint) ([]models.UserQuestProgress, error) {
-	var progress []models.UserQuestProgress
-	err := r.db.Preload("Quest").Where("user_id = ?", userID).Order("id ASC").Find(&progress).Error
-	return progress, err
+func (r *SavedSearchRepository) Create(search *models.SavedSearch) error {
+	return r.db.Create(search).Error
 }
 
-func (r *UserQuestProgressRepository) FindByUserAndQuest(userID, questID uint) (*models.UserQuestProgress, error) {
-	var progress models.UserQuestProgress
-	err := r.db.Preload("Quest").Where("user_id = ? AND quest_id = ?", userID, questID).First(&progress).Error
+func (r *SavedSearchRepository) Update(search *models.SavedSearch) error {
+	return r.db.Save(search).Error
+}
+
+// FindByUserID lists a user's saved searches by name, optionally for one entity type
+func (r *SavedSearchRepository) FindByUserID(userID uint, entityType string) ([]models.SavedSearch, error) {
+	searches := []models.SavedSearch{}
+	query := r.db.Where("user_id = ?", userID)
+	if entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+	err := query.Order("name ASC").Find(&searches).Error
+	return searches, err
+}
+
+// FindByID returns one of a user's saved searches
+func (r *SavedSearchRepository) FindByID(userID, id uint) (*models.SavedSearch, error) {
+	var search models.SavedSearch
+	err := r.db.Where("user_id = ? AND id = ?", userID, id).First(&search).Error
 	if err != nil {
 		return nil, err
 	}
-	return &progress, nil
+	return &search, nil
 }
 
-func (r *UserQuestProgressRepository) Delete(userID, questID uint) error {
-	return r.db.Where("user_id = ? AND quest_id = ?", userID, questID).Delete(&models.UserQuestProgress{}).Error
+// NameTaken reports whether the user has a saved search with this name other than exceptID
+func (r *SavedSearchRepository) NameTaken(userID uint, name string, exceptID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.SavedSearch{}).
+		Where("user_id = ? AND name = ? AND id <> ?", userID, name, exceptID).
+		Count(&count).Error
+	return count > 0, err
 }
 
-// UserHideoutModuleProgressRepository handles user hideout module progress
-type UserHideoutModuleProgressRepository struct {
+func (r *SavedSearchRepository) CountByUserID(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.SavedSearch{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// Delete removes one of a user's saved searches, returning the number of rows deleted
+func (r *SavedSearchRepository) Delete(userID, id uint) (int64, error) {
+	result := r.db.Where("user_id = ? AND id = ?", userID, id).Delete(&models.SavedSearch{})
+	return result.RowsAffected, result.Error
+}
+
+// SyncRunRepository handles the history of sync runs
+type SyncRunRepository struct {
 	db *DB
 }
 
-func NewUserHideoutModuleProgressRepository(db *DB) *UserHideoutModuleProgressRepository {
-	return &UserHideoutModuleProgressRepository{db: db}
+func NewSyncRunRepository(db *DB) *SyncRunRepository {
+	return &SyncRunRepository{db: db}
 }
 
-func (r *UserHideoutModuleProgressRepository) Upsert(userID, hideoutModuleID uint, unlocked bool, level int) (*models.UserHideoutModuleProgress, error) {
-	var progress models.UserHideoutModuleProgress
-	err := r.db.Where("user_id = ? AND hideout_module_id = ?", userID, hideoutModuleID).First(&progress).Error
+func (r *SyncRunRepository) Create(run *models.SyncRun) error {
+	return r.db.Create(run).Error
+}
 
-	if err == gorm.ErrRecordNotFound {
-		// Create new
-		progress = models.UserHideoutModuleProgress{
-			UserID:          userID,
-			HideoutModuleID: hideoutModuleID,
-			Unlocked:        unlocked,
-			Level:           level,
-		}
-		err = r.db.Create(&progress).Error
-		return &progress, err
-	} else if err != nil {
+func (r *SyncRunRepository) Update(run *models.SyncRun) error {
+	return r.db.Save(run).Error
+}
+
+// List returns sync runs newest first, optionally with one status
+func (r *SyncRunRepository) List(status string, offset, limit int) ([]models.SyncRun, int64, error) {
+	runs := []models.SyncRun{}
+	var count int64
+	query := r.db.Model(&models.SyncRun{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("started_at DESC, id DESC").Offset(offset).Limit(limit).Find(&runs).Error
+	return runs, count, err
+}
+
+func (r *SyncRunRepository) FindByID(id uint) (*models.SyncRun, error) {
+	var run models.SyncRun
+	err := r.db.First(&run, id).Error
+	if err != nil {
 		return nil, err
 	}
+	return &run, nil
+}
 
-	// Update existing
-	progress.Unlocked = unlocked
-	progress.Level = level
-	err = r.db.Save(&progress).Error
-	return &progress, err
+// DeleteBefore removes runs started before cutoff, returning the number deleted
+func (r *SyncRunRepository) DeleteBefore(cutoff time.Time) (int64, error) {
+	result := r.db.Where("started_at < ?", cutoff).Delete(&models.SyncRun{})
+	return result.RowsAffected, result.Error
 }
 
-func (r *UserHideoutModuleProgressRepository) FindByUserID(userID uint) ([]models.UserHideoutModuleProgress, error) {
-	var progress []models.UserHideoutModuleProgress
-	err := r.db.Preload("HideoutModule").Where("user_id = ?", userID).Order("id ASC").Find(&progress).Error
-	return progress, err
+// SyncConflictRepository handles conflicts between content edited through the API and
+// upstream
+type SyncConflictRepository struct {
+	db    *DB
+	cache *EntityCache
 }
 
-func (r *UserHideoutModuleProgressRepository) FindByUserAndModule(userID, hideoutModuleID uint) (*models.UserHideoutModuleProgress, error) {
-	var progress models.UserHideoutModuleProgress
-	err := r.db.Preload("HideoutModule").Where("user_id = ? AND hideout_module_id = ?", userID, hideoutModuleID).First(&progress).Error
+func NewSyncConflictRepository(db *DB, cache *EntityCache) *SyncConflictRepository {
+	return &SyncConflictRepository{db: db, cache: cache}
+}
+
+// manualOverrideModels are the content models, by entity type, admins can edit through the
+// API and sync then leaves alone
+var manualOverrideModels = map[string]interface{}{
+	"quests":          &models.Quest{},
+	"items":           &models.Item{},
+	"skill_nodes":     &models.SkillNode{},
+	"hideout_modules": &models.HideoutModule{},
+}
+
+// List returns conflicts most recently detected first, optionally of one entity type.
+// Conflicts admins dismissed are only included with dismissed.
+func (r *SyncConflictRepository) List(entityType string, dismissed bool, offset, limit int) ([]models.SyncConflict, int64, error) {
+	conflicts := []models.SyncConflict{}
+	var count int64
+	query := r.db.Model(&models.SyncConflict{})
+	if entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+	if !dismissed {
+		query = query.Where("dismissed_at IS NULL")
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("detected_at DESC, id DESC").Offset(offset).Limit(limit).Find(&conflicts).Error
+	return conflicts, count, err
+}
+
+func (r *SyncConflictRepository) FindByID(id uint) (*models.SyncConflict, error) {
+	var conflict models.SyncConflict
+	err := r.db.First(&conflict, id).Error
 	if err != nil {
 		return nil, err
 	}
-	return &progress, nil
+	return &conflict, nil
 }
 
-func (r *UserHideoutModuleProgressRepository) Delete(userID, hideoutModuleID uint) error {
-	return r.db.Where("user_id = ? AND hideout_module_id = ?", userID, hideoutModuleID).Delete(&models.UserHideoutModuleProgress{}).Error
+// Dismiss keeps the local edits of a conflict; it's listed again once upstream changes again
+func (r *SyncConflictRepository) Dismiss(conflict *models.SyncConflict) error {
+	now := time.Now()
+	conflict.DismissedAt = &now
+	return r.db.Save(conflict).Error
 }
 
-// UserSkillNodeProgressRepository handles user skill node progress
-type UserSkillNodeProgressRepository struct {
-	db *DB
+// ReleaseOverride lets sync overwrite the row of a conflict again and removes the conflict,
+// so the next sync of its entity type replaces the local edits with upstream
+func (r *SyncConflictRepository) ReleaseOverride(conflict *models.SyncConflict) error {
+	model, ok := manualOverrideModels[conflict.EntityType]
+	if !ok {
+		return fmt.Errorf("unknown entity type %q", conflict.EntityType)
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(model).Where("id = ?", conflict.EntityID).Update("manual_override", false).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.SyncConflict{}, conflict.ID).Error
+	})
+	if err == nil {
+		r.cache.scope(conflict.EntityType).invalidate()
+	}
+	return err
 }
 
-func NewUserSkillNodeProgressRepository(db *DB) *UserSkillNodeProgressRepository {
-	return &UserSkillNodeProgressRepository{db: db}
+// UserProgressRepository handles operations spanning every progress type of a user
+type UserProgressRepository struct {
+	db *DB
 }
 
-func (r *UserSkillNodeProgressRepository) Upsert(userID, skillNodeID uint, unlocked bool, level int) (*models.UserSkillNodeProgress, error) {
-	var progress models.UserSkillNodeProgress
-	err := r.db.Where("user_id = ? AND skill_node_id = ?", userID, skillNodeID).First(&progress).Error
+func NewUserProgressRepository(db *DB) *UserProgressRepository {
+	return &UserProgressRepository{db: db}
+}
+
+// ReplaceAll overwrites a user's progress in a single transaction. A nil slice leaves
+// that progress type untouched; an empty slice clears it.
+func (r *UserProgressRepository) ReplaceAll(
+	userID uint,
+	quests []models.UserQuestProgress,
+	hideoutModules []models.UserHideoutModuleProgress,
+	skillNodes []models.UserSkillNodeProgress,
+	blueprints []models.UserBlueprintProgress,
+) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if quests != nil {
+			if err := tx.Where("user_id = ?", userID).Delete(&models.UserQuestProgress{}).Error; err != nil {
+				return err
+			}
+			for i := range quests {
+				quests[i].ID = 0
+				quests[i].UserID = userID
+			}
+			if len(quests) > 0 {
+				if err := tx.Omit("User", "Quest").Create(&quests).Error; err != nil {
+					return err
+				}
+			}
+		}
 
-	if err == gorm.ErrRecordNotFound {
-		// Create new
-		progress = models.UserSkillNodeProgress{
-			UserID:      userID,
-			SkillNodeID: skillNodeID,
-			Unlocked:    unlocked,
-			Level:       level,
+		if hideoutModules != nil {
+			if err := tx.Where("user_id = ?", userID).Delete(&models.UserHideoutModuleProgress{}).Error; err != nil {
+				return err
+			}
+			for i := range hideoutModules {
+				hideoutModules[i].ID = 0
+				hideoutModules[i].UserID = userID
+			}
+			if len(hideoutModules) > 0 {
+				if err := tx.Omit("User", "HideoutModule").Create(&hideoutModules).Error; err != nil {
+					return err
+				}
+			}
 		}
-		err = r.db.Create(&progress).Error
-		return &progress, err
-	} else if err != nil {
-		return nil, err
-	}
 
-	// Update existing
-	progress.Unlocked = unlocked
-	progress.Level = level
-	err = r.db.Save(&progress).Error
-	return &progress, err
+		if skillNodes != nil {
+			if err := tx.Where("user_id = ?", userID).Delete(&models.UserSkillNodeProgress{}).Error; err != nil {
+				return err
+			}
+			for i := range skillNodes {
+				skillNodes[i].ID = 0
+				skillNodes[i].UserID = userID
+			}
+			if len(skillNodes) > 0 {
+				if err := tx.Omit("User", "SkillNode").Create(&skillNodes).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		if blueprints != nil {
+			if err := tx.Where("user_id = ?", userID).Delete(&models.UserBlueprintProgress{}).Error; err != nil {
+				return err
+			}
+			for i := range blueprints {
+				blueprints[i].ID = 0
+				blueprints[i].UserID = userID
+			}
+			if len(blueprints) > 0 {
+				if err := tx.Omit("User", "Item").Create(&blueprints).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
 }
 
-func (r *UserSkillNodeProgressRepository) FindByUserID(userID uint) ([]models.UserSkillNodeProgress, error) {
-	var progress []models.UserSkillNodeProgress
-	err := r.db.Preload("SkillNode").Where("user_id = ?", userID).Order("id ASC").Find(&progress).Error
-	return progress, err
+// Progress categories, named after the keys used in progress exports
+const (
+	ProgressCategoryQuests         = "quests"
+	ProgressCategoryHideoutModules = "hideout_modules"
+	ProgressCategorySkillNodes     = "skill_nodes"
+	ProgressCategoryBlueprints     = "blueprints"
+)
+
+// ProgressCategories lists every progress category in a stable order
+var ProgressCategories = []string{
+	ProgressCategoryQuests,
+	ProgressCategoryHideoutModules,
+	ProgressCategorySkillNodes,
+	ProgressCategoryBlueprints,
 }
 
-func (r *UserSkillNodeProgressRepository) FindByUserAndSkillNode(userID, skillNodeID uint) (*models.UserSkillNodeProgress, error) {
-	var progress models.UserSkillNodeProgress
-	err := r.db.Preload("SkillNode").Where("user_id = ? AND skill_node_id = ?", userID, skillNodeID).First(&progress).Error
+// Conflict modes for CopyProgress when the target user already has a row for an entity
+const (
+	CopyModeOverwrite = "overwrite" // clear the target's category first, then copy
+	CopyModeMerge     = "merge"     // copy everything; source rows win on conflict
+	CopyModeSkip      = "skip"      // copy only rows the target doesn't have yet
+)
+
+type progressTable struct {
+	table     string
+	entityCol string
+	valueCols []string
+}
+
+var progressTables = map[string]progressTable{
+	ProgressCategoryQuests:         {"user_quest_progress", "quest_id", []string{"completed"}},
+	ProgressCategoryHideoutModules: {"user_hideout_module_progress", "hideout_module_id", []string{"unlocked", "level"}},
+	ProgressCategorySkillNodes:     {"user_skill_node_progress", "skill_node_id", []string{"unlocked", "level"}},
+	ProgressCategoryBlueprints:     {"user_blueprint_progress", "item_id", []string{"consumed"}},
+}
+
+// CopyProgress copies the given categories of progress from one user to another in a
+// single transaction and returns the number of rows written per category.
+func (r *UserProgressRepository) CopyProgress(fromUserID, toUserID uint, categories []string, mode string) (map[string]int64, error) {
+	written := make(map[string]int64, len(categories))
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, category := range categories {
+			t, ok := progressTables[category]
+			if !ok {
+				return fmt.Errorf("unknown progress category %q", category)
+			}
+
+			if mode == CopyModeOverwrite {
+				if err := tx.Exec("DELETE FROM "+t.table+" WHERE user_id = ?", toUserID).Error; err != nil {
+					return err
+				}
+			}
+
+			cols := t.entityCol + ", " + strings.Join(t.valueCols, ", ")
+			query := "INSERT INTO " + t.table + " (user_id, " + cols + ", created_at, updated_at) " +
+				"SELECT ?, " + cols + ", NOW(), NOW() FROM " + t.table + " WHERE user_id = ?"
+
+			switch mode {
+			case CopyModeMerge:
+				updates := make([]string, 0, len(t.valueCols)+1)
+				for _, col := range t.valueCols {
+					updates = append(updates, col+" = EXCLUDED."+col)
+				}
+				updates = append(updates, "updated_at = EXCLUDED.updated_at")
+				query += " ON CONFLICT (user_id, " + t.entityCol + ") DO UPDATE SET " + strings.Join(updates, ", ")
+			case CopyModeSkip:
+				query += " ON CONFLICT (user_id, " + t.entityCol + ") DO NOTHING"
+			case CopyModeOverwrite:
+			default:
+				return fmt.Errorf("unknown copy mode %q", mode)
+			}
+
+			result := tx.Exec(query, toUserID, fromUserID)
+			if result.Error != nil {
+				return result.Error
+			}
+			written[category] = result.RowsAffected
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	return &progress, nil
+	return written, nil
+}
+
+// UserIDsWithProgress returns the IDs of users with quest, hideout module, skill node or
+// blueprint progress
+func (r *UserProgressRepository) UserIDsWithProgress() ([]uint, error) {
+	var ids []uint
+	err := r.db.Raw(`SELECT user_id FROM user_quest_progress
+		UNION SELECT user_id FROM user_hideout_module_progress
+		UNION SELECT user_id FROM user_skill_node_progress
+		UNION SELECT user_id FROM user_blueprint_progress
+		ORDER BY user_id`).Scan(&ids).Error
+	return ids, err
+}
+
+// Load returns a user's quest, hideout module, skill node and blueprint progress with the
+// external ID of each entity joined in. Types without progress are empty rather than nil.
+func (r *UserProgressRepository) Load(userID uint) (*models.ProgressSnapshotData, error) {
+	data := &models.ProgressSnapshotData{
+		Quests:         []models.UserQuestProgress{},
+		HideoutModules: []models.UserHideoutModuleProgress{},
+		SkillNodes:     []models.UserSkillNodeProgress{},
+		Blueprints:     []models.UserBlueprintProgress{},
+	}
+	err := r.db.Select("user_quest_progress.*, quests.external_id AS quest_external_id").
+		Joins("LEFT JOIN quests ON quests.id = user_quest_progress.quest_id").
+		Where("user_quest_progress.user_id = ?", userID).Order("user_quest_progress.quest_id ASC").Find(&data.Quests).Error
+	if err != nil {
+		return nil, err
+	}
+	err = r.db.Select("user_hideout_module_progress.*, hideout_modules.external_id AS hideout_module_external_id").
+		Joins("LEFT JOIN hideout_modules ON hideout_modules.id = user_hideout_module_progress.hideout_module_id").
+		Where("user_hideout_module_progress.user_id = ?", userID).Order("user_hideout_module_progress.hideout_module_id ASC").Find(&data.HideoutModules).Error
+	if err != nil {
+		return nil, err
+	}
+	err = r.db.Select("user_skill_node_progress.*, skill_nodes.external_id AS skill_node_external_id").
+		Joins("LEFT JOIN skill_nodes ON skill_nodes.id = user_skill_node_progress.skill_node_id").
+		Where("user_skill_node_progress.user_id = ?", userID).Order("user_skill_node_progress.skill_node_id ASC").Find(&data.SkillNodes).Error
+	if err != nil {
+		return nil, err
+	}
+	err = r.db.Select("user_blueprint_progress.*, items.external_id AS item_external_id").
+		Joins("LEFT JOIN items ON items.id = user_blueprint_progress.item_id").
+		Where("user_blueprint_progress.user_id = ?", userID).Order("user_blueprint_progress.item_id ASC").Find(&data.Blueprints).Error
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
 }
 
-func (r *UserSkillNodeProgressRepository) Delete(userID, skillNodeID uint) error {
-	return r.db.Where("user_id = ? AND skill_node_id = ?", userID, skillNodeID).Delete(&models.UserSkillNodeProgress{}).Error
-}
+// Restore overwrites every progress type of a user with data in a single transaction.
+// Rows for quests, hideout modules, skill nodes and items that no longer exist are left
+// out; the number left out is returned.
+func (r *UserProgressRepository) Restore(userID uint, data *models.ProgressSnapshotData) (int, error) {
+	var skipped int
+	keep := func(table string, ids []uint) (map[uint]bool, error) {
+		var existing []uint
+		if len(ids) > 0 {
+			if err := r.db.Table(table).Where("id IN ?", ids).Pluck("id", &existing).Error; err != nil {
+				return nil, err
+			}
+		}
+		found := make(map[uint]bool, len(existing))
+		for _, id := range existing {
+			found[id] = true
+		}
+		skipped += len(ids) - len(existing)
+		return found, nil
+	}
 
-// UserBlueprintProgressRepository handles user blueprint progress (tracking consumed blueprints)
-type UserBlueprintProgressRepository struct {
-	db *DB
-}
+	quests := make([]models.UserQuestProgress, 0, len(data.Quests))
+	ids := make([]uint, len(data.Quests))
+	for i, p := range data.Quests {
+		ids[i] = p.QuestID
+	}
+	found, err := keep("quests", ids)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range data.Quests {
+		if found[p.QuestID] {
+			quests = append(quests, models.UserQuestProgress{QuestID: p.QuestID, Completed: p.Completed})
+		}
+	}
 
-func NewUserBlueprintProgressRepository(db *DB) *UserBlueprintProgressRepository {
-	return &UserBlueprintProgressRepository{db: db}
-}
+	modules := make([]models.UserHideoutModuleProgress, 0, len(data.HideoutModules))
+	ids = make([]uint, len(data.HideoutModules))
+	for i, p := range data.HideoutModules {
+		ids[i] = p.HideoutModuleID
+	}
+	if found, err = keep("hideout_modules", ids); err != nil {
+		return 0, err
+	}
+	for _, p := range data.HideoutModules {
+		if found[p.HideoutModuleID] {
+			modules = append(modules, models.UserHideoutModuleProgress{HideoutModuleID: p.HideoutModuleID, Unlocked: p.Unlocked, Level: p.Level})
+		}
+	}
 
-func (r *UserBlueprintProgressRepository) Upsert(userID, itemID uint, consumed bool) (*models.UserBlueprintProgress, error) {
-	var progress models.UserBlueprintProgress
-	err := r.db.Where("user_id = ? AND item_id = ?", userID, itemID).First(&progress).Error
+	nodes := make([]models.UserSkillNodeProgress, 0, len(data.SkillNodes))
+	ids = make([]uint, len(data.SkillNodes))
+	for i, p := range data.SkillNodes {
+		ids[i] = p.SkillNodeID
+	}
+	if found, err = keep("skill_nodes", ids); err != nil {
+		return 0, err
+	}
+	for _, p := range data.SkillNodes {
+		if found[p.SkillNodeID] {
+			nodes = append(nodes, models.UserSkillNodeProgress{SkillNodeID: p.SkillNodeID, Unlocked: p.Unlocked, Level: p.Level})
+		}
+	}
 
-	if err == gorm.ErrRecordNotFound {
-		// Create new
-		progress = models.UserBlueprintProgress{
-			UserID:   userID,
-			ItemID:   itemID,
-			Consumed: consumed,
+	blueprints := make([]models.UserBlueprintProgress, 0, len(data.Blueprints))
+	ids = make([]uint, len(data.Blueprints))
+	for i, p := range data.Blueprints {
+		ids[i] = p.ItemID
+	}
+	if found, err = keep("items", ids); err != nil {
+		return 0, err
+	}
+	for _, p := range data.Blueprints {
+		if found[p.ItemID] {
+			blueprints = append(blueprints, models.UserBlueprintProgress{ItemID: p.ItemID, Consumed: p.Consumed})
 		}
-		err = r.db.Create(&progress).Error
-		return &progress, err
-	} else if err != nil {
-		return nil, err
 	}
 
-	// Update existing
-	progress.Consumed = consumed
-	err = r.db.Save(&progress).Error
-	return &progress, err
+	if err := r.ReplaceAll(userID, quests, modules, nodes, blueprints); err != nil {
+		return 0, err
+	}
+	return skipped, nil
 }
 
-func (r *UserBlueprintProgressRepository) FindByUserID(userID uint) ([]models.UserBlueprintProgress, error) {
-	var progress []models.UserBlueprintProgress
-	err := r.db.Preload("Item").Where("user_id = ?", userID).Order("id ASC").Find(&progress).Error
-	return progress, err
+// ProgressSnapshotRepository keeps user progress snapshots in the progress_snapshots table
+type ProgressSnapshotRepository struct {
+	db *DB
 }
 
-func (r *UserBlueprintProgressRepository) FindByUserAndItem(userID, itemID uint) (*models.UserBlueprintProgress, error) {
-	var progress models.UserBlueprintProgress
-	err := r.db.Preload("Item").Where("user_id = ? AND item_id = ?", userID, itemID).First(&progress).Error
-	if err != nil {
+func NewProgressSnapshotRepository(db *DB) *ProgressSnapshotRepository {
+	return &ProgressSnapshotRepository{db: db}
+}
+
+func (r *ProgressSnapshotRepository) Save(snapshot *models.ProgressSnapshot) error {
+	return r.db.Create(snapshot).Error
+}
+
+// List returns a user's snapshots, newest first, without their progress
+func (r *ProgressSnapshotRepository) List(userID uint) ([]models.ProgressSnapshot, error) {
+	var snapshots []models.ProgressSnapshot
+	err := r.db.Omit("progress").Where("user_id = ?", userID).Order("taken_at DESC, id DESC").Find(&snapshots).Error
+	return snapshots, err
+}
+
+// Get returns one of a user's snapshots with its progress, or nil if the user has no such
+// snapshot
+func (r *ProgressSnapshotRepository) Get(userID, id uint) (*models.ProgressSnapshot, error) {
+	var snapshots []models.ProgressSnapshot
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).Limit(1).Find(&snapshots).Error; err != nil {
 		return nil, err
 	}
-	return &progress, nil
+	if len(snapshots) == 0 {
+		return nil, nil
+	}
+	return &snapshots[0], nil
 }
 
-func (r *UserBlueprintProgressRepository) Delete(userID, itemID uint) error {
-	return r.db.Where("user_id = ? AND item_id = ?", userID, itemID).Delete(&models.UserBlueprintProgress{}).Error
+func (r *ProgressSnapshotRepository) DeleteBefore(cutoff time.Time) (int64, error) {
+	result := r.db.Where("taken_at < ?", cutoff).Delete(&models.ProgressSnapshot{})
+	return result.RowsAffected, result.Error
 }
 
 // Bot Repository
 type BotRepository struct {
-	db *DB
+	db    *DB
+	cache *entityCacheScope
+}
+
+func NewBotRepository(db *DB, cache *EntityCache) *BotRepository {
+	return &BotRepository{db: db, cache: cache.scope("bots")}
 }
 
-func NewBotRepository(db *DB) *BotRepository {
-	return &BotRepository{db: db}
+// InvalidateCache drops the cached detail lookups of every bot
+func (r *BotRepository) InvalidateCache() {
+	r.cache.invalidate()
 }
 
 func (r *BotRepository) FindByID(id uint) (*models.Bot, error) {
-	var bot models.Bot
-	err := r.db.First(&bot, id).Error
-	if err != nil {
-		return nil, err
-	}
-	return &bot, nil
+	return findCached(r.cache, idLookup(id), func() (*models.Bot, error) {
+		var bot models.Bot
+		err := r.db.First(&bot, id).Error
+		if err != nil {
+			return nil, err
+		}
+		return &bot, nil
+	})
 }
 
 func (r *BotRepository) FindByExternalID(externalID string) (*models.Bot, error) {
-	var bot models.Bot
-	err := r.db.Where("external_id = ?", externalID).First(&bot).Error
-	if err != nil {
-		return nil, err
-	}
-	return &bot, nil
+	return findCached(r.cache, externalIDLookup(externalID), func() (*models.Bot, error) {
+		var bot models.Bot
+		err := r.db.Where("external_id = ?", externalID).First(&bot).Error
+		if err != nil {
+			return nil, err
+		}
+		return &bot, nil
+	})
 }
 
 func (r *BotRepository) FindAll(offset, limit int) ([]models.Bot, int64, error) {
@@ -865,12 +3391,25 @@ func (r *BotRepository) FindAll(offset, limit int) ([]models.Bot, int64, error)
 	return bots, count, err
 }
 
+// FindAllBatched calls fn with every bot in id order, batchSize at a time
+func (r *BotRepository) FindAllBatched(batchSize int, fn func([]models.Bot) error) error {
+	return findAllBatched(r.db, batchSize, fn)
+}
+
+// UpsertAllByExternalID inserts or updates bots by external_id in one transaction
+func (r *BotRepository) UpsertAllByExternalID(bots []models.Bot) error {
+	defer r.cache.invalidate()
+	return upsertAllByExternalID(r.db, bots)
+}
+
 func (r *BotRepository) ListAll() ([]models.Bot, error) {
 	var bots []models.Bot
 	err := r.db.Order("id ASC").Find(&bots).Error
 	return bots, err
 }
 
+// UpsertByExternalID doesn't invalidate cached lookups, as sync upserts row by row;
+// call InvalidateCache once done
 func (r *BotRepository) UpsertByExternalID(bot *models.Bot) error {
 	var existing models.Bot
 	err := r.db.Where("external_id = ?", bot.ExternalID).First(&existing).Error
@@ -886,29 +3425,39 @@ func (r *BotRepository) UpsertByExternalID(bot *models.Bot) error {
 
 // Map Repository
 type MapRepository struct {
-	db *DB
+	db    *DB
+	cache *entityCacheScope
 }
 
-func NewMapRepository(db *DB) *MapRepository {
-	return &MapRepository{db: db}
+func NewMapRepository(db *DB, cache *EntityCache) *MapRepository {
+	return &MapRepository{db: db, cache: cache.scope("maps")}
+}
+
+// InvalidateCache drops the cached detail lookups of every map
+func (r *MapRepository) InvalidateCache() {
+	r.cache.invalidate()
 }
 
 func (r *MapRepository) FindByID(id uint) (*models.Map, error) {
-	var m models.Map
-	err := r.db.First(&m, id).Error
-	if err != nil {
-		return nil, err
-	}
-	return &m, nil
+	return findCached(r.cache, idLookup(id), func() (*models.Map, error) {
+		var m models.Map
+		err := r.db.First(&m, id).Error
+		if err != nil {
+			return nil, err
+		}
+		return &m, nil
+	})
 }
 
 func (r *MapRepository) FindByExternalID(externalID string) (*models.Map, error) {
-	var m models.Map
-	err := r.db.Where("external_id = ?", externalID).First(&m).Error
-	if err != nil {
-		return nil, err
-	}
-	return &m, nil
+	return findCached(r.cache, externalIDLookup(externalID), func() (*models.Map, error) {
+		var m models.Map
+		err := r.db.Where("external_id = ?", externalID).First(&m).Error
+		if err != nil {
+			return nil, err
+		}
+		return &m, nil
+	})
 }
 
 func (r *MapRepository) FindAll(offset, limit int) ([]models.Map, int64, error) {
@@ -922,12 +3471,25 @@ func (r *MapRepository) FindAll(offset, limit int) ([]models.Map, int64, error)
 	return maps, count, err
 }
 
+// FindAllBatched calls fn with every map in id order, batchSize at a time
+func (r *MapRepository) FindAllBatched(batchSize int, fn func([]models.Map) error) error {
+	return findAllBatched(r.db, batchSize, fn)
+}
+
+// UpsertAllByExternalID inserts or updates maps by external_id in one transaction
+func (r *MapRepository) UpsertAllByExternalID(maps []models.Map) error {
+	defer r.cache.invalidate()
+	return upsertAllByExternalID(r.db, maps)
+}
+
 func (r *MapRepository) ListAll() ([]models.Map, error) {
 	var maps []models.Map
 	err := r.db.Order("id ASC").Find(&maps).Error
 	return maps, err
 }
 
+// UpsertByExternalID doesn't invalidate cached lookups, as sync upserts row by row;
+// call InvalidateCache once done
 func (r *MapRepository) UpsertByExternalID(m *models.Map) error {
 	var existing models.Map
 	err := r.db.Where("external_id = ?", m.ExternalID).First(&existing).Error
@@ -943,29 +3505,39 @@ func (r *MapRepository) UpsertByExternalID(m *models.Map) error {
 
 // Trader Repository
 type TraderRepository struct {
-	db *DB
+	db    *DB
+	cache *entityCacheScope
+}
+
+func NewTraderRepository(db *DB, cache *EntityCache) *TraderRepository {
+	return &TraderRepository{db: db, cache: cache.scope("traders")}
 }
 
-func NewTraderRepository(db *DB) *TraderRepository {
-	return &TraderRepository{db: db}
+// InvalidateCache drops the cached detail lookups of every trader
+func (r *TraderRepository) InvalidateCache() {
+	r.cache.invalidate()
 }
 
 func (r *TraderRepository) FindByID(id uint) (*models.Trader, error) {
-	var trader models.Trader
-	err := r.db.First(&trader, id).Error
-	if err != nil {
-		return nil, err
-	}
-	return &trader, nil
+	return findCached(r.cache, idLookup(id), func() (*models.Trader, error) {
+		var trader models.Trader
+		err := r.db.First(&trader, id).Error
+		if err != nil {
+			return nil, err
+		}
+		return &trader, nil
+	})
 }
 
 func (r *TraderRepository) FindByExternalID(externalID string) (*models.Trader, error) {
-	var trader models.Trader
-	err := r.db.Where("external_id = ?", externalID).First(&trader).Error
-	if err != nil {
-		return nil, err
-	}
-	return &trader, nil
+	return findCached(r.cache, externalIDLookup(externalID), func() (*models.Trader, error) {
+		var trader models.Trader
+		err := r.db.Where("external_id = ?", externalID).First(&trader).Error
+		if err != nil {
+			return nil, err
+		}
+		return &trader, nil
+	})
 }
 
 func (r *TraderRepository) FindAll(offset, limit int) ([]models.Trader, int64, error) {
@@ -979,12 +3551,25 @@ func (r *TraderRepository) FindAll(offset, limit int) ([]models.Trader, int64, e
 	return traders, count, err
 }
 
+// FindAllBatched calls fn with every trader in id order, batchSize at a time
+func (r *TraderRepository) FindAllBatched(batchSize int, fn func([]models.Trader) error) error {
+	return findAllBatched(r.db, batchSize, fn)
+}
+
+// UpsertAllByExternalID inserts or updates traders by external_id in one transaction
+func (r *TraderRepository) UpsertAllByExternalID(traders []models.Trader) error {
+	defer r.cache.invalidate()
+	return upsertAllByExternalID(r.db, traders)
+}
+
 func (r *TraderRepository) ListAll() ([]models.Trader, error) {
 	var traders []models.Trader
 	err := r.db.Order("id ASC").Find(&traders).Error
 	return traders, err
 }
 
+// UpsertByExternalID doesn't invalidate cached lookups, as sync upserts row by row;
+// call InvalidateCache once done
 func (r *TraderRepository) UpsertByExternalID(trader *models.Trader) error {
 	var existing models.Trader
 	err := r.db.Where("external_id = ?", trader.ExternalID).First(&existing).Error
@@ -1000,29 +3585,39 @@ func (r *TraderRepository) UpsertByExternalID(trader *models.Trader) error {
 
 // Project Repository
 type ProjectRepository struct {
-	db *DB
+	db    *DB
+	cache *entityCacheScope
+}
+
+func NewProjectRepository(db *DB, cache *EntityCache) *ProjectRepository {
+	return &ProjectRepository{db: db, cache: cache.scope("projects")}
 }
 
-func NewProjectRepository(db *DB) *ProjectRepository {
-	return &ProjectRepository{db: db}
+// InvalidateCache drops the cached detail lookups of every project
+func (r *ProjectRepository) InvalidateCache() {
+	r.cache.invalidate()
 }
 
 func (r *ProjectRepository) FindByID(id uint) (*models.Project, error) {
-	var project models.Project
-	err := r.db.First(&project, id).Error
-	if err != nil {
-		return nil, err
-	}
-	return &project, nil
+	return findCached(r.cache, idLookup(id), func() (*models.Project, error) {
+		var project models.Project
+		err := r.db.First(&project, id).Error
+		if err != nil {
+			return nil, err
+		}
+		return &project, nil
+	})
 }
 
 func (r *ProjectRepository) FindByExternalID(externalID string) (*models.Project, error) {
-	var project models.Project
-	err := r.db.Where("external_id = ?", externalID).First(&project).Error
-	if err != nil {
-		return nil, err
-	}
-	return &project, nil
+	return findCached(r.cache, externalIDLookup(externalID), func() (*models.Project, error) {
+		var project models.Project
+		err := r.db.Where("external_id = ?", externalID).First(&project).Error
+		if err != nil {
+			return nil, err
+		}
+		return &project, nil
+	})
 }
 
 func (r *ProjectRepository) FindAll(offset, limit int) ([]models.Project, int64, error) {
@@ -1036,12 +3631,25 @@ func (r *ProjectRepository) FindAll(offset, limit int) ([]models.Project, int64,
 	return projects, count, err
 }
 
+// FindAllBatched calls fn with every project in id order, batchSize at a time
+func (r *ProjectRepository) FindAllBatched(batchSize int, fn func([]models.Project) error) error {
+	return findAllBatched(r.db, batchSize, fn)
+}
+
+// UpsertAllByExternalID inserts or updates projects by external_id in one transaction
+func (r *ProjectRepository) UpsertAllByExternalID(projects []models.Project) error {
+	defer r.cache.invalidate()
+	return upsertAllByExternalID(r.db, projects)
+}
+
 func (r *ProjectRepository) ListAll() ([]models.Project, error) {
 	var projects []models.Project
 	err := r.db.Order("id ASC").Find(&projects).Error
 	return projects, err
 }
 
+// UpsertByExternalID doesn't invalidate cached lookups, as sync upserts row by row;
+// call InvalidateCache once done
 func (r *ProjectRepository) UpsertByExternalID(project *models.Project) error {
 	var existing models.Project
 	err := r.db.Where("external_id = ?", project.ExternalID).First(&existing).Error
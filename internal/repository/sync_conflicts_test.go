@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestConflictSnapshotKeepsContentFields(t *testing.T) {
+	quest := &models.Quest{
+		ID:             12,
+		ExternalID:     "a_bad_feeling",
+		Name:           "A Bad Feeling",
+		XP:             500,
+		Data:           models.JSONB{"id": "a_bad_feeling"},
+		ManualOverride: true,
+		SyncedAt:       time.Now(),
+		Tags:           []string{"event"},
+	}
+
+	snapshot := conflictSnapshot(quest)
+	if snapshot["name"] != "A Bad Feeling" || snapshot["xp"] != float64(500) || snapshot["data"] == nil {
+		t.Errorf("expected the content fields in the snapshot, got %v", snapshot)
+	}
+	for _, field := range []string{"id", "manual_override", "synced_at", "created_at", "updated_at", "tags"} {
+		if _, ok := snapshot[field]; ok {
+			t.Errorf("expected %s to be left out of the snapshot", field)
+		}
+	}
+
+	// Synced at different times, the same upstream row must compare equal
+	later := *quest
+	later.SyncedAt = quest.SyncedAt.Add(time.Hour)
+	if got := conflictSnapshot(&later); !reflect.DeepEqual(got, snapshot) {
+		t.Errorf("expected snapshots of the same row to match, got %v and %v", snapshot, got)
+	}
+}
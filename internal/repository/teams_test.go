@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// recordingConn never reaches a database: recordingPool records where transactions begin
+// and end, and recordingLogger the statements built in between
+type recordingConn struct {
+	statements *[]string
+}
+
+func (p recordingConn) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, nil
+}
+
+func (p recordingConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return nil, nil
+}
+
+func (p recordingConn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, nil
+}
+
+func (p recordingConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return nil
+}
+
+type recordingPool struct {
+	recordingConn
+}
+
+func (p recordingPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	*p.statements = append(*p.statements, "BEGIN")
+	return &recordingTx{p.recordingConn}, nil
+}
+
+type recordingTx struct {
+	recordingConn
+}
+
+func (t *recordingTx) Commit() error {
+	*t.statements = append(*t.statements, "COMMIT")
+	return nil
+}
+
+func (t *recordingTx) Rollback() error {
+	*t.statements = append(*t.statements, "ROLLBACK")
+	return nil
+}
+
+type recordingLogger struct {
+	logger.Interface
+	statements *[]string
+}
+
+func (l recordingLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	*l.statements = append(*l.statements, sql)
+}
+
+func TestTeamJoinLocksTheTeam(t *testing.T) {
+	var statements []string
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: recordingPool{recordingConn{&statements}}}), &gorm.Config{
+		DryRun: true,
+		Logger: recordingLogger{logger.Discard, &statements},
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+
+	if _, err := NewTeamRepository(&DB{db}).Join(4, 9, 10); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+
+	// The team row is locked before anything is counted, and the insert happens in the
+	// same transaction, so a second join waits until the first one is committed
+	if len(statements) != 6 || statements[0] != "BEGIN" || statements[5] != "COMMIT" {
+		t.Fatalf("expected four statements in one transaction, got %q", statements)
+	}
+	if lock := statements[1]; !strings.Contains(lock, `FROM "teams"`) || !strings.HasSuffix(lock, "FOR UPDATE") {
+		t.Errorf("expected the team row to be locked first, got %q", lock)
+	}
+	if !strings.HasPrefix(statements[4], `INSERT INTO "team_members"`) {
+		t.Errorf("expected the member to be inserted last, got %q", statements[4])
+	}
+}
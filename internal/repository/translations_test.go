@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestTranslationFindMatchesSearchLiterally(t *testing.T) {
+	var statements []string
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: recordingPool{recordingConn{&statements}}}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		Logger:                 recordingLogger{logger.Discard, &statements},
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+
+	q := TranslationQuery{EntityType: "item", Langs: []string{"en"}, Search: `100%_pure\`}
+	if _, err := NewTranslationRepository(&DB{db}).Find(q); err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if len(statements) != 1 || !strings.Contains(statements[0], `text ILIKE '%100\%\_pure\\%'`) {
+		t.Errorf("expected the search's wildcards to be escaped, got %q", statements)
+	}
+}
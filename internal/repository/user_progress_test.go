@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestCopyProgressStatements(t *testing.T) {
+	for _, tc := range []struct {
+		mode string
+		want []string
+	}{
+		{CopyModeOverwrite, []string{
+			"BEGIN",
+			"DELETE FROM user_quest_progress WHERE user_id = 2",
+			"INSERT INTO user_quest_progress (user_id, quest_id, completed, created_at, updated_at) SELECT 2, quest_id, completed, NOW(), NOW() FROM user_quest_progress WHERE user_id = 1",
+			"COMMIT",
+		}},
+		{CopyModeMerge, []string{
+			"BEGIN",
+			"INSERT INTO user_quest_progress (user_id, quest_id, completed, created_at, updated_at) SELECT 2, quest_id, completed, NOW(), NOW() FROM user_quest_progress WHERE user_id = 1 ON CONFLICT (user_id, quest_id) DO UPDATE SET completed = EXCLUDED.completed, updated_at = EXCLUDED.updated_at",
+			"COMMIT",
+		}},
+		{CopyModeSkip, []string{
+			"BEGIN",
+			"INSERT INTO user_quest_progress (user_id, quest_id, completed, created_at, updated_at) SELECT 2, quest_id, completed, NOW(), NOW() FROM user_quest_progress WHERE user_id = 1 ON CONFLICT (user_id, quest_id) DO NOTHING",
+			"COMMIT",
+		}},
+	} {
+		var statements []string
+		db, err := gorm.Open(postgres.New(postgres.Config{Conn: recordingPool{recordingConn{&statements}}}), &gorm.Config{
+			DryRun:                 true,
+			SkipDefaultTransaction: true,
+			Logger:                 recordingLogger{logger.Discard, &statements},
+		})
+		if err != nil {
+			t.Fatalf("gorm.Open: %v", err)
+		}
+
+		if _, err := NewUserProgressRepository(&DB{db}).CopyProgress(1, 2, []string{ProgressCategoryQuests}, tc.mode); err != nil {
+			t.Fatalf("CopyProgress(%s): %v", tc.mode, err)
+		}
+		if got := strings.Join(statements, "\n"); got != strings.Join(tc.want, "\n") {
+			t.Errorf("CopyProgress(%s) ran:\n%s\nwant:\n%s", tc.mode, got, strings.Join(tc.want, "\n"))
+		}
+	}
+}
+
+func TestCopyProgressRejectsUnknownCategoryAndMode(t *testing.T) {
+	var statements []string
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: recordingPool{recordingConn{&statements}}}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		Logger:                 recordingLogger{logger.Discard, &statements},
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	repo := NewUserProgressRepository(&DB{db})
+
+	if _, err := repo.CopyProgress(1, 2, []string{"achievements"}, CopyModeSkip); err == nil {
+		t.Error("expected an error for an unknown category")
+	}
+	if _, err := repo.CopyProgress(1, 2, []string{ProgressCategoryQuests}, "replace"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
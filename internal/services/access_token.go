@@ -0,0 +1,194 @@
+package services
+
+import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/mat/arcapi/internal/config"
+	"github.com/mat/arcapi/internal/models"
+)
+
+// accessTokenIssuer is the iss claim of access tokens signed by the API, which tells them
+// apart from Supabase tokens
+const accessTokenIssuer = "arcapi"
+
+var (
+	// ErrNotAccessToken is returned for bearer tokens the API didn't sign, such as Supabase tokens
+	ErrNotAccessToken = errors.New("not an access token issued by this API")
+	// ErrAccessTokenInvalid is returned for access tokens with a bad signature, expired or for unknown users
+	ErrAccessTokenInvalid = errors.New("invalid access token")
+)
+
+// AccessClaims are the claims of an access token signed by the API. Subject holds the user ID.
+type AccessClaims struct {
+	Scope string `json:"scope,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// TokenPair is a short-lived access token and the refresh token that renews it
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // Seconds until the access token expires
+	RefreshToken string `json:"refresh_token"`
+}
+
+// accessTokenSigner signs and verifies access tokens with an HMAC secret
+type accessTokenSigner struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+func newAccessTokenSigner(cfg *config.Config) *accessTokenSigner {
+	secret := []byte(cfg.AccessTokenSecret)
+	if len(secret) == 0 {
+		// Without a configured secret, access tokens are only valid until the process restarts
+		// and on this instance; clients renew them with their refresh token
+		secret = make([]byte, 32)
+		if _, err := crand.Read(secret); err != nil {
+			log.Fatalf("Failed to generate access token secret: %v", err)
+		}
+		log.Println("Warning: ACCESS_TOKEN_SECRET not set, access tokens will not survive restarts or work across instances")
+	}
+
+	ttl := time.Duration(cfg.AccessTokenTTLMinutes) * time.Minute
+	if ttl <= 0 {
+		ttl = 15 * time.Minute
+	}
+
+	return &accessTokenSigner{secret: secret, ttl: ttl}
+}
+
+// sign returns an access token with the given scope for the user, identified by jti
+func (s *accessTokenSigner) sign(userID uint, scope, jti string, now time.Time) (string, time.Time, error) {
+	expiresAt := now.Add(s.ttl)
+	claims := AccessClaims{
+		Scope: scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    accessTokenIssuer,
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			ID:        jti,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	return token, expiresAt, err
+}
+
+// parse verifies an access token, returning ErrNotAccessToken for tokens issued by someone else
+func (s *accessTokenSigner) parse(tokenString string) (*AccessClaims, error) {
+	unverified := &AccessClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, unverified); err != nil || unverified.Issuer != accessTokenIssuer {
+		return nil, ErrNotAccessToken
+	}
+
+	claims := &AccessClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(accessTokenIssuer), jwt.WithExpirationRequired())
+	if err != nil || claims.ID == "" {
+		return nil, ErrAccessTokenInvalid
+	}
+	return claims, nil
+}
+
+// IssueSession starts a session with the given scope for the user: a new refresh token
+// family and an access token
+func (s *AuthService) IssueSession(user *models.User, scope, userAgent, ipAddress string) (*TokenPair, error) {
+	refreshToken, err := s.IssueRefreshToken(user.ID, scope, userAgent, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+	return s.tokenPair(user, scope, refreshToken, userAgent, ipAddress)
+}
+
+// RefreshSession rotates a refresh token: the presented token is revoked and a new one in
+// the same family and scope is returned with a new access token and their user.
+// Presenting a token that was already rotated revokes the entire family and is audit-logged.
+func (s *AuthService) RefreshSession(refreshToken, userAgent, ipAddress string) (*TokenPair, *models.User, error) {
+	newRefreshToken, token, err := s.rotateRefreshToken(refreshToken, userAgent, ipAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := s.userRepo.FindByID(token.UserID)
+	if err != nil {
+		return nil, nil, ErrRefreshTokenInvalid
+	}
+	pair, err := s.tokenPair(user, token.Scope, newRefreshToken, userAgent, ipAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pair, user, nil
+}
+
+// tokenPair signs an access token with the given scope for the user, tracked as a session
+// so it can be listed and revoked like a Supabase token, and pairs it with refreshToken
+func (s *AuthService) tokenPair(user *models.User, scope, refreshToken, userAgent, ipAddress string) (*TokenPair, error) {
+	jti, err := generateOpaqueToken(16)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	accessToken, expiresAt, err := s.accessTokens.sign(user.ID, scope, jti, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.jwtTokenRepo != nil {
+		hash := sha256.Sum256([]byte(accessToken))
+		if err := s.jwtTokenRepo.Create(&models.JWTToken{
+			UserID:     user.ID,
+			JTI:        jti,
+			TokenHash:  hex.EncodeToString(hash[:]),
+			UserAgent:  userAgent,
+			IPAddress:  ipAddress,
+			Scope:      scope,
+			ExpiresAt:  expiresAt,
+			LastUsedAt: &now,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(expiresAt.Sub(now).Seconds()),
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// ValidateAccessToken checks an access token signed by the API and returns its user and
+// session. It returns ErrNotAccessToken for other bearer tokens, so callers can try Supabase next.
+func (s *AuthService) ValidateAccessToken(tokenString, userAgent, ipAddress string) (*models.User, *models.JWTToken, error) {
+	claims, err := s.accessTokens.parse(tokenString)
+	if err != nil {
+		return nil, nil, err
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 32)
+	if err != nil {
+		return nil, nil, ErrAccessTokenInvalid
+	}
+	user, err := s.userRepo.FindByID(uint(userID))
+	if err != nil {
+		return nil, nil, ErrAccessTokenInvalid
+	}
+
+	// Revocation, caching and last use work the same as for Supabase tokens
+	token, err := s.TrackJWT(user, &SupabaseClaims{RegisteredClaims: claims.RegisteredClaims}, tokenString, userAgent, ipAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The session was stored when the token was issued; one tracked only now has lost its scope
+	if token != nil && token.Scope != claims.Scope {
+		return nil, nil, ErrAccessTokenInvalid
+	}
+	return user, token, nil
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/mat/arcapi/internal/config"
+	"github.com/mat/arcapi/internal/models"
+)
+
+// memoryRefreshTokenStore keeps refresh tokens in memory
+type memoryRefreshTokenStore struct {
+	tokens []*models.RefreshToken
+}
+
+func (s *memoryRefreshTokenStore) CreateInFamily(userID uint, plainToken, familyID, scope string, expiry time.Time, userAgent, ipAddress string) (*models.RefreshToken, error) {
+	hash := sha256.Sum256([]byte(plainToken))
+	now := time.Now()
+	rt := &models.RefreshToken{
+		ID:         uint(len(s.tokens) + 1),
+		UserID:     userID,
+		TokenHash:  hex.EncodeToString(hash[:]),
+		FamilyID:   familyID,
+		Scope:      scope,
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+		ExpiresAt:  expiry,
+		LastUsedAt: &now,
+		CreatedAt:  now,
+	}
+	s.tokens = append(s.tokens, rt)
+	return rt, nil
+}
+
+func (s *memoryRefreshTokenStore) FindByPlain(plainToken string) (*models.RefreshToken, error) {
+	hash := sha256.Sum256([]byte(plainToken))
+	for _, rt := range s.tokens {
+		if rt.TokenHash == hex.EncodeToString(hash[:]) {
+			found := *rt
+			return &found, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (s *memoryRefreshTokenStore) FindByID(id uint) (*models.RefreshToken, error) {
+	for _, rt := range s.tokens {
+		if rt.ID == id {
+			found := *rt
+			return &found, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (s *memoryRefreshTokenStore) FindActiveByUserID(userID uint) ([]models.RefreshToken, error) {
+	var active []models.RefreshToken
+	for _, rt := range s.tokens {
+		if rt.UserID == userID && !rt.IsRevoked() && !rt.IsExpired() {
+			active = append(active, *rt)
+		}
+	}
+	return active, nil
+}
+
+func (s *memoryRefreshTokenStore) Revoke(rt *models.RefreshToken) error {
+	_, err := s.RevokeActive(rt)
+	return err
+}
+
+func (s *memoryRefreshTokenStore) RevokeActive(rt *models.RefreshToken) (bool, error) {
+	for _, stored := range s.tokens {
+		if stored.ID == rt.ID && !stored.IsRevoked() {
+			now := time.Now()
+			stored.RevokedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *memoryRefreshTokenStore) RevokeFamily(familyID string) (int64, error) {
+	var revoked int64
+	for _, rt := range s.tokens {
+		if rt.FamilyID == familyID && !rt.IsRevoked() {
+			now := time.Now()
+			rt.RevokedAt = &now
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func newTestAuthService(store *memoryRefreshTokenStore) *AuthService {
+	cfg := &config.Config{AccessTokenSecret: "test-secret", AccessTokenTTLMinutes: 15, RefreshTokenTTLHours: 1}
+	return &AuthService{refreshTokenRepo: store, accessTokens: newAccessTokenSigner(cfg), cfg: cfg}
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	store := &memoryRefreshTokenStore{}
+	s := newTestAuthService(store)
+
+	first, err := s.IssueRefreshToken(7, models.SessionScopeDevice, "tracker/1.0", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("IssueRefreshToken: %v", err)
+	}
+	second, token, err := s.rotateRefreshToken(first, "tracker/1.0", "10.0.0.2")
+	if err != nil {
+		t.Fatalf("rotateRefreshToken: %v", err)
+	}
+	if second == first || token.UserID != 7 {
+		t.Fatalf("expected a new token for user 7, got %q for user %d", second, token.UserID)
+	}
+
+	rotated, _ := store.FindByPlain(second)
+	if rotated.FamilyID != token.FamilyID || rotated.Scope != models.SessionScopeDevice || rotated.IPAddress != "10.0.0.2" || rotated.UserAgent != "tracker/1.0" {
+		t.Errorf("expected the new token in the same family and scope with the request's device, got %+v", rotated)
+	}
+	if old, _ := store.FindByPlain(first); !old.IsRevoked() {
+		t.Error("expected the presented token to be revoked")
+	}
+
+	if _, _, err := s.rotateRefreshToken("unknown", "", ""); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Errorf("unknown token: got %v, want ErrRefreshTokenInvalid", err)
+	}
+}
+
+func TestRotateRefreshTokenReuseRevokesFamily(t *testing.T) {
+	store := &memoryRefreshTokenStore{}
+	s := newTestAuthService(store)
+
+	first, _ := s.IssueRefreshToken(7, "", "", "")
+	second, _, err := s.rotateRefreshToken(first, "", "")
+	if err != nil {
+		t.Fatalf("rotateRefreshToken: %v", err)
+	}
+	other, _ := s.IssueRefreshToken(7, "", "", "")
+
+	// The first token was already rotated, so presenting it again is a replay
+	if _, _, err := s.rotateRefreshToken(first, "", ""); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reused token: got %v, want ErrRefreshTokenReused", err)
+	}
+	if _, _, err := s.rotateRefreshToken(second, "", ""); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Errorf("expected the rest of the family to be revoked, got %v", err)
+	}
+	if _, _, err := s.rotateRefreshToken(other, "", ""); err != nil {
+		t.Errorf("expected other logins to keep working, got %v", err)
+	}
+}
+
+func TestAccessTokenSignAndParse(t *testing.T) {
+	signer := newAccessTokenSigner(&config.Config{AccessTokenSecret: "test-secret", AccessTokenTTLMinutes: 15})
+
+	token, expiresAt, err := signer.sign(42, models.SessionScopeDevice, "jti-1", time.Now())
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if until := time.Until(expiresAt); until <= 14*time.Minute || until > 15*time.Minute {
+		t.Errorf("expected the token to expire in 15 minutes, got %s", until)
+	}
+	claims, err := signer.parse(token)
+	if err != nil || claims.Subject != "42" || claims.ID != "jti-1" || claims.Scope != models.SessionScopeDevice {
+		t.Fatalf("parse() = %+v, %v", claims, err)
+	}
+
+	other := newAccessTokenSigner(&config.Config{AccessTokenSecret: "other-secret"})
+	if _, err := other.parse(token); !errors.Is(err, ErrAccessTokenInvalid) {
+		t.Errorf("token signed with another secret: got %v, want ErrAccessTokenInvalid", err)
+	}
+
+	expired, _, _ := signer.sign(42, "", "jti-2", time.Now().Add(-time.Hour))
+	if _, err := signer.parse(expired); !errors.Is(err, ErrAccessTokenInvalid) {
+		t.Errorf("expired token: got %v, want ErrAccessTokenInvalid", err)
+	}
+
+	foreign, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Issuer: "https://example.supabase.co/auth/v1", Subject: "42"}).SignedString([]byte("test-secret"))
+	if _, err := signer.parse(foreign); !errors.Is(err, ErrNotAccessToken) {
+		t.Errorf("token from another issuer: got %v, want ErrNotAccessToken", err)
+	}
+}
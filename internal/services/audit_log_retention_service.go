@@ -0,0 +1,168 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+const JobAuditLogPrune = "audit_log_prune"
+
+// auditLogPruneBatchSize is how many audit logs are archived and deleted at a time
+const auditLogPruneBatchSize = 5000
+
+// auditLogArchiveTimeout bounds writing a single archive file
+const auditLogArchiveTimeout = 5 * time.Minute
+
+// AuditLogArchive is a destination for the archive files of pruned audit logs
+type AuditLogArchive struct {
+	Name  string // Where archives go, e.g. the directory or bucket prefix
+	store func(ctx context.Context, name string, data []byte) error
+}
+
+// NewAuditLogDirArchive writes archive files to dir, creating it if needed
+func NewAuditLogDirArchive(dir string) AuditLogArchive {
+	return AuditLogArchive{
+		Name: dir,
+		store: func(ctx context.Context, name string, data []byte) error {
+			if err := os.MkdirAll(dir, 0o750); err != nil {
+				return err
+			}
+			return os.WriteFile(filepath.Join(dir, name), data, 0o640)
+		},
+	}
+}
+
+// NewAuditLogS3Archive uploads archive files to object storage under prefix
+func NewAuditLogS3Archive(uploader *S3Uploader, bucket, prefix string) AuditLogArchive {
+	return AuditLogArchive{
+		Name: "s3://" + bucket + "/" + prefix,
+		store: func(ctx context.Context, name string, data []byte) error {
+			hash := sha256.Sum256(data)
+			return uploader.Put(ctx, prefix+name, bytes.NewReader(data), int64(len(data)), hex.EncodeToString(hash[:]), "application/gzip")
+		},
+	}
+}
+
+// AuditLogRetentionStats is the size of the audit log table and what the retention
+// policy will prune from it
+type AuditLogRetentionStats struct {
+	repository.AuditLogStats
+	RetentionDays int      `json:"retention_days" example:"180"`
+	PrunableRows  int64    `json:"prunable_rows" example:"1200"`
+	ArchivedTo    []string `json:"archived_to"`
+}
+
+// AuditLogRetentionService keeps the audit_logs table LoggerMiddleware writes to from
+// growing without bound, deleting rows older than the retention period after archiving
+// them as gzipped JSON lines
+type AuditLogRetentionService struct {
+	repo          *repository.AuditLogRepository
+	retentionDays int
+	archives      []AuditLogArchive
+}
+
+func NewAuditLogRetentionService(repo *repository.AuditLogRepository, retentionDays int, archives ...AuditLogArchive) *AuditLogRetentionService {
+	return &AuditLogRetentionService{repo: repo, retentionDays: retentionDays, archives: archives}
+}
+
+// Prune is the audit_log_prune job: it archives and deletes audit logs older than the
+// retention period a batch at a time, so a failed archive leaves the rest in place
+func (s *AuditLogRetentionService) Prune(startedAt time.Time) (int64, error) {
+	if s.retentionDays <= 0 {
+		return 0, nil
+	}
+	cutoff := startedAt.AddDate(0, 0, -s.retentionDays)
+
+	var pruned int64
+	for batch := 1; ; batch++ {
+		logs, err := s.repo.FindBefore(cutoff, auditLogPruneBatchSize)
+		if err != nil {
+			return pruned, err
+		}
+		if len(logs) == 0 {
+			return pruned, nil
+		}
+
+		if len(s.archives) > 0 {
+			data, err := encodeAuditLogArchive(logs)
+			if err != nil {
+				return pruned, err
+			}
+			name := auditLogArchiveName(startedAt, batch)
+			for _, archive := range s.archives {
+				ctx, cancel := context.WithTimeout(context.Background(), auditLogArchiveTimeout)
+				err := archive.store(ctx, name, data)
+				cancel()
+				if err != nil {
+					return pruned, fmt.Errorf("failed to archive audit logs to %s: %w", archive.Name, err)
+				}
+			}
+			log.Printf("Archived %d audit logs as %s", len(logs), name)
+		}
+
+		ids := make([]uint, len(logs))
+		for i, l := range logs {
+			ids[i] = l.ID
+		}
+		deleted, err := s.repo.DeleteByIDs(ids)
+		if err != nil {
+			return pruned, err
+		}
+		pruned += deleted
+	}
+}
+
+// Stats reports the audit log table's size and how much of it the next prune removes
+func (s *AuditLogRetentionService) Stats(now time.Time) (*AuditLogRetentionStats, error) {
+	stats, err := s.repo.Stats()
+	if err != nil {
+		return nil, err
+	}
+	result := &AuditLogRetentionStats{
+		AuditLogStats: *stats,
+		RetentionDays: s.retentionDays,
+		ArchivedTo:    make([]string, 0, len(s.archives)),
+	}
+	if s.retentionDays > 0 {
+		if result.PrunableRows, err = s.repo.CountBefore(now.AddDate(0, 0, -s.retentionDays)); err != nil {
+			return nil, err
+		}
+	}
+	for _, archive := range s.archives {
+		result.ArchivedTo = append(result.ArchivedTo, archive.Name)
+	}
+	return result, nil
+}
+
+// auditLogArchiveName names the archive of one batch of a prune run
+func auditLogArchiveName(startedAt time.Time, batch int) string {
+	return fmt.Sprintf("audit-logs-%s-%04d.jsonl.gz", startedAt.UTC().Format("20060102-150405"), batch)
+}
+
+// encodeAuditLogArchive writes logs as gzipped JSON lines, one audit log per line
+func encodeAuditLogArchive(logs []models.AuditLog) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(gz)
+	for _, l := range logs {
+		if err := encoder.Encode(l); err != nil {
+			return nil, err
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestAuditLogArchive(t *testing.T) {
+	logs := []models.AuditLog{
+		{ID: 1, Endpoint: "/api/v1/quests", Method: "GET", StatusCode: 200},
+		{ID: 2, Endpoint: "/api/v1/items/4", Method: "PUT", StatusCode: 204},
+	}
+	data, err := encodeAuditLogArchive(logs)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "audit")
+	name := auditLogArchiveName(time.Date(2026, 10, 15, 3, 30, 0, 0, time.FixedZone("CEST", 2*60*60)), 2)
+	if name != "audit-logs-20261015-013000-0002.jsonl.gz" {
+		t.Errorf("unexpected archive name %s", name)
+	}
+	if err := NewAuditLogDirArchive(dir).store(context.Background(), name, data); err != nil {
+		t.Fatalf("store failed: %v", err)
+	}
+
+	file, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatalf("archive not written: %v", err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("archive is not gzipped: %v", err)
+	}
+	var ids []uint
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var l models.AuditLog
+		if err := json.Unmarshal(scanner.Bytes(), &l); err != nil {
+			t.Fatalf("line is not an audit log: %v", err)
+		}
+		ids = append(ids, l.ID)
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("expected one line per audit log, got ids %v", ids)
+	}
+}
@@ -1,12 +1,18 @@
 package services
 
 import (
+	"context"
 	crand "crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
 	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,18 +28,36 @@ type AuthService struct {
 	apiKeyRepo       *repository.APIKeyRepository
 	jwtTokenRepo     *repository.JWTTokenRepository
 	authCodeRepo     *repository.AuthorizationCodeRepository
-	refreshTokenRepo *repository.RefreshTokenRepository
+	refreshTokenRepo RefreshTokenStore
+	auditLogRepo     *repository.AuditLogRepository
 	cacheService     *CacheService
+	rbacService      *RBACService
+	accessTokens     *accessTokenSigner
+	hooks            *HookRegistry
 	cfg              *config.Config
 }
 
+// RefreshTokenStore persists refresh tokens, implemented by repository.RefreshTokenRepository
+type RefreshTokenStore interface {
+	CreateInFamily(userID uint, plainToken, familyID, scope string, expiry time.Time, userAgent, ipAddress string) (*models.RefreshToken, error)
+	FindByPlain(plainToken string) (*models.RefreshToken, error)
+	FindByID(id uint) (*models.RefreshToken, error)
+	FindActiveByUserID(userID uint) ([]models.RefreshToken, error)
+	Revoke(rt *models.RefreshToken) error
+	RevokeActive(rt *models.RefreshToken) (bool, error)
+	RevokeFamily(familyID string) (int64, error)
+}
+
 func NewAuthService(
 	userRepo *repository.UserRepository,
 	apiKeyRepo *repository.APIKeyRepository,
 	jwtTokenRepo *repository.JWTTokenRepository,
 	authCodeRepo *repository.AuthorizationCodeRepository,
 	refreshTokenRepo *repository.RefreshTokenRepository,
+	auditLogRepo *repository.AuditLogRepository,
 	cacheService *CacheService,
+	hooks *HookRegistry,
+	rbacService *RBACService,
 	cfg *config.Config,
 ) *AuthService {
 	return &AuthService{
@@ -42,7 +66,11 @@ func NewAuthService(
 		jwtTokenRepo:     jwtTokenRepo,
 		authCodeRepo:     authCodeRepo,
 		refreshTokenRepo: refreshTokenRepo,
+		auditLogRepo:     auditLogRepo,
 		cacheService:     cacheService,
+		rbacService:      rbacService,
+		accessTokens:     newAccessTokenSigner(cfg),
+		hooks:            hooks,
 		cfg:              cfg,
 	}
 }
@@ -124,8 +152,9 @@ func (s *AuthService) ValidateAPIKey(apiKey string) (*models.APIKey, error) {
 
 // JWT validation is now handled via SupabaseAuthService
 
-// SyncSupabaseUser ensures there is a local user matching the Supabase identity
-func (s *AuthService) SyncSupabaseUser(claims *SupabaseClaims) (*models.User, error) {
+// SyncSupabaseUser ensures there is a local user matching the Supabase identity. ctx is
+// the request's, so claim mapping hooks are cancelled with it.
+func (s *AuthService) SyncSupabaseUser(ctx context.Context, claims *SupabaseClaims) (*models.User, error) {
 	if claims == nil {
 		return nil, fmt.Errorf("supabase claims missing")
 	}
@@ -155,7 +184,7 @@ func (s *AuthService) SyncSupabaseUser(claims *SupabaseClaims) (*models.User, er
 		wasUpdated = true
 	}
 
-	// Check if user is an admin in Supabase
+	// Check if user is an admin in Supabase. The metadata only ever promotes.
 	isAdmin := false
 	if role, ok := claims.AppMetadata["role"].(string); ok && role == "admin" {
 		isAdmin = true
@@ -170,11 +199,27 @@ func (s *AuthService) SyncSupabaseUser(claims *SupabaseClaims) (*models.User, er
 		log.Printf("DEBUG: User %s metadata - AppMetadata: %v, UserMetadata: %v", user.Email, claims.AppMetadata, claims.UserMetadata)
 	}
 
-	// Promote to admin if specified in Supabase and not already admin
-	if isAdmin && user.Role != models.RoleAdmin {
-		user.Role = models.RoleAdmin
+	role := ""
+	if isAdmin {
+		role = string(models.RoleAdmin)
+	}
+
+	// Claim mapping hooks can override the role derived from the metadata with any defined role
+	mapped, err := s.hooks.MapClaims(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if mapped != "" {
+		if !s.rbacService.RoleExists(mapped) {
+			return nil, fmt.Errorf("claim mapping hook returned unknown role %q", mapped)
+		}
+		role = mapped
+	}
+
+	if role != "" && user.Role != models.UserRole(role) {
+		log.Printf("Changing role of user %s from %s to %s based on Supabase metadata or claim mapping hook", user.Email, user.Role, role)
+		user.Role = models.UserRole(role)
 		wasUpdated = true
-		log.Printf("Promoting user %s to admin based on Supabase metadata", user.Email)
 	}
 
 	if wasUpdated {
@@ -252,6 +297,11 @@ func sanitizeUsername(input string) string {
 
 // CreateAPIKey creates a new API key for a user
 func (s *AuthService) CreateAPIKey(userID uint, name string) (string, error) {
+	return s.CreateAPIKeyWithScopes(userID, name, nil)
+}
+
+// CreateAPIKeyWithScopes creates an API key granted the given scopes
+func (s *AuthService) CreateAPIKeyWithScopes(userID uint, name string, scopes []string) (string, error) {
 	key, hashed, err := s.GenerateAPIKey()
 	if err != nil {
 		return "", err
@@ -261,6 +311,7 @@ func (s *AuthService) CreateAPIKey(userID uint, name string) (string, error) {
 		UserID:  userID,
 		KeyHash: hashed,
 		Name:    name,
+		Scopes:  scopes,
 	}
 
 	err = s.apiKeyRepo.Create(apiKey)
@@ -303,3 +354,314 @@ func (s *AuthService) UpdateUserRole(user *models.User, newRole models.UserRole)
 	user.Role = newRole
 	return s.userRepo.Update(user)
 }
+
+// ErrTokenRevoked is returned when a JWT has been revoked by its owner or an admin
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// jwtTouchInterval limits how often last_used_at is written for an active JWT
+const jwtTouchInterval = time.Minute
+
+// lastSeenTouchInterval limits how often last_seen_at is written for an active user
+const lastSeenTouchInterval = 5 * time.Minute
+
+// TouchLastSeen records that user made a request. The write happens in the background and
+// at most once per lastSeenTouchInterval, so it adds nothing to request latency.
+func (s *AuthService) TouchLastSeen(user *models.User) {
+	if user.LastSeenAt != nil && time.Since(*user.LastSeenAt) < lastSeenTouchInterval {
+		return
+	}
+	now := time.Now()
+	user.LastSeenAt = &now
+	go s.userRepo.TouchLastSeen(user.ID, lastSeenTouchInterval)
+}
+
+// TrackJWT records a validated Supabase token as a session keyed by its jti and
+// rejects it if that jti has been revoked. Tokens without any identifier are not tracked.
+func (s *AuthService) TrackJWT(user *models.User, claims *SupabaseClaims, tokenString, userAgent, ipAddress string) (*models.JWTToken, error) {
+	jti := claims.TokenID()
+	if jti == "" || s.jwtTokenRepo == nil {
+		return nil, nil
+	}
+
+	cacheKey := JWTCacheKey(jti)
+	if s.cacheService != nil {
+		var cached models.JWTToken
+		if err := s.cacheService.GetJSON(cacheKey, &cached); err == nil && cached.ID > 0 && cached.UserID == user.ID {
+			if cached.IsRevoked() {
+				return nil, ErrTokenRevoked
+			}
+			return &cached, nil
+		}
+	}
+
+	token, err := s.jwtTokenRepo.FindByJTI(jti)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if token == nil || token.UserID != user.ID {
+		hash := sha256.Sum256([]byte(tokenString))
+		expiresAt := time.Now().Add(time.Hour)
+		if claims.ExpiresAt != nil {
+			expiresAt = claims.ExpiresAt.Time
+		}
+		now := time.Now()
+		token = &models.JWTToken{
+			UserID:     user.ID,
+			JTI:        jti,
+			TokenHash:  hex.EncodeToString(hash[:]),
+			UserAgent:  userAgent,
+			IPAddress:  ipAddress,
+			ExpiresAt:  expiresAt,
+			LastUsedAt: &now,
+		}
+		if err := s.jwtTokenRepo.Create(token); err != nil {
+			return nil, err
+		}
+	} else {
+		if token.IsRevoked() {
+			return nil, ErrTokenRevoked
+		}
+		if token.LastUsedAt == nil || time.Since(*token.LastUsedAt) > jwtTouchInterval {
+			go s.jwtTokenRepo.Touch(token.ID)
+		}
+	}
+
+	if s.cacheService != nil {
+		s.cacheService.SetJSON(cacheKey, token, jwtTouchInterval)
+	}
+
+	return token, nil
+}
+
+// RevokeJWTByJTI revokes exactly the token(s) identified by jti, leaving the
+// user's other sessions untouched. Returns ErrSessionNotFound if nothing was revoked.
+func (s *AuthService) RevokeJWTByJTI(jti string) error {
+	revoked, err := s.jwtTokenRepo.RevokeByJTI(jti)
+	if err != nil {
+		return err
+	}
+	if revoked == 0 {
+		return ErrSessionNotFound
+	}
+	if s.cacheService != nil {
+		s.cacheService.Delete(JWTCacheKey(jti))
+	}
+	return nil
+}
+
+// ErrRefreshTokenInvalid is returned for unknown or expired refresh tokens
+var ErrRefreshTokenInvalid = errors.New("invalid refresh token")
+
+// ErrRefreshTokenReused is returned when an already-rotated refresh token is presented again.
+// The whole token family is revoked when this happens.
+var ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+
+// IssueRefreshToken starts a new refresh token family with the given scope for the user and
+// returns the plain token
+func (s *AuthService) IssueRefreshToken(userID uint, scope, userAgent, ipAddress string) (string, error) {
+	familyID, err := generateOpaqueToken(16)
+	if err != nil {
+		return "", err
+	}
+	return s.issueRefreshTokenInFamily(userID, familyID, scope, userAgent, ipAddress)
+}
+
+// rotateRefreshToken revokes the presented token and issues the next one in its family,
+// returning the new plain token and the presented token's row
+func (s *AuthService) rotateRefreshToken(plainToken, userAgent, ipAddress string) (string, *models.RefreshToken, error) {
+	token, err := s.refreshTokenRepo.FindByPlain(plainToken)
+	if err != nil {
+		return "", nil, ErrRefreshTokenInvalid
+	}
+
+	if token.IsRevoked() {
+		s.handleRefreshTokenReuse(token, ipAddress)
+		return "", nil, ErrRefreshTokenReused
+	}
+	if token.IsExpired() {
+		return "", nil, ErrRefreshTokenInvalid
+	}
+
+	// Only one of two concurrent uses of the same token gets to rotate it; the other is a reuse
+	revoked, err := s.refreshTokenRepo.RevokeActive(token)
+	if err != nil {
+		return "", nil, err
+	}
+	if !revoked {
+		s.handleRefreshTokenReuse(token, ipAddress)
+		return "", nil, ErrRefreshTokenReused
+	}
+
+	familyID := token.FamilyID
+	if familyID == "" {
+		// Tokens issued before families existed start their own family on first rotation
+		familyID = token.TokenHash
+	}
+	newToken, err := s.issueRefreshTokenInFamily(token.UserID, familyID, token.Scope, userAgent, ipAddress)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return newToken, token, nil
+}
+
+func (s *AuthService) issueRefreshTokenInFamily(userID uint, familyID, scope, userAgent, ipAddress string) (string, error) {
+	plain, err := generateOpaqueToken(32)
+	if err != nil {
+		return "", err
+	}
+	ttl := time.Duration(s.cfg.RefreshTokenTTLHours) * time.Hour
+	if ttl <= 0 {
+		ttl = 30 * 24 * time.Hour
+	}
+	if _, err := s.refreshTokenRepo.CreateInFamily(userID, plain, familyID, scope, time.Now().Add(ttl), userAgent, ipAddress); err != nil {
+		return "", err
+	}
+	return plain, nil
+}
+
+// handleRefreshTokenReuse revokes the token's family and records the event in the audit log
+func (s *AuthService) handleRefreshTokenReuse(token *models.RefreshToken, ipAddress string) {
+	var revoked int64
+	if token.FamilyID != "" {
+		var err error
+		revoked, err = s.refreshTokenRepo.RevokeFamily(token.FamilyID)
+		if err != nil {
+			log.Printf("Failed to revoke refresh token family %s: %v", token.FamilyID, err)
+		}
+	}
+	log.Printf("Refresh token reuse detected for user %d (family %s), revoked %d token(s)", token.UserID, token.FamilyID, revoked)
+
+	if s.auditLogRepo == nil {
+		return
+	}
+	userID := token.UserID
+	details := models.JSONB{
+		"event":          "refresh_token_reuse",
+		"family_id":      token.FamilyID,
+		"token_id":       token.ID,
+		"revoked_tokens": revoked,
+	}
+	if err := s.auditLogRepo.Create(&models.AuditLog{
+		UserID:      &userID,
+		Endpoint:    "/auth/refresh",
+		Method:      http.MethodPost,
+		StatusCode:  http.StatusUnauthorized,
+		RequestBody: &details,
+		IPAddress:   ipAddress,
+	}); err != nil {
+		log.Printf("Failed to write refresh token reuse audit log: %v", err)
+	}
+}
+
+func generateOpaqueToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := crand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// ErrSessionNotFound is returned when a session ID does not match an active session owned by the user
+var ErrSessionNotFound = errors.New("session not found")
+
+const (
+	SessionTypeJWT     = "jwt"
+	SessionTypeRefresh = "refresh"
+)
+
+// Session describes an active JWT or refresh token belonging to a user
+type Session struct {
+	ID         string     `json:"id"`   // "<type>-<row id>", e.g. "jwt-12" or "refresh-4"
+	Type       string     `json:"type"` // "jwt" or "refresh"
+	Device     string     `json:"device,omitempty"`
+	IPAddress  string     `json:"ip_address,omitempty"`
+	Scope      string     `json:"scope,omitempty"` // "device" for sessions of devices logged in with a user code
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	IssuedAt   time.Time  `json:"issued_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+}
+
+// ListSessions returns all active JWTs and refresh tokens for a user, newest first
+func (s *AuthService) ListSessions(userID uint) ([]Session, error) {
+	jwts, err := s.jwtTokenRepo.FindActiveByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	refreshTokens, err := s.refreshTokenRepo.FindActiveByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, 0, len(jwts)+len(refreshTokens))
+	for _, t := range jwts {
+		sessions = append(sessions, Session{
+			ID:         fmt.Sprintf("%s-%d", SessionTypeJWT, t.ID),
+			Type:       SessionTypeJWT,
+			Device:     t.UserAgent,
+			IPAddress:  t.IPAddress,
+			Scope:      t.Scope,
+			LastUsedAt: t.LastUsedAt,
+			IssuedAt:   t.CreatedAt,
+			ExpiresAt:  t.ExpiresAt,
+		})
+	}
+	for _, t := range refreshTokens {
+		sessions = append(sessions, Session{
+			ID:         fmt.Sprintf("%s-%d", SessionTypeRefresh, t.ID),
+			Type:       SessionTypeRefresh,
+			Device:     t.UserAgent,
+			IPAddress:  t.IPAddress,
+			Scope:      t.Scope,
+			LastUsedAt: t.LastUsedAt,
+			IssuedAt:   t.CreatedAt,
+			ExpiresAt:  t.ExpiresAt,
+		})
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].IssuedAt.After(sessions[j].IssuedAt)
+	})
+
+	return sessions, nil
+}
+
+// RevokeSession revokes a single session owned by the user.
+// Returns ErrSessionNotFound if the ID is malformed, unknown, or belongs to another user.
+func (s *AuthService) RevokeSession(userID uint, sessionID string) error {
+	sessionType, idStr, ok := strings.Cut(sessionID, "-")
+	if !ok {
+		return ErrSessionNotFound
+	}
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		return ErrSessionNotFound
+	}
+
+	switch sessionType {
+	case SessionTypeJWT:
+		token, err := s.jwtTokenRepo.FindByID(uint(id))
+		if err != nil || token.UserID != userID || token.IsRevoked() {
+			return ErrSessionNotFound
+		}
+		if err := s.jwtTokenRepo.Revoke(token.ID); err != nil {
+			return err
+		}
+		if s.cacheService != nil && token.JTI != "" {
+			s.cacheService.Delete(JWTCacheKey(token.JTI))
+		}
+	case SessionTypeRefresh:
+		token, err := s.refreshTokenRepo.FindByID(uint(id))
+		if err != nil || token.UserID != userID || token.IsRevoked() {
+			return ErrSessionNotFound
+		}
+		if err := s.refreshTokenRepo.Revoke(token); err != nil {
+			return err
+		}
+	default:
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
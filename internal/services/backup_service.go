@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+const JobBackup = "backup"
+
+// backupUploadTimeout bounds a single archive upload
+const backupUploadTimeout = 30 * time.Minute
+
+// BackupService uploads the full data export to object storage for offsite backups of
+// community-curated edits. The archive is built in a temporary file first so its size
+// and checksum are known before the upload starts.
+type BackupService struct {
+	archive  func(w io.Writer) error
+	uploader *S3Uploader
+	prefix   string
+}
+
+func NewBackupService(archive func(w io.Writer) error, uploader *S3Uploader, prefix string) *BackupService {
+	return &BackupService{archive: archive, uploader: uploader, prefix: prefix}
+}
+
+// Run is the backup job: it uploads the archive as <prefix>arcapi-export-<time>.zip and
+// returns its size in bytes
+func (s *BackupService) Run(startedAt time.Time) (int64, error) {
+	file, err := os.CreateTemp("", "arcapi-backup-*.zip")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	hash := sha256.New()
+	if err := s.archive(io.MultiWriter(file, hash)); err != nil {
+		return 0, fmt.Errorf("failed to build backup archive: %w", err)
+	}
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), backupUploadTimeout)
+	defer cancel()
+	key := s.prefix + "arcapi-export-" + startedAt.UTC().Format("20060102-150405") + ".zip"
+	if err := s.uploader.Put(ctx, key, file, size, hex.EncodeToString(hash.Sum(nil)), "application/zip"); err != nil {
+		return 0, err
+	}
+
+	log.Printf("Uploaded backup %s (%d bytes)", key, size)
+	return size, nil
+}
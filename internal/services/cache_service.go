@@ -98,6 +98,11 @@ func (s *CacheService) Set(key string, value []byte, ttl time.Duration) error {
 	return s.client.Set(s.ctx, key, value, ttl).Err()
 }
 
+// Incr increments the integer at key, starting from 0 if it doesn't exist
+func (s *CacheService) Incr(key string) (int64, error) {
+	return s.client.Incr(s.ctx, key).Result()
+}
+
 func (s *CacheService) GetJSON(key string, dest interface{}) error {
 	val, err := s.Get(key)
 	if err != nil {
@@ -158,3 +163,7 @@ func JWTCacheKey(hash string) string {
 func DataCacheKey(entity, key string) string {
 	return fmt.Sprintf("data:%s:%s", entity, key)
 }
+
+func LeaderboardCacheKey(boardType string, page, limit int) string {
+	return fmt.Sprintf("leaderboard:%s:%d:%d", boardType, page, limit)
+}
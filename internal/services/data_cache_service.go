@@ -1,11 +1,10 @@
 package services
 
 import (
-	"fmt"
-	"log"
 	"sync"
 	"time"
 
+	"github.com/mat/arcapi/internal/logging"
 	"github.com/mat/arcapi/internal/models"
 	"github.com/mat/arcapi/internal/repository"
 )
@@ -17,6 +16,8 @@ const (
 	dataRefreshInterval = 15 * time.Minute
 )
 
+var dataCacheLog = logging.For("data_cache")
+
 type DataCacheService struct {
 	cacheService      *CacheService
 	itemRepo          *repository.ItemRepository
@@ -44,7 +45,7 @@ func (s *DataCacheService) Start() {
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
-				log.Printf("PANIC recovered in initial refreshItems: %v", r)
+				dataCacheLog.Error("Panic recovered", "task", "initial refreshItems", "panic", r)
 			}
 		}()
 		s.refreshItems()
@@ -53,7 +54,7 @@ func (s *DataCacheService) Start() {
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
-				log.Printf("PANIC recovered in initial refreshQuests: %v", r)
+				dataCacheLog.Error("Panic recovered", "task", "initial refreshQuests", "panic", r)
 			}
 		}()
 		s.refreshQuests()
@@ -64,7 +65,7 @@ func (s *DataCacheService) Start() {
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
-				log.Printf("PANIC recovered in cache refresh ticker: %v", r)
+				dataCacheLog.Error("Panic recovered", "task", "cache refresh ticker", "panic", r)
 			}
 		}()
 		for range ticker.C {
@@ -72,7 +73,7 @@ func (s *DataCacheService) Start() {
 			func() {
 				defer func() {
 					if r := recover(); r != nil {
-						log.Printf("PANIC recovered in periodic refreshItems: %v", r)
+						dataCacheLog.Error("Panic recovered", "task", "periodic refreshItems", "panic", r)
 					}
 				}()
 				s.refreshItems()
@@ -81,7 +82,7 @@ func (s *DataCacheService) Start() {
 			func() {
 				defer func() {
 					if r := recover(); r != nil {
-						log.Printf("PANIC recovered in periodic refreshQuests: %v", r)
+						dataCacheLog.Error("Panic recovered", "task", "periodic refreshQuests", "panic", r)
 					}
 				}()
 				s.refreshQuests()
@@ -98,18 +99,18 @@ func (s *DataCacheService) refreshItems() {
 	// Fetch all items (with a large limit to get all)
 	items, _, err := s.itemRepo.FindAll(0, 100000)
 	if err != nil {
-		fmt.Printf("Failed to fetch items for cache: %v\n", err)
+		dataCacheLog.Error("Failed to fetch items for cache", "error", err)
 		return
 	}
 
 	// Cache the items
 	if err := s.cacheService.SetJSON(itemsCacheKey, items, dataCacheTTL); err != nil {
-		fmt.Printf("Failed to cache items: %v\n", err)
+		dataCacheLog.Error("Failed to cache items", "error", err)
 		return
 	}
 
 	s.lastItemsRefresh = time.Now()
-	fmt.Printf("Successfully refreshed items cache at %s (%d items)\n", s.lastItemsRefresh.Format(time.RFC3339), len(items))
+	dataCacheLog.Info("Refreshed items cache", "items", len(items))
 }
 
 // refreshQuests fetches all quests from database and caches them
@@ -120,18 +121,18 @@ func (s *DataCacheService) refreshQuests() {
 	// Fetch all quests
 	quests, _, err := s.questRepo.FindAll(0, 100000)
 	if err != nil {
-		fmt.Printf("Failed to fetch quests for cache: %v\n", err)
+		dataCacheLog.Error("Failed to fetch quests for cache", "error", err)
 		return
 	}
 
 	// Cache the quests
 	if err := s.cacheService.SetJSON(questsCacheKey, quests, dataCacheTTL); err != nil {
-		fmt.Printf("Failed to cache quests: %v\n", err)
+		dataCacheLog.Error("Failed to cache quests", "error", err)
 		return
 	}
 
 	s.lastQuestsRefresh = time.Now()
-	fmt.Printf("Successfully refreshed quests cache at %s (%d quests)\n", s.lastQuestsRefresh.Format(time.RFC3339), len(quests))
+	dataCacheLog.Info("Refreshed quests cache", "quests", len(quests))
 }
 
 // GetItems returns cached items or fetches from database
@@ -166,7 +167,7 @@ func (s *DataCacheService) GetItems(offset, limit int) ([]models.Item, int64, er
 		go func() {
 			defer func() {
 				if r := recover(); r != nil {
-					log.Printf("PANIC recovered in background refreshItems: %v", r)
+					dataCacheLog.Error("Panic recovered", "task", "background refreshItems", "panic", r)
 				}
 			}()
 			s.refreshItems()
@@ -197,7 +198,7 @@ func (s *DataCacheService) GetQuests() ([]models.Quest, int64, error) {
 		go func() {
 			defer func() {
 				if r := recover(); r != nil {
-					log.Printf("PANIC recovered in background refreshQuests: %v", r)
+					dataCacheLog.Error("Panic recovered", "task", "background refreshQuests", "panic", r)
 				}
 			}()
 			s.refreshQuests()
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/mat/arcapi/internal/config"
+)
+
+// DataSource fetches the data repository sync reads game data from
+type DataSource interface {
+	// Fetch returns the repository as a zip archive with a single root directory, like a
+	// GitHub zipball, and the version of the data in it such as a commit SHA, or "" if the
+	// source can't tell
+	Fetch(ctx context.Context) (archive []byte, version string, err error)
+}
+
+// newDataSource returns the source configured by SYNC_SOURCE. The settings are validated
+// at startup by config.GetSyncSource; an invalid source falls back to GitHub.
+func newDataSource(cfg *config.Config, githubClient *github.Client) DataSource {
+	source, _ := cfg.GetSyncSource()
+	switch source {
+	case config.SyncSourceDir:
+		return &dirDataSource{dir: cfg.SyncSourceDir}
+	case config.SyncSourceURL:
+		return &urlDataSource{url: cfg.SyncSourceURL, client: &http.Client{}}
+	case config.SyncSourceS3:
+		endpoint, _ := cfg.GetSyncS3Endpoint()
+		return &s3DataSource{
+			bucket: NewS3Uploader(endpoint, cfg.SyncS3Region, cfg.SyncS3Bucket, cfg.SyncS3AccessKeyID, cfg.SyncS3SecretAccessKey),
+			key:    cfg.SyncS3Key,
+		}
+	}
+	return &githubDataSource{
+		client: githubClient,
+		owner:  cfg.SyncRepoOwner,
+		repo:   cfg.SyncRepoName,
+		branch: cfg.SyncRepoBranch,
+	}
+}
+
+// githubDataSource downloads the zipball of a branch of a GitHub repository
+type githubDataSource struct {
+	client              *github.Client
+	owner, repo, branch string
+}
+
+func (s *githubDataSource) Fetch(ctx context.Context) ([]byte, string, error) {
+	// Download the commit the branch points at, so the version matches the data
+	ref := s.branch
+	sha, err := s.latestSHA(ctx)
+	if err != nil {
+		syncLog.Warn("Could not get latest SHA, proceeding with sync anyway", "error", err)
+	} else {
+		syncLog.Info("Latest repository SHA", "sha", sha)
+		ref = sha
+	}
+
+	url, _, err := s.client.Repositories.GetArchiveLink(ctx, s.owner, s.repo, github.Zipball, &github.RepositoryContentGetOptions{
+		Ref: ref,
+	}, 10)
+	if err != nil {
+		return nil, sha, fmt.Errorf("failed to get archive link: %w", err)
+	}
+	archive, err := downloadDataArchive(ctx, http.DefaultClient, url.String())
+	return archive, sha, err
+}
+
+func (s *githubDataSource) latestSHA(ctx context.Context) (string, error) {
+	ref, _, err := s.client.Git.GetRef(ctx, s.owner, s.repo, "heads/"+s.branch)
+	if err != nil {
+		return "", err
+	}
+	return ref.Object.GetSHA(), nil
+}
+
+// urlDataSource downloads a zip archive from an HTTP(S) URL, such as a release asset or a
+// file server in a network without GitHub access
+type urlDataSource struct {
+	url    string
+	client *http.Client
+}
+
+func (s *urlDataSource) Fetch(ctx context.Context) ([]byte, string, error) {
+	archive, err := downloadDataArchive(ctx, s.client, s.url)
+	if err != nil {
+		return nil, "", err
+	}
+	return archive, sha256Hex(archive), nil
+}
+
+func downloadDataArchive(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download archive: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download archive: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// s3DataSource downloads a zip archive from an S3-compatible bucket
+type s3DataSource struct {
+	bucket *S3Uploader
+	key    string
+}
+
+func (s *s3DataSource) Fetch(ctx context.Context) ([]byte, string, error) {
+	archive, _, err := s.bucket.Get(ctx, s.key)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download archive: %w", err)
+	}
+	return archive, sha256Hex(archive), nil
+}
+
+// dirDataSource reads a checkout of the data repository from the local filesystem, for
+// air-gapped deployments and tests. The version is a hash of the files' paths and contents,
+// so it only changes when the data does.
+type dirDataSource struct {
+	dir string
+}
+
+// dirArchiveRoot is the root directory files are archived under, standing in for the
+// "owner-repo-sha/" directory of a GitHub zipball
+const dirArchiveRoot = "local/"
+
+func (s *dirDataSource) Fetch(ctx context.Context) ([]byte, string, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	hash := sha256.New()
+
+	// WalkDir visits files in lexical order, so the hash is stable
+	err := filepath.WalkDir(s.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if strings.HasPrefix(entry.Name(), ".") && path != s.dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		w, err := archive.Create(dirArchiveRoot + name)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		fmt.Fprintf(hash, "%s\x00%d\x00", name, len(data))
+		hash.Write(data)
+		return nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", s.dir, err)
+	}
+	if err := archive.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mat/arcapi/internal/config"
+)
+
+func TestDirDataSource(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"quests.json":        `[{"id": "q1"}]`,
+		"items/a.json":       `{"id": "a"}`,
+		".git/HEAD":          "ref: refs/heads/main",
+		"images/items/a.png": "png",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	source := newDataSource(&config.Config{SyncSource: "dir", SyncSourceDir: dir}, nil)
+	archive, version, err := source.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewSyncService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
+	if data, err := s.getZipFile(r, "quests.json"); err != nil || string(data) != files["quests.json"] {
+		t.Errorf("quests.json = %s, %v", data, err)
+	}
+	if items, _ := s.getZipDirFiles(r, "items"); len(items) != 1 {
+		t.Errorf("expected 1 item file, got %v", items)
+	}
+	if _, err := s.getZipFile(r, "HEAD"); err == nil {
+		t.Error("hidden directories should be skipped")
+	}
+
+	if _, again, _ := source.Fetch(context.Background()); again != version {
+		t.Errorf("version changed without changes: %s, then %s", version, again)
+	}
+	os.WriteFile(filepath.Join(dir, "quests.json"), []byte(`[]`), 0o644)
+	if _, changed, _ := source.Fetch(context.Background()); changed == version {
+		t.Error("expected the version to change with the data")
+	}
+}
+
+func TestURLDataSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/data.zip" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("zip"))
+	}))
+	defer server.Close()
+
+	archive, version, err := newDataSource(&config.Config{SyncSource: "url", SyncSourceURL: server.URL + "/data.zip"}, nil).Fetch(context.Background())
+	if err != nil || string(archive) != "zip" || version != sha256Hex([]byte("zip")) {
+		t.Errorf("Fetch = %q, %q, %v", archive, version, err)
+	}
+	if _, _, err := newDataSource(&config.Config{SyncSource: "url", SyncSourceURL: server.URL + "/missing.zip"}, nil).Fetch(context.Background()); err == nil {
+		t.Error("expected an error for a missing archive")
+	}
+}
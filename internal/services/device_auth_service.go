@@ -0,0 +1,187 @@
+package services
+
+import (
+	crand "crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+const (
+	// DeviceCodeTTL is how long a device has to complete the device authorization grant
+	DeviceCodeTTL = 10 * time.Minute
+	// DevicePollInterval is the minimum number of seconds between token polls
+	DevicePollInterval = 5
+
+	// userCodeAlphabet avoids vowels and look-alike characters (RFC 8628 section 6.1)
+	userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLength   = 8
+)
+
+// Device flow errors, named after the RFC 8628 error codes returned to clients
+var (
+	ErrDeviceAuthorizationPending = errors.New("authorization_pending")
+	ErrDeviceSlowDown             = errors.New("slow_down")
+	ErrDeviceAccessDenied         = errors.New("access_denied")
+	ErrDeviceExpiredToken         = errors.New("expired_token")
+	ErrDeviceInvalidGrant         = errors.New("invalid_grant")
+	ErrDeviceUserCodeNotFound     = errors.New("user code not found or expired")
+)
+
+// DeviceAuthorization is returned to a device starting the flow
+type DeviceAuthorization struct {
+	DeviceCode string
+	UserCode   string
+	ExpiresIn  int
+	Interval   int
+}
+
+// DeviceCodeStore persists pending device authorizations, implemented by
+// repository.DeviceCodeRepository
+type DeviceCodeStore interface {
+	Create(plainDeviceCode, userCode, clientName string, ttl time.Duration) (*models.DeviceCode, error)
+	FindByPlainDeviceCode(plainDeviceCode string) (*models.DeviceCode, error)
+	FindByUserCode(userCode string) (*models.DeviceCode, error)
+	Approve(code *models.DeviceCode, userID uint) error
+	Deny(code *models.DeviceCode) error
+	MarkPolled(code *models.DeviceCode) error
+	Consume(code *models.DeviceCode) (bool, error)
+	DeleteExpired(before time.Time) error
+}
+
+// DeviceAuthService implements the OAuth 2.0 device authorization grant so headless
+// clients (CLI trackers, stream overlays) can log in without users pasting API keys.
+// An approved device receives a short-lived access token and a refresh token scoped to
+// devices, listed among the user's sessions where it can be revoked on its own.
+type DeviceAuthService struct {
+	deviceCodeRepo DeviceCodeStore
+	// issueSession starts a device-scoped session for the approving user
+	issueSession func(userID uint, userAgent, ipAddress string) (*TokenPair, *models.User, error)
+}
+
+func NewDeviceAuthService(deviceCodeRepo *repository.DeviceCodeRepository, authService *AuthService) *DeviceAuthService {
+	return &DeviceAuthService{
+		deviceCodeRepo: deviceCodeRepo,
+		issueSession: func(userID uint, userAgent, ipAddress string) (*TokenPair, *models.User, error) {
+			user, err := authService.UserRepo().FindByID(userID)
+			if err != nil {
+				return nil, nil, ErrDeviceInvalidGrant
+			}
+			pair, err := authService.IssueSession(user, models.SessionScopeDevice, userAgent, ipAddress)
+			return pair, user, err
+		},
+	}
+}
+
+// RequestCode starts a device authorization for the named client
+func (s *DeviceAuthService) RequestCode(clientName string) (*DeviceAuthorization, error) {
+	deviceCode, err := generateOpaqueToken(32)
+	if err != nil {
+		return nil, err
+	}
+	userCode, err := generateUserCode()
+	if err != nil {
+		return nil, err
+	}
+
+	// Opportunistically clean up codes nobody finished
+	go s.deviceCodeRepo.DeleteExpired(time.Now().Add(-time.Hour))
+
+	if _, err := s.deviceCodeRepo.Create(deviceCode, userCode, strings.TrimSpace(clientName), DeviceCodeTTL); err != nil {
+		return nil, err
+	}
+
+	return &DeviceAuthorization{
+		DeviceCode: deviceCode,
+		UserCode:   userCode,
+		ExpiresIn:  int(DeviceCodeTTL.Seconds()),
+		Interval:   DevicePollInterval,
+	}, nil
+}
+
+// Verify approves or denies a pending device for the signed-in user
+func (s *DeviceAuthService) Verify(user *models.User, userCode string, approve bool) (*models.DeviceCode, error) {
+	code, err := s.deviceCodeRepo.FindByUserCode(NormalizeUserCode(userCode))
+	if err != nil || code.IsExpired() || code.IsApproved() || code.IsDenied() {
+		return nil, ErrDeviceUserCodeNotFound
+	}
+
+	if !approve {
+		return code, s.deviceCodeRepo.Deny(code)
+	}
+	return code, s.deviceCodeRepo.Approve(code, user.ID)
+}
+
+// ExchangeToken is polled by the device. Once the user has approved, it returns a
+// device-scoped access and refresh token exactly once; until then it returns one of the
+// RFC 8628 errors.
+func (s *DeviceAuthService) ExchangeToken(deviceCode, userAgent, ipAddress string) (*TokenPair, *models.User, error) {
+	code, err := s.deviceCodeRepo.FindByPlainDeviceCode(deviceCode)
+	if err != nil || code.IsConsumed() {
+		return nil, nil, ErrDeviceInvalidGrant
+	}
+	if code.IsExpired() {
+		return nil, nil, ErrDeviceExpiredToken
+	}
+	if code.IsDenied() {
+		return nil, nil, ErrDeviceAccessDenied
+	}
+
+	if !code.IsApproved() {
+		tooFast := code.LastPolledAt != nil && time.Since(*code.LastPolledAt) < DevicePollInterval*time.Second
+		if err := s.deviceCodeRepo.MarkPolled(code); err != nil {
+			return nil, nil, err
+		}
+		if tooFast {
+			return nil, nil, ErrDeviceSlowDown
+		}
+		return nil, nil, ErrDeviceAuthorizationPending
+	}
+
+	consumed, err := s.deviceCodeRepo.Consume(code)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !consumed || code.UserID == nil {
+		return nil, nil, ErrDeviceInvalidGrant
+	}
+
+	if code.ClientName != "" {
+		// Sessions show the client the user approved rather than its HTTP library
+		userAgent = code.ClientName
+	}
+	return s.issueSession(*code.UserID, userAgent, ipAddress)
+}
+
+// NormalizeUserCode uppercases a user-entered code and restores the XXXX-XXXX grouping,
+// so "bcdf ghjk" and "BCDF-GHJK" are treated the same
+func NormalizeUserCode(userCode string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(userCode) {
+		if r >= 'A' && r <= 'Z' {
+			b.WriteRune(r)
+		}
+	}
+	code := b.String()
+	if len(code) != userCodeLength {
+		return code
+	}
+	return code[:userCodeLength/2] + "-" + code[userCodeLength/2:]
+}
+
+func generateUserCode() (string, error) {
+	max := big.NewInt(int64(len(userCodeAlphabet)))
+	code := make([]byte, userCodeLength)
+	for i := range code {
+		n, err := crand.Int(crand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = userCodeAlphabet[n.Int64()]
+	}
+	return NormalizeUserCode(string(code)), nil
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+// memoryDeviceCodeStore keeps device codes in memory
+type memoryDeviceCodeStore struct {
+	codes []*models.DeviceCode
+}
+
+func (s *memoryDeviceCodeStore) Create(plainDeviceCode, userCode, clientName string, ttl time.Duration) (*models.DeviceCode, error) {
+	hash := sha256.Sum256([]byte(plainDeviceCode))
+	code := &models.DeviceCode{
+		ID:             uint(len(s.codes) + 1),
+		DeviceCodeHash: hex.EncodeToString(hash[:]),
+		UserCode:       userCode,
+		ClientName:     clientName,
+		ExpiresAt:      time.Now().Add(ttl),
+	}
+	s.codes = append(s.codes, code)
+	return code, nil
+}
+
+func (s *memoryDeviceCodeStore) FindByPlainDeviceCode(plainDeviceCode string) (*models.DeviceCode, error) {
+	hash := sha256.Sum256([]byte(plainDeviceCode))
+	for _, code := range s.codes {
+		if code.DeviceCodeHash == hex.EncodeToString(hash[:]) {
+			found := *code
+			return &found, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (s *memoryDeviceCodeStore) FindByUserCode(userCode string) (*models.DeviceCode, error) {
+	for _, code := range s.codes {
+		if code.UserCode == userCode {
+			found := *code
+			return &found, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (s *memoryDeviceCodeStore) stored(code *models.DeviceCode) *models.DeviceCode {
+	return s.codes[code.ID-1]
+}
+
+func (s *memoryDeviceCodeStore) Approve(code *models.DeviceCode, userID uint) error {
+	now := time.Now()
+	s.stored(code).UserID = &userID
+	s.stored(code).ApprovedAt = &now
+	return nil
+}
+
+func (s *memoryDeviceCodeStore) Deny(code *models.DeviceCode) error {
+	now := time.Now()
+	s.stored(code).DeniedAt = &now
+	return nil
+}
+
+func (s *memoryDeviceCodeStore) MarkPolled(code *models.DeviceCode) error {
+	now := time.Now()
+	s.stored(code).LastPolledAt = &now
+	return nil
+}
+
+func (s *memoryDeviceCodeStore) Consume(code *models.DeviceCode) (bool, error) {
+	stored := s.stored(code)
+	if stored.ConsumedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	stored.ConsumedAt = &now
+	return true, nil
+}
+
+func (s *memoryDeviceCodeStore) DeleteExpired(before time.Time) error {
+	return nil
+}
+
+// newTestDeviceAuthService returns a DeviceAuthService that records the user agent of
+// every session it issues
+func newTestDeviceAuthService() (*DeviceAuthService, *memoryDeviceCodeStore, *[]string) {
+	store := &memoryDeviceCodeStore{}
+	var issued []string
+	s := &DeviceAuthService{
+		deviceCodeRepo: store,
+		issueSession: func(userID uint, userAgent, ipAddress string) (*TokenPair, *models.User, error) {
+			issued = append(issued, userAgent)
+			return &TokenPair{AccessToken: "access", TokenType: "Bearer", ExpiresIn: 900, RefreshToken: "refresh"}, &models.User{ID: userID}, nil
+		},
+	}
+	return s, store, &issued
+}
+
+func TestDeviceAuthorizationGrant(t *testing.T) {
+	s, store, issued := newTestDeviceAuthService()
+
+	auth, err := s.RequestCode("  Stream overlay ")
+	if err != nil {
+		t.Fatalf("RequestCode: %v", err)
+	}
+	if auth.Interval != DevicePollInterval || auth.ExpiresIn != int(DeviceCodeTTL.Seconds()) || len(auth.UserCode) != userCodeLength+1 {
+		t.Fatalf("unexpected authorization %+v", auth)
+	}
+
+	if _, _, err := s.ExchangeToken(auth.DeviceCode, "", ""); !errors.Is(err, ErrDeviceAuthorizationPending) {
+		t.Fatalf("before approval: got %v, want ErrDeviceAuthorizationPending", err)
+	}
+	// Polling again within the interval asks the device to back off
+	if _, _, err := s.ExchangeToken(auth.DeviceCode, "", ""); !errors.Is(err, ErrDeviceSlowDown) {
+		t.Fatalf("polling too fast: got %v, want ErrDeviceSlowDown", err)
+	}
+	polled := time.Now().Add(-2 * DevicePollInterval * time.Second)
+	store.codes[0].LastPolledAt = &polled
+	if _, _, err := s.ExchangeToken(auth.DeviceCode, "", ""); !errors.Is(err, ErrDeviceAuthorizationPending) {
+		t.Fatalf("polling after the interval: got %v, want ErrDeviceAuthorizationPending", err)
+	}
+
+	// Users type the code in any case and grouping
+	user := &models.User{ID: 3}
+	code, err := s.Verify(user, "  "+auth.UserCode[:4]+" "+auth.UserCode[5:], true)
+	if err != nil || code.ClientName != "Stream overlay" {
+		t.Fatalf("Verify() = %+v, %v", code, err)
+	}
+	if _, err := s.Verify(user, auth.UserCode, true); !errors.Is(err, ErrDeviceUserCodeNotFound) {
+		t.Errorf("verifying twice: got %v, want ErrDeviceUserCodeNotFound", err)
+	}
+
+	pair, approvedBy, err := s.ExchangeToken(auth.DeviceCode, "curl/8.0", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("after approval: %v", err)
+	}
+	if pair.AccessToken == "" || pair.RefreshToken == "" || approvedBy.ID != 3 {
+		t.Errorf("expected a token pair for user 3, got %+v for %+v", pair, approvedBy)
+	}
+	if len(*issued) != 1 || (*issued)[0] != "Stream overlay" {
+		t.Errorf("expected one session named after the client, got %v", *issued)
+	}
+
+	// The device code can only be exchanged once
+	if _, _, err := s.ExchangeToken(auth.DeviceCode, "", ""); !errors.Is(err, ErrDeviceInvalidGrant) {
+		t.Errorf("exchanging twice: got %v, want ErrDeviceInvalidGrant", err)
+	}
+	if _, _, err := s.ExchangeToken("unknown", "", ""); !errors.Is(err, ErrDeviceInvalidGrant) {
+		t.Errorf("unknown device code: got %v, want ErrDeviceInvalidGrant", err)
+	}
+}
+
+func TestDeviceAuthorizationDeniedAndExpired(t *testing.T) {
+	s, store, issued := newTestDeviceAuthService()
+
+	denied, _ := s.RequestCode("")
+	if _, err := s.Verify(&models.User{ID: 3}, denied.UserCode, false); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if _, _, err := s.ExchangeToken(denied.DeviceCode, "", ""); !errors.Is(err, ErrDeviceAccessDenied) {
+		t.Errorf("denied device: got %v, want ErrDeviceAccessDenied", err)
+	}
+
+	expired, _ := s.RequestCode("")
+	store.codes[1].ExpiresAt = time.Now().Add(-time.Second)
+	if _, err := s.Verify(&models.User{ID: 3}, expired.UserCode, true); !errors.Is(err, ErrDeviceUserCodeNotFound) {
+		t.Errorf("verifying an expired code: got %v, want ErrDeviceUserCodeNotFound", err)
+	}
+	if _, _, err := s.ExchangeToken(expired.DeviceCode, "", ""); !errors.Is(err, ErrDeviceExpiredToken) {
+		t.Errorf("expired device code: got %v, want ErrDeviceExpiredToken", err)
+	}
+
+	if len(*issued) != 0 {
+		t.Errorf("expected no sessions, got %v", *issued)
+	}
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Drain states
+const (
+	DrainStateServing  = "serving"
+	DrainStateDraining = "draining"
+	DrainStateDrained  = "drained" // Safe to stop the process
+)
+
+// drainPollInterval is how often a drain checks whether work has finished
+const drainPollInterval = 100 * time.Millisecond
+
+// DrainWorker is a background worker that stops taking new work when the server drains
+type DrainWorker struct {
+	Name string
+	Stop func()      // Stops scheduling new runs
+	Busy func() bool // Reports whether a run is still in progress
+}
+
+// DrainStatus reports the progress of a drain
+type DrainStatus struct {
+	State            string     `json:"state"`
+	StartedAt        *time.Time `json:"started_at,omitempty"`
+	DrainedAt        *time.Time `json:"drained_at,omitempty"`
+	InFlightRequests int64      `json:"in_flight_requests"`
+	BusyWorkers      []string   `json:"busy_workers"`
+}
+
+// DrainService coordinates a graceful drain before a rolling deploy stops the process:
+// new requests are refused, background workers stop scheduling runs, and the drain
+// completes once in-flight requests and running jobs have finished.
+type DrainService struct {
+	inFlight  atomic.Int64
+	mu        sync.Mutex
+	state     string
+	startedAt *time.Time
+	drainedAt *time.Time
+	workers   []DrainWorker
+	onDrain   []func()
+}
+
+func NewDrainService() *DrainService {
+	return &DrainService{state: DrainStateServing}
+}
+
+// AddWorker registers a background worker to stop and wait for during a drain
+func (s *DrainService) AddWorker(worker DrainWorker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.workers = append(s.workers, worker)
+}
+
+// OnDrain registers a function called when a drain starts, e.g. to disable keep-alives
+func (s *DrainService) OnDrain(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onDrain = append(s.onDrain, fn)
+}
+
+// RequestStarted and RequestFinished count the requests a drain waits for
+func (s *DrainService) RequestStarted() {
+	s.inFlight.Add(1)
+}
+
+func (s *DrainService) RequestFinished() {
+	s.inFlight.Add(-1)
+}
+
+// Draining reports whether new requests should be refused
+func (s *DrainService) Draining() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state != DrainStateServing
+}
+
+// Drain starts draining and returns immediately; poll Status for progress. Draining is
+// one-way: the process is expected to be stopped once drained. Calling Drain again has
+// no effect.
+func (s *DrainService) Drain() {
+	s.mu.Lock()
+	if s.state != DrainStateServing {
+		s.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	s.state = DrainStateDraining
+	s.startedAt = &now
+	workers := s.workers
+	onDrain := s.onDrain
+	s.mu.Unlock()
+
+	for _, fn := range onDrain {
+		fn()
+	}
+	for _, worker := range workers {
+		worker.Stop()
+	}
+
+	go func() {
+		for s.inFlight.Load() > 0 || len(busyWorkers(workers)) > 0 {
+			time.Sleep(drainPollInterval)
+		}
+		now := time.Now()
+		s.mu.Lock()
+		s.state = DrainStateDrained
+		s.drainedAt = &now
+		s.mu.Unlock()
+	}()
+}
+
+func (s *DrainService) Status() DrainStatus {
+	s.mu.Lock()
+	status := DrainStatus{
+		State:     s.state,
+		StartedAt: s.startedAt,
+		DrainedAt: s.drainedAt,
+	}
+	workers := s.workers
+	s.mu.Unlock()
+
+	status.InFlightRequests = s.inFlight.Load()
+	status.BusyWorkers = busyWorkers(workers)
+	return status
+}
+
+func busyWorkers(workers []DrainWorker) []string {
+	busy := []string{}
+	for _, worker := range workers {
+		if worker.Busy() {
+			busy = append(busy, worker.Name)
+		}
+	}
+	return busy
+}
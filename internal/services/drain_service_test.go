@@ -0,0 +1,36 @@
+package services
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDrainWaitsForRequestsAndWorkers(t *testing.T) {
+	var busy, stopped atomic.Bool
+	busy.Store(true)
+
+	drain := NewDrainService()
+	drain.AddWorker(DrainWorker{Name: "sync", Stop: func() { stopped.Store(true) }, Busy: busy.Load})
+	drain.RequestStarted()
+
+	drain.Drain()
+	if !drain.Draining() || !stopped.Load() {
+		t.Fatal("expected the drain to start and stop the worker")
+	}
+	time.Sleep(3 * drainPollInterval)
+	status := drain.Status()
+	if status.State != DrainStateDraining || status.InFlightRequests != 1 || len(status.BusyWorkers) != 1 {
+		t.Fatalf("unexpected status while work is pending: %+v", status)
+	}
+
+	drain.RequestFinished()
+	busy.Store(false)
+	deadline := time.Now().Add(2 * time.Second)
+	for drain.Status().State != DrainStateDrained {
+		if time.Now().After(deadline) {
+			t.Fatalf("drain did not complete: %+v", drain.Status())
+		}
+		time.Sleep(drainPollInterval)
+	}
+}
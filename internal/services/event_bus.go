@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/mat/arcapi/internal/logging"
+	"github.com/mat/arcapi/internal/models"
+)
+
+var eventLog = logging.For("events")
+
+// Redis channels for live update events
+const (
+	EventChannelAlerts = "arcapi:events:alerts" // An alert was created, updated or deleted
+	EventChannelSync   = "arcapi:events:sync"   // A data sync completed; the payload is a SyncEvent
+	// Notifications were added to users' feeds; the payload is a NotificationEvent
+	EventChannelNotifications = "arcapi:events:notifications"
+)
+
+var ErrEventBusUnavailable = errors.New("live updates require Redis")
+
+// AlertEvent is published on EventChannelAlerts
+type AlertEvent struct {
+	Action  string `json:"action"` // create, update or delete
+	AlertID uint   `json:"alert_id"`
+}
+
+// NotificationEvent is published on EventChannelNotifications
+type NotificationEvent struct {
+	UserIDs []uint `json:"user_ids"` // Users with new notifications
+}
+
+// EventBus fans live update events out to every API instance through Redis pub/sub, so
+// subscribers see changes made on any instance. A nil bus drops published events and
+// has no subscriptions.
+type EventBus struct {
+	client *redis.Client
+}
+
+// NewEventBus returns a bus on the cache's Redis connection, or nil without Redis
+func NewEventBus(cacheService *CacheService) *EventBus {
+	if cacheService == nil {
+		return nil
+	}
+	return &EventBus{client: cacheService.Client()}
+}
+
+// Publish sends payload as JSON on channel
+func (b *EventBus) Publish(ctx context.Context, channel string, payload interface{}) error {
+	if b == nil {
+		return nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, channel, data).Err()
+}
+
+// Subscribe returns the raw payloads published on channel until ctx is done
+func (b *EventBus) Subscribe(ctx context.Context, channel string) (<-chan []byte, error) {
+	if b == nil {
+		return nil, ErrEventBusUnavailable
+	}
+	sub := b.client.Subscribe(ctx, channel)
+	// Wait for the subscription to be confirmed so no event published after this returns is missed
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, err
+	}
+
+	out := make(chan []byte)
+	go func() {
+		defer close(out)
+		defer sub.Close()
+		messages := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				select {
+				case out <- []byte(msg.Payload):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// PublishSyncCompleted is a post-sync hook that forwards the event to EventChannelSync
+func (b *EventBus) PublishSyncCompleted(ctx context.Context, event SyncEvent) error {
+	return b.Publish(ctx, EventChannelSync, event)
+}
+
+// PublishAlertChange announces an alert write. Failures are only logged since the write
+// itself already succeeded.
+func (b *EventBus) PublishAlertChange(ctx context.Context, action string, alertID uint) {
+	if err := b.Publish(ctx, EventChannelAlerts, AlertEvent{Action: action, AlertID: alertID}); err != nil {
+		eventLog.Warn("Failed to publish alert event", "error", err)
+	}
+}
+
+// PublishNotifications announces the users who just got notifications. Failures are only
+// logged since the notifications are stored already; waiting clients pick them up on their
+// next poll.
+func (b *EventBus) PublishNotifications(ctx context.Context, notifications []models.Notification) {
+	if b == nil || len(notifications) == 0 {
+		return
+	}
+	seen := make(map[uint]bool, len(notifications))
+	event := NotificationEvent{}
+	for _, n := range notifications {
+		if !seen[n.UserID] {
+			seen[n.UserID] = true
+			event.UserIDs = append(event.UserIDs, n.UserID)
+		}
+	}
+	if err := b.Publish(ctx, EventChannelNotifications, event); err != nil {
+		eventLog.Warn("Failed to publish notification event", "error", err)
+	}
+}
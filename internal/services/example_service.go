@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+	"gorm.io/gorm"
+)
+
+// metadataExampleKeyPrefix prefixes the metadata keys examples are stored under, one per entity
+const metadataExampleKeyPrefix = "example:"
+
+// exampleBatchSize is how many rows are scanned at a time when picking examples
+const exampleBatchSize = 500
+
+// exampleTimestamp replaces row timestamps in examples, so fixtures only change when the
+// data shape does
+var exampleTimestamp = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ErrUnknownExampleEntity is returned for entities without examples
+var ErrUnknownExampleEntity = errors.New("unknown entity")
+
+// ErrNoExample is returned when an entity has no rows to take an example from
+var ErrNoExample = errors.New("no example available")
+
+// EntityExample is a real record of an entity, sanitized for use in docs and test fixtures
+type EntityExample struct {
+	Entity      string                 `json:"entity" example:"items"`
+	DataVersion string                 `json:"data_version,omitempty" example:"3f2a9c1"`
+	GeneratedAt time.Time              `json:"generated_at"`
+	Example     map[string]interface{} `json:"example"`
+}
+
+// ExampleService picks an example record per content entity after every sync and stores
+// it in the metadata table, so every instance serves the same examples
+type ExampleService struct {
+	metadataRepo *repository.MetadataRepository
+	entities     map[string]func() (map[string]interface{}, error)
+}
+
+func NewExampleService(
+	questRepo *repository.QuestRepository,
+	itemRepo *repository.ItemRepository,
+	skillNodeRepo *repository.SkillNodeRepository,
+	hideoutModuleRepo *repository.HideoutModuleRepository,
+	enemyTypeRepo *repository.EnemyTypeRepository,
+	botRepo *repository.BotRepository,
+	mapRepo *repository.MapRepository,
+	traderRepo *repository.TraderRepository,
+	projectRepo *repository.ProjectRepository,
+	metadataRepo *repository.MetadataRepository,
+) *ExampleService {
+	return &ExampleService{
+		metadataRepo: metadataRepo,
+		entities: map[string]func() (map[string]interface{}, error){
+			"quests":          exampleOf(questRepo.FindAllBatched, func(q models.Quest) (string, models.JSONB) { return q.ExternalID, q.Data }),
+			"items":           exampleOf(itemRepo.FindAllBatched, func(i models.Item) (string, models.JSONB) { return i.ExternalID, i.Data }),
+			"skill-nodes":     exampleOf(skillNodeRepo.FindAllBatched, func(n models.SkillNode) (string, models.JSONB) { return n.ExternalID, n.Data }),
+			"hideout-modules": exampleOf(hideoutModuleRepo.FindAllBatched, func(m models.HideoutModule) (string, models.JSONB) { return m.ExternalID, m.Data }),
+			"enemy-types":     exampleOf(enemyTypeRepo.FindAllBatched, func(e models.EnemyType) (string, models.JSONB) { return e.ExternalID, e.Data }),
+			"bots":            exampleOf(botRepo.FindAllBatched, func(b models.Bot) (string, models.JSONB) { return b.ExternalID, b.Data }),
+			"maps":            exampleOf(mapRepo.FindAllBatched, func(m models.Map) (string, models.JSONB) { return m.ExternalID, m.Data }),
+			"traders":         exampleOf(traderRepo.FindAllBatched, func(t models.Trader) (string, models.JSONB) { return t.ExternalID, t.Data }),
+			"projects":        exampleOf(projectRepo.FindAllBatched, func(p models.Project) (string, models.JSONB) { return p.ExternalID, p.Data }),
+		},
+	}
+}
+
+// Entities lists the entities examples are available for, sorted
+func (s *ExampleService) Entities() []string {
+	entities := make([]string, 0, len(s.entities))
+	for entity := range s.entities {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+	return entities
+}
+
+// Regenerate replaces the stored example of every entity. It runs as a post-sync hook;
+// an entity that fails keeps its previous example.
+func (s *ExampleService) Regenerate(ctx context.Context, event SyncEvent) error {
+	var failed []string
+	for _, entity := range s.Entities() {
+		if _, err := s.generate(entity, event.DataVersion); err != nil && !errors.Is(err, ErrNoExample) {
+			log.Printf("Failed to regenerate %s example: %v", entity, err)
+			failed = append(failed, entity)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to regenerate examples for %v", failed)
+	}
+	return nil
+}
+
+// Example returns the stored example of entity, generating it if no sync has stored one yet
+func (s *ExampleService) Example(entity string) (*EntityExample, error) {
+	if _, ok := s.entities[entity]; !ok {
+		return nil, ErrUnknownExampleEntity
+	}
+
+	stored, err := s.metadataRepo.Get(metadataExampleKeyPrefix + entity)
+	if err == nil {
+		var example EntityExample
+		if err := json.Unmarshal([]byte(stored), &example); err == nil {
+			return &example, nil
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	dataVersion, _ := s.metadataRepo.Get(metadataDataVersionKey)
+	return s.generate(entity, dataVersion)
+}
+
+func (s *ExampleService) generate(entity, dataVersion string) (*EntityExample, error) {
+	record, err := s.entities[entity]()
+	if err != nil {
+		return nil, err
+	}
+	example := &EntityExample{
+		Entity:      entity,
+		DataVersion: dataVersion,
+		GeneratedAt: time.Now().UTC(),
+		Example:     record,
+	}
+	data, err := json.Marshal(example)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.metadataRepo.Set(metadataExampleKeyPrefix+entity, string(data)); err != nil {
+		return nil, err
+	}
+	return example, nil
+}
+
+// exampleOf picks the row whose data has the most fields, so the example shows as much of
+// the shape as possible. Ties go to the lowest external ID, keeping the pick stable.
+func exampleOf[T any](findAllBatched func(int, func([]T) error) error, describe func(T) (string, models.JSONB)) func() (map[string]interface{}, error) {
+	return func() (map[string]interface{}, error) {
+		var best *T
+		bestID, bestFields := "", -1
+		err := findAllBatched(exampleBatchSize, func(rows []T) error {
+			for i := range rows {
+				id, data := describe(rows[i])
+				fields := exampleFieldCount(data)
+				if fields > bestFields || (fields == bestFields && id < bestID) {
+					row := rows[i]
+					best, bestID, bestFields = &row, id, fields
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if best == nil {
+			return nil, ErrNoExample
+		}
+		return sanitizeExample(*best)
+	}
+}
+
+// exampleFieldCount counts the fields of data that hold a value, including nested ones
+func exampleFieldCount(value interface{}) int {
+	count := 0
+	switch v := value.(type) {
+	case models.JSONB:
+		return exampleFieldCount(map[string]interface{}(v))
+	case map[string]interface{}:
+		for _, child := range v {
+			if child != nil {
+				count += 1 + exampleFieldCount(child)
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			count += exampleFieldCount(child)
+		}
+	}
+	return count
+}
+
+// sanitizeExample converts a row to its JSON form with the database ID and timestamps,
+// which only mean something to this deployment, replaced by fixed values
+func sanitizeExample(row interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return nil, err
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	if _, ok := record["id"]; ok {
+		record["id"] = 1
+	}
+	for _, key := range []string{"synced_at", "created_at", "updated_at"} {
+		if _, ok := record[key]; ok {
+			record[key] = exampleTimestamp
+		}
+	}
+	return record, nil
+}
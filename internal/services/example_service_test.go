@@ -0,0 +1,40 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestExampleOfPicksRichestRow(t *testing.T) {
+	items := []models.Item{
+		{ID: 4, ExternalID: "b_sparse", Name: "Sparse", Data: models.JSONB{"name": "Sparse"}},
+		{ID: 9, ExternalID: "c_rich", Name: "Rich", Data: models.JSONB{"name": "Rich", "recipe": map[string]interface{}{"metal_parts": float64(2)}}},
+		{ID: 2, ExternalID: "a_rich", Name: "Also rich", Data: models.JSONB{"name": "Also rich", "stats": map[string]interface{}{"damage": float64(3)}}},
+	}
+	findItems := func(batchSize int, fn func([]models.Item) error) error {
+		for i := range items {
+			if err := fn(items[i : i+1]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	example, err := exampleOf(findItems, func(i models.Item) (string, models.JSONB) { return i.ExternalID, i.Data })()
+	if err != nil {
+		t.Fatalf("exampleOf failed: %v", err)
+	}
+	// Both rich rows have three fields; the lower external ID wins the tie
+	if example["external_id"] != "a_rich" {
+		t.Errorf("expected a_rich, got %v", example["external_id"])
+	}
+	if example["id"] != 1 || example["synced_at"] != exampleTimestamp {
+		t.Errorf("expected id and timestamps sanitized, got %v and %v", example["id"], example["synced_at"])
+	}
+
+	none := func(batchSize int, fn func([]models.Item) error) error { return nil }
+	if _, err := exampleOf(none, func(i models.Item) (string, models.JSONB) { return i.ExternalID, i.Data })(); err != ErrNoExample {
+		t.Errorf("expected ErrNoExample for an empty table, got %v", err)
+	}
+}
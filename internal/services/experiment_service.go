@@ -0,0 +1,189 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+// experimentCacheTTL bounds how long experiment changes take to reach every instance
+const experimentCacheTTL = time.Minute
+
+var ErrInvalidExperiment = errors.New("invalid experiment")
+
+var experimentKeyPattern = regexp.MustCompile(`^[a-z0-9_]{1,64}$`)
+
+// ExperimentAssignment is the variant a user is assigned in an experiment
+type ExperimentAssignment struct {
+	Key     string `json:"key"`
+	Variant string `json:"variant"`
+}
+
+// ExperimentService assigns users to experiment variants. Experiments are few and read
+// on every lookup, so like roles they are kept in memory and reloaded periodically.
+type ExperimentService struct {
+	repo *repository.ExperimentRepository
+
+	mu          sync.RWMutex
+	experiments []models.Experiment
+	loadedAt    time.Time
+}
+
+func NewExperimentService(repo *repository.ExperimentRepository) *ExperimentService {
+	return &ExperimentService{repo: repo}
+}
+
+// assignVariant deterministically picks userID's variant, or returns false when the user
+// falls outside the rollout. The hash of key and user ID decides both enrollment and
+// variant, so raising the rollout only adds users and never moves enrolled ones.
+func assignVariant(experiment models.Experiment, userID uint) (string, bool) {
+	if !experiment.Enabled || len(experiment.Variants) == 0 {
+		return "", false
+	}
+	sum := sha256.Sum256([]byte(experiment.Key + ":" + strconv.FormatUint(uint64(userID), 10)))
+	if binary.BigEndian.Uint64(sum[:8])%100 >= uint64(experiment.RolloutPercent) {
+		return "", false
+	}
+
+	total := 0
+	for _, v := range experiment.Variants {
+		total += v.Weight
+	}
+	point := int(binary.BigEndian.Uint64(sum[8:16]) % uint64(total))
+	for _, v := range experiment.Variants {
+		if point < v.Weight {
+			return v.Name, true
+		}
+		point -= v.Weight
+	}
+	return "", false
+}
+
+// Assignments returns the variants of every enabled experiment the user is enrolled in
+func (s *ExperimentService) Assignments(userID uint) []ExperimentAssignment {
+	assignments := []ExperimentAssignment{}
+	for _, experiment := range s.load() {
+		if variant, ok := assignVariant(experiment, userID); ok {
+			assignments = append(assignments, ExperimentAssignment{Key: experiment.Key, Variant: variant})
+		}
+	}
+	return assignments
+}
+
+// Variant returns the user's variant in the experiment, or "" when the user isn't
+// enrolled, so handlers can gate features still being rolled out
+func (s *ExperimentService) Variant(key string, userID uint) string {
+	for _, experiment := range s.load() {
+		if experiment.Key == key {
+			variant, _ := assignVariant(experiment, userID)
+			return variant
+		}
+	}
+	return ""
+}
+
+// ListExperiments returns all experiments
+func (s *ExperimentService) ListExperiments() ([]models.Experiment, error) {
+	return s.repo.ListAll()
+}
+
+// SaveExperiment creates an experiment or replaces the settings of an existing one
+func (s *ExperimentService) SaveExperiment(experiment models.Experiment) (*models.Experiment, error) {
+	if err := validateExperiment(experiment); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.FindByKey(experiment.Key)
+	if err != nil {
+		existing = &experiment
+		err = s.repo.Create(existing)
+	} else {
+		existing.Description = experiment.Description
+		existing.Enabled = experiment.Enabled
+		existing.RolloutPercent = experiment.RolloutPercent
+		existing.Variants = experiment.Variants
+		err = s.repo.Update(existing)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.Invalidate()
+	return existing, nil
+}
+
+// DeleteExperiment removes an experiment; its users fall back to the default behavior
+func (s *ExperimentService) DeleteExperiment(key string) error {
+	experiment, err := s.repo.FindByKey(key)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(experiment.ID); err != nil {
+		return err
+	}
+	s.Invalidate()
+	return nil
+}
+
+// Invalidate forces the next lookup to reload experiments from the database
+func (s *ExperimentService) Invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+func (s *ExperimentService) load() []models.Experiment {
+	s.mu.RLock()
+	fresh := time.Since(s.loadedAt) < experimentCacheTTL
+	experiments := s.experiments
+	s.mu.RUnlock()
+	if fresh {
+		return experiments
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if time.Since(s.loadedAt) >= experimentCacheTTL {
+		all, err := s.repo.ListAll()
+		if err != nil {
+			// Keep serving the previous snapshot rather than dropping every assignment
+			log.Printf("Warning: Failed to load experiments: %v", err)
+		} else {
+			s.experiments = all
+			s.loadedAt = time.Now()
+		}
+	}
+	return s.experiments
+}
+
+func validateExperiment(experiment models.Experiment) error {
+	if !experimentKeyPattern.MatchString(experiment.Key) {
+		return fmt.Errorf("%w: key must be 1-64 lowercase letters, digits or underscores", ErrInvalidExperiment)
+	}
+	if experiment.RolloutPercent < 0 || experiment.RolloutPercent > 100 {
+		return fmt.Errorf("%w: rollout_percent must be between 0 and 100", ErrInvalidExperiment)
+	}
+	if len(experiment.Variants) == 0 {
+		return fmt.Errorf("%w: at least one variant is required", ErrInvalidExperiment)
+	}
+	names := make(map[string]bool, len(experiment.Variants))
+	for _, v := range experiment.Variants {
+		if v.Name == "" || len(v.Name) > 32 || names[v.Name] {
+			return fmt.Errorf("%w: variant names must be unique and 1-32 characters", ErrInvalidExperiment)
+		}
+		if v.Weight <= 0 {
+			return fmt.Errorf("%w: variant weights must be positive", ErrInvalidExperiment)
+		}
+		names[v.Name] = true
+	}
+	return nil
+}
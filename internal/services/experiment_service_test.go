@@ -0,0 +1,48 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestAssignVariant(t *testing.T) {
+	experiment := models.Experiment{
+		Key:            "remaining_items_calculator",
+		Enabled:        true,
+		RolloutPercent: 50,
+		Variants:       models.ExperimentVariants{{Name: "control", Weight: 1}, {Name: "treatment", Weight: 3}},
+	}
+
+	enrolled := map[uint]string{}
+	counts := map[string]int{}
+	for id := uint(1); id <= 4000; id++ {
+		variant, ok := assignVariant(experiment, id)
+		if again, _ := assignVariant(experiment, id); again != variant {
+			t.Fatalf("user %d got %q then %q", id, variant, again)
+		}
+		if ok {
+			enrolled[id] = variant
+			counts[variant]++
+		}
+	}
+	if n := len(enrolled); n < 1800 || n > 2200 {
+		t.Errorf("expected about half of 4000 users enrolled, got %d", n)
+	}
+	if counts["treatment"] < 2*counts["control"] {
+		t.Errorf("expected treatment to get about 3x control, got %v", counts)
+	}
+
+	// Raising the rollout keeps enrolled users in their variant
+	experiment.RolloutPercent = 80
+	for id, variant := range enrolled {
+		if got, ok := assignVariant(experiment, id); !ok || got != variant {
+			t.Fatalf("user %d moved from %q to %q after raising the rollout", id, variant, got)
+		}
+	}
+
+	experiment.Enabled = false
+	if _, ok := assignVariant(experiment, 1); ok {
+		t.Error("expected no assignment for a disabled experiment")
+	}
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Hook events, as used in the hooks YAML file
+const (
+	HookPostSync = "post_sync"
+	HookPreWrite = "pre_write"
+	HookClaimMap = "claim_map"
+)
+
+// SyncEvent is passed to post-sync hooks after a successful sync
+type SyncEvent struct {
+	DataVersion string         `json:"data_version,omitempty"`
+	Changes     []EntityChange `json:"changes"`
+	CompletedAt time.Time      `json:"completed_at"`
+}
+
+// WriteEvent is passed to pre-write hooks before a data write reaches its handler
+type WriteEvent struct {
+	EntityType string          `json:"entity_type"` // Route collection, e.g. "items" or "hideout-modules"
+	Action     string          `json:"action"`      // create, update or delete
+	EntityID   string          `json:"entity_id,omitempty"`
+	UserID     uint            `json:"user_id,omitempty"`
+	Body       json.RawMessage `json:"body,omitempty"`
+}
+
+// WriteRejectedError is returned by a pre-write hook to reject a write; Message is shown to the client
+type WriteRejectedError struct {
+	Message string
+}
+
+func (e *WriteRejectedError) Error() string {
+	return e.Message
+}
+
+type PostSyncHook func(ctx context.Context, event SyncEvent) error
+type PreWriteHook func(ctx context.Context, event WriteEvent) error
+
+// ClaimMapper returns the name of a defined role for a Supabase user (e.g. "admin" or
+// "moderator"), or "" to keep the role derived from the token's metadata
+type ClaimMapper func(ctx context.Context, claims *SupabaseClaims) (string, error)
+
+// claimMapTTL is how long the role a claim mapper chose is reused for the same claims.
+// Claims are mapped on every request with a Supabase token, and mappers may call out
+// over HTTP.
+const claimMapTTL = 5 * time.Minute
+
+// claimMapping is a claim mapper result remembered for one Supabase user
+type claimMapping struct {
+	fingerprint string // The claims it was chosen for
+	role        string
+	expiresAt   time.Time
+}
+
+// HookRegistry holds the extension points self-hosters configure in HOOKS_CONFIG. Go
+// plugins receive it in their exported RegisterHooks(*services.HookRegistry) function.
+// A nil registry has no hooks.
+type HookRegistry struct {
+	postSync     []PostSyncHook
+	preWrite     []PreWriteHook
+	claimMappers []ClaimMapper
+
+	claimMu       sync.Mutex
+	claimMappings map[string]claimMapping // By Supabase user ID
+}
+
+func NewHookRegistry() *HookRegistry {
+	return &HookRegistry{}
+}
+
+func (r *HookRegistry) OnPostSync(hook PostSyncHook) {
+	r.postSync = append(r.postSync, hook)
+}
+
+func (r *HookRegistry) OnPreWrite(hook PreWriteHook) {
+	r.preWrite = append(r.preWrite, hook)
+}
+
+func (r *HookRegistry) AddClaimMapper(mapper ClaimMapper) {
+	r.claimMappers = append(r.claimMappers, mapper)
+}
+
+func (r *HookRegistry) HasPreWrite() bool {
+	return r != nil && len(r.preWrite) > 0
+}
+
+// RunPostSync calls every post-sync hook. Failures are logged since the sync already completed.
+func (r *HookRegistry) RunPostSync(ctx context.Context, event SyncEvent) {
+	if r == nil {
+		return
+	}
+	for _, hook := range r.postSync {
+		if err := hook(ctx, event); err != nil {
+			log.Printf("Warning: Post-sync hook failed: %v", err)
+		}
+	}
+}
+
+// RunPreWrite calls the pre-write hooks in order and returns the first rejection or failure
+func (r *HookRegistry) RunPreWrite(ctx context.Context, event WriteEvent) error {
+	if r == nil {
+		return nil
+	}
+	for _, hook := range r.preWrite {
+		if err := hook(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MapClaims returns the role chosen by the first claim mapper with an opinion, or "".
+// The result is reused until the user's claims change or claimMapTTL passes.
+func (r *HookRegistry) MapClaims(ctx context.Context, claims *SupabaseClaims) (string, error) {
+	if r == nil || len(r.claimMappers) == 0 {
+		return "", nil
+	}
+
+	key := claims.Sub
+	if key == "" {
+		key = claims.Email
+	}
+	fingerprint, err := json.Marshal([]interface{}{claims.Email, claims.AppMetadata, claims.UserMetadata})
+	if err != nil {
+		return "", err
+	}
+	r.claimMu.Lock()
+	cached, ok := r.claimMappings[key]
+	r.claimMu.Unlock()
+	if ok && cached.fingerprint == string(fingerprint) && time.Now().Before(cached.expiresAt) {
+		return cached.role, nil
+	}
+
+	role := ""
+	for _, mapper := range r.claimMappers {
+		mapped, err := mapper(ctx, claims)
+		if err != nil {
+			return "", err
+		}
+		if mapped != "" {
+			role = mapped
+			break
+		}
+	}
+
+	r.claimMu.Lock()
+	if r.claimMappings == nil {
+		r.claimMappings = make(map[string]claimMapping)
+	}
+	r.claimMappings[key] = claimMapping{fingerprint: string(fingerprint), role: role, expiresAt: time.Now().Add(claimMapTTL)}
+	r.claimMu.Unlock()
+	return role, nil
+}
+
+// hooksFile is the YAML layout of HOOKS_CONFIG
+type hooksFile struct {
+	Plugins []string         `yaml:"plugins"` // Paths of Go plugins exporting RegisterHooks
+	HTTP    []httpHookConfig `yaml:"http"`
+}
+
+// LoadHooks builds the registry described by the YAML file at path. An empty path
+// returns an empty registry.
+func LoadHooks(path string) (*HookRegistry, error) {
+	registry := NewHookRegistry()
+	if path == "" {
+		return registry, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hooks config: %w", err)
+	}
+	var file hooksFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse hooks config: %w", err)
+	}
+
+	for _, pluginPath := range file.Plugins {
+		if err := loadHookPlugin(pluginPath, registry); err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", pluginPath, err)
+		}
+	}
+	for i, cfg := range file.HTTP {
+		if err := registerHTTPHook(registry, cfg); err != nil {
+			return nil, fmt.Errorf("http hook %d: %w", i+1, err)
+		}
+	}
+	return registry, nil
+}
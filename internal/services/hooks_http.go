@@ -0,0 +1,146 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// httpHookConfig is one HTTP callout in HOOKS_CONFIG
+type httpHookConfig struct {
+	Event          string   `yaml:"event"` // post_sync, pre_write or claim_map
+	URL            string   `yaml:"url"`
+	Secret         string   `yaml:"secret"` // Signs request bodies in X-Hook-Signature as sha256=<hex HMAC>
+	TimeoutSeconds int      `yaml:"timeout_seconds"`
+	EntityTypes    []string `yaml:"entity_types"` // pre_write only: limit to these collections, e.g. "items"
+	// FailOpen lets writes and logins through when a pre_write or claim_map callout can't
+	// be reached; by default they are refused
+	FailOpen bool `yaml:"fail_open"`
+}
+
+type httpHook struct {
+	cfg    httpHookConfig
+	client *http.Client
+}
+
+func registerHTTPHook(registry *HookRegistry, cfg httpHookConfig) error {
+	if !strings.HasPrefix(cfg.URL, "http://") && !strings.HasPrefix(cfg.URL, "https://") {
+		return fmt.Errorf("invalid url %q", cfg.URL)
+	}
+	timeout := 5 * time.Second
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	hook := &httpHook{cfg: cfg, client: &http.Client{Timeout: timeout}}
+
+	switch cfg.Event {
+	case HookPostSync:
+		registry.OnPostSync(func(ctx context.Context, event SyncEvent) error {
+			_, err := hook.call(ctx, event, nil)
+			return err
+		})
+	case HookPreWrite:
+		registry.OnPreWrite(hook.preWrite)
+	case HookClaimMap:
+		registry.AddClaimMapper(hook.mapClaims)
+	default:
+		return fmt.Errorf("unknown event %q: expected post_sync, pre_write or claim_map", cfg.Event)
+	}
+	return nil
+}
+
+// preWrite rejects the write when the callout answers with a non-2xx status, passing its
+// {"error": "..."} message on to the client
+func (h *httpHook) preWrite(ctx context.Context, event WriteEvent) error {
+	if len(h.cfg.EntityTypes) > 0 && !slices.Contains(h.cfg.EntityTypes, event.EntityType) {
+		return nil
+	}
+	status, err := h.call(ctx, event, nil)
+	if err != nil && status == 0 {
+		if h.cfg.FailOpen {
+			log.Printf("Warning: Pre-write hook %s unavailable, allowing write: %v", h.cfg.URL, err)
+			return nil
+		}
+		return fmt.Errorf("pre-write hook %s unavailable: %w", h.cfg.URL, err)
+	}
+	if err != nil {
+		return &WriteRejectedError{Message: err.Error()}
+	}
+	return nil
+}
+
+func (h *httpHook) mapClaims(ctx context.Context, claims *SupabaseClaims) (string, error) {
+	payload := map[string]interface{}{
+		"sub":           claims.Sub,
+		"email":         claims.Email,
+		"app_metadata":  claims.AppMetadata,
+		"user_metadata": claims.UserMetadata,
+	}
+	var resp struct {
+		Role string `json:"role"`
+	}
+	if _, err := h.call(ctx, payload, &resp); err != nil {
+		if h.cfg.FailOpen {
+			log.Printf("Warning: Claim mapping hook %s failed, keeping the default role: %v", h.cfg.URL, err)
+			return "", nil
+		}
+		return "", fmt.Errorf("claim mapping hook %s failed: %w", h.cfg.URL, err)
+	}
+	return resp.Role, nil
+}
+
+// call POSTs payload as JSON and decodes a 2xx response into out, if given. The status is 0
+// when no response was received.
+func (h *httpHook) call(ctx context.Context, payload interface{}, out interface{}) (int, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Hook-Event", h.cfg.Event)
+	if h.cfg.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(h.cfg.Secret))
+		mac.Write(body)
+		req.Header.Set("X-Hook-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(respBody, &errResp) == nil && errResp.Error != "" {
+			return resp.StatusCode, fmt.Errorf("%s", errResp.Error)
+		}
+		return resp.StatusCode, fmt.Errorf("hook responded with status %d", resp.StatusCode)
+	}
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return resp.StatusCode, fmt.Errorf("invalid hook response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
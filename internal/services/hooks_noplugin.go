@@ -0,0 +1,9 @@
+//go:build !cgo || !(linux || darwin || freebsd)
+
+package services
+
+import "errors"
+
+func loadHookPlugin(path string, registry *HookRegistry) error {
+	return errors.New("Go plugins are not supported by this build (requires cgo on linux, darwin or freebsd)")
+}
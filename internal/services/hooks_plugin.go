@@ -0,0 +1,26 @@
+//go:build cgo && (linux || darwin || freebsd)
+
+package services
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// loadHookPlugin opens a Go plugin and calls its exported RegisterHooks function
+func loadHookPlugin(path string, registry *HookRegistry) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return err
+	}
+	sym, err := p.Lookup("RegisterHooks")
+	if err != nil {
+		return err
+	}
+	register, ok := sym.(func(*HookRegistry))
+	if !ok {
+		return fmt.Errorf("RegisterHooks must be a func(*services.HookRegistry), got %T", sym)
+	}
+	register(registry)
+	return nil
+}
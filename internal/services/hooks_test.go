@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLoadHooksHTTPPreWriteRejects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Hook-Event") != HookPreWrite || r.Header.Get("X-Hook-Signature") == "" {
+			t.Errorf("unexpected hook headers: %v", r.Header)
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"error":"name must not be empty"}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "hooks.yaml")
+	config := "http:\n  - event: pre_write\n    url: " + server.URL + "\n    secret: s3cret\n    entity_types: [items]\n"
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	hooks, err := LoadHooks(path)
+	if err != nil {
+		t.Fatalf("LoadHooks: %v", err)
+	}
+
+	if err := hooks.RunPreWrite(context.Background(), WriteEvent{EntityType: "quests", Action: "create"}); err != nil {
+		t.Errorf("expected writes to other collections to pass, got %v", err)
+	}
+
+	err = hooks.RunPreWrite(context.Background(), WriteEvent{EntityType: "items", Action: "create"})
+	var rejected *WriteRejectedError
+	if !errors.As(err, &rejected) || rejected.Message != "name must not be empty" {
+		t.Errorf("expected rejection with the hook's message, got %v", err)
+	}
+}
+
+func TestLoadHooksRejectsUnknownEvent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hooks.yaml")
+	if err := os.WriteFile(path, []byte("http:\n  - event: on_login\n    url: https://example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadHooks(path); err == nil {
+		t.Error("expected an error for an unknown event")
+	}
+}
+
+func TestLoadHooksHTTPClaimMapReturnsRole(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.Header.Get("X-Hook-Event") != HookClaimMap {
+			t.Errorf("unexpected hook event: %q", r.Header.Get("X-Hook-Event"))
+		}
+		w.Write([]byte(`{"role":"moderator"}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "hooks.yaml")
+	config := "http:\n  - event: claim_map\n    url: " + server.URL + "\n"
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	hooks, err := LoadHooks(path)
+	if err != nil {
+		t.Fatalf("LoadHooks: %v", err)
+	}
+
+	claims := &SupabaseClaims{Sub: "user-1", Email: "a@example.com"}
+	for i := 0; i < 3; i++ {
+		role, err := hooks.MapClaims(context.Background(), claims)
+		if err != nil || role != "moderator" {
+			t.Fatalf("MapClaims = %q, %v; want moderator", role, err)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("expected the hook to be called once for unchanged claims, got %d calls", got)
+	}
+}
+
+func TestMapClaimsRemapsWhenClaimsChange(t *testing.T) {
+	calls := 0
+	hooks := NewHookRegistry()
+	hooks.AddClaimMapper(func(ctx context.Context, claims *SupabaseClaims) (string, error) {
+		calls++
+		if claims.AppMetadata["team"] == "staff" {
+			return "admin", nil
+		}
+		return "", nil
+	})
+
+	claims := &SupabaseClaims{Sub: "user-1", AppMetadata: map[string]interface{}{"team": "players"}}
+	if role, _ := hooks.MapClaims(context.Background(), claims); role != "" {
+		t.Errorf("expected no role, got %q", role)
+	}
+	if role, _ := hooks.MapClaims(context.Background(), claims); role != "" || calls != 1 {
+		t.Errorf("expected the cached empty role, got %q after %d calls", role, calls)
+	}
+
+	claims = &SupabaseClaims{Sub: "user-1", AppMetadata: map[string]interface{}{"team": "staff"}}
+	if role, _ := hooks.MapClaims(context.Background(), claims); role != "admin" || calls != 2 {
+		t.Errorf("expected admin after the claims changed, got %q after %d calls", role, calls)
+	}
+}
+
+func TestMapClaimsDoesNotCacheErrors(t *testing.T) {
+	calls := 0
+	hooks := NewHookRegistry()
+	hooks.AddClaimMapper(func(ctx context.Context, claims *SupabaseClaims) (string, error) {
+		calls++
+		if calls == 1 {
+			return "", errors.New("unavailable")
+		}
+		return "moderator", nil
+	})
+
+	claims := &SupabaseClaims{Sub: "user-1"}
+	if _, err := hooks.MapClaims(context.Background(), claims); err == nil {
+		t.Fatal("expected the first mapping to fail")
+	}
+	if role, err := hooks.MapClaims(context.Background(), claims); err != nil || role != "moderator" {
+		t.Errorf("expected a retry to map moderator, got %q, %v", role, err)
+	}
+}
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+const JobImageCheck = "image_check"
+
+// imageCheckWorkers bounds concurrent requests so a full run doesn't hammer the image host
+const imageCheckWorkers = 8
+
+// ImageCheckService verifies the stored image URLs of items and enemy types. Broken
+// URLs are retried with alternate filename encodings and, when one works, the entity
+// is pointed at it. Results are kept in image_checks for the data-quality report.
+type ImageCheckService struct {
+	itemRepo       *repository.ItemRepository
+	enemyTypeRepo  *repository.EnemyTypeRepository
+	imageCheckRepo *repository.ImageCheckRepository
+	client         *http.Client
+}
+
+func NewImageCheckService(itemRepo *repository.ItemRepository, enemyTypeRepo *repository.EnemyTypeRepository, imageCheckRepo *repository.ImageCheckRepository) *ImageCheckService {
+	return &ImageCheckService{
+		itemRepo:       itemRepo,
+		enemyTypeRepo:  enemyTypeRepo,
+		imageCheckRepo: imageCheckRepo,
+		client:         &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// imageTarget is an entity image to verify; apply points the entity at another URL
+type imageTarget struct {
+	entityType string
+	entityID   string
+	url        string // URL to verify, the original one for previously repaired entities
+	current    string // URL the entity currently serves
+	apply      func(imageURL string) error
+}
+
+// Run checks every image URL and returns the number of broken or repaired images
+func (s *ImageCheckService) Run(checkedAt time.Time) (int64, error) {
+	targets, err := s.targets()
+	if err != nil {
+		return 0, err
+	}
+
+	// An entity already pointed at a repaired URL is checked against its original URL
+	// again, so a fixed upstream filename is picked up
+	previous, err := s.imageCheckRepo.ListAll()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load previous image checks: %w", err)
+	}
+	repaired := make(map[string]models.ImageCheck, len(previous))
+	for _, check := range previous {
+		if check.RepairedURL != "" {
+			repaired[check.EntityType+":"+check.EntityID] = check
+		}
+	}
+
+	checks := make([]models.ImageCheck, len(targets))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < imageCheckWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				target := targets[i]
+				if check, ok := repaired[target.entityType+":"+target.entityID]; ok && check.RepairedURL == target.current {
+					target.url = check.URL
+				}
+				checks[i] = s.check(target, checkedAt)
+			}
+		}()
+	}
+	for i := range targets {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var failures int64
+	for _, check := range checks {
+		if check.Status != models.ImageStatusOK {
+			failures++
+		}
+	}
+	if err := s.imageCheckRepo.ReplaceAll(checks); err != nil {
+		return 0, fmt.Errorf("failed to store image checks: %w", err)
+	}
+	return failures, nil
+}
+
+func (s *ImageCheckService) targets() ([]imageTarget, error) {
+	items, err := s.itemRepo.ListAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load items: %w", err)
+	}
+	enemyTypes, err := s.enemyTypeRepo.ListAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load enemy types: %w", err)
+	}
+
+	var targets []imageTarget
+	for _, item := range items {
+		if item.ImageURL == "" {
+			continue
+		}
+		id := item.ID
+		targets = append(targets, imageTarget{
+			entityType: models.ImageEntityItem,
+			entityID:   item.ExternalID,
+			url:        item.ImageURL,
+			current:    item.ImageURL,
+			apply: func(repairedURL string) error {
+				return s.itemRepo.UpdateImageURL(id, repairedURL)
+			},
+		})
+	}
+	for _, enemyType := range enemyTypes {
+		if enemyType.ImageURL == "" {
+			continue
+		}
+		id := enemyType.ID
+		targets = append(targets, imageTarget{
+			entityType: models.ImageEntityEnemyType,
+			entityID:   enemyType.ExternalID,
+			url:        enemyType.ImageURL,
+			current:    enemyType.ImageURL,
+			apply: func(repairedURL string) error {
+				return s.enemyTypeRepo.UpdateImageURL(id, repairedURL)
+			},
+		})
+	}
+	return targets, nil
+}
+
+// check verifies target.url and, when it is broken, tries its alternate encodings
+func (s *ImageCheckService) check(target imageTarget, checkedAt time.Time) models.ImageCheck {
+	result := models.ImageCheck{
+		EntityType: target.entityType,
+		EntityID:   target.entityID,
+		URL:        target.url,
+		Status:     models.ImageStatusOK,
+		CheckedAt:  checkedAt,
+	}
+
+	status, err := s.probe(target.url)
+	result.HTTPStatus = status
+	if err == nil {
+		// The original works (again), so drop any earlier repair
+		if target.current != target.url {
+			if err := target.apply(target.url); err != nil {
+				log.Printf("Warning: Failed to restore image URL for %s %s: %v", target.entityType, target.entityID, err)
+			}
+		}
+		return result
+	}
+	result.Status = models.ImageStatusBroken
+	result.Error = err.Error()
+
+	for _, candidate := range imageURLCandidates(target.url) {
+		if _, err := s.probe(candidate); err != nil {
+			continue
+		}
+		if candidate != target.current {
+			if err := target.apply(candidate); err != nil {
+				log.Printf("Warning: Failed to repair image URL for %s %s: %v", target.entityType, target.entityID, err)
+				break
+			}
+		}
+		result.Status = models.ImageStatusRepaired
+		result.RepairedURL = candidate
+		break
+	}
+	return result
+}
+
+// probe sends a HEAD request, falling back to a one-byte ranged GET for hosts that
+// don't allow HEAD. It returns the response status and an error unless it was 2xx.
+func (s *ImageCheckService) probe(rawURL string) (int, error) {
+	status, err := s.request(http.MethodHead, rawURL)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusForbidden) {
+		status, err = s.request(http.MethodGet, rawURL)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if status < 200 || status >= 300 {
+		return status, fmt.Errorf("image responded with status %d", status)
+	}
+	return status, nil
+}
+
+func (s *ImageCheckService) request(method, rawURL string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// imageURLCandidates returns alternate spellings of an image URL's filename: differently
+// escaped, with spaces and underscores swapped, lowercased and with the other common
+// image extension. The original URL is never included.
+func imageURLCandidates(rawURL string) []string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Path == "" {
+		return nil
+	}
+	dir, filename := path.Split(u.Path)
+	if filename == "" {
+		return nil
+	}
+
+	names := []string{
+		filename,
+		strings.ReplaceAll(filename, " ", "_"),
+		strings.ReplaceAll(filename, "_", " "),
+		strings.ReplaceAll(filename, " ", "-"),
+		strings.ToLower(filename),
+		strings.ToLower(strings.ReplaceAll(filename, " ", "_")),
+	}
+	// Filenames escaped once too often upstream end up as e.g. "Rusted%2520Gear.png"
+	if decoded, err := url.PathUnescape(filename); err == nil && decoded != filename {
+		names = append(names, decoded)
+	}
+	ext := path.Ext(filename)
+	switch strings.ToLower(ext) {
+	case ".png":
+		names = append(names, strings.TrimSuffix(filename, ext)+".webp")
+	case ".webp":
+		names = append(names, strings.TrimSuffix(filename, ext)+".png")
+	}
+
+	seen := map[string]bool{rawURL: true}
+	var candidates []string
+	for _, name := range names {
+		for _, escaped := range []string{url.PathEscape(name), strings.ReplaceAll(url.QueryEscape(name), "+", "%20")} {
+			candidate := *u
+			candidate.Path = dir + name
+			candidate.RawPath = dir + escaped
+			if s := candidate.String(); !seen[s] {
+				seen[s] = true
+				candidates = append(candidates, s)
+			}
+		}
+	}
+	return candidates
+}
+
+// imageRepairs returns the repaired image checks of one entity type keyed by external ID,
+// so a sync doesn't put back image URLs the image check already replaced
+func (s *SyncService) imageRepairs(entityType string) map[string]models.ImageCheck {
+	repairs := make(map[string]models.ImageCheck)
+	if s.imageCheckRepo == nil {
+		return repairs
+	}
+	checks, err := s.imageCheckRepo.FindByStatus(models.ImageStatusRepaired)
+	if err != nil {
+		log.Printf("Warning: Failed to load image repairs: %v", err)
+		return repairs
+	}
+	for _, check := range checks {
+		if check.EntityType == entityType {
+			repairs[check.EntityID] = check
+		}
+	}
+	return repairs
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestImageURLCandidates(t *testing.T) {
+	original := "https://example.com/images/items/Rusted%20Gear.png"
+	candidates := imageURLCandidates(original)
+
+	for _, want := range []string{
+		"https://example.com/images/items/Rusted_Gear.png",
+		"https://example.com/images/items/rusted%20gear.png",
+		"https://example.com/images/items/rusted_gear.png",
+		"https://example.com/images/items/Rusted%20Gear.webp",
+	} {
+		if !slices.Contains(candidates, want) {
+			t.Errorf("expected candidate %s, got %v", want, candidates)
+		}
+	}
+	if slices.Contains(candidates, original) {
+		t.Errorf("candidates should not include the original URL")
+	}
+}
+
+func TestImageURLCandidatesDecodesDoubleEscaping(t *testing.T) {
+	candidates := imageURLCandidates("https://example.com/images/Rusted%2520Gear.png")
+	if !slices.Contains(candidates, "https://example.com/images/Rusted%20Gear.png") {
+		t.Errorf("expected a singly escaped candidate, got %v", candidates)
+	}
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+// itemStatBlocks are the item data keys that hold weapon and equipment stat blocks
+var itemStatBlocks = []string{"stats", "weaponStats", "armorStats", "shieldStats"}
+
+// statValuePattern matches upstream values such as "12.5", "1,200" or "15%"
+var statValuePattern = regexp.MustCompile(`^\s*(-?[0-9][0-9,]*(?:\.[0-9]+)?)\s*([^\s0-9]*)\s*$`)
+
+// itemStatsFromData parses the numeric stats out of an item's stat blocks. Non-numeric
+// entries are skipped; when blocks repeat a stat the first block listed wins.
+func itemStatsFromData(itemExternalID string, data map[string]interface{}) []models.ItemStat {
+	if itemExternalID == "" {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var stats []models.ItemStat
+	now := time.Now()
+	for _, block := range itemStatBlocks {
+		values, ok := data[block].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key, raw := range values {
+			stat := snakeCase(key)
+			if stat == "" || seen[stat] {
+				continue
+			}
+			value, unit, ok := parseStatValue(raw)
+			if !ok {
+				continue
+			}
+			seen[stat] = true
+			stats = append(stats, models.ItemStat{
+				ItemExternalID: itemExternalID,
+				Stat:           stat,
+				Value:          value,
+				Unit:           unit,
+				SyncedAt:       now,
+			})
+		}
+	}
+	return stats
+}
+
+func parseStatValue(raw interface{}) (float64, string, bool) {
+	switch v := raw.(type) {
+	case float64:
+		return v, "", true
+	case string:
+		m := statValuePattern.FindStringSubmatch(v)
+		if m == nil {
+			return 0, "", false
+		}
+		value, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", ""), 64)
+		if err != nil {
+			return 0, "", false
+		}
+		return value, m[2], true
+	}
+	return 0, "", false
+}
+
+// snakeCase normalizes camelCase and spaced keys: "fireRate" and "Fire Rate" -> "fire_rate"
+func snakeCase(key string) string {
+	var b strings.Builder
+	prevLower := false
+	for _, r := range strings.TrimSpace(key) {
+		switch {
+		case r == ' ' || r == '-' || r == '_':
+			if b.Len() > 0 && !strings.HasSuffix(b.String(), "_") {
+				b.WriteRune('_')
+			}
+			prevLower = false
+			continue
+		case unicode.IsUpper(r) && prevLower:
+			b.WriteRune('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+		prevLower = unicode.IsLower(r) || unicode.IsDigit(r)
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
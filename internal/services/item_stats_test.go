@@ -0,0 +1,36 @@
+package services
+
+import "testing"
+
+func TestItemStatsFromData(t *testing.T) {
+	data := map[string]interface{}{
+		"stats": map[string]interface{}{
+			"damage":              float64(40),
+			"fireRate":            "1,200",
+			"Headshot Multiplier": "15%",
+			"ammoType":            "Medium",
+		},
+		"armorStats": map[string]interface{}{"damage": float64(1)},
+	}
+
+	stats := itemStatsFromData("ferro", data)
+	got := make(map[string]float64, len(stats))
+	units := make(map[string]string, len(stats))
+	for _, s := range stats {
+		got[s.Stat] = s.Value
+		units[s.Stat] = s.Unit
+	}
+
+	want := map[string]float64{"damage": 40, "fire_rate": 1200, "headshot_multiplier": 15}
+	if len(got) != len(want) {
+		t.Fatalf("stats = %v, want %v", got, want)
+	}
+	for stat, value := range want {
+		if got[stat] != value {
+			t.Errorf("%s = %v, want %v", stat, got[stat], value)
+		}
+	}
+	if units["headshot_multiplier"] != "%" {
+		t.Errorf("expected %% unit, got %q", units["headshot_multiplier"])
+	}
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+const (
+	LeaderboardOverall = "overall"
+	LeaderboardQuests  = "quests"
+	LeaderboardHideout = "hideout"
+
+	// leaderboardCacheTTL bounds staleness if an invalidation is missed; pages are
+	// also dropped whenever the leaderboard job refreshes the table.
+	leaderboardCacheTTL = 10 * time.Minute
+
+	maxLeaderboardNameLength = 32
+)
+
+// leaderboardRankColumns maps a leaderboard type to the rank column it is ordered by
+var leaderboardRankColumns = map[string]string{
+	LeaderboardOverall: "rank",
+	LeaderboardQuests:  "quests_rank",
+	LeaderboardHideout: "hideout_rank",
+}
+
+var (
+	ErrUnknownLeaderboard     = errors.New("unknown leaderboard type")
+	ErrInvalidLeaderboardName = errors.New("leaderboard name must be at most 32 characters")
+)
+
+// LeaderboardPage is one cached page of a leaderboard
+type LeaderboardPage struct {
+	Entries []models.LeaderboardEntry `json:"entries"`
+	Total   int64                     `json:"total"`
+}
+
+// LeaderboardService serves the opt-in leaderboards from the materialized table,
+// caching pages in Redis when available.
+type LeaderboardService struct {
+	statsRepo    *repository.StatsRepository
+	userRepo     *repository.UserRepository
+	cacheService *CacheService
+}
+
+func NewLeaderboardService(statsRepo *repository.StatsRepository, userRepo *repository.UserRepository, cacheService *CacheService) *LeaderboardService {
+	return &LeaderboardService{
+		statsRepo:    statsRepo,
+		userRepo:     userRepo,
+		cacheService: cacheService,
+	}
+}
+
+// IsValidLeaderboardType reports whether boardType names a known leaderboard
+func IsValidLeaderboardType(boardType string) bool {
+	_, ok := leaderboardRankColumns[boardType]
+	return ok
+}
+
+// Page returns a page of the given leaderboard
+func (s *LeaderboardService) Page(boardType string, page, limit int) (*LeaderboardPage, error) {
+	rankColumn, ok := leaderboardRankColumns[boardType]
+	if !ok {
+		return nil, ErrUnknownLeaderboard
+	}
+
+	cacheKey := LeaderboardCacheKey(boardType, page, limit)
+	if s.cacheService != nil {
+		var cached LeaderboardPage
+		if err := s.cacheService.GetJSON(cacheKey, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	entries, total, err := s.statsRepo.FindLeaderboard(rankColumn, (page-1)*limit, limit)
+	if err != nil {
+		return nil, err
+	}
+	result := &LeaderboardPage{Entries: entries, Total: total}
+
+	if s.cacheService != nil {
+		if err := s.cacheService.SetJSON(cacheKey, result, leaderboardCacheTTL); err != nil {
+			log.Printf("Warning: Failed to cache leaderboard page %s: %v", cacheKey, err)
+		}
+	}
+
+	return result, nil
+}
+
+// Entry returns the user's own leaderboard row, if they are ranked
+func (s *LeaderboardService) Entry(userID uint) (*models.LeaderboardEntry, error) {
+	return s.statsRepo.FindLeaderboardEntry(userID)
+}
+
+// Refresh rebuilds the leaderboard table and drops cached pages
+func (s *LeaderboardService) Refresh(refreshedAt time.Time) (int64, error) {
+	rows, err := s.statsRepo.RefreshLeaderboard(refreshedAt)
+	if err != nil {
+		return 0, err
+	}
+	s.Invalidate()
+	return rows, nil
+}
+
+// Invalidate drops every cached leaderboard page
+func (s *LeaderboardService) Invalidate() {
+	if s.cacheService == nil {
+		return
+	}
+	if err := s.cacheService.DeletePattern("leaderboard:*"); err != nil {
+		log.Printf("Warning: Failed to invalidate leaderboard cache: %v", err)
+	}
+}
+
+// UpdatePrivacy changes the user's leaderboard, telemetry and last seen settings. Opting
+// out of the leaderboards removes the user immediately; opting in takes effect on the next
+// refresh.
+func (s *LeaderboardService) UpdatePrivacy(user *models.User, optIn *bool, name *string, telemetryOptIn, lastSeenPublic *bool) error {
+	if name != nil {
+		trimmed := strings.TrimSpace(*name)
+		if len([]rune(trimmed)) > maxLeaderboardNameLength {
+			return ErrInvalidLeaderboardName
+		}
+		user.LeaderboardName = trimmed
+	}
+	if optIn != nil {
+		user.LeaderboardOptIn = *optIn
+	}
+	if telemetryOptIn != nil {
+		user.TelemetryOptIn = *telemetryOptIn
+	}
+	if lastSeenPublic != nil {
+		user.LastSeenPublic = *lastSeenPublic
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	if !user.LeaderboardOptIn {
+		if err := s.statsRepo.DeleteLeaderboardEntry(user.ID); err != nil {
+			return err
+		}
+		s.Invalidate()
+	}
+
+	return nil
+}
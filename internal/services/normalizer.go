@@ -0,0 +1,305 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+// NormalizedQuantity is an item and how many of it, however the upstream data spelled it
+type NormalizedQuantity struct {
+	ItemID   string `json:"item_id"`
+	Quantity int    `json:"quantity"`
+}
+
+// NormalizedStat is a numeric item stat with its unit split off, e.g. 15 and "%"
+type NormalizedStat struct {
+	Stat  string  `json:"stat"`
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit,omitempty"`
+}
+
+// NormalizedItem is the typed view of an item: every field always present with one type,
+// text resolved to one language and numbers parsed out of upstream strings
+type NormalizedItem struct {
+	ID            uint                   `json:"id"`
+	ExternalID    string                 `json:"external_id"`
+	Name          string                 `json:"name"`
+	Description   string                 `json:"description"`
+	Type          string                 `json:"type"`
+	Rarity        string                 `json:"rarity"`
+	Value         int                    `json:"value"`
+	WeightKg      float64                `json:"weight_kg"`
+	StackSize     int                    `json:"stack_size"`
+	ImageURL      string                 `json:"image_url"`
+	CraftBench    []string               `json:"craft_bench"`
+	CraftQuantity int                    `json:"craft_quantity"`
+	Recipe        []NormalizedQuantity   `json:"recipe"`
+	RecyclesInto  []NormalizedQuantity   `json:"recycles_into"`
+	SalvagesInto  []NormalizedQuantity   `json:"salvages_into"`
+	Stats         []NormalizedStat       `json:"stats"`
+	UpdatedAt     string                 `json:"updated_at,omitempty"`
+	Other         map[string]interface{} `json:"other,omitempty"` // Remaining upstream fields, localized
+}
+
+// NormalizedQuest is the typed view of a quest
+type NormalizedQuest struct {
+	ID                  uint                   `json:"id"`
+	ExternalID          string                 `json:"external_id"`
+	Name                string                 `json:"name"`
+	Description         string                 `json:"description"`
+	Trader              string                 `json:"trader"`
+	XP                  int                    `json:"xp"`
+	RequiredTraderLevel int                    `json:"required_trader_level"`
+	Objectives          []string               `json:"objectives"`
+	RewardItems         []NormalizedQuantity   `json:"reward_items"`
+	PreviousQuestIDs    []string               `json:"previous_quest_ids"`
+	NextQuestIDs        []string               `json:"next_quest_ids"`
+	Maps                []string               `json:"maps"`
+	UpdatedAt           string                 `json:"updated_at,omitempty"`
+	Other               map[string]interface{} `json:"other,omitempty"` // Remaining upstream fields, localized
+}
+
+// normalizedItemKeys are the item data keys NormalizedItem has fields for
+var normalizedItemKeys = map[string]bool{
+	"id": true, "name": true, "description": true, "type": true, "rarity": true, "value": true,
+	"weightKg": true, "stackSize": true, "imageFilename": true, "image_url": true, "craftBench": true,
+	"craftQuantity": true, "recipe": true, "recyclesInto": true, "salvagesInto": true, "updatedAt": true,
+	"stats": true, "weaponStats": true, "armorStats": true, "shieldStats": true,
+}
+
+// normalizedQuestKeys are the quest data keys NormalizedQuest has fields for
+var normalizedQuestKeys = map[string]bool{
+	"id": true, "name": true, "description": true, "trader": true, "xp": true, "objectives": true,
+	"rewardItemIds": true, "previousQuestIds": true, "nextQuestIds": true, "maps": true, "map": true,
+	"locations": true, "requiredTraderLevel": true, "traderLevel": true, "trader_level": true,
+	"updatedAt": true,
+}
+
+// NormalizeItem builds the typed view of an item with text in lang, falling back to
+// English when lang is empty or missing
+func NormalizeItem(item models.Item, lang string) NormalizedItem {
+	data := item.Data
+	n := NormalizedItem{
+		ID:            item.ID,
+		ExternalID:    item.ExternalID,
+		Name:          normalizedText(data["name"], lang, item.Name),
+		Description:   normalizedText(data["description"], lang, item.Description),
+		Type:          normalizedText(data["type"], lang, item.Type),
+		Rarity:        normalizedText(data["rarity"], lang, ""),
+		Value:         normalizedInt(data["value"]),
+		WeightKg:      normalizedFloat(data["weightKg"]),
+		StackSize:     normalizedInt(data["stackSize"]),
+		ImageURL:      item.ImageURL,
+		CraftBench:    normalizedStrings(data["craftBench"], lang),
+		CraftQuantity: normalizedInt(data["craftQuantity"]),
+		Recipe:        normalizedQuantities(data["recipe"]),
+		RecyclesInto:  normalizedQuantities(data["recyclesInto"]),
+		SalvagesInto:  normalizedQuantities(data["salvagesInto"]),
+		Stats:         []NormalizedStat{},
+		UpdatedAt:     normalizedText(data["updatedAt"], lang, ""),
+		Other:         normalizedOther(data, normalizedItemKeys, lang),
+	}
+	// Items that can be crafted make one unless the data says otherwise
+	if n.CraftQuantity == 0 && len(n.Recipe) > 0 {
+		n.CraftQuantity = 1
+	}
+	for _, stat := range itemStatsFromData(item.ExternalID, data) {
+		n.Stats = append(n.Stats, NormalizedStat{Stat: stat.Stat, Value: stat.Value, Unit: stat.Unit})
+	}
+	sort.Slice(n.Stats, func(i, j int) bool { return n.Stats[i].Stat < n.Stats[j].Stat })
+	return n
+}
+
+// NormalizeQuest builds the typed view of a quest with text in lang, falling back to
+// English when lang is empty or missing
+func NormalizeQuest(quest models.Quest, lang string) NormalizedQuest {
+	data := quest.Data
+	n := NormalizedQuest{
+		ID:               quest.ID,
+		ExternalID:       quest.ExternalID,
+		Name:             normalizedText(data["name"], lang, quest.Name),
+		Description:      normalizedText(data["description"], lang, quest.Description),
+		Trader:           normalizedText(data["trader"], lang, quest.Trader),
+		XP:               normalizedInt(data["xp"]),
+		Objectives:       normalizedStrings(data["objectives"], lang),
+		RewardItems:      normalizedQuantities(data["rewardItemIds"]),
+		PreviousQuestIDs: normalizedStrings(data["previousQuestIds"], lang),
+		NextQuestIDs:     normalizedStrings(data["nextQuestIds"], lang),
+		Maps:             []string{},
+		UpdatedAt:        normalizedText(data["updatedAt"], lang, ""),
+		Other:            normalizedOther(data, normalizedQuestKeys, lang),
+	}
+	if n.XP == 0 {
+		n.XP = quest.XP
+	}
+	// Quests synced before the data kept every field still have them in their own columns
+	if len(n.Objectives) == 0 && quest.Objectives != nil {
+		n.Objectives = normalizedStrings(quest.Objectives["objectives"], lang)
+	}
+	if len(n.RewardItems) == 0 && quest.RewardItemIds != nil {
+		n.RewardItems = normalizedQuantities(quest.RewardItemIds["reward_item_ids"])
+	}
+	for _, field := range []string{"maps", "map", "locations"} {
+		if maps := normalizedStrings(data[field], lang); len(maps) > 0 {
+			n.Maps = maps
+			break
+		}
+	}
+	for _, field := range []string{"requiredTraderLevel", "traderLevel", "trader_level"} {
+		if level := normalizedInt(data[field]); level > 0 {
+			n.RequiredTraderLevel = level
+			break
+		}
+	}
+	return n
+}
+
+// normalizedText reads a string, number or multilingual string as text in lang, or
+// fallback when the value is missing or empty
+func normalizedText(value interface{}, lang, fallback string) string {
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = strings.TrimSpace(v)
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		text = strconv.FormatBool(v)
+	case map[string]interface{}:
+		if models.IsTranslationMap(v) {
+			text = translatedText(v, lang)
+		}
+	}
+	if text == "" {
+		return fallback
+	}
+	return text
+}
+
+// translatedText picks lang from a multilingual string, falling back to English and then
+// to the first language that has text
+func translatedText(m map[string]interface{}, lang string) string {
+	if text, _ := m[lang].(string); text != "" {
+		return text
+	}
+	if text, _ := m["en"].(string); text != "" {
+		return text
+	}
+	for _, code := range models.TranslationLanguages {
+		if text, _ := m[code].(string); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// normalizedFloat reads a number that may be written as a string such as "1,200" or "2.5kg".
+// Anything unparseable is 0.
+func normalizedFloat(value interface{}) float64 {
+	if v, _, ok := parseStatValue(value); ok {
+		return v
+	}
+	return 0
+}
+
+func normalizedInt(value interface{}) int {
+	return int(math.Round(normalizedFloat(value)))
+}
+
+// normalizedStrings reads a single value or a list of values as a list of text, never nil
+func normalizedStrings(value interface{}, lang string) []string {
+	values := []string{}
+	switch v := value.(type) {
+	case []interface{}:
+		for _, element := range v {
+			if text := normalizedText(element, lang, ""); text != "" {
+				values = append(values, text)
+			}
+		}
+	default:
+		if text := normalizedText(v, lang, ""); text != "" {
+			values = append(values, text)
+		}
+	}
+	return values
+}
+
+// normalizedQuantities reads item quantities written as a map of item ID to quantity or a
+// list of {itemId, quantity} objects. Entries without an item or a positive quantity are
+// dropped, and the result is sorted by item ID.
+func normalizedQuantities(value interface{}) []NormalizedQuantity {
+	quantities := []NormalizedQuantity{}
+	add := func(itemID string, quantity int) {
+		if itemID != "" && quantity > 0 {
+			quantities = append(quantities, NormalizedQuantity{ItemID: itemID, Quantity: quantity})
+		}
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for id, qty := range v {
+			add(id, normalizedInt(qty))
+		}
+	case []interface{}:
+		for _, entry := range v {
+			switch e := entry.(type) {
+			case string:
+				add(e, 1)
+			case map[string]interface{}:
+				var id string
+				for _, key := range []string{"itemId", "item_id", "id"} {
+					if id = normalizedText(e[key], "", ""); id != "" {
+						break
+					}
+				}
+				quantity := 1
+				if qty, ok := e["quantity"]; ok {
+					quantity = normalizedInt(qty)
+				}
+				add(id, quantity)
+			}
+		}
+	}
+	sort.SliceStable(quantities, func(i, j int) bool { return quantities[i].ItemID < quantities[j].ItemID })
+	return quantities
+}
+
+// normalizedOther returns the data fields without a typed field of their own, with
+// multilingual strings resolved to lang
+func normalizedOther(data models.JSONB, known map[string]bool, lang string) map[string]interface{} {
+	var other map[string]interface{}
+	for key, value := range data {
+		if known[key] {
+			continue
+		}
+		if other == nil {
+			other = make(map[string]interface{})
+		}
+		other[key] = translatedValue(value, lang)
+	}
+	return other
+}
+
+func translatedValue(value interface{}, lang string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if models.IsTranslationMap(v) {
+			return translatedText(v, lang)
+		}
+		out := make(map[string]interface{}, len(v))
+		for key, element := range v {
+			out[key] = translatedValue(element, lang)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, element := range v {
+			out[i] = translatedValue(element, lang)
+		}
+		return out
+	}
+	return value
+}
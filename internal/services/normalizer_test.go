@@ -0,0 +1,118 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestNormalizeItem(t *testing.T) {
+	item := models.Item{
+		ID:         3,
+		ExternalID: "ferro_i",
+		Name:       "Ferro I",
+		Type:       "Weapon",
+		ImageURL:   "https://cdn.example/ferro.png",
+		Data: models.JSONB{
+			"name":        map[string]interface{}{"en": "Ferro I", "de": "Ferro I (DE)"},
+			"description": map[string]interface{}{"en": "A rifle"},
+			"rarity":      "Common",
+			"value":       "1,200",
+			"weightKg":    float64(6.5),
+			"stackSize":   float64(1),
+			"craftBench":  "weapon_bench",
+			"recipe":      map[string]interface{}{"metal_parts": float64(5), "rubber_parts": "2", "broken": float64(0)},
+			"recyclesInto": []interface{}{
+				map[string]interface{}{"itemId": "metal_parts", "quantity": float64(3)},
+				"rubber_parts",
+			},
+			"stats":     map[string]interface{}{"damage": float64(40), "Headshot Multiplier": "15%"},
+			"foundIn":   []interface{}{map[string]interface{}{"en": "Buried City", "de": "Begrabene Stadt"}},
+			"updatedAt": "2026-01-02",
+		},
+	}
+
+	n := NormalizeItem(item, "de")
+	if n.Name != "Ferro I (DE)" || n.Description != "A rifle" || n.Type != "Weapon" || n.Rarity != "Common" {
+		t.Errorf("unexpected text fields %+v", n)
+	}
+	if n.Value != 1200 || n.WeightKg != 6.5 || n.StackSize != 1 || n.CraftQuantity != 1 {
+		t.Errorf("unexpected numbers value=%d weight=%v stack=%d craft=%d", n.Value, n.WeightKg, n.StackSize, n.CraftQuantity)
+	}
+	if !reflect.DeepEqual(n.CraftBench, []string{"weapon_bench"}) {
+		t.Errorf("craft bench = %v", n.CraftBench)
+	}
+	wantRecipe := []NormalizedQuantity{{"metal_parts", 5}, {"rubber_parts", 2}}
+	if !reflect.DeepEqual(n.Recipe, wantRecipe) {
+		t.Errorf("recipe = %v, want %v", n.Recipe, wantRecipe)
+	}
+	wantRecycles := []NormalizedQuantity{{"metal_parts", 3}, {"rubber_parts", 1}}
+	if !reflect.DeepEqual(n.RecyclesInto, wantRecycles) {
+		t.Errorf("recycles into = %v, want %v", n.RecyclesInto, wantRecycles)
+	}
+	if n.SalvagesInto == nil || len(n.SalvagesInto) != 0 {
+		t.Errorf("expected an empty salvages list, got %v", n.SalvagesInto)
+	}
+	wantStats := []NormalizedStat{{"damage", 40, ""}, {"headshot_multiplier", 15, "%"}}
+	if !reflect.DeepEqual(n.Stats, wantStats) {
+		t.Errorf("stats = %v, want %v", n.Stats, wantStats)
+	}
+	if found := n.Other["foundIn"].([]interface{}); found[0] != "Begrabene Stadt" {
+		t.Errorf("expected other fields localized, got %v", n.Other)
+	}
+	if _, ok := n.Other["recipe"]; ok {
+		t.Error("typed fields should not repeat under other")
+	}
+}
+
+func TestNormalizeQuest(t *testing.T) {
+	quest := models.Quest{
+		ID:         9,
+		ExternalID: "q_clean_sweep",
+		Name:       "Clean Sweep",
+		Trader:     "Shani",
+		XP:         500,
+		Data: models.JSONB{
+			"name":       map[string]interface{}{"en": "Clean Sweep", "fr": "Grand ménage"},
+			"objectives": []interface{}{map[string]interface{}{"en": "Get 3 ARC Alloy", "fr": "Obtenir 3 alliages ARC"}, "Return to Shani"},
+			"rewardItemIds": []interface{}{
+				map[string]interface{}{"itemId": "bandage", "quantity": float64(2)},
+				map[string]interface{}{"item_id": "ammo", "quantity": "10"},
+			},
+			"previousQuestIds":    "q_first_steps",
+			"map":                 "Dam Battlegrounds",
+			"requiredTraderLevel": "2",
+		},
+	}
+
+	n := NormalizeQuest(quest, "fr")
+	if n.Name != "Grand ménage" || n.Trader != "Shani" || n.XP != 500 || n.RequiredTraderLevel != 2 {
+		t.Errorf("unexpected fields %+v", n)
+	}
+	if !reflect.DeepEqual(n.Objectives, []string{"Obtenir 3 alliages ARC", "Return to Shani"}) {
+		t.Errorf("objectives = %v", n.Objectives)
+	}
+	wantRewards := []NormalizedQuantity{{"ammo", 10}, {"bandage", 2}}
+	if !reflect.DeepEqual(n.RewardItems, wantRewards) {
+		t.Errorf("rewards = %v, want %v", n.RewardItems, wantRewards)
+	}
+	if !reflect.DeepEqual(n.PreviousQuestIDs, []string{"q_first_steps"}) || len(n.NextQuestIDs) != 0 || n.NextQuestIDs == nil {
+		t.Errorf("previous = %v, next = %v", n.PreviousQuestIDs, n.NextQuestIDs)
+	}
+	if !reflect.DeepEqual(n.Maps, []string{"Dam Battlegrounds"}) {
+		t.Errorf("maps = %v", n.Maps)
+	}
+
+	// Older rows keep objectives and rewards only in their own columns
+	legacy := models.Quest{
+		ExternalID:    "q_old",
+		Name:          "Old",
+		Objectives:    models.JSONB{"objectives": []interface{}{"Do it"}},
+		RewardItemIds: models.JSONB{"reward_item_ids": []interface{}{map[string]interface{}{"itemId": "coin"}}},
+	}
+	n = NormalizeQuest(legacy, "")
+	if n.Name != "Old" || !reflect.DeepEqual(n.Objectives, []string{"Do it"}) || !reflect.DeepEqual(n.RewardItems, []NormalizedQuantity{{"coin", 1}}) {
+		t.Errorf("unexpected legacy quest %+v", n)
+	}
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// notificationHubRetry is how long the hub waits before resubscribing after losing Redis
+const notificationHubRetry = 5 * time.Second
+
+// NotificationHub wakes long-polling clients when they get a notification. It holds one
+// subscription to EventChannelNotifications per instance and fans events out to the
+// waiting requests in memory, so a poll doesn't cost a Redis connection.
+type NotificationHub struct {
+	eventBus *EventBus
+
+	mu      sync.Mutex
+	waiters map[uint]map[chan struct{}]struct{}
+	live    bool
+}
+
+func NewNotificationHub(eventBus *EventBus) *NotificationHub {
+	return &NotificationHub{
+		eventBus: eventBus,
+		waiters:  make(map[uint]map[chan struct{}]struct{}),
+	}
+}
+
+// Run subscribes to notification events until ctx is done, resubscribing whenever the
+// subscription drops. Without an event bus it returns straight away and the hub is never
+// live.
+func (h *NotificationHub) Run(ctx context.Context) {
+	if h.eventBus == nil {
+		return
+	}
+	for ctx.Err() == nil {
+		events, err := h.eventBus.Subscribe(ctx, EventChannelNotifications)
+		if err != nil {
+			eventLog.Warn("Failed to subscribe to notification events", "error", err)
+		} else {
+			h.setLive(true)
+			for payload := range events {
+				var event NotificationEvent
+				if err := json.Unmarshal(payload, &event); err != nil {
+					eventLog.Warn("Ignoring malformed notification event", "error", err)
+					continue
+				}
+				h.Notify(event.UserIDs...)
+			}
+			h.setLive(false)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(notificationHubRetry):
+		}
+	}
+}
+
+// Live reports whether the hub is receiving events. While it isn't, waiters are only woken
+// by Notify calls on this instance, so callers should poll the database as well.
+func (h *NotificationHub) Live() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.live
+}
+
+func (h *NotificationHub) setLive(live bool) {
+	h.mu.Lock()
+	h.live = live
+	h.mu.Unlock()
+}
+
+// Wait returns a channel that receives when the user gets a notification. Call stop once
+// done waiting.
+func (h *NotificationHub) Wait(userID uint) (wake <-chan struct{}, stop func()) {
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	if h.waiters[userID] == nil {
+		h.waiters[userID] = make(map[chan struct{}]struct{})
+	}
+	h.waiters[userID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.waiters[userID], ch)
+		if len(h.waiters[userID]) == 0 {
+			delete(h.waiters, userID)
+		}
+		h.mu.Unlock()
+	}
+}
+
+// Notify wakes the requests waiting on the given users
+func (h *NotificationHub) Notify(userIDs ...uint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, userID := range userIDs {
+		for ch := range h.waiters[userID] {
+			select {
+			case ch <- struct{}{}:
+			default:
+				// Already woken
+			}
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package services
+
+import "testing"
+
+func TestNotificationHubWakesWaitersOfUser(t *testing.T) {
+	hub := NewNotificationHub(nil)
+	first, stopFirst := hub.Wait(1)
+	second, stopSecond := hub.Wait(1)
+	other, stopOther := hub.Wait(2)
+	defer stopOther()
+
+	// Notifying twice before anyone reads must not block
+	hub.Notify(1)
+	hub.Notify(1, 3)
+
+	for i, wake := range []<-chan struct{}{first, second} {
+		select {
+		case <-wake:
+		default:
+			t.Errorf("waiter %d of user 1 was not woken", i)
+		}
+	}
+	select {
+	case <-other:
+		t.Error("waiter of user 2 was woken")
+	default:
+	}
+
+	stopFirst()
+	stopSecond()
+	hub.Notify(1)
+	if _, ok := hub.waiters[1]; ok {
+		t.Error("expected stopped waiters to be removed")
+	}
+	if hub.Live() {
+		t.Error("a hub without an event bus should never be live")
+	}
+}
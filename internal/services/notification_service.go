@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+// EntityChange describes an existing entity whose upstream data changed during a sync
+type EntityChange struct {
+	EntityType string   `json:"entity_type"`
+	EntityID   string   `json:"entity_id"`
+	Name       string   `json:"name"`
+	Fields     []string `json:"fields"` // Top-level data keys that were added, removed or changed
+}
+
+// entityChangeIgnoredFields are bookkeeping keys that change without the content changing
+var entityChangeIgnoredFields = map[string]bool{"updatedAt": true, "updated_at": true}
+
+// diffEntityData compares an entity's stored data with the incoming data and returns the
+// change, or nil if nothing differs
+func diffEntityData(entityType, entityID, name string, previous, current map[string]interface{}) *EntityChange {
+	var fields []string
+	for key, value := range current {
+		if old, ok := previous[key]; (!ok || !reflect.DeepEqual(old, value)) && !entityChangeIgnoredFields[key] {
+			fields = append(fields, key)
+		}
+	}
+	for key := range previous {
+		if _, ok := current[key]; !ok && !entityChangeIgnoredFields[key] {
+			fields = append(fields, key)
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	sort.Strings(fields)
+	return &EntityChange{EntityType: entityType, EntityID: entityID, Name: name, Fields: fields}
+}
+
+// entityChangeLabels names entity types in notification titles
+var entityChangeLabels = map[string]string{
+	models.FavoriteEntityQuest:     "Quest",
+	models.FavoriteEntityItem:      "Item",
+	models.FavoriteEntityMap:       "Map",
+	models.NoteEntityHideoutModule: "Hideout module",
+}
+
+type NotificationService struct {
+	notificationRepo          *repository.NotificationRepository
+	favoriteRepo              *repository.FavoriteRepository
+	questProgressRepo         *repository.UserQuestProgressRepository
+	hideoutModuleProgressRepo *repository.UserHideoutModuleProgressRepository
+	eventBus                  *EventBus
+}
+
+func NewNotificationService(
+	notificationRepo *repository.NotificationRepository,
+	favoriteRepo *repository.FavoriteRepository,
+	questProgressRepo *repository.UserQuestProgressRepository,
+	hideoutModuleProgressRepo *repository.UserHideoutModuleProgressRepository,
+	eventBus *EventBus,
+) *NotificationService {
+	return &NotificationService{
+		notificationRepo:          notificationRepo,
+		favoriteRepo:              favoriteRepo,
+		questProgressRepo:         questProgressRepo,
+		hideoutModuleProgressRepo: hideoutModuleProgressRepo,
+		eventBus:                  eventBus,
+	}
+}
+
+// NotifyEntityChanges sends each changed entity's notification to the users who favorited
+// it or have it in progress (uncompleted quests, tracked hideout modules). A user interested
+// for both reasons gets a single notification.
+func (s *NotificationService) NotifyEntityChanges(changes []EntityChange) (int, error) {
+	byType := make(map[string][]string)
+	changeByKey := make(map[string]EntityChange, len(changes))
+	for _, change := range changes {
+		byType[change.EntityType] = append(byType[change.EntityType], change.EntityID)
+		changeByKey[change.EntityType+":"+change.EntityID] = change
+	}
+
+	var notifications []models.Notification
+	for entityType, ids := range byType {
+		users, err := s.interestedUsers(entityType, ids)
+		if err != nil {
+			return 0, err
+		}
+		seen := make(map[string]bool, len(users))
+		for _, u := range users {
+			key := fmt.Sprintf("%s:%d", u.EntityID, u.UserID)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			notifications = append(notifications, entityChangeNotification(u.UserID, changeByKey[entityType+":"+u.EntityID]))
+		}
+	}
+
+	if err := s.notificationRepo.CreateBatch(notifications); err != nil {
+		return 0, err
+	}
+	s.eventBus.PublishNotifications(context.Background(), notifications)
+	return len(notifications), nil
+}
+
+func (s *NotificationService) interestedUsers(entityType string, ids []string) ([]repository.EntityUser, error) {
+	users, err := s.favoriteRepo.FindUsersByEntities(entityType, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find favorites: %w", err)
+	}
+
+	var tracking []repository.EntityUser
+	switch entityType {
+	case models.FavoriteEntityQuest:
+		tracking, err = s.questProgressRepo.FindUsersTracking(ids)
+	case models.NoteEntityHideoutModule:
+		tracking, err = s.hideoutModuleProgressRepo.FindUsersTracking(ids)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find progress: %w", err)
+	}
+	return append(users, tracking...), nil
+}
+
+func entityChangeNotification(userID uint, change EntityChange) models.Notification {
+	label := entityChangeLabels[change.EntityType]
+	if label == "" {
+		label = "Entry"
+	}
+	name := change.Name
+	if name == "" {
+		name = change.EntityID
+	}
+
+	fields := make([]interface{}, len(change.Fields))
+	for i, field := range change.Fields {
+		fields[i] = field
+	}
+
+	return models.Notification{
+		UserID:     userID,
+		Type:       models.NotificationEntityChanged,
+		EntityType: change.EntityType,
+		EntityID:   change.EntityID,
+		Title:      fmt.Sprintf("%s updated: %s", label, name),
+		Body:       "Changed: " + strings.Join(change.Fields, ", "),
+		Data:       models.JSONB{"fields": fields},
+	}
+}
+
+// NotifyTraderStock tells the users who favorited an item that it is back in stock. A user
+// gets one notification per item, naming every trader that stocks it.
+func (s *NotificationService) NotifyTraderStock(arrivals []TraderStock) (int, error) {
+	byItem := make(map[string][]TraderStock)
+	var ids []string
+	for _, arrival := range arrivals {
+		if _, ok := byItem[arrival.ItemID]; !ok {
+			ids = append(ids, arrival.ItemID)
+		}
+		byItem[arrival.ItemID] = append(byItem[arrival.ItemID], arrival)
+	}
+
+	users, err := s.favoriteRepo.FindUsersByEntities(models.FavoriteEntityItem, ids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find favorites: %w", err)
+	}
+	notifications := make([]models.Notification, 0, len(users))
+	for _, u := range users {
+		notifications = append(notifications, traderStockNotification(u.UserID, byItem[u.EntityID]))
+	}
+
+	if err := s.notificationRepo.CreateBatch(notifications); err != nil {
+		return 0, err
+	}
+	s.eventBus.PublishNotifications(context.Background(), notifications)
+	return len(notifications), nil
+}
+
+func traderStockNotification(userID uint, stock []TraderStock) models.Notification {
+	name := stock[0].ItemName
+	if name == "" {
+		name = stock[0].ItemID
+	}
+	traders := make([]string, len(stock))
+	traderIDs := make([]interface{}, len(stock))
+	for i, st := range stock {
+		traders[i] = st.TraderName
+		traderIDs[i] = st.TraderID
+	}
+
+	return models.Notification{
+		UserID:     userID,
+		Type:       models.NotificationTraderStock,
+		EntityType: models.FavoriteEntityItem,
+		EntityID:   stock[0].ItemID,
+		Title:      "In stock: " + name,
+		Body:       "Available this rotation from " + strings.Join(traders, ", "),
+		Data:       models.JSONB{"trader_ids": traderIDs},
+	}
+}
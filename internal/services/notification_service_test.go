@@ -0,0 +1,36 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffEntityData(t *testing.T) {
+	previous := map[string]interface{}{
+		"name":       "Trash Day",
+		"objectives": []interface{}{"Loot 3 bins"},
+		"xp":         float64(500),
+		"updatedAt":  "2025-01-01",
+		"legacy":     true,
+	}
+	current := map[string]interface{}{
+		"name":       "Trash Day",
+		"objectives": []interface{}{"Loot 5 bins"},
+		"xp":         float64(500),
+		"updatedAt":  "2025-02-01",
+	}
+
+	change := diffEntityData("quest", "trash_day", "Trash Day", previous, current)
+	if change == nil {
+		t.Fatal("expected a change")
+	}
+	if want := []string{"legacy", "objectives"}; !reflect.DeepEqual(change.Fields, want) {
+		t.Errorf("got fields %v, want %v", change.Fields, want)
+	}
+
+	current["objectives"] = []interface{}{"Loot 3 bins"}
+	current["legacy"] = true
+	if change := diffEntityData("quest", "trash_day", "Trash Day", previous, current); change != nil {
+		t.Errorf("expected only updatedAt to differ and be ignored, got %+v", change)
+	}
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+// Kinds of ProgressChange
+const (
+	ProgressAdded   = "added"
+	ProgressRemoved = "removed"
+	ProgressChanged = "changed"
+)
+
+// ProgressChange is one progress row that differs between two points in time. Before and
+// After hold the row's values, such as completed or level; Before is omitted for added rows
+// and After for removed ones.
+type ProgressChange struct {
+	Category   string                 `json:"category" example:"quests"`
+	EntityID   uint                   `json:"entity_id"`
+	ExternalID string                 `json:"external_id,omitempty"`
+	Change     string                 `json:"change" example:"changed"` // added, removed or changed
+	Before     map[string]interface{} `json:"before,omitempty"`
+	After      map[string]interface{} `json:"after,omitempty"`
+}
+
+// ProgressDiffCounts is how many rows of a category were added, removed and changed
+type ProgressDiffCounts struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	Changed int `json:"changed"`
+}
+
+// ProgressDiffPoint is one side of a diff: a snapshot, or the live progress when
+// SnapshotID is nil
+type ProgressDiffPoint struct {
+	SnapshotID *uint     `json:"snapshot_id"`
+	Reason     string    `json:"reason,omitempty"`
+	TakenAt    time.Time `json:"taken_at"`
+}
+
+// ProgressDiff is how a user's progress changed from one point in time to another
+type ProgressDiff struct {
+	UserID  uint                          `json:"user_id"`
+	From    ProgressDiffPoint             `json:"from"`
+	To      ProgressDiffPoint             `json:"to"`
+	Summary map[string]ProgressDiffCounts `json:"summary"`
+	Changes []ProgressChange              `json:"changes"`
+}
+
+// Diff compares one of a user's snapshots with another, or with their current progress
+// when toID is 0
+func (s *ProgressSnapshotService) Diff(userID, fromID, toID uint, now time.Time) (*ProgressDiff, error) {
+	from, err := s.store.Get(userID, fromID)
+	if err != nil {
+		return nil, err
+	}
+	if from == nil || from.Progress == nil {
+		return nil, ErrProgressSnapshotNotFound
+	}
+
+	var to *models.ProgressSnapshot
+	if toID != 0 {
+		if to, err = s.store.Get(userID, toID); err != nil {
+			return nil, err
+		}
+		if to == nil || to.Progress == nil {
+			return nil, ErrProgressSnapshotNotFound
+		}
+	} else {
+		data, err := s.progressRepo.Load(userID)
+		if err != nil {
+			return nil, err
+		}
+		to = &models.ProgressSnapshot{UserID: userID, TakenAt: now.UTC(), Progress: data}
+	}
+
+	summary, changes := diffProgress(from.Progress, to.Progress)
+	diff := &ProgressDiff{
+		UserID:  userID,
+		From:    ProgressDiffPoint{SnapshotID: &from.ID, Reason: from.Reason, TakenAt: from.TakenAt},
+		To:      ProgressDiffPoint{TakenAt: to.TakenAt},
+		Summary: summary,
+		Changes: changes,
+	}
+	if toID != 0 {
+		diff.To.SnapshotID = &to.ID
+		diff.To.Reason = to.Reason
+	}
+	return diff, nil
+}
+
+// progressRow is a progress row reduced to what a diff compares
+type progressRow struct {
+	externalID string
+	values     map[string]interface{}
+}
+
+// progressRows indexes each category of data by entity ID
+func progressRows(data *models.ProgressSnapshotData) map[string]map[uint]progressRow {
+	rows := map[string]map[uint]progressRow{
+		repository.ProgressCategoryQuests:         make(map[uint]progressRow, len(data.Quests)),
+		repository.ProgressCategoryHideoutModules: make(map[uint]progressRow, len(data.HideoutModules)),
+		repository.ProgressCategorySkillNodes:     make(map[uint]progressRow, len(data.SkillNodes)),
+		repository.ProgressCategoryBlueprints:     make(map[uint]progressRow, len(data.Blueprints)),
+	}
+	for _, p := range data.Quests {
+		rows[repository.ProgressCategoryQuests][p.QuestID] = progressRow{p.QuestExternalID, map[string]interface{}{"completed": p.Completed}}
+	}
+	for _, p := range data.HideoutModules {
+		rows[repository.ProgressCategoryHideoutModules][p.HideoutModuleID] = progressRow{p.HideoutModuleExternalID, map[string]interface{}{"unlocked": p.Unlocked, "level": p.Level}}
+	}
+	for _, p := range data.SkillNodes {
+		rows[repository.ProgressCategorySkillNodes][p.SkillNodeID] = progressRow{p.SkillNodeExternalID, map[string]interface{}{"unlocked": p.Unlocked, "level": p.Level}}
+	}
+	for _, p := range data.Blueprints {
+		rows[repository.ProgressCategoryBlueprints][p.ItemID] = progressRow{p.ItemExternalID, map[string]interface{}{"consumed": p.Consumed}}
+	}
+	return rows
+}
+
+// diffProgress lists the rows added, removed and changed from one copy of a user's progress
+// to another, by category and then entity ID, with counts per category
+func diffProgress(from, to *models.ProgressSnapshotData) (map[string]ProgressDiffCounts, []ProgressChange) {
+	before, after := progressRows(from), progressRows(to)
+	summary := make(map[string]ProgressDiffCounts, len(repository.ProgressCategories))
+	changes := []ProgressChange{}
+
+	for _, category := range repository.ProgressCategories {
+		var counts ProgressDiffCounts
+		ids := make([]uint, 0, len(before[category])+len(after[category]))
+		for id := range before[category] {
+			ids = append(ids, id)
+		}
+		for id := range after[category] {
+			if _, ok := before[category][id]; !ok {
+				ids = append(ids, id)
+			}
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+		for _, id := range ids {
+			old, hadOld := before[category][id]
+			cur, hasCur := after[category][id]
+			change := ProgressChange{Category: category, EntityID: id}
+			switch {
+			case !hadOld:
+				change.Change, change.ExternalID, change.After = ProgressAdded, cur.externalID, cur.values
+				counts.Added++
+			case !hasCur:
+				change.Change, change.ExternalID, change.Before = ProgressRemoved, old.externalID, old.values
+				counts.Removed++
+			case !sameProgressValues(old.values, cur.values):
+				change.Change, change.ExternalID, change.Before, change.After = ProgressChanged, cur.externalID, old.values, cur.values
+				if change.ExternalID == "" {
+					change.ExternalID = old.externalID
+				}
+				counts.Changed++
+			default:
+				continue
+			}
+			changes = append(changes, change)
+		}
+		summary[category] = counts
+	}
+	return summary, changes
+}
+
+func sameProgressValues(a, b map[string]interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if b[key] != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestDiffProgress(t *testing.T) {
+	from := &models.ProgressSnapshotData{
+		Quests: []models.UserQuestProgress{
+			{QuestID: 1, QuestExternalID: "q1", Completed: true},
+			{QuestID: 2, QuestExternalID: "q2", Completed: false},
+		},
+		HideoutModules: []models.UserHideoutModuleProgress{
+			{HideoutModuleID: 4, HideoutModuleExternalID: "workbench", Unlocked: true, Level: 2},
+		},
+	}
+	to := &models.ProgressSnapshotData{
+		Quests: []models.UserQuestProgress{
+			{QuestID: 2, QuestExternalID: "q2", Completed: true},
+			{QuestID: 3, QuestExternalID: "q3", Completed: false},
+		},
+		HideoutModules: []models.UserHideoutModuleProgress{
+			{HideoutModuleID: 4, HideoutModuleExternalID: "workbench", Unlocked: true, Level: 2},
+		},
+		Blueprints: []models.UserBlueprintProgress{{ItemID: 9, ItemExternalID: "bp", Consumed: true}},
+	}
+
+	summary, changes := diffProgress(from, to)
+
+	want := []struct {
+		category string
+		id       uint
+		change   string
+	}{
+		{"quests", 1, ProgressRemoved},
+		{"quests", 2, ProgressChanged},
+		{"quests", 3, ProgressAdded},
+		{"blueprints", 9, ProgressAdded},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(changes), len(want), changes)
+	}
+	for i, w := range want {
+		c := changes[i]
+		if c.Category != w.category || c.EntityID != w.id || c.Change != w.change {
+			t.Errorf("change %d = %s %d %s, want %s %d %s", i, c.Category, c.EntityID, c.Change, w.category, w.id, w.change)
+		}
+	}
+	if c := changes[1]; c.ExternalID != "q2" || c.Before["completed"] != false || c.After["completed"] != true {
+		t.Errorf("changed quest should carry before and after values, got %+v", c)
+	}
+	if c := changes[0]; c.After != nil || c.Before["completed"] != true {
+		t.Errorf("removed quest should only carry before values, got %+v", c)
+	}
+
+	if got := summary["quests"]; got != (ProgressDiffCounts{Added: 1, Removed: 1, Changed: 1}) {
+		t.Errorf("quests summary = %+v", got)
+	}
+	if got := summary["hideout_modules"]; got != (ProgressDiffCounts{}) {
+		t.Errorf("unchanged hideout modules summary = %+v", got)
+	}
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mat/arcapi/internal/logging"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+var snapshotLog = logging.For("progress_snapshots")
+
+const JobProgressSnapshot = "progress_snapshot"
+
+// ErrProgressSnapshotNotFound is returned when a user has no snapshot with the given ID
+var ErrProgressSnapshotNotFound = errors.New("progress snapshot not found")
+
+// ProgressSnapshotStore is where user progress snapshots are kept. List leaves out the
+// progress itself; Get returns nil when the user has no such snapshot.
+type ProgressSnapshotStore interface {
+	Save(snapshot *models.ProgressSnapshot) error
+	List(userID uint) ([]models.ProgressSnapshot, error)
+	Get(userID, id uint) (*models.ProgressSnapshot, error)
+	DeleteBefore(cutoff time.Time) (int64, error)
+}
+
+// ProgressSnapshotRestore is the outcome of restoring a snapshot
+type ProgressSnapshotRestore struct {
+	Snapshot models.ProgressSnapshot `json:"snapshot"`
+	BackupID uint                    `json:"backup_id"` // The snapshot of the progress that was overwritten
+	Skipped  int                     `json:"skipped"`   // Rows left out because their entity no longer exists
+}
+
+// ProgressSnapshotService takes a daily snapshot of every user's progress and restores
+// them on request, so progress lost to an accidental reset or a buggy client sync can be
+// recovered for the retention period
+type ProgressSnapshotService struct {
+	progressRepo  *repository.UserProgressRepository
+	store         ProgressSnapshotStore
+	retentionDays int
+}
+
+func NewProgressSnapshotService(progressRepo *repository.UserProgressRepository, store ProgressSnapshotStore, retentionDays int) *ProgressSnapshotService {
+	return &ProgressSnapshotService{progressRepo: progressRepo, store: store, retentionDays: retentionDays}
+}
+
+// Enabled reports whether snapshots are taken at all
+func (s *ProgressSnapshotService) Enabled() bool {
+	return s.retentionDays > 0
+}
+
+// Run is the progress_snapshot job: it snapshots every user with progress, then deletes
+// snapshots older than the retention period. A user whose snapshot fails is logged and
+// skipped so the others are still covered.
+func (s *ProgressSnapshotService) Run(startedAt time.Time) (int64, error) {
+	if !s.Enabled() {
+		return 0, nil
+	}
+	userIDs, err := s.progressRepo.UserIDsWithProgress()
+	if err != nil {
+		return 0, err
+	}
+
+	var taken, failed int64
+	for _, userID := range userIDs {
+		if _, err := s.Take(userID, models.ProgressSnapshotScheduled, startedAt); err != nil {
+			snapshotLog.Error("Failed to snapshot progress", "user_id", userID, "error", err)
+			failed++
+			continue
+		}
+		taken++
+	}
+
+	if _, err := s.store.DeleteBefore(startedAt.AddDate(0, 0, -s.retentionDays)); err != nil {
+		return taken, fmt.Errorf("failed to prune progress snapshots: %w", err)
+	}
+	if failed > 0 {
+		return taken, fmt.Errorf("failed to snapshot progress of %d of %d users", failed, len(userIDs))
+	}
+	return taken, nil
+}
+
+// Take snapshots a user's current progress
+func (s *ProgressSnapshotService) Take(userID uint, reason string, takenAt time.Time) (*models.ProgressSnapshot, error) {
+	data, err := s.progressRepo.Load(userID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &models.ProgressSnapshot{
+		UserID:   userID,
+		TakenAt:  takenAt.UTC(),
+		Reason:   reason,
+		Counts:   data.Counts(),
+		Progress: data,
+	}
+	if err := s.store.Save(snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// List returns a user's snapshots, newest first
+func (s *ProgressSnapshotService) List(userID uint) ([]models.ProgressSnapshot, error) {
+	snapshots, err := s.store.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	if snapshots == nil {
+		snapshots = []models.ProgressSnapshot{}
+	}
+	return snapshots, nil
+}
+
+// Restore overwrites a user's progress with one of their snapshots, snapshotting the
+// progress it replaces first so the restore itself can be undone
+func (s *ProgressSnapshotService) Restore(userID, id uint, now time.Time) (*ProgressSnapshotRestore, error) {
+	snapshot, err := s.store.Get(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot == nil || snapshot.Progress == nil {
+		return nil, ErrProgressSnapshotNotFound
+	}
+
+	backup, err := s.Take(userID, models.ProgressSnapshotPreRestore, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot current progress: %w", err)
+	}
+	skipped, err := s.progressRepo.Restore(userID, snapshot.Progress)
+	if err != nil {
+		return nil, err
+	}
+
+	restored := *snapshot
+	restored.Progress = nil
+	return &ProgressSnapshotRestore{Snapshot: restored, BackupID: backup.ID, Skipped: skipped}, nil
+}
+
+// ProgressSnapshotDirStore keeps snapshots as JSON files under dir, one directory per user.
+// A snapshot's ID is the Unix millisecond it was taken at.
+type ProgressSnapshotDirStore struct {
+	dir string
+}
+
+func NewProgressSnapshotDirStore(dir string) *ProgressSnapshotDirStore {
+	return &ProgressSnapshotDirStore{dir: dir}
+}
+
+func (s *ProgressSnapshotDirStore) userDir(userID uint) string {
+	return filepath.Join(s.dir, strconv.FormatUint(uint64(userID), 10))
+}
+
+func (s *ProgressSnapshotDirStore) Save(snapshot *models.ProgressSnapshot) error {
+	dir := s.userDir(snapshot.UserID)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	snapshot.ID = uint(snapshot.TakenAt.UnixMilli())
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so a crash never leaves a truncated snapshot behind
+	tmp, err := os.CreateTemp(dir, ".snapshot-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, snapshotFileName(snapshot.ID)))
+}
+
+func (s *ProgressSnapshotDirStore) List(userID uint) ([]models.ProgressSnapshot, error) {
+	ids, err := s.ids(s.userDir(userID))
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make([]models.ProgressSnapshot, 0, len(ids))
+	for _, id := range ids {
+		snapshot, err := s.Get(userID, id)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot != nil {
+			snapshot.Progress = nil
+			snapshots = append(snapshots, *snapshot)
+		}
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].ID > snapshots[j].ID })
+	return snapshots, nil
+}
+
+func (s *ProgressSnapshotDirStore) Get(userID, id uint) (*models.ProgressSnapshot, error) {
+	data, err := os.ReadFile(filepath.Join(s.userDir(userID), snapshotFileName(id)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var snapshot models.ProgressSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("corrupt progress snapshot %d of user %d: %w", id, userID, err)
+	}
+	return &snapshot, nil
+}
+
+func (s *ProgressSnapshotDirStore) DeleteBefore(cutoff time.Time) (int64, error) {
+	users, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var deleted int64
+	for _, user := range users {
+		if !user.IsDir() {
+			continue
+		}
+		dir := filepath.Join(s.dir, user.Name())
+		ids, err := s.ids(dir)
+		if err != nil {
+			return deleted, err
+		}
+		for _, id := range ids {
+			if int64(id) >= cutoff.UnixMilli() {
+				continue
+			}
+			if err := os.Remove(filepath.Join(dir, snapshotFileName(id))); err != nil {
+				return deleted, err
+			}
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// ids returns the IDs of the snapshot files in dir
+func (s *ProgressSnapshotDirStore) ids(dir string) ([]uint, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ids []uint
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		if id, err := strconv.ParseUint(name, 10, 64); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids, nil
+}
+
+func snapshotFileName(id uint) string {
+	return strconv.FormatUint(uint64(id), 10) + ".json"
+}
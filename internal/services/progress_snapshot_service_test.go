@@ -0,0 +1,72 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestProgressSnapshotDirStore(t *testing.T) {
+	store := NewProgressSnapshotDirStore(t.TempDir())
+	day := time.Date(2026, 10, 15, 4, 15, 0, 0, time.UTC)
+
+	var ids []uint
+	for i, userID := range []uint{7, 7, 8} {
+		data := &models.ProgressSnapshotData{
+			Quests:     []models.UserQuestProgress{{QuestID: 3, Completed: true}},
+			Blueprints: []models.UserBlueprintProgress{},
+		}
+		snapshot := &models.ProgressSnapshot{
+			UserID:   userID,
+			TakenAt:  day.AddDate(0, 0, i),
+			Reason:   models.ProgressSnapshotScheduled,
+			Counts:   data.Counts(),
+			Progress: data,
+		}
+		if err := store.Save(snapshot); err != nil {
+			t.Fatalf("save failed: %v", err)
+		}
+		ids = append(ids, snapshot.ID)
+	}
+
+	listed, err := store.List(7)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(listed) != 2 || listed[0].ID != ids[1] || listed[1].ID != ids[0] {
+		t.Fatalf("expected user 7's snapshots newest first, got %+v", listed)
+	}
+	if listed[0].Progress != nil || listed[0].Counts.Quests != 1 {
+		t.Errorf("list should carry counts but not progress, got %+v", listed[0])
+	}
+
+	got, err := store.Get(7, ids[0])
+	if err != nil || got == nil || got.Progress == nil || !got.Progress.Quests[0].Completed {
+		t.Fatalf("expected snapshot with progress, got %+v (%v)", got, err)
+	}
+	if got, err := store.Get(8, ids[0]); err != nil || got != nil {
+		t.Errorf("another user's snapshot should not be found, got %+v (%v)", got, err)
+	}
+
+	deleted, err := store.DeleteBefore(day.AddDate(0, 0, 2))
+	if err != nil || deleted != 2 {
+		t.Fatalf("expected 2 snapshots pruned, got %d (%v)", deleted, err)
+	}
+	if listed, _ := store.List(7); len(listed) != 0 {
+		t.Errorf("expected user 7's snapshots pruned, got %+v", listed)
+	}
+	if listed, _ := store.List(8); len(listed) != 1 {
+		t.Errorf("expected user 8's snapshot kept, got %+v", listed)
+	}
+}
+
+func TestProgressSnapshotDirStoreEmpty(t *testing.T) {
+	store := NewProgressSnapshotDirStore(t.TempDir() + "/missing")
+	if listed, err := store.List(1); err != nil || len(listed) != 0 {
+		t.Errorf("expected no snapshots, got %+v (%v)", listed, err)
+	}
+	if deleted, err := store.DeleteBefore(time.Now()); err != nil || deleted != 0 {
+		t.Errorf("expected nothing pruned, got %d (%v)", deleted, err)
+	}
+}
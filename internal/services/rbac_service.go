@@ -0,0 +1,182 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+// roleCacheTTL bounds how long role permission changes take to reach every instance
+const roleCacheTTL = time.Minute
+
+// ErrInvalidPermission is returned when a role is given an unknown permission string
+var ErrInvalidPermission = errors.New("invalid permission")
+
+// ErrPermissionEscalation is returned when a user tries to hand out permissions they don't
+// hold themselves, or to change the role of someone who holds more
+var ErrPermissionEscalation = errors.New("permission escalation")
+
+// RBACService resolves user roles to permissions. Roles are few and read on every
+// protected request, so they are kept in memory and reloaded periodically.
+type RBACService struct {
+	roleRepo *repository.RoleRepository
+
+	mu       sync.RWMutex
+	roles    map[string]models.Role
+	loadedAt time.Time
+}
+
+func NewRBACService(roleRepo *repository.RoleRepository) *RBACService {
+	return &RBACService{roleRepo: roleRepo}
+}
+
+// HasPermission reports whether the user's role grants permission.
+// The admin role always has every permission so a bad role edit cannot lock admins out.
+func (s *RBACService) HasPermission(user *models.User, permission string) bool {
+	if user == nil {
+		return false
+	}
+	if user.Role == models.RoleAdmin {
+		return true
+	}
+	role, ok := s.role(string(user.Role))
+	return ok && role.HasPermission(permission)
+}
+
+// IsAdmin reports whether the user has the admin role
+func (s *RBACService) IsAdmin(user *models.User) bool {
+	return user != nil && user.Role == models.RoleAdmin
+}
+
+// holdsAll reports whether the user's role grants every one of permissions
+func (s *RBACService) holdsAll(user *models.User, permissions []string) bool {
+	for _, p := range permissions {
+		if !s.HasPermission(user, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// rolePermissions returns the permissions a role grants, every permission for admin
+func (s *RBACService) rolePermissions(name string) []string {
+	return s.Permissions(&models.User{Role: models.UserRole(name)})
+}
+
+// CheckSaveRole returns ErrPermissionEscalation unless actor may give role name the
+// permissions: actor must hold every one of them, and every permission the role has now,
+// so a role can't be used to pass on or strip rights the actor doesn't have
+func (s *RBACService) CheckSaveRole(actor *models.User, name string, permissions []string) error {
+	if !s.holdsAll(actor, permissions) || !s.holdsAll(actor, s.rolePermissions(name)) {
+		return ErrPermissionEscalation
+	}
+	return nil
+}
+
+// CheckAssignRole returns ErrPermissionEscalation unless actor may move target to role:
+// only admins can change an admin's role or grant admin, and actor must hold every
+// permission of both target's current role and the new one
+func (s *RBACService) CheckAssignRole(actor, target *models.User, role string) error {
+	if s.IsAdmin(actor) {
+		return nil
+	}
+	if s.IsAdmin(target) || role == string(models.RoleAdmin) {
+		return ErrPermissionEscalation
+	}
+	if !s.holdsAll(actor, s.Permissions(target)) || !s.holdsAll(actor, s.rolePermissions(role)) {
+		return ErrPermissionEscalation
+	}
+	return nil
+}
+
+// Permissions returns the permissions granted to the user
+func (s *RBACService) Permissions(user *models.User) []string {
+	if user == nil {
+		return []string{}
+	}
+	if user.Role == models.RoleAdmin {
+		return models.AllPermissions
+	}
+	role, ok := s.role(string(user.Role))
+	if !ok || role.Permissions == nil {
+		return []string{}
+	}
+	return role.Permissions
+}
+
+// RoleExists reports whether a role with this name is defined
+func (s *RBACService) RoleExists(name string) bool {
+	_, ok := s.role(name)
+	return ok
+}
+
+// ListRoles returns all defined roles
+func (s *RBACService) ListRoles() ([]models.Role, error) {
+	return s.roleRepo.ListAll()
+}
+
+// SaveRole creates a role or replaces the permissions of an existing one
+func (s *RBACService) SaveRole(name, description string, permissions []string) (*models.Role, error) {
+	for _, p := range permissions {
+		if !models.IsValidPermission(p) {
+			return nil, ErrInvalidPermission
+		}
+	}
+
+	role, err := s.roleRepo.FindByName(name)
+	if err != nil {
+		role = &models.Role{Name: name, Description: description, Permissions: models.StringList(permissions)}
+		err = s.roleRepo.Create(role)
+	} else {
+		if description != "" {
+			role.Description = description
+		}
+		role.Permissions = models.StringList(permissions)
+		err = s.roleRepo.Update(role)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.Invalidate()
+	return role, nil
+}
+
+// Invalidate forces the next lookup to reload roles from the database
+func (s *RBACService) Invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+func (s *RBACService) role(name string) (models.Role, bool) {
+	s.mu.RLock()
+	fresh := time.Since(s.loadedAt) < roleCacheTTL
+	role, ok := s.roles[name]
+	s.mu.RUnlock()
+	if fresh {
+		return role, ok
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if time.Since(s.loadedAt) >= roleCacheTTL {
+		roles, err := s.roleRepo.ListAll()
+		if err != nil {
+			// Keep serving the previous snapshot rather than denying everything
+			log.Printf("Warning: Failed to load roles: %v", err)
+		} else {
+			s.roles = make(map[string]models.Role, len(roles))
+			for _, r := range roles {
+				s.roles[r.Name] = r
+			}
+			s.loadedAt = time.Now()
+		}
+	}
+	role, ok = s.roles[name]
+	return role, ok
+}
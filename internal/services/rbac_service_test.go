@@ -0,0 +1,113 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+// newTestRBACService returns an RBACService serving roles from memory
+func newTestRBACService(roles ...models.Role) *RBACService {
+	s := &RBACService{roles: make(map[string]models.Role), loadedAt: time.Now()}
+	for _, r := range roles {
+		s.roles[r.Name] = r
+	}
+	return s
+}
+
+func testRoles() []models.Role {
+	return []models.Role{
+		{Name: string(models.RoleUser), Permissions: models.StringList{}},
+		{Name: string(models.RoleModerator), Permissions: models.StringList{models.PermManageAlerts}},
+		{Name: "user_manager", Permissions: models.StringList{models.PermManageUsers, models.PermManageRoles}},
+		{Name: "data_manager", Permissions: models.StringList{models.PermManageData}},
+	}
+}
+
+func TestHasPermission(t *testing.T) {
+	s := newTestRBACService(testRoles()...)
+
+	tests := []struct {
+		role       models.UserRole
+		permission string
+		want       bool
+	}{
+		{models.RoleAdmin, models.PermManageRoles, true},
+		{models.RoleModerator, models.PermManageAlerts, true},
+		{models.RoleModerator, models.PermManageUsers, false},
+		{models.RoleUser, models.PermManageAlerts, false},
+		{"unknown", models.PermManageAlerts, false},
+	}
+	for _, tt := range tests {
+		if got := s.HasPermission(&models.User{Role: tt.role}, tt.permission); got != tt.want {
+			t.Errorf("HasPermission(%s, %s) = %v, want %v", tt.role, tt.permission, got, tt.want)
+		}
+	}
+	if s.HasPermission(nil, models.PermManageAlerts) {
+		t.Error("expected no permissions without a user")
+	}
+}
+
+func TestCheckSaveRole(t *testing.T) {
+	s := newTestRBACService(testRoles()...)
+	admin := &models.User{Role: models.RoleAdmin}
+	manager := &models.User{Role: "user_manager"}
+
+	tests := []struct {
+		name        string
+		actor       *models.User
+		role        string
+		permissions []string
+		wantErr     bool
+	}{
+		{"admin grants anything", admin, "data_manager", models.AllPermissions, false},
+		{"permissions the actor holds", manager, "support", []string{models.PermManageUsers}, false},
+		{"permission the actor lacks", manager, "support", []string{models.PermManageData}, true},
+		{"grants itself more", manager, "user_manager", []string{models.PermManageUsers, models.PermManageRoles, models.PermManageData}, true},
+		{"strips a role with more", manager, "data_manager", []string{}, true},
+		{"no actor", nil, "support", []string{}, false},
+	}
+	for _, tt := range tests {
+		err := s.CheckSaveRole(tt.actor, tt.role, tt.permissions)
+		if tt.wantErr && !errors.Is(err, ErrPermissionEscalation) {
+			t.Errorf("%s: expected ErrPermissionEscalation, got %v", tt.name, err)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+	}
+}
+
+func TestCheckAssignRole(t *testing.T) {
+	s := newTestRBACService(testRoles()...)
+	admin := &models.User{Role: models.RoleAdmin}
+	manager := &models.User{Role: "user_manager"}
+	user := &models.User{Role: models.RoleUser}
+
+	tests := []struct {
+		name    string
+		actor   *models.User
+		target  *models.User
+		role    string
+		wantErr bool
+	}{
+		{"admin grants admin", admin, user, string(models.RoleAdmin), false},
+		{"admin demotes admin", admin, &models.User{Role: models.RoleAdmin}, string(models.RoleUser), false},
+		{"manager grants admin", manager, user, string(models.RoleAdmin), true},
+		{"manager demotes admin", manager, admin, string(models.RoleUser), true},
+		{"manager grants a role it covers", manager, user, "user_manager", false},
+		{"manager grants a role with more", manager, user, "data_manager", true},
+		{"manager demotes a role with more", manager, &models.User{Role: "data_manager"}, string(models.RoleUser), true},
+	}
+	for _, tt := range tests {
+		err := s.CheckAssignRole(tt.actor, tt.target, tt.role)
+		if tt.wantErr && !errors.Is(err, ErrPermissionEscalation) {
+			t.Errorf("%s: expected ErrPermissionEscalation, got %v", tt.name, err)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+	}
+}
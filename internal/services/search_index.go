@@ -0,0 +1,89 @@
+package services
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+// searchObjectivesPrefix is the translation path prefix of quest objectives
+const searchObjectivesPrefix = "objectives."
+
+// buildSearchDocuments builds one search document per entity and language from the
+// translations of an entity type. The body is the name, description and objectives in
+// that order; fields an entity has no translation of in a language are taken from English.
+// Entities without a name are left out.
+func buildSearchDocuments(entityType string, translations []models.Translation, syncedAt time.Time) []models.SearchDocument {
+	// texts[entityID][field][lang]
+	texts := make(map[string]map[string]map[string]string)
+	var entityIDs []string
+	for _, t := range translations {
+		fields, ok := texts[t.EntityID]
+		if !ok {
+			fields = make(map[string]map[string]string)
+			texts[t.EntityID] = fields
+			entityIDs = append(entityIDs, t.EntityID)
+		}
+		if fields[t.Field] == nil {
+			fields[t.Field] = make(map[string]string)
+		}
+		fields[t.Field][t.Lang] = t.Text
+	}
+
+	var documents []models.SearchDocument
+	for _, entityID := range entityIDs {
+		fields := texts[entityID]
+		searchFields := append([]string{"name", "description"}, objectiveFields(fields)...)
+		for _, lang := range models.TranslationLanguages {
+			text := func(field string) string {
+				if t := fields[field][lang]; t != "" {
+					return t
+				}
+				return fields[field]["en"]
+			}
+			name := text("name")
+			if name == "" {
+				continue
+			}
+			lines := make([]string, 0, len(searchFields))
+			for _, field := range searchFields {
+				if t := text(field); t != "" {
+					lines = append(lines, t)
+				}
+			}
+			documents = append(documents, models.SearchDocument{
+				EntityType: entityType,
+				EntityID:   entityID,
+				Lang:       lang,
+				Name:       name,
+				Body:       strings.Join(lines, "\n"),
+				SyncedAt:   syncedAt,
+			})
+		}
+	}
+	return documents
+}
+
+// objectiveFields returns the objective paths among fields ("objectives.0", ...) in order.
+// Objectives with nested translated parts are left out.
+func objectiveFields(fields map[string]map[string]string) []string {
+	var paths []string
+	for field := range fields {
+		index, ok := strings.CutPrefix(field, searchObjectivesPrefix)
+		if !ok {
+			continue
+		}
+		if _, err := strconv.Atoi(index); err == nil {
+			paths = append(paths, field)
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		a, _ := strconv.Atoi(strings.TrimPrefix(paths[i], searchObjectivesPrefix))
+		b, _ := strconv.Atoi(strings.TrimPrefix(paths[j], searchObjectivesPrefix))
+		return a < b
+	})
+	return paths
+}
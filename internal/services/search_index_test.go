@@ -0,0 +1,46 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestBuildSearchDocumentsPerLanguage(t *testing.T) {
+	objectives := make([]interface{}, 11)
+	for i := range objectives {
+		objectives[i] = map[string]interface{}{"en": "Step " + string(rune('A'+i))}
+	}
+	objectives[0] = map[string]interface{}{"en": "Get 3 ARC Alloy", "de": "Besorge 3 ARC-Legierung"}
+	data := map[string]interface{}{
+		"name":        map[string]interface{}{"en": "Trash Into Treasure", "de": "Aus Müll wird Gold"},
+		"description": map[string]interface{}{"en": "Scrap is worth something."},
+		"objectives":  objectives,
+	}
+	translations := extractTranslations("quest", "trash_into_treasure", data, time.Now())
+	translations = append(translations, extractTranslations("quest", "unnamed", map[string]interface{}{
+		"description": map[string]interface{}{"en": "No name"},
+	}, time.Now())...)
+
+	documents := buildSearchDocuments("quest", translations, time.Now())
+	if len(documents) != len(models.TranslationLanguages) {
+		t.Fatalf("expected a document per language for the named quest only, got %d", len(documents))
+	}
+	byLang := make(map[string]models.SearchDocument, len(documents))
+	for _, doc := range documents {
+		byLang[doc.Lang] = doc
+	}
+
+	de := byLang["de"]
+	if de.Name != "Aus Müll wird Gold" {
+		t.Errorf("expected the German name, got %q", de.Name)
+	}
+	wantDE := "Aus Müll wird Gold\nScrap is worth something.\nBesorge 3 ARC-Legierung\nStep B\nStep C\nStep D\nStep E\nStep F\nStep G\nStep H\nStep I\nStep J\nStep K"
+	if de.Body != wantDE {
+		t.Errorf("expected the German body with English fallbacks and objectives in order, got %q", de.Body)
+	}
+	if fr := byLang["fr"]; fr.Name != "Trash Into Treasure" || fr.EntityType != "quest" || fr.EntityID != "trash_into_treasure" {
+		t.Errorf("expected the French document to fall back to English, got %+v", fr)
+	}
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+// captchaVerifyTimeout bounds the call to the captcha provider
+const captchaVerifyTimeout = 5 * time.Second
+
+var (
+	ErrInvalidSecurityReport = errors.New("invalid security report")
+	ErrCaptchaFailed         = errors.New("captcha verification failed")
+)
+
+// securityReportSeverities are the severities a reporter may claim
+var securityReportSeverities = map[string]bool{"": true, "low": true, "medium": true, "high": true, "critical": true}
+
+// SecurityReport is a vulnerability report submitted by anyone, signed in or not
+type SecurityReport struct {
+	Title         string `json:"title" example:"Stored XSS in map marker labels"`
+	Description   string `json:"description" example:"Marker labels are rendered unescaped on the community site..."`
+	Severity      string `json:"severity,omitempty" example:"high"` // low, medium, high or critical
+	ReporterName  string `json:"reporter_name,omitempty" example:"Jane Doe"`
+	ReporterEmail string `json:"reporter_email,omitempty" example:"jane@example.com"`
+	CaptchaToken  string `json:"captcha_token,omitempty"` // Required when a captcha secret is configured
+}
+
+func (r *SecurityReport) normalize() error {
+	r.Title = strings.TrimSpace(r.Title)
+	r.Description = strings.TrimSpace(r.Description)
+	r.Severity = strings.ToLower(strings.TrimSpace(r.Severity))
+	r.ReporterName = strings.TrimSpace(r.ReporterName)
+	r.ReporterEmail = strings.TrimSpace(r.ReporterEmail)
+
+	if r.Title == "" || len(r.Title) > 200 {
+		return fmt.Errorf("%w: title must be 1 to 200 characters", ErrInvalidSecurityReport)
+	}
+	if len(r.Description) < 20 || len(r.Description) > 20000 {
+		return fmt.Errorf("%w: description must be 20 to 20000 characters", ErrInvalidSecurityReport)
+	}
+	if !securityReportSeverities[r.Severity] {
+		return fmt.Errorf("%w: severity must be low, medium, high or critical", ErrInvalidSecurityReport)
+	}
+	if len(r.ReporterName) > 100 {
+		return fmt.Errorf("%w: reporter_name must be at most 100 characters", ErrInvalidSecurityReport)
+	}
+	if r.ReporterEmail != "" {
+		if addr, err := mail.ParseAddress(r.ReporterEmail); err != nil || addr.Address != r.ReporterEmail || len(r.ReporterEmail) > 254 {
+			return fmt.Errorf("%w: reporter_email must be an email address", ErrInvalidSecurityReport)
+		}
+	}
+	return nil
+}
+
+// SecurityReportService takes vulnerability reports from the public, stores them as
+// security events and notifies every admin
+type SecurityReportService struct {
+	eventRepo        *repository.SecurityEventRepository
+	userRepo         *repository.UserRepository
+	notificationRepo *repository.NotificationRepository
+	eventBus         *EventBus
+	captchaSecret    string
+	captchaVerifyURL string
+	httpClient       *http.Client
+}
+
+func NewSecurityReportService(
+	eventRepo *repository.SecurityEventRepository,
+	userRepo *repository.UserRepository,
+	notificationRepo *repository.NotificationRepository,
+	eventBus *EventBus,
+	captchaSecret, captchaVerifyURL string,
+) *SecurityReportService {
+	return &SecurityReportService{
+		eventRepo:        eventRepo,
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		eventBus:         eventBus,
+		captchaSecret:    captchaSecret,
+		captchaVerifyURL: captchaVerifyURL,
+		httpClient:       &http.Client{Timeout: captchaVerifyTimeout},
+	}
+}
+
+// CaptchaRequired reports whether reports must carry a captcha token
+func (s *SecurityReportService) CaptchaRequired() bool {
+	return s.captchaSecret != ""
+}
+
+// Submit validates and stores a report. Admins are notified in their notification feed;
+// failing to notify them is logged but doesn't reject the report.
+func (s *SecurityReportService) Submit(ctx context.Context, report SecurityReport, ipAddress, userAgent string) (*models.SecurityEvent, error) {
+	if err := report.normalize(); err != nil {
+		return nil, err
+	}
+	if s.CaptchaRequired() {
+		if err := s.verifyCaptcha(ctx, report.CaptchaToken, ipAddress); err != nil {
+			return nil, err
+		}
+	}
+
+	event := &models.SecurityEvent{
+		Type:          models.SecurityEventVulnerabilityReport,
+		Status:        models.SecurityEventStatusNew,
+		Severity:      report.Severity,
+		Title:         report.Title,
+		Description:   report.Description,
+		ReporterName:  report.ReporterName,
+		ReporterEmail: report.ReporterEmail,
+		IPAddress:     ipAddress,
+		UserAgent:     userAgent,
+	}
+	if err := s.eventRepo.Create(event); err != nil {
+		return nil, err
+	}
+
+	if err := s.notifyAdmins(event); err != nil {
+		log.Printf("Failed to notify admins of security event %d: %v", event.ID, err)
+	}
+	return event, nil
+}
+
+func (s *SecurityReportService) notifyAdmins(event *models.SecurityEvent) error {
+	adminIDs, err := s.userRepo.FindIDsByRole(models.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	notifications := make([]models.Notification, len(adminIDs))
+	for i, id := range adminIDs {
+		notifications[i] = securityReportNotification(id, event)
+	}
+	if err := s.notificationRepo.CreateBatch(notifications); err != nil {
+		return err
+	}
+	s.eventBus.PublishNotifications(context.Background(), notifications)
+	return nil
+}
+
+func securityReportNotification(userID uint, event *models.SecurityEvent) models.Notification {
+	title := "Vulnerability report: " + event.Title
+	if event.Severity != "" {
+		title = fmt.Sprintf("Vulnerability report (%s): %s", event.Severity, event.Title)
+	}
+	return models.Notification{
+		UserID:     userID,
+		Type:       models.NotificationSecurityReport,
+		EntityType: "security_event",
+		EntityID:   strconv.FormatUint(uint64(event.ID), 10),
+		Title:      title,
+		Body:       "Review it at GET /api/v1/admin/security/events.",
+		Data:       models.JSONB{"security_event_id": event.ID, "severity": event.Severity},
+	}
+}
+
+// verifyCaptcha checks token with a siteverify endpoint (Cloudflare Turnstile, hCaptcha
+// and reCAPTCHA share the request and response format)
+func (s *SecurityReportService) verifyCaptcha(ctx context.Context, token, ipAddress string) error {
+	if strings.TrimSpace(token) == "" {
+		return fmt.Errorf("%w: captcha_token is required", ErrCaptchaFailed)
+	}
+	form := url.Values{"secret": {s.captchaSecret}, "response": {token}}
+	if ipAddress != "" {
+		form.Set("remoteip", ipAddress)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.captchaVerifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("captcha provider unavailable: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("captcha provider returned %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("invalid captcha provider response: %w", err)
+	}
+	if !result.Success {
+		return ErrCaptchaFailed
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestSecurityReportNormalize(t *testing.T) {
+	valid := SecurityReport{
+		Title:         "  Stored XSS in marker labels ",
+		Description:   "Marker labels are rendered unescaped on the map page.",
+		Severity:      "High",
+		ReporterEmail: "jane@example.com",
+	}
+	if err := valid.normalize(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if valid.Title != "Stored XSS in marker labels" || valid.Severity != "high" {
+		t.Errorf("expected trimmed title and lowercase severity, got %q, %q", valid.Title, valid.Severity)
+	}
+
+	invalid := map[string]SecurityReport{
+		"no title":          {Description: valid.Description},
+		"short description": {Title: "XSS", Description: "it's broken"},
+		"unknown severity":  {Title: "XSS", Description: valid.Description, Severity: "urgent"},
+		"bad email":         {Title: "XSS", Description: valid.Description, ReporterEmail: "Jane <jane@example.com>"},
+	}
+	for name, report := range invalid {
+		if err := report.normalize(); !errors.Is(err, ErrInvalidSecurityReport) {
+			t.Errorf("%s: expected ErrInvalidSecurityReport, got %v", name, err)
+		}
+	}
+}
+
+func TestSecurityReportVerifyCaptcha(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.Form.Get("secret") != "s3cret" || r.Form.Get("remoteip") != "203.0.113.7" {
+			t.Errorf("unexpected siteverify request %v", r.Form)
+		}
+		if r.Form.Get("response") == "good" {
+			w.Write([]byte(`{"success": true}`))
+			return
+		}
+		w.Write([]byte(`{"success": false, "error-codes": ["invalid-input-response"]}`))
+	}))
+	defer server.Close()
+
+	s := NewSecurityReportService(nil, nil, nil, nil, "s3cret", server.URL)
+	if !s.CaptchaRequired() {
+		t.Fatal("a configured secret should require a captcha")
+	}
+	if err := s.verifyCaptcha(context.Background(), "good", "203.0.113.7"); err != nil {
+		t.Errorf("expected a valid token to pass, got %v", err)
+	}
+	if err := s.verifyCaptcha(context.Background(), "bad", "203.0.113.7"); !errors.Is(err, ErrCaptchaFailed) {
+		t.Errorf("expected ErrCaptchaFailed, got %v", err)
+	}
+	if err := s.verifyCaptcha(context.Background(), "", "203.0.113.7"); !errors.Is(err, ErrCaptchaFailed) {
+		t.Errorf("expected a missing token to fail, got %v", err)
+	}
+}
+
+func TestSecurityReportNotification(t *testing.T) {
+	event := &models.SecurityEvent{ID: 12, Title: "Open redirect", Severity: "medium"}
+	n := securityReportNotification(3, event)
+	if n.UserID != 3 || n.Type != models.NotificationSecurityReport || n.EntityID != "12" {
+		t.Errorf("unexpected notification %+v", n)
+	}
+	if !strings.Contains(n.Title, "(medium)") || !strings.Contains(n.Title, "Open redirect") {
+		t.Errorf("title = %q", n.Title)
+	}
+}
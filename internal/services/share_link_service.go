@@ -0,0 +1,101 @@
+package services
+
+import (
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/mat/arcapi/internal/config"
+)
+
+var (
+	ErrShareTokenInvalid = errors.New("invalid share token")
+	ErrShareTokenExpired = errors.New("share token expired")
+)
+
+// shareTokenPayloadSize is the encoded payload length: user ID (8 bytes) + expiry unix seconds (8 bytes)
+const shareTokenPayloadSize = 16
+
+// ShareLinkService signs and verifies stateless, expiring tokens that grant
+// read-only access to a user's progress snapshot.
+type ShareLinkService struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+func NewShareLinkService(cfg *config.Config) *ShareLinkService {
+	secret := []byte(cfg.ShareLinkSecret)
+	if len(secret) == 0 {
+		// Without a configured secret, links are only valid until the process restarts
+		secret = make([]byte, 32)
+		if _, err := crand.Read(secret); err != nil {
+			log.Fatalf("Failed to generate share link secret: %v", err)
+		}
+		log.Println("Warning: SHARE_LINK_SECRET not set, shared progress links will not survive restarts")
+	}
+
+	ttl := time.Duration(cfg.ShareLinkTTLHours) * time.Hour
+	if ttl <= 0 {
+		ttl = 7 * 24 * time.Hour
+	}
+
+	return &ShareLinkService{secret: secret, ttl: ttl}
+}
+
+// MaxTTL is the longest lifetime a share token may be issued with
+func (s *ShareLinkService) MaxTTL() time.Duration {
+	return s.ttl
+}
+
+// Sign creates a token for userID that expires after ttl (capped at MaxTTL)
+func (s *ShareLinkService) Sign(userID uint, ttl time.Duration) (string, time.Time) {
+	if ttl <= 0 || ttl > s.ttl {
+		ttl = s.ttl
+	}
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+
+	payload := make([]byte, shareTokenPayloadSize)
+	binary.BigEndian.PutUint64(payload[:8], uint64(userID))
+	binary.BigEndian.PutUint64(payload[8:], uint64(expiresAt.Unix()))
+
+	token := base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(s.mac(payload))
+	return token, expiresAt
+}
+
+// Verify checks the token signature and expiry and returns the user it was issued for
+func (s *ShareLinkService) Verify(token string) (uint, time.Time, error) {
+	encodedPayload, encodedSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, time.Time{}, ErrShareTokenInvalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil || len(payload) != shareTokenPayloadSize {
+		return 0, time.Time{}, ErrShareTokenInvalid
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil || !hmac.Equal(sig, s.mac(payload)) {
+		return 0, time.Time{}, ErrShareTokenInvalid
+	}
+
+	userID := uint(binary.BigEndian.Uint64(payload[:8]))
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(payload[8:])), 0)
+	if time.Now().After(expiresAt) {
+		return 0, time.Time{}, ErrShareTokenExpired
+	}
+
+	return userID, expiresAt, nil
+}
+
+func (s *ShareLinkService) mac(payload []byte) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte("progress-share:"))
+	h.Write(payload)
+	return h.Sum(nil)
+}
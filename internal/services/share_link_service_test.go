@@ -0,0 +1,38 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mat/arcapi/internal/config"
+)
+
+func TestShareLinkServiceSignAndVerify(t *testing.T) {
+	s := NewShareLinkService(&config.Config{ShareLinkSecret: "test-secret", ShareLinkTTLHours: 24})
+
+	token, expiresAt := s.Sign(42, 48*time.Hour)
+	if until := time.Until(expiresAt); until > 24*time.Hour {
+		t.Fatalf("expected ttl to be capped at 24h, got %s", until)
+	}
+
+	userID, _, err := s.Verify(token)
+	if err != nil || userID != 42 {
+		t.Fatalf("Verify() = %d, %v; want 42, nil", userID, err)
+	}
+
+	other := NewShareLinkService(&config.Config{ShareLinkSecret: "other-secret", ShareLinkTTLHours: 24})
+	if _, _, err := other.Verify(token); !errors.Is(err, ErrShareTokenInvalid) {
+		t.Errorf("token signed with another secret: got %v, want ErrShareTokenInvalid", err)
+	}
+	if _, _, err := s.Verify(token[:len(token)-2]); !errors.Is(err, ErrShareTokenInvalid) {
+		t.Errorf("truncated token: got %v, want ErrShareTokenInvalid", err)
+	}
+
+	// Expiry is truncated to whole seconds, so a 1ns lifetime is already in the past
+	short := &ShareLinkService{secret: []byte("test-secret"), ttl: time.Nanosecond}
+	token, _ = short.Sign(42, 0)
+	if _, _, err := short.Verify(token); !errors.Is(err, ErrShareTokenExpired) {
+		t.Errorf("expired token: got %v, want ErrShareTokenExpired", err)
+	}
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/mat/arcapi/internal/repository"
+)
+
+const (
+	JobSoftDeletePurge = "soft_delete_purge"
+
+	// SoftDeletePurgeSchedule runs the soft_delete_purge job once a day
+	SoftDeletePurgeSchedule = "45 3 * * *"
+)
+
+// ErrUnknownSoftDeleteEntity is returned for entities that are not soft-deleted
+var ErrUnknownSoftDeleteEntity = errors.New("unknown entity")
+
+// softDeletable restores and purges one soft-deleted content entity
+type softDeletable struct {
+	restore    func(id uint) error
+	purge      func(cutoff time.Time) (int64, error)
+	invalidate func()
+}
+
+// SoftDeleteService restores content entities deleted through the write API and purges
+// them for good once they have been deleted for the retention period
+type SoftDeleteService struct {
+	entities      map[string]softDeletable
+	retentionDays int
+}
+
+func NewSoftDeleteService(
+	questRepo *repository.QuestRepository,
+	itemRepo *repository.ItemRepository,
+	skillNodeRepo *repository.SkillNodeRepository,
+	hideoutModuleRepo *repository.HideoutModuleRepository,
+	enemyTypeRepo *repository.EnemyTypeRepository,
+	dataCacheService *DataCacheService,
+	retentionDays int,
+) *SoftDeleteService {
+	invalidateQuests, invalidateItems := func() {}, func() {}
+	if dataCacheService != nil {
+		invalidateQuests = func() { dataCacheService.InvalidateQuestsCache() }
+		invalidateItems = func() { dataCacheService.InvalidateItemsCache() }
+	}
+	return &SoftDeleteService{
+		entities: map[string]softDeletable{
+			"quests":          {questRepo.Restore, questRepo.PurgeDeleted, invalidateQuests},
+			"items":           {itemRepo.Restore, itemRepo.PurgeDeleted, invalidateItems},
+			"skill-nodes":     {skillNodeRepo.Restore, skillNodeRepo.PurgeDeleted, func() {}},
+			"hideout-modules": {hideoutModuleRepo.Restore, hideoutModuleRepo.PurgeDeleted, func() {}},
+			"enemy-types":     {enemyTypeRepo.Restore, enemyTypeRepo.PurgeDeleted, func() {}},
+		},
+		retentionDays: retentionDays,
+	}
+}
+
+// Entities lists the entities that are soft-deleted, sorted
+func (s *SoftDeleteService) Entities() []string {
+	entities := make([]string, 0, len(s.entities))
+	for entity := range s.entities {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+	return entities
+}
+
+// Restore undoes the soft delete of the entity row with id. It returns
+// gorm.ErrRecordNotFound when there is no deleted row with that id.
+func (s *SoftDeleteService) Restore(entity string, id uint) error {
+	e, ok := s.entities[entity]
+	if !ok {
+		return ErrUnknownSoftDeleteEntity
+	}
+	if err := e.restore(id); err != nil {
+		return err
+	}
+	e.invalidate()
+	return nil
+}
+
+// Purge is the soft_delete_purge job: it permanently deletes rows soft-deleted more than
+// the retention period ago, along with the progress rows referencing them
+func (s *SoftDeleteService) Purge(startedAt time.Time) (int64, error) {
+	if s.retentionDays <= 0 {
+		return 0, nil
+	}
+	cutoff := startedAt.AddDate(0, 0, -s.retentionDays)
+	var purged int64
+	for _, entity := range s.Entities() {
+		n, err := s.entities[entity].purge(cutoff)
+		if err != nil {
+			return purged, err
+		}
+		purged += n
+	}
+	return purged, nil
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSoftDeleteServicePurge(t *testing.T) {
+	startedAt := time.Date(2026, 10, 15, 3, 45, 0, 0, time.UTC)
+	var cutoffs []time.Time
+	purge := func(n int64) func(time.Time) (int64, error) {
+		return func(cutoff time.Time) (int64, error) {
+			cutoffs = append(cutoffs, cutoff)
+			return n, nil
+		}
+	}
+	s := &SoftDeleteService{
+		entities: map[string]softDeletable{
+			"quests": {purge: purge(2)},
+			"items":  {purge: purge(3)},
+		},
+		retentionDays: 30,
+	}
+
+	purged, err := s.Purge(startedAt)
+	if err != nil || purged != 5 {
+		t.Fatalf("expected 5 rows purged, got %d (%v)", purged, err)
+	}
+	want := startedAt.AddDate(0, 0, -30)
+	if len(cutoffs) != 2 || !cutoffs[0].Equal(want) || !cutoffs[1].Equal(want) {
+		t.Errorf("expected every entity purged before %v, got %v", want, cutoffs)
+	}
+
+	s.retentionDays = 0
+	cutoffs = nil
+	if purged, err := s.Purge(startedAt); purged != 0 || err != nil || len(cutoffs) != 0 {
+		t.Errorf("expected a retention of 0 to keep deleted rows, purged %d (%v)", purged, err)
+	}
+}
+
+func TestSoftDeleteServiceRestore(t *testing.T) {
+	var restored uint
+	invalidated := false
+	s := &SoftDeleteService{entities: map[string]softDeletable{
+		"items": {
+			restore:    func(id uint) error { restored = id; return nil },
+			invalidate: func() { invalidated = true },
+		},
+	}}
+
+	if err := s.Restore("items", 7); err != nil || restored != 7 || !invalidated {
+		t.Errorf("expected item 7 restored and the cache invalidated, got %d, %v (%v)", restored, invalidated, err)
+	}
+	if err := s.Restore("alerts", 7); !errors.Is(err, ErrUnknownSoftDeleteEntity) {
+		t.Errorf("expected ErrUnknownSoftDeleteEntity, got %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mat/arcapi/internal/config"
+	"github.com/mat/arcapi/internal/repository"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	JobLeaderboard = "leaderboard"
+	JobQuestStats  = "quest_stats"
+)
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobRunning  = errors.New("job is already running")
+)
+
+// JobStatus describes the last run of a background aggregation job
+type JobStatus struct {
+	Name           string     `json:"name"`
+	Schedule       string     `json:"schedule"`
+	Running        bool       `json:"running"`
+	LastStartedAt  *time.Time `json:"last_started_at,omitempty"`
+	LastFinishedAt *time.Time `json:"last_finished_at,omitempty"`
+	LastDurationMs int64      `json:"last_duration_ms"`
+	LastRows       int64      `json:"last_rows"`
+	LastError      string     `json:"last_error,omitempty"`
+	RunCount       int64      `json:"run_count"`
+}
+
+type statsJob struct {
+	status JobStatus
+	run    func(refreshedAt time.Time) (int64, error)
+	// ownSchedule jobs were added with AddJob and run on their own cron entry rather than
+	// with the stats refresh
+	ownSchedule bool
+}
+
+// StatsService refreshes the materialized leaderboard and stats tables on a schedule
+// so read endpoints never aggregate over the progress tables live. Other maintenance
+// jobs can be added with AddJob to share its status reporting and manual runs.
+type StatsService struct {
+	statsRepo *repository.StatsRepository
+	cfg       *config.Config
+	cron      *cron.Cron
+	mu        sync.Mutex
+	jobs      map[string]*statsJob
+}
+
+func NewStatsService(statsRepo *repository.StatsRepository, leaderboardService *LeaderboardService, cfg *config.Config) *StatsService {
+	s := &StatsService{
+		statsRepo: statsRepo,
+		cfg:       cfg,
+		cron:      cron.New(),
+		jobs:      make(map[string]*statsJob),
+	}
+	s.jobs[JobLeaderboard] = &statsJob{
+		status: JobStatus{Name: JobLeaderboard, Schedule: cfg.StatsCron},
+		run:    leaderboardService.Refresh,
+	}
+	s.jobs[JobQuestStats] = &statsJob{
+		status: JobStatus{Name: JobQuestStats, Schedule: cfg.StatsCron},
+		run:    statsRepo.RefreshQuestStats,
+	}
+	return s
+}
+
+func (s *StatsService) Start() error {
+	_, err := s.cron.AddFunc(s.cfg.StatsCron, func() {
+		s.RunAll()
+	})
+	if err != nil {
+		return fmt.Errorf("invalid stats cron expression: %w", err)
+	}
+
+	s.cron.Start()
+	log.Printf("Stats service started with schedule: %s", s.cfg.StatsCron)
+
+	// Populate the tables right away so a fresh deploy doesn't serve an empty leaderboard
+	go s.RunAll()
+
+	return nil
+}
+
+// AddJob registers a job that runs on its own cron schedule. It is not run at startup
+// or by RunAll, but can be triggered like the stats jobs through RunJob.
+func (s *StatsService) AddJob(name, schedule string, run func(startedAt time.Time) (int64, error)) error {
+	_, err := s.cron.AddFunc(schedule, func() {
+		if err := s.RunJob(name); err != nil && !errors.Is(err, ErrJobRunning) {
+			log.Printf("Job %s failed: %v", name, err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("invalid cron expression for job %s: %w", name, err)
+	}
+
+	s.mu.Lock()
+	s.jobs[name] = &statsJob{
+		status:      JobStatus{Name: name, Schedule: schedule},
+		run:         run,
+		ownSchedule: true,
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *StatsService) Stop() {
+	s.cron.Stop()
+}
+
+// RunAll runs every stats job in turn, skipping any that are already running
+func (s *StatsService) RunAll() {
+	for _, name := range s.statsJobNames() {
+		if err := s.RunJob(name); err != nil && !errors.Is(err, ErrJobRunning) {
+			log.Printf("Stats job %s failed: %v", name, err)
+		}
+	}
+}
+
+// RunJob runs a single job synchronously and records its status
+func (s *StatsService) RunJob(name string) error {
+	s.mu.Lock()
+	job, ok := s.jobs[name]
+	if !ok {
+		s.mu.Unlock()
+		return ErrJobNotFound
+	}
+	if job.status.Running {
+		s.mu.Unlock()
+		return ErrJobRunning
+	}
+	started := time.Now()
+	job.status.Running = true
+	job.status.LastStartedAt = &started
+	s.mu.Unlock()
+
+	rows, err := job.run(started)
+
+	finished := time.Now()
+	s.mu.Lock()
+	job.status.Running = false
+	job.status.LastFinishedAt = &finished
+	job.status.LastDurationMs = finished.Sub(started).Milliseconds()
+	job.status.RunCount++
+	if err != nil {
+		job.status.LastError = err.Error()
+	} else {
+		job.status.LastError = ""
+		job.status.LastRows = rows
+	}
+	s.mu.Unlock()
+
+	return err
+}
+
+// IsRunning reports whether any job is currently running
+func (s *StatsService) IsRunning() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, job := range s.jobs {
+		if job.status.Running {
+			return true
+		}
+	}
+	return false
+}
+
+// HasJob reports whether a job with the given name is registered
+func (s *StatsService) HasJob(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.jobs[name]
+	return ok
+}
+
+// Jobs returns a snapshot of every job's status, sorted by name
+func (s *StatsService) Jobs() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		statuses = append(statuses, job.status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+func (s *StatsService) statsJobNames() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.jobs))
+	for name, job := range s.jobs {
+		if !job.ownSchedule {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+func TestStatsServiceRunJobRecordsStatus(t *testing.T) {
+	s := &StatsService{cron: cron.New(), jobs: map[string]*statsJob{
+		"ok": {status: JobStatus{Name: "ok"}, run: func(time.Time) (int64, error) { return 42, nil }},
+		"bad": {status: JobStatus{Name: "bad"}, run: func(time.Time) (int64, error) {
+			return 0, errors.New("boom")
+		}},
+	}}
+
+	if err := s.RunJob("ok"); err != nil {
+		t.Fatalf("RunJob(ok) returned %v", err)
+	}
+	if err := s.RunJob("bad"); err == nil {
+		t.Fatal("RunJob(bad) should return the job error")
+	}
+	if err := s.RunJob("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("RunJob(missing) = %v, want ErrJobNotFound", err)
+	}
+
+	jobs := s.Jobs()
+	if len(jobs) != 2 || jobs[0].Name != "bad" || jobs[1].Name != "ok" {
+		t.Fatalf("unexpected job list %+v", jobs)
+	}
+	if jobs[0].LastError != "boom" || jobs[0].RunCount != 1 || jobs[0].Running {
+		t.Errorf("unexpected failed job status %+v", jobs[0])
+	}
+	if jobs[1].LastRows != 42 || jobs[1].LastError != "" || jobs[1].LastFinishedAt == nil {
+		t.Errorf("unexpected successful job status %+v", jobs[1])
+	}
+}
@@ -23,6 +23,7 @@ import (
 type SupabaseClaims struct {
 	Email        string                 `json:"email"`
 	Sub          string                 `json:"sub"`
+	SessionID    string                 `json:"session_id"`
 	AppMetadata  map[string]interface{} `json:"app_metadata"`
 	UserMetadata map[string]interface{} `json:"user_metadata"`
 	jwt.RegisteredClaims
@@ -219,6 +220,15 @@ func (s *SupabaseAuthService) keyForToken(token *jwt.Token) (interface{}, error)
 	return key, nil
 }
 
+// TokenID returns the identifier used to track and revoke this token:
+// the jti claim when present, otherwise the Supabase session_id
+func (c *SupabaseClaims) TokenID() string {
+	if c.ID != "" {
+		return c.ID
+	}
+	return c.SessionID
+}
+
 // ValidateToken validates an incoming Supabase JWT token and returns the claims
 func (s *SupabaseAuthService) ValidateToken(tokenString string) (*SupabaseClaims, error) {
 	claims := &SupabaseClaims{}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+	"gorm.io/gorm"
+)
+
+// maxSyncRunErrors caps the errors kept per run, so a run in which every row fails to save
+// stays small
+const maxSyncRunErrors = 100
+
+// beginRun starts the history record of a sync; entity is the only entity type synced, or
+// empty for a full sync
+func (s *SyncService) beginRun(trigger, entity string, startedAt time.Time) {
+	run := &models.SyncRun{
+		Trigger:   trigger,
+		Entity:    entity,
+		Status:    models.SyncRunRunning,
+		StartedAt: startedAt,
+		Entities:  models.SyncRunEntities{},
+		Errors:    models.StringList{},
+	}
+	s.runMu.Lock()
+	s.run = run
+	s.runMu.Unlock()
+
+	if s.syncRunRepo == nil {
+		return
+	}
+	if err := s.syncRunRepo.Create(run); err != nil {
+		syncLog.Warn("Failed to record sync run", "error", err)
+	}
+}
+
+// finishRun records how the current sync ended and prunes runs past the retention period
+func (s *SyncService) finishRun(status, dataVersion string, err error) {
+	s.runMu.Lock()
+	run := s.run
+	s.run = nil
+	s.runMu.Unlock()
+	if run == nil {
+		return
+	}
+
+	finishedAt := time.Now().UTC()
+	run.Status = status
+	run.DataVersion = dataVersion
+	run.FinishedAt = &finishedAt
+	run.DurationMS = finishedAt.Sub(run.StartedAt).Milliseconds()
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	if s.syncRunRepo == nil {
+		return
+	}
+	// Save creates the run if beginRun failed to
+	if err := s.syncRunRepo.Update(run); err != nil {
+		syncLog.Warn("Failed to record sync run", "error", err)
+		return
+	}
+	if s.cfg.SyncRunRetentionDays > 0 {
+		if _, err := s.syncRunRepo.DeleteBefore(finishedAt.AddDate(0, 0, -s.cfg.SyncRunRetentionDays)); err != nil {
+			syncLog.Warn("Failed to prune sync runs", "error", err)
+		}
+	}
+}
+
+// recordRun applies update to the current run, if any
+func (s *SyncService) recordRun(update func(run *models.SyncRun)) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	if s.run != nil {
+		update(s.run)
+	}
+}
+
+// addRunError adds a non-fatal error to the current run
+func (s *SyncService) addRunError(message string) {
+	s.recordRun(func(run *models.SyncRun) {
+		if len(run.Errors) < maxSyncRunErrors {
+			run.Errors = append(run.Errors, message)
+		}
+	})
+}
+
+// entitiesSynced logs and records how many rows of an entity type the sync read
+func (s *SyncService) entitiesSynced(entity string, count int) {
+	syncLog.Info("Synced entities", "entity", entity, "count", count)
+	s.recordRun(func(run *models.SyncRun) {
+		counts := run.Entities[entity]
+		counts.Total = count
+		run.Entities[entity] = counts
+	})
+}
+
+// upsertFailed logs and records a row that failed to save
+func (s *SyncService) upsertFailed(entity, externalID string, err error) {
+	syncLog.Error("Failed to upsert entity", "entity", entity, "external_id", externalID, "error", err)
+	s.recordRun(func(run *models.SyncRun) {
+		counts := run.Entities[entity]
+		counts.Failed++
+		run.Entities[entity] = counts
+	})
+	s.addRunError(fmt.Sprintf("%s %s: %v", entity, externalID, err))
+}
+
+// overrideKept logs and records a row left as it is because it was edited through the API
+func (s *SyncService) overrideKept(entity, externalID string) {
+	syncLog.Debug("Kept manually edited entity", "entity", entity, "external_id", externalID)
+	s.recordRun(func(run *models.SyncRun) {
+		counts := run.Entities[entity]
+		counts.Kept++
+		run.Entities[entity] = counts
+	})
+}
+
+// entityUnreadable logs and records an entity type missing from or unreadable in the archive
+func (s *SyncService) entityUnreadable(entity string, err error) {
+	syncLog.Warn("Could not read entity data from zip", "entity", entity, "error", err)
+	s.addRunError(fmt.Sprintf("%s: could not read entity data: %v", entity, err))
+}
+
+// entityFailed logs and records an entity type whose sync stopped with an error
+func (s *SyncService) entityFailed(entity string, err error) {
+	syncLog.Error("Failed to sync entity", "entity", entity, "error", err)
+	s.addRunError(fmt.Sprintf("%s: %v", entity, err))
+}
+
+// Runs lists recorded sync runs, newest first, optionally with one status
+func (s *SyncService) Runs(status string, offset, limit int) ([]models.SyncRun, int64, error) {
+	if s.syncRunRepo == nil {
+		return []models.SyncRun{}, 0, nil
+	}
+	return s.syncRunRepo.List(status, offset, limit)
+}
+
+// FindRun returns one recorded sync run
+func (s *SyncService) FindRun(id uint) (*models.SyncRun, error) {
+	if s.syncRunRepo == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return s.syncRunRepo.FindByID(id)
+}
@@ -5,9 +5,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
 	"strings"
@@ -16,26 +16,91 @@ import (
 
 	"github.com/google/go-github/v57/github"
 	"github.com/mat/arcapi/internal/config"
+	"github.com/mat/arcapi/internal/logging"
 	"github.com/mat/arcapi/internal/models"
 	"github.com/mat/arcapi/internal/repository"
 	"github.com/robfig/cron/v3"
 )
 
+const (
+	// metadataDataVersionKey stores the source commit SHA of the last successful sync
+	metadataDataVersionKey = "data_version"
+	defaultDataVersion     = "1.0"
+)
+
+var syncLog = logging.For("sync")
+
+var (
+	// ErrSyncRunning is returned when a sync can't start because another one is running
+	ErrSyncRunning = errors.New("sync already running")
+	// ErrUnknownSyncEntity is returned for a partial sync of an entity type sync doesn't know
+	ErrUnknownSyncEntity = errors.New("unknown sync entity")
+)
+
 type SyncService struct {
-	questRepo         *repository.QuestRepository
-	itemRepo          *repository.ItemRepository
-	skillNodeRepo     *repository.SkillNodeRepository
-	hideoutModuleRepo *repository.HideoutModuleRepository
-	botRepo           *repository.BotRepository
-	mapRepo           *repository.MapRepository
-	traderRepo        *repository.TraderRepository
-	projectRepo       *repository.ProjectRepository
-	dataCacheService  *DataCacheService
-	githubClient      *github.Client
-	cfg               *config.Config
-	cron              *cron.Cron
-	mu                sync.Mutex
-	isRunning         bool
+	questRepo           *repository.QuestRepository
+	itemRepo            *repository.ItemRepository
+	skillNodeRepo       *repository.SkillNodeRepository
+	hideoutModuleRepo   *repository.HideoutModuleRepository
+	botRepo             *repository.BotRepository
+	mapRepo             *repository.MapRepository
+	traderRepo          *repository.TraderRepository
+	projectRepo         *repository.ProjectRepository
+	recipeRepo          *repository.RecipeRepository
+	itemStatRepo        *repository.ItemStatRepository
+	translationRepo     *repository.TranslationRepository
+	metadataRepo        *repository.MetadataRepository
+	imageCheckRepo      *repository.ImageCheckRepository
+	notificationService *NotificationService
+	hooks               *HookRegistry
+	dataCacheService    *DataCacheService
+	source              DataSource
+	cfg                 *config.Config
+	cron                *cron.Cron
+	mu                  sync.Mutex
+	isRunning           bool
+	// ctx is cancelled by Stop to abort the running sync; guarded by mu
+	ctx    context.Context
+	cancel context.CancelFunc
+	// running tracks syncs in progress so Wait can wait for them to unwind
+	running sync.WaitGroup
+	// changes collects entities whose data changed during the current sync
+	changes []EntityChange
+	// health records the outcome of this instance's syncs, guarded by mu
+	health SyncHealth
+	// run is the history record of the current sync, guarded by runMu
+	syncRunRepo *repository.SyncRunRepository
+	runMu       sync.Mutex
+	run         *models.SyncRun
+	// githubProbe checks GitHub for health checks; it bypasses the rate limit guard, which
+	// would otherwise hold the probe until the limit resets
+	githubProbe  *github.Client
+	githubMu     sync.Mutex
+	githubStatus *GitHubStatus
+}
+
+// githubProbeInterval is how long a GitHub reachability result is reused
+const githubProbeInterval = time.Minute
+
+// GitHubStatus is the outcome of the latest check that GitHub, where data is synced from,
+// is reachable
+type GitHubStatus struct {
+	Reachable          bool      `json:"reachable" example:"true"`
+	LatencyMS          int64     `json:"latency_ms" example:"84"`
+	RateLimitRemaining int       `json:"rate_limit_remaining" example:"58"`
+	Error              string    `json:"error,omitempty"`
+	CheckedAt          time.Time `json:"checked_at"`
+}
+
+// SyncHealth is the outcome of the syncs run by this instance since it started
+type SyncHealth struct {
+	IsRunning     bool       `json:"is_running" example:"false"`
+	DataVersion   string     `json:"data_version" example:"3f2a9c1"`
+	LastStartedAt *time.Time `json:"last_started_at,omitempty"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastErrorAt   *time.Time `json:"last_error_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty" example:"failed to download archive"`
+	Healthy       bool       `json:"healthy" example:"true"` // False when the last finished sync failed
 }
 
 func NewSyncService(
@@ -47,9 +112,17 @@ func NewSyncService(
 	mapRepo *repository.MapRepository,
 	traderRepo *repository.TraderRepository,
 	projectRepo *repository.ProjectRepository,
+	recipeRepo *repository.RecipeRepository,
+	itemStatRepo *repository.ItemStatRepository,
+	translationRepo *repository.TranslationRepository,
+	metadataRepo *repository.MetadataRepository,
+	imageCheckRepo *repository.ImageCheckRepository,
+	syncRunRepo *repository.SyncRunRepository,
+	notificationService *NotificationService,
+	hooks *HookRegistry,
 	cfg *config.Config,
 ) *SyncService {
-	return NewSyncServiceWithCache(questRepo, itemRepo, skillNodeRepo, hideoutModuleRepo, botRepo, mapRepo, traderRepo, projectRepo, nil, cfg)
+	return NewSyncServiceWithCache(questRepo, itemRepo, skillNodeRepo, hideoutModuleRepo, botRepo, mapRepo, traderRepo, projectRepo, recipeRepo, itemStatRepo, translationRepo, metadataRepo, imageCheckRepo, syncRunRepo, notificationService, hooks, nil, cfg)
 }
 
 func NewSyncServiceWithCache(
@@ -61,6 +134,14 @@ func NewSyncServiceWithCache(
 	mapRepo *repository.MapRepository,
 	traderRepo *repository.TraderRepository,
 	projectRepo *repository.ProjectRepository,
+	recipeRepo *repository.RecipeRepository,
+	itemStatRepo *repository.ItemStatRepository,
+	translationRepo *repository.TranslationRepository,
+	metadataRepo *repository.MetadataRepository,
+	imageCheckRepo *repository.ImageCheckRepository,
+	syncRunRepo *repository.SyncRunRepository,
+	notificationService *NotificationService,
+	hooks *HookRegistry,
 	dataCacheService *DataCacheService,
 	cfg *config.Config,
 ) *SyncService {
@@ -68,19 +149,29 @@ func NewSyncServiceWithCache(
 	client := github.NewClient(newRateLimitAwareHTTPClient())
 
 	service := &SyncService{
-		questRepo:         questRepo,
-		itemRepo:          itemRepo,
-		skillNodeRepo:     skillNodeRepo,
-		hideoutModuleRepo: hideoutModuleRepo,
-		botRepo:           botRepo,
-		mapRepo:           mapRepo,
-		traderRepo:        traderRepo,
-		projectRepo:       projectRepo,
-		dataCacheService:  dataCacheService,
-		githubClient:      client,
-		cfg:               cfg,
-		cron:              cron.New(),
+		questRepo:           questRepo,
+		itemRepo:            itemRepo,
+		skillNodeRepo:       skillNodeRepo,
+		hideoutModuleRepo:   hideoutModuleRepo,
+		botRepo:             botRepo,
+		mapRepo:             mapRepo,
+		traderRepo:          traderRepo,
+		projectRepo:         projectRepo,
+		recipeRepo:          recipeRepo,
+		itemStatRepo:        itemStatRepo,
+		translationRepo:     translationRepo,
+		metadataRepo:        metadataRepo,
+		imageCheckRepo:      imageCheckRepo,
+		syncRunRepo:         syncRunRepo,
+		notificationService: notificationService,
+		hooks:               hooks,
+		dataCacheService:    dataCacheService,
+		source:              newDataSource(cfg, client),
+		githubProbe:         github.NewClient(&http.Client{Timeout: 5 * time.Second}),
+		cfg:                 cfg,
+		cron:                cron.New(),
 	}
+	service.ctx, service.cancel = context.WithCancel(context.Background())
 
 	return service
 }
@@ -101,35 +192,147 @@ func NewSyncServiceWithMissionRepo(
 	return nil
 }
 
-func (s *SyncService) Start() error {
+// Start schedules syncs and runs the first one. Syncs run under ctx, typically the server's
+// shutdown context, and are cancelled when it is done or Stop is called.
+func (s *SyncService) Start(ctx context.Context) error {
+	s.mu.Lock()
+	s.cancel()
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.mu.Unlock()
+
 	_, err := s.cron.AddFunc(s.cfg.SyncCron, func() {
-		go s.Sync()
+		go s.Sync(s.context(), models.SyncTriggerScheduled)
 	})
 	if err != nil {
 		return fmt.Errorf("invalid cron expression: %w", err)
 	}
 
 	s.cron.Start()
-	log.Printf("Sync service started with schedule: %s", s.cfg.SyncCron)
+	syncLog.Info("Sync service started", "schedule", s.cfg.SyncCron)
 
 	// Run initial sync
-	go s.Sync()
+	go s.Sync(s.context(), models.SyncTriggerStartup)
 
 	return nil
 }
 
-func (s *SyncService) Stop() {
+// StopScheduling stops starting scheduled syncs and lets a running one finish, for
+// draining an instance before it's replaced
+func (s *SyncService) StopScheduling() {
 	s.cron.Stop()
 }
 
+// Stop stops scheduling syncs and cancels the running one, aborting its GitHub requests
+// and the remaining database writes, for shutdown. It doesn't wait; use Wait for that.
+func (s *SyncService) Stop() {
+	s.StopScheduling()
+	s.mu.Lock()
+	s.cancel()
+	s.mu.Unlock()
+}
+
+// Wait blocks until running syncs have returned or ctx is done
+func (s *SyncService) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// context returns the context syncs run under
+func (s *SyncService) context() context.Context {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ctx
+}
+
 // ForceSync triggers a sync immediately, even if one is already running
 func (s *SyncService) ForceSync() error {
 	s.mu.Lock()
 	s.isRunning = false // Allow force sync even if one is running
 	s.mu.Unlock()
 
-	log.Println("Force sync triggered...")
-	go s.Sync()
+	syncLog.Info("Force sync triggered")
+	go s.Sync(s.context(), models.SyncTriggerManual)
+	return nil
+}
+
+// Health reports whether syncs on this instance are succeeding
+func (s *SyncService) Health() SyncHealth {
+	s.mu.Lock()
+	health := s.health
+	health.IsRunning = s.isRunning
+	s.mu.Unlock()
+
+	health.DataVersion = s.DataVersion()
+	health.Healthy = health.LastErrorAt == nil || (health.LastSuccessAt != nil && health.LastSuccessAt.After(*health.LastErrorAt))
+	return health
+}
+
+// UsesGitHub reports whether data is synced from GitHub, rather than a source configured
+// with SYNC_SOURCE
+func (s *SyncService) UsesGitHub() bool {
+	_, ok := s.source.(*githubDataSource)
+	return ok
+}
+
+// CheckGitHub reports whether the GitHub API is reachable, asking at most once per
+// githubProbeInterval. It reads the rate limit, which doesn't count against it.
+func (s *SyncService) CheckGitHub(ctx context.Context) GitHubStatus {
+	s.githubMu.Lock()
+	defer s.githubMu.Unlock()
+	if s.githubStatus != nil && time.Since(s.githubStatus.CheckedAt) < githubProbeInterval {
+		return *s.githubStatus
+	}
+
+	started := time.Now()
+	limits, _, err := s.githubProbe.RateLimit.Get(ctx)
+	status := GitHubStatus{
+		Reachable: err == nil,
+		LatencyMS: time.Since(started).Milliseconds(),
+		CheckedAt: time.Now().UTC(),
+	}
+	if err != nil {
+		status.Error = err.Error()
+	} else if limits.GetCore() != nil {
+		status.RateLimitRemaining = limits.GetCore().Remaining
+	}
+	s.githubStatus = &status
+	return status
+}
+
+// recordSyncResult stores the outcome of a sync for Health
+func (s *SyncService) recordSyncResult(err error) {
+	now := time.Now().UTC()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.health.LastErrorAt = &now
+		s.health.LastError = err.Error()
+		return
+	}
+	s.health.LastSuccessAt = &now
+}
+
+// SyncEntity triggers a sync of a single entity type, such as quests or skill_nodes, to
+// pick up an upstream fix without syncing everything else
+func (s *SyncService) SyncEntity(entity string) error {
+	if !s.hasSyncStep(entity) {
+		return ErrUnknownSyncEntity
+	}
+	if s.IsRunning() {
+		return ErrSyncRunning
+	}
+
+	syncLog.Info("Partial sync triggered", "entity", entity)
+	go s.sync(s.context(), models.SyncTriggerManual, entity)
 	return nil
 }
 
@@ -140,124 +343,166 @@ func (s *SyncService) IsRunning() bool {
 	return s.isRunning
 }
 
-func (s *SyncService) Sync() {
+// Sync downloads the data repository and upserts every entity in it, recording the run in
+// the sync history under trigger. It returns early, without recording a failure, once ctx
+// is cancelled.
+func (s *SyncService) Sync(ctx context.Context, trigger string) {
+	s.sync(ctx, trigger, "")
+}
+
+// sync runs a sync of every entity type, or only of entity if it isn't empty
+func (s *SyncService) sync(ctx context.Context, trigger, entity string) {
 	s.mu.Lock()
 	if s.isRunning {
 		s.mu.Unlock()
-		log.Println("Sync already running, skipping...")
+		syncLog.Info("Sync already running, skipping")
+		return
+	}
+	if ctx.Err() != nil {
+		s.mu.Unlock()
 		return
 	}
 	s.isRunning = true
+	s.running.Add(1)
+	startedAt := time.Now().UTC()
+	s.health.LastStartedAt = &startedAt
 	s.mu.Unlock()
+	s.beginRun(trigger, entity, startedAt)
 
 	defer func() {
 		s.mu.Lock()
 		s.isRunning = false
 		s.mu.Unlock()
+		s.running.Done()
 	}()
 
-	log.Println("Starting data sync from GitHub ZIP archive...")
-
-	ctx := context.Background()
-	owner := "MatD1"
-	repo := "arcraiders-data-fork"
-	branch := "main"
+	syncLog.Info("Starting data sync", "source", s.cfg.SyncSource, "entity", entity)
+	s.changes = []EntityChange{}
 
-	// 1. Get latest SHA to help with identification
-	sha, err := s.getLatestSHA(ctx, owner, repo, branch)
-	if err != nil {
-		log.Printf("Warning: Could not get latest SHA: %v. Proceeding with sync anyway.", err)
-	} else {
-		log.Printf("Latest repository SHA: %s", sha)
+	// 1. Download the archive
+	zipData, sha, err := s.source.Fetch(ctx)
+	if ctx.Err() != nil {
+		syncLog.Info("Sync cancelled", "stage", "download")
+		s.finishRun(models.SyncRunCancelled, sha, nil)
+		return
 	}
-
-	// 2. Download zipball
-	zipData, err := s.downloadArchive(ctx, owner, repo, branch)
 	if err != nil {
-		log.Printf("Error downloading archive: %v", err)
+		syncLog.Error("Failed to download archive", "error", err)
+		err = fmt.Errorf("failed to download archive: %w", err)
+		s.recordSyncResult(err)
+		s.finishRun(models.SyncRunFailed, sha, err)
 		return
 	}
-	log.Printf("Downloaded archive (%d bytes)", len(zipData))
-
-	// 3. Process archive
-	if err := s.processArchive(ctx, zipData); err != nil {
-		log.Printf("Error processing archive: %v", err)
+	syncLog.Info("Downloaded archive", "bytes", len(zipData), "version", sha)
+
+	// 2. Process archive
+	if err := s.processArchive(ctx, zipData, entity); err != nil {
+		if ctx.Err() != nil {
+			syncLog.Info("Sync cancelled", "stage", "process")
+			s.finishRun(models.SyncRunCancelled, sha, nil)
+			return
+		}
+		syncLog.Error("Failed to process archive", "error", err)
+		err = fmt.Errorf("failed to process archive: %w", err)
+		s.recordSyncResult(err)
+		s.finishRun(models.SyncRunFailed, sha, err)
 		return
 	}
 
-	log.Println("Data sync completed successfully.")
+	syncLog.Info("Data sync completed")
+	s.recordSyncResult(nil)
+	s.finishRun(models.SyncRunSucceeded, sha, nil)
+	s.notifyChanges()
+
+	// After a partial sync the other entity types are still at the previous version
+	if sha != "" && entity == "" && s.metadataRepo != nil {
+		if err := s.metadataRepo.Set(metadataDataVersionKey, sha); err != nil {
+			syncLog.Warn("Failed to record data version", "error", err)
+		}
+	}
 
 	// Update cache if available
 	if s.dataCacheService != nil {
-		log.Println("Triggering cache refresh...")
+		syncLog.Info("Triggering cache refresh")
 		s.dataCacheService.RefreshNow()
 	}
-}
 
-func (s *SyncService) getLatestSHA(ctx context.Context, owner, repo, branch string) (string, error) {
-	ref, _, err := s.githubClient.Git.GetRef(ctx, owner, repo, "heads/"+branch)
-	if err != nil {
-		return "", err
-	}
-	return ref.Object.GetSHA(), nil
+	s.hooks.RunPostSync(ctx, SyncEvent{
+		DataVersion: sha,
+		Changes:     s.changes,
+		CompletedAt: time.Now(),
+	})
 }
 
-func (s *SyncService) downloadArchive(ctx context.Context, owner, repo, ref string) ([]byte, error) {
-	url, _, err := s.githubClient.Repositories.GetArchiveLink(ctx, owner, repo, github.Zipball, &github.RepositoryContentGetOptions{
-		Ref: ref,
-	}, 10)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get archive link: %w", err)
-	}
+// syncStep syncs one entity type from the archive
+type syncStep struct {
+	entity string
+	sync   func(context.Context, *zip.Reader) error
+	// invalidate drops the entity type's cached lookups once it's synced
+	invalidate func()
+}
 
-	resp, err := http.Get(url.String())
-	if err != nil {
-		return nil, fmt.Errorf("failed to download archive: %w", err)
+// syncSteps lists the entity types in the order a full sync goes through them
+func (s *SyncService) syncSteps() []syncStep {
+	return []syncStep{
+		{"quests", s.syncQuestsFromZip, s.questRepo.InvalidateCache},
+		{"items", s.syncItemsFromZip, s.itemRepo.InvalidateCache},
+		{"skill_nodes", s.syncSkillNodesFromZip, s.skillNodeRepo.InvalidateCache},
+		{"hideout_modules", s.syncHideoutModulesFromZip, s.hideoutModuleRepo.InvalidateCache},
+		{"bots", s.syncBotsFromZip, s.botRepo.InvalidateCache},
+		{"maps", s.syncMapsFromZip, s.mapRepo.InvalidateCache},
+		{"traders", s.syncTradersFromZip, s.traderRepo.InvalidateCache},
+		{"projects", s.syncProjectsFromZip, s.projectRepo.InvalidateCache},
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+func (s *SyncService) hasSyncStep(entity string) bool {
+	for _, step := range s.syncSteps() {
+		if step.entity == entity {
+			return true
+		}
 	}
-
-	return io.ReadAll(resp.Body)
+	return false
 }
 
-func (s *SyncService) processArchive(ctx context.Context, zipData []byte) error {
+// processArchive syncs every entity type from the archive, or only entity if it isn't empty
+func (s *SyncService) processArchive(ctx context.Context, zipData []byte, entity string) error {
 	r, err := zip.NewReader(bytes.NewReader(zipData), int64(len(zipData)))
 	if err != nil {
 		return fmt.Errorf("failed to create zip reader: %w", err)
 	}
 
-	if err := s.syncQuestsFromZip(ctx, r); err != nil {
-		log.Printf("Error syncing quests from zip: %v", err)
-	}
-	if err := s.syncItemsFromZip(ctx, r); err != nil {
-		log.Printf("Error syncing items from zip: %v", err)
-	}
-	if err := s.syncSkillNodesFromZip(ctx, r); err != nil {
-		log.Printf("Error syncing skill nodes from zip: %v", err)
-	}
-	if err := s.syncHideoutModulesFromZip(ctx, r); err != nil {
-		log.Printf("Error syncing hideout modules from zip: %v", err)
-	}
-	if err := s.syncBotsFromZip(ctx, r); err != nil {
-		log.Printf("Error syncing bots from zip: %v", err)
-	}
-	if err := s.syncMapsFromZip(ctx, r); err != nil {
-		log.Printf("Error syncing maps from zip: %v", err)
-	}
-	if err := s.syncTradersFromZip(ctx, r); err != nil {
-		log.Printf("Error syncing traders from zip: %v", err)
-	}
-	if err := s.syncProjectsFromZip(ctx, r); err != nil {
-		log.Printf("Error syncing projects from zip: %v", err)
+	for _, step := range s.syncSteps() {
+		if entity != "" && step.entity != entity {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := step.sync(ctx, r)
+		// Even a failed step may have updated some rows
+		step.invalidate()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			s.entityFailed(step.entity, err)
+		}
 	}
 
 	return nil
 }
 
+// dataPath returns where a data file or directory is in the source repository
+func (s *SyncService) dataPath(name string) string {
+	if dir := s.cfg.GetSyncRepoPath(); dir != "" {
+		return dir + "/" + name
+	}
+	return name
+}
+
 func (s *SyncService) getZipFile(r *zip.Reader, path string) ([]byte, error) {
+	path = s.dataPath(path)
 	// GitHub zipballs have a root directory like "owner-repo-sha/"
 	// We need to find the file regardless of the root directory name
 	for _, f := range r.File {
@@ -281,7 +526,7 @@ func (s *SyncService) getZipFile(r *zip.Reader, path string) ([]byte, error) {
 
 func (s *SyncService) getZipDirFiles(r *zip.Reader, dir string) (map[string][]byte, error) {
 	files := make(map[string][]byte)
-	dirToken := "/" + dir + "/"
+	dirToken := "/" + s.dataPath(dir) + "/"
 	for _, f := range r.File {
 		if f.FileInfo().IsDir() {
 			continue
@@ -334,10 +579,23 @@ func (s *SyncService) loadZipCollection(r *zip.Reader, dir, fallback string) ([]
 	return result, nil
 }
 
+// DataVersion returns the source commit SHA of the last successful sync,
+// falling back to a static version when no sync has been recorded yet
+func (s *SyncService) DataVersion() string {
+	if s.metadataRepo == nil {
+		return defaultDataVersion
+	}
+	version, err := s.metadataRepo.Get(metadataDataVersionKey)
+	if err != nil || version == "" {
+		return defaultDataVersion
+	}
+	return version
+}
+
 // GetSnapshot returns a full point-in-time snapshot of all static data
 func (s *SyncService) GetSnapshot() (*models.Snapshot, error) {
 	snapshot := &models.Snapshot{
-		Version:  "1.0", // Could be based on GitHub SHA or current timestamp
+		Version:  s.DataVersion(),
 		SyncedAt: time.Now(),
 	}
 
@@ -389,11 +647,21 @@ func (s *SyncService) GetSnapshot() (*models.Snapshot, error) {
 func (s *SyncService) syncQuestsFromZip(ctx context.Context, r *zip.Reader) error {
 	questsData, err := s.loadZipCollection(r, "quests", "quests.json")
 	if err != nil {
-		log.Printf("Warning: Could not load quests data from zip: %v", err)
+		s.entityUnreadable("quests", err)
 		return nil
 	}
 
+	var previous map[string]models.JSONB
+	if s.notificationService != nil {
+		rows, err := s.questRepo.ListAll()
+		previous = indexEntityData(rows, err, func(row models.Quest) (string, models.JSONB) { return row.ExternalID, row.Data })
+	}
+
+	var translations []models.Translation
 	for _, q := range questsData {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		quest := &models.Quest{
 			SyncedAt: time.Now(),
 		}
@@ -425,28 +693,46 @@ func (s *SyncService) syncQuestsFromZip(ctx context.Context, r *zip.Reader) erro
 		quest.Data = models.JSONB(q)
 
 		err := s.questRepo.UpsertByExternalID(quest)
-		if err != nil {
-			log.Printf("Error upserting quest %s: %v", quest.ExternalID, err)
+		if errors.Is(err, repository.ErrManualOverride) {
+			// The row is left as admins edited it, but its translations still come from upstream
+			s.overrideKept("quests", quest.ExternalID)
+			translations = append(translations, extractTranslations(models.TranslationEntityQuest, quest.ExternalID, q, quest.SyncedAt)...)
+		} else if err != nil {
+			s.upsertFailed("quests", quest.ExternalID, err)
+		} else {
+			s.recordChange(models.FavoriteEntityQuest, quest.ExternalID, quest.Name, previous, q)
+			translations = append(translations, extractTranslations(models.TranslationEntityQuest, quest.ExternalID, q, quest.SyncedAt)...)
 		}
 	}
 
-	log.Printf("Synced %d quests from zip", len(questsData))
+	s.entitiesSynced("quests", len(questsData))
+	s.storeTranslations(models.TranslationEntityQuest, translations)
 	return nil
 }
 
 func (s *SyncService) syncItemsFromZip(ctx context.Context, r *zip.Reader) error {
 	itemsData, err := s.loadZipCollection(r, "items", "items.json")
 	if err != nil {
-		log.Printf("Warning: Could not load items data from zip: %v", err)
+		s.entityUnreadable("items", err)
 		return nil
 	}
 
-	owner := "MatD1"
-	repo := "arcraiders-data-fork"
-	branch := "main"
-	baseImageURL := fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/images/items", owner, repo, branch)
+	baseImageURL := fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/%s", s.cfg.SyncRepoOwner, s.cfg.SyncRepoName, s.cfg.SyncRepoBranch, s.dataPath("images/items"))
+
+	var recipes []models.Recipe
+	var stats []models.ItemStat
+	var translations []models.Translation
+	imageRepairs := s.imageRepairs(models.ImageEntityItem)
+	var previous map[string]models.JSONB
+	if s.notificationService != nil {
+		rows, err := s.itemRepo.ListAll()
+		previous = indexEntityData(rows, err, func(row models.Item) (string, models.JSONB) { return row.ExternalID, row.Data })
+	}
 
 	for _, i := range itemsData {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		item := &models.Item{
 			SyncedAt: time.Now(),
 		}
@@ -482,24 +768,137 @@ func (s *SyncService) syncItemsFromZip(ctx context.Context, r *zip.Reader) error
 				encodedFilename := url.PathEscape(filename)
 				item.ImageURL = fmt.Sprintf("%s/%s", baseImageURL, encodedFilename)
 			}
+			// Keep the working URL found by the image check while upstream still has the broken one
+			if check, ok := imageRepairs[item.ExternalID]; ok && check.URL == item.ImageURL {
+				item.ImageURL = check.RepairedURL
+			}
 		}
 
 		item.Data = models.JSONB(i)
 
 		err := s.itemRepo.UpsertByExternalID(item)
-		if err != nil {
-			log.Printf("Error upserting item %s: %v", item.ExternalID, err)
+		if errors.Is(err, repository.ErrManualOverride) {
+			// The row is left as admins edited it, but its translations still come from upstream
+			s.overrideKept("items", item.ExternalID)
+			translations = append(translations, extractTranslations(models.TranslationEntityItem, item.ExternalID, i, item.SyncedAt)...)
+		} else if err != nil {
+			s.upsertFailed("items", item.ExternalID, err)
+		} else {
+			s.recordChange(models.FavoriteEntityItem, item.ExternalID, item.Name, previous, i)
+			translations = append(translations, extractTranslations(models.TranslationEntityItem, item.ExternalID, i, item.SyncedAt)...)
+		}
+
+		if recipe := recipeFromItemData(item.ExternalID, i); recipe != nil {
+			recipes = append(recipes, *recipe)
 		}
+		stats = append(stats, itemStatsFromData(item.ExternalID, i)...)
 	}
 
-	log.Printf("Synced %d items from zip", len(itemsData))
+	s.entitiesSynced("items", len(itemsData))
+
+	if s.recipeRepo != nil {
+		if err := s.recipeRepo.ReplaceAll(recipes); err != nil {
+			return fmt.Errorf("failed to store recipes: %w", err)
+		}
+		s.entitiesSynced("recipes", len(recipes))
+	}
+	if s.itemStatRepo != nil {
+		if err := s.itemStatRepo.ReplaceAll(stats); err != nil {
+			return fmt.Errorf("failed to store item stats: %w", err)
+		}
+		s.entitiesSynced("item_stats", len(stats))
+	}
+	s.storeTranslations(models.TranslationEntityItem, translations)
 	return nil
 }
 
+// indexEntityData maps stored rows' external IDs to their data for change detection. On a
+// load error changes for that collection are simply not detected.
+func indexEntityData[T any](rows []T, err error, fields func(T) (string, models.JSONB)) map[string]models.JSONB {
+	if err != nil {
+		syncLog.Warn("Failed to load existing data for change notifications", "error", err)
+		return nil
+	}
+	index := make(map[string]models.JSONB, len(rows))
+	for _, row := range rows {
+		id, data := fields(row)
+		index[id] = data
+	}
+	return index
+}
+
+// recordChange notes an existing entity whose data differs from what was stored. New
+// entities are skipped since nobody can have favorited or tracked them yet.
+func (s *SyncService) recordChange(entityType, entityID, name string, previous map[string]models.JSONB, current map[string]interface{}) {
+	old, ok := previous[entityID]
+	if !ok {
+		return
+	}
+	if change := diffEntityData(entityType, entityID, name, old, current); change != nil {
+		s.changes = append(s.changes, *change)
+	}
+}
+
+// notifyChanges sends notifications for the entities changed by the last sync
+func (s *SyncService) notifyChanges() {
+	if s.notificationService == nil || len(s.changes) == 0 {
+		return
+	}
+	sent, err := s.notificationService.NotifyEntityChanges(s.changes)
+	if err != nil {
+		syncLog.Warn("Failed to send change notifications", "error", err)
+		return
+	}
+	syncLog.Info("Sent change notifications", "changed_entities", len(s.changes), "notifications", sent)
+}
+
+// recipeFromItemData extracts the crafting recipe embedded in an upstream item, or nil if
+// the item isn't craftable. Upstream stores it as "recipe": {"<ingredient id>": <qty>}.
+func recipeFromItemData(itemExternalID string, data map[string]interface{}) *models.Recipe {
+	raw, ok := data["recipe"].(map[string]interface{})
+	if !ok || itemExternalID == "" {
+		return nil
+	}
+
+	ingredients := make(map[string]interface{}, len(raw))
+	for ingredientID, qty := range raw {
+		if q, ok := qty.(float64); ok && q > 0 {
+			ingredients[ingredientID] = int(q)
+		}
+	}
+	if len(ingredients) == 0 {
+		return nil
+	}
+
+	recipe := &models.Recipe{
+		ItemExternalID: itemExternalID,
+		OutputQuantity: 1,
+		Ingredients:    models.JSONB(ingredients),
+		SyncedAt:       time.Now(),
+	}
+	if q, ok := data["craftQuantity"].(float64); ok && q > 0 {
+		recipe.OutputQuantity = int(q)
+	}
+	// craftBench is a single bench ID in most entries but a list in a few
+	switch bench := data["craftBench"].(type) {
+	case string:
+		recipe.Bench = bench
+	case []interface{}:
+		benches := make([]string, 0, len(bench))
+		for _, b := range bench {
+			if name, ok := b.(string); ok {
+				benches = append(benches, name)
+			}
+		}
+		recipe.Bench = strings.Join(benches, ",")
+	}
+	return recipe
+}
+
 func (s *SyncService) syncSkillNodesFromZip(ctx context.Context, r *zip.Reader) error {
 	data, err := s.getZipFile(r, "skillNodes.json")
 	if err != nil {
-		log.Printf("Warning: Could not fetch skillNodes.json from zip: %v", err)
+		s.entityUnreadable("skill_nodes", err)
 		return nil
 	}
 
@@ -508,7 +907,11 @@ func (s *SyncService) syncSkillNodesFromZip(ctx context.Context, r *zip.Reader)
 		return err
 	}
 
+	var translations []models.Translation
 	for _, sn := range skillNodes {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		skillNode := &models.SkillNode{
 			SyncedAt: time.Now(),
 		}
@@ -552,23 +955,40 @@ func (s *SyncService) syncSkillNodesFromZip(ctx context.Context, r *zip.Reader)
 		skillNode.Data = models.JSONB(sn)
 
 		err := s.skillNodeRepo.UpsertByExternalID(skillNode)
-		if err != nil {
-			log.Printf("Error upserting skill node %s: %v", skillNode.ExternalID, err)
+		if errors.Is(err, repository.ErrManualOverride) {
+			// The row is left as admins edited it, but its translations still come from upstream
+			s.overrideKept("skill_nodes", skillNode.ExternalID)
+			translations = append(translations, extractTranslations(models.TranslationEntitySkillNode, skillNode.ExternalID, sn, skillNode.SyncedAt)...)
+		} else if err != nil {
+			s.upsertFailed("skill_nodes", skillNode.ExternalID, err)
+		} else {
+			translations = append(translations, extractTranslations(models.TranslationEntitySkillNode, skillNode.ExternalID, sn, skillNode.SyncedAt)...)
 		}
 	}
 
-	log.Printf("Synced %d skill nodes from zip", len(skillNodes))
+	s.entitiesSynced("skill_nodes", len(skillNodes))
+	s.storeTranslations(models.TranslationEntitySkillNode, translations)
 	return nil
 }
 
 func (s *SyncService) syncHideoutModulesFromZip(ctx context.Context, r *zip.Reader) error {
 	hideoutData, err := s.loadZipCollection(r, "hideout", "hideoutModules.json")
 	if err != nil {
-		log.Printf("Warning: Could not load hideout modules data from zip: %v", err)
+		s.entityUnreadable("hideout_modules", err)
 		return nil
 	}
 
+	var previous map[string]models.JSONB
+	if s.notificationService != nil {
+		rows, err := s.hideoutModuleRepo.ListAll()
+		previous = indexEntityData(rows, err, func(row models.HideoutModule) (string, models.JSONB) { return row.ExternalID, row.Data })
+	}
+
+	var translations []models.Translation
 	for _, hm := range hideoutData {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		hideoutModule := &models.HideoutModule{
 			SyncedAt: time.Now(),
 		}
@@ -594,19 +1014,27 @@ func (s *SyncService) syncHideoutModulesFromZip(ctx context.Context, r *zip.Read
 		hideoutModule.Data = models.JSONB(hm)
 
 		err := s.hideoutModuleRepo.UpsertByExternalID(hideoutModule)
-		if err != nil {
-			log.Printf("Error upserting hideout module %s: %v", hideoutModule.ExternalID, err)
+		if errors.Is(err, repository.ErrManualOverride) {
+			// The row is left as admins edited it, but its translations still come from upstream
+			s.overrideKept("hideout_modules", hideoutModule.ExternalID)
+			translations = append(translations, extractTranslations(models.TranslationEntityHideoutModule, hideoutModule.ExternalID, hm, hideoutModule.SyncedAt)...)
+		} else if err != nil {
+			s.upsertFailed("hideout_modules", hideoutModule.ExternalID, err)
+		} else {
+			s.recordChange(models.NoteEntityHideoutModule, hideoutModule.ExternalID, hideoutModule.Name, previous, hm)
+			translations = append(translations, extractTranslations(models.TranslationEntityHideoutModule, hideoutModule.ExternalID, hm, hideoutModule.SyncedAt)...)
 		}
 	}
 
-	log.Printf("Synced %d hideout modules from zip", len(hideoutData))
+	s.entitiesSynced("hideout_modules", len(hideoutData))
+	s.storeTranslations(models.TranslationEntityHideoutModule, translations)
 	return nil
 }
 
 func (s *SyncService) syncBotsFromZip(ctx context.Context, r *zip.Reader) error {
 	data, err := s.getZipFile(r, "bots.json")
 	if err != nil {
-		log.Printf("Warning: Could not fetch bots.json from zip: %v", err)
+		s.entityUnreadable("bots", err)
 		return nil
 	}
 
@@ -616,6 +1044,9 @@ func (s *SyncService) syncBotsFromZip(ctx context.Context, r *zip.Reader) error
 	}
 
 	for _, b := range bots {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		bot := &models.Bot{
 			SyncedAt: time.Now(),
 		}
@@ -633,18 +1064,18 @@ func (s *SyncService) syncBotsFromZip(ctx context.Context, r *zip.Reader) error
 
 		err := s.botRepo.UpsertByExternalID(bot)
 		if err != nil {
-			log.Printf("Error upserting bot %s: %v", bot.ExternalID, err)
+			s.upsertFailed("bots", bot.ExternalID, err)
 		}
 	}
 
-	log.Printf("Synced %d bots from zip", len(bots))
+	s.entitiesSynced("bots", len(bots))
 	return nil
 }
 
 func (s *SyncService) syncMapsFromZip(ctx context.Context, r *zip.Reader) error {
 	data, err := s.getZipFile(r, "maps.json")
 	if err != nil {
-		log.Printf("Warning: Could not fetch maps.json from zip: %v", err)
+		s.entityUnreadable("maps", err)
 		return nil
 	}
 
@@ -653,7 +1084,16 @@ func (s *SyncService) syncMapsFromZip(ctx context.Context, r *zip.Reader) error
 		return err
 	}
 
+	var previous map[string]models.JSONB
+	if s.notificationService != nil {
+		rows, err := s.mapRepo.ListAll()
+		previous = indexEntityData(rows, err, func(row models.Map) (string, models.JSONB) { return row.ExternalID, row.Data })
+	}
+
 	for _, m := range maps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		mapModel := &models.Map{
 			SyncedAt: time.Now(),
 		}
@@ -687,18 +1127,20 @@ func (s *SyncService) syncMapsFromZip(ctx context.Context, r *zip.Reader) error
 
 		err := s.mapRepo.UpsertByExternalID(mapModel)
 		if err != nil {
-			log.Printf("Error upserting map %s: %v", mapModel.ExternalID, err)
+			s.upsertFailed("maps", mapModel.ExternalID, err)
+		} else {
+			s.recordChange(models.FavoriteEntityMap, mapModel.ExternalID, mapModel.Name, previous, m)
 		}
 	}
 
-	log.Printf("Synced %d maps from zip", len(maps))
+	s.entitiesSynced("maps", len(maps))
 	return nil
 }
 
 func (s *SyncService) syncTradersFromZip(ctx context.Context, r *zip.Reader) error {
 	data, err := s.getZipFile(r, "trades.json")
 	if err != nil {
-		log.Printf("Warning: Could not fetch trades.json from zip: %v", err)
+		s.entityUnreadable("traders", err)
 		return nil
 	}
 
@@ -740,20 +1182,23 @@ func (s *SyncService) syncTradersFromZip(ctx context.Context, r *zip.Reader) err
 	}
 
 	for _, trader := range traderMap {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		err := s.traderRepo.UpsertByExternalID(trader)
 		if err != nil {
-			log.Printf("Error upserting trader %s: %v", trader.ExternalID, err)
+			s.upsertFailed("traders", trader.ExternalID, err)
 		}
 	}
 
-	log.Printf("Synced %d traders from zip", len(traderMap))
+	s.entitiesSynced("traders", len(traderMap))
 	return nil
 }
 
 func (s *SyncService) syncProjectsFromZip(ctx context.Context, r *zip.Reader) error {
 	data, err := s.getZipFile(r, "projects.json")
 	if err != nil {
-		log.Printf("Warning: Could not fetch projects.json from zip: %v", err)
+		s.entityUnreadable("projects", err)
 		return nil
 	}
 
@@ -763,6 +1208,9 @@ func (s *SyncService) syncProjectsFromZip(ctx context.Context, r *zip.Reader) er
 	}
 
 	for _, p := range projects {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		project := &models.Project{
 			SyncedAt: time.Now(),
 		}
@@ -796,10 +1244,10 @@ func (s *SyncService) syncProjectsFromZip(ctx context.Context, r *zip.Reader) er
 
 		err := s.projectRepo.UpsertByExternalID(project)
 		if err != nil {
-			log.Printf("Error upserting project %s: %v", project.ExternalID, err)
+			s.upsertFailed("projects", project.ExternalID, err)
 		}
 	}
 
-	log.Printf("Synced %d projects from zip", len(projects))
+	s.entitiesSynced("projects", len(projects))
 	return nil
 }
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mat/arcapi/internal/config"
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestSyncSkipsCancelledContext(t *testing.T) {
+	s := NewSyncService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
+	s.Stop()
+
+	// Would panic on the nil repositories if it got past the cancelled context
+	s.Sync(s.context(), models.SyncTriggerManual)
+	if health := s.Health(); health.LastStartedAt != nil {
+		t.Errorf("a cancelled sync should not start, got %+v", health)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Wait(ctx); err != nil {
+		t.Errorf("Wait with no sync running = %v", err)
+	}
+}
+
+func TestStopSchedulingKeepsRunningSync(t *testing.T) {
+	s := NewSyncService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
+
+	// Draining lets the running sync finish
+	s.StopScheduling()
+	if err := s.context().Err(); err != nil {
+		t.Errorf("StopScheduling cancelled the running sync: %v", err)
+	}
+
+	s.Stop()
+	if s.context().Err() == nil {
+		t.Error("expected Stop to cancel the running sync")
+	}
+}
+
+func TestProcessArchiveStopsWhenCancelled(t *testing.T) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.Create("repo-main/quests.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte(`[{"id": "q1", "name": "First"}]`))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewSyncService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.processArchive(ctx, buf.Bytes(), ""); !errors.Is(err, context.Canceled) {
+		t.Errorf("processArchive = %v, want context.Canceled", err)
+	}
+}
+
+func TestSyncRunRecordsEntityCounts(t *testing.T) {
+	s := NewSyncService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
+	s.beginRun(models.SyncTriggerManual, "", time.Now().UTC())
+	run := s.run
+
+	s.upsertFailed("quests", "q2", errors.New("duplicate key"))
+	s.entitiesSynced("quests", 3)
+	s.entityUnreadable("bots", errors.New("file not found"))
+	for i := 0; i < maxSyncRunErrors; i++ {
+		s.upsertFailed("items", "broken", errors.New("invalid"))
+	}
+	s.finishRun(models.SyncRunSucceeded, "abc123", nil)
+
+	if run.Status != models.SyncRunSucceeded || run.DataVersion != "abc123" || run.FinishedAt == nil {
+		t.Errorf("expected a finished, succeeded run, got %+v", run)
+	}
+	if got := run.Entities["quests"]; got != (models.SyncRunEntityCounts{Total: 3, Failed: 1}) {
+		t.Errorf("expected 3 quests with 1 failed, got %+v", got)
+	}
+	if got := run.Entities["items"].Failed; got != maxSyncRunErrors {
+		t.Errorf("expected %d failed items, got %d", maxSyncRunErrors, got)
+	}
+	if len(run.Errors) != maxSyncRunErrors || run.Errors[0] != "quests q2: duplicate key" {
+		t.Errorf("expected errors capped at %d, got %d starting %q", maxSyncRunErrors, len(run.Errors), run.Errors[0])
+	}
+
+	// Rows counted after the run finished don't reach it
+	s.upsertFailed("quests", "q3", errors.New("late"))
+	if run.Entities["quests"].Failed != 1 {
+		t.Errorf("a finished run should not change, got %+v", run.Entities["quests"])
+	}
+}
+
+func TestSyncEntityRejectsUnknownOrBusy(t *testing.T) {
+	s := NewSyncService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{})
+	if err := s.SyncEntity("enemy_types"); !errors.Is(err, ErrUnknownSyncEntity) {
+		t.Errorf("SyncEntity(enemy_types) = %v, want ErrUnknownSyncEntity", err)
+	}
+
+	s.isRunning = true
+	if err := s.SyncEntity("skill_nodes"); !errors.Is(err, ErrSyncRunning) {
+		t.Errorf("SyncEntity while running = %v, want ErrSyncRunning", err)
+	}
+}
+
+func TestGetZipFileUsesRepoPath(t *testing.T) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"repo-main/bots.json":          `"root"`,
+		"repo-main/data/bots.json":     `"data"`,
+		"repo-main/data/quests/a.json": `{"id": "a"}`,
+	} {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewSyncService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &config.Config{SyncRepoPath: "/data/"})
+	if data, err := s.getZipFile(r, "bots.json"); err != nil || string(data) != `"data"` {
+		t.Errorf("getZipFile(bots.json) = %s, %v; want the file under data/", data, err)
+	}
+	if files, _ := s.getZipDirFiles(r, "quests"); len(files) != 1 {
+		t.Errorf("expected the quests directory under data/, got %v", files)
+	}
+}
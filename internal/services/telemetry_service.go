@@ -0,0 +1,128 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+)
+
+const (
+	JobTelemetryPrune = "telemetry_prune"
+
+	// TelemetryPruneSchedule runs the telemetry_prune job once a day
+	TelemetryPruneSchedule = "15 3 * * *"
+
+	// MaxTelemetryBatch caps the events accepted in one request
+	MaxTelemetryBatch = 50
+)
+
+var (
+	ErrTelemetryDisabled        = errors.New("telemetry is disabled")
+	ErrTelemetryConsentRequired = errors.New("telemetry requires opting in with telemetry_opt_in on /me/privacy")
+	ErrInvalidTelemetryEvent    = errors.New("invalid telemetry event")
+)
+
+var (
+	// telemetryNamePattern allows dotted lowercase names such as quests.detail, which keeps
+	// free text (and anything personal in it) out of the counts
+	telemetryNamePattern       = regexp.MustCompile(`^[a-z0-9_]+(\.[a-z0-9_]+)*$`)
+	telemetryAppVersionPattern = regexp.MustCompile(`^[0-9A-Za-z.+-]*$`)
+)
+
+// TelemetryEvent is one event reported by a client app
+type TelemetryEvent struct {
+	Type       string `json:"type"`        // screen_view or feature_use
+	Name       string `json:"name"`        // Screen or feature name, e.g. quests.detail
+	Platform   string `json:"platform"`    // ios, android, web or desktop
+	AppVersion string `json:"app_version"` // Optional client version, e.g. 1.4.2
+}
+
+func (e TelemetryEvent) validate() error {
+	switch e.Type {
+	case models.TelemetryEventScreenView, models.TelemetryEventFeatureUse:
+	default:
+		return fmt.Errorf("%w: type must be screen_view or feature_use", ErrInvalidTelemetryEvent)
+	}
+	if len(e.Name) > 64 || !telemetryNamePattern.MatchString(e.Name) {
+		return fmt.Errorf("%w: name must be at most 64 lowercase letters, digits, underscores and dots", ErrInvalidTelemetryEvent)
+	}
+	switch e.Platform {
+	case models.TelemetryPlatformIOS, models.TelemetryPlatformAndroid, models.TelemetryPlatformWeb, models.TelemetryPlatformDesktop:
+	default:
+		return fmt.Errorf("%w: platform must be ios, android, web or desktop", ErrInvalidTelemetryEvent)
+	}
+	if len(e.AppVersion) > 32 || !telemetryAppVersionPattern.MatchString(e.AppVersion) {
+		return fmt.Errorf("%w: app_version must be at most 32 characters like 1.4.2", ErrInvalidTelemetryEvent)
+	}
+	return nil
+}
+
+// TelemetryService records coarse usage analytics from client apps that users opted in
+// to. Events are folded into daily counts per event, platform and app version; nothing
+// about the reporting user is stored.
+type TelemetryService struct {
+	repo          *repository.TelemetryRepository
+	enabled       bool
+	retentionDays int
+}
+
+func NewTelemetryService(repo *repository.TelemetryRepository, enabled bool, retentionDays int) *TelemetryService {
+	return &TelemetryService{repo: repo, enabled: enabled, retentionDays: retentionDays}
+}
+
+// Record validates a batch of events from user and adds them to today's counts. The
+// batch is rejected as a whole if any event is invalid.
+func (s *TelemetryService) Record(user *models.User, events []TelemetryEvent, now time.Time) (int, error) {
+	if !s.enabled {
+		return 0, ErrTelemetryDisabled
+	}
+	if !user.TelemetryOptIn {
+		return 0, ErrTelemetryConsentRequired
+	}
+	if len(events) == 0 || len(events) > MaxTelemetryBatch {
+		return 0, fmt.Errorf("%w: expected 1 to %d events", ErrInvalidTelemetryEvent, MaxTelemetryBatch)
+	}
+
+	day := now.UTC().Truncate(24 * time.Hour)
+	counts := make(map[TelemetryEvent]int64)
+	for i, event := range events {
+		if err := event.validate(); err != nil {
+			return 0, fmt.Errorf("event %d: %w", i, err)
+		}
+		counts[event]++
+	}
+
+	rows := make([]models.TelemetryCount, 0, len(counts))
+	for event, count := range counts {
+		rows = append(rows, models.TelemetryCount{
+			Day:        day,
+			EventType:  event.Type,
+			Name:       event.Name,
+			Platform:   event.Platform,
+			AppVersion: event.AppVersion,
+			Count:      count,
+		})
+	}
+	if err := s.repo.Increment(rows); err != nil {
+		return 0, err
+	}
+	return len(events), nil
+}
+
+// Summarize totals the counts of the last days days, optionally for one event type
+func (s *TelemetryService) Summarize(days int, eventType string, now time.Time) ([]repository.TelemetrySummary, error) {
+	since := now.UTC().Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+	return s.repo.Summarize(since, eventType)
+}
+
+// Prune is the telemetry_prune job: it deletes counts older than the retention period
+func (s *TelemetryService) Prune(startedAt time.Time) (int64, error) {
+	if s.retentionDays <= 0 {
+		return 0, nil
+	}
+	return s.repo.DeleteBefore(startedAt.UTC().Truncate(24*time.Hour).AddDate(0, 0, -s.retentionDays))
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestTelemetryRecordRejects(t *testing.T) {
+	s := NewTelemetryService(nil, true, 0)
+	optedIn := &models.User{TelemetryOptIn: true}
+	valid := TelemetryEvent{Type: models.TelemetryEventScreenView, Name: "quests.detail", Platform: models.TelemetryPlatformIOS, AppVersion: "1.4.2"}
+
+	tests := []struct {
+		name   string
+		user   *models.User
+		events []TelemetryEvent
+		want   error
+	}{
+		{"no consent", &models.User{}, []TelemetryEvent{valid}, ErrTelemetryConsentRequired},
+		{"empty batch", optedIn, nil, ErrInvalidTelemetryEvent},
+		{"batch too large", optedIn, make([]TelemetryEvent, MaxTelemetryBatch+1), ErrInvalidTelemetryEvent},
+		{"unknown type", optedIn, []TelemetryEvent{valid, {Type: "click", Name: "x", Platform: "web"}}, ErrInvalidTelemetryEvent},
+		{"free text name", optedIn, []TelemetryEvent{{Type: models.TelemetryEventFeatureUse, Name: "Searched for john@example.com", Platform: "web"}}, ErrInvalidTelemetryEvent},
+		{"unknown platform", optedIn, []TelemetryEvent{{Type: models.TelemetryEventFeatureUse, Name: "search", Platform: "linux"}}, ErrInvalidTelemetryEvent},
+	}
+	for _, tt := range tests {
+		if _, err := s.Record(tt.user, tt.events, time.Now()); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	disabled := NewTelemetryService(nil, false, 0)
+	if _, err := disabled.Record(optedIn, []TelemetryEvent{valid}, time.Now()); !errors.Is(err, ErrTelemetryDisabled) {
+		t.Errorf("disabled: got %v, want %v", err, ErrTelemetryDisabled)
+	}
+}
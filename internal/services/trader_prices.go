@@ -0,0 +1,262 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+// TraderPrice is one item price read from the external trader feed
+type TraderPrice struct {
+	TraderID   string
+	TraderName string
+	ItemID     string
+	ItemName   string
+	Price      float64
+}
+
+// TraderBarter is an item a trader hands over in exchange for other items rather than coins
+type TraderBarter struct {
+	TraderID   string
+	TraderName string
+	ItemID     string
+	Cost       map[string]int // Item external ID -> quantity given per unit received
+}
+
+// traderPriceFields are checked in order for an item's price in the external feed
+var traderPriceFields = []string{"trader_price", "traderPrice", "price", "value"}
+
+// traderRotatingFields are checked for an item's rotating stock flag in the external feed
+var traderRotatingFields = []string{"rotating", "is_rotating", "isRotating"}
+
+// changedTraderPrices drops prices equal to the item's latest snapshot at the trader
+func changedTraderPrices(prices []TraderPrice, latest []models.TraderPriceHistory) []TraderPrice {
+	previous := make(map[string]float64, len(latest))
+	for _, snapshot := range latest {
+		previous[snapshot.TraderID+":"+snapshot.ItemExternalID] = snapshot.Price
+	}
+	changed := make([]TraderPrice, 0, len(prices))
+	for _, p := range prices {
+		if price, ok := previous[p.TraderID+":"+p.ItemID]; ok && price == p.Price {
+			continue
+		}
+		changed = append(changed, p)
+	}
+	return changed
+}
+
+// TraderKey normalizes a trader name into the ID used for price history lookups
+func TraderKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// TraderInventory is one trader's item list from the external feed
+type TraderInventory struct {
+	Name  string
+	Items []interface{}
+}
+
+// extractTraderInventories groups the external trader payload by trader key. The feed is
+// keyed by trader name ({"data": {"Apollo": [items...]}}), but a list of trader objects
+// with an items/inventory array is accepted too so a format change doesn't break callers.
+func extractTraderInventories(payload interface{}) map[string]TraderInventory {
+	if root, ok := payload.(map[string]interface{}); ok {
+		if data, ok := root["data"]; ok {
+			payload = data
+		}
+	}
+
+	inventories := make(map[string]TraderInventory)
+	add := func(name string, inventory interface{}) {
+		items, ok := inventory.([]interface{})
+		if key := TraderKey(name); ok && key != "" {
+			inventories[key] = TraderInventory{Name: name, Items: items}
+		}
+	}
+
+	switch v := payload.(type) {
+	case map[string]interface{}:
+		for traderName, inventory := range v {
+			add(traderName, inventory)
+		}
+	case []interface{}:
+		for _, entry := range v {
+			trader, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := trader["name"].(string)
+			if name == "" {
+				name, _ = trader["id"].(string)
+			}
+			inventory := trader["items"]
+			if inventory == nil {
+				inventory = trader["inventory"]
+			}
+			add(name, inventory)
+		}
+	}
+	return inventories
+}
+
+// extractTraderPrices reads item prices out of the external trader payload
+func extractTraderPrices(payload interface{}) []TraderPrice {
+	var prices []TraderPrice
+	for _, inventory := range extractTraderInventories(payload) {
+		prices = append(prices, traderInventoryPrices(inventory.Name, inventory.Items)...)
+	}
+	return prices
+}
+
+// TraderStock is an item a trader currently sells, read from the external trader feed
+type TraderStock struct {
+	TraderID   string
+	TraderName string
+	ItemID     string
+	ItemName   string
+	Rotating   bool // The feed flags the item as part of rotating stock
+}
+
+// extractTraderStock reads every trader's listed items out of the external trader payload,
+// once per trader and item
+func extractTraderStock(payload interface{}) []TraderStock {
+	var stock []TraderStock
+	for _, inventory := range extractTraderInventories(payload) {
+		seen := make(map[string]bool, len(inventory.Items))
+		for _, entry := range inventory.Items {
+			item, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			itemID := traderFeedString(item["id"])
+			if itemID == "" {
+				itemID = traderFeedString(item["item_id"])
+			}
+			if itemID == "" || seen[itemID] {
+				continue
+			}
+			seen[itemID] = true
+
+			name, _ := item["name"].(string)
+			rotating := false
+			for _, field := range traderRotatingFields {
+				if flag, ok := item[field].(bool); ok {
+					rotating = flag
+					break
+				}
+			}
+			stock = append(stock, TraderStock{
+				TraderID:   TraderKey(inventory.Name),
+				TraderName: inventory.Name,
+				ItemID:     itemID,
+				ItemName:   name,
+				Rotating:   rotating,
+			})
+		}
+	}
+	return stock
+}
+
+// extractTraderBarters reads barter offers out of the external trader payload. An offer is
+// an inventory entry with a "barter" (or "trade") cost given either as an item ID ->
+// quantity map or as a list of {item_id, quantity} objects.
+func extractTraderBarters(payload interface{}) []TraderBarter {
+	var barters []TraderBarter
+	for _, inventory := range extractTraderInventories(payload) {
+		for _, entry := range inventory.Items {
+			item, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			itemID := traderFeedString(item["id"])
+			if itemID == "" {
+				itemID = traderFeedString(item["item_id"])
+			}
+			cost := item["barter"]
+			if cost == nil {
+				cost = item["trade"]
+			}
+			costs := traderBarterCost(cost)
+			if itemID == "" || len(costs) == 0 {
+				continue
+			}
+			barters = append(barters, TraderBarter{
+				TraderID:   TraderKey(inventory.Name),
+				TraderName: inventory.Name,
+				ItemID:     itemID,
+				Cost:       costs,
+			})
+		}
+	}
+	return barters
+}
+
+func traderBarterCost(value interface{}) map[string]int {
+	costs := make(map[string]int)
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for id, qty := range v {
+			if q, ok := qty.(float64); ok && q > 0 {
+				costs[id] += int(q)
+			}
+		}
+	case []interface{}:
+		for _, entry := range v {
+			part, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			id := traderFeedString(part["item_id"])
+			if id == "" {
+				id = traderFeedString(part["id"])
+			}
+			q, _ := part["quantity"].(float64)
+			if id != "" && q > 0 {
+				costs[id] += int(q)
+			}
+		}
+	}
+	return costs
+}
+
+func traderInventoryPrices(traderName string, items []interface{}) []TraderPrice {
+	prices := make([]TraderPrice, 0, len(items))
+	for _, entry := range items {
+		item, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		itemID := traderFeedString(item["id"])
+		if itemID == "" {
+			itemID = traderFeedString(item["item_id"])
+		}
+		if itemID == "" {
+			continue
+		}
+		for _, field := range traderPriceFields {
+			if price, ok := item[field].(float64); ok {
+				name, _ := item["name"].(string)
+				prices = append(prices, TraderPrice{
+					TraderID:   TraderKey(traderName),
+					TraderName: traderName,
+					ItemID:     itemID,
+					ItemName:   name,
+					Price:      price,
+				})
+				break
+			}
+		}
+	}
+	return prices
+}
+
+func traderFeedString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestExtractTraderPrices(t *testing.T) {
+	keyed := map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"Apollo": []interface{}{
+				map[string]interface{}{"id": "bandage", "name": "Bandage", "trader_price": float64(300), "value": float64(250)},
+				map[string]interface{}{"id": "no_price"},
+			},
+		},
+	}
+	prices := extractTraderPrices(keyed)
+	if len(prices) != 1 {
+		t.Fatalf("expected 1 price, got %+v", prices)
+	}
+	if p := prices[0]; p.TraderID != "apollo" || p.ItemID != "bandage" || p.Price != 300 {
+		t.Errorf("unexpected price %+v", p)
+	}
+
+	listed := []interface{}{
+		map[string]interface{}{"name": "Celeste", "inventory": []interface{}{
+			map[string]interface{}{"item_id": float64(12), "price": float64(80)},
+		}},
+	}
+	prices = extractTraderPrices(listed)
+	if len(prices) != 1 || prices[0].TraderID != "celeste" || prices[0].ItemID != "12" {
+		t.Errorf("unexpected prices from trader list %+v", prices)
+	}
+}
+
+func TestChangedTraderPrices(t *testing.T) {
+	latest := []models.TraderPriceHistory{
+		{TraderID: "apollo", ItemExternalID: "bandage", Price: 300},
+		{TraderID: "apollo", ItemExternalID: "rope", Price: 50},
+	}
+	prices := []TraderPrice{
+		{TraderID: "apollo", ItemID: "bandage", Price: 300},
+		{TraderID: "apollo", ItemID: "rope", Price: 60},
+		{TraderID: "celeste", ItemID: "bandage", Price: 300},
+	}
+
+	changed := changedTraderPrices(prices, latest)
+	if len(changed) != 2 || changed[0].ItemID != "rope" || changed[1].TraderID != "celeste" {
+		t.Errorf("expected the changed and the new price, got %+v", changed)
+	}
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mat/arcapi/internal/logging"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
+	"github.com/robfig/cron/v3"
+)
+
+var traderLog = logging.For("traders")
+
+// traderStockRetentionWeeks is how many weeks of stock rotations are kept
+const traderStockRetentionWeeks = 12
+
+// TraderRotationItem is an item in a trader's current stock rotation
+type TraderRotationItem struct {
+	models.TraderStockItem
+	NewThisRotation bool `json:"new_this_rotation"` // Not stocked by this trader in the previous rotation
+}
+
+// TraderRotationStock is one trader's stock in the current rotation
+type TraderRotationStock struct {
+	TraderID   string               `json:"trader_id"`
+	TraderName string               `json:"trader_name"`
+	Items      []TraderRotationItem `json:"items"`
+	// Removed lists items from the previous rotation that this rotation hasn't listed yet
+	Removed []models.TraderStockItem `json:"removed"`
+}
+
+// TraderRotation is the current stock rotation of every trader
+type TraderRotation struct {
+	StartedAt      time.Time             `json:"started_at"`
+	NextRotationAt time.Time             `json:"next_rotation_at"`
+	SecondsUntil   int64                 `json:"seconds_until"`
+	Traders        []TraderRotationStock `json:"data"`
+}
+
+// TraderRotationService tracks which items traders stock in each weekly rotation, from
+// refreshes of the external trader feed, and notifies users when an item they favorited
+// comes into stock
+type TraderRotationService struct {
+	stockRepo           *repository.TraderStockRepository
+	notificationService *NotificationService // May be nil
+	schedule            cron.Schedule
+}
+
+// NewTraderRotationService parses the rotation schedule, a standard cron expression such as
+// the weekly reset
+func NewTraderRotationService(stockRepo *repository.TraderStockRepository, notificationService *NotificationService, rotationCron string) (*TraderRotationService, error) {
+	schedule, err := cron.ParseStandard(rotationCron)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trader rotation schedule %q: %w", rotationCron, err)
+	}
+	return &TraderRotationService{stockRepo: stockRepo, notificationService: notificationService, schedule: schedule}, nil
+}
+
+// Rotation returns when the rotation running at now started and when the next one starts
+func (s *TraderRotationService) Rotation(now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	// Step back until a rotation starts at or before now; one step for weekly schedules
+	from := now.AddDate(0, 0, -8)
+	for i := 0; s.schedule.Next(from).After(now) && i < 52; i++ {
+		from = from.AddDate(0, 0, -7)
+	}
+	start := s.schedule.Next(from)
+	for next := s.schedule.Next(start); !next.After(now); next = s.schedule.Next(start) {
+		start = next
+	}
+	return start.UTC(), s.schedule.Next(now).UTC()
+}
+
+// Record stores the stock listed in a refresh of the trader feed under the current
+// rotation. Items that weren't stocked by the trader in the previous rotation are announced
+// to the users who favorited them, once per rotation. Nothing is announced before a
+// previous rotation has been recorded, so the first refresh doesn't notify for everything.
+func (s *TraderRotationService) Record(data interface{}, seenAt time.Time) error {
+	stock := extractTraderStock(data)
+	if len(stock) == 0 {
+		return nil
+	}
+	start, _ := s.Rotation(seenAt)
+
+	current, err := s.stockRepo.FindByRotation(start, "")
+	if err != nil {
+		return fmt.Errorf("failed to load current rotation: %w", err)
+	}
+	previousStart, err := s.stockRepo.FindPreviousRotation(start)
+	if err != nil {
+		return fmt.Errorf("failed to find previous rotation: %w", err)
+	}
+	var previous []models.TraderStockItem
+	if previousStart != nil {
+		if previous, err = s.stockRepo.FindByRotation(*previousStart, ""); err != nil {
+			return fmt.Errorf("failed to load previous rotation: %w", err)
+		}
+	}
+
+	known := make(map[string]bool, len(current)+len(previous))
+	for _, rotation := range [][]models.TraderStockItem{current, previous} {
+		for _, item := range rotation {
+			known[item.TraderID+":"+item.ItemExternalID] = true
+		}
+	}
+
+	items := make([]models.TraderStockItem, len(stock))
+	var arrivals []TraderStock
+	for i, st := range stock {
+		items[i] = models.TraderStockItem{
+			TraderID:       st.TraderID,
+			TraderName:     st.TraderName,
+			ItemExternalID: st.ItemID,
+			ItemName:       st.ItemName,
+			RotationStart:  start,
+			Rotating:       st.Rotating,
+			FirstSeenAt:    seenAt,
+			LastSeenAt:     seenAt,
+		}
+		if previousStart != nil && !known[st.TraderID+":"+st.ItemID] {
+			arrivals = append(arrivals, st)
+		}
+	}
+	if err := s.stockRepo.UpsertBatch(items); err != nil {
+		return fmt.Errorf("failed to record trader stock: %w", err)
+	}
+
+	if len(arrivals) > 0 && s.notificationService != nil {
+		if _, err := s.notificationService.NotifyTraderStock(arrivals); err != nil {
+			traderLog.Error("Failed to send trader stock notifications", "error", err)
+		}
+	}
+
+	if _, err := s.stockRepo.DeleteOlderThan(start.AddDate(0, 0, -7*traderStockRetentionWeeks)); err != nil {
+		traderLog.Error("Failed to prune trader stock rotations", "error", err)
+	}
+	return nil
+}
+
+// Current returns the stock of the rotation running at now, compared with the previous
+// rotation. traderID and itemID narrow it to one trader or to the traders stocking an item.
+func (s *TraderRotationService) Current(now time.Time, traderID, itemID string) (*TraderRotation, error) {
+	start, next := s.Rotation(now)
+	current, err := s.stockRepo.FindByRotation(start, traderID)
+	if err != nil {
+		return nil, err
+	}
+	previousStart, err := s.stockRepo.FindPreviousRotation(start)
+	if err != nil {
+		return nil, err
+	}
+	var previous []models.TraderStockItem
+	if previousStart != nil {
+		if previous, err = s.stockRepo.FindByRotation(*previousStart, traderID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &TraderRotation{
+		StartedAt:      start,
+		NextRotationAt: next,
+		SecondsUntil:   int64(next.Sub(now) / time.Second),
+		Traders:        compareTraderRotations(current, previous, previousStart != nil, itemID),
+	}, nil
+}
+
+// compareTraderRotations groups the current rotation's stock by trader, marking items new
+// since the previous rotation (when there was one) and listing the items dropped from it.
+// With itemID, only traders stocking that item are kept, with only that item.
+func compareTraderRotations(current, previous []models.TraderStockItem, hasPrevious bool, itemID string) []TraderRotationStock {
+	byTrader := make(map[string]*TraderRotationStock)
+	stocked := make(map[string]bool, len(current))
+	for _, item := range current {
+		stocked[item.TraderID+":"+item.ItemExternalID] = true
+	}
+	wasStocked := make(map[string]bool, len(previous))
+	for _, item := range previous {
+		wasStocked[item.TraderID+":"+item.ItemExternalID] = true
+	}
+
+	trader := func(item models.TraderStockItem) *TraderRotationStock {
+		t, ok := byTrader[item.TraderID]
+		if !ok {
+			t = &TraderRotationStock{TraderID: item.TraderID, TraderName: item.TraderName, Items: []TraderRotationItem{}, Removed: []models.TraderStockItem{}}
+			byTrader[item.TraderID] = t
+		}
+		return t
+	}
+	for _, item := range current {
+		if itemID != "" && item.ItemExternalID != itemID {
+			continue
+		}
+		t := trader(item)
+		t.Items = append(t.Items, TraderRotationItem{
+			TraderStockItem: item,
+			NewThisRotation: hasPrevious && !wasStocked[item.TraderID+":"+item.ItemExternalID],
+		})
+	}
+	if itemID == "" {
+		for _, item := range previous {
+			if !stocked[item.TraderID+":"+item.ItemExternalID] {
+				t := trader(item)
+				t.Removed = append(t.Removed, item)
+			}
+		}
+	}
+
+	traders := make([]TraderRotationStock, 0, len(byTrader))
+	for _, t := range byTrader {
+		traders = append(traders, *t)
+	}
+	sort.Slice(traders, func(i, j int) bool { return traders[i].TraderID < traders[j].TraderID })
+	return traders
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+func TestExtractTraderStock(t *testing.T) {
+	payload := map[string]interface{}{
+		"data": map[string]interface{}{
+			"Apollo": []interface{}{
+				map[string]interface{}{"id": "bandage", "name": "Bandage", "price": float64(300)},
+				map[string]interface{}{"id": "bandage", "name": "Bandage", "price": float64(280)},
+				map[string]interface{}{"id": "shield", "name": "Shield", "isRotating": true},
+				map[string]interface{}{"name": "No ID"},
+			},
+		},
+	}
+	stock := extractTraderStock(payload)
+	if len(stock) != 2 {
+		t.Fatalf("expected 2 stocked items, got %+v", stock)
+	}
+	for _, st := range stock {
+		if st.TraderID != "apollo" || st.Rotating != (st.ItemID == "shield") {
+			t.Errorf("unexpected stock %+v", st)
+		}
+	}
+}
+
+func TestTraderRotationBounds(t *testing.T) {
+	s, err := NewTraderRotationService(nil, nil, "0 0 * * 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		now, start, next time.Time
+	}{
+		// Thursday 2026-10-15
+		{time.Date(2026, 10, 15, 14, 0, 0, 0, time.UTC), time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		// Exactly at a reset, the new rotation has started
+		{time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 26, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		start, next := s.Rotation(tc.now)
+		if !start.Equal(tc.start) || !next.Equal(tc.next) {
+			t.Errorf("Rotation(%s) = %s, %s, want %s, %s", tc.now, start, next, tc.start, tc.next)
+		}
+	}
+
+	if _, err := NewTraderRotationService(nil, nil, "weekly"); err == nil {
+		t.Error("expected an invalid schedule to be rejected")
+	}
+}
+
+func TestCompareTraderRotations(t *testing.T) {
+	stock := func(trader, item string) models.TraderStockItem {
+		return models.TraderStockItem{TraderID: trader, TraderName: trader, ItemExternalID: item}
+	}
+	current := []models.TraderStockItem{stock("apollo", "bandage"), stock("apollo", "shield"), stock("celeste", "shield")}
+	previous := []models.TraderStockItem{stock("apollo", "bandage"), stock("apollo", "rifle"), stock("lance", "battery")}
+
+	traders := compareTraderRotations(current, previous, true, "")
+	if len(traders) != 3 || traders[0].TraderID != "apollo" || traders[1].TraderID != "celeste" || traders[2].TraderID != "lance" {
+		t.Fatalf("unexpected traders %+v", traders)
+	}
+	apollo := traders[0]
+	if len(apollo.Items) != 2 || apollo.Items[0].NewThisRotation || !apollo.Items[1].NewThisRotation {
+		t.Errorf("apollo items = %+v, want shield new and bandage not", apollo.Items)
+	}
+	if len(apollo.Removed) != 1 || apollo.Removed[0].ItemExternalID != "rifle" {
+		t.Errorf("apollo removed = %+v, want rifle", apollo.Removed)
+	}
+	if len(traders[2].Items) != 0 || len(traders[2].Removed) != 1 {
+		t.Errorf("lance = %+v, want only battery removed", traders[2])
+	}
+
+	// Without a previous rotation nothing is new
+	for _, trader := range compareTraderRotations(current, nil, false, "") {
+		for _, item := range trader.Items {
+			if item.NewThisRotation {
+				t.Errorf("%s/%s marked new without a previous rotation", trader.TraderID, item.ItemExternalID)
+			}
+		}
+	}
+
+	// An item filter keeps the traders stocking it
+	traders = compareTraderRotations(current, previous, true, "shield")
+	if len(traders) != 2 || len(traders[0].Items) != 1 || len(traders[0].Removed) != 0 || traders[1].TraderID != "celeste" {
+		t.Errorf("shield traders = %+v", traders)
+	}
+}
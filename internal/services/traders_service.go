@@ -1,6 +1,8 @@
 package services
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -8,6 +10,10 @@ import (
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/mat/arcapi/internal/config"
+	"github.com/mat/arcapi/internal/models"
+	"github.com/mat/arcapi/internal/repository"
 )
 
 const (
@@ -18,21 +24,32 @@ const (
 )
 
 type TradersService struct {
-	cacheService *CacheService
-	httpClient   *http.Client
-	mu           sync.RWMutex
-	lastFetch    time.Time
+	cacheService     *CacheService
+	priceHistoryRepo *repository.TraderPriceHistoryRepository
+	cfg              *config.Config
+	httpClient       *http.Client
+	mu               sync.RWMutex
+	lastFetch        time.Time
+	onRefresh        []func(data interface{}, fetchedAt time.Time) error
 }
 
-func NewTradersService(cacheService *CacheService) *TradersService {
+func NewTradersService(cacheService *CacheService, priceHistoryRepo *repository.TraderPriceHistoryRepository, cfg *config.Config) *TradersService {
 	return &TradersService{
-		cacheService: cacheService,
+		cacheService:     cacheService,
+		priceHistoryRepo: priceHistoryRepo,
+		cfg:              cfg,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
 	}
 }
 
+// OnRefresh registers fn to run with the feed after every scheduled refresh. Call it before
+// Start.
+func (s *TradersService) OnRefresh(fn func(data interface{}, fetchedAt time.Time) error) {
+	s.onRefresh = append(s.onRefresh, fn)
+}
+
 // Start starts the background refresh goroutine
 func (s *TradersService) Start() {
 	// Initial fetch with panic recovery
@@ -106,6 +123,50 @@ func (s *TradersService) refreshTraders() {
 
 	s.lastFetch = time.Now()
 	fmt.Printf("Successfully refreshed traders data at %s\n", s.lastFetch.Format(time.RFC3339))
+
+	s.recordPriceHistory(data, s.lastFetch)
+	for _, fn := range s.onRefresh {
+		if err := fn(data, s.lastFetch); err != nil {
+			traderLog.Error("Trader refresh hook failed", "error", err)
+		}
+	}
+}
+
+// recordPriceHistory stores a price snapshot for every item in the refreshed feed whose
+// price changed since its latest snapshot, and prunes snapshots past the retention window
+func (s *TradersService) recordPriceHistory(data interface{}, recordedAt time.Time) {
+	if s.priceHistoryRepo == nil {
+		return
+	}
+
+	latest, err := s.priceHistoryRepo.Latest()
+	if err != nil {
+		log.Printf("Failed to load latest trader prices: %v", err)
+		return
+	}
+	prices := changedTraderPrices(extractTraderPrices(data), latest)
+	snapshots := make([]models.TraderPriceHistory, 0, len(prices))
+	for _, p := range prices {
+		snapshots = append(snapshots, models.TraderPriceHistory{
+			TraderID:       p.TraderID,
+			TraderName:     p.TraderName,
+			ItemExternalID: p.ItemID,
+			ItemName:       p.ItemName,
+			Price:          p.Price,
+			RecordedAt:     recordedAt,
+		})
+	}
+	if err := s.priceHistoryRepo.CreateBatch(snapshots); err != nil {
+		log.Printf("Failed to record trader price history: %v", err)
+		return
+	}
+
+	if s.cfg != nil && s.cfg.TraderPriceHistoryDays > 0 {
+		cutoff := recordedAt.AddDate(0, 0, -s.cfg.TraderPriceHistoryDays)
+		if _, err := s.priceHistoryRepo.DeleteOlderThan(cutoff); err != nil {
+			log.Printf("Failed to prune trader price history: %v", err)
+		}
+	}
 }
 
 // GetTraders returns the cached traders data, fetching if necessary
@@ -154,3 +215,41 @@ func (s *TradersService) GetTraders() (interface{}, error) {
 
 	return data, nil
 }
+
+// GetInventories returns the live inventory of every trader in the feed, keyed by TraderKey
+func (s *TradersService) GetInventories() (map[string]TraderInventory, error) {
+	data, err := s.GetTraders()
+	if err != nil {
+		return nil, err
+	}
+	return extractTraderInventories(data), nil
+}
+
+// TraderMarket is a snapshot of every coin price and barter offer in the trader feed.
+// Version changes whenever the feed content does, so it can be used in cache keys.
+type TraderMarket struct {
+	Prices  []TraderPrice
+	Barters []TraderBarter
+	Version string
+}
+
+// GetMarket returns the current prices and barter offers across all traders
+func (s *TradersService) GetMarket() (*TraderMarket, error) {
+	data, err := s.GetTraders()
+	if err != nil {
+		return nil, err
+	}
+
+	// encoding/json sorts map keys, so equal feeds hash the same on every instance
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode traders data: %w", err)
+	}
+	sum := sha1.Sum(raw)
+
+	return &TraderMarket{
+		Prices:  extractTraderPrices(data),
+		Barters: extractTraderBarters(data),
+		Version: hex.EncodeToString(sum[:])[:12],
+	}, nil
+}
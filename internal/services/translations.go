@@ -0,0 +1,82 @@
+package services
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mat/arcapi/internal/models"
+)
+
+// extractTranslations flattens every multilingual string in an upstream entity into one
+// row per language, keyed by its dotted path within the data (e.g. "objectives.0")
+func extractTranslations(entityType, entityID string, data map[string]interface{}, syncedAt time.Time) []models.Translation {
+	var translations []models.Translation
+	var walk func(path string, value interface{})
+	walk = func(path string, value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if path != "" && models.IsTranslationMap(v) {
+				langs := make([]string, 0, len(v))
+				for lang := range v {
+					langs = append(langs, lang)
+				}
+				sort.Strings(langs)
+				for _, lang := range langs {
+					if text := v[lang].(string); text != "" {
+						translations = append(translations, models.Translation{
+							EntityType: entityType,
+							EntityID:   entityID,
+							Field:      path,
+							Lang:       lang,
+							Text:       text,
+							SyncedAt:   syncedAt,
+						})
+					}
+				}
+				return
+			}
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				walk(joinTranslationPath(path, key), v[key])
+			}
+		case []interface{}:
+			for i, element := range v {
+				walk(joinTranslationPath(path, strconv.Itoa(i)), element)
+			}
+		}
+	}
+	walk("", data)
+	return translations
+}
+
+func joinTranslationPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// storeTranslations replaces the stored translations of one entity type with those
+// collected during this sync, and rebuilds the entity type's search documents from them
+func (s *SyncService) storeTranslations(entityType string, translations []models.Translation) {
+	if s.translationRepo == nil {
+		return
+	}
+	if err := s.translationRepo.ReplaceForEntityType(entityType, translations); err != nil {
+		syncLog.Warn("Failed to store translations", "entity", entityType, "error", err)
+	} else {
+		syncLog.Info("Synced translations from zip", "entity", entityType, "translations", len(translations))
+	}
+
+	documents := buildSearchDocuments(entityType, translations, time.Now())
+	if err := s.translationRepo.ReplaceSearchDocuments(entityType, documents); err != nil {
+		syncLog.Warn("Failed to store search documents", "entity", entityType, "error", err)
+		return
+	}
+	syncLog.Info("Indexed search documents", "entity", entityType, "documents", len(documents))
+}
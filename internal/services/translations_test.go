@@ -0,0 +1,38 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExtractTranslationsFlattensMultilingualFields(t *testing.T) {
+	data := map[string]interface{}{
+		"id":   "trash_into_treasure",
+		"name": map[string]interface{}{"en": "Trash Into Treasure", "de": "Aus Müll wird Gold", "fr": ""},
+		"objectives": []interface{}{
+			map[string]interface{}{"en": "Get 3 ARC Alloy", "de": "Besorge 3 ARC-Legierung"},
+		},
+		"position": map[string]interface{}{"en": 1.0},
+	}
+
+	translations := extractTranslations("quest", "trash_into_treasure", data, time.Now())
+	got := make(map[string]string, len(translations))
+	for _, tr := range translations {
+		got[tr.Field+"/"+tr.Lang] = tr.Text
+	}
+
+	want := map[string]string{
+		"name/en":         "Trash Into Treasure",
+		"name/de":         "Aus Müll wird Gold",
+		"objectives.0/en": "Get 3 ARC Alloy",
+		"objectives.0/de": "Besorge 3 ARC-Legierung",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d translations, got %v", len(want), got)
+	}
+	for key, text := range want {
+		if got[key] != text {
+			t.Errorf("%s: expected %q, got %q", key, text, got[key])
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mat/arcapi/internal/models"
+	"gorm.io/gorm"
+)
+
+// baseline creates the schema that GORM AutoMigrate managed on startup before versioned
+// migrations. It is a no-op on databases AutoMigrate already set up. It migrates the
+// models as they are now, so a fresh database may already have the changes of later
+// migrations, which must therefore be idempotent (e.g. check HasColumn before adding one).
+var baseline = &gormigrate.Migration{
+	ID: "202610150000_baseline",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(
+			&models.User{},
+			&models.APIKey{},
+			&models.JWTToken{},
+			&models.Quest{},
+			&models.Item{},
+			&models.SkillNode{},
+			&models.HideoutModule{},
+			&models.EnemyType{},
+			&models.Alert{},
+			&models.AuditLog{},
+			&models.UserQuestProgress{},
+			&models.UserHideoutModuleProgress{},
+			&models.UserSkillNodeProgress{},
+			&models.UserBlueprintProgress{},
+			&models.UserTraderProgress{},
+			&models.UserInventoryItem{},
+			&models.UserPlayerLevel{},
+			&models.UserNote{},
+			&models.Favorite{},
+			&models.Notification{},
+			&models.AuthorizationCode{},
+			&models.RefreshToken{},
+			&models.Bot{},
+			&models.Map{},
+			&models.Trader{},
+			&models.Project{},
+			&models.Metadata{},
+			&models.ItemAlias{},
+			&models.DeviceCode{},
+			&models.Role{},
+			&models.Team{},
+			&models.TeamMember{},
+			&models.LeaderboardEntry{},
+			&models.QuestCompletionStat{},
+			&models.Recipe{},
+			&models.ItemStat{},
+			&models.Translation{},
+			&models.ImageCheck{},
+			&models.TelemetryCount{},
+			&models.Experiment{},
+			&models.TraderPriceHistory{},
+			&models.MapMarker{},
+		)
+	},
+}
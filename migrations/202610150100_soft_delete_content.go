@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mat/arcapi/internal/models"
+	"gorm.io/gorm"
+)
+
+// softDeleteContent adds the indexed deleted_at column that makes deleting content
+// entities through the write API a soft delete
+var softDeleteContent = &gormigrate.Migration{
+	ID: "202610150100_soft_delete_content",
+	Migrate: func(tx *gorm.DB) error {
+		for _, model := range []interface{}{
+			&models.Quest{},
+			&models.Item{},
+			&models.SkillNode{},
+			&models.HideoutModule{},
+			&models.EnemyType{},
+		} {
+			migrator := tx.Migrator()
+			if !migrator.HasColumn(model, "DeletedAt") {
+				if err := migrator.AddColumn(model, "DeletedAt"); err != nil {
+					return err
+				}
+			}
+			if !migrator.HasIndex(model, "DeletedAt") {
+				if err := migrator.CreateIndex(model, "DeletedAt"); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	},
+}
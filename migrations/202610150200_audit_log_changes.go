@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mat/arcapi/internal/models"
+	"gorm.io/gorm"
+)
+
+// auditLogChanges adds the entity and field-level changes of write requests to audit logs
+var auditLogChanges = &gormigrate.Migration{
+	ID: "202610150200_audit_log_changes",
+	Migrate: func(tx *gorm.DB) error {
+		migrator := tx.Migrator()
+		for _, field := range []string{"EntityType", "EntityID", "Changes"} {
+			if !migrator.HasColumn(&models.AuditLog{}, field) {
+				if err := migrator.AddColumn(&models.AuditLog{}, field); err != nil {
+					return err
+				}
+			}
+		}
+		if !migrator.HasIndex(&models.AuditLog{}, "idx_audit_logs_entity") {
+			return migrator.CreateIndex(&models.AuditLog{}, "idx_audit_logs_entity")
+		}
+		return nil
+	},
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mat/arcapi/internal/models"
+	"gorm.io/gorm"
+)
+
+// alertIncidents adds the incident lifecycle fields to alerts
+var alertIncidents = &gormigrate.Migration{
+	ID: "202610150300_alert_incidents",
+	Migrate: func(tx *gorm.DB) error {
+		migrator := tx.Migrator()
+		for _, field := range []string{"IncidentStatus", "IncidentUpdates", "ResolvedAt"} {
+			if !migrator.HasColumn(&models.Alert{}, field) {
+				if err := migrator.AddColumn(&models.Alert{}, field); err != nil {
+					return err
+				}
+			}
+		}
+		if !migrator.HasIndex(&models.Alert{}, "IncidentStatus") {
+			return migrator.CreateIndex(&models.Alert{}, "IncidentStatus")
+		}
+		return nil
+	},
+}
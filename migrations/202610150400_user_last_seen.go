@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mat/arcapi/internal/models"
+	"gorm.io/gorm"
+)
+
+// userLastSeen adds when each user last made a request, and whether they share it
+var userLastSeen = &gormigrate.Migration{
+	ID: "202610150400_user_last_seen",
+	Migrate: func(tx *gorm.DB) error {
+		migrator := tx.Migrator()
+		for _, field := range []string{"LastSeenAt", "LastSeenPublic"} {
+			if !migrator.HasColumn(&models.User{}, field) {
+				if err := migrator.AddColumn(&models.User{}, field); err != nil {
+					return err
+				}
+			}
+		}
+		if !migrator.HasIndex(&models.User{}, "LastSeenAt") {
+			return migrator.CreateIndex(&models.User{}, "LastSeenAt")
+		}
+		return nil
+	},
+}
@@ -0,0 +1,15 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mat/arcapi/internal/models"
+	"gorm.io/gorm"
+)
+
+// securityEvents adds the table vulnerability reports are stored in
+var securityEvents = &gormigrate.Migration{
+	ID: "202610150500_security_events",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(&models.SecurityEvent{})
+	},
+}
@@ -0,0 +1,15 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mat/arcapi/internal/models"
+	"gorm.io/gorm"
+)
+
+// gameEvents adds the table behind the game event calendar
+var gameEvents = &gormigrate.Migration{
+	ID: "202610150600_game_events",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(&models.GameEvent{})
+	},
+}
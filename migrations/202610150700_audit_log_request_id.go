@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mat/arcapi/internal/models"
+	"gorm.io/gorm"
+)
+
+// auditLogRequestID records the request ID of each audit log row
+var auditLogRequestID = &gormigrate.Migration{
+	ID: "202610150700_audit_log_request_id",
+	Migrate: func(tx *gorm.DB) error {
+		migrator := tx.Migrator()
+		if !migrator.HasColumn(&models.AuditLog{}, "RequestID") {
+			if err := migrator.AddColumn(&models.AuditLog{}, "RequestID"); err != nil {
+				return err
+			}
+		}
+		if !migrator.HasIndex(&models.AuditLog{}, "RequestID") {
+			return migrator.CreateIndex(&models.AuditLog{}, "RequestID")
+		}
+		return nil
+	},
+}
@@ -0,0 +1,15 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mat/arcapi/internal/models"
+	"gorm.io/gorm"
+)
+
+// traderStock adds the table weekly trader stock rotations are tracked in
+var traderStock = &gormigrate.Migration{
+	ID: "202610150800_trader_stock",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(&models.TraderStockItem{})
+	},
+}
@@ -0,0 +1,15 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mat/arcapi/internal/models"
+	"gorm.io/gorm"
+)
+
+// progressSnapshots adds the table daily user progress snapshots are kept in
+var progressSnapshots = &gormigrate.Migration{
+	ID: "202610150900_progress_snapshots",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(&models.ProgressSnapshot{})
+	},
+}
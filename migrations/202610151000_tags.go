@@ -0,0 +1,15 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mat/arcapi/internal/models"
+	"gorm.io/gorm"
+)
+
+// tags adds the admin-managed tag taxonomy and the table applying tags to items and quests
+var tags = &gormigrate.Migration{
+	ID: "202610151000_tags",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(&models.Tag{}, &models.EntityTag{})
+	},
+}
@@ -0,0 +1,15 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mat/arcapi/internal/models"
+	"gorm.io/gorm"
+)
+
+// savedSearches adds the table users' named filter presets are kept in
+var savedSearches = &gormigrate.Migration{
+	ID: "202610151100_saved_searches",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(&models.SavedSearch{})
+	},
+}
@@ -0,0 +1,15 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mat/arcapi/internal/models"
+	"gorm.io/gorm"
+)
+
+// syncRuns adds the table the history of sync runs is recorded in
+var syncRuns = &gormigrate.Migration{
+	ID: "202610151200_sync_runs",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(&models.SyncRun{})
+	},
+}
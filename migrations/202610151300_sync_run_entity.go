@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mat/arcapi/internal/models"
+	"gorm.io/gorm"
+)
+
+// syncRunEntity records which entity type a partial sync run synced
+var syncRunEntity = &gormigrate.Migration{
+	ID: "202610151300_sync_run_entity",
+	Migrate: func(tx *gorm.DB) error {
+		migrator := tx.Migrator()
+		if !migrator.HasColumn(&models.SyncRun{}, "Entity") {
+			return migrator.AddColumn(&models.SyncRun{}, "Entity")
+		}
+		return nil
+	},
+}
@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mat/arcapi/internal/models"
+	"gorm.io/gorm"
+)
+
+// syncConflicts adds the flag that keeps sync from overwriting content edited through the
+// API, and the table conflicts between those edits and upstream are recorded in
+var syncConflicts = &gormigrate.Migration{
+	ID: "202610151400_sync_conflicts",
+	Migrate: func(tx *gorm.DB) error {
+		migrator := tx.Migrator()
+		for _, model := range []interface{}{&models.Quest{}, &models.Item{}, &models.SkillNode{}, &models.HideoutModule{}} {
+			if !migrator.HasColumn(model, "ManualOverride") {
+				if err := migrator.AddColumn(model, "ManualOverride"); err != nil {
+					return err
+				}
+			}
+		}
+		return tx.AutoMigrate(&models.SyncConflict{})
+	},
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/mat/arcapi/internal/models"
+	"gorm.io/gorm"
+)
+
+// searchDocuments adds the per-language search index. Trigram GIN indexes serve the
+// substring and prefix matches of search and autocomplete in every language, including
+// those Postgres has no text search configuration for.
+var searchDocuments = &gormigrate.Migration{
+	ID: "202610151500_search_documents",
+	Migrate: func(tx *gorm.DB) error {
+		if err := tx.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+			return err
+		}
+		if err := tx.AutoMigrate(&models.SearchDocument{}); err != nil {
+			return err
+		}
+		for _, stmt := range []string{
+			"CREATE INDEX IF NOT EXISTS idx_search_documents_name_trgm ON search_documents USING gin (name gin_trgm_ops)",
+			"CREATE INDEX IF NOT EXISTS idx_search_documents_body_trgm ON search_documents USING gin (body gin_trgm_ops)",
+		} {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
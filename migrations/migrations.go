@@ -0,0 +1,27 @@
+// Package migrations holds the versioned database schema migrations. Each one is applied
+// once, in order, and recorded in the schema_migrations table.
+package migrations
+
+import "github.com/go-gormigrate/gormigrate/v2"
+
+// All lists every migration in the order they are applied. IDs are the UTC time the
+// migration was written plus a short name; never change or remove one once it has been
+// released, and append new migrations to the end.
+var All = []*gormigrate.Migration{
+	baseline,
+	softDeleteContent,
+	auditLogChanges,
+	alertIncidents,
+	userLastSeen,
+	securityEvents,
+	gameEvents,
+	auditLogRequestID,
+	traderStock,
+	progressSnapshots,
+	tags,
+	savedSearches,
+	syncRuns,
+	syncRunEntity,
+	syncConflicts,
+	searchDocuments,
+}
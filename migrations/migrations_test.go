@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"regexp"
+	"testing"
+)
+
+var migrationIDPattern = regexp.MustCompile(`^[0-9]{12}_[a-z0-9_]+$`)
+
+func TestMigrationIDsAreOrderedAndUnique(t *testing.T) {
+	seen := make(map[string]bool, len(All))
+	for i, m := range All {
+		if !migrationIDPattern.MatchString(m.ID) {
+			t.Errorf("migration ID %q should be YYYYMMDDHHMM_name", m.ID)
+		}
+		if seen[m.ID] {
+			t.Errorf("duplicate migration ID %q", m.ID)
+		}
+		seen[m.ID] = true
+		if i > 0 && m.ID <= All[i-1].ID {
+			t.Errorf("migration %q is listed after %q; append new migrations in ID order", m.ID, All[i-1].ID)
+		}
+		if m.Migrate == nil {
+			t.Errorf("migration %q has no Migrate func", m.ID)
+		}
+	}
+}